@@ -0,0 +1,92 @@
+// Top bar indicator for cc-dailyuse-bar. It follows the app's
+// org.petems.CCDailyUse session bus service: GetIndicator when the app
+// appears, then IndicatorChanged on every update. The app does the
+// formatting; this only displays it.
+
+import Clutter from 'gi://Clutter';
+import Gio from 'gi://Gio';
+import GLib from 'gi://GLib';
+import GObject from 'gi://GObject';
+import St from 'gi://St';
+
+import {Extension} from 'resource:///org/gnome/shell/extensions/extension.js';
+import * as Main from 'resource:///org/gnome/shell/ui/main.js';
+import * as PanelMenu from 'resource:///org/gnome/shell/ui/panelMenu.js';
+import * as PopupMenu from 'resource:///org/gnome/shell/ui/popupMenu.js';
+
+const BUS_NAME = 'org.petems.CCDailyUse';
+const OBJECT_PATH = '/org/petems/CCDailyUse';
+const INTERFACE = 'org.petems.CCDailyUse';
+
+const NOT_RUNNING = ['CC', 'unknown', 'cc-dailyuse-bar is not running', ['cc-dailyuse-bar is not running']];
+
+const UsageIndicator = GObject.registerClass(
+class UsageIndicator extends PanelMenu.Button {
+    _init() {
+        super._init(0.0, 'Claude Code usage');
+
+        this._label = new St.Label({y_align: Clutter.ActorAlign.CENTER});
+        this.add_child(this._label);
+
+        this._lines = new PopupMenu.PopupMenuSection();
+        this.menu.addMenuItem(this._lines);
+        this.menu.addMenuItem(new PopupMenu.PopupSeparatorMenuItem());
+        this.menu.addAction('Refresh', () => this._call('Refresh'));
+
+        this._show(NOT_RUNNING);
+        this._signal = Gio.DBus.session.signal_subscribe(
+            BUS_NAME, INTERFACE, 'IndicatorChanged', OBJECT_PATH, null,
+            Gio.DBusSignalFlags.NONE,
+            (_conn, _sender, _path, _iface, _signal, params) => this._show(params.deep_unpack()));
+        this._watch = Gio.bus_watch_name(
+            Gio.BusType.SESSION, BUS_NAME, Gio.BusNameWatcherFlags.NONE,
+            () => this._fetch(),
+            () => this._show(NOT_RUNNING));
+    }
+
+    _fetch() {
+        Gio.DBus.session.call(
+            BUS_NAME, OBJECT_PATH, INTERFACE, 'GetIndicator', null,
+            new GLib.VariantType('(sssas)'), Gio.DBusCallFlags.NONE, -1, null,
+            (conn, result) => {
+                try {
+                    this._show(conn.call_finish(result).deep_unpack());
+                } catch (e) {
+                    logError(e, 'cc-dailyuse-bar: GetIndicator failed');
+                }
+            });
+    }
+
+    _call(method) {
+        Gio.DBus.session.call(
+            BUS_NAME, OBJECT_PATH, INTERFACE, method, null, null,
+            Gio.DBusCallFlags.NONE, -1, null, null);
+    }
+
+    _show([label, style, tooltip, lines]) {
+        this._label.text = label;
+        this._label.style_class = `cc-dailyuse-${style}`;
+        this.accessible_name = tooltip || label;
+        this._lines.removeAll();
+        for (const line of lines)
+            this._lines.addMenuItem(new PopupMenu.PopupMenuItem(line, {reactive: false}));
+    }
+
+    destroy() {
+        Gio.DBus.session.signal_unsubscribe(this._signal);
+        Gio.bus_unwatch_name(this._watch);
+        super.destroy();
+    }
+});
+
+export default class CCDailyUseExtension extends Extension {
+    enable() {
+        this._indicator = new UsageIndicator();
+        Main.panel.addToStatusArea(this.uuid, this._indicator);
+    }
+
+    disable() {
+        this._indicator?.destroy();
+        this._indicator = null;
+    }
+}
@@ -0,0 +1,88 @@
+// Plasma 6 widget for cc-dailyuse-bar's --plasmoid-feed mode. It rereads
+// the feed file every refresh_seconds and draws the theme icon and color
+// role the feed names, so it follows the active Plasma theme.
+import QtQuick
+import QtQuick.Layouts
+import org.kde.kirigami as Kirigami
+import org.kde.plasma.components as PlasmaComponents
+import org.kde.plasma.plasmoid
+import org.kde.plasma.plasma5support as Plasma5Support
+
+PlasmoidItem {
+    id: root
+
+    // Must match `cc-dailyuse-bar run --plasmoid-feed`; this is the default.
+    readonly property string feedCommand: 'cat "${XDG_RUNTIME_DIR:-/tmp}/cc-dailyuse-bar/plasmoid.json"'
+
+    property var feed: null
+    property int refreshSeconds: 30
+
+    function roleColor(role) {
+        switch (role) {
+        case "positive": return Kirigami.Theme.positiveTextColor;
+        case "neutral": return Kirigami.Theme.neutralTextColor;
+        case "negative": return Kirigami.Theme.negativeTextColor;
+        default: return Kirigami.Theme.disabledTextColor;
+        }
+    }
+
+    Plasma5Support.DataSource {
+        id: reader
+        engine: "executable"
+        onNewData: (source, data) => {
+            disconnectSource(source);
+            try {
+                root.feed = data["exit code"] === 0 ? JSON.parse(data.stdout) : null;
+            } catch (e) {
+                root.feed = null;
+            }
+            if (root.feed && root.feed.refresh_seconds > 0) {
+                root.refreshSeconds = root.feed.refresh_seconds;
+            }
+        }
+    }
+
+    Timer {
+        interval: root.refreshSeconds * 1000
+        running: true
+        repeat: true
+        triggeredOnStart: true
+        onTriggered: reader.connectSource(root.feedCommand)
+    }
+
+    Plasmoid.icon: feed ? feed.icon : "data-information"
+    toolTipMainText: feed ? feed.title : "CC Daily Use Bar"
+    toolTipSubText: feed ? "Status " + feed.status_label : "Start cc-dailyuse-bar run --plasmoid-feed"
+
+    compactRepresentation: RowLayout {
+        Kirigami.Icon {
+            source: Plasmoid.icon
+            color: root.roleColor(root.feed ? root.feed.icon_color : "disabled")
+            isMask: true
+            Layout.preferredWidth: Kirigami.Units.iconSizes.small
+            Layout.preferredHeight: Kirigami.Units.iconSizes.small
+        }
+        PlasmaComponents.Label {
+            text: root.feed ? root.feed.title : "CC –"
+        }
+        MouseArea {
+            anchors.fill: parent
+            onClicked: root.expanded = !root.expanded
+        }
+    }
+
+    fullRepresentation: ColumnLayout {
+        PlasmaComponents.Label {
+            text: root.feed ? root.feed.title : "cc-dailyuse-bar is not running"
+            font.bold: true
+        }
+        PlasmaComponents.Label {
+            visible: root.feed !== null
+            text: root.feed ? "Week: $" + root.feed.weekly_cost.toFixed(2) + "   Month: $" + root.feed.monthly_cost.toFixed(2) : ""
+        }
+        PlasmaComponents.Label {
+            visible: root.feed !== null
+            text: root.feed ? "Updated " + new Date(root.feed.updated_at).toLocaleTimeString() : ""
+        }
+    }
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+	"cc-dailyuse-bar/src/services"
+)
+
+// runSelfCheck validates the configuration, resolves ccusage, performs one
+// fetch, and parses the result, printing one line per stage. The returned
+// error carries a stage-specific exit code (see exit_codes.go).
+func runSelfCheck(cmd *cobra.Command) error {
+	out := cmd.OutOrStdout()
+
+	configService := services.NewConfigService()
+	if cfgFile != "" {
+		configService.SetConfigPath(cfgFile)
+	}
+	config, err := configService.Load()
+	if err == nil {
+		err = mergeConfig(config, cmd)
+	}
+	if err != nil {
+		fmt.Fprintf(out, "[FAIL] config: %v\n", err)
+		return withExitCode(ExitConfig, lib.WrapError(err, lib.ErrCodeConfig,
+			fmt.Sprintf("self-check failed: invalid configuration at %s", configService.GetConfigPath())))
+	}
+	fmt.Fprintf(out, "[ok]   config: %s\n", configService.GetConfigPath())
+
+	result, err := services.NewUsageService(config).SelfCheck()
+	if err != nil {
+		return reportSelfCheckFailure(cmd, err)
+	}
+
+	fmt.Fprintf(out, "[ok]   binary: %s\n", result.ResolvedPath)
+	fmt.Fprintf(out, "[ok]   fetch: %d bytes\n", result.OutputBytes)
+	if result.TodayFound {
+		fmt.Fprintf(out, "[ok]   parse: %d daily entries, today %s\n",
+			result.Entries, config.CostFormat().Format(result.Today.TotalCost))
+	} else {
+		fmt.Fprintf(out, "[ok]   parse: %d daily entries, no usage today\n", result.Entries)
+	}
+	if result.Sources != nil {
+		fmt.Fprintf(out, "[ok]   sources: %s\n", services.FormatSourceBreakdown(result.Sources, config.CostFormat()))
+	}
+	if result.Overlap != nil {
+		fmt.Fprintf(out, "[warn] data: %s\n", result.Overlap.Warning())
+	}
+	fmt.Fprintln(out, "Self-check passed")
+	return nil
+}
+
+func reportSelfCheckFailure(cmd *cobra.Command, err error) error {
+	out := cmd.OutOrStdout()
+	var checkErr *services.SelfCheckError
+	if !errors.As(err, &checkErr) {
+		fmt.Fprintf(out, "[FAIL] %v\n", err)
+		return err
+	}
+
+	code := ExitFailure
+	switch checkErr.Stage {
+	case services.StageBinary:
+		code = ExitBinary
+	case services.StageFetch:
+		code = ExitFetch
+	case services.StageParse:
+		code = ExitParse
+	}
+
+	// Earlier stages passed if we got this far.
+	if checkErr.Stage != services.StageBinary {
+		fmt.Fprintln(out, "[ok]   binary")
+	}
+	if checkErr.Stage == services.StageParse {
+		fmt.Fprintln(out, "[ok]   fetch")
+	}
+	fmt.Fprintf(out, "[FAIL] %s: %v\n", checkErr.Stage, checkErr.Err)
+	return withExitCode(code, lib.WrapError(err, lib.ErrCodeCCUsage, "self-check failed"))
+}
+
+// selfCheckSummary is a one-line description of a self-check outcome, used
+// by callers (like the service installer) that only need pass/fail.
+func selfCheckSummary(config *models.Config) string {
+	result, err := services.NewUsageService(config).SelfCheck()
+	if err != nil {
+		return fmt.Sprintf("failed (%v); run `cc-dailyuse-bar run --check` for details", err)
+	}
+	return fmt.Sprintf("passed (%d daily entries from %s)", result.Entries, result.ResolvedPath)
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/internal/testhelpers"
+)
+
+func TestRunCheck_ExitCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, dir string) string // returns config path
+		wantCode int
+		wantOut  string
+	}{
+		{
+			name: "invalid config",
+			setup: func(t *testing.T, dir string) string {
+				path := filepath.Join(dir, "config.yaml")
+				require.NoError(t, os.WriteFile(path, []byte("update_interval: -5\n"), 0o644))
+				return path
+			},
+			wantCode: ExitConfig,
+			wantOut:  "[FAIL] config",
+		},
+		{
+			name: "missing binary",
+			setup: func(t *testing.T, dir string) string {
+				return writeBinaryConfig(t, dir, filepath.Join(dir, "missing"))
+			},
+			wantCode: ExitBinary,
+			wantOut:  "[FAIL] binary",
+		},
+		{
+			name: "fetch fails",
+			setup: func(t *testing.T, dir string) string {
+				return writeBinaryConfig(t, dir, testhelpers.WriteFakeCommand(t, dir, "ccusage", "", 1))
+			},
+			wantCode: ExitFetch,
+			wantOut:  "[FAIL] fetch",
+		},
+		{
+			name: "unparseable output",
+			setup: func(t *testing.T, dir string) string {
+				return writeBinaryConfig(t, dir, testhelpers.WriteFakeCommand(t, dir, "ccusage", "nope\n", 0))
+			},
+			wantCode: ExitParse,
+			wantOut:  "[FAIL] parse",
+		},
+		{
+			name: "healthy",
+			setup: func(t *testing.T, dir string) string {
+				return writeBinaryConfig(t, dir, testhelpers.WriteFakeCommand(t, dir, "ccusage", `{"daily": []}`+"\n", 0))
+			},
+			wantCode: ExitOK,
+			wantOut:  "Self-check passed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			savedCfgFile, savedCheck := cfgFile, checkMode
+			t.Cleanup(func() { cfgFile, checkMode = savedCfgFile, savedCheck })
+
+			cfgPath := tt.setup(t, t.TempDir())
+			out, err := executeWithOutput(t, "run", "--check", "--config", cfgPath)
+			assert.Equal(t, tt.wantCode, exitCode(err), "error: %v", err)
+			assert.Contains(t, out, tt.wantOut)
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, ExitOK, exitCode(nil))
+	assert.Equal(t, ExitFailure, exitCode(errors.New("boom")))
+	wrapped := withExitCode(ExitParse, errors.New("bad json"))
+	assert.Equal(t, ExitParse, exitCode(wrapped))
+	assert.Equal(t, "bad json", wrapped.Error())
+}
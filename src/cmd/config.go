@@ -13,8 +13,10 @@ import (
 )
 
 var (
-	forceInit  bool
-	showFormat string
+	forceInit        bool
+	showFormat       string
+	encryptBackend   string
+	encryptRecipient string
 )
 
 var configCmd = &cobra.Command{
@@ -88,14 +90,53 @@ var configShowCmd = &cobra.Command{
 	},
 }
 
+var configEncryptCmd = &cobra.Command{
+	Use:   "encrypt <field>",
+	Short: "Encrypt a sensitive config value",
+	Long: `Move a sensitive value out of the config file, leaving an "enc:" reference
+that is decrypted whenever the config is loaded. field is ledger_webhook,
+http_token, telegram_bot_token, or notification_sinks[N].url.
+
+The keychain backend stores the value in the macOS keychain or, on Linux,
+the Secret Service via secret-tool. The age backend keeps the ciphertext in
+the config, decrypted with age-identity.txt beside it; --recipient encrypts
+to another key instead of that identity's own.`,
+	Example: `  cc-dailyuse-bar config encrypt ledger_webhook
+  cc-dailyuse-bar config encrypt 'notification_sinks[0].url' --backend age`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		svc := services.NewConfigService()
+		if cfgFile != "" {
+			svc.SetConfigPath(cfgFile)
+		}
+		if encryptRecipient != "" {
+			svc.SetSecretStore(services.SecretBackendAge, services.NewAgeStore(svc.AgeIdentityPath(), encryptRecipient))
+		}
+
+		config, err := svc.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if err := svc.Encrypt(config, args[0], encryptBackend); err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", args[0], err)
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "🔒 Encrypted %s in %s with %s.\n", args[0], svc.GetConfigPath(), encryptBackend)
+		return nil
+	},
+}
+
 func init() {
 	RootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configInitCmd)
 	configCmd.AddCommand(configValidateCmd)
 	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configEncryptCmd)
 
 	configInitCmd.Flags().BoolVarP(&forceInit, "force", "f", false, "Overwrite existing config")
 	configShowCmd.Flags().StringVar(&showFormat, "format", "yaml", "Output format (yaml or json)")
+	configEncryptCmd.Flags().StringVar(&encryptBackend, "backend", services.SecretBackendKeychain, "Where to keep the value (keychain or age)")
+	configEncryptCmd.Flags().StringVar(&encryptRecipient, "recipient", "", "age public key to encrypt to (default: age-identity.txt's own)")
 }
 
 func printConfig(cmd *cobra.Command, config *models.Config, format string) error {
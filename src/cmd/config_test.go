@@ -127,3 +127,17 @@ func TestConfigInitCmd_ForceOverwrites(t *testing.T) {
 	assert.NotContains(t, string(contents), "pre-existing-marker")
 	assert.Contains(t, string(contents), "ccusage_path")
 }
+
+func TestConfigEncryptCmd_RequiresSetValue(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+
+	buf := new(bytes.Buffer)
+	RootCmd.SetOut(buf)
+	RootCmd.SetErr(buf)
+	RootCmd.SetArgs([]string{"config", "encrypt", "ledger_webhook", "--config", cfgPath})
+
+	err := RootCmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no sensitive value set at ledger_webhook")
+	assert.NoFileExists(t, cfgPath)
+}
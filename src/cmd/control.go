@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"time"
+
+	"cc-dailyuse-bar/src/models"
+	"cc-dailyuse-bar/src/services"
+)
+
+// daemonControl implements control.Handler for the running tray app.
+type daemonControl struct {
+	config       *models.LiveConfig
+	usageService *services.UsageService
+	reloader     *configReloader
+	quit         func()
+}
+
+func (d *daemonControl) Status() (*models.UsageState, error) {
+	return d.usageService.GetDailyUsage()
+}
+
+func (d *daemonControl) Refresh() (*models.UsageState, error) {
+	return d.usageService.Refresh()
+}
+
+// ReloadConfig re-reads the config file, the same as SIGHUP.
+func (d *daemonControl) ReloadConfig() error {
+	if err := d.reloader.Reload(); err != nil {
+		return err
+	}
+	_, err := d.usageService.Refresh()
+	return err
+}
+
+// SetThresholds validates and applies a new yellow/red pair for this run
+// only; the config file is left untouched.
+func (d *daemonControl) SetThresholds(yellow, red float64) error {
+	_, err := d.config.Update(func(c *models.Config) error {
+		c.YellowThreshold, c.RedThreshold = yellow, red
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	d.usageService.SetThresholds(yellow, red)
+	_, err = d.usageService.Refresh()
+	return err
+}
+
+func (d *daemonControl) Quit() {
+	d.quit()
+}
+
+// dashboardSource feeds the web dashboard from the running app.
+type dashboardSource struct {
+	config  *models.LiveConfig
+	history *services.HistoryService // nil in demo mode
+}
+
+func (d *dashboardSource) Config() models.Config {
+	return *d.config.Get()
+}
+
+// DailyTotals reads the last days days, ending today, from the history
+// file.
+func (d *dashboardSource) DailyTotals(days int) ([]models.DailyTotal, error) {
+	if d.history == nil {
+		return nil, nil
+	}
+	now := time.Now()
+	y, m, day := now.Date()
+	since := time.Date(y, m, day, 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1-days)
+	samples, err := d.history.Samples(since)
+	if err != nil {
+		return nil, err
+	}
+	return models.BuildDailyTotals(samples, now, days), nil
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cc-dailyuse-bar/src/internal/control"
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// ctlTimeout is generous because refresh waits on a full ccusage run.
+const ctlTimeout = 60 * time.Second
+
+var (
+	ctlSocket string
+	ctlJSON   bool
+)
+
+var ctlCmd = &cobra.Command{
+	Use:   "ctl <command> [args]",
+	Short: "Control the running instance",
+	Long: `Send a command to the running CC Daily Use Bar over its control socket.
+
+Commands:
+  status                     Show the current usage state
+  refresh                    Query ccusage now and update the tray
+  refresh-async              Start a refresh without waiting for it
+  reload-config              Re-read the configuration file
+  set-threshold <yellow> <red>  Change alert thresholds until restart
+  quit                       Stop the running instance`,
+	Args:      cobra.MinimumNArgs(1),
+	ValidArgs: control.Commands,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		resp, err := control.Send(ctlSocket, control.Request{Command: args[0], Args: args[1:]}, ctlTimeout)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if ctlJSON {
+			data, err := json.MarshalIndent(resp, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal response: %w", err)
+			}
+			fmt.Fprintln(out, string(data))
+		} else {
+			if resp.Message != "" {
+				fmt.Fprintln(out, resp.Message)
+			}
+			if resp.State != nil {
+				fmt.Fprint(out, formatCtlState(resp.State))
+			}
+		}
+
+		if !resp.OK {
+			return lib.NewError(lib.ErrCodeSystem, resp.Error)
+		}
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(ctlCmd)
+
+	ctlCmd.Flags().StringVar(&ctlSocket, "socket", control.DefaultSocketPath(), "Path to the control socket")
+	ctlCmd.Flags().BoolVar(&ctlJSON, "json", false, "Print the raw JSON response")
+}
+
+func formatCtlState(state *models.UsageState) string {
+	if !state.IsAvailable {
+		return "Status: Unknown (usage data unavailable)\n"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Status: %s\n", state.Status)
+	fmt.Fprintf(&b, "Daily Cost: %s\n", models.DefaultCostFormat().Format(state.DailyCost))
+	fmt.Fprintf(&b, "Calls: %d\n", state.DailyCalls)
+	fmt.Fprintf(&b, "Tokens: %d\n", state.DailyCount)
+	fmt.Fprintf(&b, "Last Update: %s\n", state.LastUpdate.Format("2006-01-02 15:04:05"))
+	return b.String()
+}
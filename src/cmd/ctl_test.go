@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/internal/control"
+	"cc-dailyuse-bar/src/models"
+	"cc-dailyuse-bar/src/services"
+)
+
+type stubControl struct {
+	quit chan struct{}
+}
+
+func (s *stubControl) Status() (*models.UsageState, error) {
+	return &models.UsageState{DailyCost: 12.5, DailyCount: 4200, Status: models.Yellow, IsAvailable: true}, nil
+}
+func (s *stubControl) Refresh() (*models.UsageState, error)    { return s.Status() }
+func (s *stubControl) ReloadConfig() error                     { return nil }
+func (s *stubControl) SetThresholds(yellow, red float64) error { return nil }
+func (s *stubControl) Quit()                                   { close(s.quit) }
+
+// startStubControl serves a stubControl on a short socket path (Unix socket
+// paths are length-limited, which t.TempDir() can exceed).
+func startStubControl(t *testing.T) (string, *stubControl) {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "ccdb")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "control.sock")
+	stub := &stubControl{quit: make(chan struct{})}
+	server := control.NewServer(path, stub)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() { server.Close() })
+	return path, stub
+}
+
+func executeWithOutput(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	savedSocket, savedJSON := ctlSocket, ctlJSON
+	savedStop, savedControl := stopMode, controlSocket
+	t.Cleanup(func() {
+		ctlSocket, ctlJSON = savedSocket, savedJSON
+		stopMode, controlSocket = savedStop, savedControl
+		RootCmd.SetArgs(nil)
+		RootCmd.SetOut(nil)
+	})
+
+	var out bytes.Buffer
+	RootCmd.SetOut(&out)
+	RootCmd.SetArgs(args)
+	err := RootCmd.Execute()
+	return out.String(), err
+}
+
+func TestCtlCmd_Status(t *testing.T) {
+	path, _ := startStubControl(t)
+
+	out, err := executeWithOutput(t, "ctl", "status", "--socket", path)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Status: High")
+	assert.Contains(t, out, "Daily Cost: $12.50")
+	assert.Contains(t, out, "Tokens: 4200")
+}
+
+func TestCtlCmd_ErrorResponse(t *testing.T) {
+	path, _ := startStubControl(t)
+
+	_, err := executeWithOutput(t, "ctl", "set-threshold", "5", "--socket", path)
+	assert.ErrorContains(t, err, "two arguments")
+}
+
+func TestCtlCmd_NoInstance(t *testing.T) {
+	_, err := executeWithOutput(t, "ctl", "status", "--socket", filepath.Join(t.TempDir(), "none.sock"))
+	assert.ErrorContains(t, err, "no running instance")
+}
+
+func TestRunCmd_Stop(t *testing.T) {
+	path, stub := startStubControl(t)
+
+	out, err := executeWithOutput(t, "run", "--stop", "--control-socket", path)
+	require.NoError(t, err)
+	assert.Contains(t, out, "shutting down")
+	<-stub.quit
+}
+
+func TestDaemonControl_SetThresholdsValidates(t *testing.T) {
+	config := models.ConfigDefaults()
+	d := &daemonControl{config: models.NewLiveConfig(config), usageService: services.NewUsageService(config)}
+
+	err := d.SetThresholds(20, 10)
+	assert.Error(t, err)
+	assert.Same(t, config, d.config.Get(), "invalid thresholds are not applied")
+	assert.Equal(t, 10.0, config.YellowThreshold)
+}
+
+func TestDashboardSource_DailyTotals(t *testing.T) {
+	config := models.ConfigDefaults()
+	d := &dashboardSource{config: models.NewLiveConfig(config)}
+	totals, err := d.DailyTotals(7)
+	require.NoError(t, err)
+	assert.Nil(t, totals, "no history in demo mode")
+
+	history := services.NewHistoryServiceAt(filepath.Join(t.TempDir(), "history.jsonl"))
+	require.NoError(t, history.Record(&models.UsageState{DailyCost: 3.5, DailyCount: 40, Status: models.Green, IsAvailable: true}))
+	d.history = history
+
+	totals, err = d.DailyTotals(7)
+	require.NoError(t, err)
+	require.Len(t, totals, 7)
+	today := totals[6]
+	assert.True(t, today.Tracked)
+	assert.Equal(t, 3.5, today.Cost)
+	assert.False(t, totals[0].Tracked)
+	assert.Equal(t, *config, d.Config())
+}
@@ -1,13 +1,20 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"cc-dailyuse-bar/src/internal/control"
 	"cc-dailyuse-bar/src/models"
 	"cc-dailyuse-bar/src/services"
 )
@@ -15,10 +22,22 @@ import (
 var doctorCmd = &cobra.Command{
 	Use:     "doctor",
 	Aliases: []string{"status"},
-	Short:   "Check the health of the application and dependencies",
+	Short:   "Check the health of the application and dependencies, and fix common problems",
+	Long: `Check the config, the app's directories, the control socket, and ccusage,
+then run the self-test (one fetch and parse, as run --check does).
+
+Problems with a known fix are offered one at a time when run from a
+terminal: a config that won't load or has invalid thresholds, directories
+other users can write to, a control socket left behind by a crash, a
+claude_data_dir that no longer exists, and a ccusage_path that no longer
+resolves when ccusage, bunx, or npx is on PATH. A config is only reset
+to defaults when the file doesn't parse. --yes applies every fix without
+asking; --fix applies only the directory permission fixes.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Fprintln(cmd.OutOrStdout(), "Running health checks...")
+		out := cmd.OutOrStdout()
+		fmt.Fprintln(out, "Running health checks...")
 		hasWarnings := false
+		fixer := newDoctorFixer(cmd)
 
 		svc := services.NewConfigService()
 		if cfgFile != "" {
@@ -27,57 +46,334 @@ var doctorCmd = &cobra.Command{
 
 		// 1. Config Check
 		config, err := svc.Load()
+		if err != nil {
+			config, err = repairConfig(out, svc, fixer, err)
+		}
 		if err != nil {
 			return fmt.Errorf("config: failed to load configuration from %q; fix the file or run 'cc-dailyuse-bar config init --force' to reset to defaults: %w",
 				svc.GetConfigPath(), err)
 		}
-		fmt.Fprintf(cmd.OutOrStdout(), "Config: Valid (loaded from %s)\n", svc.GetConfigPath())
+		fmt.Fprintf(out, "Config: Valid (loaded from %s)\n", svc.GetConfigPath())
 
-		// 2. Binary Check
-		path, err := exec.LookPath(config.CCUsagePath)
-		if err != nil {
-			return fmt.Errorf("binary: 'ccusage' not found at %q; install ccusage or update 'ccusage_path' in config", config.CCUsagePath)
+		// 2. Directory permissions
+		if checkDirPermissions(out, services.DefaultPaths().WithConfigFile(cfgFile), fixer) {
+			hasWarnings = true
+		}
+
+		// 3. A control socket left by a crashed instance
+		if checkControlSocket(out, control.DefaultSocketPath(), fixer) {
+			hasWarnings = true
 		}
 
-		// On non-Windows, verify the file is executable via permission bits.
-		// On Windows, executability is determined by file extension and PATHEXT,
-		// so LookPath success is sufficient.
-		if runtime.GOOS != "windows" {
-			info, statErr := os.Stat(path)
-			if statErr != nil {
-				return fmt.Errorf("binary: '%s' is not accessible: %w", path, statErr)
+		// 4. A pinned Claude data directory that's gone
+		if checkClaudeDataDir(out, svc, config, fixer) {
+			hasWarnings = true
+		}
+
+		// 5. Binary Check. For runner commands ("bunx ccusage") this checks
+		// the runner; the self-test below proves ccusage itself resolves.
+		path, err := findCCUsageBinary(config.CCUsagePath)
+		if err != nil {
+			if !repairCCUsagePath(out, svc, config, fixer) {
+				return err
 			}
-			if info.Mode()&0111 == 0 {
-				return fmt.Errorf("binary: '%s' is not executable", path)
+			if path, err = findCCUsageBinary(config.CCUsagePath); err != nil {
+				return err
 			}
 		}
-		fmt.Fprintf(cmd.OutOrStdout(), "Binary: Found at '%s'\n", path)
+		fmt.Fprintf(out, "Binary: Found at '%s'\n", path)
 
-		// 3. Connectivity Check (One-shot poll)
-		fmt.Fprintf(cmd.OutOrStdout(), "Connectivity: Testing API connection (timeout: %ds)...\n", config.CmdTimeout)
-		usageService := services.NewUsageService(config)
-
-		state, err := usageService.UpdateUsage()
+		// 6. Self-test: one fetch and parse
+		fmt.Fprintf(out, "Connectivity: Testing API connection (timeout: %ds)...\n", config.CmdTimeout)
+		result, err := services.NewUsageService(config).SelfCheck()
 		if err != nil {
 			return fmt.Errorf("connectivity: failed to fetch usage data: %w", err)
 		}
-
-		if state.Status == models.Unknown && !state.IsAvailable {
-			fmt.Fprintln(cmd.OutOrStdout(), "Connectivity: Data unavailable (API returned no data)")
-			hasWarnings = true
+		if result.TodayFound {
+			fmt.Fprintf(out, "Connectivity: Success! (%d daily entries; today %s, %d tokens)\n",
+				result.Entries, config.CostFormat().Format(result.Today.TotalCost), result.Today.TotalTokens)
 		} else {
-			fmt.Fprintf(cmd.OutOrStdout(), "Connectivity: Success! (Cost: $%.2f, Count: %d)\n", state.DailyCost, state.DailyCount)
+			fmt.Fprintf(out, "Connectivity: Success! (%d daily entries, no usage today)\n", result.Entries)
+		}
+		if result.Sources != nil {
+			fmt.Fprintf(out, "Sources: %s\n", services.FormatSourceBreakdown(result.Sources, config.CostFormat()))
+		}
+		if result.Overlap != nil {
+			fmt.Fprintf(out, "Data: Warning: %s\n", result.Overlap.Warning())
+			hasWarnings = true
 		}
 
 		if hasWarnings {
-			fmt.Fprintln(cmd.OutOrStdout(), "\nSome checks had warnings.")
+			fmt.Fprintln(out, "\nSome checks had warnings.")
 		} else {
-			fmt.Fprintln(cmd.OutOrStdout(), "\nAll checks passed!")
+			fmt.Fprintln(out, "\nAll checks passed!")
 		}
 		return nil
 	},
 }
 
+// findCCUsageBinary resolves ccusage_path's executable, checking it can
+// be run.
+func findCCUsageBinary(ccusagePath string) (string, error) {
+	bin, _, err := services.SplitCCUsageCommand(ccusagePath)
+	if err != nil {
+		return "", fmt.Errorf("binary: invalid ccusage_path %q: %w", ccusagePath, err)
+	}
+	path, err := exec.LookPath(bin)
+	if err != nil {
+		return "", fmt.Errorf("binary: %q not found (ccusage_path %q); install ccusage or update 'ccusage_path' in config", bin, ccusagePath)
+	}
+
+	// On non-Windows, verify the file is executable via permission bits.
+	// On Windows, executability is determined by file extension and PATHEXT,
+	// so LookPath success is sufficient.
+	if runtime.GOOS != "windows" {
+		info, statErr := os.Stat(path)
+		if statErr != nil {
+			return "", fmt.Errorf("binary: '%s' is not accessible: %w", path, statErr)
+		}
+		if info.Mode()&0111 == 0 {
+			return "", fmt.Errorf("binary: '%s' is not executable", path)
+		}
+	}
+	return path, nil
+}
+
+// doctorYes applies every fix doctor offers without asking; doctorFix
+// only the directory permission fixes.
+var doctorYes, doctorFix bool
+
+// doctorFixer decides whether to apply each fix doctor finds: all of them
+// with --yes, the ones the user accepts when stdin is a terminal, and none
+// otherwise. permissions applies directory permission fixes regardless.
+type doctorFixer struct {
+	out         io.Writer
+	in          *bufio.Reader // nil when there's no one to ask
+	yes         bool
+	permissions bool
+}
+
+func newDoctorFixer(cmd *cobra.Command) *doctorFixer {
+	fixer := &doctorFixer{out: cmd.OutOrStdout(), yes: doctorYes, permissions: doctorFix}
+	in := cmd.InOrStdin()
+	if file, ok := in.(*os.File); ok {
+		if info, err := file.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return fixer
+		}
+	}
+	fixer.in = bufio.NewReader(in)
+	return fixer
+}
+
+// confirm reports whether to go ahead with action, e.g. "Remove the stale
+// control socket".
+func (f *doctorFixer) confirm(action string) bool {
+	if f.yes {
+		fmt.Fprintf(f.out, "  Fixing: %s\n", action)
+		return true
+	}
+	if f.in == nil {
+		return false
+	}
+	fmt.Fprintf(f.out, "  %s? [y/N] ", action)
+	line, _ := f.in.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// confirmPermissions is confirm for a directory permission fix, which
+// --fix applies without asking.
+func (f *doctorFixer) confirmPermissions(action string) bool {
+	if f.permissions {
+		fmt.Fprintf(f.out, "  Fixing: %s\n", action)
+		return true
+	}
+	return f.confirm(action)
+}
+
+// doctorHint is how to apply the fixes doctor can make.
+const doctorHint = "run 'cc-dailyuse-bar doctor --yes' to fix it"
+
+// repairConfig offers fixes for a config that failed to load with loadErr:
+// default thresholds when those are what's wrong, and a reset to defaults,
+// after backing the file up, when it doesn't parse. It returns the config
+// loaded after a fix, or loadErr.
+func repairConfig(out io.Writer, svc *services.ConfigService, fixer *doctorFixer, loadErr error) (*models.Config, error) {
+	fmt.Fprintf(out, "Config: Error: %v\n", loadErr)
+	defaults := models.ConfigDefaults()
+
+	if config, err := svc.LoadUnchecked(); err == nil && (config.YellowThreshold <= 0 || config.RedThreshold <= config.YellowThreshold) {
+		config.YellowThreshold, config.RedThreshold = defaults.YellowThreshold, defaults.RedThreshold
+		if config.Validate() == nil && fixer.confirm(fmt.Sprintf("Reset yellow_threshold and red_threshold to %.2f and %.2f",
+			defaults.YellowThreshold, defaults.RedThreshold)) {
+			if err := svc.Save(config); err != nil {
+				return nil, err
+			}
+			fmt.Fprintln(out, "Config: Fixed thresholds")
+			return svc.Load()
+		}
+	}
+
+	// Resetting only helps when the base file itself won't parse: not for
+	// a broken local overlay, a secret that can't be decrypted, or a file
+	// that can't be read right now.
+	path := svc.GetConfigPath()
+	if !unparseableConfig(path) || !fixer.confirm("Back up "+path+" and reset it to defaults") {
+		return nil, loadErr
+	}
+	backup, err := backupPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Rename(path, backup); err != nil {
+		return nil, fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+	if err := svc.Save(defaults); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(out, "Config: Reset to defaults; the old file is at %s\n", backup)
+	return svc.Load()
+}
+
+// unparseableConfig reports whether the config file at path can be read
+// but not parsed.
+func unparseableConfig(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var config models.Config
+	return services.DecodeConfig(services.ConfigFormatFor(path), data, &config) != nil
+}
+
+// backupPath returns the first of path.bak, path.bak.1, path.bak.2, ...
+// that doesn't exist yet, so an earlier backup is never overwritten.
+func backupPath(path string) (string, error) {
+	for i := 0; i < 100; i++ {
+		backup := path + ".bak"
+		if i > 0 {
+			backup += "." + strconv.Itoa(i)
+		}
+		if _, err := os.Lstat(backup); errors.Is(err, os.ErrNotExist) {
+			return backup, nil
+		}
+	}
+	return "", fmt.Errorf("failed to back up %s: too many backups already; remove some", path)
+}
+
+// checkDirPermissions reports the app directories other users could
+// tamper with, fixing the ones it can when fixer agrees. It returns
+// whether any problem remains.
+func checkDirPermissions(out io.Writer, paths *services.PathResolver, fixer *doctorFixer) bool {
+	remaining := false
+	for _, issue := range paths.CheckPermissions() {
+		if !issue.Fixable {
+			fmt.Fprintf(out, "Directories: Warning: %s directory %s is %s (%04o); change its owner to you with chown, or remove it\n",
+				issue.Dir.Role, issue.Dir.Path, issue.Problem, issue.Mode)
+			remaining = true
+			continue
+		}
+		fmt.Fprintf(out, "Directories: Warning: %s directory %s is %s (%04o)\n", issue.Dir.Role, issue.Dir.Path, issue.Problem, issue.Mode)
+		if !fixer.confirmPermissions("Remove group and world write access from " + issue.Dir.Path) {
+			fmt.Fprintf(out, "Directories: Not fixed; %s\n", doctorHint)
+			remaining = true
+			continue
+		}
+		if err := issue.Fix(); err != nil {
+			fmt.Fprintf(out, "Directories: Warning: could not fix %s directory %s: %v\n", issue.Dir.Role, issue.Dir.Path, err)
+			remaining = true
+			continue
+		}
+		fmt.Fprintf(out, "Directories: Fixed %s directory %s\n", issue.Dir.Role, issue.Dir.Path)
+	}
+	return remaining
+}
+
+// checkControlSocket reports a control socket with nothing listening on
+// it, which makes ctl and run --stop fail until the tray starts again,
+// and offers to remove it. It returns whether the problem remains.
+func checkControlSocket(out io.Writer, path string, fixer *doctorFixer) bool {
+	if !control.IsStale(path) {
+		return false
+	}
+	fmt.Fprintf(out, "Control: Warning: stale control socket %s from an instance that didn't exit cleanly\n", path)
+	if !fixer.confirm("Remove the stale control socket") {
+		fmt.Fprintf(out, "Control: Not fixed; %s\n", doctorHint)
+		return true
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(out, "Control: Warning: could not remove %s: %v\n", path, err)
+		return true
+	}
+	fmt.Fprintln(out, "Control: Removed the stale control socket")
+	return false
+}
+
+// checkClaudeDataDir reports a claude_data_dir that no longer exists, so
+// ccusage finds no usage, and offers to clear it. It returns whether the
+// problem remains.
+func checkClaudeDataDir(out io.Writer, svc *services.ConfigService, config *models.Config, fixer *doctorFixer) bool {
+	var missing []string
+	for _, dir := range strings.Split(config.ClaudeDataDir, ",") {
+		dir = strings.TrimSpace(dir)
+		if dir == "" {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				dir = filepath.Join(home, rest)
+			}
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			missing = append(missing, dir)
+		}
+	}
+	if len(missing) == 0 {
+		return false
+	}
+	fmt.Fprintf(out, "Data: Warning: claude_data_dir %s doesn't exist, so ccusage finds no usage there\n", strings.Join(missing, ", "))
+	if !fixer.confirm("Clear claude_data_dir so ccusage looks in Claude's default locations") {
+		fmt.Fprintf(out, "Data: Not fixed; %s\n", doctorHint)
+		return true
+	}
+	config.ClaudeDataDir = ""
+	if err := svc.Save(config); err != nil {
+		fmt.Fprintf(out, "Data: Warning: could not save config: %v\n", err)
+		return true
+	}
+	fmt.Fprintln(out, "Data: Cleared claude_data_dir")
+	return false
+}
+
+// repairCCUsagePath offers to point ccusage_path at a ccusage found on
+// PATH, or at bunx or npx running it, when the configured one is missing.
+// It returns whether config now has a new ccusage_path.
+func repairCCUsagePath(out io.Writer, svc *services.ConfigService, config *models.Config, fixer *doctorFixer) bool {
+	found := services.FindCCUsage()
+	if found == "" || found == config.CCUsagePath {
+		return false
+	}
+	fmt.Fprintf(out, "Binary: Warning: ccusage_path %q doesn't resolve\n", config.CCUsagePath)
+	if !fixer.confirm(fmt.Sprintf("Set ccusage_path to %q", found)) {
+		return false
+	}
+	previous := config.CCUsagePath
+	config.CCUsagePath = found
+	if err := svc.Save(config); err != nil {
+		config.CCUsagePath = previous
+		fmt.Fprintf(out, "Binary: Warning: could not save config: %v\n", err)
+		return false
+	}
+	fmt.Fprintf(out, "Binary: Set ccusage_path to %q\n", found)
+	return true
+}
+
 func init() {
+	doctorCmd.Flags().BoolVarP(&doctorYes, "yes", "y", false, "Apply every fix without asking")
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Remove group and world write access from the app's directories without asking")
 	RootCmd.AddCommand(doctorCmd)
 }
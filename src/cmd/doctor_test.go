@@ -1,14 +1,20 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+	"cc-dailyuse-bar/src/services"
 )
 
 func TestDoctorCmd_InvalidConfig(t *testing.T) {
@@ -101,3 +107,175 @@ func TestDoctorCmd_BinaryNotExecutable(t *testing.T) {
 	// the explicit mode-bits check in doctor.go is a defense-in-depth fallback.
 	assert.Contains(t, err.Error(), "binary")
 }
+
+func TestCheckDirPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits don't apply on Windows")
+	}
+	paths := services.NewPathResolverAt(t.TempDir())
+	require.NoError(t, os.MkdirAll(paths.DataDir(), 0o700))
+	require.NoError(t, os.Chmod(paths.DataDir(), 0o777))
+
+	var out bytes.Buffer
+	assert.True(t, checkDirPermissions(&out, paths, &doctorFixer{out: &out}))
+	assert.Contains(t, out.String(), "data directory "+paths.DataDir()+" is writable by group and others (0777)")
+	assert.Contains(t, out.String(), "Directories: Not fixed; run 'cc-dailyuse-bar doctor --yes'")
+
+	out.Reset()
+	assert.False(t, checkDirPermissions(&out, paths, &doctorFixer{out: &out, yes: true}))
+	assert.Contains(t, out.String(), "Directories: Fixed data directory")
+	assert.False(t, checkDirPermissions(&out, paths, &doctorFixer{out: &out}))
+
+	require.NoError(t, os.Chmod(paths.DataDir(), 0o777))
+	fixOnly := &doctorFixer{out: &out, permissions: true}
+	assert.False(t, checkDirPermissions(&out, paths, fixOnly), "--fix applies permission fixes")
+	assert.False(t, fixOnly.confirm("Remove the stale control socket"), "but nothing else")
+}
+
+// answeringFixer asks the questions doctor would and answers them from
+// answers, one per line.
+func answeringFixer(out *bytes.Buffer, answers string) *doctorFixer {
+	return &doctorFixer{out: out, in: bufio.NewReader(strings.NewReader(answers))}
+}
+
+func TestDoctorFixer_Confirm(t *testing.T) {
+	var out bytes.Buffer
+	fixer := answeringFixer(&out, "y\nno\n\n")
+	assert.True(t, fixer.confirm("Remove it"))
+	assert.False(t, fixer.confirm("Remove it"))
+	assert.False(t, fixer.confirm("Remove it"))
+	assert.False(t, fixer.confirm("Remove it"), "no answer left")
+	assert.Equal(t, 4, strings.Count(out.String(), "  Remove it? [y/N] "))
+
+	assert.False(t, (&doctorFixer{out: &out}).confirm("Remove it"), "nobody to ask")
+	assert.True(t, (&doctorFixer{out: &out, yes: true}).confirm("Remove it"))
+}
+
+func TestCheckControlSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "ccdb")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "control.sock")
+
+	var out bytes.Buffer
+	assert.False(t, checkControlSocket(&out, path, &doctorFixer{out: &out}), "no socket")
+
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+	assert.True(t, checkControlSocket(&out, path, answeringFixer(&out, "n\n")))
+	assert.FileExists(t, path)
+
+	assert.False(t, checkControlSocket(&out, path, answeringFixer(&out, "y\n")))
+	assert.NoFileExists(t, path)
+	assert.Contains(t, out.String(), "Control: Removed the stale control socket")
+}
+
+func TestCheckClaudeDataDir(t *testing.T) {
+	dir := t.TempDir()
+	svc := services.NewConfigService()
+	svc.SetConfigPath(filepath.Join(dir, "config.yaml"))
+	config := models.ConfigDefaults()
+
+	var out bytes.Buffer
+	assert.False(t, checkClaudeDataDir(&out, svc, config, &doctorFixer{out: &out}), "unset")
+	config.ClaudeDataDir = dir
+	assert.False(t, checkClaudeDataDir(&out, svc, config, &doctorFixer{out: &out}), "exists")
+
+	config.ClaudeDataDir = filepath.Join(dir, "gone")
+	assert.True(t, checkClaudeDataDir(&out, svc, config, &doctorFixer{out: &out}))
+	assert.False(t, checkClaudeDataDir(&out, svc, config, &doctorFixer{out: &out, yes: true}))
+	loaded, err := svc.Load()
+	require.NoError(t, err)
+	assert.Empty(t, loaded.ClaudeDataDir)
+}
+
+func TestRepairConfig_Thresholds(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := writeBinaryConfig(t, dir, "/usr/local/bin/ccusage")
+	data, err := os.ReadFile(cfgPath)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(cfgPath, []byte(strings.Replace(string(data), "red_threshold: 20.0", "red_threshold: 5.0", 1)), 0o644))
+
+	svc := services.NewConfigService()
+	svc.SetConfigPath(cfgPath)
+	_, loadErr := svc.Load()
+	require.Error(t, loadErr)
+
+	var out bytes.Buffer
+	_, err = repairConfig(&out, svc, &doctorFixer{out: &out}, loadErr)
+	assert.Equal(t, loadErr, err, "nothing fixed without a yes")
+
+	config, err := repairConfig(&out, svc, answeringFixer(&out, "y\n"), loadErr)
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, config.YellowThreshold)
+	assert.Equal(t, 20.0, config.RedThreshold)
+	assert.Equal(t, "/usr/local/bin/ccusage", config.CCUsagePath)
+	assert.NoFileExists(t, cfgPath+".bak")
+}
+
+func TestRepairConfig_ResetsUnparseableFile(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte("update_interval: [\n"), 0o644))
+	svc := services.NewConfigService()
+	svc.SetConfigPath(cfgPath)
+	_, loadErr := svc.Load()
+	require.Error(t, loadErr)
+
+	var out bytes.Buffer
+	config, err := repairConfig(&out, svc, &doctorFixer{out: &out, yes: true}, loadErr)
+	require.NoError(t, err)
+	assert.Equal(t, models.ConfigDefaults().UpdateInterval, config.UpdateInterval)
+	backup, err := os.ReadFile(cfgPath + ".bak")
+	require.NoError(t, err)
+	assert.Equal(t, "update_interval: [\n", string(backup))
+
+	require.NoError(t, os.WriteFile(cfgPath, []byte("update_interval: {\n"), 0o644))
+	_, loadErr = svc.Load()
+	require.Error(t, loadErr)
+	_, err = repairConfig(&out, svc, &doctorFixer{out: &out, yes: true}, loadErr)
+	require.NoError(t, err)
+	backup, err = os.ReadFile(cfgPath + ".bak.1")
+	require.NoError(t, err)
+	assert.Equal(t, "update_interval: {\n", string(backup), "an earlier backup isn't overwritten")
+	backup, err = os.ReadFile(cfgPath + ".bak")
+	require.NoError(t, err)
+	assert.Equal(t, "update_interval: [\n", string(backup))
+}
+
+func TestRepairConfig_KeepsParseableFile(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := writeBinaryConfig(t, dir, "/usr/local/bin/ccusage")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.local.yaml"), []byte("update_interval: [\n"), 0o644))
+	svc := services.NewConfigService()
+	svc.SetConfigPath(cfgPath)
+	_, loadErr := svc.Load()
+	require.Error(t, loadErr, "the overlay is broken")
+
+	var out bytes.Buffer
+	_, err := repairConfig(&out, svc, &doctorFixer{out: &out, yes: true}, loadErr)
+	assert.Equal(t, loadErr, err)
+	assert.NoFileExists(t, cfgPath+".bak", "the base file isn't what's wrong")
+	assert.NotContains(t, out.String(), "reset it to defaults")
+}
+
+func TestRepairCCUsagePath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake executables are shell scripts")
+	}
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	svc := services.NewConfigService()
+	svc.SetConfigPath(filepath.Join(dir, "config.yaml"))
+	config := models.ConfigDefaults()
+	config.CCUsagePath = filepath.Join(dir, "old-node", "ccusage")
+
+	var out bytes.Buffer
+	assert.False(t, repairCCUsagePath(&out, svc, config, &doctorFixer{out: &out, yes: true}), "nothing to switch to")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bunx"), []byte("#!/bin/sh\n"), 0o755))
+	assert.False(t, repairCCUsagePath(&out, svc, config, &doctorFixer{out: &out}))
+	assert.True(t, repairCCUsagePath(&out, svc, config, &doctorFixer{out: &out, yes: true}))
+	assert.Equal(t, "bunx ccusage", config.CCUsagePath)
+	loaded, err := svc.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "bunx ccusage", loaded.CCUsagePath)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import "errors"
+
+// Process exit codes. Anything other than these is reported as
+// ExitFailure; `run --check` uses the specific codes so provisioning
+// scripts can tell failure classes apart.
+const (
+	ExitOK      = 0
+	ExitFailure = 1 // Unclassified error
+	ExitConfig  = 2 // Config file unreadable or invalid
+	ExitBinary  = 3 // ccusage not found or not executable
+	ExitFetch   = 4 // ccusage ran but failed or timed out
+	ExitParse   = 5 // ccusage output was not the expected JSON
+)
+
+// exitError attaches a specific exit code to an error.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
+func withExitCode(code int, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+// exitCode maps a command error to the process exit status.
+func exitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var coded *exitError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	return ExitFailure
+}
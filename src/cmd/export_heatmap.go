@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/services"
+)
+
+var (
+	heatMapDays   int
+	heatMapFormat string
+	heatMapOutput string
+)
+
+// heatMapHistory opens the recorded usage history; tests replace it.
+var heatMapHistory = services.NewHistoryService
+
+var exportHeatMapCmd = &cobra.Command{
+	Use:   "export-heatmap",
+	Short: "Export tokens by weekday and hour as a heat map",
+	Long: `Write a weekday × hour heat map of the tokens used over the last days,
+built from the usage history the tray app records, to see when in the week
+usage peaks and schedule heavy agent jobs around it. The CSV has a row per
+weekday and a column per hour; the HTML page shades each cell and shows its
+tokens and cost on hover. The tray's Export submenu writes the same files.`,
+	Example: `  cc-dailyuse-bar export-heatmap --format html -o ~/heatmap.html
+  cc-dailyuse-bar export-heatmap --days 84 > heatmap.csv`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if heatMapDays < 1 || heatMapDays > 90 {
+			return lib.ValidationError("--days must be between 1 and 90, the history's retention")
+		}
+
+		configService := services.NewConfigService()
+		if cfgFile != "" {
+			configService.SetConfigPath(cfgFile)
+		}
+		config, err := configService.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		heatMap, err := heatMapHistory().HeatMap(heatMapDays)
+		if err != nil {
+			return err
+		}
+		data, err := services.RenderHeatMap(heatMap, heatMapFormat, config.CostFormat())
+		if err != nil {
+			return err
+		}
+
+		if heatMapOutput == "" || heatMapOutput == "-" {
+			_, err := cmd.OutOrStdout().Write(data)
+			return err
+		}
+		if err := lib.WriteFileAtomic(heatMapOutput, data); err != nil {
+			return lib.WrapError(err, lib.ErrCodeSystem, "failed to write heat map")
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %d days to %s\n", heatMapDays, heatMapOutput)
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(exportHeatMapCmd)
+	exportHeatMapCmd.Flags().IntVar(&heatMapDays, "days", services.DefaultHeatMapDays, "Number of days to include, ending today")
+	exportHeatMapCmd.Flags().StringVar(&heatMapFormat, "format", services.HeatMapCSV, "Output format: csv or html")
+	exportHeatMapCmd.Flags().StringVarP(&heatMapOutput, "output", "o", "", "Write to this file instead of stdout")
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+	"cc-dailyuse-bar/src/services"
+)
+
+func TestExportHeatMapCmd(t *testing.T) {
+	savedCfgFile, savedDays, savedFormat, savedOutput, savedHistory := cfgFile, heatMapDays, heatMapFormat, heatMapOutput, heatMapHistory
+	t.Cleanup(func() {
+		cfgFile, heatMapDays, heatMapFormat, heatMapOutput, heatMapHistory = savedCfgFile, savedDays, savedFormat, savedOutput, savedHistory
+	})
+
+	dir := t.TempDir()
+	historyPath := filepath.Join(dir, "history.jsonl")
+	heatMapHistory = func() *services.HistoryService { return services.NewHistoryServiceAt(historyPath) }
+	recorder := services.NewHistoryServiceAt(historyPath)
+	now := time.Now()
+	for _, tokens := range []int{100, 400} {
+		require.NoError(t, recorder.Record(&models.UsageState{IsAvailable: true, DailyCount: tokens, DailyCost: 1}))
+	}
+	cfgPath := writeBinaryConfig(t, dir, "ccusage")
+
+	out, err := executeWithOutput(t, "export-heatmap", "--config", cfgPath)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 8)
+	assert.True(t, strings.HasPrefix(lines[0], "weekday,00,01,"))
+	assert.Contains(t, out, now.Weekday().String()+",")
+
+	htmlPath := filepath.Join(dir, "heatmap.html")
+	_, err = executeWithOutput(t, "export-heatmap", "--config", cfgPath, "--format", "html", "-o", htmlPath)
+	require.NoError(t, err)
+	data, err := os.ReadFile(htmlPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "<title>Claude Code usage by hour</title>")
+
+	_, err = executeWithOutput(t, "export-heatmap", "--config", cfgPath, "--format", "png")
+	assert.Error(t, err)
+	_, err = executeWithOutput(t, "export-heatmap", "--config", cfgPath, "--format", "csv", "--days", "91")
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/services"
+)
+
+var (
+	icsDays   int
+	icsOutput string
+)
+
+var exportICSCmd = &cobra.Command{
+	Use:   "export-ics",
+	Short: "Export daily spend as an iCalendar feed",
+	Long: `Write an .ics calendar with one all-day event per day whose title is that
+day's spend. Point a calendar app at the output file and regenerate it
+periodically (e.g. from cron); events are keyed by date, so re-imports
+update existing entries rather than duplicating them. With project_tags set,
+each event also lists that day's spend per tag.`,
+	Example: `  cc-dailyuse-bar export-ics --days 30 -o ~/Calendars/claude-spend.ics`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if icsDays < 1 || icsDays > 366 {
+			return lib.ValidationError("--days must be between 1 and 366")
+		}
+
+		configService := services.NewConfigService()
+		if cfgFile != "" {
+			configService.SetConfigPath(cfgFile)
+		}
+		config, err := configService.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		usageService := services.NewUsageService(config)
+		since := today.AddDate(0, 0, 1-icsDays)
+		days, err := usageService.DailyHistory(since, today)
+		if err != nil {
+			return err
+		}
+		tags, err := usageService.DailyTagCosts(since, today)
+		if err != nil {
+			return err
+		}
+
+		data, err := services.RenderICS(days, tags, config.CostFormat(), now)
+		if err != nil {
+			return err
+		}
+
+		if icsOutput == "" || icsOutput == "-" {
+			_, err := cmd.OutOrStdout().Write(data)
+			return err
+		}
+		if err := lib.WriteFileAtomic(icsOutput, data); err != nil {
+			return lib.WrapError(err, lib.ErrCodeSystem, "failed to write calendar file")
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %d days to %s\n", len(days), icsOutput)
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(exportICSCmd)
+	exportICSCmd.Flags().IntVar(&icsDays, "days", 90, "Number of days to include, ending today")
+	exportICSCmd.Flags().StringVarP(&icsOutput, "output", "o", "", "Write to this file instead of stdout")
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/internal/testhelpers"
+)
+
+func TestExportICSCmd(t *testing.T) {
+	testhelpers.RequireShell(t)
+	savedCfgFile, savedDays, savedOutput := cfgFile, icsDays, icsOutput
+	t.Cleanup(func() { cfgFile, icsDays, icsOutput = savedCfgFile, savedDays, savedOutput })
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "ccusage")
+	require.NoError(t, os.WriteFile(script, []byte(`#!/bin/bash
+echo '{"daily":[{"date":"2025-03-13","totalTokens":5,"totalCost":1.25},{"date":"2025-03-14","totalTokens":7,"totalCost":2}]}'
+`), 0o755))
+	cfgPath := writeBinaryConfig(t, dir, script)
+
+	out, err := executeWithOutput(t, "export-ics", "--config", cfgPath, "--days", "7")
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(out, "BEGIN:VEVENT"))
+	assert.Contains(t, out, "SUMMARY:Claude Code $1.25")
+
+	icsPath := filepath.Join(dir, "spend.ics")
+	_, err = executeWithOutput(t, "export-ics", "--config", cfgPath, "-o", icsPath)
+	require.NoError(t, err)
+	data, err := os.ReadFile(icsPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "SUMMARY:Claude Code $2.00")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3, "no temp files left behind")
+
+	_, err = executeWithOutput(t, "export-ics", "--config", cfgPath, "--days", "0")
+	assert.Error(t, err)
+}
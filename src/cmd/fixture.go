@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+	"cc-dailyuse-bar/src/services"
+)
+
+// fixtureClock pins "now" to midday on the fixture's date so the report's
+// days line up with today, yesterday, and the current week.
+type fixtureClock struct{ now time.Time }
+
+func (c fixtureClock) Now() time.Time { return c.now }
+
+// runFixture feeds a saved `ccusage daily --json` report through the same
+// parse → state → threshold → title pipeline as the tray and prints what
+// would be displayed. No ccusage process is started.
+func runFixture(cmd *cobra.Command, config *models.Config, path, date string) error {
+	out := cmd.OutOrStdout()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return lib.WrapError(err, lib.ErrCodeConfig, "failed to read fixture")
+	}
+	if date == "" {
+		if date, err = services.LatestReportDate(data); err != nil {
+			return withExitCode(ExitParse, err)
+		}
+	}
+	day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return lib.ValidationError(fmt.Sprintf("invalid --fixture-date %q (use YYYY-MM-DD)", date))
+	}
+
+	usageService := services.NewUsageService(config)
+	usageService.SetClock(fixtureClock{now: day.Add(12 * time.Hour)})
+	state, applyErr := usageService.ApplyFixture(data)
+
+	format := config.CostFormat()
+	fmt.Fprintf(out, "Fixture: %s (as of %s)\n", path, date)
+	if !state.IsAvailable {
+		fmt.Fprintf(out, "Title:   %s\n", models.FormatUnknownTitle(config))
+	} else {
+		fmt.Fprintf(out, "Title:   %s\n", models.FormatTitle(state, config))
+		status := state.Status.String()
+		if state.Level != "" {
+			status += fmt.Sprintf(" (level %q)", state.Level)
+		}
+		if state.Quiet {
+			status += " (quiet)"
+		}
+		fmt.Fprintf(out, "Status:  %s\n", status)
+		fmt.Fprintf(out, "Cost:    %s\n", format.Format(state.DailyCost))
+		fmt.Fprintf(out, "Tokens:  %d\n", state.DailyCount)
+		fmt.Fprintf(out, "Week:    %s\n", format.Format(state.WeeklyCost))
+		for _, alert := range state.ModelAlerts {
+			fmt.Fprintf(out, "Model:   %s %s (limit %s)\n",
+				alert.Pattern, format.Format(alert.Cost), format.Format(alert.Threshold))
+		}
+	}
+	if applyErr != nil {
+		fmt.Fprintf(out, "Result:  %v\n", applyErr)
+		if !state.IsAvailable {
+			return withExitCode(ExitParse, applyErr)
+		}
+	}
+	return nil
+}
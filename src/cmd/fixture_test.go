@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunFixture(t *testing.T) {
+	savedPath, savedDate := fixturePath, fixtureDate
+	t.Cleanup(func() {
+		fixturePath, fixtureDate = savedPath, savedDate
+		for _, name := range []string{"red-threshold", "yellow-threshold"} {
+			flag := runCmd.Flags().Lookup(name)
+			_ = flag.Value.Set(flag.DefValue)
+			flag.Changed = false
+		}
+	})
+
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`ccusage_path: ccusage
+update_interval: 30
+yellow_threshold: 10
+red_threshold: 20
+debug_level: INFO
+cache_window: 10
+cmd_timeout: 30
+model_thresholds:
+  opus: 5
+`), 0o644))
+	fixture := filepath.Join(dir, "ccusage.json")
+	require.NoError(t, os.WriteFile(fixture, []byte(`{"daily":[
+  {"date":"2025-03-13","totalTokens":10,"totalCost":4},
+  {"date":"2025-03-14","totalTokens":1234,"totalCost":12.345,
+   "modelBreakdowns":[{"modelName":"claude-opus-4","cost":8}]}
+]}`), 0o644))
+
+	out, err := executeWithOutput(t, "run", "--config", cfgPath, "--fixture", fixture)
+	require.NoError(t, err)
+	assert.Contains(t, out, "as of 2025-03-14")
+	assert.Contains(t, out, "Title:   CC 🟡 $12.35")
+	assert.Contains(t, out, "Status:  High")
+	assert.Contains(t, out, "Tokens:  1234")
+	assert.Contains(t, out, "Week:    $16.35")
+	assert.Contains(t, out, "Model:   opus $8.00 (limit $5.00)")
+
+	out, err = executeWithOutput(t, "run", "--config", cfgPath, "--fixture", fixture,
+		"--fixture-date", "2025-03-13", "--red-threshold", "3.5", "--yellow-threshold", "1")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Title:   CC 🔴 $4.00", "flag overrides apply")
+
+	out, err = executeWithOutput(t, "run", "--config", cfgPath, "--fixture", fixture, "--fixture-date", "2025-03-20")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Title:   CC 🟢 $0.00")
+	assert.Contains(t, out, "no data for today")
+
+	require.NoError(t, os.WriteFile(fixture, []byte("not json"), 0o644))
+	out, err = executeWithOutput(t, "run", "--config", cfgPath, "--fixture", fixture, "--fixture-date", "2025-03-14")
+	assert.Equal(t, ExitParse, exitCode(err))
+	assert.Contains(t, out, "CC ⚪️ Unknown")
+}
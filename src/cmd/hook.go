@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cc-dailyuse-bar/src/internal/control"
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/services"
+)
+
+// hookNotifyTimeout bounds how long the hook may hold up Claude Code when
+// the instance is busy or wedged.
+const hookNotifyTimeout = 2 * time.Second
+
+var (
+	hookSettings string
+	hookEvents   []string
+	hookBinPath  string
+	hookSocket   string
+)
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Refresh the tray from Claude Code hooks",
+	Long: `Install a Claude Code hook that tells the running instance to refresh
+each time Claude finishes responding, so the cost updates within seconds
+instead of at the next poll.
+
+The hook runs "cc-dailyuse-bar hook notify", which asks the instance to
+refresh over its control socket and returns at once; it never fails, so
+Claude Code isn't held up or shown errors when the tray isn't running.`,
+	Example: `  cc-dailyuse-bar hook install
+  cc-dailyuse-bar hook install --event Stop --event SubagentStop
+  cc-dailyuse-bar hook uninstall`,
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Add the refresh hook to Claude Code's settings",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := hookSettingsPath()
+		if err != nil {
+			return err
+		}
+		binPath, err := hookBinary(hookBinPath)
+		if err != nil {
+			return err
+		}
+		if len(hookEvents) == 0 {
+			return lib.ValidationError("--event needs at least one Claude Code hook event")
+		}
+		command := services.HookCommand(binPath)
+		if err := services.InstallClaudeHook(path, hookEvents, command); err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Hook installed in %s\n", path)
+		fmt.Fprintf(out, "Events:  %s\n", strings.Join(hookEvents, ", "))
+		fmt.Fprintf(out, "Command: %s\n", command)
+		fmt.Fprintln(out, "Claude Code picks it up in new sessions. Remove it with `cc-dailyuse-bar hook uninstall`.")
+		return nil
+	},
+}
+
+var hookUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the refresh hook from Claude Code's settings",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := hookSettingsPath()
+		if err != nil {
+			return err
+		}
+		removed, err := services.UninstallClaudeHook(path)
+		if err != nil {
+			return err
+		}
+		if removed {
+			fmt.Fprintf(cmd.OutOrStdout(), "Hook removed from %s\n", path)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "No hook installed in %s\n", path)
+		}
+		return nil
+	},
+}
+
+var hookStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Report whether the refresh hook is installed",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := hookSettingsPath()
+		if err != nil {
+			return err
+		}
+		events, err := services.ClaudeHookEvents(path)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "Not installed in %s\n", path)
+			return nil
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Installed in %s on %s\n", path, strings.Join(events, ", "))
+		return nil
+	},
+}
+
+var hookNotifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Ask the running instance to refresh (run by the hook)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		resp, err := control.Send(hookSocket, control.Request{Command: control.CmdRefreshAsync}, hookNotifyTimeout)
+		logger := lib.NewLogger("hook")
+		if err != nil {
+			logger.Debug("No instance to notify", map[string]interface{}{
+				"error": err.Error(),
+			})
+			return nil
+		}
+		if !resp.OK {
+			logger.Debug("Refresh request refused", map[string]interface{}{
+				"error": resp.Error,
+			})
+		}
+		return nil
+	},
+}
+
+func init() {
+	hookCmd.PersistentFlags().StringVar(&hookSettings, "settings", "", "Claude Code settings file (default: settings.json in CLAUDE_CONFIG_DIR or ~/.claude)")
+	hookInstallCmd.Flags().StringArrayVar(&hookEvents, "event", []string{services.DefaultHookEvent}, "Claude Code hook event to refresh on (repeatable)")
+	hookInstallCmd.Flags().StringVar(&hookBinPath, "bin-path", "", "Binary the hook runs (default: this one)")
+	hookNotifyCmd.Flags().StringVar(&hookSocket, "socket", control.DefaultSocketPath(), "Path to the control socket")
+
+	hookCmd.AddCommand(hookInstallCmd, hookUninstallCmd, hookStatusCmd, hookNotifyCmd)
+	RootCmd.AddCommand(hookCmd)
+}
+
+func hookSettingsPath() (string, error) {
+	if hookSettings != "" {
+		return hookSettings, nil
+	}
+	return services.ClaudeSettingsPath()
+}
+
+// hookBinary returns the absolute path of the binary the hook runs:
+// override when given, else this one with symlinks resolved.
+func hookBinary(override string) (string, error) {
+	if override != "" {
+		abs, err := filepath.Abs(override)
+		if err != nil {
+			return "", lib.WrapError(err, lib.ErrCodeSystem, "failed to resolve --bin-path")
+		}
+		return abs, nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return "", lib.WrapError(err, lib.ErrCodeSystem, "failed to get executable path")
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		return resolved, nil
+	}
+	return exe, nil
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHookCmd_InstallStatusUninstall(t *testing.T) {
+	settings := filepath.Join(t.TempDir(), "settings.json")
+
+	out, err := executeWithOutput(t, "hook", "install", "--settings", settings, "--bin-path", "/usr/local/bin/cc-dailyuse-bar")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Hook installed in "+settings)
+	assert.Contains(t, out, "Command: '/usr/local/bin/cc-dailyuse-bar' hook notify")
+
+	data, err := os.ReadFile(settings)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"Stop"`)
+
+	out, err = executeWithOutput(t, "hook", "status", "--settings", settings)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Installed in "+settings+" on Stop")
+
+	out, err = executeWithOutput(t, "hook", "uninstall", "--settings", settings)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Hook removed")
+
+	out, err = executeWithOutput(t, "hook", "status", "--settings", settings)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Not installed")
+}
+
+func TestHookCmd_Notify(t *testing.T) {
+	path, _ := startStubControl(t)
+	_, err := executeWithOutput(t, "hook", "notify", "--socket", path)
+	require.NoError(t, err)
+
+	_, err = executeWithOutput(t, "hook", "notify", "--socket", filepath.Join(t.TempDir(), "none.sock"))
+	assert.NoError(t, err, "never fails the Claude Code hook")
+}
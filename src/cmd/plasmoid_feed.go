@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"cc-dailyuse-bar/src/internal/control"
+	"cc-dailyuse-bar/src/models"
+	"cc-dailyuse-bar/src/services"
+)
+
+// runPlasmoidFeed polls like the tray but, instead of showing an icon,
+// rewrites the Plasma widget feed at path after every update, until
+// SIGINT, SIGTERM, or `run --stop`. It needs no GUI, so nogui builds
+// support it too.
+func runPlasmoidFeed(cmd *cobra.Command, config *models.Config, path string) error {
+	usageService, _ := newRunUsageService(config)
+	feed := services.NewPlasmoidFeedAt(path)
+
+	quit := make(chan struct{})
+	var quitOnce sync.Once
+	stop := func() { quitOnce.Do(func() { close(quit) }) }
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go func() {
+		select {
+		case sig := <-sigChan:
+			logger.Info("Received signal, shutting down gracefully", map[string]interface{}{
+				"signal": sig.String(),
+			})
+			stop()
+		case <-quit:
+		}
+	}()
+
+	configService := services.NewConfigService()
+	if cfgFile != "" {
+		configService.SetConfigPath(cfgFile)
+	}
+	live := models.NewLiveConfig(config)
+	reloader := &configReloader{
+		cmd:           cmd,
+		config:        live,
+		configService: configService,
+		usageService:  usageService,
+	}
+	controlServer := control.NewServer(controlSocket, &daemonControl{
+		config:       live,
+		usageService: usageService,
+		reloader:     reloader,
+		quit:         stop,
+	})
+	if err := controlServer.Start(); err != nil {
+		logger.Warn("Control socket unavailable", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	defer controlServer.Close()
+
+	// SIGHUP reloads the configuration file in place.
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	defer signal.Stop(hupChan)
+	go func() {
+		for {
+			select {
+			case <-hupChan:
+				logger.Info("Received SIGHUP, reloading configuration")
+				if err := reloader.Reload(); err == nil {
+					_, _ = usageService.Refresh()
+				}
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	logger.Info("Writing plasmoid feed", map[string]interface{}{
+		"path": feed.Path(),
+	})
+	return servePlasmoidFeed(usageService, live, feed, quit)
+}
+
+// servePlasmoidFeed writes an update to feed now and after every poll
+// until quit is closed, then removes it so the widget shows the feed as
+// stopped.
+func servePlasmoidFeed(usageService *services.UsageService, config *models.LiveConfig, feed *services.PlasmoidFeed, quit <-chan struct{}) error {
+	publish := func(state *models.UsageState) {
+		if state == nil {
+			return
+		}
+		if err := feed.Write(services.NewPlasmoidSnapshot(state, config.Get())); err != nil {
+			logger.Warn("Failed to write plasmoid feed", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+	if err := usageService.StartPolling(config.Get().UpdateInterval, publish); err != nil {
+		return err
+	}
+	_, _ = usageService.Refresh() // delivered to publish; failures still write an unavailable entry
+
+	<-quit
+	usageService.StopPolling()
+	if err := feed.Remove(); err != nil {
+		logger.Warn("Failed to remove plasmoid feed", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return nil
+}
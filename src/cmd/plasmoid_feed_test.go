@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/internal/testhelpers"
+	"cc-dailyuse-bar/src/models"
+	"cc-dailyuse-bar/src/services"
+)
+
+func TestServePlasmoidFeed(t *testing.T) {
+	testhelpers.RequireShell(t)
+	dir := t.TempDir()
+	script := filepath.Join(dir, "ccusage")
+	today := time.Now().Format("2006-01-02")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/bash\n"+
+		`echo '{"daily":[{"date":"`+today+`","totalTokens":90,"totalCost":12.5}]}'`+"\n"), 0o755))
+
+	config := models.ConfigDefaults()
+	config.CCUsagePath = script
+	config.YellowThreshold = 10
+	config.RedThreshold = 20
+	usageService := services.NewUsageService(config)
+	feed := services.NewPlasmoidFeedAt(filepath.Join(dir, "plasmoid.json"))
+
+	quit := make(chan struct{})
+	done := make(chan error, 1)
+	go func() { done <- servePlasmoidFeed(usageService, models.NewLiveConfig(config), feed, quit) }()
+
+	var got map[string]interface{}
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(feed.Path())
+		return err == nil && json.Unmarshal(data, &got) == nil
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.Equal(t, "yellow", got["status"])
+	assert.Equal(t, "data-warning", got["icon"])
+	assert.Equal(t, 12.5, got["daily_cost"])
+
+	close(quit)
+	require.NoError(t, <-done)
+	_, err := os.Stat(feed.Path())
+	assert.True(t, os.IsNotExist(err), "the feed is removed on exit")
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+	"cc-dailyuse-bar/src/services"
+)
+
+// Output formats for run --print.
+const (
+	printFormatText       = "text"
+	printFormatJSON       = "json"
+	printFormatJSONSchema = "json-schema"
+)
+
+// runPrint fetches today's usage once and prints it in format: readable
+// text or the versioned models.UsageOutput JSON. When ccusage fails the
+// output still goes to stdout, marked unavailable, and the command exits
+// with ExitFetch. The json-schema format is handled before the config is
+// loaded, by runPrintSchema.
+func runPrint(cmd *cobra.Command, config *models.Config, format string) error {
+	out := cmd.OutOrStdout()
+	if err := checkPrintFormat(format); err != nil {
+		return err
+	}
+
+	usageService := services.NewUsageService(config)
+	if demoMode {
+		usageService.SetDemoFeed(services.NewDemoFeed(config))
+	}
+	state, fetchErr := usageService.UpdateUsage()
+
+	if format == printFormatJSON {
+		data, err := json.MarshalIndent(models.NewUsageOutput(state, config, time.Now()), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(data))
+	} else {
+		fmt.Fprint(out, formatPrintText(state, config))
+	}
+
+	if !state.IsAvailable {
+		if fetchErr == nil {
+			fetchErr = lib.NewError(lib.ErrCodeCCUsage, "usage data unavailable")
+		}
+		return withExitCode(ExitFetch, lib.WrapError(fetchErr, lib.ErrCodeCCUsage, "failed to fetch usage"))
+	}
+	return nil
+}
+
+// runPrintSchema prints the JSON Schema for --format json.
+func runPrintSchema(cmd *cobra.Command) error {
+	_, err := cmd.OutOrStdout().Write(models.UsageOutputSchema())
+	return err
+}
+
+func checkPrintFormat(format string) error {
+	switch format {
+	case printFormatText, printFormatJSON, printFormatJSONSchema:
+		return nil
+	}
+	return lib.ValidationError(fmt.Sprintf("invalid --format %q (use %s, %s, or %s)",
+		format, printFormatText, printFormatJSON, printFormatJSONSchema))
+}
+
+func formatPrintText(state *models.UsageState, config *models.Config) string {
+	if !state.IsAvailable {
+		return fmt.Sprintf("Title:    %s\nStatus:   Unknown (usage data unavailable)\n", models.FormatUnknownTitle(config))
+	}
+	format := config.CostFormat()
+	status := state.Status.String()
+	if state.Level != "" {
+		status += fmt.Sprintf(" (level %q)", state.Level)
+	}
+	if state.Quiet {
+		status += " (quiet)"
+	}
+	return fmt.Sprintf("Title:    %s\nStatus:   %s\nToday:    %s, %d tokens, %d calls\nWeek:     %s\nMonth:    %s (forecast %s)\n",
+		models.FormatTitle(state, config), status,
+		format.Format(state.DailyCost), state.DailyCount, state.DailyCalls,
+		format.Format(state.WeeklyCost),
+		format.Format(state.MonthlyCost), format.Format(state.Forecast))
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/internal/testhelpers"
+	"cc-dailyuse-bar/src/models"
+)
+
+func resetPrintFlags(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		for _, name := range []string{"print", "format"} {
+			flag := runCmd.Flags().Lookup(name)
+			_ = flag.Value.Set(flag.DefValue)
+			flag.Changed = false
+		}
+	})
+}
+
+func TestRunPrint(t *testing.T) {
+	resetPrintFlags(t)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dir := t.TempDir()
+	today := time.Now().Format("2006-01-02")
+	report := fmt.Sprintf(`{"daily": [{"date": %q, "totalTokens": 4200, "totalCost": 12.5}]}`, today)
+	cfgPath := writeBinaryConfig(t, dir, testhelpers.WriteFakeCommand(t, dir, "ccusage", report+"\n", 0))
+
+	out, err := executeWithOutput(t, "run", "--config", cfgPath, "--print")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Title:    CC 🟡 $12.50")
+	assert.Contains(t, out, "Status:   High")
+	assert.Contains(t, out, "Today:    $12.50, 4200 tokens")
+
+	out, err = executeWithOutput(t, "run", "--config", cfgPath, "--print", "--format", "json")
+	require.NoError(t, err)
+	var output map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &output))
+	assert.EqualValues(t, models.SchemaVersion, output["schema_version"])
+	assert.Equal(t, "yellow", output["status"])
+	assert.Equal(t, 12.5, output["today"].(map[string]interface{})["cost_usd"])
+	assert.Nil(t, output["level"])
+}
+
+func TestRunPrint_Unavailable(t *testing.T) {
+	resetPrintFlags(t)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dir := t.TempDir()
+	cfgPath := writeBinaryConfig(t, dir, testhelpers.WriteFakeCommand(t, dir, "ccusage", "", 1))
+
+	out, err := executeWithOutput(t, "run", "--config", cfgPath, "--print", "--format", "json")
+	assert.Equal(t, ExitFetch, exitCode(err), "error: %v", err)
+	// Cobra appends the usage text after the JSON here; outside tests it
+	// goes to stderr.
+	var output models.UsageOutput
+	require.NoError(t, json.NewDecoder(strings.NewReader(out)).Decode(&output), "the output is still printed")
+	assert.False(t, output.Available)
+	assert.Equal(t, "unknown", output.Status)
+	assert.Nil(t, output.Today)
+}
+
+func TestRunPrint_Formats(t *testing.T) {
+	resetPrintFlags(t)
+
+	out, err := executeWithOutput(t, "run", "--print", "--format", "json-schema")
+	require.NoError(t, err)
+	assert.Equal(t, string(models.UsageOutputSchema()), out)
+
+	_, err = executeWithOutput(t, "run", "--print", "--format", "yaml", "--config", writeBinaryConfig(t, t.TempDir(), "ccusage"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid --format "yaml"`)
+
+	_, err = executeWithOutput(t, "run", "--print=false", "--format", "json")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--format needs --print")
+}
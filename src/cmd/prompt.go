@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+	"cc-dailyuse-bar/src/services"
+)
+
+var (
+	promptColor        string
+	promptMaxStaleness int
+	promptRefreshCache bool
+)
+
+// promptStatePath is where the prompt command saves the result of its
+// own ccusage runs between prompts.
+var promptStatePath = filepath.Join(services.DefaultPaths().CacheDir(), "prompt-status.json")
+
+// promptColors maps AlertStatus color names to ANSI SGR parameters.
+var promptColors = map[string]string{
+	models.Green.ColorName():  "32",
+	models.Yellow.ColorName(): "33",
+	models.Red.ColorName():    "1;31",
+}
+
+// promptBlueOrangeColors replaces promptColors for the blue-orange
+// status_palette: blue, orange, and reversed bold orange.
+var promptBlueOrangeColors = map[string]string{
+	models.Green.ColorName():  "34",
+	models.Yellow.ColorName(): "38;5;208",
+	models.Red.ColorName():    "1;7;38;5;208",
+}
+
+// promptEscapes wraps ANSI sequences so each shell leaves them out of the
+// prompt width; without it long command lines wrap in the wrong place.
+var promptEscapes = map[string][2]string{
+	"none": {},
+	"ansi": {"", ""},
+	"bash": {"\001", "\002"},
+	"zsh":  {"%{", "%}"},
+}
+
+// startPromptRefresh updates the cache in a detached process; tests
+// replace it.
+var startPromptRefresh = spawnPromptRefresh
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Print today's usage as a shell prompt segment",
+	Long: `Print today's spend, e.g. "CC $12.40", for a shell prompt or a starship
+custom module.
+
+Prompts are drawn before every command, so this never waits for ccusage:
+it prints the running tray's status file or its own saved result, and when
+neither was written within update_interval (plus 30 seconds of slack) it
+refreshes the saved result in the background for the next prompt. Data
+older than --max-staleness seconds, or unavailable, prints nothing, so the
+segment disappears rather than showing a stale figure.
+
+--color adds ANSI colors by status. Use "bash" or "zsh" inside PS1/PROMPT
+so the escapes don't count toward the prompt width, or "ansi" elsewhere.`,
+	Example: `  # ~/.bashrc
+  PS1='$(cc-dailyuse-bar prompt --color bash) \w \$ '
+
+  # ~/.config/starship.toml
+  [custom.claude]
+  command = "cc-dailyuse-bar prompt"
+  when = true
+  style = "bold yellow"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		escapes, ok := promptEscapes[promptColor]
+		if !ok {
+			return lib.ValidationError(fmt.Sprintf("--color must be none, ansi, bash, or zsh, got %q", promptColor))
+		}
+
+		configService := services.NewConfigService()
+		if cfgFile != "" {
+			configService.SetConfigPath(cfgFile)
+		}
+		config, err := configService.Load()
+		if err != nil {
+			return withExitCode(ExitConfig, fmt.Errorf("failed to load config: %w", err))
+		}
+
+		cache := services.NewStatusFileAt(promptStatePath)
+		if promptRefreshCache {
+			fetchSnapshot(config, cache)
+			return nil
+		}
+
+		maxAge := time.Duration(config.UpdateInterval)*time.Second + statusFileSlack
+		snapshot, ok := loadPromptSnapshot(maxAge, services.NewStatusFile(), cache)
+		if !ok {
+			return nil
+		}
+		maxStaleness := time.Duration(promptMaxStaleness) * time.Second
+		fmt.Fprint(cmd.OutOrStdout(), promptSegment(snapshot, config, maxStaleness, promptColor != "none", escapes))
+		return nil
+	},
+}
+
+// loadPromptSnapshot returns what the tray or the cache last saved,
+// starting a background refresh when neither is younger than maxAge. The
+// cache's timestamp is bumped first, so the prompts drawn while that
+// refresh runs don't each start another; without a cache yet, an
+// unavailable snapshot is saved in its place.
+func loadPromptSnapshot(maxAge time.Duration, tray, cache *services.StatusFile) (models.StatusSnapshot, bool) {
+	snapshot, fresh, ok := readSnapshot(maxAge, tray, cache)
+	if !fresh {
+		now := time.Now()
+		if err := os.Chtimes(cache.Path(), now, now); errors.Is(err, os.ErrNotExist) {
+			_ = cache.Write(models.StatusSnapshot{Version: models.StatusSnapshotVersion, SchemaVersion: models.SchemaVersion})
+		}
+		startPromptRefresh()
+	}
+	return snapshot, ok
+}
+
+// readSnapshot returns the first of files written within maxAge, with
+// fresh set. Failing that it returns the readable snapshot with the most
+// recent data, if any, so callers can decide whether it is too old to show.
+func readSnapshot(maxAge time.Duration, files ...*services.StatusFile) (snapshot models.StatusSnapshot, fresh, ok bool) {
+	for _, sf := range files {
+		candidate, written, err := sf.Read()
+		if err != nil || candidate.Version != models.StatusSnapshotVersion {
+			continue
+		}
+		if time.Since(written) < maxAge {
+			return candidate, true, true
+		}
+		if !ok || candidate.UpdatedAt.After(snapshot.UpdatedAt) {
+			snapshot, ok = candidate, true
+		}
+	}
+	return snapshot, false, ok
+}
+
+// fetchSnapshot queries ccusage once and saves the result to cache. Failed
+// queries are saved too, so a broken ccusage isn't retried on every
+// prompt.
+func fetchSnapshot(config *models.Config, cache *services.StatusFile) models.StatusSnapshot {
+	state, _ := services.NewUsageService(config).UpdateUsage()
+	if state == nil {
+		state = &models.UsageState{}
+	}
+	snapshot := models.NewStatusSnapshot(state, config)
+	if err := cache.Write(snapshot); err != nil {
+		logger.Warn("Failed to save prompt status", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return snapshot
+}
+
+// promptSegment renders snapshot as "CC $12.40", colored when color is
+// set, or "" when its data is unavailable or older than maxStaleness.
+func promptSegment(snapshot models.StatusSnapshot, config *models.Config, maxStaleness time.Duration, color bool, escapes [2]string) string {
+	if !snapshot.Available || time.Since(snapshot.UpdatedAt) > maxStaleness {
+		return ""
+	}
+	colors := promptColors
+	if config.StatusPalette == models.StatusPaletteBlueOrange {
+		colors = promptBlueOrangeColors
+	}
+	sgr, ok := colors[snapshot.Status]
+	if !ok {
+		return ""
+	}
+	text := "CC " + config.TitleCostFormat().Format(snapshot.DailyCost)
+	if !color {
+		return text
+	}
+	return escapes[0] + "\x1b[" + sgr + "m" + escapes[1] + text + escapes[0] + "\x1b[0m" + escapes[1]
+}
+
+// spawnPromptRefresh starts `prompt --refresh-cache` detached from the
+// shell, so the prompt returns immediately.
+func spawnPromptRefresh() {
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+	args := []string{"prompt", "--refresh-cache"}
+	if cfgFile != "" {
+		args = append(args, "--config", cfgFile)
+	}
+	child := exec.Command(exe, args...)
+	if err := child.Start(); err != nil {
+		return
+	}
+	_ = child.Process.Release()
+}
+
+func init() {
+	RootCmd.AddCommand(promptCmd)
+	promptCmd.Flags().StringVar(&promptColor, "color", "none", "ANSI colors by status: none, ansi, bash (PS1), or zsh (PROMPT)")
+	promptCmd.Flags().IntVar(&promptMaxStaleness, "max-staleness", 900, "Print nothing when the data is older than this many seconds")
+	promptCmd.Flags().BoolVar(&promptRefreshCache, "refresh-cache", false, "Query ccusage and update the saved result, printing nothing")
+	_ = promptCmd.Flags().MarkHidden("refresh-cache")
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+	"cc-dailyuse-bar/src/services"
+)
+
+func TestPromptSegment(t *testing.T) {
+	config := models.ConfigDefaults()
+	now := time.Now()
+	yellow := models.StatusSnapshot{Available: true, Status: "yellow", DailyCost: 12.4, UpdatedAt: now}
+	tests := []struct {
+		name     string
+		snapshot models.StatusSnapshot
+		color    string
+		want     string
+	}{
+		{"plain", yellow, "none", "CC $12.40"},
+		{"ansi", yellow, "ansi", "\x1b[33mCC $12.40\x1b[0m"},
+		{"bash", yellow, "bash", "\001\x1b[33m\002CC $12.40\001\x1b[0m\002"},
+		{"zsh", yellow, "zsh", "%{\x1b[33m%}CC $12.40%{\x1b[0m%}"},
+		{"red", models.StatusSnapshot{Available: true, Status: "red", DailyCost: 25, UpdatedAt: now}, "ansi", "\x1b[1;31mCC $25.00\x1b[0m"},
+		{"stale", models.StatusSnapshot{Available: true, Status: "green", UpdatedAt: now.Add(-time.Hour)}, "none", ""},
+		{"unavailable", models.StatusSnapshot{Status: "unknown", UpdatedAt: now}, "none", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := promptSegment(tt.snapshot, config, 15*time.Minute, tt.color != "none", promptEscapes[tt.color])
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPromptSegment_BlueOrange(t *testing.T) {
+	config := models.ConfigDefaults()
+	config.StatusPalette = models.StatusPaletteBlueOrange
+	yellow := models.StatusSnapshot{Available: true, Status: "yellow", DailyCost: 12.4, UpdatedAt: time.Now()}
+	assert.Equal(t, "\x1b[38;5;208mCC $12.40\x1b[0m", promptSegment(yellow, config, 15*time.Minute, true, promptEscapes["ansi"]))
+}
+
+func TestLoadPromptSnapshot(t *testing.T) {
+	saved := startPromptRefresh
+	t.Cleanup(func() { startPromptRefresh = saved })
+	refreshes := 0
+	startPromptRefresh = func() { refreshes++ }
+
+	dir := t.TempDir()
+	tray := services.NewStatusFileAt(filepath.Join(dir, "status.json"))
+	cache := services.NewStatusFileAt(filepath.Join(dir, "cached-status.json"))
+
+	_, ok := loadPromptSnapshot(time.Minute, tray, cache)
+	assert.False(t, ok, "nothing saved yet")
+	assert.Equal(t, 1, refreshes)
+	snapshot, ok := loadPromptSnapshot(time.Minute, tray, cache)
+	assert.True(t, ok)
+	assert.False(t, snapshot.Available, "a placeholder until the first refresh is done")
+	assert.Equal(t, 1, refreshes, "the first refresh isn't started again")
+
+	require.NoError(t, cache.Write(models.StatusSnapshot{Version: models.StatusSnapshotVersion, Available: true, Status: "green"}))
+	snapshot, ok = loadPromptSnapshot(time.Minute, tray, cache)
+	assert.True(t, ok)
+	assert.Equal(t, "green", snapshot.Status)
+	assert.Equal(t, 1, refreshes, "fresh cache needs no refresh")
+
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(cache.Path(), old, old))
+	snapshot, ok = loadPromptSnapshot(time.Minute, tray, cache)
+	assert.True(t, ok, "stale data is still returned")
+	assert.Equal(t, "green", snapshot.Status)
+	assert.Equal(t, 2, refreshes)
+
+	_, _ = loadPromptSnapshot(time.Minute, tray, cache)
+	assert.Equal(t, 2, refreshes, "a refresh in flight isn't started again")
+}
+
+func TestPromptCmd_InvalidColor(t *testing.T) {
+	saved := promptColor
+	t.Cleanup(func() { promptColor = saved })
+
+	_, err := executeWithOutput(t, "prompt", "--color", "rainbow")
+	assert.ErrorContains(t, err, "--color must be none, ansi, bash, or zsh")
+}
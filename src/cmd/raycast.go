@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cc-dailyuse-bar/src/models"
+	"cc-dailyuse-bar/src/services"
+)
+
+var (
+	raycastInline bool
+	raycastScript bool
+)
+
+var raycastCmd = &cobra.Command{
+	Use:   "raycast",
+	Short: "Print today's usage for a Raycast script command",
+	Long: `Query ccusage once and print today's usage as markdown for a Raycast
+script command in fullOutput mode: the menu bar title as a heading, then
+today, comparison, weekly, and model sections.
+
+With --inline, print only the title line for a command in inline mode,
+which Raycast re-runs on its own schedule. With --script, print a ready
+to use script command file that calls this binary; save it into your
+Raycast script commands directory.`,
+	Example: `  cc-dailyuse-bar raycast --script > ~/raycast/claude-usage.sh
+  cc-dailyuse-bar raycast --script --inline > ~/raycast/claude-usage-inline.sh`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configService := services.NewConfigService()
+		if cfgFile != "" {
+			configService.SetConfigPath(cfgFile)
+		}
+		config, err := configService.Load()
+		if err != nil {
+			return withExitCode(ExitConfig, fmt.Errorf("failed to load config: %w", err))
+		}
+
+		out := cmd.OutOrStdout()
+		if raycastScript {
+			exe, err := os.Executable()
+			if err != nil {
+				exe = "cc-dailyuse-bar"
+			}
+			configPath := cfgFile
+			if configPath != "" {
+				// Raycast runs scripts from its own working directory.
+				if abs, err := filepath.Abs(configPath); err == nil {
+					configPath = abs
+				}
+			}
+			fmt.Fprint(out, raycastScriptCommand(exe, configPath, raycastInline, config.UpdateInterval))
+			return nil
+		}
+
+		state, fetchErr := services.NewUsageService(config).UpdateUsage()
+		if raycastInline {
+			if fetchErr != nil || !state.IsAvailable {
+				fmt.Fprintln(out, models.FormatUnknownTitle(config))
+			} else {
+				fmt.Fprintln(out, models.FormatTitle(state, config))
+			}
+		} else {
+			fmt.Fprint(out, raycastMarkdown(state, config, fetchErr))
+		}
+		if fetchErr != nil {
+			return withExitCode(ExitFetch, fetchErr)
+		}
+		return nil
+	},
+}
+
+// raycastMarkdown renders state as the fullOutput body of a Raycast
+// script command.
+func raycastMarkdown(state *models.UsageState, config *models.Config, fetchErr error) string {
+	var b strings.Builder
+	if fetchErr != nil || state == nil || !state.IsAvailable {
+		fmt.Fprintf(&b, "# %s\n\n", models.FormatUnknownTitle(config))
+		b.WriteString("⚠️ Usage data unavailable")
+		if fetchErr != nil {
+			fmt.Fprintf(&b, ": %v", fetchErr)
+		}
+		b.WriteString("\n\nRun `cc-dailyuse-bar doctor` to diagnose.\n")
+		return b.String()
+	}
+
+	format := config.CostFormat()
+	fmt.Fprintf(&b, "# %s\n\n", models.FormatTitle(state, config))
+
+	b.WriteString("## Today\n\n")
+	fmt.Fprintf(&b, "- **Cost:** %s\n", format.Format(state.DailyCost))
+	fmt.Fprintf(&b, "- **Calls:** %d\n", state.DailyCalls)
+	fmt.Fprintf(&b, "- **Tokens:** %d\n", state.DailyCount)
+	status := state.Status.String()
+	if state.Level != "" {
+		status += fmt.Sprintf(" (%s)", state.Level)
+	}
+	fmt.Fprintf(&b, "- **Status:** %s\n", status)
+	if remaining, ok := state.RemainingToRed(config); ok && remaining > 0 {
+		fmt.Fprintf(&b, "- **Until red:** %s\n", format.Format(remaining))
+	}
+	if state.Quiet {
+		fmt.Fprintf(&b, "- **Alerts:** quiet until %s\n", config.QuietUntil)
+	}
+	for _, line := range state.ComparisonLines() {
+		fmt.Fprintf(&b, "- %s\n", line)
+	}
+
+	b.WriteString("\n## This Week\n\n")
+	fmt.Fprintf(&b, "- **Cost:** %s\n", format.Format(state.WeeklyCost))
+	if config.WeeklyBudget > 0 {
+		remaining := state.WeeklyRemaining(config.WeeklyBudget)
+		if remaining < 0 {
+			fmt.Fprintf(&b, "- **Over budget by:** %s\n", format.Format(-remaining))
+		} else {
+			fmt.Fprintf(&b, "- **Left:** %s of %s\n", format.Format(remaining), format.Format(config.WeeklyBudget))
+		}
+	}
+
+	if len(state.Models) > 0 {
+		b.WriteString("\n## Models\n\n| Model | Cost | Tokens |\n|-------|-----:|-------:|\n")
+		for _, m := range state.Models {
+			fmt.Fprintf(&b, "| %s | %s | %d |\n", m.Name, format.Format(m.Cost), m.Tokens)
+		}
+	}
+	for _, alert := range state.ModelAlerts {
+		fmt.Fprintf(&b, "\n> 🔶 %s: %s (limit %s)\n",
+			alert.Pattern, format.Format(alert.Cost), format.Format(alert.Threshold))
+	}
+
+	if state.Demo {
+		b.WriteString("\n🧪 Demo mode: synthetic data\n")
+	}
+	fmt.Fprintf(&b, "\n---\n_Updated %s_\n", format.Locale.FormatDateTime(state.LastUpdate))
+	return b.String()
+}
+
+// raycastScriptCommand renders a Raycast script command that runs exe.
+// Inline commands refresh on the configured update interval, which Raycast
+// clamps to at least 10 seconds.
+func raycastScriptCommand(exe, configPath string, inline bool, updateInterval int) string {
+	var b strings.Builder
+	b.WriteString("#!/bin/bash\n\n")
+	b.WriteString("# Required parameters:\n")
+	b.WriteString("# @raycast.schemaVersion 1\n")
+	b.WriteString("# @raycast.title Claude Code Usage\n")
+	command := shellQuote(exe) + " raycast"
+	if configPath != "" {
+		command += " --config " + shellQuote(configPath)
+	}
+	if inline {
+		b.WriteString("# @raycast.mode inline\n")
+		fmt.Fprintf(&b, "# @raycast.refreshTime %s\n", raycastRefreshTime(updateInterval))
+		command += " --inline"
+	} else {
+		b.WriteString("# @raycast.mode fullOutput\n")
+	}
+	b.WriteString("\n# Optional parameters:\n")
+	b.WriteString("# @raycast.icon 🤖\n")
+	b.WriteString("# @raycast.packageName cc-dailyuse-bar\n")
+	b.WriteString("# @raycast.description Today's Claude Code spend from ccusage\n\n")
+	fmt.Fprintf(&b, "exec %s\n", command)
+	return b.String()
+}
+
+// raycastRefreshTime formats seconds the way @raycast.refreshTime expects,
+// e.g. "30s" or "5m".
+func raycastRefreshTime(seconds int) string {
+	if seconds < 10 {
+		seconds = 10
+	}
+	d := time.Duration(seconds) * time.Second
+	if d%time.Minute == 0 {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
+
+// shellQuote single-quotes s for bash when it contains anything beyond a
+// plain path.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789/._-+") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func init() {
+	RootCmd.AddCommand(raycastCmd)
+	raycastCmd.Flags().BoolVar(&raycastInline, "inline", false, "Print only the title line, for an inline-mode script command")
+	raycastCmd.Flags().BoolVar(&raycastScript, "script", false, "Print a Raycast script command that runs this binary, then exit")
+}
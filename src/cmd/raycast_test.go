@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/internal/testhelpers"
+	"cc-dailyuse-bar/src/models"
+)
+
+func TestRaycastMarkdown(t *testing.T) {
+	config := models.ConfigDefaults()
+	config.WeeklyBudget = 50
+	state := &models.UsageState{
+		LastUpdate:  time.Date(2025, 3, 14, 15, 4, 5, 0, time.Local),
+		DailyCost:   12.4,
+		DailyCount:  4200,
+		DailyCalls:  37,
+		WeeklyCost:  60,
+		Status:      models.Yellow,
+		IsAvailable: true,
+		Yesterday:   &models.CostComparison{Date: "2025-03-13", Cost: 10},
+		Models:      []models.ModelUsage{{Name: "claude-sonnet-4", Tokens: 4200, Cost: 12.4}},
+	}
+
+	assert.Equal(t, `# CC 🟡 $12.40
+
+## Today
+
+- **Cost:** $12.40
+- **Calls:** 37
+- **Tokens:** 4200
+- **Status:** High
+- **Until red:** $7.60
+- vs yesterday: ▲ +24%
+
+## This Week
+
+- **Cost:** $60.00
+- **Over budget by:** $10.00
+
+## Models
+
+| Model | Cost | Tokens |
+|-------|-----:|-------:|
+| claude-sonnet-4 | $12.40 | 4200 |
+
+---
+_Updated 2025-03-14 15:04:05_
+`, raycastMarkdown(state, config, nil))
+
+	unavailable := raycastMarkdown(&models.UsageState{}, config, errors.New("ccusage failed"))
+	assert.Contains(t, unavailable, "# "+models.UnknownTitle)
+	assert.Contains(t, unavailable, "Usage data unavailable: ccusage failed")
+}
+
+func TestRaycastScriptCommand(t *testing.T) {
+	full := raycastScriptCommand("/usr/local/bin/cc-dailyuse-bar", "", false, 30)
+	assert.Contains(t, full, "# @raycast.schemaVersion 1\n")
+	assert.Contains(t, full, "# @raycast.mode fullOutput\n")
+	assert.NotContains(t, full, "refreshTime")
+	assert.Contains(t, full, "exec /usr/local/bin/cc-dailyuse-bar raycast\n")
+
+	inline := raycastScriptCommand("/Applications/CC Bar/cc-dailyuse-bar", "/tmp/it's.yaml", true, 120)
+	assert.Contains(t, inline, "# @raycast.mode inline\n# @raycast.refreshTime 2m\n")
+	assert.Contains(t, inline, `exec '/Applications/CC Bar/cc-dailyuse-bar' raycast --config '/tmp/it'\''s.yaml' --inline`)
+}
+
+func TestRaycastRefreshTime(t *testing.T) {
+	assert.Equal(t, "10s", raycastRefreshTime(5))
+	assert.Equal(t, "30s", raycastRefreshTime(30))
+	assert.Equal(t, "1m", raycastRefreshTime(60))
+	assert.Equal(t, "90s", raycastRefreshTime(90))
+}
+
+func TestRaycastCmd(t *testing.T) {
+	testhelpers.RequireShell(t)
+	savedCfgFile, savedInline, savedScript := cfgFile, raycastInline, raycastScript
+	t.Cleanup(func() {
+		cfgFile, raycastInline, raycastScript = savedCfgFile, savedInline, savedScript
+		for _, name := range []string{"inline", "script"} {
+			raycastCmd.Flags().Lookup(name).Changed = false
+		}
+	})
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "ccusage")
+	today := time.Now().Format("2006-01-02")
+	require.NoError(t, os.WriteFile(script, []byte(fmt.Sprintf(`#!/bin/bash
+echo '{"daily":[{"date":%q,"totalTokens":900,"totalCost":21}]}'
+`, today)), 0o755))
+	cfgPath := writeBinaryConfig(t, dir, script)
+
+	out, err := executeWithOutput(t, "raycast", "--config", cfgPath)
+	require.NoError(t, err)
+	assert.Contains(t, out, "# CC 🔴 $21.00\n")
+	assert.Contains(t, out, "- **Status:** Critical\n")
+
+	out, err = executeWithOutput(t, "raycast", "--config", cfgPath, "--inline")
+	require.NoError(t, err)
+	assert.Equal(t, "CC 🔴 $21.00\n", out)
+
+	out, err = executeWithOutput(t, "raycast", "--config", cfgPath, "--script")
+	require.NoError(t, err)
+	assert.Contains(t, out, "# @raycast.refreshTime 30s\n")
+	assert.Contains(t, out, " raycast --config "+cfgPath+" --inline\n")
+
+	raycastInline, raycastScript = false, false
+	broken := writeBinaryConfig(t, t.TempDir(), filepath.Join(dir, "missing"))
+	out, err = executeWithOutput(t, "raycast", "--config", broken)
+	require.Error(t, err)
+	assert.Equal(t, ExitFetch, exitCode(err))
+	assert.Contains(t, out, models.UnknownTitle)
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+	"cc-dailyuse-bar/src/services"
+)
+
+// restartOnlyFields are settings read once at startup; reloads report
+// changes to them but they only take effect after a restart.
+var restartOnlyFields = map[string]bool{
+	"watch_data_dirs": true,
+	"team_dir":        true,
+	"http_listen":     true,
+	"http_token":      true,
+	"http_pprof":      true,
+}
+
+// configReloader re-reads the config file on SIGHUP or `ctl reload-config`
+// and applies it to the running app.
+type configReloader struct {
+	cmd           *cobra.Command     // run command whose flag overrides are re-applied
+	config        *models.LiveConfig // shared with the tray, control socket, and dashboard
+	configService *services.ConfigService
+	usageService  *services.UsageService
+}
+
+// Reload loads and validates the config file, re-applies command-line
+// overrides, checks that a changed ccusage_path resolves, and only then
+// swaps the result in as a new snapshot. Any failure leaves the running
+// configuration untouched. It runs as a LiveConfig.Update, so it can't
+// interleave with a change made from the tray and undo it.
+func (r *configReloader) Reload() error {
+	var changes []models.ConfigChange
+	updated, err := r.config.Update(func(next *models.Config) error {
+		loaded, err := r.configService.Load()
+		if err != nil {
+			logger.Error("Config reload failed, keeping current configuration", map[string]interface{}{
+				"error": err.Error(),
+				"path":  r.configService.GetConfigPath(),
+			})
+			return err
+		}
+		if r.cmd != nil {
+			if err := mergeConfig(loaded, r.cmd); err != nil {
+				return lib.WrapError(err, lib.ErrCodeValidation, "invalid configuration after flag overrides")
+			}
+		}
+
+		if loaded.CCUsagePath != next.CCUsagePath {
+			if err := r.usageService.CheckCCUsagePath(loaded.CCUsagePath); err != nil {
+				logger.Error("Config reload failed, keeping current configuration", map[string]interface{}{
+					"error": err.Error(),
+					"path":  r.configService.GetConfigPath(),
+				})
+				return err
+			}
+		}
+
+		if changes, err = models.DiffConfig(next, loaded); err != nil {
+			return lib.WrapError(err, lib.ErrCodeConfig, "failed to compare configurations")
+		}
+		*next = *loaded
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.usageService.ApplyConfig(updated)
+	applyConfigLogLevel(r.cmd, updated)
+	r.logChanges(changes)
+	return nil
+}
+
+func (r *configReloader) logChanges(changes []models.ConfigChange) {
+	if len(changes) == 0 {
+		logger.Info("Configuration reloaded, no changes")
+		return
+	}
+
+	applied := make([]string, 0, len(changes))
+	var restart []string
+	for _, change := range changes {
+		if restartOnlyFields[change.Field] {
+			restart = append(restart, change.String())
+		} else {
+			applied = append(applied, change.String())
+		}
+	}
+
+	context := map[string]interface{}{"changes": applied}
+	if len(restart) > 0 {
+		context["restart_required"] = restart
+	}
+	logger.Info("Configuration reloaded", context)
+}
+
+// applyConfigLogLevel follows the config's debug_level unless --log-level
+// was given explicitly.
+func applyConfigLogLevel(cmd *cobra.Command, config *models.Config) {
+	if cmd != nil {
+		if flag := cmd.Flag("log-level"); flag != nil && flag.Changed {
+			return
+		}
+	}
+	lib.SetGlobalLevel(lib.LogLevel(config.GetLogLevel()))
+}
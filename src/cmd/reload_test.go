@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+	"cc-dailyuse-bar/src/services"
+)
+
+func newTestReloader(t *testing.T) (*configReloader, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	configService := services.NewConfigService()
+	configService.SetConfigPath(path)
+
+	config := models.ConfigDefaults()
+	require.NoError(t, configService.Save(config))
+
+	return &configReloader{
+		config:        models.NewLiveConfig(config),
+		configService: configService,
+		usageService:  services.NewUsageService(config),
+	}, path
+}
+
+func TestConfigReloader_AppliesChanges(t *testing.T) {
+	t.Cleanup(func() { lib.SetGlobalLevel(lib.INFO) })
+	reloader, path := newTestReloader(t)
+	before := reloader.config.Get()
+
+	updated := models.ConfigDefaults()
+	updated.YellowThreshold = 15
+	updated.RedThreshold = 40
+	updated.UpdateInterval = 60
+	updated.DebugLevel = "DEBUG"
+	require.NoError(t, reloader.configService.Save(updated))
+
+	require.NoError(t, reloader.Reload())
+	live := reloader.config.Get()
+	assert.NotSame(t, before, live, "a new snapshot is swapped in")
+	assert.Equal(t, 10.0, before.YellowThreshold, "readers holding the old snapshot see it unchanged")
+	assert.Equal(t, 15.0, live.YellowThreshold)
+	assert.Equal(t, 40.0, live.RedThreshold)
+	assert.Equal(t, 60, live.UpdateInterval)
+	assert.Equal(t, lib.DEBUG, lib.GetGlobalLevel())
+
+	// An invalid file is rejected without touching the running config.
+	require.NoError(t, os.WriteFile(path, []byte("update_interval: 5\n"), 0o644))
+	assert.Error(t, reloader.Reload())
+	assert.Same(t, live, reloader.config.Get())
+}
+
+func TestConfigReloader_RejectsMissingCCUsage(t *testing.T) {
+	reloader, _ := newTestReloader(t)
+	before := reloader.config.Get()
+
+	updated := models.ConfigDefaults()
+	updated.CCUsagePath = filepath.Join(t.TempDir(), "missing-ccusage")
+	updated.YellowThreshold = 15
+	require.NoError(t, reloader.configService.Save(updated))
+
+	err := reloader.Reload()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing-ccusage")
+	assert.Same(t, before, reloader.config.Get(), "the running config is kept")
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+	"cc-dailyuse-bar/src/services"
+)
+
+// replayMaxPause caps the wait between two recordings, so a quiet night in
+// the recording doesn't stall the replay.
+const replayMaxPause = time.Second
+
+// runReplay feeds the responses record mode saved in dir, oldest first,
+// through the same pipeline as the tray, with each recording's own time as
+// "now", and prints what would have been displayed after each. The gaps
+// between recordings are divided by speed (at most replayMaxPause); speed
+// 0 replays without pausing. No ccusage process is started.
+func runReplay(cmd *cobra.Command, config *models.Config, dir string, speed float64) error {
+	out := cmd.OutOrStdout()
+
+	if speed < 0 {
+		return lib.ValidationError("--replay-speed must be positive")
+	}
+	recordings, err := services.LoadRecordings(dir)
+	if err != nil {
+		return err
+	}
+	if len(recordings) == 0 {
+		return lib.ValidationError(fmt.Sprintf("no recordings in %s; set record_dir to save some", dir))
+	}
+
+	usageService := services.NewUsageService(config)
+	clock := &fixtureClock{}
+	usageService.SetClock(clock)
+
+	failed := 0
+	for i, recording := range recordings {
+		if i > 0 && speed > 0 {
+			gap := time.Duration(float64(recording.Time.Sub(recordings[i-1].Time)) / speed)
+			time.Sleep(min(gap, replayMaxPause))
+		}
+		data, err := os.ReadFile(recording.Path)
+		if err != nil {
+			return lib.WrapError(err, lib.ErrCodeConfig, "failed to read recording")
+		}
+
+		clock.now = recording.Time
+		state, applyErr := usageService.ApplyFixture(data)
+		line := recording.Time.Format("2006-01-02 15:04:05") + "  "
+		if state.IsAvailable {
+			line += models.FormatTitle(state, config)
+		} else {
+			line += models.FormatUnknownTitle(config)
+		}
+		if applyErr != nil {
+			line += fmt.Sprintf("  (%v)", applyErr)
+			if !state.IsAvailable {
+				failed++
+			}
+		}
+		fmt.Fprintln(out, line)
+	}
+
+	fmt.Fprintf(out, "Replayed %d recordings from %s\n", len(recordings), dir)
+	if failed > 0 {
+		return withExitCode(ExitParse, lib.NewError(lib.ErrCodeCCUsage,
+			fmt.Sprintf("%d of %d recordings failed to parse", failed, len(recordings))))
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunReplay(t *testing.T) {
+	savedDir, savedSpeed := replayDir, replaySpeed
+	t.Cleanup(func() { replayDir, replaySpeed = savedDir, savedSpeed })
+
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`ccusage_path: ccusage
+update_interval: 30
+yellow_threshold: 10
+red_threshold: 20
+debug_level: INFO
+cache_window: 10
+cmd_timeout: 30
+`), 0o644))
+	recordings := filepath.Join(dir, "recordings")
+	require.NoError(t, os.Mkdir(recordings, 0o755))
+	for name, body := range map[string]string{
+		"20250314T090000.000Z.json": `{"daily":[{"date":"2025-03-14","totalTokens":10,"totalCost":4}]}`,
+		"20250314T110000.000Z.json": `{"daily":[{"date":"2025-03-14","totalTokens":90,"totalCost":12.5}]}`,
+		"20250314T120000.000Z.json": `{"daily":[{"date":"2025-03-14"`,
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(recordings, name), []byte(body), 0o644))
+	}
+
+	out, err := executeWithOutput(t, "run", "--config", cfgPath, "--replay", recordings, "--replay-speed", "0")
+	assert.Equal(t, ExitParse, exitCode(err))
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.GreaterOrEqual(t, len(lines), 4)
+	assert.Equal(t, "2025-03-14 09:00:00  CC 🟢 $4.00", lines[0])
+	assert.Equal(t, "2025-03-14 11:00:00  CC 🟡 $12.50", lines[1])
+	assert.True(t, strings.HasPrefix(lines[2], "2025-03-14 12:00:00  CC ⚪️ Unknown  ("), lines[2])
+	assert.Equal(t, "Replayed 3 recordings from "+recordings, lines[3])
+	assert.ErrorContains(t, err, "1 of 3 recordings failed to parse")
+
+	_, err = executeWithOutput(t, "run", "--config", cfgPath, "--replay", t.TempDir(), "--replay-speed", "0")
+	assert.ErrorContains(t, err, "no recordings in")
+}
@@ -32,10 +32,10 @@ Anthropic Claude Code usage and costs in real-time.`,
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the RootCmd.
 // Cobra prints the error to stderr itself; we just translate non-nil into a
-// non-zero exit status.
+// non-zero exit status (see exit_codes.go).
 func Execute() {
 	if err := RootCmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -5,15 +5,31 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"cc-dailyuse-bar/src/internal/control"
 	"cc-dailyuse-bar/src/lib"
 	"cc-dailyuse-bar/src/models"
 	"cc-dailyuse-bar/src/services"
 )
 
-var daemonMode bool
+var (
+	daemonMode       bool
+	demoMode         bool
+	stopMode         bool
+	checkMode        bool
+	printMode        bool
+	printFormat      string
+	validatePath     string
+	fixturePath      string
+	fixtureDate      string
+	replayDir        string
+	replaySpeed      float64
+	plasmoidFeedPath string
+	controlSocket    string
+)
 
 var logger = lib.NewLogger("cmd-run")
 
@@ -28,16 +44,27 @@ var runCmd = &cobra.Command{
 	Long: `Start the CC Daily Use Bar in the system tray.
 This is the default mode if no command is specified.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Validate the parent process before forking a daemon — otherwise the
-		// parent prints a success PID even when the child is guaranteed to fail
-		// (no GUI build, bad config, invalid flags).
-		if runTrayApp == nil {
-			return lib.NewError(lib.ErrCodeSystem, "this binary was built without GUI support (use a build without the 'nogui' tag)")
+		if stopMode {
+			return stopRunningInstance(cmd)
+		}
+		if checkMode {
+			return runSelfCheck(cmd)
+		}
+		if validatePath != "" {
+			return runValidateConfig(cmd, validatePath)
+		}
+		if cmd.Flags().Changed("format") && !printMode {
+			return lib.ValidationError("--format needs --print")
+		}
+		if printMode && printFormat == printFormatJSONSchema {
+			return runPrintSchema(cmd)
 		}
 
 		configService := services.NewConfigService()
 		if cfgFile != "" {
 			configService.SetConfigPath(cfgFile)
+		} else {
+			migrateLegacyConfig()
 		}
 
 		// Load() already returns ConfigDefaults for a missing file; any error
@@ -52,6 +79,31 @@ This is the default mode if no command is specified.`,
 		if err := mergeConfig(config, cmd); err != nil {
 			return lib.WrapError(err, lib.ErrCodeValidation, "invalid configuration after flag overrides")
 		}
+		applyConfigLogLevel(cmd, config)
+		warnDirPermissions(cfgFile)
+
+		if printMode {
+			return runPrint(cmd, config, printFormat)
+		}
+		if fixturePath != "" {
+			return runFixture(cmd, config, fixturePath, fixtureDate)
+		}
+		if replayDir != "" {
+			return runReplay(cmd, config, replayDir, replaySpeed)
+		}
+		if plasmoidFeedPath != "" {
+			if daemonMode {
+				return runAsDaemon(cmd)
+			}
+			return runPlasmoidFeed(cmd, config, plasmoidFeedPath)
+		}
+
+		// Validate the parent process before forking a daemon — otherwise the
+		// parent prints a success PID even when the child is guaranteed to fail
+		// (no GUI build, bad config, invalid flags).
+		if runTrayApp == nil {
+			return lib.NewError(lib.ErrCodeSystem, "this binary was built without GUI support (use a build without the 'nogui' tag)")
+		}
 
 		if daemonMode {
 			return runAsDaemon(cmd)
@@ -61,17 +113,71 @@ This is the default mode if no command is specified.`,
 	},
 }
 
+// newRunUsageService creates the usage service for a long-running mode:
+// fed synthetic data with --demo, otherwise recording history. history is
+// nil in demo mode.
+func newRunUsageService(config *models.Config) (*services.UsageService, *services.HistoryService) {
+	usageService := services.NewUsageService(config)
+	if demoMode {
+		// Synthetic data must never end up in the recorded history.
+		usageService.SetDemoFeed(services.NewDemoFeed(config))
+		return usageService, nil
+	}
+	history := services.NewHistoryService()
+	usageService.SetHistory(history)
+	return usageService, history
+}
+
 func init() {
 	RootCmd.AddCommand(runCmd)
 
 	// Local flags for run command
 	runCmd.Flags().BoolVarP(&daemonMode, "daemon", "d", false, "Run as daemon (background process)")
+	runCmd.Flags().BoolVar(&demoMode, "demo", false, "Show synthetic, clearly labelled data cycling through green, yellow, and red (no ccusage needed)")
+	runCmd.Flags().BoolVar(&stopMode, "stop", false, "Stop the running instance via its control socket")
+	runCmd.Flags().BoolVar(&checkMode, "check", false, "Validate config, resolve ccusage, fetch and parse once, then exit (non-zero code per failure class)")
+	runCmd.Flags().BoolVar(&printMode, "print", false, "Fetch today's usage once, print it, then exit (non-zero when ccusage fails)")
+	runCmd.Flags().StringVar(&printFormat, "format", printFormatText, "Output for --print: text, json (versioned, see README), or json-schema")
+	runCmd.Flags().StringVar(&validatePath, "validate-config", "", "Check this config file against the JSON Schema, report problems with line:column, then exit")
+	runCmd.Flags().StringVar(&fixturePath, "fixture", "", "Print what would be displayed for a saved `ccusage daily --json` output, then exit")
+	runCmd.Flags().StringVar(&fixtureDate, "fixture-date", "", "Treat this date (YYYY-MM-DD) as today for --fixture (default: latest date in the fixture)")
+	runCmd.Flags().StringVar(&replayDir, "replay", "", "Feed the ccusage reports saved by record_dir in this directory through the pipeline, print each result, then exit")
+	runCmd.Flags().Float64Var(&replaySpeed, "replay-speed", 60, "How many times faster than recorded --replay runs (pauses capped at 1s); 0 for no pauses")
+	runCmd.Flags().StringVar(&plasmoidFeedPath, "plasmoid-feed", "", "Run without a tray icon, keeping this JSON file up to date for a KDE Plasma widget (bare flag: "+services.DefaultPlasmoidFeedPath()+")")
+	runCmd.Flags().Lookup("plasmoid-feed").NoOptDefVal = services.DefaultPlasmoidFeedPath()
+	runCmd.Flags().StringVar(&controlSocket, "control-socket", control.DefaultSocketPath(), "Path to the control socket")
 	runCmd.Flags().Int("update-interval", 0, "Update interval in seconds")
 	runCmd.Flags().Float64("yellow-threshold", 0, "Yellow alert threshold ($)")
 	runCmd.Flags().Float64("red-threshold", 0, "Red alert threshold ($)")
+	runCmd.Flags().Float64("weekly-budget", 0, "Weekly spend goal ($); 0 disables")
+	runCmd.Flags().Float64("monthly-budget", 0, "Monthly spend goal ($) for the month-end forecast; 0 disables")
+	runCmd.Flags().Float64("session-cap", 0, "Alert when a single session's cost reaches this ($); 0 disables")
 	runCmd.Flags().String("ccusage-path", "", "Path to ccusage binary")
 	runCmd.Flags().Int("cache-window", 0, "Cache window in seconds")
 	runCmd.Flags().Int("cmd-timeout", 0, "Command timeout in seconds")
+	runCmd.Flags().Bool("watch-data-dirs", false, "Refresh immediately when Claude writes new usage data")
+	runCmd.Flags().Bool("screen-reader", false, "Use words instead of emoji in the title and menu")
+	runCmd.Flags().String("title-mode", "", "Menu bar title: full or compact (status only)")
+	runCmd.Flags().String("title-display", "", "Figure in the menu bar title: cost, tokens, percent, or none")
+	runCmd.Flags().Int("title-max-width", 0, "Truncate the menu bar title to this many display cells; 0 means no limit")
+	runCmd.Flags().String("title-truncation", "", "How a title over --title-max-width is shortened: end, middle, or segments")
+	runCmd.Flags().String("display-format", "", `Menu bar title template, or a preset such as "@percent"`)
+	runCmd.Flags().Bool("activity-indicator", false, "End the title with ● while Claude Code is generating, ○ otherwise")
+	runCmd.Flags().String("red-sound", "", `Sound played when usage turns red: "system" or an audio file's absolute path`)
+	runCmd.Flags().String("status-palette", "", "Status symbols: default, shapes, or blue-orange for color blindness")
+	runCmd.Flags().String("quiet-until", "", "Silence alerts through this date (YYYY-MM-DD)")
+	runCmd.Flags().String("quiet-hours", "", "Pause polling and notifications daily during this window (HH:MM-HH:MM)")
+	runCmd.Flags().String("team-dir", "", "Shared directory of teammates' ccusage JSON exports")
+	runCmd.Flags().String("claude-data-dir", "", "Claude config directory for ccusage to read (sets CLAUDE_CONFIG_DIR)")
+	runCmd.Flags().String("otlp-endpoint", "", "OpenTelemetry collector OTLP/HTTP URL for poll traces")
+	runCmd.Flags().Int("poll-reliability-warning", 0, "Warn when fewer than this % of the last day's polls succeeded; 0 disables")
+	runCmd.Flags().Int("poll-failure-alert", 0, "Notify when polls have failed for this many minutes, and on recovery; 0 disables")
+	runCmd.Flags().Int("log-output-length", 0, "Bytes of ccusage output quoted in warning logs (default 128)")
+	runCmd.Flags().String("ccusage-dump-file", "", "Append every raw ccusage response to this file")
+	runCmd.Flags().String("record-dir", "", "Save each distinct raw ccusage report to this directory for --replay")
+	runCmd.Flags().String("ledger-csv", "", "Append each finished day's totals to this CSV file")
+	runCmd.Flags().String("http-listen", "", "Serve the status to editor plugins on this loopback host:port")
+	runCmd.Flags().Bool("http-pprof", false, "Serve Go runtime profiles under /debug/pprof/ on the status API (needs http_token)")
 }
 
 func mergeConfig(config *models.Config, cmd *cobra.Command) error {
@@ -89,6 +195,18 @@ func mergeConfig(config *models.Config, cmd *cobra.Command) error {
 		v, _ := flags.GetFloat64("red-threshold")
 		config.RedThreshold = v
 	}
+	if flags.Changed("weekly-budget") {
+		v, _ := flags.GetFloat64("weekly-budget")
+		config.WeeklyBudget = v
+	}
+	if flags.Changed("monthly-budget") {
+		v, _ := flags.GetFloat64("monthly-budget")
+		config.MonthlyBudget = v
+	}
+	if flags.Changed("session-cap") {
+		v, _ := flags.GetFloat64("session-cap")
+		config.SessionCap = v
+	}
 	if flags.Changed("ccusage-path") {
 		v, _ := flags.GetString("ccusage-path")
 		config.CCUsagePath = v
@@ -101,6 +219,98 @@ func mergeConfig(config *models.Config, cmd *cobra.Command) error {
 		v, _ := flags.GetInt("cmd-timeout")
 		config.CmdTimeout = v
 	}
+	if flags.Changed("title-mode") {
+		v, _ := flags.GetString("title-mode")
+		config.TitleMode = models.TitleMode(v)
+	}
+	if flags.Changed("title-display") {
+		v, _ := flags.GetString("title-display")
+		config.TitleDisplay = models.TitleDisplay(v)
+	}
+	if flags.Changed("title-max-width") {
+		v, _ := flags.GetInt("title-max-width")
+		config.TitleMaxWidth = v
+	}
+	if flags.Changed("title-truncation") {
+		v, _ := flags.GetString("title-truncation")
+		config.TitleTruncation = models.TitleTruncation(v)
+	}
+	if flags.Changed("activity-indicator") {
+		v, _ := flags.GetBool("activity-indicator")
+		config.ActivityIndicator = v
+	}
+	if flags.Changed("display-format") {
+		v, _ := flags.GetString("display-format")
+		config.DisplayFormat = v
+	}
+	if flags.Changed("red-sound") {
+		v, _ := flags.GetString("red-sound")
+		config.RedSound = v
+	}
+	if flags.Changed("status-palette") {
+		v, _ := flags.GetString("status-palette")
+		config.StatusPalette = models.StatusPalette(v)
+	}
+	if flags.Changed("screen-reader") {
+		v, _ := flags.GetBool("screen-reader")
+		config.ScreenReader = v
+	}
+	if flags.Changed("watch-data-dirs") {
+		v, _ := flags.GetBool("watch-data-dirs")
+		config.WatchDataDirs = v
+	}
+	if flags.Changed("quiet-until") {
+		v, _ := flags.GetString("quiet-until")
+		config.QuietUntil = v
+	}
+	if flags.Changed("quiet-hours") {
+		v, _ := flags.GetString("quiet-hours")
+		config.QuietHours = v
+	}
+	if flags.Changed("team-dir") {
+		v, _ := flags.GetString("team-dir")
+		config.TeamDir = v
+	}
+	if flags.Changed("claude-data-dir") {
+		v, _ := flags.GetString("claude-data-dir")
+		config.ClaudeDataDir = v
+	}
+	if flags.Changed("otlp-endpoint") {
+		v, _ := flags.GetString("otlp-endpoint")
+		config.OTLPEndpoint = v
+	}
+	if flags.Changed("poll-reliability-warning") {
+		v, _ := flags.GetInt("poll-reliability-warning")
+		config.PollReliabilityWarning = v
+	}
+	if flags.Changed("poll-failure-alert") {
+		v, _ := flags.GetInt("poll-failure-alert")
+		config.PollFailureAlert = v
+	}
+	if flags.Changed("log-output-length") {
+		v, _ := flags.GetInt("log-output-length")
+		config.LogOutputLength = v
+	}
+	if flags.Changed("ccusage-dump-file") {
+		v, _ := flags.GetString("ccusage-dump-file")
+		config.CCUsageDumpFile = v
+	}
+	if flags.Changed("record-dir") {
+		v, _ := flags.GetString("record-dir")
+		config.RecordDir = v
+	}
+	if flags.Changed("ledger-csv") {
+		v, _ := flags.GetString("ledger-csv")
+		config.LedgerCSV = v
+	}
+	if flags.Changed("http-listen") {
+		v, _ := flags.GetString("http-listen")
+		config.HTTPListen = v
+	}
+	if flags.Changed("http-pprof") {
+		v, _ := flags.GetBool("http-pprof")
+		config.HTTPPprof = v
+	}
 
 	return config.Validate()
 }
@@ -166,7 +376,65 @@ func runAsDaemon(cmd *cobra.Command) error {
 	// capture this output, and so deferred cleanup in the caller still runs.
 	out := cmd.OutOrStdout()
 	fmt.Fprintf(out, "CC Daily Use Bar started as daemon (PID: %d)\n", child.Process.Pid)
-	fmt.Fprintf(out, "To stop: kill %d\n", child.Process.Pid)
+	fmt.Fprintf(out, "To stop: cc-dailyuse-bar run --stop (or kill %d)\n", child.Process.Pid)
+
+	return nil
+}
 
+// stopRunningInstance asks the live process to quit over the control socket.
+func stopRunningInstance(cmd *cobra.Command) error {
+	resp, err := control.Send(controlSocket, control.Request{Command: control.CmdQuit}, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	if !resp.OK {
+		return lib.NewError(lib.ErrCodeSystem, resp.Error)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "CC Daily Use Bar is shutting down")
 	return nil
 }
+
+// migrateLegacyConfig moves a config from the home directory, where it
+// was kept before the XDG config directory, into place before it's loaded,
+// logging what it did so upgrading users can find their settings.
+func migrateLegacyConfig() {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	migration, err := services.MigrateLegacyConfig(services.DefaultPaths(), home)
+	switch {
+	case err != nil:
+		logger.Warn("Failed to migrate legacy config", map[string]interface{}{
+			"error": err.Error(),
+		})
+	case migration == nil:
+	case migration.Ignored:
+		logger.Warn("Ignoring legacy config; a config already exists in the config directory", map[string]interface{}{
+			"legacy": migration.From,
+			"config": services.DefaultPaths().ConfigDir(),
+		})
+	default:
+		logger.Info("Migrated legacy config", map[string]interface{}{
+			"from":   migration.From,
+			"to":     migration.To,
+			"backup": migration.Backup,
+		})
+	}
+}
+
+// warnDirPermissions logs the app directories other users could tamper
+// with at startup; `doctor --yes` corrects them.
+func warnDirPermissions(configPath string) {
+	for _, issue := range services.DefaultPaths().WithConfigFile(configPath).CheckPermissions() {
+		hint := "run 'cc-dailyuse-bar doctor --yes'"
+		if !issue.Fixable {
+			hint = "change its owner with chown"
+		}
+		logger.Warn("App directory is "+issue.Problem+"; "+hint, map[string]interface{}{
+			"role": issue.Dir.Role,
+			"path": issue.Dir.Path,
+			"mode": fmt.Sprintf("%04o", issue.Mode),
+		})
+	}
+}
@@ -5,11 +5,15 @@ package cmd
 import (
 	"os"
 	"os/signal"
+	"runtime"
 	"syscall"
 
 	"github.com/getlantern/systray"
 	"github.com/spf13/cobra"
 
+	"cc-dailyuse-bar/src/internal/control"
+	"cc-dailyuse-bar/src/internal/dbus"
+	"cc-dailyuse-bar/src/internal/httpapi"
 	"cc-dailyuse-bar/src/internal/tray"
 	"cc-dailyuse-bar/src/models"
 	"cc-dailyuse-bar/src/services"
@@ -21,7 +25,7 @@ func init() {
 
 func startTrayApp(cmd *cobra.Command, config *models.Config) error {
 	// Initialize Usage Service
-	usageService := services.NewUsageService(config)
+	usageService, history := newRunUsageService(config)
 
 	// Setup signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -37,7 +41,91 @@ func startTrayApp(cmd *cobra.Command, config *models.Config) error {
 	}()
 
 	// Initialize Tray Runner
-	runner := tray.NewRunner(config, usageService)
+	live := models.NewLiveConfig(config)
+	runner := tray.NewRunner(live, usageService)
+	configService := services.NewConfigService()
+	if cfgFile != "" {
+		configService.SetConfigPath(cfgFile)
+	}
+	runner.SetConfigService(configService)
+	runner.SetHistoryService(history) // nil in demo mode hides the histogram
+	runner.SetStatusFile(services.NewStatusFile())
+
+	// Local control socket for `ctl` and `run --stop`. Failing to bind (e.g.
+	// another instance owns it) shouldn't stop the tray from starting.
+	reloader := &configReloader{
+		cmd:           cmd,
+		config:        live,
+		configService: configService,
+		usageService:  usageService,
+	}
+	handler := &daemonControl{
+		config:       live,
+		usageService: usageService,
+		reloader:     reloader,
+		quit: func() {
+			usageService.StopPolling()
+			systray.Quit()
+		},
+	}
+	controlServer := control.NewServer(controlSocket, handler)
+	if err := controlServer.Start(); err != nil {
+		logger.Warn("Control socket unavailable", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	defer controlServer.Close()
+
+	// org.petems.CCDailyUse on the session bus for GNOME and KDE widgets.
+	// Desktops without a session bus just go without.
+	if runtime.GOOS == "linux" {
+		bus := dbus.NewServer(handler)
+		if err := bus.Start(); err != nil {
+			logger.Info("D-Bus service unavailable", map[string]interface{}{
+				"error": err.Error(),
+			})
+		} else {
+			runner.SetBusService(bus)
+			defer bus.Close()
+		}
+	}
+
+	// Localhost status API and dashboard for editor plugins and browsers.
+	if config.HTTPListen != "" {
+		api := httpapi.NewServer(config.HTTPListen)
+		api.SetDashboard(&dashboardSource{config: live, history: history})
+		api.SetToken(config.HTTPToken)
+		if config.HTTPPprof {
+			api.EnableProfiling()
+		}
+		if config.HTTPToken != "" {
+			for _, path := range configService.ReadableByOthers() {
+				logger.Warn("http_token is readable by other users; run chmod 600 on the config file", map[string]interface{}{
+					"path": path,
+				})
+			}
+		}
+		if err := api.Start(); err != nil {
+			logger.Warn("Status API unavailable", map[string]interface{}{
+				"error": err.Error(),
+			})
+		} else {
+			runner.SetStatusAPI(api)
+			defer api.Close()
+		}
+	}
+
+	// SIGHUP reloads the configuration file in place.
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for range hupChan {
+			logger.Info("Received SIGHUP, reloading configuration")
+			if err := reloader.Reload(); err == nil {
+				_, _ = usageService.Refresh()
+			}
+		}
+	}()
 
 	// Start the application (blocks until exit)
 	runner.Run()
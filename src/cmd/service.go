@@ -13,6 +13,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/services"
 )
 
 //go:embed templates/launchagent.plist
@@ -164,10 +165,27 @@ func runServiceInstall(cmd *cobra.Command, binOverride string) error {
 	fmt.Fprintf(w, "LaunchAgent installed: %s\n", plistPath)
 	fmt.Fprintf(w, "Binary:                %s\n", binPath)
 	fmt.Fprintf(w, "Logs:                  %s\n", logs)
+	fmt.Fprintf(w, "Post-install check:    %s\n", postInstallCheck())
 	fmt.Fprintln(w, "Disable autostart with `cc-dailyuse-bar service uninstall`.")
 	return nil
 }
 
+// postInstallCheck runs the same self-check as `run --check` so a broken
+// ccusage setup is flagged at install time rather than as a silent "CC
+// Unknown" after the next login. It never fails the install itself.
+// Overridable in tests so installs don't spawn the real ccusage.
+var postInstallCheck = func() string {
+	configService := services.NewConfigService()
+	if cfgFile != "" {
+		configService.SetConfigPath(cfgFile)
+	}
+	config, err := configService.Load()
+	if err != nil {
+		return fmt.Sprintf("failed (invalid config at %s: %v)", configService.GetConfigPath(), err)
+	}
+	return selfCheckSummary(config)
+}
+
 func runServiceUninstall(cmd *cobra.Command, purgeLogs bool) error {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -35,6 +35,13 @@ func TestRenderLaunchAgent_SubstitutesTokens(t *testing.T) {
 	assert.Contains(t, rendered, "<string>com.cc-dailyuse-bar</string>", "Label should be preserved")
 }
 
+func stubPostInstallCheck(t *testing.T) {
+	t.Helper()
+	prev := postInstallCheck
+	postInstallCheck = func() string { return "skipped" }
+	t.Cleanup(func() { postInstallCheck = prev })
+}
+
 func TestResolveBinPath_OverrideTakesPrecedence(t *testing.T) {
 	dir := t.TempDir()
 	target := filepath.Join(dir, "my-binary")
@@ -84,6 +91,7 @@ func TestRunServiceInstall_WritesPlistAndCallsLaunchctl(t *testing.T) {
 		return nil, nil
 	}
 	t.Cleanup(func() { execLaunchctl = prev })
+	stubPostInstallCheck(t)
 
 	buf := new(bytes.Buffer)
 	cmd := serviceInstallCmd
@@ -120,6 +128,7 @@ func TestRunServiceInstall_IsIdempotent(t *testing.T) {
 	prev := execLaunchctl
 	execLaunchctl = func(args ...string) ([]byte, error) { return nil, nil }
 	t.Cleanup(func() { execLaunchctl = prev })
+	stubPostInstallCheck(t)
 
 	cmd := serviceInstallCmd
 	cmd.SetOut(new(bytes.Buffer))
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/services"
+)
+
+var (
+	settingsOutput  string
+	settingsHistory bool
+	settingsForce   bool
+)
+
+var exportSettingsCmd = &cobra.Command{
+	Use:   "export-settings",
+	Short: "Bundle the config (and optionally history) into one archive",
+	Long: `Write a .tar.gz holding the config file and its local overlay, plus with
+--history everything in the data directory: usage history, monthly
+archives, and the ledger state. Restore it on another machine with
+import-settings, or attach it to a bug report.
+
+Config values are copied as they are. Encrypt secrets first with
+"config encrypt" (keychain references don't leave this machine) or check
+the archive before sharing it.`,
+	Example: `  cc-dailyuse-bar export-settings -o cc-settings.tar.gz
+  cc-dailyuse-bar export-settings --history -o ~/Sync/cc-settings.tar.gz`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configService := services.NewConfigService()
+		if cfgFile != "" {
+			configService.SetConfigPath(cfgFile)
+		}
+		paths := services.DefaultPaths().WithConfigFile(cfgFile)
+
+		var buf bytes.Buffer
+		manifest, err := services.ExportSettings(&buf, configService, paths, settingsHistory, Version, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if settingsOutput == "" || settingsOutput == "-" {
+			_, err := cmd.OutOrStdout().Write(buf.Bytes())
+			return err
+		}
+		if err := os.WriteFile(settingsOutput, buf.Bytes(), 0o600); err != nil {
+			return lib.WrapError(err, lib.ErrCodeSystem, "failed to write settings archive")
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %s and %d data files to %s\n", describeArchivedConfig(manifest), len(manifest.DataFiles), settingsOutput)
+		return nil
+	},
+}
+
+var importSettingsCmd = &cobra.Command{
+	Use:   "import-settings <archive>",
+	Short: "Restore a settings archive from export-settings",
+	Long: `Restore the config, and history if the archive has it, from an archive
+written by export-settings. The config is validated first and converted to
+the format of this machine's config file where they differ. Files that
+already exist are only replaced with --force. Restart the tray afterwards.`,
+	Example: `  cc-dailyuse-bar import-settings cc-settings.tar.gz
+  cc-dailyuse-bar import-settings --force - < cc-settings.tar.gz`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configService := services.NewConfigService()
+		if cfgFile != "" {
+			configService.SetConfigPath(cfgFile)
+		}
+		paths := services.DefaultPaths().WithConfigFile(cfgFile)
+
+		in := cmd.InOrStdin()
+		if args[0] != "-" {
+			file, err := os.Open(args[0])
+			if err != nil {
+				return lib.WrapError(err, lib.ErrCodeSystem, "failed to open settings archive")
+			}
+			defer file.Close()
+			in = file
+		}
+
+		manifest, err := services.ImportSettings(in, configService, paths, settingsForce)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "✅ Imported %s and %d data files exported %s from %s\n",
+			describeArchivedConfig(manifest), len(manifest.DataFiles), manifest.Created.Local().Format("2006-01-02 15:04"), manifest.OS)
+		return nil
+	},
+}
+
+// describeArchivedConfig names the config files in an archive.
+func describeArchivedConfig(manifest *services.SettingsManifest) string {
+	switch {
+	case manifest.Config != "" && manifest.Overlay != "":
+		return manifest.Config + " with " + manifest.Overlay
+	case manifest.Config != "":
+		return manifest.Config
+	case manifest.Overlay != "":
+		return manifest.Overlay
+	default:
+		return "no config"
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(exportSettingsCmd)
+	RootCmd.AddCommand(importSettingsCmd)
+	exportSettingsCmd.Flags().StringVarP(&settingsOutput, "output", "o", "", "Write to this file instead of stdout")
+	exportSettingsCmd.Flags().BoolVar(&settingsHistory, "history", false, "Include usage history, monthly archives, and ledger state")
+	importSettingsCmd.Flags().BoolVarP(&settingsForce, "force", "f", false, "Replace existing config and data files")
+}
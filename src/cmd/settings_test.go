@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportSettingsCmd(t *testing.T) {
+	savedCfgFile, savedOutput, savedHistory, savedForce := cfgFile, settingsOutput, settingsHistory, settingsForce
+	t.Cleanup(func() {
+		cfgFile, settingsOutput, settingsHistory, settingsForce = savedCfgFile, savedOutput, savedHistory, savedForce
+	})
+
+	dir := t.TempDir()
+	cfgPath := writeBinaryConfig(t, dir, "/usr/local/bin/ccusage")
+	archive := filepath.Join(dir, "settings.tar.gz")
+	_, err := executeWithOutput(t, "export-settings", "--config", cfgPath, "-o", archive)
+	require.NoError(t, err)
+
+	target := filepath.Join(t.TempDir(), "config.yaml")
+	out, err := executeWithOutput(t, "import-settings", "--config", target, archive)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Imported config.yaml and 0 data files")
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "/usr/local/bin/ccusage")
+
+	_, err = executeWithOutput(t, "import-settings", "--config", target, archive)
+	assert.ErrorContains(t, err, "--force")
+}
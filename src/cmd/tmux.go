@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cc-dailyuse-bar/src/models"
+	"cc-dailyuse-bar/src/services"
+)
+
+var tmuxMaxAge int
+
+// tmuxStatePath is where the tmux command saves the result of its own
+// ccusage runs between status refreshes.
+var tmuxStatePath = filepath.Join(services.DefaultPaths().CacheDir(), "tmux-status.json")
+
+// statusFileSlack is added to update_interval when judging whether the
+// tray's status file is fresh: the tray writes it after each poll, which
+// finishes some seconds past the interval.
+const statusFileSlack = 30 * time.Second
+
+// tmuxColors maps AlertStatus color names to tmux style attributes.
+var tmuxColors = map[string]string{
+	models.Green.ColorName():   "fg=green",
+	models.Yellow.ColorName():  "fg=yellow",
+	models.Red.ColorName():     "fg=red,bold",
+	models.Unknown.ColorName(): "fg=colour244",
+}
+
+// tmuxBlueOrangeColors replaces tmuxColors for the blue-orange
+// status_palette; critical is reversed so it differs from high by more
+// than hue.
+var tmuxBlueOrangeColors = map[string]string{
+	models.Green.ColorName():   "fg=blue",
+	models.Yellow.ColorName():  "fg=colour208",
+	models.Red.ColorName():     "fg=colour208,bold,reverse",
+	models.Unknown.ColorName(): "fg=colour244",
+}
+
+var tmuxCmd = &cobra.Command{
+	Use:   "tmux",
+	Short: "Print today's usage colored for the tmux status line",
+	Long: `Print today's spend as a compact string with tmux style codes, e.g.
+"#[fg=yellow]CC $12.40#[default]", for status-right.
+
+tmux re-runs the command on every status refresh, so it avoids spawning
+ccusage each time: it prints the running tray's status file when that was
+written within --max-age seconds, then the result it saved last time, and
+only otherwise queries ccusage once and saves the result.`,
+	Example: `  # ~/.tmux.conf
+  set -g status-right '#(cc-dailyuse-bar tmux) %H:%M'
+  set -g status-interval 15`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configService := services.NewConfigService()
+		if cfgFile != "" {
+			configService.SetConfigPath(cfgFile)
+		}
+		config, err := configService.Load()
+		if err != nil {
+			return withExitCode(ExitConfig, fmt.Errorf("failed to load config: %w", err))
+		}
+
+		maxAge := time.Duration(config.UpdateInterval)*time.Second + statusFileSlack
+		if tmuxMaxAge > 0 {
+			maxAge = time.Duration(tmuxMaxAge) * time.Second
+		}
+		snapshot := loadTmuxSnapshot(config, maxAge,
+			services.NewStatusFile(), services.NewStatusFileAt(tmuxStatePath))
+		fmt.Fprintln(cmd.OutOrStdout(), tmuxStatus(snapshot, config))
+		return nil
+	},
+}
+
+// loadTmuxSnapshot returns the first of the tray's status file and the
+// tmux cache that was written within maxAge, or else queries ccusage and
+// saves the result to cache. Failed queries are saved too, so a broken
+// ccusage isn't retried on every refresh.
+func loadTmuxSnapshot(config *models.Config, maxAge time.Duration, tray, cache *services.StatusFile) models.StatusSnapshot {
+	for _, sf := range []*services.StatusFile{tray, cache} {
+		snapshot, written, err := sf.Read()
+		if err == nil && snapshot.Version == models.StatusSnapshotVersion && time.Since(written) < maxAge {
+			return snapshot
+		}
+	}
+
+	state, _ := services.NewUsageService(config).UpdateUsage()
+	if state == nil {
+		state = &models.UsageState{}
+	}
+	snapshot := models.NewStatusSnapshot(state, config)
+	if err := cache.Write(snapshot); err != nil {
+		logger.Warn("Failed to save tmux status", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return snapshot
+}
+
+// tmuxStatus renders snapshot as "#[fg=<color>]CC $12.40#[default]".
+func tmuxStatus(snapshot models.StatusSnapshot, config *models.Config) string {
+	colors := tmuxColors
+	if config.StatusPalette == models.StatusPaletteBlueOrange {
+		colors = tmuxBlueOrangeColors
+	}
+	style, ok := colors[snapshot.Status]
+	if !ok || !snapshot.Available || snapshot.Status == models.Unknown.ColorName() {
+		return "#[" + colors[models.Unknown.ColorName()] + "]CC ?#[default]"
+	}
+	return fmt.Sprintf("#[%s]CC %s#[default]", style, config.TitleCostFormat().Format(snapshot.DailyCost))
+}
+
+func init() {
+	RootCmd.AddCommand(tmuxCmd)
+	tmuxCmd.Flags().IntVar(&tmuxMaxAge, "max-age", 0, "Seconds a saved result stays fresh (default: update_interval plus 30)")
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/internal/testhelpers"
+	"cc-dailyuse-bar/src/models"
+	"cc-dailyuse-bar/src/services"
+)
+
+func TestTmuxStatus(t *testing.T) {
+	config := models.ConfigDefaults()
+	tests := []struct {
+		name     string
+		snapshot models.StatusSnapshot
+		want     string
+	}{
+		{"green", models.StatusSnapshot{Available: true, Status: "green", DailyCost: 4}, "#[fg=green]CC $4.00#[default]"},
+		{"yellow", models.StatusSnapshot{Available: true, Status: "yellow", DailyCost: 12.4}, "#[fg=yellow]CC $12.40#[default]"},
+		{"red", models.StatusSnapshot{Available: true, Status: "red", DailyCost: 25}, "#[fg=red,bold]CC $25.00#[default]"},
+		{"unknown", models.StatusSnapshot{Available: true, Status: "unknown"}, "#[fg=colour244]CC ?#[default]"},
+		{"unavailable", models.StatusSnapshot{Status: "red", DailyCost: 25}, "#[fg=colour244]CC ?#[default]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tmuxStatus(tt.snapshot, config))
+		})
+	}
+}
+
+func TestTmuxStatus_BlueOrange(t *testing.T) {
+	config := models.ConfigDefaults()
+	config.StatusPalette = models.StatusPaletteBlueOrange
+	assert.Equal(t, "#[fg=blue]CC $4.00#[default]",
+		tmuxStatus(models.StatusSnapshot{Available: true, Status: "green", DailyCost: 4}, config))
+	assert.Equal(t, "#[fg=colour208,bold,reverse]CC $25.00#[default]",
+		tmuxStatus(models.StatusSnapshot{Available: true, Status: "red", DailyCost: 25}, config))
+}
+
+func TestLoadTmuxSnapshot(t *testing.T) {
+	testhelpers.RequireShell(t)
+	dir := t.TempDir()
+	config := models.ConfigDefaults()
+	config.YellowThreshold = 10
+	config.RedThreshold = 20
+	counter := filepath.Join(dir, "runs")
+	config.CCUsagePath = filepath.Join(dir, "ccusage")
+	today := time.Now().Format("2006-01-02")
+	require.NoError(t, os.WriteFile(config.CCUsagePath, []byte("#!/bin/bash\n"+
+		`[ "$1" = daily ] && echo run >> `+counter+"\n"+
+		`echo '{"daily":[{"date":"`+today+`","totalTokens":90,"totalCost":12.5}]}'`+"\n"), 0o755))
+	runs := func() int {
+		data, _ := os.ReadFile(counter)
+		return len(data) / len("run\n")
+	}
+
+	tray := services.NewStatusFileAt(filepath.Join(dir, "status.json"))
+	cache := services.NewStatusFileAt(filepath.Join(dir, "tmux-status.json"))
+
+	// Nothing cached: query ccusage once and save the result.
+	snapshot := loadTmuxSnapshot(config, time.Minute, tray, cache)
+	assert.Equal(t, "yellow", snapshot.Status)
+	assert.Equal(t, 1, runs())
+
+	// Fresh cache: no new query.
+	snapshot = loadTmuxSnapshot(config, time.Minute, tray, cache)
+	assert.Equal(t, 12.5, snapshot.DailyCost)
+	assert.Equal(t, 1, runs())
+
+	// The running tray's status file wins over the cache.
+	require.NoError(t, tray.Write(models.StatusSnapshot{Version: models.StatusSnapshotVersion, Available: true, Status: "red", DailyCost: 30}))
+	snapshot = loadTmuxSnapshot(config, time.Minute, tray, cache)
+	assert.Equal(t, "red", snapshot.Status)
+	assert.Equal(t, 1, runs())
+
+	// Both stale: query again and save the result.
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(tray.Path(), old, old))
+	require.NoError(t, os.Chtimes(cache.Path(), old, old))
+	snapshot = loadTmuxSnapshot(config, time.Minute, tray, cache)
+	assert.Equal(t, "yellow", snapshot.Status)
+	_, written, err := cache.Read()
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), written, time.Minute)
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+	"cc-dailyuse-bar/src/services"
+)
+
+// runValidateConfig checks the file at path against the embedded JSON
+// Schema, printing each violation as path:line:column so editors and CI
+// logs can jump to it. Failures exit with ExitConfig.
+func runValidateConfig(cmd *cobra.Command, path string) error {
+	out := cmd.OutOrStdout()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return withExitCode(ExitConfig, lib.WrapError(err, lib.ErrCodeConfig, "failed to read config file"))
+	}
+
+	// JSON parses as YAML with accurate positions; TOML is converted first,
+	// so its violations are reported by field only.
+	withPositions := services.ConfigFormatFor(path) != services.FormatTOML
+	if !withPositions {
+		if data, err = services.ConfigToYAML(services.FormatTOML, data); err != nil {
+			fmt.Fprintf(out, "%s: %v\n", path, err)
+			return withExitCode(ExitConfig, lib.WrapError(err, lib.ErrCodeConfig,
+				fmt.Sprintf("%s is not a valid configuration", path)))
+		}
+	}
+
+	violations, err := models.ValidateConfigYAML(data)
+	if err != nil && len(violations) == 0 {
+		fmt.Fprintf(out, "%s: %v\n", path, err)
+		return withExitCode(ExitConfig, lib.WrapError(err, lib.ErrCodeConfig,
+			fmt.Sprintf("%s is not a valid configuration", path)))
+	}
+	for _, v := range violations {
+		if withPositions {
+			fmt.Fprintf(out, "%s:%d:%d: ", path, v.Line, v.Column)
+		} else {
+			fmt.Fprintf(out, "%s: ", path)
+		}
+		if v.Path != "" {
+			fmt.Fprintf(out, "%s: ", v.Path)
+		}
+		fmt.Fprintln(out, v.Message)
+	}
+	if len(violations) > 0 {
+		return withExitCode(ExitConfig, lib.ValidationError(
+			fmt.Sprintf("%s has %d schema violation(s)", path, len(violations))))
+	}
+
+	fmt.Fprintf(out, "%s: valid\n", path)
+	return nil
+}
+
+var schemaOutput string
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema for the configuration file",
+	Long: `Print the JSON Schema describing config.yaml. Save it and reference it from
+the top of your config for editor completion and inline validation:
+
+  # yaml-language-server: $schema=/path/to/config.schema.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if schemaOutput == "" || schemaOutput == "-" {
+			_, err := cmd.OutOrStdout().Write(models.ConfigSchema())
+			return err
+		}
+		if err := lib.WriteFileAtomic(schemaOutput, models.ConfigSchema()); err != nil {
+			return lib.WrapError(err, lib.ErrCodeSystem, "failed to write schema")
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Schema written to %s\n", schemaOutput)
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configSchemaCmd)
+	configSchemaCmd.Flags().StringVarP(&schemaOutput, "output", "o", "", "Write to this file instead of stdout")
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunValidateConfig(t *testing.T) {
+	savedPath := validatePath
+	t.Cleanup(func() { validatePath = savedPath })
+
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantOut  []string
+	}{
+		{
+			name:     "valid",
+			body:     "ccusage_path: ccusage\nupdate_interval: 30\nyellow_threshold: 10\nred_threshold: 20\ndebug_level: INFO\ncache_window: 10\ncmd_timeout: 30\n",
+			wantCode: ExitOK,
+			wantOut:  []string{"valid"},
+		},
+		{
+			name:     "schema violations",
+			body:     "ccusage_path: ccusage\nupdate_interval: 5\nalert_levels:\n  - {name: x, threshold: cheap}\n",
+			wantCode: ExitConfig,
+			wantOut: []string{
+				"config.yaml:2:18: update_interval: must be >= 10",
+				"config.yaml:4:26: alert_levels[0].threshold: expected number, got string",
+			},
+		},
+		{
+			name:     "semantic error",
+			body:     "ccusage_path: ccusage\nupdate_interval: 30\nyellow_threshold: 20\nred_threshold: 10\ndebug_level: INFO\ncache_window: 10\ncmd_timeout: 30\n",
+			wantCode: ExitConfig,
+			wantOut:  []string{"red_threshold must be greater than yellow_threshold"},
+		},
+		{
+			name:     "malformed yaml",
+			body:     "update_interval: [\n",
+			wantCode: ExitConfig,
+			wantOut:  []string{"invalid YAML"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "config.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.body), 0o644))
+
+			out, err := executeWithOutput(t, "run", "--validate-config", path)
+			assert.Equal(t, tt.wantCode, exitCode(err), "error: %v", err)
+			for _, want := range tt.wantOut {
+				assert.Contains(t, out, want)
+			}
+		})
+	}
+
+	_, err := executeWithOutput(t, "run", "--validate-config", filepath.Join(dir, "missing.yaml"))
+	assert.Equal(t, ExitConfig, exitCode(err))
+}
+
+func TestRunValidateConfig_OtherFormats(t *testing.T) {
+	savedPath := validatePath
+	t.Cleanup(func() { validatePath = savedPath })
+
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte("{\n  \"update_interval\": 5\n}\n"), 0o644))
+	out, err := executeWithOutput(t, "run", "--validate-config", jsonPath)
+	assert.Equal(t, ExitConfig, exitCode(err))
+	assert.Contains(t, out, "config.json:2:22: update_interval: must be >= 10")
+
+	tomlPath := filepath.Join(dir, "config.toml")
+	require.NoError(t, os.WriteFile(tomlPath, []byte("update_interval = 5\n"), 0o644))
+	out, err = executeWithOutput(t, "run", "--validate-config", tomlPath)
+	assert.Equal(t, ExitConfig, exitCode(err))
+	assert.Contains(t, out, "config.toml: update_interval: must be >= 10")
+}
+
+func TestConfigSchemaCmd(t *testing.T) {
+	savedOutput := schemaOutput
+	t.Cleanup(func() { schemaOutput = savedOutput })
+
+	out, err := executeWithOutput(t, "config", "schema")
+	require.NoError(t, err)
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &schema))
+	assert.Equal(t, "object", schema["type"])
+
+	path := filepath.Join(t.TempDir(), "config.schema.json")
+	_, err = executeWithOutput(t, "config", "schema", "-o", path)
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, out, string(data))
+}
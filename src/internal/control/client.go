@@ -0,0 +1,31 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+)
+
+// Send delivers req to the instance listening on path and returns its reply.
+// A non-OK reply is returned as-is; only transport failures are errors.
+func Send(path string, req Request, timeout time.Duration) (*Response, error) {
+	conn, err := net.DialTimeout("unix", path, timeout)
+	if err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeSystem, "no running instance found (is cc-dailyuse-bar running?)")
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeSystem, "failed to send control request")
+	}
+
+	var resp Response
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&resp); err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeSystem, "failed to read control response")
+	}
+	return &resp, nil
+}
@@ -0,0 +1,51 @@
+// Package control implements the local control socket that lets scripts
+// (and `cc-dailyuse-bar ctl`) talk to a running instance.
+//
+// The protocol is deliberately tiny: a client connects to a Unix domain
+// socket, writes one JSON Request terminated by a newline, reads one JSON
+// Response, and the server closes the connection.
+package control
+
+import (
+	"path/filepath"
+
+	"github.com/adrg/xdg"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+// Supported commands.
+const (
+	CmdStatus       = "status"
+	CmdRefresh      = "refresh"
+	CmdRefreshAsync = "refresh-async"
+	CmdReloadConfig = "reload-config"
+	CmdSetThreshold = "set-threshold"
+	CmdQuit         = "quit"
+)
+
+// Commands lists every supported command, in help order.
+var Commands = []string{CmdStatus, CmdRefresh, CmdRefreshAsync, CmdReloadConfig, CmdSetThreshold, CmdQuit}
+
+// Request is a single command sent to the running instance.
+type Request struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+}
+
+// Response is the running instance's reply to a Request. Its state
+// follows the app's internals, but carries models.SchemaVersion and the
+// same status names as the status file.
+type Response struct {
+	SchemaVersion int                `json:"schema_version"`
+	OK            bool               `json:"ok"`
+	Error         string             `json:"error,omitempty"`
+	Message       string             `json:"message,omitempty"`
+	State         *models.UsageState `json:"state,omitempty"`
+}
+
+// DefaultSocketPath returns the control socket location under the XDG
+// runtime directory.
+func DefaultSocketPath() string {
+	return filepath.Join(xdg.RuntimeDir, "cc-dailyuse-bar", "control.sock")
+}
@@ -0,0 +1,251 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// connTimeout bounds how long a single client may hold a connection.
+const connTimeout = 30 * time.Second
+
+// Handler carries out control commands against the running application.
+type Handler interface {
+	Status() (*models.UsageState, error)
+	Refresh() (*models.UsageState, error)
+	ReloadConfig() error
+	SetThresholds(yellow, red float64) error
+	Quit()
+}
+
+// Server accepts control connections on a Unix domain socket.
+type Server struct {
+	path     string
+	handler  Handler
+	listener net.Listener
+	logger   *lib.Logger
+	wg       sync.WaitGroup
+	closeMu  sync.Mutex
+	closed   bool
+
+	refreshing atomic.Bool // a refresh-async is running
+}
+
+// NewServer creates a Server that will listen on path once started.
+func NewServer(path string, handler Handler) *Server {
+	return &Server{
+		path:    path,
+		handler: handler,
+		logger:  lib.NewLogger("control-server"),
+	}
+}
+
+// Start binds the socket and serves connections in the background. A stale
+// socket left behind by a crashed instance is removed; a live one means
+// another instance is already running and is reported as an error.
+func (s *Server) Start() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to create control socket directory")
+	}
+
+	if _, err := os.Stat(s.path); err == nil {
+		if !IsStale(s.path) {
+			return lib.NewError(lib.ErrCodeSystem, fmt.Sprintf("another instance is already listening on %s", s.path))
+		}
+		if err := os.Remove(s.path); err != nil {
+			return lib.WrapError(err, lib.ErrCodeSystem, "failed to remove stale control socket")
+		}
+	}
+
+	listener, err := net.Listen("unix", s.path)
+	if err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to listen on control socket")
+	}
+	if err := os.Chmod(s.path, 0o600); err != nil {
+		listener.Close()
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to restrict control socket permissions")
+	}
+	s.listener = listener
+
+	s.wg.Add(1)
+	go s.acceptLoop()
+
+	s.logger.Info("Control socket listening", map[string]interface{}{
+		"path": s.path,
+	})
+	return nil
+}
+
+// IsStale reports whether a socket file exists at path with nothing
+// listening on it, as left behind by an instance that crashed.
+func IsStale(path string) bool {
+	if _, err := os.Stat(path); err != nil {
+		return false
+	}
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return true
+	}
+	conn.Close()
+	return false
+}
+
+// Close stops accepting connections, waits for in-flight requests and
+// background refreshes, and removes the socket file. It is safe to call
+// more than once.
+func (s *Server) Close() error {
+	s.closeMu.Lock()
+	if s.closed || s.listener == nil {
+		s.closeMu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.closeMu.Unlock()
+
+	err := s.listener.Close()
+	s.wg.Wait()
+	if rmErr := os.Remove(s.path); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+func (s *Server) acceptLoop() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			s.logger.Warn("Control socket accept failed", map[string]interface{}{
+				"error": err.Error(),
+			})
+			continue
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(conn)
+		}()
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(connTimeout))
+
+	var req Request
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err == nil || len(line) > 0 {
+		err = json.Unmarshal(line, &req)
+	}
+
+	var resp Response
+	if err != nil {
+		resp = Response{Error: fmt.Sprintf("invalid request: %v", err)}
+	} else {
+		resp = s.dispatch(req)
+	}
+	resp.SchemaVersion = models.SchemaVersion
+
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		s.logger.Warn("Failed to write control response", map[string]interface{}{
+			"command": req.Command,
+			"error":   err.Error(),
+		})
+	}
+
+	// Quit after replying so the client sees the acknowledgement.
+	if resp.OK && req.Command == CmdQuit {
+		go s.handler.Quit()
+	}
+}
+
+func (s *Server) dispatch(req Request) Response {
+	s.logger.Debug("Control command received", map[string]interface{}{
+		"command": req.Command,
+		"args":    req.Args,
+	})
+
+	switch req.Command {
+	case CmdStatus:
+		return stateResponse(s.handler.Status())
+	case CmdRefresh:
+		return stateResponse(s.handler.Refresh())
+	case CmdRefreshAsync:
+		return s.refreshAsync()
+	case CmdReloadConfig:
+		if err := s.handler.ReloadConfig(); err != nil {
+			return Response{Error: err.Error()}
+		}
+		return Response{OK: true, Message: "configuration reloaded"}
+	case CmdSetThreshold:
+		yellow, red, err := parseThresholdArgs(req.Args)
+		if err != nil {
+			return Response{Error: err.Error()}
+		}
+		if err := s.handler.SetThresholds(yellow, red); err != nil {
+			return Response{Error: err.Error()}
+		}
+		return Response{OK: true, Message: fmt.Sprintf("thresholds set to $%.2f/$%.2f", yellow, red)}
+	case CmdQuit:
+		return Response{OK: true, Message: "shutting down"}
+	default:
+		return Response{Error: fmt.Sprintf("unknown command %q", req.Command)}
+	}
+}
+
+// refreshAsync starts a refresh in the background and replies at once, for
+// callers such as Claude Code hooks that mustn't wait on ccusage. Requests
+// arriving while one runs are folded into it. Close waits for it, since
+// the connection being served keeps s.wg above zero while it is added.
+func (s *Server) refreshAsync() Response {
+	if !s.refreshing.CompareAndSwap(false, true) {
+		return Response{OK: true, Message: "refresh already running"}
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.refreshing.Store(false)
+		if _, err := s.handler.Refresh(); err != nil {
+			s.logger.Warn("Background refresh failed", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}()
+	return Response{OK: true, Message: "refresh started"}
+}
+
+func stateResponse(state *models.UsageState, err error) Response {
+	if err != nil {
+		return Response{Error: err.Error(), State: state}
+	}
+	return Response{OK: true, State: state}
+}
+
+func parseThresholdArgs(args []string) (float64, float64, error) {
+	if len(args) != 2 {
+		return 0, 0, lib.ValidationError("set-threshold takes two arguments: <yellow> <red>")
+	}
+	yellow, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		return 0, 0, lib.ValidationError(fmt.Sprintf("invalid yellow threshold %q", args[0]))
+	}
+	red, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		return 0, 0, lib.ValidationError(fmt.Sprintf("invalid red threshold %q", args[1]))
+	}
+	return yellow, red, nil
+}
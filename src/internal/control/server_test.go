@@ -0,0 +1,251 @@
+package control
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/internal/testhelpers"
+	"cc-dailyuse-bar/src/models"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testhelpers.RunSilenced(m))
+}
+
+type fakeHandler struct {
+	mu         sync.Mutex
+	yellow     float64
+	red        float64
+	reloads    int
+	reloadErr  error
+	quit       chan struct{}
+	state      *models.UsageState
+	refreshErr error
+	refreshes  int
+	release    chan struct{} // when set, Refresh waits for it
+}
+
+func newFakeHandler() *fakeHandler {
+	return &fakeHandler{
+		quit:  make(chan struct{}),
+		state: &models.UsageState{DailyCost: 4.2, IsAvailable: true},
+	}
+}
+
+func (f *fakeHandler) Status() (*models.UsageState, error) { return f.state, nil }
+
+func (f *fakeHandler) Refresh() (*models.UsageState, error) {
+	f.mu.Lock()
+	f.refreshes++
+	f.mu.Unlock()
+	if f.release != nil {
+		<-f.release
+	}
+	return f.state, f.refreshErr
+}
+
+func (f *fakeHandler) refreshCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.refreshes
+}
+
+func (f *fakeHandler) ReloadConfig() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reloads++
+	return f.reloadErr
+}
+
+func (f *fakeHandler) SetThresholds(yellow, red float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.yellow, f.red = yellow, red
+	return nil
+}
+
+func (f *fakeHandler) Quit() { close(f.quit) }
+
+// socketPath returns a short socket path; Unix socket paths are limited to
+// roughly 100 bytes, which t.TempDir() can exceed.
+func socketPath(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "ccdb")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "control.sock")
+}
+
+func startServer(t *testing.T, handler Handler) string {
+	t.Helper()
+	path := socketPath(t)
+	server := NewServer(path, handler)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() { server.Close() })
+	return path
+}
+
+func TestServer_Commands(t *testing.T) {
+	handler := newFakeHandler()
+	path := startServer(t, handler)
+
+	resp, err := Send(path, Request{Command: CmdStatus}, time.Second)
+	require.NoError(t, err)
+	assert.True(t, resp.OK)
+	assert.Equal(t, models.SchemaVersion, resp.SchemaVersion)
+	require.NotNil(t, resp.State)
+	assert.Equal(t, 4.2, resp.State.DailyCost)
+
+	resp, err = Send(path, Request{Command: CmdSetThreshold, Args: []string{"5", "12.5"}}, time.Second)
+	require.NoError(t, err)
+	assert.True(t, resp.OK)
+	assert.Equal(t, 5.0, handler.yellow)
+	assert.Equal(t, 12.5, handler.red)
+
+	resp, err = Send(path, Request{Command: CmdReloadConfig}, time.Second)
+	require.NoError(t, err)
+	assert.True(t, resp.OK)
+	assert.Equal(t, 1, handler.reloads)
+
+	handler.refreshErr = errors.New("ccusage failed")
+	resp, err = Send(path, Request{Command: CmdRefresh}, time.Second)
+	require.NoError(t, err)
+	assert.False(t, resp.OK)
+	assert.Equal(t, "ccusage failed", resp.Error)
+}
+
+func TestServer_RejectsBadRequests(t *testing.T) {
+	path := startServer(t, newFakeHandler())
+
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr string
+	}{
+		{"unknown command", Request{Command: "explode"}, "unknown command"},
+		{"missing threshold args", Request{Command: CmdSetThreshold, Args: []string{"5"}}, "two arguments"},
+		{"non-numeric threshold", Request{Command: CmdSetThreshold, Args: []string{"five", "10"}}, "invalid yellow"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := Send(path, tt.req, time.Second)
+			require.NoError(t, err)
+			assert.False(t, resp.OK)
+			assert.Contains(t, resp.Error, tt.wantErr)
+		})
+	}
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("not json\n"))
+	require.NoError(t, err)
+	buf := make([]byte, 256)
+	n, _ := conn.Read(buf)
+	assert.Contains(t, string(buf[:n]), "invalid request")
+}
+
+func TestServer_QuitRepliesThenQuits(t *testing.T) {
+	handler := newFakeHandler()
+	path := startServer(t, handler)
+
+	resp, err := Send(path, Request{Command: CmdQuit}, time.Second)
+	require.NoError(t, err)
+	assert.True(t, resp.OK)
+
+	select {
+	case <-handler.quit:
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler.Quit was not called")
+	}
+}
+
+func TestServer_RefreshAsync(t *testing.T) {
+	handler := newFakeHandler()
+	handler.release = make(chan struct{})
+	path := startServer(t, handler)
+
+	resp, err := Send(path, Request{Command: CmdRefreshAsync}, time.Second)
+	require.NoError(t, err)
+	assert.True(t, resp.OK)
+	assert.Equal(t, "refresh started", resp.Message, "replies without waiting for the refresh")
+	require.Eventually(t, func() bool { return handler.refreshCount() == 1 }, 2*time.Second, 10*time.Millisecond)
+
+	resp, err = Send(path, Request{Command: CmdRefreshAsync}, time.Second)
+	require.NoError(t, err)
+	assert.True(t, resp.OK)
+	assert.Equal(t, "refresh already running", resp.Message)
+
+	close(handler.release)
+	require.Eventually(t, func() bool {
+		resp, err := Send(path, Request{Command: CmdRefreshAsync}, time.Second)
+		return err == nil && resp.Message == "refresh started"
+	}, 2*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return handler.refreshCount() == 2 }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestServer_CloseWaitsForRefreshAsync(t *testing.T) {
+	handler := newFakeHandler()
+	handler.release = make(chan struct{})
+	path := socketPath(t)
+	server := NewServer(path, handler)
+	require.NoError(t, server.Start())
+
+	_, err := Send(path, Request{Command: CmdRefreshAsync}, time.Second)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return handler.refreshCount() == 1 }, 2*time.Second, 10*time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		server.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		t.Fatal("Close returned while the refresh was running")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(handler.release)
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close did not return after the refresh finished")
+	}
+}
+
+func TestServer_StaleAndLiveSockets(t *testing.T) {
+	path := startServer(t, newFakeHandler())
+
+	// A second server must not steal a live socket.
+	err := NewServer(path, newFakeHandler()).Start()
+	assert.ErrorContains(t, err, "already listening")
+
+	assert.False(t, IsStale(path))
+
+	// A leftover file with no listener is cleaned up.
+	stale := socketPath(t)
+	assert.False(t, IsStale(stale), "no file at all")
+	require.NoError(t, os.WriteFile(stale, nil, 0o600))
+	assert.True(t, IsStale(stale))
+	server := NewServer(stale, newFakeHandler())
+	require.NoError(t, server.Start())
+	require.NoError(t, server.Close())
+	require.NoError(t, server.Close(), "Close is idempotent")
+
+	_, err = os.Stat(stale)
+	assert.True(t, os.IsNotExist(err), "socket file removed on close")
+}
+
+func TestSend_NoInstance(t *testing.T) {
+	_, err := Send(socketPath(t), Request{Command: CmdStatus}, time.Second)
+	assert.ErrorContains(t, err, "no running instance")
+}
@@ -0,0 +1,371 @@
+// Package dbus publishes the tray's state on the Linux session bus as
+// org.petems.CCDailyUse, so GNOME Shell extensions and KDE Plasma widgets
+// can read cost and status as properties, follow PropertiesChanged, and
+// call Refresh or Quit. A panel indicator can instead use GetIndicator and
+// IndicatorChanged, which carry exactly what the tray icon shows.
+package dbus
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// The service's well-known name, object, and interface.
+const (
+	ServiceName = "org.petems.CCDailyUse"
+	ObjectPath  = "/org/petems/CCDailyUse"
+	Interface   = "org.petems.CCDailyUse"
+)
+
+// ProtocolVersion is bumped when a member of the interface is removed or
+// changes meaning, so companions such as the GNOME Shell extension can
+// tell they are talking to an incompatible version. Adding members does
+// not bump it.
+const ProtocolVersion = uint32(1)
+
+// Standard interfaces the service implements.
+const (
+	propertiesInterface = "org.freedesktop.DBus.Properties"
+	introspectInterface = "org.freedesktop.DBus.Introspectable"
+)
+
+// Error names returned to callers.
+const (
+	errUnknownInterface = "org.freedesktop.DBus.Error.UnknownInterface"
+	errUnknownProperty  = "org.freedesktop.DBus.Error.UnknownProperty"
+	errPropertyReadOnly = "org.freedesktop.DBus.Error.PropertyReadOnly"
+	errRefreshFailed    = Interface + ".Error.RefreshFailed"
+)
+
+// Handler carries out the methods callers invoke.
+type Handler interface {
+	Refresh() (*models.UsageState, error)
+	Quit()
+}
+
+// Indicator is everything a panel indicator shows, already formatted the
+// way the tray formats it.
+type Indicator struct {
+	Label   string   // the menu bar title, e.g. "CC 🟡 $12.40"
+	Style   string   // green, yellow, red, or unknown, for colouring the label
+	Tooltip string   // the title described in words
+	Lines   []string // the detail lines at the top of the tray menu
+}
+
+// body is the indicator as GetIndicator and IndicatorChanged carry it:
+// (sssas).
+func (ind Indicator) body() []interface{} {
+	lines := ind.Lines
+	if lines == nil {
+		lines = []string{}
+	}
+	return []interface{}{ind.Label, ind.Style, ind.Tooltip, lines}
+}
+
+// property is one read-only property and how it is read from a snapshot.
+type property struct {
+	name string
+	get  func(models.StatusSnapshot) interface{}
+}
+
+// properties mirror the status file's fields; see StatusSnapshot.
+var properties = []property{
+	{"Available", func(s models.StatusSnapshot) interface{} { return s.Available }},
+	{"Status", func(s models.StatusSnapshot) interface{} { return s.Status }},
+	{"StatusLabel", func(s models.StatusSnapshot) interface{} { return s.StatusLabel }},
+	{"Level", func(s models.StatusSnapshot) interface{} { return s.Level }},
+	{"Title", func(s models.StatusSnapshot) interface{} { return s.Title }},
+	{"DailyCost", func(s models.StatusSnapshot) interface{} { return s.DailyCost }},
+	{"DailyTokens", func(s models.StatusSnapshot) interface{} { return int64(s.DailyTokens) }},
+	{"DailyCalls", func(s models.StatusSnapshot) interface{} { return int64(s.DailyCalls) }},
+	{"WeeklyCost", func(s models.StatusSnapshot) interface{} { return s.WeeklyCost }},
+	{"WeeklyTokens", func(s models.StatusSnapshot) interface{} { return int64(s.WeeklyTokens) }},
+	{"MonthlyCost", func(s models.StatusSnapshot) interface{} { return s.MonthlyCost }},
+	{"Forecast", func(s models.StatusSnapshot) interface{} { return s.Forecast }},
+	{"YellowThreshold", func(s models.StatusSnapshot) interface{} { return s.YellowThreshold }},
+	{"RedThreshold", func(s models.StatusSnapshot) interface{} { return s.RedThreshold }},
+	{"WeeklyBudget", func(s models.StatusSnapshot) interface{} { return s.WeeklyBudget }},
+	{"MonthlyBudget", func(s models.StatusSnapshot) interface{} { return s.MonthlyBudget }},
+	{"Quiet", func(s models.StatusSnapshot) interface{} { return s.Quiet }},
+	{"Demo", func(s models.StatusSnapshot) interface{} { return s.Demo }},
+	{"UpdatedAt", func(s models.StatusSnapshot) interface{} { // Unix seconds; 0 before the first update
+		if s.UpdatedAt.IsZero() {
+			return int64(0)
+		}
+		return s.UpdatedAt.Unix()
+	}},
+	{"ProtocolVersion", func(models.StatusSnapshot) interface{} { return ProtocolVersion }},
+}
+
+// Server owns ServiceName on the session bus while the tray runs.
+type Server struct {
+	handler Handler
+	logger  *lib.Logger
+
+	mutex     sync.Mutex
+	conn      *godbus.Conn // nil until Start succeeds and after Close
+	values    map[string]godbus.Variant
+	indicator Indicator
+	closed    bool
+	wg        sync.WaitGroup // Refresh calls in flight
+}
+
+// NewServer creates a Server whose properties read as unknown until the
+// first Publish.
+func NewServer(handler Handler) *Server {
+	return &Server{
+		handler: handler,
+		logger:  lib.NewLogger("dbus-server"),
+		values:  propertyValues(models.StatusSnapshot{Status: "unknown", StatusLabel: models.Unknown.String()}),
+		indicator: Indicator{
+			Label:   "CC",
+			Style:   "unknown",
+			Tooltip: "Waiting for the first update",
+		},
+	}
+}
+
+// Start joins the session bus and claims ServiceName. It fails when there
+// is no session bus or another instance already owns the name; it never
+// launches a bus of its own.
+func (s *Server) Start() error {
+	c, err := godbus.SessionBusPrivateNoAutoStartup()
+	if err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to find the D-Bus session bus")
+	}
+	if err := c.Auth(nil); err != nil {
+		c.Close()
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to connect to the D-Bus session bus")
+	}
+	if err := c.Hello(); err != nil {
+		c.Close()
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to connect to the D-Bus session bus")
+	}
+	return s.serve(c)
+}
+
+func (s *Server) startAt(address string) error {
+	c, err := godbus.Connect(address)
+	if err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to connect to the D-Bus session bus")
+	}
+	return s.serve(c)
+}
+
+// serve exports the object on c and claims ServiceName, closing c on
+// failure.
+func (s *Server) serve(c *godbus.Conn) error {
+	err := c.Export(object{s}, ObjectPath, Interface)
+	if err == nil {
+		err = c.Export(propertiesObject{s}, ObjectPath, propertiesInterface)
+	}
+	if err == nil {
+		err = c.Export(introspect.NewIntrospectable(introspectNode()), ObjectPath, introspectInterface)
+	}
+	if err != nil {
+		c.Close()
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to export "+ObjectPath)
+	}
+
+	reply, err := c.RequestName(ServiceName, godbus.NameFlagDoNotQueue)
+	if err != nil {
+		c.Close()
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to claim "+ServiceName)
+	}
+	if reply != godbus.RequestNameReplyPrimaryOwner && reply != godbus.RequestNameReplyAlreadyOwner {
+		c.Close()
+		return lib.NewError(lib.ErrCodeSystem, fmt.Sprintf("another instance already owns %s on the session bus", ServiceName))
+	}
+
+	s.mutex.Lock()
+	s.conn = c
+	s.mutex.Unlock()
+	go s.watch(c)
+	return nil
+}
+
+// watch logs the connection dropping other than through Close.
+func (s *Server) watch(c *godbus.Conn) {
+	<-c.Context().Done()
+	s.mutex.Lock()
+	closed := s.closed
+	s.mutex.Unlock()
+	if !closed {
+		s.logger.Warn("Lost the D-Bus session bus connection", nil)
+	}
+}
+
+// Close leaves the bus, releasing ServiceName, and waits for Refresh
+// calls in flight. It is safe to call more than once, and on a Server that
+// never started.
+func (s *Server) Close() {
+	s.mutex.Lock()
+	c := s.conn
+	s.conn = nil
+	s.closed = true
+	s.mutex.Unlock()
+	if c != nil {
+		c.Close()
+	}
+	s.wg.Wait()
+}
+
+// Publish updates the properties from snapshot and emits
+// PropertiesChanged for those that changed.
+func (s *Server) Publish(snapshot models.StatusSnapshot) {
+	values := propertyValues(snapshot)
+
+	s.mutex.Lock()
+	changed := map[string]godbus.Variant{}
+	for name, v := range values {
+		if !reflect.DeepEqual(s.values[name], v) {
+			changed[name] = v
+		}
+	}
+	s.values = values
+	c := s.conn
+	s.mutex.Unlock()
+
+	if c == nil || len(changed) == 0 {
+		return
+	}
+	if err := c.Emit(ObjectPath, propertiesInterface+".PropertiesChanged", Interface, changed, []string{}); err != nil {
+		s.logger.Debug("Failed to emit PropertiesChanged", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// PublishIndicator replaces what GetIndicator returns and emits
+// IndicatorChanged when it differs.
+func (s *Server) PublishIndicator(ind Indicator) {
+	s.mutex.Lock()
+	changed := !reflect.DeepEqual(s.indicator.body(), ind.body())
+	s.indicator = ind
+	c := s.conn
+	s.mutex.Unlock()
+
+	if c == nil || !changed {
+		return
+	}
+	if err := c.Emit(ObjectPath, Interface+".IndicatorChanged", ind.body()...); err != nil {
+		s.logger.Debug("Failed to emit IndicatorChanged", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+func propertyValues(snapshot models.StatusSnapshot) map[string]godbus.Variant {
+	values := make(map[string]godbus.Variant, len(properties))
+	for _, p := range properties {
+		values[p.name] = godbus.MakeVariant(p.get(snapshot))
+	}
+	return values
+}
+
+// object carries the service's own methods; godbus exports every exported
+// method of the value it is given, so they live apart from Server's.
+type object struct{ server *Server }
+
+// Refresh runs an update. godbus answers each call on its own goroutine,
+// so property reads are served while ccusage runs.
+func (o object) Refresh() *godbus.Error {
+	s := o.server
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return godbus.NewError(errRefreshFailed, []interface{}{"shutting down"})
+	}
+	s.wg.Add(1)
+	s.mutex.Unlock()
+	defer s.wg.Done()
+
+	if _, err := s.handler.Refresh(); err != nil {
+		return godbus.NewError(errRefreshFailed, []interface{}{err.Error()})
+	}
+	return nil
+}
+
+// Quit asks the tray to exit once the reply is on its way, since exiting
+// closes the connection the reply goes out on.
+func (o object) Quit() *godbus.Error {
+	go o.server.handler.Quit()
+	return nil
+}
+
+func (o object) GetIndicator() (string, string, string, []string, *godbus.Error) {
+	o.server.mutex.Lock()
+	body := o.server.indicator.body()
+	o.server.mutex.Unlock()
+	return body[0].(string), body[1].(string), body[2].(string), body[3].([]string), nil
+}
+
+// propertiesObject implements org.freedesktop.DBus.Properties over the
+// Server's values, so that Publish can report every change in one
+// PropertiesChanged signal.
+type propertiesObject struct{ server *Server }
+
+func (p propertiesObject) Get(iface, name string) (godbus.Variant, *godbus.Error) {
+	if iface != Interface && iface != "" {
+		return godbus.Variant{}, godbus.NewError(errUnknownInterface, []interface{}{fmt.Sprintf("no properties on %s", iface)})
+	}
+	p.server.mutex.Lock()
+	v, ok := p.server.values[name]
+	p.server.mutex.Unlock()
+	if !ok {
+		return godbus.Variant{}, godbus.NewError(errUnknownProperty, []interface{}{fmt.Sprintf("no property %s", name)})
+	}
+	return v, nil
+}
+
+func (p propertiesObject) GetAll(iface string) (map[string]godbus.Variant, *godbus.Error) {
+	values := map[string]godbus.Variant{}
+	if iface == Interface || iface == "" {
+		p.server.mutex.Lock()
+		for name, v := range p.server.values {
+			values[name] = v
+		}
+		p.server.mutex.Unlock()
+	}
+	return values, nil
+}
+
+func (p propertiesObject) Set(string, string, godbus.Variant) *godbus.Error {
+	return godbus.NewError(errPropertyReadOnly, []interface{}{"all properties are read-only"})
+}
+
+// introspectNode describes the object for tools like busctl and d-feet.
+func introspectNode() *introspect.Node {
+	indicatorArgs := func(direction string) []introspect.Arg {
+		return []introspect.Arg{
+			{Name: "label", Type: "s", Direction: direction},
+			{Name: "style", Type: "s", Direction: direction},
+			{Name: "tooltip", Type: "s", Direction: direction},
+			{Name: "lines", Type: "as", Direction: direction},
+		}
+	}
+	service := introspect.Interface{
+		Name:    Interface,
+		Methods: []introspect.Method{{Name: "Refresh"}, {Name: "Quit"}, {Name: "GetIndicator", Args: indicatorArgs("out")}},
+		Signals: []introspect.Signal{{Name: "IndicatorChanged", Args: indicatorArgs("")}},
+	}
+	for _, p := range properties {
+		service.Properties = append(service.Properties, introspect.Property{
+			Name:   p.name,
+			Type:   godbus.SignatureOf(p.get(models.StatusSnapshot{})).String(),
+			Access: "read",
+		})
+	}
+	return &introspect.Node{
+		Name:       ObjectPath,
+		Interfaces: []introspect.Interface{service, prop.IntrospectData, introspect.IntrospectData},
+	}
+}
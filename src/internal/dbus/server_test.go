@@ -0,0 +1,232 @@
+package dbus
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+// privateBus starts a throwaway dbus-daemon and returns its address,
+// skipping the test where none is installed.
+func privateBus(t *testing.T) string {
+	t.Helper()
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not installed")
+	}
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "bus")
+	config := filepath.Join(dir, "bus.conf")
+	require.NoError(t, os.WriteFile(config, []byte(fmt.Sprintf(`<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:path=%s</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`, socket)), 0o600))
+
+	// The daemon prints its address once it is listening; the socket file
+	// appears a moment earlier, before connections are accepted.
+	cmd := exec.Command(daemon, "--config-file="+config, "--nofork", "--nopidfile", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+	_, err = bufio.NewReader(stdout).ReadString('\n')
+	require.NoError(t, err, "dbus-daemon exited before listening")
+	return "unix:path=" + socket
+}
+
+type fakeHandler struct {
+	refreshes atomic.Int32
+	fail      atomic.Bool
+	quit      chan struct{}
+}
+
+func (h *fakeHandler) Refresh() (*models.UsageState, error) {
+	h.refreshes.Add(1)
+	if h.fail.Load() {
+		return nil, errors.New("ccusage timed out")
+	}
+	return models.NewUsageState(), nil
+}
+
+func (h *fakeHandler) Quit() { close(h.quit) }
+
+// connect joins the bus at address as a client, with signals matching
+// rules delivered to the returned channel.
+func connect(t *testing.T, address string, rules ...godbus.MatchOption) (*godbus.Conn, chan *godbus.Signal) {
+	t.Helper()
+	client, err := godbus.Connect(address)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	signals := make(chan *godbus.Signal, 16)
+	if len(rules) > 0 {
+		require.NoError(t, client.AddMatchSignal(rules...))
+		client.Signal(signals)
+	}
+	return client, signals
+}
+
+func callService(client *godbus.Conn, method string, args ...interface{}) *godbus.Call {
+	return client.Object(ServiceName, ObjectPath).Call(method, 0, args...)
+}
+
+// nextSignal returns the next signal named name, skipping the bus's own.
+func nextSignal(t *testing.T, signals chan *godbus.Signal, name string) *godbus.Signal {
+	t.Helper()
+	for {
+		select {
+		case signal := <-signals:
+			if signal.Name == name {
+				return signal
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s signal", name)
+		}
+	}
+}
+
+func TestServer_OverSessionBus(t *testing.T) {
+	address := privateBus(t)
+	handler := &fakeHandler{quit: make(chan struct{})}
+	server := NewServer(handler)
+	require.NoError(t, server.startAt(address))
+	defer server.Close()
+
+	client, signals := connect(t, address, godbus.WithMatchInterface(propertiesInterface), godbus.WithMatchObjectPath(ObjectPath))
+
+	var status godbus.Variant
+	require.NoError(t, callService(client, propertiesInterface+".Get", Interface, "Status").Store(&status))
+	assert.Equal(t, "unknown", status.Value())
+
+	server.Publish(models.StatusSnapshot{
+		Available: true, Status: "yellow", StatusLabel: "High", Title: "CC 🟡 $12.40",
+		DailyCost: 12.4, DailyTokens: 1234, YellowThreshold: 10, RedThreshold: 20,
+		UpdatedAt: time.Unix(1700000000, 0),
+	})
+	signal := nextSignal(t, signals, propertiesInterface+".PropertiesChanged")
+	require.Len(t, signal.Body, 3)
+	assert.Equal(t, Interface, signal.Body[0])
+	changed := map[string]interface{}{}
+	for name, v := range signal.Body[1].(map[string]godbus.Variant) {
+		changed[name] = v.Value()
+	}
+	assert.Equal(t, map[string]interface{}{
+		"Available": true, "Status": "yellow", "StatusLabel": "High", "Title": "CC 🟡 $12.40",
+		"DailyCost": 12.4, "DailyTokens": int64(1234), "YellowThreshold": 10.0, "RedThreshold": 20.0,
+		"UpdatedAt": int64(1700000000),
+	}, changed, "only changed properties are sent")
+
+	var all map[string]godbus.Variant
+	require.NoError(t, callService(client, propertiesInterface+".GetAll", Interface).Store(&all))
+	assert.Len(t, all, len(properties))
+
+	var xml string
+	require.NoError(t, callService(client, introspectInterface+".Introspect").Store(&xml))
+	assert.Contains(t, xml, `<property name="DailyCost" type="d" access="read"></property>`)
+
+	// Tools that browse from "/" find the object.
+	require.NoError(t, client.Object(ServiceName, "/org/petems").Call(introspectInterface+".Introspect", 0).Store(&xml))
+	assert.Contains(t, xml, `<node name="CCDailyUse"/>`)
+
+	assert.ErrorContains(t, callService(client, propertiesInterface+".Get", Interface, "Nope").Err, "no property Nope")
+	var readOnly godbus.Error
+	assert.ErrorAs(t, callService(client, propertiesInterface+".Set", Interface, "DailyCost", godbus.MakeVariant(1.0)).Err, &readOnly)
+	assert.Equal(t, errPropertyReadOnly, readOnly.Name)
+	assert.Error(t, callService(client, Interface+".Explode").Err)
+
+	require.NoError(t, callService(client, Interface+".Refresh").Err)
+	assert.Equal(t, int32(1), handler.refreshes.Load())
+	handler.fail.Store(true)
+	var failed godbus.Error
+	require.ErrorAs(t, callService(client, Interface+".Refresh").Err, &failed)
+	assert.Equal(t, errRefreshFailed, failed.Name)
+	assert.Equal(t, []interface{}{"ccusage timed out"}, failed.Body)
+
+	require.NoError(t, callService(client, Interface+".Quit").Err)
+	select {
+	case <-handler.quit:
+	case <-time.After(time.Second):
+		t.Fatal("Quit did not reach the handler")
+	}
+}
+
+func TestServer_NameAlreadyOwned(t *testing.T) {
+	address := privateBus(t)
+	first := NewServer(&fakeHandler{})
+	require.NoError(t, first.startAt(address))
+	defer first.Close()
+
+	second := NewServer(&fakeHandler{})
+	err := second.startAt(address)
+	assert.ErrorContains(t, err, "another instance already owns "+ServiceName)
+	second.Close()
+}
+
+func TestServer_StartWithoutSessionBus(t *testing.T) {
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "")
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	server := NewServer(&fakeHandler{})
+	assert.ErrorContains(t, server.Start(), "session bus")
+	server.Publish(models.StatusSnapshot{Status: "green"}) // no connection: just stores
+	server.Close()
+}
+
+func TestServer_Indicator(t *testing.T) {
+	address := privateBus(t)
+	server := NewServer(&fakeHandler{})
+	require.NoError(t, server.startAt(address))
+	defer server.Close()
+
+	client, signals := connect(t, address, godbus.WithMatchInterface(Interface))
+
+	var label, style, tooltip string
+	var lines []string
+	require.NoError(t, callService(client, Interface+".GetIndicator").Store(&label, &style, &tooltip, &lines))
+	assert.Equal(t, []interface{}{"CC", "unknown", "Waiting for the first update", []string{}}, []interface{}{label, style, tooltip, lines})
+
+	yellow := Indicator{
+		Label:   "CC 🟡 $12.40",
+		Style:   "yellow",
+		Tooltip: "Claude Code spend today: $12.40, status High",
+		Lines:   []string{"💰 Daily Cost: $12.40", "🔢 Tokens: 1.2M"},
+	}
+	server.PublishIndicator(yellow)
+	server.PublishIndicator(yellow) // unchanged: no second signal
+	server.PublishIndicator(Indicator{Label: "CC 🔴 $25.00", Style: "red"})
+
+	want := [][]interface{}{
+		{"CC 🟡 $12.40", "yellow", "Claude Code spend today: $12.40, status High", []string{"💰 Daily Cost: $12.40", "🔢 Tokens: 1.2M"}},
+		{"CC 🔴 $25.00", "red", "", []string{}},
+	}
+	for _, body := range want {
+		signal := nextSignal(t, signals, Interface+".IndicatorChanged")
+		assert.Equal(t, body, signal.Body)
+	}
+
+	var version godbus.Variant
+	require.NoError(t, callService(client, propertiesInterface+".Get", Interface, "ProtocolVersion").Store(&version))
+	assert.Equal(t, ProtocolVersion, version.Value())
+}
@@ -0,0 +1,113 @@
+package httpapi
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// tokenCookie holds the token for browsers, which can't add an
+// Authorization header to EventSource or page loads.
+const tokenCookie = "cc_dailyuse_token"
+
+// loginNonceTTL is how long a LoginURL stays usable. It only needs to
+// cover a browser starting up.
+const loginNonceTTL = time.Minute
+
+// authorize checks the request against the token, if one is set, writing
+// the response itself and returning false when the request must stop
+// here. The token is accepted as "Authorization: Bearer <token>", as the
+// session cookie, or once as ?token=, which sets the cookie and redirects
+// to the same URL without it so the token doesn't linger in the address
+// bar or history. A ?login= nonce from LoginURL is swapped for the cookie
+// the same way.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if s.token == "" {
+		return true
+	}
+
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && s.tokenMatches(bearer) {
+		return true
+	}
+	if cookie, err := r.Cookie(tokenCookie); err == nil && s.tokenMatches(cookie.Value) {
+		return true
+	}
+
+	query := r.URL.Query()
+	accepted := ""
+	if token := query.Get("token"); token != "" && s.tokenMatches(token) {
+		accepted = "token"
+	} else if nonce := query.Get("login"); nonce != "" && s.redeemLoginNonce(nonce) {
+		accepted = "login"
+	}
+	if accepted != "" {
+		http.SetCookie(w, &http.Cookie{
+			Name:     tokenCookie,
+			Value:    s.token,
+			Path:     "/",
+			HttpOnly: true,
+			SameSite: http.SameSiteStrictMode,
+		})
+		query.Del(accepted)
+		target := *r.URL
+		target.RawQuery = query.Encode()
+		http.Redirect(w, r, target.RequestURI(), http.StatusSeeOther)
+		return false
+	}
+
+	w.Header().Set("WWW-Authenticate", `Bearer realm="cc-dailyuse-bar"`)
+	http.Error(w, "missing or wrong token", http.StatusUnauthorized)
+	return false
+}
+
+// tokenMatches compares in constant time, so response timing doesn't
+// reveal how much of a guess was right.
+func (s *Server) tokenMatches(candidate string) bool {
+	return subtle.ConstantTimeCompare([]byte(candidate), []byte(s.token)) == 1
+}
+
+// LoginURL returns the dashboard's address for opening in a browser. With
+// a token set it carries a one-time ?login= nonce, valid for
+// loginNonceTTL, rather than the token itself: the URL is passed to the
+// browser on its command line, where other local users can read it.
+func (s *Server) LoginURL() (string, error) {
+	target := url.URL{Scheme: "http", Host: s.Addr(), Path: "/"}
+	if s.token == "" {
+		return target.String(), nil
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	nonce := hex.EncodeToString(buf)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for old, expires := range s.logins {
+		if now.After(expires) {
+			delete(s.logins, old)
+		}
+	}
+	if s.logins == nil {
+		s.logins = make(map[string]time.Time)
+	}
+	s.logins[nonce] = now.Add(loginNonceTTL)
+	target.RawQuery = url.Values{"login": {nonce}}.Encode()
+	return target.String(), nil
+}
+
+// redeemLoginNonce reports whether nonce came from LoginURL and hasn't
+// expired, using it up either way.
+func (s *Server) redeemLoginNonce(nonce string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expires, ok := s.logins[nonce]
+	delete(s.logins, nonce)
+	return ok && time.Now().Before(expires)
+}
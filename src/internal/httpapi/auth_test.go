@@ -0,0 +1,162 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "0123456789abcdef0123"
+
+func startTokenServer(t *testing.T) string {
+	t.Helper()
+	s := NewServer("127.0.0.1:0")
+	s.SetToken(testToken)
+	require.NoError(t, s.Start())
+	t.Cleanup(func() { _ = s.Close() })
+	return "http://" + s.Addr()
+}
+
+func TestAuth_Bearer(t *testing.T) {
+	base := startTokenServer(t)
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"missing", "", http.StatusUnauthorized},
+		{"wrong", "Bearer nope", http.StatusUnauthorized},
+		{"not bearer", "Basic " + testToken, http.StatusUnauthorized},
+		{"right", "Bearer " + testToken, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, base+"/v1/status", nil)
+			require.NoError(t, err)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tt.want, resp.StatusCode)
+			assert.Equal(t, strconv.Itoa(APIVersion), resp.Header.Get(VersionHeader))
+			if tt.want == http.StatusUnauthorized {
+				assert.Contains(t, resp.Header.Get("WWW-Authenticate"), "Bearer")
+			}
+		})
+	}
+}
+
+func TestAuth_QueryTokenSetsCookie(t *testing.T) {
+	base := startTokenServer(t)
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client := &http.Client{Jar: jar}
+
+	resp, err := client.Get(base + "/?token=" + testToken + "&x=1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "/?x=1", resp.Request.URL.RequestURI(), "redirected without the token")
+
+	resp, err = client.Get(base + "/v1/status")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "the cookie authorizes later requests")
+
+	resp, err = http.Get(base + "/?token=wrong")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestAuth_LoginURL(t *testing.T) {
+	s := NewServer("127.0.0.1:0")
+	s.SetToken(testToken)
+	require.NoError(t, s.Start())
+	t.Cleanup(func() { _ = s.Close() })
+
+	login, err := s.LoginURL()
+	require.NoError(t, err)
+	assert.NotContains(t, login, testToken, "the token never goes on a command line")
+	assert.Contains(t, login, "/?login=")
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client := &http.Client{Jar: jar}
+	resp, err := client.Get(login)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "/", resp.Request.URL.RequestURI(), "redirected without the nonce")
+
+	resp, err = client.Get("http://" + s.Addr() + "/v1/status")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "the cookie authorizes later requests")
+
+	resp, err = http.Get(login)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "a nonce works once")
+
+	s.mu.Lock()
+	s.logins["stale"] = time.Now().Add(-time.Second)
+	s.mu.Unlock()
+	resp, err = http.Get("http://" + s.Addr() + "/?login=stale")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "an expired nonce is refused")
+}
+
+func TestAuth_LoginURLWithoutToken(t *testing.T) {
+	s, _ := startServer(t)
+	login, err := s.LoginURL()
+	require.NoError(t, err)
+	assert.Equal(t, "http://"+s.Addr()+"/", login)
+}
+
+func TestAuth_NoToken(t *testing.T) {
+	_, base := startServer(t)
+	resp, err := http.Get(base + "/v1/status")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestServer_Profiling(t *testing.T) {
+	s := NewServer("127.0.0.1:0")
+	s.SetToken(testToken)
+	s.EnableProfiling()
+	require.NoError(t, s.Start())
+	t.Cleanup(func() { _ = s.Close() })
+
+	for header, want := range map[string]int{"": http.StatusUnauthorized, "Bearer " + testToken: http.StatusOK} {
+		req, err := http.NewRequest(http.MethodGet, "http://"+s.Addr()+"/debug/pprof/goroutine?debug=1", nil)
+		require.NoError(t, err)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, want, resp.StatusCode, header)
+	}
+
+	// Without EnableProfiling the path falls through to the dashboard's 404.
+	base := startTokenServer(t)
+	req, err := http.NewRequest(http.MethodGet, base+"/debug/pprof/", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,153 @@
+package httpapi
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+// The dashboard's page, script, and styles, served at /.
+//
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+// History chart range for GET /history.
+const (
+	defaultHistoryDays = 30
+	maxHistoryDays     = 90 // the history file's retention
+)
+
+// DashboardSource supplies what the dashboard shows besides the published
+// state.
+type DashboardSource interface {
+	// Config returns a copy of the running configuration.
+	Config() models.Config
+	// DailyTotals returns the last days days of recorded spend, ending
+	// today; nil when no history is kept, as in demo mode.
+	DailyTotals(days int) ([]models.DailyTotal, error)
+}
+
+// ConfigSummary is the part of the configuration the dashboard shows.
+type ConfigSummary struct {
+	UpdateInterval  int      `json:"update_interval"`
+	YellowThreshold float64  `json:"yellow_threshold"` // today's, after day_thresholds
+	RedThreshold    float64  `json:"red_threshold"`
+	AlertLevels     []string `json:"alert_levels,omitempty"` // "name $threshold status", in order
+	WeeklyBudget    float64  `json:"weekly_budget,omitempty"`
+	MonthlyBudget   float64  `json:"monthly_budget,omitempty"`
+	QuietUntil      string   `json:"quiet_until,omitempty"`
+	QuietHours      string   `json:"quiet_hours,omitempty"`
+	WatchDataDirs   bool     `json:"watch_data_dirs"`
+	TeamDir         bool     `json:"team_dir"` // whether a team folder is configured
+	// How the dashboard formats costs, as the tray does: CostPrecision
+	// decimals rounded by CostRounding, in Locale's style ("" for
+	// "$1234.56"). Costs are always in US dollars.
+	CostPrecision int    `json:"cost_precision"`
+	CostRounding  string `json:"cost_rounding"`
+	Locale        string `json:"locale,omitempty"` // BCP 47, with locale: auto resolved
+}
+
+// summarize picks the dashboard's view of config as of now.
+func summarize(config models.Config, now time.Time) ConfigSummary {
+	yellow, red := config.ThresholdsFor(now.Weekday())
+	summary := ConfigSummary{
+		UpdateInterval:  config.UpdateInterval,
+		YellowThreshold: yellow,
+		RedThreshold:    red,
+		WeeklyBudget:    config.WeeklyBudget,
+		MonthlyBudget:   config.MonthlyBudget,
+		QuietUntil:      config.QuietUntil,
+		QuietHours:      config.QuietHours,
+		WatchDataDirs:   config.WatchDataDirs,
+		TeamDir:         config.TeamDir != "",
+	}
+	format := config.CostFormat()
+	summary.CostPrecision = format.Precision
+	summary.CostRounding = string(format.Rounding)
+	summary.Locale = format.Locale.String()
+	for _, level := range config.AlertLevels {
+		summary.AlertLevels = append(summary.AlertLevels,
+			level.Name+" "+format.Format(level.Threshold)+" "+level.Status.ColorName())
+	}
+	return summary
+}
+
+// SetDashboard enables GET /history and GET /config from source. Without
+// it they answer 404 and the dashboard shows only the live state.
+func (s *Server) SetDashboard(source DashboardSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dashboard = source
+}
+
+func (s *Server) dashboardSource() DashboardSource {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dashboard
+}
+
+// dashboardHandler serves the embedded dashboard files.
+func dashboardHandler() http.Handler {
+	files, err := fs.Sub(dashboardFiles, "dashboard")
+	if err != nil {
+		panic(err) // the embedded tree is fixed at build time
+	}
+	fileServer := http.FileServerFS(files)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Everything the page loads comes from here; nothing may frame it.
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		fileServer.ServeHTTP(w, r)
+	})
+}
+
+// handleHistory returns ?days= (default 30, at most 90) daily totals.
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	source := s.dashboardSource()
+	if source == nil {
+		http.NotFound(w, r)
+		return
+	}
+	days := defaultHistoryDays
+	if value := r.URL.Query().Get("days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			http.Error(w, "days must be a positive number", http.StatusBadRequest)
+			return
+		}
+		days = min(n, maxHistoryDays)
+	}
+	totals, err := source.DailyTotals(days)
+	if err != nil {
+		s.logger.Warn("Failed to read history for the dashboard", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "history unavailable", http.StatusInternalServerError)
+		return
+	}
+	if totals == nil {
+		totals = []models.DailyTotal{}
+	}
+	writeJSON(w, totals)
+}
+
+// handleConfig returns the configuration summary.
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	source := s.dashboardSource()
+	if source == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, summarize(source.Config(), time.Now()))
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,243 @@
+// Read-only dashboard for cc-dailyuse-bar. The headline status comes from
+// the stable /v1/events stream, the details from /events, and the chart
+// and settings from /history and /config, which are reloaded every few
+// minutes; in between, each update moves today's bar itself.
+"use strict";
+
+const $ = (id) => document.getElementById(id);
+const number = (n) => Number(n || 0).toLocaleString();
+const statusLabels = { green: "OK", yellow: "High", red: "Critical", unknown: "Unknown" };
+
+// How often /history and /config are reloaded, for settings changes and
+// the day rolling over.
+const staticRefreshMs = 5 * 60 * 1000;
+
+let config = null;
+let history = null;
+let usage = null; // the last /events update, redrawn when config changes
+let reloadedFor = ""; // the day updateToday last reloaded /history for
+let currency = null; // Intl.NumberFormat for config.locale, once loaded
+
+// money formats a cost as the tray does: cost_precision decimals rounded
+// by cost_rounding, in the configured locale's style, else "$1234.56".
+function money(n) {
+  const precision = config ? config.cost_precision : 2;
+  const scale = 10 ** precision;
+  const cost = Number(n || 0) * scale;
+  let rounded;
+  switch (config && config.cost_rounding) {
+    case "up":
+      rounded = Math.ceil(cost - 1e-9) / scale; // as in CostFormat.Round
+      break;
+    case "down":
+      rounded = Math.floor(cost + 1e-9) / scale;
+      break;
+    default:
+      rounded = Math.round(cost) / scale;
+  }
+  return currency ? currency.format(rounded) : "$" + rounded.toFixed(precision);
+}
+
+function useConfig(summary) {
+  config = summary;
+  currency = null;
+  if (summary.locale) {
+    try {
+      currency = new Intl.NumberFormat(summary.locale, {
+        style: "currency",
+        currency: "USD",
+        minimumFractionDigits: summary.cost_precision,
+        maximumFractionDigits: summary.cost_precision,
+      });
+    } catch (e) {
+      // A tag the browser doesn't know: fall back to "$1234.56".
+    }
+  }
+}
+
+function setLive(on) {
+  $("live").textContent = on ? "live" : "offline";
+  $("live").classList.toggle("on", on);
+}
+
+function showStatus(status) {
+  $("title").textContent = status.title || "CC Daily Use Bar";
+  document.title = status.title || "CC Daily Use Bar";
+  $("state").className = "card status-" + status.status;
+  $("status").textContent = status.available
+    ? statusLabels[status.status] + (status.quiet ? " (quiet)" : "")
+    : "Usage data unavailable";
+}
+
+function showUsage(state) {
+  if (!state) {
+    return;
+  }
+  usage = state;
+  updateToday(state);
+  $("daily-cost").textContent = money(state.daily_cost);
+  $("daily-tokens").textContent = number(state.daily_count);
+  $("daily-calls").textContent = number(state.daily_calls);
+  $("weekly-cost").textContent = money(state.weekly_cost);
+  $("monthly-cost").textContent = money(state.monthly_cost);
+  $("forecast").textContent = money(state.forecast);
+  $("updated").textContent = new Date(state.last_update).toLocaleTimeString();
+}
+
+function follow(path, event, show) {
+  const source = new EventSource(path);
+  source.addEventListener(event, (e) => {
+    setLive(true);
+    show(JSON.parse(e.data));
+  });
+  source.onerror = () => setLive(false);
+}
+
+const svgNS = "http://www.w3.org/2000/svg";
+
+function svg(name, attrs, text) {
+  const el = document.createElementNS(svgNS, name);
+  for (const [key, value] of Object.entries(attrs)) {
+    el.setAttribute(key, value);
+  }
+  if (text !== undefined) {
+    el.textContent = text;
+  }
+  return el;
+}
+
+function drawChart(days) {
+  const chart = $("chart");
+  chart.replaceChildren();
+  const tracked = days.filter((d) => d.tracked);
+  if (tracked.length === 0) {
+    $("chart-note").textContent = "No history recorded yet.";
+    return;
+  }
+
+  const width = chart.clientWidth || 600;
+  const height = chart.clientHeight || 220;
+  const top = 10, bottom = 20;
+  const red = config ? config.red_threshold : 0;
+  const peak = Math.max(red, ...tracked.map((d) => d.cost)) || 1;
+  const slot = width / days.length;
+  const y = (cost) => top + (height - top - bottom) * (1 - cost / peak);
+  chart.setAttribute("viewBox", `0 0 ${width} ${height}`);
+
+  days.forEach((day, i) => {
+    if (day.tracked) {
+      const bar = svg("rect", {
+        x: i * slot + slot * 0.15,
+        y: y(day.cost),
+        width: slot * 0.7,
+        height: Math.max(y(0) - y(day.cost), 1),
+        class: "bar-" + (day.status || "unknown"),
+      });
+      bar.appendChild(svg("title", {}, `${day.date}: ${money(day.cost)}, ${number(day.tokens)} tokens`));
+      chart.appendChild(bar);
+    }
+    if (i % Math.ceil(days.length / 10) === 0) {
+      chart.appendChild(svg("text", { x: i * slot + slot / 2, y: height - 5, "text-anchor": "middle" }, day.date.slice(5)));
+    }
+  });
+
+  if (red > 0) {
+    chart.appendChild(svg("line", { x1: 0, x2: width, y1: y(red), y2: y(red), class: "threshold" }));
+    chart.appendChild(svg("text", { x: width - 4, y: y(red) - 3, "text-anchor": "end" }, "red " + money(red)));
+  }
+  const untracked = days.length - tracked.length;
+  $("chart-note").textContent = untracked > 0
+    ? `${untracked} of ${days.length} days weren't recorded (the app wasn't running).`
+    : "";
+}
+
+// updateToday moves today's bar to state between reloads of /history. A
+// state from a day the chart doesn't end on reloads it instead.
+function updateToday(state) {
+  if (!history || history.length === 0 || !state.is_available) {
+    return;
+  }
+  const updated = new Date(state.last_update);
+  const pad = (n) => String(n).padStart(2, "0");
+  const date = `${updated.getFullYear()}-${pad(updated.getMonth() + 1)}-${pad(updated.getDate())}`;
+  const today = history[history.length - 1];
+  if (today.date !== date) {
+    if (date > today.date && reloadedFor !== date) {
+      reloadedFor = date;
+      loadStatic(); // a new day: the chart should end on it
+    }
+    return;
+  }
+  Object.assign(today, { cost: state.daily_cost, tokens: state.daily_count, status: state.status, tracked: true });
+  drawChart(history);
+}
+
+function showConfig(summary) {
+  const rows = [
+    ["Update every", summary.update_interval + "s"],
+    ["Thresholds today", money(summary.yellow_threshold) + " / " + money(summary.red_threshold)],
+  ];
+  if (summary.alert_levels) {
+    rows.push(["Alert levels", summary.alert_levels.join(", ")]);
+  }
+  if (summary.weekly_budget) {
+    rows.push(["Weekly budget", money(summary.weekly_budget)]);
+  }
+  if (summary.monthly_budget) {
+    rows.push(["Monthly budget", money(summary.monthly_budget)]);
+  }
+  if (summary.quiet_until) {
+    rows.push(["Quiet until", summary.quiet_until]);
+  }
+  if (summary.quiet_hours) {
+    rows.push(["Quiet hours", summary.quiet_hours]);
+  }
+  rows.push(["Watch data folders", summary.watch_data_dirs ? "yes" : "no"]);
+  rows.push(["Team folder", summary.team_dir ? "yes" : "no"]);
+
+  $("config").replaceChildren(...rows.flatMap(([label, value]) => {
+    const dt = document.createElement("dt");
+    const dd = document.createElement("dd");
+    dt.textContent = label;
+    dd.textContent = value;
+    return [dt, dd];
+  }));
+}
+
+async function getJSON(path) {
+  const response = await fetch(path, { cache: "no-store" });
+  if (!response.ok) {
+    throw new Error(path + ": " + response.status);
+  }
+  return response.json();
+}
+
+let loading = null;
+
+// loadStatic reloads /config and /history, sharing a load in progress.
+function loadStatic() {
+  loading = loading || (async () => {
+    try {
+      useConfig(await getJSON("config"));
+      showConfig(config);
+      showUsage(usage); // in the new cost format
+    } catch (e) {
+      $("config").replaceChildren();
+    }
+    try {
+      history = await getJSON("history?days=30");
+      drawChart(history);
+    } catch (e) {
+      history = null;
+      $("chart-note").textContent = "History unavailable.";
+    }
+  })().finally(() => {
+    loading = null;
+  });
+  return loading;
+}
+
+follow("v1/events", "status", showStatus);
+follow("events", "usage", showUsage);
+loadStatic();
+setInterval(loadStatic, staticRefreshMs);
@@ -0,0 +1,126 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+type fakeDashboard struct {
+	config models.Config
+	days   int // last DailyTotals argument
+	err    error
+}
+
+func (f *fakeDashboard) Config() models.Config { return f.config }
+
+func (f *fakeDashboard) DailyTotals(days int) ([]models.DailyTotal, error) {
+	f.days = days
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []models.DailyTotal{{Date: "2025-03-14", Cost: 12, Tracked: true}}, nil
+}
+
+func get(t *testing.T, url string) (*http.Response, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func TestDashboard_Assets(t *testing.T) {
+	_, base := startServer(t)
+
+	for path, contentType := range map[string]string{
+		"/":          "text/html; charset=utf-8",
+		"/app.js":    "text/javascript; charset=utf-8",
+		"/style.css": "text/css; charset=utf-8",
+	} {
+		resp, body := get(t, base+path)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+		assert.Equal(t, contentType, resp.Header.Get("Content-Type"), path)
+		assert.Contains(t, resp.Header.Get("Content-Security-Policy"), "default-src 'self'", path)
+		assert.NotEmpty(t, body, path)
+	}
+
+	resp, _ := get(t, base+"/missing.js")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestDashboard_HistoryAndConfig(t *testing.T) {
+	s, base := startServer(t)
+
+	resp, _ := get(t, base+"/history")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "no source, no history")
+	resp, _ = get(t, base+"/config")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	config := *models.ConfigDefaults()
+	config.WeeklyBudget = 75
+	config.AlertLevels = []models.AlertLevel{{Name: "half", Threshold: 10, Status: models.Yellow}}
+	source := &fakeDashboard{config: config}
+	s.SetDashboard(source)
+
+	resp, body := get(t, base+"/history")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, defaultHistoryDays, source.days)
+	var totals []models.DailyTotal
+	require.NoError(t, json.Unmarshal([]byte(body), &totals))
+	assert.Equal(t, 12.0, totals[0].Cost)
+
+	get(t, base+"/history?days=365")
+	assert.Equal(t, maxHistoryDays, source.days)
+	resp, _ = get(t, base+"/history?days=0")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	source.err = errors.New("disk on fire")
+	resp, _ = get(t, base+"/history")
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	resp, body = get(t, base+"/config")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var summary ConfigSummary
+	require.NoError(t, json.Unmarshal([]byte(body), &summary))
+	assert.Equal(t, 30, summary.UpdateInterval)
+	assert.Equal(t, 75.0, summary.WeeklyBudget)
+	assert.Equal(t, []string{"half $10.00 yellow"}, summary.AlertLevels)
+	assert.Equal(t, 2, summary.CostPrecision)
+	assert.Equal(t, "nearest", summary.CostRounding)
+	assert.Empty(t, summary.Locale)
+}
+
+func TestSummarize_CostFormat(t *testing.T) {
+	config := *models.ConfigDefaults()
+	precision := 0
+	config.CostPrecision = &precision
+	config.CostRounding = models.RoundUp
+	config.Locale = "de_DE.UTF-8"
+
+	summary := summarize(config, time.Now())
+	assert.Equal(t, 0, summary.CostPrecision)
+	assert.Equal(t, "up", summary.CostRounding)
+	assert.Equal(t, "de-DE", summary.Locale)
+}
+
+func TestSummarize_DayThresholds(t *testing.T) {
+	config := *models.ConfigDefaults()
+	config.DayThresholds = map[string]models.ThresholdPair{"weekends": {YellowThreshold: 2, RedThreshold: 5}}
+	saturday := time.Date(2025, 3, 15, 12, 0, 0, 0, time.Local)
+
+	summary := summarize(config, saturday)
+	assert.Equal(t, 2.0, summary.YellowThreshold)
+	assert.Equal(t, 5.0, summary.RedThreshold)
+	assert.False(t, summary.TeamDir)
+}
@@ -0,0 +1,407 @@
+// Package httpapi serves the tray's status as JSON on a loopback address
+// for editor statusline plugins (Neovim, VS Code) and other local tools.
+// Clients either poll GET /v1/status, long-poll it with ?since=<seq> to
+// wait for the next change, or follow GET /v1/events as Server-Sent
+// Events. Every response carries VersionHeader so a plugin can tell it is
+// talking to an incompatible version. With a token set (SetToken), every
+// request must present it as a bearer token or the session cookie a
+// browser gets from opening /?token=<token> or a LoginURL.
+//
+// GET /usage and GET /events serve the full UsageState the same two ways
+// for dashboards that want everything the tray knows. That shape follows
+// the app's internals and is not covered by APIVersion. The read-only
+// dashboard at / is built on them plus GET /history and GET /config.
+// EnableProfiling adds net/http/pprof's handlers under /debug/pprof/.
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"sync"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// APIVersion is bumped when a Status field is removed or changes meaning,
+// or an endpoint changes incompatibly. Adding fields does not bump it.
+const APIVersion = 1
+
+// VersionHeader carries APIVersion on every response.
+const VersionHeader = "X-CC-DailyUse-API-Version"
+
+// Long-poll and event stream timing.
+const (
+	defaultWait   = 30 * time.Second // ?since= without ?wait=
+	maxWait       = 60 * time.Second
+	keepAlive     = 15 * time.Second // SSE comment so proxies and clients see a live stream
+	shutdownGrace = 2 * time.Second
+)
+
+// Status is the minimal state an editor statusline needs.
+type Status struct {
+	SchemaVersion int       `json:"schema_version"` // models.SchemaVersion
+	Seq           uint64    `json:"seq"`            // increases on every change; pass as ?since= to wait for the next one
+	UpdatedAt     time.Time `json:"updated_at"`
+	Available     bool      `json:"available"`
+	Status        string    `json:"status"` // green, yellow, red, or unknown
+	Title         string    `json:"title"`  // menu bar title, e.g. "CC 🟡 $12.40"
+	DailyCost     float64   `json:"daily_cost"`
+	DailyTokens   int       `json:"daily_tokens"`
+	Quiet         bool      `json:"quiet,omitempty"`
+}
+
+// statusFrom trims a status file snapshot to the API's fields.
+func statusFrom(snapshot models.StatusSnapshot) Status {
+	return Status{
+		SchemaVersion: models.SchemaVersion,
+		UpdatedAt:     snapshot.UpdatedAt,
+		Available:     snapshot.Available,
+		Status:        snapshot.Status,
+		Title:         snapshot.Title,
+		DailyCost:     snapshot.DailyCost,
+		DailyTokens:   snapshot.DailyTokens,
+		Quiet:         snapshot.Quiet,
+	}
+}
+
+// Server serves the latest published Status over HTTP.
+type Server struct {
+	addr     string
+	logger   *lib.Logger
+	server   *http.Server
+	mux      *http.ServeMux
+	listener net.Listener
+
+	mu        sync.Mutex
+	status    Status
+	changed   chan struct{}        // closed and replaced on every change
+	usage     feed                 // every UsageState update, already encoded
+	dashboard DashboardSource      // nil until SetDashboard
+	token     string               // required from clients when set; see SetToken
+	logins    map[string]time.Time // LoginURL's unused nonces and when they expire
+	done      chan struct{}        // closed by Close to end waiting requests
+	closed    bool
+}
+
+// feed is the latest encoded value of a stream and its sequence number.
+type feed struct {
+	seq     uint64
+	data    []byte
+	changed chan struct{} // closed and replaced on every update
+}
+
+// NewServer creates a Server that will listen on addr, a loopback
+// host:port, once started. Until the first Publish it reports an
+// unavailable "unknown" status.
+func NewServer(addr string) *Server {
+	s := &Server{
+		addr:    addr,
+		logger:  lib.NewLogger("http-api"),
+		status:  Status{SchemaVersion: models.SchemaVersion, Status: models.Unknown.ColorName()},
+		changed: make(chan struct{}),
+		usage:   feed{data: []byte("null"), changed: make(chan struct{})},
+		done:    make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/status", s.handleStatus)
+	mux.HandleFunc("/v1/events", s.handleEvents)
+	mux.HandleFunc("/usage", s.handleUsage)
+	mux.HandleFunc("/events", s.handleUsageEvents)
+	mux.HandleFunc("/history", s.handleHistory)
+	mux.HandleFunc("/config", s.handleConfig)
+	mux.Handle("/", dashboardHandler())
+	s.mux = mux
+	s.server = &http.Server{
+		Handler:           s.guard(mux),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return s
+}
+
+// Start binds the address and serves requests in the background. Only
+// loopback addresses are accepted. Once SetToken has set a token, every
+// request must present it, as a bearer token, the session cookie, or a
+// one-time LoginURL nonce; see authorize. Without one, any local user can
+// read the API.
+func (s *Server) Start() error {
+	if !models.IsLoopbackAddr(s.addr) {
+		return lib.ValidationError(fmt.Sprintf("refusing to serve the status API on non-loopback address %q", s.addr))
+	}
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to listen for the status API")
+	}
+	s.listener = listener
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Warn("Status API stopped", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}()
+
+	s.logger.Info("Status API listening", map[string]interface{}{
+		"addr": listener.Addr().String(),
+	})
+	return nil
+}
+
+// SetToken requires every request to carry token, so other users of a
+// shared machine can't read the API over loopback. Call it before Start;
+// an empty token leaves the API open.
+func (s *Server) SetToken(token string) {
+	s.token = token
+}
+
+// EnableProfiling serves Go's runtime profiles under /debug/pprof/ behind
+// the same loopback and token checks as the rest of the API. Call it
+// before Start, and only with a token set: profiles and the command line
+// reveal what the process holds.
+func (s *Server) EnableProfiling() {
+	s.mux.HandleFunc("/debug/pprof/", pprof.Index)
+	s.mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	s.mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	s.mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	s.mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
+
+// Addr returns the address being served, with the port the system chose
+// when addr asked for port 0. It is empty before Start.
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// Close ends waiting requests and event streams and stops the server. It
+// is safe to call more than once.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	s.mu.Unlock()
+
+	if s.listener == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownGrace)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// Publish makes snapshot the served status, waking long-polls and event
+// streams when anything changed.
+func (s *Server) Publish(snapshot models.StatusSnapshot) {
+	next := statusFrom(snapshot)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next.Seq = s.status.Seq
+	if next == s.status {
+		return
+	}
+	next.Seq++
+	s.status = next
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// PublishUsage makes state the served UsageState and pushes it to
+// /events streams. Unlike Publish, every update is sent, changed or not.
+func (s *Server) PublishUsage(state *models.UsageState) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		s.logger.Warn("Failed to encode usage state", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usage.seq++
+	s.usage.data = data
+	close(s.usage.changed)
+	s.usage.changed = make(chan struct{})
+}
+
+// current returns the served status and a channel closed on its next
+// change.
+func (s *Server) current() (Status, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status, s.changed
+}
+
+// guard rejects requests whose Host isn't loopback, so a web page can't
+// reach the API through DNS rebinding, and stamps the version header.
+func (s *Server) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(VersionHeader, strconv.Itoa(APIVersion))
+		if !loopbackHost(r.Host) {
+			http.Error(w, "forbidden host", http.StatusForbidden)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !s.authorize(w, r) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// loopbackHost reports whether a Host header names the loopback
+// interface, with or without a port.
+func loopbackHost(host string) bool {
+	if models.IsLoopbackAddr(host) {
+		return true
+	}
+	return models.IsLoopbackAddr(net.JoinHostPort(host, "0"))
+}
+
+// handleStatus returns the status at once, or with ?since=<seq> waits up
+// to ?wait= seconds (default 30, at most 60) while seq is still current.
+// The response is the current status either way; compare its seq. A seq
+// the server hasn't reached, as after a restart, returns at once.
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status, changed := s.current()
+
+	if since := r.URL.Query().Get("since"); since != "" {
+		seq, err := strconv.ParseUint(since, 10, 64)
+		if err != nil {
+			http.Error(w, "since must be a sequence number", http.StatusBadRequest)
+			return
+		}
+		wait, err := waitParam(r.URL.Query().Get("wait"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if status.Seq == seq {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			select {
+			case <-changed:
+			case <-timer.C:
+			case <-s.done:
+			case <-r.Context().Done():
+				return
+			}
+			status, _ = s.current()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatUint(status.Seq, 10)))
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+// waitParam parses ?wait= seconds, capped at maxWait.
+func waitParam(value string) (time.Duration, error) {
+	if value == "" {
+		return defaultWait, nil
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0, fmt.Errorf("wait must be a number of seconds")
+	}
+	return min(time.Duration(seconds)*time.Second, maxWait), nil
+}
+
+// handleEvents streams a "status" event with the current status, then
+// another on every change.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	s.stream(w, r, "status", func() (uint64, []byte, <-chan struct{}) {
+		status, changed := s.current()
+		data, _ := json.Marshal(status)
+		return status.Seq, data, changed
+	})
+}
+
+// handleUsage returns the latest UsageState, or null before the first
+// update.
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	data := s.usage.data
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(append(data, '\n'))
+}
+
+// handleUsageEvents streams a "usage" event with the latest UsageState,
+// once there is one, then another on every update.
+func (s *Server) handleUsageEvents(w http.ResponseWriter, r *http.Request) {
+	s.stream(w, r, "usage", func() (uint64, []byte, <-chan struct{}) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.usage.seq == 0 {
+			return 0, nil, s.usage.changed
+		}
+		return s.usage.seq, s.usage.data, s.usage.changed
+	})
+}
+
+// stream writes Server-Sent Events named event: next's data (skipped when
+// nil) now and again each time next's channel closes, with a keep-alive
+// comment in between, until the client disconnects or the server closes.
+func (s *Server) stream(w http.ResponseWriter, r *http.Request, event string, next func() (uint64, []byte, <-chan struct{})) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+	for {
+		id, data, changed := next()
+		if data != nil {
+			if _, err := fmt.Fprintf(w, "event: %s\nid: %d\ndata: %s\n\n", event, id, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+
+	wait:
+		for {
+			select {
+			case <-changed:
+				break wait
+			case <-ticker.C:
+				if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+					return
+				}
+				flusher.Flush()
+			case <-s.done:
+				return
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+}
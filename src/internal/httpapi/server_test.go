@@ -0,0 +1,254 @@
+package httpapi
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/internal/testhelpers"
+	"cc-dailyuse-bar/src/models"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testhelpers.RunSilenced(m))
+}
+
+func startServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	s := NewServer("127.0.0.1:0")
+	require.NoError(t, s.Start())
+	t.Cleanup(func() { _ = s.Close() })
+	return s, "http://" + s.Addr()
+}
+
+func getStatus(t *testing.T, url string) (Status, *http.Response) {
+	t.Helper()
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var status Status
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	return status, resp
+}
+
+func snapshot(cost float64, status string) models.StatusSnapshot {
+	return models.StatusSnapshot{Available: true, Status: status, Title: "CC", DailyCost: cost}
+}
+
+func TestServer_Status(t *testing.T) {
+	s, base := startServer(t)
+
+	status, resp := getStatus(t, base+"/v1/status")
+	assert.Equal(t, strconv.Itoa(APIVersion), resp.Header.Get(VersionHeader))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "unknown", status.Status)
+	assert.Equal(t, models.SchemaVersion, status.SchemaVersion)
+	assert.False(t, status.Available)
+	assert.Zero(t, status.Seq)
+
+	s.Publish(snapshot(12.4, "yellow"))
+	status, resp = getStatus(t, base+"/v1/status")
+	assert.Equal(t, uint64(1), status.Seq)
+	assert.Equal(t, "yellow", status.Status)
+	assert.Equal(t, 12.4, status.DailyCost)
+	assert.Equal(t, `"1"`, resp.Header.Get("ETag"))
+
+	s.Publish(snapshot(12.4, "yellow"))
+	status, _ = getStatus(t, base+"/v1/status")
+	assert.Equal(t, uint64(1), status.Seq, "an unchanged status keeps its seq")
+}
+
+func TestServer_LongPoll(t *testing.T) {
+	s, base := startServer(t)
+	s.Publish(snapshot(4, "green"))
+
+	start := time.Now()
+	status, _ := getStatus(t, base+"/v1/status?since=0")
+	assert.Equal(t, uint64(1), status.Seq, "a newer status returns at once")
+	assert.Less(t, time.Since(start), time.Second)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		s.Publish(snapshot(25, "red"))
+	}()
+	status, _ = getStatus(t, base+"/v1/status?since=1&wait=10")
+	assert.Equal(t, uint64(2), status.Seq)
+	assert.Equal(t, "red", status.Status)
+
+	start = time.Now()
+	status, _ = getStatus(t, base+"/v1/status?since=2&wait=0")
+	assert.Equal(t, uint64(2), status.Seq, "the wait ran out without a change")
+	assert.Less(t, time.Since(start), time.Second)
+
+	status, _ = getStatus(t, base+"/v1/status?since=99")
+	assert.Equal(t, uint64(2), status.Seq, "a seq from before a restart returns at once")
+
+	resp, err := http.Get(base + "/v1/status?since=x")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestServer_Events(t *testing.T) {
+	s, base := startServer(t)
+	s.Publish(snapshot(4, "green"))
+
+	resp, err := http.Get(base + "/v1/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, strconv.Itoa(APIVersion), resp.Header.Get(VersionHeader))
+
+	reader := bufio.NewReader(resp.Body)
+	next := func() Status {
+		t.Helper()
+		var data string
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimRight(line, "\n")
+			if line == "" && data != "" {
+				var status Status
+				require.NoError(t, json.Unmarshal([]byte(data), &status))
+				return status
+			}
+			if rest, ok := strings.CutPrefix(line, "data: "); ok {
+				data = rest
+			}
+		}
+	}
+
+	assert.Equal(t, "green", next().Status)
+	s.Publish(snapshot(25, "red"))
+	got := next()
+	assert.Equal(t, "red", got.Status)
+	assert.Equal(t, uint64(2), got.Seq)
+}
+
+func TestServer_Usage(t *testing.T) {
+	s, base := startServer(t)
+
+	resp, err := http.Get(base + "/usage")
+	require.NoError(t, err)
+	var state *models.UsageState
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&state))
+	resp.Body.Close()
+	assert.Nil(t, state, "null before the first update")
+
+	s.PublishUsage(&models.UsageState{DailyCost: 12.4, DailyCount: 900, Status: models.Yellow, IsAvailable: true})
+	resp, err = http.Get(base + "/usage")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&state))
+	assert.Equal(t, 12.4, state.DailyCost)
+	assert.Equal(t, 900, state.DailyCount)
+	assert.Equal(t, models.Yellow, state.Status)
+}
+
+func TestServer_UsageEvents(t *testing.T) {
+	s, base := startServer(t)
+
+	resp, err := http.Get(base + "/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := make(chan string, 4)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		var event string
+		for scanner.Scan() {
+			line := scanner.Text()
+			if rest, ok := strings.CutPrefix(line, "event: "); ok {
+				event = rest
+			}
+			if rest, ok := strings.CutPrefix(line, "data: "); ok {
+				events <- event + " " + rest
+			}
+		}
+	}()
+
+	// Nothing is sent before the first update; every update is sent, even
+	// an unchanged one.
+	state := &models.UsageState{DailyCost: 4, Status: models.Green, IsAvailable: true}
+	for range 2 {
+		s.PublishUsage(state)
+		select {
+		case got := <-events:
+			assert.True(t, strings.HasPrefix(got, "usage {"), got)
+			assert.Contains(t, got, `"daily_cost":4`)
+		case <-time.After(5 * time.Second):
+			t.Fatal("no usage event")
+		}
+		time.Sleep(50 * time.Millisecond) // let the stream wait for the next update
+	}
+}
+
+func TestServer_CloseEndsStreams(t *testing.T) {
+	s, base := startServer(t)
+	resp, err := http.Get(base + "/v1/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.NoError(t, s.Close())
+	done := make(chan struct{})
+	go func() {
+		_, _ = bufio.NewReader(resp.Body).ReadString(0)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("event stream still open after Close")
+	}
+	assert.NoError(t, s.Close(), "closing twice is fine")
+}
+
+func TestServer_Guards(t *testing.T) {
+	_, base := startServer(t)
+
+	req, err := http.NewRequest(http.MethodGet, base+"/v1/status", nil)
+	require.NoError(t, err)
+	req.Host = "evil.example.com"
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "DNS rebinding is refused")
+
+	resp, err = http.Post(base+"/v1/status", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	resp, err = http.Get(base + "/nope")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestServer_RefusesNonLoopback(t *testing.T) {
+	assert.ErrorContains(t, NewServer("0.0.0.0:0").Start(), "non-loopback")
+}
+
+func TestLoopbackHost(t *testing.T) {
+	for host, want := range map[string]bool{
+		"127.0.0.1:7399": true,
+		"localhost":      true,
+		"[::1]:7399":     true,
+		"::1":            true,
+		"example.com":    false,
+		"10.0.0.1:7399":  false,
+	} {
+		assert.Equal(t, want, loopbackHost(host), host)
+	}
+}
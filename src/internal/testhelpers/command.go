@@ -0,0 +1,46 @@
+package testhelpers
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// WriteFakeCommand writes a program called name into dir that prints
+// stdout and exits with exitCode, standing in for ccusage or another tool:
+// a sh script, or on Windows a cmd.exe shim, name.cmd. The output is kept
+// in a file beside it, so it needs no quoting. It returns the program's
+// path.
+func WriteFakeCommand(t testing.TB, dir, name, stdout string, exitCode int) string {
+	t.Helper()
+	outPath := filepath.Join(dir, name+".out")
+	if err := os.WriteFile(outPath, []byte(stdout), 0o644); err != nil {
+		t.Fatalf("write fake command output: %v", err)
+	}
+
+	path := filepath.Join(dir, name)
+	var script string
+	if runtime.GOOS == "windows" {
+		path += ".cmd"
+		script = "@echo off\r\ntype \"" + outPath + "\"\r\nexit /b " + strconv.Itoa(exitCode) + "\r\n"
+	} else {
+		script = "#!/bin/sh\ncat '" + strings.ReplaceAll(outPath, "'", `'\''`) + "'\nexit " + strconv.Itoa(exitCode) + "\n"
+	}
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake command: %v", err)
+	}
+	return path
+}
+
+// RequireShell skips a test whose fake commands are shell scripts on
+// Windows, which has no /bin/sh; use WriteFakeCommand where a fixed
+// output is enough.
+func RequireShell(t testing.TB) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+}
@@ -2,10 +2,16 @@ package tray
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/getlantern/systray"
 
+	"cc-dailyuse-bar/src/internal/dbus"
+	"cc-dailyuse-bar/src/internal/httpapi"
 	"cc-dailyuse-bar/src/lib"
 	"cc-dailyuse-bar/src/models"
 	"cc-dailyuse-bar/src/services"
@@ -13,21 +19,177 @@ import (
 
 // Runner handles the system tray UI and logic
 type Runner struct {
-	config       *models.Config
+	live         *models.LiveConfig // see config
 	usageService *services.UsageService
 	menuItems    []*systray.MenuItem
 	logger       *lib.Logger
 	stopFallback chan struct{} // signals the fallback polling goroutine to stop
+
+	configService  *services.ConfigService // persists menu-driven changes; nil keeps them in memory
+	quietItem      *systray.MenuItem
+	intervalItem   *systray.MenuItem
+	intervalItems  []*systray.MenuItem // one per updateIntervalChoices entry
+	displayItem    *systray.MenuItem
+	thresholdItem  *systray.MenuItem
+	thresholdItems []*systray.MenuItem // one per thresholdActions entry
+	dataDirItem    *systray.MenuItem   // hidden unless usage data is in both default folders
+	dataDirItems   []*systray.MenuItem // one "Use only" entry per default folder
+	settingsItem   *systray.MenuItem
+	settingsItems  []*systray.MenuItem // read-only lines, see settingsLines
+
+	resources        *services.ResourceMonitor
+	diagnosticsItem  *systray.MenuItem
+	diagnosticsItems []*systray.MenuItem // read-only lines, see diagnosticsLines
+
+	notifications *services.NotificationService
+	alertSound    *services.AlertSound
+	telegram      *services.TelegramAlerts
+	ackItem       *systray.MenuItem // shown while reminders are due, see NotificationService.NotifyStatus
+	soundItem     *systray.MenuItem // hidden unless red_sound is set
+
+	teamService *services.TeamService // nil unless team_dir is configured
+	teamItem    *systray.MenuItem
+	teamItems   []*systray.MenuItem
+	teamBusy    atomic.Bool // updateTeam is reading the exports
+
+	tagItem  *systray.MenuItem // hidden until project_tags splits today's spend
+	tagItems []*systray.MenuItem
+
+	historyService *services.HistoryService // nil hides the hourly breakdown
+	peakItem       *systray.MenuItem
+	hourItems      []*systray.MenuItem
+	changesItem    *systray.MenuItem
+	changeItems    []*systray.MenuItem
+	recalcItem     *systray.MenuItem      // shown while ccusage's pricing has changed since history was recorded
+	streak         int                    // days under budget before streakDay
+	streakDay      string                 // YYYY-MM-DD streak was computed on; empty before the first update
+	ledger         *services.DailyLedger  // nil until the first update; see dailyLedger
+	ledgerSettings ledgerSettings         // what ledger was built from
+	ledgerDay      atomic.Pointer[string] // YYYY-MM-DD the ledger was last caught up on
+	ledgerBusy     atomic.Bool            // a catch-up is running
+	archiveDay     string                 // YYYY-MM-DD the month archive was last checked on
+
+	statusFile *services.StatusFile // nil disables the widget status file
+	bus        *dbus.Server         // nil unless the D-Bus service is running
+	statusAPI  *httpapi.Server      // nil unless http_listen is set
+}
+
+const (
+	// detailMenuSize is the number of placeholders for the usage detail
+	// lines at the top of the menu.
+	detailMenuSize = 16
+	// quietPeriodDays is how long the "Quiet for a week" menu item silences alerts.
+	quietPeriodDays = 7
+	// teamMenuSize is the number of per-person placeholders in the team submenu.
+	teamMenuSize = 20
+	// tagMenuSize is the number of per-tag placeholders in the tags submenu.
+	tagMenuSize = 15
+	// histogramBarWidth is the number of block characters in a full-width
+	// hourly histogram bar.
+	histogramBarWidth = 10
+	// histogramMenuSize fits one line per hour plus the untracked line.
+	histogramMenuSize = 25
+	// statusChangeMenuSize is the number of placeholders in the status
+	// changes submenu; older changes are summarised in the first line.
+	statusChangeMenuSize = 15
+	// settingsMenuSize fits every line settingsLines can produce.
+	settingsMenuSize = 21
+	// diagnosticsMenuSize fits every line diagnosticsLines can produce.
+	diagnosticsMenuSize = 8
+	// dataDirMenuSize is the number of default Claude folders
+	// DetectDataDirOverlap can report.
+	dataDirMenuSize = 2
+)
+
+// thresholdStep is how much the Thresholds submenu nudges a threshold.
+const thresholdStep = 5.0
+
+// thresholdAction is one Thresholds submenu entry: it maps the current
+// yellow/red pair to a new one.
+type thresholdAction struct {
+	label  string
+	preset bool // presets are listed after the nudges, below a divider
+	apply  func(yellow, red float64) (float64, float64)
+}
+
+// raiseYellow and raiseRed are also run by the Raise threshold action on
+// status notifications.
+var (
+	raiseYellow = thresholdAction{"Yellow +$5", false, func(y, r float64) (float64, float64) { return y + thresholdStep, r }}
+	raiseRed    = thresholdAction{"Red +$5", false, func(y, r float64) (float64, float64) { return y, r + thresholdStep }}
+)
+
+// thresholdActions are the quick adjustments offered by the Thresholds
+// submenu: nudges of thresholdStep on either threshold, then presets.
+var thresholdActions = []thresholdAction{
+	raiseYellow,
+	{"Yellow −$5", false, func(y, r float64) (float64, float64) { return y - thresholdStep, r }},
+	raiseRed,
+	{"Red −$5", false, func(y, r float64) (float64, float64) { return y, r - thresholdStep }},
+	{"Light day: $5 / $10", true, func(_, _ float64) (float64, float64) { return 5, 10 }},
+	{"Default: $10 / $20", true, func(_, _ float64) (float64, float64) {
+		defaults := models.ConfigDefaults()
+		return defaults.YellowThreshold, defaults.RedThreshold
+	}},
+	{"Heavy day: $25 / $50", true, func(_, _ float64) (float64, float64) { return 25, 50 }},
 }
 
+// updateIntervalChoices are the polling intervals, in seconds, offered by
+// the "Update every" submenu.
+var updateIntervalChoices = []int{15, 30, 60, 300}
+
 // NewRunner creates a new instance of Runner
-func NewRunner(config *models.Config, usageService *services.UsageService) *Runner {
-	return &Runner{
-		config:       config,
-		usageService: usageService,
-		menuItems:    make([]*systray.MenuItem, 0),
-		logger:       lib.NewLogger("tray-runner"),
+func NewRunner(config *models.LiveConfig, usageService *services.UsageService) *Runner {
+	tr := &Runner{
+		live:          config,
+		usageService:  usageService,
+		menuItems:     make([]*systray.MenuItem, 0),
+		logger:        lib.NewLogger("tray-runner"),
+		notifications: services.NewNotificationService(),
+		alertSound:    services.NewAlertSound(),
+		telegram:      services.NewTelegramAlerts(),
+		resources:     services.NewResourceMonitor(),
+	}
+	if teamDir := config.Get().TeamDir; teamDir != "" {
+		tr.teamService = services.NewTeamService(teamDir)
 	}
+	return tr
+}
+
+// config returns the current config snapshot, which reloads and menu
+// changes replace rather than modify. Read it once where several settings
+// must agree.
+func (tr *Runner) config() *models.Config {
+	return tr.live.Get()
+}
+
+// SetConfigService lets menu actions such as quiet mode persist to the
+// config file.
+func (tr *Runner) SetConfigService(cs *services.ConfigService) {
+	tr.configService = cs
+}
+
+// SetHistoryService enables the peak hour item and hourly histogram
+// submenu, built from locally recorded usage samples.
+func (tr *Runner) SetHistoryService(hs *services.HistoryService) {
+	tr.historyService = hs
+}
+
+// SetStatusFile publishes every update to sf for widgets and scripts; the
+// file is removed on exit.
+func (tr *Runner) SetStatusFile(sf *services.StatusFile) {
+	tr.statusFile = sf
+}
+
+// SetBusService publishes every update as properties of the D-Bus
+// service.
+func (tr *Runner) SetBusService(bus *dbus.Server) {
+	tr.bus = bus
+}
+
+// SetStatusAPI publishes every update to the editor status API.
+func (tr *Runner) SetStatusAPI(api *httpapi.Server) {
+	tr.statusAPI = api
 }
 
 // Run starts the system tray application
@@ -37,18 +199,7 @@ func (tr *Runner) Run() {
 }
 
 func (tr *Runner) emojiForStatus(status models.AlertStatus) string {
-	switch status {
-	case models.Green:
-		return "🟢"
-	case models.Yellow:
-		return "🟡"
-	case models.Red:
-		return "🔴"
-	case models.Unknown:
-		return "⚪️"
-	default:
-		return "⚪️"
-	}
+	return tr.config().Symbols().Symbol(status)
 }
 
 func (tr *Runner) onReady() {
@@ -56,20 +207,128 @@ func (tr *Runner) onReady() {
 	systray.SetTooltip("Claude Code Daily Usage Monitor")
 
 	// Create placeholder menu items (will be dynamically updated)
-	for i := 0; i < 10; i++ {
+	for i := 0; i < detailMenuSize; i++ {
 		tr.menuItems = append(tr.menuItems, systray.AddMenuItem("Loading...", "Loading..."))
 	}
 
+	tr.dataDirItem = systray.AddMenuItem("", "")
+	tr.dataDirItem.AddSubMenuItem("ccusage may count usage twice;", "").Disable()
+	tr.dataDirItem.AddSubMenuItem("pick the folder Claude Code uses:", "").Disable()
+	for i := 0; i < dataDirMenuSize; i++ {
+		tr.dataDirItems = append(tr.dataDirItems, tr.dataDirItem.AddSubMenuItem("", ""))
+	}
+	tr.dataDirItem.Hide()
+	go tr.checkDataDirs()
+
+	if tr.teamService != nil {
+		systray.AddSeparator()
+		tr.teamItem = systray.AddMenuItem(tr.label("👥 Team: Loading..."), "Usage exported by teammates")
+		for i := 0; i < teamMenuSize; i++ {
+			tr.teamItems = append(tr.teamItems, tr.teamItem.AddSubMenuItem("", ""))
+		}
+	}
+
+	tr.tagItem = systray.AddMenuItem(tr.label("🏷️ Tags today"), "Today's spend per project_tags tag")
+	for i := 0; i < tagMenuSize; i++ {
+		item := tr.tagItem.AddSubMenuItem("", "")
+		item.Disable()
+		tr.tagItems = append(tr.tagItems, item)
+	}
+	tr.tagItem.Hide()
+
+	if tr.historyService != nil {
+		systray.AddSeparator()
+		tr.peakItem = systray.AddMenuItem(tr.label("⏱ Peak hour: Loading..."), "Today's spend by hour")
+		for i := 0; i < histogramMenuSize; i++ {
+			tr.hourItems = append(tr.hourItems, tr.peakItem.AddSubMenuItem("", ""))
+		}
+		tr.changesItem = systray.AddMenuItem(tr.label("🚦 Status changes today: Loading..."), "When today's status crossed a threshold")
+		for i := 0; i < statusChangeMenuSize; i++ {
+			tr.changeItems = append(tr.changeItems, tr.changesItem.AddSubMenuItem("", ""))
+		}
+		tr.recalcItem = systray.AddMenuItem(tr.label("♻️ Prices changed: recalculate history"),
+			"Reprice recorded history with ccusage's current prices so charts stay consistent")
+		tr.recalcItem.Hide()
+		go func() {
+			for range tr.recalcItem.ClickedCh {
+				tr.recalculateHistory()
+			}
+		}()
+
+		exportItem := systray.AddMenuItem(tr.label("📤 Export"), "Save views of the recorded history to files")
+		for _, export := range []struct{ label, format string }{
+			{"Usage heat map (HTML)", services.HeatMapHTML},
+			{"Usage heat map (CSV)", services.HeatMapCSV},
+		} {
+			item := exportItem.AddSubMenuItem(export.label, "Tokens by weekday and hour over the last four weeks")
+			format := export.format
+			go func() {
+				for range item.ClickedCh {
+					tr.exportHeatMap(format)
+				}
+			}()
+		}
+	}
+
 	systray.AddSeparator()
-	mSettings := systray.AddMenuItem("Settings", "Open settings")
+	tr.ackItem = systray.AddMenuItem(tr.label("✋ Acknowledge alert"), "Stop reminders about today's spend")
+	tr.ackItem.Hide()
+	tr.quietItem = systray.AddMenuItem("", "")
+	tr.refreshQuietItem()
+	tr.soundItem = systray.AddMenuItem("", "Mute or unmute the sound played when usage turns red")
+	tr.refreshSoundItem()
+	tr.intervalItem = systray.AddMenuItem("", "How often usage is refreshed")
+	for _, seconds := range updateIntervalChoices {
+		item := tr.intervalItem.AddSubMenuItemCheckbox(formatInterval(seconds), "Refresh usage every "+formatInterval(seconds), false)
+		tr.intervalItems = append(tr.intervalItems, item)
+		go func(seconds int) {
+			for range item.ClickedCh {
+				tr.chooseUpdateInterval(seconds)
+			}
+		}(seconds)
+	}
+	tr.refreshIntervalItems()
+	tr.displayItem = systray.AddMenuItem("", "Change what the menu bar title shows after the status")
+	tr.refreshDisplayItem()
+	tr.thresholdItem = systray.AddMenuItem("", "Adjust the yellow and red thresholds")
+	for i, action := range thresholdActions {
+		if i > 0 && action.preset && !thresholdActions[i-1].preset {
+			tr.thresholdItem.AddSubMenuItem("──────", "").Disable()
+		}
+		item := tr.thresholdItem.AddSubMenuItem(action.label, models.PlainText(action.label))
+		tr.thresholdItems = append(tr.thresholdItems, item)
+		go func(action thresholdAction) {
+			for range item.ClickedCh {
+				tr.applyThresholdAction(action)
+			}
+		}(action)
+	}
+	tr.refreshThresholdItems()
+	tr.settingsItem = systray.AddMenuItem(tr.label("⚙️ Current settings"), "The configuration in effect")
+	for i := 0; i < settingsMenuSize; i++ {
+		item := tr.settingsItem.AddSubMenuItem("", "")
+		item.Disable()
+		tr.settingsItems = append(tr.settingsItems, item)
+	}
+	tr.refreshSettingsItems()
+	tr.resources.Start()
+	tr.diagnosticsItem = systray.AddMenuItem("", "Health of the running app")
+	for i := 0; i < diagnosticsMenuSize; i++ {
+		item := tr.diagnosticsItem.AddSubMenuItem("", "")
+		item.Disable()
+		tr.diagnosticsItems = append(tr.diagnosticsItems, item)
+	}
+	tr.refreshDiagnosticsItems()
 	systray.AddSeparator()
 	mQuit := systray.AddMenuItem("Quit", "Quit the application")
 
+	tr.notifications.SetActionHandler(tr.handleNotificationAction, tr.statusAPI != nil || tr.historyService != nil)
+
 	// Initial update
 	tr.updateStatus()
 
 	// Use the service's polling mechanism
-	err := tr.usageService.StartPolling(tr.config.UpdateInterval, func(state *models.UsageState) {
+	err := tr.usageService.StartPolling(tr.config().UpdateInterval, func(state *models.UsageState) {
 		tr.updateUIFromState(state)
 	})
 	if err != nil {
@@ -78,7 +337,7 @@ func (tr *Runner) onReady() {
 		})
 		tr.stopFallback = make(chan struct{})
 		go func() {
-			ticker := time.NewTicker(time.Duration(tr.config.UpdateInterval) * time.Second)
+			ticker := time.NewTicker(time.Duration(tr.config().UpdateInterval) * time.Second)
 			defer ticker.Stop()
 			for {
 				select {
@@ -95,8 +354,15 @@ func (tr *Runner) onReady() {
 	go func() {
 		for {
 			select {
-			case <-mSettings.ClickedCh:
-				tr.showSettings()
+			case <-tr.quietItem.ClickedCh:
+				tr.toggleQuiet()
+			case <-tr.soundItem.ClickedCh:
+				tr.toggleSoundMuted()
+			case <-tr.ackItem.ClickedCh:
+				tr.notifications.Acknowledge()
+				tr.refreshAckItem()
+			case <-tr.displayItem.ClickedCh:
+				tr.cycleTitleDisplay()
 			case <-mQuit.ClickedCh:
 				systray.Quit()
 				return
@@ -106,33 +372,588 @@ func (tr *Runner) onReady() {
 }
 
 func (tr *Runner) updateUIFromState(state *models.UsageState) {
+	tr.refreshDiagnosticsItems()
+	tr.notifications.SetFocusPolicy(tr.config().FocusNotifications) // follows config reloads
+	tr.notifications.SetSinks(tr.config())
+	tr.notifications.FlushDeferred()
+	percent, polls := tr.usageService.PollReliability()
+	tr.notifications.NotifyPollReliability(state, percent, polls, tr.config().PollReliabilityWarning)
+	tr.notifications.NotifyPollFailure(state, tr.usageService.FailingSince(), time.Duration(tr.config().PollFailureAlert)*time.Minute)
 	if state == nil {
 		systray.SetTitle("CC Error")
 		tr.updateMenuItems([]string{"❌ No data available"})
+		tr.publishIndicator(dbus.Indicator{Label: "CC Error", Style: "unknown", Lines: []string{"❌ No data available"}})
 		return
 	}
 
 	if !state.IsAvailable {
-		systray.SetTitle("CC ⚪️ Unknown")
+		systray.SetTitle(models.FormatUnknownTitle(tr.config()))
+		systray.SetTooltip("Claude Code usage data unavailable")
 		tr.updateMenuItems([]string{"⚠️ Usage data unavailable"})
+		tr.publishStatus(state)
+		tr.publishIndicator(dbus.Indicator{
+			Label:   models.FormatUnknownTitle(tr.config()),
+			Style:   "unknown",
+			Tooltip: "Claude Code usage data unavailable",
+			Lines:   []string{"⚠️ Usage data unavailable"},
+		})
 		return
 	}
 
 	// Recompute status from thresholds before reading it — otherwise a stale
 	// Unknown carried over from a prior tick would short-circuit the display.
-	state.UpdateStatus(tr.config.YellowThreshold, tr.config.RedThreshold)
-	emoji := tr.emojiForStatus(state.Status)
+	state.UpdateStatusFromConfig(tr.config())
+	tr.publishStatus(state)
+	if !state.Paused {
+		tr.notifications.NotifyModelAlerts(state, tr.config().CostFormat())
+		tr.notifications.NotifySessionAlerts(state, tr.config())
+		tr.notifications.NotifyStatus(state, tr.config())
+	}
+	tr.refreshAckItem()
+	tr.alertSound.Observe(state, tr.config())
+	tr.telegram.Observe(state, tr.config(), time.Now())
+	tr.refreshStreak(state, time.Now())
+	tr.refreshLedger(time.Now())
+	tr.refreshArchive(state, time.Now())
 
 	// Update compact title
-	systray.SetTitle(fmt.Sprintf("CC %s $%.2f", emoji, state.DailyCost))
+	systray.SetTitle(tr.formatTitle(state))
+	systray.SetTooltip(tr.titleTooltip(state))
+	tr.refreshQuietItem()     // the quiet period may have lapsed since the last tick
+	tr.refreshSoundItem()     // a config reload may have set or cleared red_sound
+	tr.refreshIntervalItems() // a config reload may have changed the interval
+	tr.refreshDisplayItem()
+	tr.refreshThresholdItems()
+	tr.refreshSettingsItems() // always the live config, including reloads
 
 	// Update detailed menu items
 	detailedInfo := []string{
-		fmt.Sprintf("💰 Daily Cost: $%.2f", state.DailyCost),
-		fmt.Sprintf("🎯 API Calls: %d", state.DailyCount),
-		fmt.Sprintf("📅 Last Update: %s", state.LastUpdate.Format("2006-01-02 15:04:05")),
+		fmt.Sprintf("💰 Daily Cost: %s", tr.config().CostFormat().Format(state.DailyCost)),
+		fmt.Sprintf("🎯 Calls: %d", state.DailyCalls),
+		fmt.Sprintf("🔢 Tokens: %s", tr.config().DisplayLocale().FormatTokens(state.DailyCount)),
+		fmt.Sprintf("📅 Last Update: %s", tr.config().DisplayLocale().FormatDateTime(state.LastUpdate)),
+	}
+	if line := tr.untilRedLine(state); line != "" {
+		detailedInfo = append(detailedInfo, line)
+	}
+	if names := state.ModelNames(); len(names) > 0 {
+		detailedInfo = append(detailedInfo, "🤖 Models: "+strings.Join(names, ", "))
+	}
+	if line := tr.topSessionLine(state); line != "" {
+		detailedInfo = append(detailedInfo, line)
+	}
+	detailedInfo = append(detailedInfo, comparisonLines(state)...)
+	if tr.streak > 0 {
+		detailedInfo = append(detailedInfo, models.FormatStreak(tr.streak))
+	}
+	if state.Level != "" {
+		detailedInfo = append(detailedInfo, fmt.Sprintf("🚦 Alert Level: %s", state.Level))
+	}
+	detailedInfo = append(detailedInfo, tr.modelAlertLines(state)...)
+	detailedInfo = append(detailedInfo, tr.sessionAlertLines(state)...)
+	if line := tr.weeklyBudgetLine(state); line != "" {
+		detailedInfo = append(detailedInfo, line)
+	}
+	if line := tr.forecastLine(state); line != "" {
+		detailedInfo = append(detailedInfo, line)
+	}
+	if state.Quiet {
+		detailedInfo = append(detailedInfo, fmt.Sprintf("🔕 Alerts quiet until %s", tr.config().QuietUntil))
+	}
+	if state.Paused {
+		detailedInfo = append(detailedInfo, fmt.Sprintf("💤 Quiet hours: polling paused (%s)", tr.config().QuietHoursWindow()))
+	}
+	if state.Demo {
+		detailedInfo = append(detailedInfo, "🧪 Demo mode: synthetic data")
 	}
 	tr.updateMenuItems(detailedInfo)
+	tr.publishIndicator(dbus.Indicator{
+		Label:   tr.formatTitle(state),
+		Style:   state.Status.ColorName(),
+		Tooltip: tr.titleTooltip(state),
+		Lines:   detailedInfo,
+	})
+	tr.updateTeam()
+	tr.updateTags(state)
+	tr.updateHistogram()
+	tr.updateStatusChanges()
+	tr.refreshRecalcItem()
+}
+
+// refreshStreak recomputes the under-budget streak once per day, since
+// only finished days count towards it, and announces it when the day
+// rolls over while the app is running, unless quiet hours have paused
+// polling.
+func (tr *Runner) refreshStreak(state *models.UsageState, now time.Time) {
+	today := now.Format("2006-01-02")
+	if tr.historyService == nil || today == tr.streakDay {
+		return
+	}
+
+	streak, err := tr.historyService.UnderBudgetStreak()
+	if err != nil {
+		tr.logger.Warn("Failed to compute spending streak", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	newDay := tr.streakDay != ""
+	tr.streak, tr.streakDay = streak, today
+	if newDay && !state.Paused {
+		tr.notifications.NotifyDailyReset(state, streak)
+	}
+}
+
+// ledgerSettings are the config settings a DailyLedger is built from.
+type ledgerSettings struct {
+	csv, webhook, telegramToken, telegramChat string
+	format                                    models.CostFormat
+}
+
+// dailyLedger returns the ledger for the current config, building a new
+// one only when its settings change.
+func (tr *Runner) dailyLedger() *services.DailyLedger {
+	config := tr.config()
+	settings := ledgerSettings{config.LedgerCSV, config.LedgerWebhook, config.TelegramBotToken, config.TelegramChatID, config.CostFormat()}
+	if tr.ledger == nil || settings != tr.ledgerSettings {
+		tr.ledger = services.NewDailyLedger(settings.csv, settings.webhook)
+		if bot := services.NewTelegramBotForConfig(config); bot != nil {
+			tr.ledger.SetTelegram(bot, settings.format)
+		}
+		tr.ledgerSettings = settings
+	}
+	return tr.ledger
+}
+
+// refreshLedger delivers finished days to ledger_csv, ledger_webhook, and
+// Telegram once per day, in the background since it runs ccusage and may
+// post over the network. A failed delivery is retried at the next update.
+func (tr *Runner) refreshLedger(now time.Time) {
+	ledger := tr.dailyLedger()
+	today := now.Format("2006-01-02")
+	if !ledger.Enabled() {
+		return
+	}
+	if day := tr.ledgerDay.Load(); day != nil && *day == today {
+		return
+	}
+	if !tr.ledgerBusy.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer tr.ledgerBusy.Store(false)
+		if err := ledger.CatchUp(now, tr.usageService.DailyHistory); err != nil {
+			tr.logger.Warn("Failed to update the spend ledger", map[string]interface{}{
+				"error": err.Error(),
+			})
+			return
+		}
+		tr.ledgerDay.Store(&today)
+	}()
+}
+
+// refreshArchive writes last month's archive, once it's over, and
+// announces it. It checks once per day, so a month finished while the app
+// wasn't running is archived at the next start; it runs in the background
+// since it runs ccusage.
+func (tr *Runner) refreshArchive(state *models.UsageState, now time.Time) {
+	today := now.Format("2006-01-02")
+	if today == tr.archiveDay {
+		return
+	}
+	tr.archiveDay = today
+	budget, format := tr.config().MonthlyBudget, tr.config().CostFormat()
+	go func() {
+		archiver := services.NewMonthArchiver()
+		archive, err := archiver.ArchivePreviousMonth(now, budget, format, tr.usageService.DailyHistory)
+		if err != nil {
+			tr.logger.Warn("Failed to archive last month", map[string]interface{}{
+				"error": err.Error(),
+			})
+			return
+		}
+		if archive == nil {
+			return
+		}
+		_, mdPath := archiver.Paths(archive.Month)
+		tr.logger.Info("Archived last month", map[string]interface{}{
+			"month": archive.Month,
+			"path":  mdPath,
+		})
+		tr.notifications.NotifyMonthArchived(state, archive, mdPath, format)
+	}()
+}
+
+// refreshRecalcItem offers to recalculate history only while ccusage's
+// prices differ from those some recorded history was computed with.
+func (tr *Runner) refreshRecalcItem() {
+	if tr.historyService == nil || tr.recalcItem == nil {
+		return
+	}
+	if tr.historyService.PricingChangePending() {
+		tr.recalcItem.Show()
+	} else {
+		tr.recalcItem.Hide()
+	}
+}
+
+// recalculateHistory reprices recorded history with ccusage's current
+// prices and redraws the views built from it.
+func (tr *Runner) recalculateHistory() {
+	days, err := tr.historyService.RecalculateHistory(tr.usageService.DailyHistory)
+	if err != nil {
+		tr.logger.Error("Failed to recalculate usage history", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	tr.logger.Info("Recalculated usage history with current pricing", map[string]interface{}{
+		"days": days,
+	})
+	tr.refreshRecalcItem()
+	tr.updateHistogram()
+	tr.updateStatusChanges()
+}
+
+// exportHeatMap writes the usage heat map in format and says where.
+func (tr *Runner) exportHeatMap(format string) {
+	heatMap, err := tr.historyService.HeatMap(services.DefaultHeatMapDays)
+	var path string
+	if err == nil {
+		path, err = services.ExportHeatMap(heatMap, format, tr.config().CostFormat())
+	}
+	if err != nil {
+		tr.logger.Error("Failed to export usage heat map", map[string]interface{}{
+			"format": format,
+			"error":  err.Error(),
+		})
+		return
+	}
+	tr.logger.Info("Exported usage heat map", map[string]interface{}{
+		"path": path,
+	})
+	tr.notifications.NotifyExported("Usage heat map", path)
+}
+
+// updateHistogram refreshes the peak hour item and its per-hour submenu.
+func (tr *Runner) updateHistogram() {
+	if tr.historyService == nil || tr.peakItem == nil {
+		return
+	}
+
+	histogram, err := tr.historyService.HourlyHistogram()
+	if err != nil {
+		tr.logger.Warn("Failed to read usage history", map[string]interface{}{
+			"error": err.Error(),
+		})
+		tr.peakItem.SetTitle(tr.label("⏱ Peak hour: unavailable"))
+		tr.setHourItems(nil)
+		return
+	}
+
+	summary, lines := tr.histogramMenuLines(histogram)
+	tr.peakItem.SetTitle(tr.label(summary))
+	tr.setHourItems(lines)
+}
+
+func (tr *Runner) setHourItems(lines []string) {
+	tr.setSubmenuItems(tr.hourItems, lines)
+}
+
+// updateStatusChanges refreshes the status changes item and its submenu.
+func (tr *Runner) updateStatusChanges() {
+	if tr.historyService == nil || tr.changesItem == nil {
+		return
+	}
+
+	changes, err := tr.historyService.StatusChangesToday()
+	if err != nil {
+		tr.logger.Warn("Failed to read status changes", map[string]interface{}{
+			"error": err.Error(),
+		})
+		tr.changesItem.SetTitle(tr.label("🚦 Status changes today: unavailable"))
+		tr.setSubmenuItems(tr.changeItems, nil)
+		return
+	}
+
+	summary, lines := tr.statusChangeMenuLines(changes)
+	tr.changesItem.SetTitle(tr.label(summary))
+	tr.setSubmenuItems(tr.changeItems, lines)
+}
+
+// setSubmenuItems shows one placeholder per line and hides the rest.
+func (tr *Runner) setSubmenuItems(items []*systray.MenuItem, lines []string) {
+	for i, item := range items {
+		if i < len(lines) {
+			tr.setLine(item, lines[i])
+			item.Show()
+		} else {
+			item.Hide()
+		}
+	}
+}
+
+// statusChangeMenuLines renders the status changes summary and one line per
+// transition, e.g. "11:05 🟢 → 🟡 at $10.20". When there are more changes
+// than placeholders, the oldest are folded into a leading "+N earlier".
+func (tr *Runner) statusChangeMenuLines(changes []models.StatusChange) (string, []string) {
+	if len(changes) == 0 {
+		return "🚦 Status changes today: none", nil
+	}
+	summary := fmt.Sprintf("🚦 Status changes today: %d", len(changes))
+
+	format := tr.config().CostFormat()
+	var lines []string
+	if len(changes) > statusChangeMenuSize {
+		hidden := len(changes) - statusChangeMenuSize + 1
+		lines = append(lines, fmt.Sprintf("+%d earlier", hidden))
+		changes = changes[hidden:]
+	}
+	for _, c := range changes {
+		lines = append(lines, fmt.Sprintf("%s %s → %s at %s", format.Locale.FormatTime(c.Time),
+			tr.emojiForStatus(c.From), tr.emojiForStatus(c.To), format.Format(c.Cost)))
+	}
+	return summary, lines
+}
+
+// histogramMenuLines renders the peak hour summary and one bar per hour
+// with recorded spend, scaled to the busiest hour.
+func (tr *Runner) histogramMenuLines(h models.HourlyHistogram) (string, []string) {
+	format := tr.config().CostFormat()
+	peak, peakCost, ok := h.Peak()
+	if !ok {
+		return "⏱ Peak hour: no data yet", nil
+	}
+	summary := fmt.Sprintf("⏱ Peak hour: %02d:00–%02d:00 (%s)", peak, (peak+1)%24, format.Format(peakCost))
+
+	var lines []string
+	for hour, cost := range h.Hours {
+		if cost <= 0 {
+			continue
+		}
+		filled := int(math.Round(cost / peakCost * histogramBarWidth))
+		if filled < 1 {
+			filled = 1
+		}
+		bar := strings.Repeat("█", filled) + strings.Repeat("░", histogramBarWidth-filled)
+		lines = append(lines, fmt.Sprintf("%02d %s %s", hour, bar, format.Format(cost)))
+	}
+	if h.Untracked > 0 {
+		lines = append(lines, fmt.Sprintf("Before tracking: %s", format.Format(h.Untracked)))
+	}
+	return summary, lines
+}
+
+// updateTeam refreshes the team total and per-person submenu from the
+// shared export directory. It reads in the background, since team_dir is
+// often a network share; updates arriving while a read runs are skipped.
+func (tr *Runner) updateTeam() {
+	if tr.teamService == nil || tr.teamItem == nil {
+		return
+	}
+	if !tr.teamBusy.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer tr.teamBusy.Store(false)
+		team, err := tr.teamService.Collect()
+		if err != nil {
+			tr.logger.Warn("Failed to read team exports", map[string]interface{}{
+				"error":    err.Error(),
+				"team_dir": tr.config().TeamDir,
+			})
+			tr.teamItem.SetTitle(tr.label("👥 Team: unavailable"))
+			tr.setTeamItems(nil)
+			return
+		}
+
+		summary, lines := tr.teamMenuLines(team)
+		tr.teamItem.SetTitle(tr.label(summary))
+		tr.setTeamItems(lines)
+	}()
+}
+
+func (tr *Runner) setTeamItems(lines []string) {
+	tr.setSubmenuItems(tr.teamItems, lines)
+}
+
+// teamMenuLines renders the team total and one line per teammate. Lines
+// beyond the submenu size are folded into a trailing "+N more" entry.
+func (tr *Runner) teamMenuLines(team *models.TeamUsage) (string, []string) {
+	format := tr.config().CostFormat()
+	summary := fmt.Sprintf("👥 Team Today: %s (%d)", format.Format(team.DailyCost), len(team.Members))
+
+	lines := make([]string, 0, len(team.Members))
+	for _, m := range team.Members {
+		switch {
+		case m.Error != "":
+			lines = append(lines, fmt.Sprintf("⚠️ %s: unreadable export", m.Name))
+		case !m.HasToday && m.LatestDate != "":
+			lines = append(lines, fmt.Sprintf("%s: no data today (last %s)", m.Name, m.LatestDate))
+		case !m.HasToday:
+			lines = append(lines, fmt.Sprintf("%s: no data", m.Name))
+		default:
+			lines = append(lines, fmt.Sprintf("%s: %s", m.Name, format.Format(m.DailyCost)))
+		}
+	}
+
+	if len(lines) > teamMenuSize {
+		hidden := len(lines) - teamMenuSize + 1
+		lines = append(lines[:teamMenuSize-1], fmt.Sprintf("+%d more", hidden))
+	}
+	return summary, lines
+}
+
+// updateTags shows today's per-tag split, or hides the item when
+// project_tags is empty or the session report hasn't run yet.
+func (tr *Runner) updateTags(state *models.UsageState) {
+	if tr.tagItem == nil {
+		return
+	}
+	if len(state.TagCosts) == 0 {
+		tr.tagItem.Hide()
+		return
+	}
+	summary, lines := tr.tagMenuLines(state.TagCosts)
+	tr.tagItem.SetTitle(tr.label(summary))
+	tr.setSubmenuItems(tr.tagItems, lines)
+	tr.tagItem.Show()
+}
+
+// tagMenuLines renders the most expensive tag as the summary and one line
+// per tag, e.g. "client-A: $3.10 (72%)". Tags beyond the submenu size are
+// folded into a trailing "+N more" entry.
+func (tr *Runner) tagMenuLines(costs []models.TagCost) (string, []string) {
+	format := tr.config().CostFormat()
+	summary := fmt.Sprintf("🏷️ Tags today: %s %s", costs[0].Tag, format.Format(costs[0].Cost))
+
+	total := 0.0
+	for _, tc := range costs {
+		total += tc.Cost
+	}
+	lines := make([]string, 0, len(costs))
+	for _, tc := range costs {
+		lines = append(lines, fmt.Sprintf("%s: %s (%.0f%%)", tc.Tag, format.Format(tc.Cost), tc.Cost/total*100))
+	}
+	if len(lines) > tagMenuSize {
+		hidden := len(lines) - tagMenuSize + 1
+		lines = append(lines[:tagMenuSize-1], fmt.Sprintf("+%d more", hidden))
+	}
+	return summary, lines
+}
+
+// comparisonLines renders today's spend relative to yesterday and the same
+// weekday last week, skipping days ccusage has no entry for.
+func comparisonLines(state *models.UsageState) []string {
+	return state.ComparisonLines()
+}
+
+// modelAlertLines renders one menu line per model threshold reached today.
+func (tr *Runner) modelAlertLines(state *models.UsageState) []string {
+	format := tr.config().CostFormat()
+	lines := make([]string, 0, len(state.ModelAlerts))
+	for _, alert := range state.ModelAlerts {
+		lines = append(lines, fmt.Sprintf("🔶 %s: %s (limit %s)",
+			alert.Pattern, format.Format(alert.Cost), format.Format(alert.Threshold)))
+	}
+	return lines
+}
+
+// sessionAlertLines renders one menu line per session active today whose
+// total cost reached session_cap.
+func (tr *Runner) sessionAlertLines(state *models.UsageState) []string {
+	format := tr.config().CostFormat()
+	lines := make([]string, 0, len(state.SessionAlerts))
+	for _, session := range state.SessionAlerts {
+		lines = append(lines, fmt.Sprintf("🧨 Session %s: %s in total (cap %s)",
+			session.Name, format.Format(session.Cost), format.Format(tr.config().SessionCap)))
+	}
+	return lines
+}
+
+// formatTitle renders the compact menu bar title for an available state.
+func (tr *Runner) formatTitle(state *models.UsageState) string {
+	return models.FormatTitle(state, tr.config())
+}
+
+// titleTooltip describes the menu bar title in words, e.g. "Claude Code
+// spend today: $12.40, status High", adding the token rate while a session
+// is running, e.g. ", ~3.1K tok/min".
+func (tr *Runner) titleTooltip(state *models.UsageState) string {
+	format := tr.config().CostFormat()
+	tooltip := fmt.Sprintf("Claude Code spend today: %s, status %s",
+		format.Format(state.DailyCost), state.Status)
+	if rate, ok := tr.usageService.TokenRate(); ok && !state.Paused {
+		tooltip += ", " + models.FormatTokenRate(rate, format.Locale)
+	}
+	if state.Paused {
+		tooltip += ", polling paused for quiet hours"
+	}
+	return tooltip
+}
+
+// untilRedLine renders how much can still be spent today before status
+// turns red. Returns "" when no alert level is red.
+func (tr *Runner) untilRedLine(state *models.UsageState) string {
+	remaining, ok := state.RemainingToRed(tr.config())
+	if !ok {
+		return ""
+	}
+	if remaining <= 0 {
+		return "🔴 Red threshold reached"
+	}
+	return fmt.Sprintf("⏳ Until red: %s", tr.config().CostFormat().Format(remaining))
+}
+
+// topSessionLine names the most expensive session active today, e.g.
+// "🏆 Top session today: refactor-api ($4.20 in total)". The cost is the
+// session's total, which may include earlier days. Returns "" before the
+// session report has found one.
+func (tr *Runner) topSessionLine(state *models.UsageState) string {
+	if state.TopSession == nil {
+		return ""
+	}
+	return fmt.Sprintf("🏆 Top session today: %s (%s in total)",
+		state.TopSession.Name, tr.config().CostFormat().Format(state.TopSession.Cost))
+}
+
+// weeklyBudgetLine renders the remaining weekly budget, colored by how much
+// is left. Returns "" when no weekly budget is configured.
+func (tr *Runner) weeklyBudgetLine(state *models.UsageState) string {
+	budget := tr.config().WeeklyBudget
+	if budget <= 0 {
+		return ""
+	}
+
+	emoji := tr.emojiForStatus(state.WeeklyBudgetStatus(budget))
+	remaining := state.WeeklyRemaining(budget)
+	format := tr.config().CostFormat()
+	if remaining < 0 {
+		return fmt.Sprintf("%s Over weekly budget by %s", emoji, format.Format(-remaining))
+	}
+	return fmt.Sprintf("%s Left this week: %s", emoji, format.Format(remaining))
+}
+
+// forecastLine renders the projected month-end spend in whole dollars,
+// e.g. "📆 Est. month: $412", compared to monthly_budget when one is set.
+// Returns "" before any spend this month.
+func (tr *Runner) forecastLine(state *models.UsageState) string {
+	if state.Forecast <= 0 {
+		return ""
+	}
+	format := tr.config().CostFormat()
+	format.Precision = 0 // it's an estimate; cents would be false precision
+	line := fmt.Sprintf("📆 Est. month: %s", format.Format(state.Forecast))
+
+	budget := tr.config().MonthlyBudget
+	if budget <= 0 {
+		return line
+	}
+	if state.Forecast > budget {
+		line = "⚠️" + strings.TrimPrefix(line, "📆")
+	}
+	return fmt.Sprintf("%s (%.0f%% of %s budget)", line, state.Forecast/budget*100, format.Format(budget))
 }
 
 func (tr *Runner) updateStatus() {
@@ -144,12 +965,49 @@ func (tr *Runner) updateStatus() {
 		})
 		systray.SetTitle("CC Error")
 		tr.updateMenuItems([]string{"❌ Failed to fetch data"})
+		tr.publishIndicator(dbus.Indicator{Label: "CC Error", Style: "unknown", Lines: []string{"❌ Failed to fetch data"}})
+		if usage != nil {
+			tr.publishStatus(usage)
+		}
 		return
 	}
 
 	tr.updateUIFromState(usage)
 }
 
+// publishStatus writes state to the status file, D-Bus service, and status
+// API, if attached.
+func (tr *Runner) publishStatus(state *models.UsageState) {
+	if tr.statusFile == nil && tr.bus == nil && tr.statusAPI == nil {
+		return
+	}
+	snapshot := models.NewStatusSnapshot(state, tr.config())
+	if tr.bus != nil {
+		tr.bus.Publish(snapshot)
+	}
+	if tr.statusAPI != nil {
+		tr.statusAPI.Publish(snapshot)
+		tr.statusAPI.PublishUsage(state)
+	}
+	if tr.statusFile == nil {
+		return
+	}
+	if err := tr.statusFile.Write(snapshot); err != nil {
+		tr.logger.Warn("Failed to write status file", map[string]interface{}{
+			"error": err.Error(),
+			"path":  tr.statusFile.Path(),
+		})
+	}
+}
+
+// publishIndicator hands what the tray icon shows to the D-Bus service,
+// for panel indicators such as the GNOME Shell extension.
+func (tr *Runner) publishIndicator(ind dbus.Indicator) {
+	if tr.bus != nil {
+		tr.bus.PublishIndicator(ind)
+	}
+}
+
 func (tr *Runner) updateMenuItems(info []string) {
 	for i, item := range tr.menuItems {
 		if i < len(info) {
@@ -157,7 +1015,7 @@ func (tr *Runner) updateMenuItems(info []string) {
 				item.Hide()
 			} else {
 				item.Show()
-				item.SetTitle(info[i])
+				tr.setLine(item, info[i])
 			}
 		} else {
 			item.Hide()
@@ -165,35 +1023,541 @@ func (tr *Runner) updateMenuItems(info []string) {
 	}
 }
 
-func (tr *Runner) showSettings() {
-	// Show settings in the tray title temporarily
-	settingsTitle := fmt.Sprintf("Settings: %ds, $%.1f/$%.1f",
-		tr.config.UpdateInterval, tr.config.YellowThreshold, tr.config.RedThreshold)
-	systray.SetTitle(settingsTitle)
+// quietMenuTitle labels the quiet toggle for the current config.
+func (tr *Runner) quietMenuTitle(now time.Time) string {
+	if tr.config().QuietActive(now) {
+		return "🔔 Resume alerts"
+	}
+	return "🔕 Quiet for a week"
+}
+
+func (tr *Runner) refreshQuietItem() {
+	if tr.quietItem != nil {
+		tr.quietItem.SetTitle(tr.label(tr.quietMenuTitle(time.Now())))
+	}
+}
+
+// toggleQuiet starts a week-long quiet period, or ends the current one.
+func (tr *Runner) toggleQuiet() {
+	now := time.Now()
+	quietUntil := ""
+	if !tr.config().QuietActive(now) {
+		quietUntil = now.AddDate(0, 0, quietPeriodDays-1).Format(models.QuietDateFormat)
+	}
 
-	// Log full settings
-	tr.logger.Info("Current Settings", map[string]interface{}{
-		"ccusage_path":     tr.config.CCUsagePath,
-		"update_interval":  tr.config.UpdateInterval,
-		"yellow_threshold": tr.config.YellowThreshold,
-		"red_threshold":    tr.config.RedThreshold,
-		"debug_level":      tr.config.DebugLevel,
+	if err := tr.setQuietUntil(quietUntil); err != nil {
+		tr.logger.Error("Failed to update quiet mode", map[string]interface{}{
+			"error":       err.Error(),
+			"quiet_until": quietUntil,
+		})
+	}
+	tr.refreshQuietItem()
+	tr.updateStatus()
+}
+
+// setQuietUntil applies quiet_until to the running service and, when a
+// config service is attached, saves it so the period survives restarts.
+func (tr *Runner) setQuietUntil(quietUntil string) error {
+	if err := tr.usageService.SetQuietUntil(quietUntil); err != nil {
+		return err
+	}
+	_, err := tr.updateConfig(func(c *models.Config) {
+		c.QuietUntil = quietUntil
 	})
+	return err
+}
 
-	// Reset title after 3 seconds
-	go func() {
-		time.Sleep(3 * time.Second)
-		// Get current usage to restore proper title
-		usage, err := tr.usageService.GetDailyUsage()
-		if err == nil && usage != nil && usage.IsAvailable {
-			// Recalculate status before reading it to avoid stale emoji
-			usage.UpdateStatus(tr.config.YellowThreshold, tr.config.RedThreshold)
-			emoji := tr.emojiForStatus(usage.Status)
-			systray.SetTitle(fmt.Sprintf("CC %s $%.2f", emoji, usage.DailyCost))
+// updateConfig applies change to the running config and, when a config
+// service is attached, to the stored one. The file is reloaded and changed
+// rather than overwritten with the running config, so CLI flag overrides
+// aren't written back. Both happen inside one LiveConfig.Update, so menu
+// clicks, notification actions, and config reloads run one at a time and
+// none undoes another. A failed save still changes the running config.
+func (tr *Runner) updateConfig(change func(*models.Config)) (*models.Config, error) {
+	var saveErr error
+	updated, err := tr.live.Update(func(c *models.Config) error {
+		change(c)
+		if tr.configService == nil {
+			return nil
+		}
+		stored, err := tr.configService.Load()
+		if err != nil {
+			saveErr = err
+			return nil
+		}
+		change(stored)
+		saveErr = tr.configService.Save(stored)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, saveErr
+}
+
+// handleNotificationAction runs an action clicked on a status alert.
+func (tr *Runner) handleNotificationAction(key string) {
+	tr.logger.Info("Notification action chosen", map[string]interface{}{
+		"action": key,
+	})
+	switch key {
+	case services.ActionOpenReport:
+		tr.openReport()
+	case services.ActionRaiseYellow:
+		tr.applyThresholdAction(raiseYellow)
+	case services.ActionRaiseRed:
+		tr.applyThresholdAction(raiseRed)
+	}
+}
+
+// reportURL is what the Open report action opens: the dashboard when the
+// HTTP API is running, otherwise a freshly written usage heat map, or ""
+// when neither is available.
+func (tr *Runner) reportURL() (string, error) {
+	if tr.statusAPI != nil {
+		return tr.statusAPI.LoginURL()
+	}
+	if tr.historyService == nil {
+		return "", nil
+	}
+	heatMap, err := tr.historyService.HeatMap(services.DefaultHeatMapDays)
+	if err != nil {
+		return "", err
+	}
+	return services.ExportHeatMap(heatMap, services.HeatMapHTML, tr.config().CostFormat())
+}
+
+func (tr *Runner) openReport() {
+	target, err := tr.reportURL()
+	if err == nil && target != "" {
+		err = services.OpenURL(target)
+	}
+	if err != nil {
+		tr.logger.Error("Failed to open report", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// refreshAckItem shows the Acknowledge item while reminders are due.
+func (tr *Runner) refreshAckItem() {
+	if tr.ackItem == nil {
+		return
+	}
+	if tr.notifications.Reminding() {
+		tr.ackItem.Show()
+	} else {
+		tr.ackItem.Hide()
+	}
+}
+
+// soundMenuTitle labels the mute toggle with the current state.
+func (tr *Runner) soundMenuTitle() string {
+	if tr.config().RedSoundMuted {
+		return "🔇 Red alert sound: muted"
+	}
+	return "🔔 Red alert sound: on"
+}
+
+func (tr *Runner) refreshSoundItem() {
+	if tr.soundItem == nil {
+		return
+	}
+	if tr.config().RedSound == "" {
+		tr.soundItem.Hide()
+		return
+	}
+	tr.soundItem.SetTitle(tr.label(tr.soundMenuTitle()))
+	tr.soundItem.Show()
+}
+
+// toggleSoundMuted mutes or unmutes red_sound.
+func (tr *Runner) toggleSoundMuted() {
+	muted := !tr.config().RedSoundMuted
+	if err := tr.setSoundMuted(muted); err != nil {
+		tr.logger.Error("Failed to change red alert sound", map[string]interface{}{
+			"error":           err.Error(),
+			"red_sound_muted": muted,
+		})
+	}
+	tr.refreshSoundItem()
+}
+
+// setSoundMuted applies red_sound_muted and, when a config service is
+// attached, saves it so the choice survives restarts.
+func (tr *Runner) setSoundMuted(muted bool) error {
+	_, err := tr.updateConfig(func(c *models.Config) {
+		c.RedSoundMuted = muted
+	})
+	return err
+}
+
+// formatInterval renders a polling interval as "15s", "1m", or "1m30s".
+func formatInterval(seconds int) string {
+	d := time.Duration(seconds) * time.Second
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", seconds)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", seconds/60)
+	default:
+		return d.String()
+	}
+}
+
+// refreshIntervalItems labels the submenu with the current interval and
+// checks the matching choice, if any.
+func (tr *Runner) refreshIntervalItems() {
+	if tr.intervalItem == nil {
+		return
+	}
+	tr.intervalItem.SetTitle(tr.label("⏲ Update every: " + formatInterval(tr.config().UpdateInterval)))
+	for i, item := range tr.intervalItems {
+		if updateIntervalChoices[i] == tr.config().UpdateInterval {
+			item.Check()
 		} else {
-			systray.SetTitle("CC Loading...")
+			item.Uncheck()
 		}
-	}()
+	}
+}
+
+func (tr *Runner) chooseUpdateInterval(seconds int) {
+	if err := tr.setUpdateInterval(seconds); err != nil {
+		tr.logger.Error("Failed to change update interval", map[string]interface{}{
+			"error":           err.Error(),
+			"update_interval": seconds,
+		})
+	}
+	tr.refreshIntervalItems()
+	tr.refreshSettingsItems()
+}
+
+// setUpdateInterval restarts polling at the new interval and, when a
+// config service is attached, saves it as update_interval.
+func (tr *Runner) setUpdateInterval(seconds int) error {
+	if err := tr.usageService.SetUpdateInterval(seconds); err != nil {
+		return err
+	}
+	_, err := tr.updateConfig(func(c *models.Config) {
+		c.UpdateInterval = seconds
+	})
+	return err
+}
+
+// displayMenuTitle labels the Cycle display item with the current choice.
+func (tr *Runner) displayMenuTitle() string {
+	display := tr.config().TitleDisplay
+	if display == "" {
+		display = models.TitleDisplayCost
+	}
+	return "🔁 Cycle display: " + string(display)
+}
+
+func (tr *Runner) refreshDisplayItem() {
+	if tr.displayItem != nil {
+		tr.displayItem.SetTitle(tr.label(tr.displayMenuTitle()))
+	}
+}
+
+// cycleTitleDisplay steps the title through cost, tokens, percent, and
+// none, and redraws it from the latest usage.
+func (tr *Runner) cycleTitleDisplay() {
+	display := tr.config().TitleDisplay.Next()
+	if err := tr.setTitleDisplay(display); err != nil {
+		tr.logger.Error("Failed to change title display", map[string]interface{}{
+			"error":         err.Error(),
+			"title_display": string(display),
+		})
+	}
+	tr.refreshDisplayItem()
+	tr.refreshSettingsItems()
+	if state, err := tr.usageService.GetDailyUsage(); err == nil {
+		tr.updateUIFromState(state)
+	}
+}
+
+// setTitleDisplay switches the title's figure and, when a config service
+// is attached, saves it as title_display so it survives restarts.
+func (tr *Runner) setTitleDisplay(display models.TitleDisplay) error {
+	_, err := tr.updateConfig(func(c *models.Config) {
+		c.TitleDisplay = display
+	})
+	return err
+}
+
+// checkDataDirs shows the data folder warning when usage data is in more
+// than one default Claude folder. It walks both folders, so it runs in the
+// background rather than holding up the menu.
+func (tr *Runner) checkDataDirs() {
+	overlap := services.DetectDataDirOverlap(tr.config().ClaudeDataDir)
+	if overlap == nil {
+		return
+	}
+	tr.logger.Warn("Claude usage data found in more than one folder", map[string]interface{}{
+		"dirs":         overlap.Dirs,
+		"shared_files": overlap.SharedFiles,
+	})
+	tr.dataDirItem.SetTitle(tr.label(dataDirMenuTitle(overlap)))
+	tr.dataDirItem.SetTooltip(overlap.Warning())
+	for i, item := range tr.dataDirItems {
+		if i >= len(overlap.Dirs) {
+			item.Hide()
+			continue
+		}
+		dir := overlap.Dirs[i]
+		item.SetTitle("Use only " + dir)
+		item.SetTooltip("Set claude_data_dir to " + dir)
+		go func() {
+			for range item.ClickedCh {
+				tr.chooseClaudeDataDir(dir)
+			}
+		}()
+	}
+	tr.dataDirItem.Show()
+}
+
+// dataDirMenuTitle labels the warning shown when usage data exists in
+// more than one default Claude folder.
+func dataDirMenuTitle(overlap *services.DataDirOverlap) string {
+	return fmt.Sprintf("⚠️ Claude data in %d folders", len(overlap.Dirs))
+}
+
+func (tr *Runner) chooseClaudeDataDir(dir string) {
+	if err := tr.setClaudeDataDir(dir); err != nil {
+		tr.logger.Error("Failed to set Claude data directory", map[string]interface{}{
+			"error":           err.Error(),
+			"claude_data_dir": dir,
+		})
+		return
+	}
+	tr.dataDirItem.Hide()
+	tr.updateStatus()
+}
+
+// setClaudeDataDir points ccusage at dir alone and, when a config service
+// is attached, saves it as claude_data_dir.
+func (tr *Runner) setClaudeDataDir(dir string) error {
+	updated, err := tr.updateConfig(func(c *models.Config) {
+		c.ClaudeDataDir = dir
+	})
+	if updated != nil {
+		tr.usageService.ApplyConfig(updated)
+	}
+	return err
+}
+
+// thresholdCandidate returns the config that action would produce, or an
+// error when the result is invalid (e.g. yellow at or above red).
+func (tr *Runner) thresholdCandidate(action thresholdAction) (*models.Config, error) {
+	candidate := tr.config().Clone()
+	candidate.YellowThreshold, candidate.RedThreshold = action.apply(candidate.YellowThreshold, candidate.RedThreshold)
+	if err := candidate.Validate(); err != nil {
+		return nil, err
+	}
+	return candidate, nil
+}
+
+// refreshThresholdItems shows the current pair and disables adjustments
+// that would produce an invalid one. Custom alert levels replace the
+// yellow/red pair entirely, so the submenu is disabled while they're set.
+func (tr *Runner) refreshThresholdItems() {
+	if tr.thresholdItem == nil {
+		return
+	}
+	if len(tr.config().AlertLevels) > 0 {
+		tr.thresholdItem.SetTitle(tr.label("🎚 Thresholds: set by alert_levels"))
+		tr.thresholdItem.Disable()
+		return
+	}
+	format := tr.config().CostFormat()
+	tr.thresholdItem.SetTitle(tr.label(fmt.Sprintf("🎚 Thresholds: %s / %s",
+		format.Format(tr.config().YellowThreshold), format.Format(tr.config().RedThreshold))))
+	tr.thresholdItem.Enable()
+	for i, item := range tr.thresholdItems {
+		if _, err := tr.thresholdCandidate(thresholdActions[i]); err != nil {
+			item.Disable()
+		} else {
+			item.Enable()
+		}
+	}
+}
+
+func (tr *Runner) applyThresholdAction(action thresholdAction) {
+	candidate, err := tr.thresholdCandidate(action)
+	if err == nil {
+		err = tr.setThresholds(candidate.YellowThreshold, candidate.RedThreshold)
+	}
+	if err != nil {
+		tr.logger.Error("Failed to adjust thresholds", map[string]interface{}{
+			"error":  err.Error(),
+			"action": action.label,
+		})
+	}
+	tr.refreshThresholdItems()
+	tr.updateStatus()
+}
+
+// setThresholds applies a new yellow/red pair to the running service and,
+// when a config service is attached, saves it.
+func (tr *Runner) setThresholds(yellow, red float64) error {
+	updated, err := tr.updateConfig(func(c *models.Config) {
+		c.YellowThreshold, c.RedThreshold = yellow, red
+	})
+	if updated != nil {
+		tr.usageService.SetThresholds(yellow, red)
+	}
+	return err
+}
+
+// settingsLines describes the live config for the read-only Current
+// settings submenu, one setting per line; unset optional settings are
+// left out.
+func (tr *Runner) settingsLines() []string {
+	c := tr.config()
+	format := c.CostFormat()
+	lines := []string{
+		"ccusage: " + c.CCUsagePath,
+		"Update every: " + formatInterval(c.UpdateInterval),
+	}
+	if c.TitleMode == models.TitleModeCompact {
+		lines = append(lines, "Title: status only")
+	} else if c.TitleDisplay != "" && c.TitleDisplay != models.TitleDisplayCost {
+		lines = append(lines, "Title shows: "+string(c.TitleDisplay))
+	}
+	if locale := c.DisplayLocale(); locale != nil {
+		lines = append(lines, "Locale: "+locale.String())
+	}
+	if len(c.AlertLevels) > 0 {
+		levels := make([]string, 0, len(c.AlertLevels))
+		for _, level := range c.AlertLevels {
+			levels = append(levels, fmt.Sprintf("%s %s", level.Name, format.Format(level.Threshold)))
+		}
+		lines = append(lines, "Alert levels: "+strings.Join(levels, ", "))
+	} else {
+		lines = append(lines, fmt.Sprintf("Thresholds: %s / %s",
+			format.Format(c.YellowThreshold), format.Format(c.RedThreshold)))
+	}
+	if len(c.DayThresholds) > 0 {
+		days := make([]string, 0, len(c.DayThresholds))
+		for day := range c.DayThresholds {
+			days = append(days, day)
+		}
+		sort.Strings(days)
+		lines = append(lines, "Day overrides: "+strings.Join(days, ", "))
+	}
+	if len(c.ModelThresholds) > 0 {
+		patterns := make([]string, 0, len(c.ModelThresholds))
+		for pattern := range c.ModelThresholds {
+			patterns = append(patterns, pattern)
+		}
+		sort.Strings(patterns)
+		limits := make([]string, 0, len(patterns))
+		for _, pattern := range patterns {
+			limits = append(limits, fmt.Sprintf("%s %s", pattern, format.Format(c.ModelThresholds[pattern])))
+		}
+		lines = append(lines, "Model limits: "+strings.Join(limits, ", "))
+	}
+	if c.WeeklyBudget > 0 {
+		lines = append(lines, "Weekly budget: "+format.Format(c.WeeklyBudget))
+	}
+	if c.QuietUntil != "" {
+		lines = append(lines, "Quiet until: "+c.QuietUntil)
+	}
+	if c.QuietHours != "" {
+		lines = append(lines, "Quiet hours: "+c.QuietHoursWindow().String())
+	}
+	lines = append(lines,
+		"Cache window: "+formatInterval(c.CacheWindow),
+		"Command timeout: "+formatInterval(c.CmdTimeout),
+	)
+	if c.WatchDataDirs {
+		lines = append(lines, "Watching Claude data folders")
+	}
+	if c.ClaudeDataDir != "" {
+		lines = append(lines, "Claude data: "+c.ClaudeDataDir)
+	}
+	if c.TeamDir != "" {
+		lines = append(lines, "Team folder: "+c.TeamDir)
+	}
+	lines = append(lines, "Log level: "+strings.ToUpper(c.DebugLevel))
+	if tr.configService != nil {
+		lines = append(lines, "Config file: "+tr.configService.GetConfigPath())
+	}
+	return lines
+}
+
+// refreshSettingsItems re-renders the Current settings submenu from the
+// live config.
+func (tr *Runner) refreshSettingsItems() {
+	if tr.settingsItem != nil {
+		tr.setSubmenuItems(tr.settingsItems, tr.settingsLines())
+	}
+}
+
+// label adapts a menu title for the screen_reader setting, spelling out
+// status dots and dropping other emoji.
+func (tr *Runner) label(title string) string {
+	if tr.config().ScreenReader {
+		return models.PlainText(title)
+	}
+	return title
+}
+
+// setLine shows an information line, with its plain-text reading as the
+// tooltip so assistive tools get words rather than emoji.
+func (tr *Runner) setLine(item *systray.MenuItem, line string) {
+	item.SetTitle(tr.label(line))
+	item.SetTooltip(models.PlainText(line))
+}
+
+// diagnosticsTitle flags the Diagnostics item when the resource monitor
+// suspects a leak.
+func (tr *Runner) diagnosticsTitle() string {
+	if len(tr.resources.Warnings()) > 0 {
+		return "⚠️ Diagnostics: possible leak"
+	}
+	if percent, polls := tr.usageService.PollReliability(); services.PollReliabilityLow(percent, polls, tr.config().PollReliabilityWarning) {
+		return "⚠️ Diagnostics: polls failing"
+	}
+	return "🩺 Diagnostics"
+}
+
+// diagnosticsLines lists the Diagnostics submenu: the poller's state,
+// success rate, and stall recoveries, updates the menu fell too far behind
+// to show, the latest resource sample, and any leak warnings.
+func (tr *Runner) diagnosticsLines() []string {
+	lines := []string{"Polling: " + tr.usageService.PollingStatus().String()}
+	if percent, polls := tr.usageService.PollReliability(); polls > 0 {
+		lines = append(lines, fmt.Sprintf("Poll reliability: %d%% (%d polls, 24h)", percent, polls))
+	}
+	if count, last := tr.usageService.StallRecoveries(); count > 0 {
+		line := "Recovered from stall at " + tr.config().DisplayLocale().FormatTime(last)
+		if count > 1 {
+			line += fmt.Sprintf(" (%d times)", count)
+		}
+		lines = append(lines, line)
+	}
+	if dropped := tr.usageService.DroppedUIUpdates(); dropped > 0 {
+		lines = append(lines, fmt.Sprintf("Skipped menu updates: %d (menu was busy)", dropped))
+	}
+	if sample, ok := tr.resources.Latest(); ok {
+		lines = append(lines,
+			fmt.Sprintf("Goroutines: %d", sample.Goroutines),
+			"Heap: "+services.FormatBytes(sample.HeapBytes),
+		)
+	}
+	for _, warning := range tr.resources.Warnings() {
+		lines = append(lines, "⚠️ "+warning)
+	}
+	return lines
+}
+
+// refreshDiagnosticsItems re-renders the Diagnostics item and submenu.
+func (tr *Runner) refreshDiagnosticsItems() {
+	if tr.diagnosticsItem != nil {
+		tr.diagnosticsItem.SetTitle(tr.label(tr.diagnosticsTitle()))
+		tr.setSubmenuItems(tr.diagnosticsItems, tr.diagnosticsLines())
+	}
 }
 
 func (tr *Runner) onExit() {
@@ -206,4 +1570,13 @@ func (tr *Runner) onExit() {
 	if tr.usageService != nil {
 		tr.usageService.StopPolling()
 	}
+	tr.resources.Stop()
+
+	if tr.statusFile != nil {
+		if err := tr.statusFile.Remove(); err != nil {
+			tr.logger.Warn("Failed to remove status file", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
 }
@@ -2,7 +2,10 @@ package tray
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -19,7 +22,15 @@ func TestMain(m *testing.M) {
 func newTestRunner() *Runner {
 	config := models.ConfigDefaults()
 	usageService := services.NewUsageService(config)
-	return NewRunner(config, usageService)
+	return NewRunner(models.NewLiveConfig(config), usageService)
+}
+
+// configure changes runner's config without validation, as a CLI flag
+// override or an earlier setting would have.
+func configure(runner *Runner, change func(*models.Config)) {
+	config := runner.config().Clone()
+	change(config)
+	runner.live.Set(config)
 }
 
 func TestEmojiForStatus(t *testing.T) {
@@ -47,11 +58,513 @@ func TestNewRunner_Fields(t *testing.T) {
 	config := models.ConfigDefaults()
 	usageService := services.NewUsageService(config)
 
-	runner := NewRunner(config, usageService)
+	runner := NewRunner(models.NewLiveConfig(config), usageService)
 
 	require.NotNil(t, runner)
-	assert.Equal(t, config, runner.config)
+	assert.Same(t, config, runner.config())
 	assert.Equal(t, usageService, runner.usageService)
 	assert.NotNil(t, runner.menuItems)
 	assert.NotNil(t, runner.logger)
 }
+
+func TestFormatTitle_UsesTitleCostFormat(t *testing.T) {
+	runner := newTestRunner()
+	state := &models.UsageState{DailyCost: 12.345, Status: models.Yellow, IsAvailable: true}
+
+	assert.Equal(t, "CC 🟡 $12.35", runner.formatTitle(state))
+
+	whole := 0
+	configure(runner, func(c *models.Config) {
+		c.TitleCostPrecision = &whole
+		c.CostRounding = models.RoundUp
+	})
+	assert.Equal(t, "CC 🟡 $13", runner.formatTitle(state))
+	assert.Equal(t, "$12.35", runner.config().CostFormat().Format(state.DailyCost))
+}
+
+func TestWeeklyBudgetLine(t *testing.T) {
+	runner := newTestRunner()
+	state := &models.UsageState{WeeklyCost: 11.80, IsAvailable: true}
+
+	assert.Empty(t, runner.weeklyBudgetLine(state), "disabled without a budget")
+
+	configure(runner, func(c *models.Config) { c.WeeklyBudget = 50 })
+	assert.Equal(t, "🟢 Left this week: $38.20", runner.weeklyBudgetLine(state))
+
+	state.WeeklyCost = 40
+	assert.Equal(t, "🟡 Left this week: $10.00", runner.weeklyBudgetLine(state))
+
+	state.WeeklyCost = 52.5
+	assert.Equal(t, "🔴 Over weekly budget by $2.50", runner.weeklyBudgetLine(state))
+}
+
+func TestUntilRedLine(t *testing.T) {
+	runner := newTestRunner() // red at $20
+
+	assert.Equal(t, "⏳ Until red: $7.60", runner.untilRedLine(&models.UsageState{DailyCost: 12.4}))
+	assert.Equal(t, "🔴 Red threshold reached", runner.untilRedLine(&models.UsageState{DailyCost: 20}))
+
+	configure(runner, func(c *models.Config) {
+		c.AlertLevels = []models.AlertLevel{{Name: "busy", Threshold: 5, Status: models.Yellow}}
+	})
+	assert.Empty(t, runner.untilRedLine(&models.UsageState{DailyCost: 1}), "no red level configured")
+}
+
+func TestTopSessionLine(t *testing.T) {
+	runner := newTestRunner()
+
+	assert.Empty(t, runner.topSessionLine(&models.UsageState{}))
+	state := &models.UsageState{TopSession: &models.SessionCost{Name: "refactor-api", Cost: 4.2}}
+	assert.Equal(t, "🏆 Top session today: refactor-api ($4.20 in total)", runner.topSessionLine(state))
+}
+
+func TestForecastLine(t *testing.T) {
+	runner := newTestRunner()
+
+	assert.Empty(t, runner.forecastLine(&models.UsageState{}), "no spend this month")
+	state := &models.UsageState{Forecast: 411.6}
+	assert.Equal(t, "📆 Est. month: $412", runner.forecastLine(state))
+
+	configure(runner, func(c *models.Config) { c.MonthlyBudget = 500 })
+	assert.Equal(t, "📆 Est. month: $412 (82% of $500 budget)", runner.forecastLine(state))
+	state.Forecast = 612
+	assert.Equal(t, "⚠️ Est. month: $612 (122% of $500 budget)", runner.forecastLine(state))
+}
+
+func TestFormatTitle_UsesAlertLevelSymbol(t *testing.T) {
+	runner := newTestRunner()
+	state := &models.UsageState{DailyCost: 16, Status: models.Yellow, LevelSymbol: "🟠", IsAvailable: true}
+	assert.Equal(t, "CC 🟠 $16.00", runner.formatTitle(state))
+
+	state.LevelSymbol = ""
+	assert.Equal(t, "CC 🟡 $16.00", runner.formatTitle(state))
+}
+
+func TestQuietMenuTitle(t *testing.T) {
+	runner := newTestRunner()
+	now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)
+
+	assert.Equal(t, "🔕 Quiet for a week", runner.quietMenuTitle(now))
+
+	configure(runner, func(c *models.Config) { c.QuietUntil = "2025-03-20" })
+	assert.Equal(t, "🔔 Resume alerts", runner.quietMenuTitle(now))
+}
+
+func TestSetQuietUntil_PersistsToConfigFile(t *testing.T) {
+	runner := newTestRunner()
+	configure(runner, func(c *models.Config) { c.UpdateInterval = 60 }) // stands in for a CLI flag override
+
+	configService := services.NewConfigService()
+	configService.SetConfigPath(filepath.Join(t.TempDir(), "config.yaml"))
+	runner.SetConfigService(configService)
+
+	require.NoError(t, runner.setQuietUntil("2025-03-20"))
+	assert.Equal(t, "2025-03-20", runner.config().QuietUntil)
+
+	stored, err := configService.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "2025-03-20", stored.QuietUntil)
+	assert.Equal(t, 30, stored.UpdateInterval, "flag overrides stay out of the file")
+
+	assert.Error(t, runner.setQuietUntil("soon"))
+	assert.Equal(t, "2025-03-20", runner.config().QuietUntil)
+}
+
+func TestFormatInterval(t *testing.T) {
+	assert.Equal(t, "15s", formatInterval(15))
+	assert.Equal(t, "1m", formatInterval(60))
+	assert.Equal(t, "5m", formatInterval(300))
+	assert.Equal(t, "1m30s", formatInterval(90))
+}
+
+func TestSetUpdateInterval_PersistsToConfigFile(t *testing.T) {
+	runner := newTestRunner()
+	configure(runner, func(c *models.Config) { c.YellowThreshold = 12 }) // stands in for a CLI flag override
+
+	configService := services.NewConfigService()
+	configService.SetConfigPath(filepath.Join(t.TempDir(), "config.yaml"))
+	runner.SetConfigService(configService)
+
+	require.NoError(t, runner.setUpdateInterval(300))
+	assert.Equal(t, 300, runner.config().UpdateInterval)
+
+	stored, err := configService.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 300, stored.UpdateInterval)
+	assert.Equal(t, 10.0, stored.YellowThreshold, "flag overrides stay out of the file")
+
+	assert.Error(t, runner.setUpdateInterval(5), "below the configurable minimum")
+	assert.Equal(t, 300, runner.config().UpdateInterval)
+}
+
+func TestSetTitleDisplay_PersistsToConfigFile(t *testing.T) {
+	runner := newTestRunner()
+	configure(runner, func(c *models.Config) { c.YellowThreshold = 12 }) // stands in for a CLI flag override
+	assert.Equal(t, "🔁 Cycle display: cost", runner.displayMenuTitle())
+
+	configService := services.NewConfigService()
+	configService.SetConfigPath(filepath.Join(t.TempDir(), "config.yaml"))
+	runner.SetConfigService(configService)
+
+	require.NoError(t, runner.setTitleDisplay(models.TitleDisplayTokens))
+	assert.Equal(t, "🔁 Cycle display: tokens", runner.displayMenuTitle())
+	assert.Contains(t, runner.settingsLines(), "Title shows: tokens")
+
+	stored, err := configService.Load()
+	require.NoError(t, err)
+	assert.Equal(t, models.TitleDisplayTokens, stored.TitleDisplay)
+	assert.Equal(t, 10.0, stored.YellowThreshold, "flag overrides stay out of the file")
+}
+
+func TestSetSoundMuted_PersistsToConfigFile(t *testing.T) {
+	runner := newTestRunner()
+	configure(runner, func(c *models.Config) { c.RedSound = models.RedSoundSystem }) // stands in for a CLI flag override
+	assert.Equal(t, "🔔 Red alert sound: on", runner.soundMenuTitle())
+
+	configService := services.NewConfigService()
+	configService.SetConfigPath(filepath.Join(t.TempDir(), "config.yaml"))
+	runner.SetConfigService(configService)
+
+	require.NoError(t, runner.setSoundMuted(true))
+	assert.Equal(t, "🔇 Red alert sound: muted", runner.soundMenuTitle())
+
+	stored, err := configService.Load()
+	require.NoError(t, err)
+	assert.True(t, stored.RedSoundMuted)
+	assert.Empty(t, stored.RedSound, "flag overrides stay out of the file")
+}
+
+func TestSetClaudeDataDir_PersistsToConfigFile(t *testing.T) {
+	runner := newTestRunner()
+	configure(runner, func(c *models.Config) { c.YellowThreshold = 12 }) // stands in for a CLI flag override
+
+	configService := services.NewConfigService()
+	configService.SetConfigPath(filepath.Join(t.TempDir(), "config.yaml"))
+	runner.SetConfigService(configService)
+
+	dir := filepath.Join(t.TempDir(), ".claude")
+	require.NoError(t, runner.setClaudeDataDir(dir))
+	assert.Equal(t, dir, runner.config().ClaudeDataDir)
+
+	stored, err := configService.Load()
+	require.NoError(t, err)
+	assert.Equal(t, dir, stored.ClaudeDataDir)
+	assert.Equal(t, 10.0, stored.YellowThreshold, "flag overrides stay out of the file")
+}
+
+func TestDataDirMenuTitle(t *testing.T) {
+	overlap := &services.DataDirOverlap{Dirs: []string{"/home/me/.config/claude", "/home/me/.claude"}}
+	assert.Equal(t, "⚠️ Claude data in 2 folders", dataDirMenuTitle(overlap))
+}
+
+func TestSettingsLines(t *testing.T) {
+	runner := newTestRunner()
+	assert.Equal(t, []string{
+		"ccusage: ccusage",
+		"Update every: 30s",
+		"Thresholds: $10.00 / $20.00",
+		"Cache window: 10s",
+		"Command timeout: 30s",
+		"Log level: INFO",
+	}, runner.settingsLines())
+
+	configure(runner, func(c *models.Config) { // changes show up without a restart
+		c.UpdateInterval = 60
+		c.AlertLevels = []models.AlertLevel{{Name: "busy", Threshold: 5, Status: models.Yellow}}
+		c.ModelThresholds = map[string]float64{"opus": 10, "haiku": 1}
+		c.QuietHours = "23:00-07:00"
+		c.Locale = "de_DE.UTF-8"
+	})
+	configService := services.NewConfigService()
+	configService.SetConfigPath("/tmp/config.yaml")
+	runner.SetConfigService(configService)
+
+	lines := runner.settingsLines()
+	assert.Contains(t, lines, "Update every: 1m")
+	assert.Contains(t, lines, "Alert levels: busy 5,00\u00a0$")
+	assert.Contains(t, lines, "Model limits: haiku 1,00\u00a0$, opus 10,00\u00a0$")
+	assert.Contains(t, lines, "Locale: de-DE")
+	assert.Contains(t, lines, "Quiet hours: 23:00–07:00")
+	assert.Equal(t, "Config file: /tmp/config.yaml", lines[len(lines)-1])
+	assert.LessOrEqual(t, len(lines), settingsMenuSize)
+}
+
+func TestDiagnosticsLines(t *testing.T) {
+	runner := newTestRunner()
+	assert.Equal(t, []string{"Polling: stopped"}, runner.diagnosticsLines())
+	assert.Equal(t, "🩺 Diagnostics", runner.diagnosticsTitle())
+
+	runner.resources.Start()
+	defer runner.resources.Stop()
+	lines := runner.diagnosticsLines()
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[1], "Goroutines: "))
+	assert.True(t, strings.HasPrefix(lines[2], "Heap: "))
+	assert.LessOrEqual(t, len(lines)+5, diagnosticsMenuSize, "room for reliability, stall and skipped update lines, and both leak warnings")
+}
+
+func TestDiagnostics_PollReliability(t *testing.T) {
+	config := models.ConfigDefaults()
+	config.CCUsagePath = filepath.Join(t.TempDir(), "missing")
+	config.PollReliabilityWarning = 90
+	runner := NewRunner(models.NewLiveConfig(config), services.NewUsageService(config))
+	for i := 0; i < 10; i++ {
+		_, _ = runner.usageService.Refresh()
+	}
+
+	assert.Contains(t, runner.diagnosticsLines(), "Poll reliability: 0% (10 polls, 24h)")
+	assert.Equal(t, "⚠️ Diagnostics: polls failing", runner.diagnosticsTitle())
+}
+
+func TestLabel(t *testing.T) {
+	runner := newTestRunner()
+	assert.Equal(t, "⚙️ Current settings", runner.label("⚙️ Current settings"))
+
+	configure(runner, func(c *models.Config) { c.ScreenReader = true })
+	assert.Equal(t, "Current settings", runner.label("⚙️ Current settings"))
+	assert.Equal(t, "11:05 OK → High at $10.20", runner.label("11:05 🟢 → 🟡 at $10.20"))
+}
+
+func TestTitleTooltip(t *testing.T) {
+	runner := newTestRunner()
+	state := &models.UsageState{DailyCost: 12.4, Status: models.Yellow, IsAvailable: true}
+	assert.Equal(t, "Claude Code spend today: $12.40, status High", runner.titleTooltip(state))
+
+	state.Paused = true
+	assert.Contains(t, runner.titleTooltip(state), "polling paused")
+}
+
+func TestThresholdCandidate(t *testing.T) {
+	runner := newTestRunner() // $10 / $20
+
+	tests := []struct {
+		label      string
+		wantYellow float64
+		wantRed    float64
+	}{
+		{"Yellow +$5", 15, 20},
+		{"Yellow −$5", 5, 20},
+		{"Red +$5", 10, 25},
+		{"Red −$5", 10, 15},
+		{"Heavy day: $25 / $50", 25, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			action := findThresholdAction(t, tt.label)
+			candidate, err := runner.thresholdCandidate(action)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantYellow, candidate.YellowThreshold)
+			assert.Equal(t, tt.wantRed, candidate.RedThreshold)
+		})
+	}
+
+	configure(runner, func(c *models.Config) { c.YellowThreshold = 15 })
+	_, err := runner.thresholdCandidate(findThresholdAction(t, "Red −$5"))
+	assert.Error(t, err, "red may not drop to yellow")
+	configure(runner, func(c *models.Config) { c.YellowThreshold = 2 })
+	_, err = runner.thresholdCandidate(findThresholdAction(t, "Yellow −$5"))
+	assert.Error(t, err, "thresholds may not go negative")
+}
+
+func findThresholdAction(t *testing.T, label string) thresholdAction {
+	t.Helper()
+	for _, action := range thresholdActions {
+		if action.label == label {
+			return action
+		}
+	}
+	t.Fatalf("no threshold action %q", label)
+	return thresholdAction{}
+}
+
+func TestSetThresholds_PersistsToConfigFile(t *testing.T) {
+	runner := newTestRunner()
+	configure(runner, func(c *models.Config) { c.UpdateInterval = 60 }) // stands in for a CLI flag override
+
+	configService := services.NewConfigService()
+	configService.SetConfigPath(filepath.Join(t.TempDir(), "config.yaml"))
+	runner.SetConfigService(configService)
+
+	require.NoError(t, runner.setThresholds(15, 30))
+	assert.Equal(t, 15.0, runner.config().YellowThreshold)
+	assert.Equal(t, 30.0, runner.config().RedThreshold)
+
+	stored, err := configService.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 15.0, stored.YellowThreshold)
+	assert.Equal(t, 30.0, stored.RedThreshold)
+	assert.Equal(t, 30, stored.UpdateInterval, "flag overrides stay out of the file")
+
+	assert.Error(t, runner.setThresholds(30, 30))
+	assert.Equal(t, 15.0, runner.config().YellowThreshold)
+}
+
+func TestTeamMenuLines(t *testing.T) {
+	runner := newTestRunner()
+	team := &models.TeamUsage{}
+	team.AddMember(models.TeamMember{Name: "alice", DailyCost: 4.25, HasToday: true})
+	team.AddMember(models.TeamMember{Name: "bob", LatestDate: "2025-03-12"})
+	team.AddMember(models.TeamMember{Name: "carol"})
+	team.AddMember(models.TeamMember{Name: "dave", DailyCost: 99, Error: "bad json"})
+
+	summary, lines := runner.teamMenuLines(team)
+	assert.Equal(t, "👥 Team Today: $4.25 (4)", summary)
+	assert.Equal(t, []string{
+		"alice: $4.25",
+		"bob: no data today (last 2025-03-12)",
+		"carol: no data",
+		"⚠️ dave: unreadable export",
+	}, lines)
+
+	big := &models.TeamUsage{}
+	for i := 0; i < teamMenuSize+5; i++ {
+		big.AddMember(models.TeamMember{Name: "m", HasToday: true})
+	}
+	_, lines = runner.teamMenuLines(big)
+	require.Len(t, lines, teamMenuSize)
+	assert.Equal(t, "+6 more", lines[teamMenuSize-1])
+}
+
+func TestTagMenuLines(t *testing.T) {
+	runner := newTestRunner()
+	summary, lines := runner.tagMenuLines([]models.TagCost{
+		{Tag: "client-A", Cost: 3},
+		{Tag: "oss", Cost: 0.75},
+		{Tag: models.UntaggedTag, Cost: 0.25},
+	})
+	assert.Equal(t, "🏷️ Tags today: client-A $3.00", summary)
+	assert.Equal(t, []string{"client-A: $3.00 (75%)", "oss: $0.75 (19%)", "untagged: $0.25 (6%)"}, lines)
+
+	many := make([]models.TagCost, tagMenuSize+2)
+	for i := range many {
+		many[i] = models.TagCost{Tag: "t", Cost: 1}
+	}
+	_, lines = runner.tagMenuLines(many)
+	require.Len(t, lines, tagMenuSize)
+	assert.Equal(t, "+3 more", lines[tagMenuSize-1])
+}
+
+func TestModelAlertLines(t *testing.T) {
+	runner := newTestRunner()
+	state := &models.UsageState{ModelAlerts: []models.ModelAlert{{Pattern: "opus", Cost: 12.5, Threshold: 10}}}
+	assert.Equal(t, []string{"🔶 opus: $12.50 (limit $10.00)"}, runner.modelAlertLines(state))
+	assert.Empty(t, runner.modelAlertLines(&models.UsageState{}))
+}
+
+func TestSessionAlertLines(t *testing.T) {
+	runner := newTestRunner()
+	configure(runner, func(c *models.Config) { c.SessionCap = 5 })
+	state := &models.UsageState{SessionAlerts: []models.SessionCost{{Name: "refactor-api", Cost: 6.2}}}
+	assert.Equal(t, []string{"🧨 Session refactor-api: $6.20 in total (cap $5.00)"}, runner.sessionAlertLines(state))
+	assert.Empty(t, runner.sessionAlertLines(&models.UsageState{}))
+}
+
+type recordingNotifier struct{ messages []string }
+
+func (r *recordingNotifier) Notify(title, message string) error {
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+type fixedClock struct{ now time.Time }
+
+func (c fixedClock) Now() time.Time { return c.now }
+
+func TestRefreshStreak(t *testing.T) {
+	runner := newTestRunner()
+	notifier := &recordingNotifier{}
+	runner.notifications.SetNotifier(notifier)
+	history := services.NewHistoryServiceAt(filepath.Join(t.TempDir(), "history.jsonl"))
+	runner.SetHistoryService(history)
+
+	for day := 11; day <= 13; day++ {
+		history.SetClock(fixedClock{now: time.Date(2025, 3, day, 17, 0, 0, 0, time.Local)})
+		require.NoError(t, history.Record(&models.UsageState{IsAvailable: true, DailyCost: 5, Status: models.Green}))
+	}
+
+	state := &models.UsageState{}
+	runner.refreshStreak(state, time.Date(2025, 3, 13, 18, 0, 0, 0, time.Local))
+	runner.notifications.Wait()
+	assert.Equal(t, 2, runner.streak)
+	assert.Empty(t, notifier.messages, "starting up isn't a new day")
+
+	midnight := time.Date(2025, 3, 14, 0, 1, 0, 0, time.Local)
+	history.SetClock(fixedClock{now: midnight})
+	runner.refreshStreak(state, midnight)
+	runner.notifications.Wait()
+	assert.Equal(t, 3, runner.streak)
+	assert.Equal(t, []string{"🔥 3-day streak under budget"}, notifier.messages)
+
+	next := midnight.AddDate(0, 0, 1)
+	history.SetClock(fixedClock{now: next})
+	runner.refreshStreak(&models.UsageState{Paused: true}, next)
+	runner.notifications.Wait()
+	assert.Equal(t, "2025-03-15", runner.streakDay)
+	assert.Len(t, notifier.messages, 1, "nothing is announced during quiet hours")
+}
+
+func TestHistogramMenuLines(t *testing.T) {
+	runner := newTestRunner()
+	summary, lines := runner.histogramMenuLines(models.HourlyHistogram{})
+	assert.Equal(t, "⏱ Peak hour: no data yet", summary)
+	assert.Empty(t, lines)
+
+	var h models.HourlyHistogram
+	h.Hours[9] = 1.2
+	h.Hours[14] = 3.2
+	h.Hours[23] = 0.01
+	h.Untracked = 0.5
+	summary, lines = runner.histogramMenuLines(h)
+	assert.Equal(t, "⏱ Peak hour: 14:00–15:00 ($3.20)", summary)
+	assert.Equal(t, []string{
+		"09 ████░░░░░░ $1.20",
+		"14 ██████████ $3.20",
+		"23 █░░░░░░░░░ $0.01",
+		"Before tracking: $0.50",
+	}, lines)
+}
+
+func TestStatusChangeMenuLines(t *testing.T) {
+	runner := newTestRunner()
+	at := func(h, m int) time.Time { return time.Date(2025, 3, 14, h, m, 0, 0, time.Local) }
+
+	summary, lines := runner.statusChangeMenuLines(nil)
+	assert.Equal(t, "🚦 Status changes today: none", summary)
+	assert.Empty(t, lines)
+
+	summary, lines = runner.statusChangeMenuLines([]models.StatusChange{
+		{Time: at(11, 5), From: models.Green, To: models.Yellow, Cost: 10.2},
+		{Time: at(15, 40), From: models.Yellow, To: models.Red, Cost: 20.5},
+	})
+	assert.Equal(t, "🚦 Status changes today: 2", summary)
+	assert.Equal(t, []string{
+		"11:05 🟢 → 🟡 at $10.20",
+		"15:40 🟡 → 🔴 at $20.50",
+	}, lines)
+
+	many := make([]models.StatusChange, statusChangeMenuSize+3)
+	for i := range many {
+		many[i] = models.StatusChange{Time: at(9, i), From: models.Green, To: models.Yellow}
+	}
+	_, lines = runner.statusChangeMenuLines(many)
+	require.Len(t, lines, statusChangeMenuSize)
+	assert.Equal(t, "+4 earlier", lines[0])
+	assert.True(t, strings.HasPrefix(lines[len(lines)-1], "09:17 "))
+}
+
+func TestComparisonLines(t *testing.T) {
+	state := &models.UsageState{
+		DailyCost: 13.2,
+		Yesterday: &models.CostComparison{Date: "2025-03-13", Cost: 10},
+		LastWeek:  &models.CostComparison{Date: "2025-03-07", Cost: 0},
+	}
+	assert.Equal(t, []string{
+		"vs yesterday: ▲ +32%",
+		"vs same day last week: no spend then",
+	}, comparisonLines(state))
+
+	state.DailyCost = 9
+	state.LastWeek = nil
+	assert.Equal(t, []string{"vs yesterday: ▼ −10%"}, comparisonLines(state))
+	assert.Empty(t, comparisonLines(&models.UsageState{}))
+}
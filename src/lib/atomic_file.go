@@ -0,0 +1,30 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic replaces path via a temp file in the same directory, so
+// readers polling the file (calendar apps, widgets) never see a partial
+// write. The result is world-readable (0644).
+func WriteFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
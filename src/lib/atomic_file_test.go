@@ -0,0 +1,28 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.json")
+
+	require.NoError(t, WriteFileAtomic(path, []byte("one")))
+	require.NoError(t, WriteFileAtomic(path, []byte("two")))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "two", string(data))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temp files left behind")
+
+	assert.Error(t, WriteFileAtomic(filepath.Join(dir, "missing", "out.json"), nil))
+}
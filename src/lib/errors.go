@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"runtime"
+	"runtime/debug"
 	"strings"
 )
 
@@ -112,6 +113,7 @@ const (
 	ErrCodeValidation = "VALIDATION_ERROR"
 	ErrCodeSystem     = "SYSTEM_ERROR"
 	ErrCodeTemplate   = "TEMPLATE_ERROR"
+	ErrCodePanic      = "PANIC"
 )
 
 // Convenience functions for common error types
@@ -151,6 +153,18 @@ func TemplateError(message string) *AppError {
 	return NewError(ErrCodeTemplate, message)
 }
 
+// PanicError turns a value returned by recover() into an error for a
+// structured panic report. It must be called from the deferred function
+// that recovered, so the context's stack still shows where the panic
+// happened.
+func PanicError(recovered interface{}) *AppError {
+	err := NewError(ErrCodePanic, fmt.Sprintf("panic: %v", recovered))
+	if cause, ok := recovered.(error); ok {
+		err.Cause = cause
+	}
+	return err.WithContext("stack", string(debug.Stack()))
+}
+
 // IsErrorCode checks if an error has a specific error code
 func IsErrorCode(err error, code string) bool {
 	var appErr *AppError
@@ -289,3 +289,19 @@ func TestErrorConstants(t *testing.T) {
 
 	assert.Len(t, seen, len(expectedCodes), "Should have expected number of error codes")
 }
+
+func TestPanicError(t *testing.T) {
+	var err *AppError
+	func() {
+		defer func() { err = PanicError(recover()) }()
+		panic("boom")
+	}()
+
+	require.NotNil(t, err)
+	assert.Equal(t, ErrCodePanic, err.Code)
+	assert.Equal(t, "[PANIC] panic: boom", err.Error())
+	assert.Contains(t, err.Context["stack"], "TestPanicError")
+
+	cause := errors.New("index out of range")
+	assert.ErrorIs(t, PanicError(cause), cause)
+}
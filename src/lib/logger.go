@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -45,6 +46,15 @@ type Logger struct {
 	component string
 	level     LogLevel
 	writer    io.Writer
+	inherit   bool // follow the global level until SetLevel is called
+}
+
+// globalLevel is the level inheriting loggers filter at. It is atomic so
+// SetGlobalLevel can change it (e.g. on config reload) while loggers run.
+var globalLevel atomic.Int32
+
+func init() {
+	globalLevel.Store(int32(INFO))
 }
 
 func (l *Logger) ensureWriter() {
@@ -68,6 +78,7 @@ func NewLogger(component string) *Logger {
 		component: component,
 		level:     INFO,
 		writer:    getDefaultWriter(),
+		inherit:   true,
 	}
 }
 
@@ -91,9 +102,18 @@ func setDefaultWriter(writer io.Writer) {
 	defaultWriter = writer
 }
 
-// SetLevel sets the minimum log level
+// SetLevel sets the minimum log level, detaching this logger from the
+// global level
 func (l *Logger) SetLevel(level LogLevel) {
 	l.level = level
+	l.inherit = false
+}
+
+func (l *Logger) effectiveLevel() LogLevel {
+	if l.inherit {
+		return LogLevel(globalLevel.Load())
+	}
+	return l.level
 }
 
 // SetOutput sets the destination writer for this logger instance
@@ -132,7 +152,7 @@ func (l *Logger) Fatal(message string, context ...map[string]interface{}) {
 
 // log performs the actual logging with structured JSON output
 func (l *Logger) log(level LogLevel, message string, context ...map[string]interface{}) {
-	if level < l.level {
+	if level < l.effectiveLevel() {
 		return
 	}
 
@@ -178,8 +198,10 @@ func (l *Logger) WithContext(context map[string]interface{}) func(LogLevel, stri
 // Global logger instance for convenience
 var globalLogger = NewLogger("cc-dailyuse-bar")
 
-// SetGlobalLevel sets the global logger level
+// SetGlobalLevel sets the global logger level, which every logger that
+// hasn't had SetLevel called follows
 func SetGlobalLevel(level LogLevel) {
+	globalLevel.Store(int32(level))
 	globalLogger.SetLevel(level)
 }
 
@@ -1,6 +1,7 @@
 package lib
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 	"os"
@@ -186,6 +187,7 @@ func TestLogger_WithContext(t *testing.T) {
 func TestGlobalLogger(t *testing.T) {
 	// Test global logger functions
 	SetGlobalLevel(DEBUG)
+	t.Cleanup(func() { SetGlobalLevel(INFO) })
 	r, w, err := os.Pipe()
 	require.NoError(t, err)
 	SetGlobalOutput(w)
@@ -280,3 +282,23 @@ func TestLogEntry_Structure(t *testing.T) {
 	assert.Equal(t, entry.Component, unmarshaled.Component)
 	assert.Equal(t, entry.Message, unmarshaled.Message)
 }
+
+func TestLogger_InheritsGlobalLevel(t *testing.T) {
+	SetGlobalLevel(INFO)
+	t.Cleanup(func() { SetGlobalLevel(INFO) })
+
+	var buf bytes.Buffer
+	logger := NewLogger("inherit")
+	logger.SetOutput(&buf)
+
+	logger.Debug("hidden at INFO")
+	SetGlobalLevel(DEBUG)
+	logger.Debug("shown at DEBUG")
+	assert.NotContains(t, buf.String(), "hidden at INFO")
+	assert.Contains(t, buf.String(), "shown at DEBUG")
+
+	buf.Reset()
+	logger.SetLevel(ERROR)
+	logger.Warn("explicit level wins")
+	assert.Empty(t, buf.String())
+}
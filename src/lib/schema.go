@@ -0,0 +1,288 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema is the subset of JSON Schema used to describe the config file:
+// type, properties, additionalProperties, required, enum, numeric and
+// length bounds, pattern, and items. Annotation keywords (title,
+// description, default, ...) are accepted and ignored.
+type Schema struct {
+	Type                 schemaTypes        `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *additionalSchema  `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// schemaTypes accepts "type" as a single name or a list of names.
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = schemaTypes{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("type must be a string or list of strings: %w", err)
+	}
+	*t = many
+	return nil
+}
+
+// additionalSchema accepts additionalProperties as a boolean or a schema.
+type additionalSchema struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+func (a *additionalSchema) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &a.Allowed); err == nil {
+		return nil
+	}
+	a.Allowed = true
+	return json.Unmarshal(data, &a.Schema)
+}
+
+// SchemaViolation is one place where a document does not match its schema.
+type SchemaViolation struct {
+	Line    int
+	Column  int
+	Path    string // e.g. alert_levels[1].threshold; empty for the document root
+	Message string
+}
+
+func (v SchemaViolation) String() string {
+	if v.Path == "" {
+		return fmt.Sprintf("line %d, column %d: %s", v.Line, v.Column, v.Message)
+	}
+	return fmt.Sprintf("line %d, column %d: %s: %s", v.Line, v.Column, v.Path, v.Message)
+}
+
+// ParseSchema decodes a JSON Schema document.
+func ParseSchema(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, WrapError(err, ErrCodeValidation, "invalid JSON schema")
+	}
+	if err := s.compile(); err != nil {
+		return nil, WrapError(err, ErrCodeValidation, "invalid JSON schema")
+	}
+	return &s, nil
+}
+
+func (s *Schema) compile() error {
+	if s == nil {
+		return nil
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return err
+		}
+		s.pattern = re
+	}
+	for _, p := range s.Properties {
+		if err := p.compile(); err != nil {
+			return err
+		}
+	}
+	if s.AdditionalProperties != nil {
+		if err := s.AdditionalProperties.Schema.compile(); err != nil {
+			return err
+		}
+	}
+	return s.Items.compile()
+}
+
+// ValidateYAML checks a YAML document against the schema and returns every
+// violation in document order. The error is non-nil only when the document
+// is not valid YAML.
+func (s *Schema) ValidateYAML(data []byte) ([]SchemaViolation, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, WrapError(err, ErrCodeValidation, "invalid YAML")
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil // empty file
+	}
+
+	var violations []SchemaViolation
+	s.validate(doc.Content[0], "", &violations)
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].Line != violations[j].Line {
+			return violations[i].Line < violations[j].Line
+		}
+		return violations[i].Column < violations[j].Column
+	})
+	return violations, nil
+}
+
+func (s *Schema) validate(node *yaml.Node, path string, out *[]SchemaViolation) {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	report := func(n *yaml.Node, format string, args ...interface{}) {
+		*out = append(*out, SchemaViolation{Line: n.Line, Column: n.Column, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	kind := yamlKind(node)
+	if len(s.Type) > 0 && !s.allowsType(kind, node) {
+		report(node, "expected %s, got %s", strings.Join(s.Type, " or "), kind)
+		return
+	}
+
+	switch kind {
+	case "object":
+		s.validateMapping(node, path, out, report)
+	case "array":
+		for i, item := range node.Content {
+			if s.Items != nil {
+				s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), out)
+			}
+		}
+	case "string":
+		if s.MinLength != nil && len([]rune(node.Value)) < *s.MinLength {
+			report(node, "must be at least %d characters", *s.MinLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(node.Value) {
+			report(node, "%q does not match pattern %s", node.Value, s.Pattern)
+		}
+	case "integer", "number":
+		value, err := strconv.ParseFloat(strings.ReplaceAll(node.Value, "_", ""), 64)
+		if err != nil {
+			if err := node.Decode(&value); err != nil {
+				report(node, "invalid number %q", node.Value)
+				return
+			}
+		}
+		if s.Minimum != nil && value < *s.Minimum {
+			report(node, "must be >= %s", formatBound(*s.Minimum))
+		}
+		if s.ExclusiveMinimum != nil && value <= *s.ExclusiveMinimum {
+			report(node, "must be > %s", formatBound(*s.ExclusiveMinimum))
+		}
+		if s.Maximum != nil && value > *s.Maximum {
+			report(node, "must be <= %s", formatBound(*s.Maximum))
+		}
+	}
+
+	if len(s.Enum) > 0 && !s.enumContains(node) {
+		values := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			values[i] = fmt.Sprint(v)
+		}
+		report(node, "must be one of: %s", strings.Join(values, ", "))
+	}
+}
+
+func (s *Schema) validateMapping(node *yaml.Node, path string, out *[]SchemaViolation, report func(*yaml.Node, string, ...interface{})) {
+	seen := make(map[string]bool, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value == "<<" && key.Tag == "!!merge" {
+			continue
+		}
+		seen[key.Value] = true
+		childPath := key.Value
+		if path != "" {
+			childPath = path + "." + key.Value
+		}
+
+		if prop, ok := s.Properties[key.Value]; ok {
+			prop.validate(value, childPath, out)
+			continue
+		}
+		switch {
+		case s.AdditionalProperties == nil:
+			// Unconstrained.
+		case !s.AdditionalProperties.Allowed:
+			*out = append(*out, SchemaViolation{Line: key.Line, Column: key.Column, Path: path,
+				Message: fmt.Sprintf("unknown field %q", key.Value)})
+		case s.AdditionalProperties.Schema != nil:
+			s.AdditionalProperties.Schema.validate(value, childPath, out)
+		}
+	}
+	for _, name := range s.Required {
+		if !seen[name] {
+			report(node, "missing required field %q", name)
+		}
+	}
+}
+
+// yamlKind maps a node onto a JSON Schema type name.
+func yamlKind(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch node.ShortTag() {
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	case "!!bool":
+		return "boolean"
+	case "!!null":
+		return "null"
+	default:
+		return "string" // includes !!timestamp, which the config reads as text
+	}
+}
+
+func (s *Schema) allowsType(kind string, node *yaml.Node) bool {
+	for _, t := range s.Type {
+		switch {
+		case t == kind:
+			return true
+		case t == "number" && kind == "integer":
+			return true
+		case t == "integer" && kind == "number":
+			var f float64
+			if node.Decode(&f) == nil && f == math.Trunc(f) && !math.IsInf(f, 0) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *Schema) enumContains(node *yaml.Node) bool {
+	for _, v := range s.Enum {
+		if str, ok := v.(string); ok {
+			if node.Value == str {
+				return true
+			}
+			continue
+		}
+		if fmt.Sprint(v) == node.Value {
+			return true
+		}
+	}
+	return false
+}
+
+func formatBound(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
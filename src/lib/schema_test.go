@@ -0,0 +1,82 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+  "type": "object",
+  "additionalProperties": false,
+  "properties": {
+    "name":     { "type": "string", "minLength": 1, "pattern": "^[a-z]+$" },
+    "interval": { "type": "integer", "minimum": 10, "maximum": 300 },
+    "ratio":    { "type": "number", "exclusiveMinimum": 0 },
+    "mode":     { "type": "string", "enum": ["up", "down"] },
+    "enabled":  { "type": "boolean" },
+    "limits":   { "type": "object", "additionalProperties": { "type": "number" } },
+    "levels": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "required": ["threshold"],
+        "properties": { "threshold": { "type": ["number", "null"] } }
+      }
+    }
+  }
+}`
+
+func TestSchema_ValidateYAML(t *testing.T) {
+	schema, err := ParseSchema([]byte(testSchema))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		yaml string
+		want []string
+	}{
+		{"empty document", "", nil},
+		{"valid", "name: abc\ninterval: 30\nratio: 0.5\nmode: up\nenabled: true\nlimits: {a: 1}\nlevels:\n  - threshold: 5\n  - threshold: ~\n", nil},
+		{"integral float accepted as integer", "interval: 30.0\n", nil},
+		{"unknown field", "nmae: abc\n", []string{`line 1, column 1: unknown field "nmae"`}},
+		{"wrong type", "interval: soon\n", []string{"line 1, column 11: interval: expected integer, got string"}},
+		{"bounds", "interval: 5\nratio: 0\n", []string{
+			"line 1, column 11: interval: must be >= 10",
+			"line 2, column 8: ratio: must be > 0",
+		}},
+		{"enum and pattern", "mode: sideways\nname: ABC\n", []string{
+			"line 1, column 7: mode: must be one of: up, down",
+			`line 2, column 7: name: "ABC" does not match pattern ^[a-z]+$`,
+		}},
+		{"nested paths", "limits:\n  opus: lots\nlevels:\n  - {}\n", []string{
+			"line 2, column 9: limits.opus: expected number, got string",
+			`line 4, column 5: levels[0]: missing required field "threshold"`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations, err := schema.ValidateYAML([]byte(tt.yaml))
+			require.NoError(t, err)
+			var got []string
+			for _, v := range violations {
+				got = append(got, v.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSchema_Errors(t *testing.T) {
+	_, err := ParseSchema([]byte(`{"type": 5}`))
+	assert.Error(t, err)
+	_, err = ParseSchema([]byte(`{"pattern": "("}`))
+	assert.Error(t, err)
+
+	schema, err := ParseSchema([]byte(`{"type": "object"}`))
+	require.NoError(t, err)
+	_, err = schema.ValidateYAML([]byte("a: [unclosed\n"))
+	assert.Error(t, err)
+}
@@ -2,29 +2,125 @@ package lib
 
 import (
 	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
 	"text/template"
 )
 
-// TemplateEngine provides template execution with validation and error handling
+// maxParsedTemplates bounds the parsed-template cache; it is emptied when
+// full rather than tracking use, as callers render a handful of templates.
+const maxParsedTemplates = 64
+
+// PresetPrefix marks a template string that names a registered preset,
+// e.g. "@percent".
+const PresetPrefix = "@"
+
+// TemplateEngine provides template execution with validation and error
+// handling. Parsed templates are cached by their text, so rendering the
+// same template on every update parses it once. Register presets and
+// functions before sharing the engine between goroutines.
 type TemplateEngine struct {
-	logger *Logger
+	logger  *Logger
+	presets map[string]string
+	funcs   template.FuncMap
+
+	mu     sync.Mutex
+	parsed map[string]*template.Template // by template text, after presets are resolved
 }
 
 // NewTemplateEngine creates a new template engine
 func NewTemplateEngine() *TemplateEngine {
 	return &TemplateEngine{
-		logger: NewLogger("template"),
+		logger:  NewLogger("template"),
+		presets: map[string]string{},
+		funcs:   template.FuncMap{},
+		parsed:  map[string]*template.Template{},
+	}
+}
+
+// RegisterFuncs makes funcs callable from templates, replacing any
+// already registered under the same names. Cached templates are dropped,
+// as they were parsed with the old functions.
+func (te *TemplateEngine) RegisterFuncs(funcs template.FuncMap) {
+	for name, fn := range funcs {
+		te.funcs[name] = fn
+	}
+	te.ClearCache()
+}
+
+// ClearCache drops every cached parsed template.
+func (te *TemplateEngine) ClearCache() {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	te.parsed = map[string]*template.Template{}
+}
+
+// parse returns templateStr parsed with the registered functions, from the
+// cache when it has been parsed before. Failures aren't cached.
+func (te *TemplateEngine) parse(templateStr string) (*template.Template, error) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	if tmpl, ok := te.parsed[templateStr]; ok {
+		return tmpl, nil
+	}
+	tmpl, err := template.New("display").Funcs(te.funcs).Parse(templateStr)
+	if err != nil {
+		return nil, err
+	}
+	if len(te.parsed) >= maxParsedTemplates {
+		te.parsed = map[string]*template.Template{}
+	}
+	te.parsed[templateStr] = tmpl
+	return tmpl, nil
+}
+
+// RegisterPreset makes templateStr available as "@name", replacing any
+// preset already registered under name.
+func (te *TemplateEngine) RegisterPreset(name, templateStr string) {
+	te.presets[name] = templateStr
+}
+
+// Presets returns the registered preset names, sorted.
+func (te *TemplateEngine) Presets() []string {
+	names := make([]string, 0, len(te.presets))
+	for name := range te.presets {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
 }
 
-// Execute executes a template string with the provided data
+// Resolve returns the template templateStr stands for: the registered
+// preset's for "@name", otherwise templateStr itself.
+func (te *TemplateEngine) Resolve(templateStr string) (string, error) {
+	name, ok := strings.CutPrefix(templateStr, PresetPrefix)
+	if !ok {
+		return templateStr, nil
+	}
+	preset, ok := te.presets[name]
+	if !ok {
+		return "", TemplateError(fmt.Sprintf("unknown template preset %q (available: %s)",
+			templateStr, PresetPrefix+strings.Join(te.Presets(), ", "+PresetPrefix)))
+	}
+	return preset, nil
+}
+
+// Execute executes a template string, or a preset named "@name", with the
+// provided data
 func (te *TemplateEngine) Execute(templateStr string, data interface{}) (string, error) {
 	if templateStr == "" {
 		return "", TemplateError("template string cannot be empty")
 	}
+	templateStr, err := te.Resolve(templateStr)
+	if err != nil {
+		return "", err
+	}
 
-	// Parse the template
-	tmpl, err := template.New("display").Parse(templateStr)
+	// Parse the template, or reuse the cached parse
+	tmpl, err := te.parse(templateStr)
 	if err != nil {
 		te.logger.Error("Template parsing failed", map[string]interface{}{
 			"template": templateStr,
@@ -54,13 +150,18 @@ func (te *TemplateEngine) Execute(templateStr string, data interface{}) (string,
 	return result, nil
 }
 
-// Validate validates a template string without executing it
+// Validate validates a template string, or resolves a preset name,
+// without executing it, so unknown fields pass; see ValidateStrict
 func (te *TemplateEngine) Validate(templateStr string) error {
 	if templateStr == "" {
 		return TemplateError("template string cannot be empty")
 	}
+	templateStr, err := te.Resolve(templateStr)
+	if err != nil {
+		return err
+	}
 
-	_, err := template.New("validation").Parse(templateStr)
+	_, err = te.parse(templateStr)
 	if err != nil {
 		te.logger.Warn("Template validation failed", map[string]interface{}{
 			"template": templateStr,
@@ -76,6 +177,33 @@ func (te *TemplateEngine) Validate(templateStr string) error {
 	return nil
 }
 
+// ValidateStrict validates templateStr, or the preset it names, then
+// executes it against sample with missingkey=error, so a field or map key
+// sample doesn't have fails here instead of rendering "<no value>". Only
+// the branches sample takes are checked; callers with conditional
+// templates should try a sample for each case.
+func (te *TemplateEngine) ValidateStrict(templateStr string, sample interface{}) error {
+	if err := te.Validate(templateStr); err != nil {
+		return err
+	}
+	resolved, err := te.Resolve(templateStr)
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New("validation").Funcs(te.funcs).Option("missingkey=error").Parse(resolved)
+	if err != nil {
+		return WrapError(err, ErrCodeTemplate, "template validation failed")
+	}
+	if err := tmpl.Execute(io.Discard, sample); err != nil {
+		te.logger.Warn("Template refers to missing data", map[string]interface{}{
+			"template": resolved,
+			"error":    err.Error(),
+		})
+		return WrapError(err, ErrCodeTemplate, "template refers to data that doesn't exist")
+	}
+	return nil
+}
+
 // ExecuteWithDefault executes a template and returns a default value on error
 func (te *TemplateEngine) ExecuteWithDefault(templateStr string, data interface{}, defaultValue string) string {
 	result, err := te.Execute(templateStr, data)
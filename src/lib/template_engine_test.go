@@ -1,7 +1,10 @@
 package lib
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"text/template"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -312,3 +315,121 @@ func TestTemplateEngine_Logging(t *testing.T) {
 	assert.Error(t, err)
 	assert.Empty(t, result)
 }
+
+func TestTemplateEngine_Presets(t *testing.T) {
+	engine := NewTemplateEngine()
+	engine.RegisterPreset("greeting", "Hello {{.Name}}")
+	engine.RegisterPreset("bare", "{{.Name}}")
+	data := map[string]string{"Name": "World"}
+
+	result, err := engine.Execute("@greeting", data)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello World", result)
+	assert.NoError(t, engine.Validate("@bare"))
+	assert.Equal(t, []string{"bare", "greeting"}, engine.Presets())
+
+	result, err = engine.Execute("mail@example.com {{.Name}}", data)
+	require.NoError(t, err)
+	assert.Equal(t, "mail@example.com World", result, "only a leading @ names a preset")
+
+	err = engine.Validate("@missing")
+	require.Error(t, err)
+	assert.True(t, IsErrorCode(err, ErrCodeTemplate))
+	assert.Contains(t, err.Error(), `unknown template preset "@missing" (available: @bare, @greeting)`)
+
+	engine.RegisterPreset("bare", "[{{.Name}}]")
+	result, err = engine.Execute("@bare", data)
+	require.NoError(t, err)
+	assert.Equal(t, "[World]", result, "registering again replaces the preset")
+}
+
+func TestTemplateEngine_RegisterFuncs(t *testing.T) {
+	engine := NewTemplateEngine()
+	assert.Error(t, engine.Validate("{{shout .Name}}"), "unregistered functions don't parse")
+
+	engine.RegisterFuncs(template.FuncMap{"shout": strings.ToUpper})
+	require.NoError(t, engine.Validate("{{shout .Name}}"))
+	result, err := engine.Execute("{{shout .Name}}!", map[string]string{"Name": "World"})
+	require.NoError(t, err)
+	assert.Equal(t, "WORLD!", result)
+}
+
+func TestTemplateEngine_Cache(t *testing.T) {
+	engine := NewTemplateEngine()
+	engine.RegisterPreset("hello", "Hello {{.Name}}")
+	data := map[string]string{"Name": "World"}
+
+	for i := 0; i < 3; i++ {
+		_, err := engine.Execute("@hello", data)
+		require.NoError(t, err)
+		_, err = engine.Execute("Hello {{.Name}}", data)
+		require.NoError(t, err)
+	}
+	assert.Len(t, engine.parsed, 1, "a preset and its text share one parse")
+	cached := engine.parsed["Hello {{.Name}}"]
+
+	_, err := engine.Execute("Hello {{.Name", data)
+	require.Error(t, err)
+	assert.Len(t, engine.parsed, 1, "failed parses aren't cached")
+
+	engine.RegisterFuncs(template.FuncMap{"shout": strings.ToUpper})
+	assert.Empty(t, engine.parsed, "new functions invalidate the cache")
+	_, err = engine.Execute("Hello {{.Name}}", data)
+	require.NoError(t, err)
+	assert.NotSame(t, cached, engine.parsed["Hello {{.Name}}"])
+
+	for i := 0; i < maxParsedTemplates; i++ {
+		require.NoError(t, engine.Validate(fmt.Sprintf("template %d", i)))
+	}
+	assert.LessOrEqual(t, len(engine.parsed), maxParsedTemplates, "the cache is bounded")
+}
+
+// BenchmarkTemplateEngine_Execute renders a title-sized template the way
+// the tray does on every update: cached reuses the parse, uncached is the
+// cost of parsing each time.
+func BenchmarkTemplateEngine_Execute(b *testing.B) {
+	const tmpl = "CC {{.Symbol}} {{.Cost}} · {{.Tokens}} · {{.Calls}} calls"
+	data := map[string]interface{}{"Symbol": "🟡", "Cost": "$12.40", "Tokens": "1.2M", "Calls": 37}
+
+	b.Run("cached", func(b *testing.B) {
+		engine := NewTemplateEngine()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := engine.Execute(tmpl, data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		engine := NewTemplateEngine()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			engine.ClearCache()
+			if _, err := engine.Execute(tmpl, data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestTemplateEngine_ValidateStrict(t *testing.T) {
+	engine := NewTemplateEngine()
+	engine.RegisterPreset("greeting", "Hello {{.Name}}")
+	sample := map[string]string{"Name": "World"}
+
+	assert.NoError(t, engine.Validate("{{.InvalidField}}"), "plain validation only parses")
+	result, err := engine.Execute("{{.InvalidField}}", sample)
+	require.NoError(t, err)
+	assert.Equal(t, "<no value>", result)
+
+	assert.NoError(t, engine.ValidateStrict("@greeting", sample))
+	err = engine.ValidateStrict("{{.InvalidField}}", sample)
+	require.Error(t, err)
+	assert.True(t, IsErrorCode(err, ErrCodeTemplate))
+	assert.Contains(t, err.Error(), "template refers to data that doesn't exist")
+
+	type data struct{ Name string }
+	assert.ErrorContains(t, engine.ValidateStrict("{{.Nmae}}", data{}), "can't evaluate field Nmae")
+	assert.NoError(t, engine.ValidateStrict("{{if .Name}}{{.Nmae}}{{end}}", data{}), "only the branches taken are checked")
+	assert.ErrorContains(t, engine.ValidateStrict("{{.Name", data{}), "template validation failed")
+}
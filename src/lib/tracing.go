@@ -0,0 +1,230 @@
+package lib
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tracer records spans and sends each finished trace to an OpenTelemetry
+// collector over OTLP/HTTP, using the protocol's JSON encoding so no SDK
+// is needed. A nil *Tracer is valid and records nothing, which lets
+// callers trace unconditionally.
+type Tracer struct {
+	url     string
+	service string
+	client  *http.Client
+	logger  *Logger
+	exports sync.WaitGroup
+}
+
+// NewTracer returns a tracer exporting to an OTLP/HTTP collector at
+// endpoint (e.g. "http://localhost:4318"); spans are posted to
+// <endpoint>/v1/traces under the given service name.
+func NewTracer(endpoint, service string) *Tracer {
+	return &Tracer{
+		url:     strings.TrimRight(endpoint, "/") + "/v1/traces",
+		service: service,
+		client:  &http.Client{Timeout: 5 * time.Second},
+		logger:  NewLogger("tracer"),
+	}
+}
+
+// Start begins a root span. Ending it exports the span and every child
+// that ended before it.
+func (t *Tracer) Start(name string) *Span {
+	if t == nil {
+		return nil
+	}
+	span := &Span{tracer: t, name: name, start: time.Now()}
+	span.root = span
+	_, _ = rand.Read(span.traceID[:])
+	_, _ = rand.Read(span.spanID[:])
+	return span
+}
+
+// Shutdown waits for exports already in flight.
+func (t *Tracer) Shutdown() {
+	if t != nil {
+		t.exports.Wait()
+	}
+}
+
+// Span is one timed operation within a trace. All methods are no-ops on a
+// nil *Span.
+type Span struct {
+	tracer   *Tracer
+	root     *Span
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	start    time.Time
+	end      time.Time
+	attrs    map[string]interface{}
+	errMsg   string
+
+	mutex    sync.Mutex // root only: guards finished
+	finished []*Span
+}
+
+// Child begins a span nested under s.
+func (s *Span) Child(name string) *Span {
+	if s == nil {
+		return nil
+	}
+	child := &Span{tracer: s.tracer, root: s.root, traceID: s.traceID, parentID: s.spanID, name: name, start: time.Now()}
+	_, _ = rand.Read(child.spanID[:])
+	return child
+}
+
+// SetAttribute records a string, bool, integer, or float attribute.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	if s.attrs == nil {
+		s.attrs = map[string]interface{}{}
+	}
+	s.attrs[key] = value
+}
+
+// SetError marks the span as failed with err's message; a nil err is
+// ignored.
+func (s *Span) SetError(err error) {
+	if s != nil && err != nil {
+		s.errMsg = err.Error()
+	}
+}
+
+// End finishes the span. Ending a root span exports the whole trace in
+// the background.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.end = time.Now()
+	root := s.root
+	root.mutex.Lock()
+	root.finished = append(root.finished, s)
+	spans := root.finished
+	root.mutex.Unlock()
+
+	if s == root {
+		s.tracer.exports.Add(1)
+		go func() {
+			defer s.tracer.exports.Done()
+			s.tracer.export(spans)
+		}()
+	}
+}
+
+func (t *Tracer) export(spans []*Span) {
+	body, err := json.Marshal(t.payload(spans))
+	if err == nil {
+		var resp *http.Response
+		resp, err = t.client.Post(t.url, "application/json", bytes.NewReader(body))
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode/100 != 2 {
+				err = fmt.Errorf("collector returned %s", resp.Status)
+			}
+		}
+	}
+	if err != nil {
+		t.logger.Warn("Failed to export trace", map[string]interface{}{
+			"error": err.Error(),
+			"url":   t.url,
+		})
+	}
+}
+
+// otlpSpan and friends mirror the OTLP/JSON trace encoding: hex IDs,
+// nanosecond timestamps as strings, and typed attribute values.
+type otlpAttribute struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"` // 1 OK, 2 ERROR
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"` // 1 INTERNAL
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+func (t *Tracer) payload(spans []*Span) map[string]interface{} {
+	encoded := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(s.traceID[:]),
+			SpanID:            hex.EncodeToString(s.spanID[:]),
+			Name:              s.name,
+			Kind:              1,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        otlpAttributes(s.attrs),
+			Status:            otlpStatus{Code: 1},
+		}
+		if s.parentID != [8]byte{} {
+			span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+		}
+		if s.errMsg != "" {
+			span.Status = otlpStatus{Code: 2, Message: s.errMsg}
+		}
+		encoded = append(encoded, span)
+	}
+
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": otlpAttributes(map[string]interface{}{"service.name": t.service}),
+			},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]interface{}{"name": t.service},
+				"spans": encoded,
+			}},
+		}},
+	}
+}
+
+func otlpAttributes(attrs map[string]interface{}) []otlpAttribute {
+	out := make([]otlpAttribute, 0, len(attrs))
+	for key, value := range attrs {
+		var typed map[string]interface{}
+		switch v := value.(type) {
+		case bool:
+			typed = map[string]interface{}{"boolValue": v}
+		case int:
+			typed = map[string]interface{}{"intValue": strconv.Itoa(v)}
+		case int64:
+			typed = map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+		case float64:
+			typed = map[string]interface{}{"doubleValue": v}
+		default:
+			typed = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+		}
+		out = append(out, otlpAttribute{Key: key, Value: typed})
+	}
+	// Stable output makes payloads diffable and testable.
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
@@ -0,0 +1,105 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// otlpCollector records the bodies posted to /v1/traces.
+type otlpCollector struct {
+	mutex  sync.Mutex
+	bodies []map[string]interface{}
+}
+
+func (c *otlpCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	data, _ := io.ReadAll(r.Body)
+	var body map[string]interface{}
+	if json.Unmarshal(data, &body) == nil {
+		c.mutex.Lock()
+		c.bodies = append(c.bodies, body)
+		c.mutex.Unlock()
+	}
+}
+
+func (c *otlpCollector) spans(t *testing.T) []map[string]interface{} {
+	t.Helper()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	require.Len(t, c.bodies, 1)
+	resource := c.bodies[0]["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	scope := resource["scopeSpans"].([]interface{})[0].(map[string]interface{})
+	var spans []map[string]interface{}
+	for _, s := range scope["spans"].([]interface{}) {
+		spans = append(spans, s.(map[string]interface{}))
+	}
+	return spans
+}
+
+func TestTracer_ExportsTrace(t *testing.T) {
+	collector := &otlpCollector{}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	tracer := NewTracer(server.URL+"/", "test-service")
+	root := tracer.Start("poll")
+	child := root.Child("ccusage.exec")
+	child.SetAttribute("attempt", 1)
+	child.SetAttribute("cached", false)
+	child.SetError(errors.New("exit status 1"))
+	child.End()
+	root.End()
+	tracer.Shutdown()
+
+	spans := collector.spans(t)
+	require.Len(t, spans, 2)
+	exec, poll := spans[0], spans[1]
+
+	assert.Equal(t, "poll", poll["name"])
+	assert.Len(t, poll["traceId"], 32)
+	assert.Len(t, poll["spanId"], 16)
+	assert.NotContains(t, poll, "parentSpanId")
+	assert.Equal(t, float64(1), poll["status"].(map[string]interface{})["code"])
+
+	assert.Equal(t, "ccusage.exec", exec["name"])
+	assert.Equal(t, poll["traceId"], exec["traceId"])
+	assert.Equal(t, poll["spanId"], exec["parentSpanId"])
+	assert.Equal(t, map[string]interface{}{"code": float64(2), "message": "exit status 1"}, exec["status"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"key": "attempt", "value": map[string]interface{}{"intValue": "1"}},
+		map[string]interface{}{"key": "cached", "value": map[string]interface{}{"boolValue": false}},
+	}, exec["attributes"])
+}
+
+func TestTracer_CollectorDown(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	tracer := NewTracer(server.URL, "test-service")
+	tracer.Start("poll").End()
+	tracer.Shutdown() // logs the failure and returns
+}
+
+func TestTracer_NilIsNoop(t *testing.T) {
+	var tracer *Tracer
+	span := tracer.Start("poll")
+	assert.Nil(t, span)
+
+	child := span.Child("ccusage.exec")
+	child.SetAttribute("attempt", 1)
+	child.SetError(errors.New("boom"))
+	child.End()
+	span.End()
+	tracer.Shutdown()
+}
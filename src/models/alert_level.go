@@ -0,0 +1,133 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"cc-dailyuse-bar/src/lib"
+)
+
+// AlertLevel is one data-driven stage of the spend ladder (e.g. 25%, 50%,
+// 75%, 100% of a budget). Each level maps onto one of the coarse
+// AlertStatus values so existing consumers keep working, while Name and
+// Symbol let the display distinguish finer-grained stages.
+type AlertLevel struct {
+	Name      string      `yaml:"name" json:"name"`
+	Threshold float64     `yaml:"threshold" json:"threshold"`
+	Status    AlertStatus `yaml:"status" json:"status"`
+	Symbol    string      `yaml:"symbol,omitempty" json:"symbol,omitempty"`
+	// Notify, when set, announces reaching this level in place of the
+	// status_notifications entry for its status.
+	Notify *NotifyPolicy `yaml:"notify,omitempty" json:"notify,omitempty"`
+}
+
+// ParseAlertStatus converts a status name from config into an AlertStatus.
+// Both color names (green/yellow/red) and display names (ok/high/critical)
+// are accepted, case-insensitively.
+func ParseAlertStatus(name string) (AlertStatus, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "green", "ok":
+		return Green, nil
+	case "yellow", "high":
+		return Yellow, nil
+	case "red", "critical":
+		return Red, nil
+	case "unknown":
+		return Unknown, nil
+	default:
+		return Unknown, fmt.Errorf("unknown alert status %q", name)
+	}
+}
+
+// UnmarshalYAML reads a status name ("yellow", "high"), or the integer
+// older versions wrote.
+func (a *AlertStatus) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var legacy int
+	if err := unmarshal(&legacy); err == nil {
+		return a.setLegacy(legacy)
+	}
+	var name string
+	if err := unmarshal(&name); err != nil {
+		return err
+	}
+	status, err := ParseAlertStatus(name)
+	if err != nil {
+		return err
+	}
+	*a = status
+	return nil
+}
+
+// MarshalYAML writes the status as its color name, as MarshalJSON does.
+func (a AlertStatus) MarshalYAML() (interface{}, error) {
+	return a.ColorName(), nil
+}
+
+// MarshalJSON writes the status as its color name (green, yellow, red, or
+// unknown), the vocabulary every JSON status shares, rather than the
+// opaque integer.
+func (a AlertStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.ColorName())
+}
+
+// UnmarshalJSON reads a color or status name, or the integer older
+// versions wrote to the HTTP API, control socket, and saved state.
+func (a *AlertStatus) UnmarshalJSON(data []byte) error {
+	var legacy int
+	if err := json.Unmarshal(data, &legacy); err == nil {
+		return a.setLegacy(legacy)
+	}
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("alert status must be a name or integer, got %s", data)
+	}
+	status, err := ParseAlertStatus(name)
+	if err != nil {
+		return err
+	}
+	*a = status
+	return nil
+}
+
+func (a *AlertStatus) setLegacy(value int) error {
+	if value < int(Green) || value > int(Unknown) {
+		return fmt.Errorf("unknown alert status %d", value)
+	}
+	*a = AlertStatus(value)
+	return nil
+}
+
+// ValidateAlertLevels checks that levels are named, use a real status, and
+// are listed in strictly ascending threshold order.
+func ValidateAlertLevels(levels []AlertLevel) error {
+	for i, level := range levels {
+		if strings.TrimSpace(level.Name) == "" {
+			return lib.ValidationError(fmt.Sprintf("alert_levels[%d]: name cannot be empty", i))
+		}
+		if level.Threshold < 0 {
+			return lib.ValidationError(fmt.Sprintf("alert_levels[%d]: threshold must be positive", i))
+		}
+		if level.Status != Green && level.Status != Yellow && level.Status != Red {
+			return lib.ValidationError(fmt.Sprintf("alert_levels[%d]: status must be one of: green, yellow, red", i))
+		}
+		if err := level.Notify.Validate(fmt.Sprintf("alert_levels[%d].notify", i)); err != nil {
+			return err
+		}
+		if i > 0 && level.Threshold <= levels[i-1].Threshold {
+			return lib.ValidationError(fmt.Sprintf("alert_levels[%d]: thresholds must be in ascending order", i))
+		}
+	}
+	return nil
+}
+
+// MatchAlertLevel returns the highest level whose threshold cost has
+// reached, or false when cost is below every threshold.
+func MatchAlertLevel(levels []AlertLevel, cost float64) (AlertLevel, bool) {
+	for i := len(levels) - 1; i >= 0; i-- {
+		if cost >= levels[i].Threshold {
+			return levels[i], true
+		}
+	}
+	return AlertLevel{}, false
+}
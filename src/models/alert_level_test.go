@@ -0,0 +1,152 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func budgetLadder() []AlertLevel {
+	return []AlertLevel{
+		{Name: "25%", Threshold: 5, Status: Green, Symbol: "🟢"},
+		{Name: "50%", Threshold: 10, Status: Yellow},
+		{Name: "75%", Threshold: 15, Status: Yellow, Symbol: "🟠"},
+		{Name: "100%", Threshold: 20, Status: Red},
+	}
+}
+
+func TestParseAlertStatus(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected AlertStatus
+		wantErr  bool
+	}{
+		{"green", Green, false},
+		{"OK", Green, false},
+		{"yellow", Yellow, false},
+		{"High", Yellow, false},
+		{" red ", Red, false},
+		{"critical", Red, false},
+		{"unknown", Unknown, false},
+		{"purple", Unknown, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			status, err := ParseAlertStatus(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, status)
+		})
+	}
+}
+
+func TestMatchAlertLevel(t *testing.T) {
+	levels := budgetLadder()
+
+	_, ok := MatchAlertLevel(levels, 4.99)
+	assert.False(t, ok)
+
+	level, ok := MatchAlertLevel(levels, 5)
+	require.True(t, ok)
+	assert.Equal(t, "25%", level.Name)
+
+	level, _ = MatchAlertLevel(levels, 17)
+	assert.Equal(t, "75%", level.Name)
+
+	level, _ = MatchAlertLevel(levels, 500)
+	assert.Equal(t, "100%", level.Name)
+}
+
+func TestValidateAlertLevels(t *testing.T) {
+	assert.NoError(t, ValidateAlertLevels(nil))
+	assert.NoError(t, ValidateAlertLevels(budgetLadder()))
+
+	tests := []struct {
+		name     string
+		mutate   func([]AlertLevel)
+		expected string
+	}{
+		{"empty name", func(l []AlertLevel) { l[1].Name = " " }, "alert_levels[1]: name cannot be empty"},
+		{"negative threshold", func(l []AlertLevel) { l[0].Threshold = -1 }, "alert_levels[0]: threshold must be positive"},
+		{"unknown status", func(l []AlertLevel) { l[2].Status = Unknown }, "alert_levels[2]: status must be one of"},
+		{"not ascending", func(l []AlertLevel) { l[3].Threshold = 15 }, "alert_levels[3]: thresholds must be in ascending order"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			levels := budgetLadder()
+			tt.mutate(levels)
+			err := ValidateAlertLevels(levels)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestUsageState_UpdateLevels(t *testing.T) {
+	state := &UsageState{DailyCost: 2}
+	state.UpdateLevels(budgetLadder())
+	assert.Equal(t, Green, state.Status)
+	assert.Empty(t, state.Level)
+
+	state.DailyCost = 16
+	state.UpdateLevels(budgetLadder())
+	assert.Equal(t, Yellow, state.Status)
+	assert.Equal(t, "75%", state.Level)
+	assert.Equal(t, "🟠", state.LevelSymbol)
+
+	// Falling back to the threshold pair clears the level details.
+	state.UpdateStatus(10, 20)
+	assert.Equal(t, Yellow, state.Status)
+	assert.Empty(t, state.Level)
+	assert.Empty(t, state.LevelSymbol)
+}
+
+func TestUsageState_UpdateStatusFromConfig(t *testing.T) {
+	config := ConfigDefaults()
+	state := &UsageState{DailyCost: 12}
+
+	state.UpdateStatusFromConfig(config)
+	assert.Equal(t, Yellow, state.Status, "classic thresholds without alert_levels")
+
+	config.AlertLevels = budgetLadder()
+	state.DailyCost = 21
+	state.UpdateStatusFromConfig(config)
+	assert.Equal(t, Red, state.Status)
+	assert.Equal(t, "100%", state.Level)
+}
+
+func TestConfig_AlertLevelsYAML(t *testing.T) {
+	input := `
+alert_levels:
+  - name: "50%"
+    threshold: 10
+    status: yellow
+  - name: "100%"
+    threshold: 20
+    status: critical
+    symbol: "🔥"
+`
+	var config Config
+	require.NoError(t, yaml.Unmarshal([]byte(input), &config))
+	require.Len(t, config.AlertLevels, 2)
+	assert.Equal(t, Yellow, config.AlertLevels[0].Status)
+	assert.Equal(t, Red, config.AlertLevels[1].Status)
+	assert.Equal(t, "🔥", config.AlertLevels[1].Symbol)
+
+	out, err := yaml.Marshal(config.AlertLevels)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "status: red")
+
+	require.NoError(t, yaml.Unmarshal([]byte("alert_levels:\n  - name: x\n    threshold: 1\n    status: 1\n"), &config))
+	assert.Equal(t, Yellow, config.AlertLevels[0].Status, "integers from older versions still load")
+
+	err = yaml.Unmarshal([]byte("alert_levels:\n  - name: x\n    threshold: 1\n    status: purple\n"), &config)
+	assert.Error(t, err)
+}
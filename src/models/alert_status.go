@@ -27,6 +27,21 @@ func (a AlertStatus) String() string {
 	}
 }
 
+// ColorName returns the lower-case color for the status: green, yellow,
+// red, or unknown.
+func (a AlertStatus) ColorName() string {
+	switch a {
+	case Green:
+		return "green"
+	case Yellow:
+		return "yellow"
+	case Red:
+		return "red"
+	default:
+		return "unknown"
+	}
+}
+
 // ToTrayIcon converts an AlertStatus to the corresponding TrayIcon
 func (a AlertStatus) ToTrayIcon() TrayIcon {
 	switch a {
@@ -1,9 +1,12 @@
 package models
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestAlertStatus_String(t *testing.T) {
@@ -24,6 +27,48 @@ func TestAlertStatus_String(t *testing.T) {
 	}
 }
 
+func TestAlertStatus_ColorName(t *testing.T) {
+	assert.Equal(t, "green", Green.ColorName())
+	assert.Equal(t, "yellow", Yellow.ColorName())
+	assert.Equal(t, "red", Red.ColorName())
+	assert.Equal(t, "unknown", Unknown.ColorName())
+	assert.Equal(t, "unknown", AlertStatus(999).ColorName())
+}
+
+func TestAlertStatus_JSON(t *testing.T) {
+	for status, name := range map[AlertStatus]string{Green: "green", Yellow: "yellow", Red: "red", Unknown: "unknown"} {
+		data, err := json.Marshal(status)
+		require.NoError(t, err)
+		assert.Equal(t, `"`+name+`"`, string(data))
+
+		var decoded AlertStatus
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, status, decoded)
+	}
+
+	var state UsageState
+	require.NoError(t, json.Unmarshal([]byte(`{"status": 2}`), &state))
+	assert.Equal(t, Red, state.Status, "integers from older versions still decode")
+	require.NoError(t, json.Unmarshal([]byte(`{"status": "high"}`), &state))
+	assert.Equal(t, Yellow, state.Status, "status names decode too")
+
+	var status AlertStatus
+	assert.Error(t, json.Unmarshal([]byte(`"purple"`), &status))
+	assert.Error(t, json.Unmarshal([]byte(`7`), &status))
+	assert.Error(t, json.Unmarshal([]byte(`true`), &status))
+}
+
+func TestAlertStatus_YAML(t *testing.T) {
+	data, err := yaml.Marshal(map[string]AlertStatus{"status": Red})
+	require.NoError(t, err)
+	assert.Equal(t, "status: red\n", string(data))
+
+	var decoded map[string]AlertStatus
+	require.NoError(t, yaml.Unmarshal([]byte("a: high\nb: 0\nc: Red\n"), &decoded))
+	assert.Equal(t, map[string]AlertStatus{"a": Yellow, "b": Green, "c": Red}, decoded)
+	assert.Error(t, yaml.Unmarshal([]byte("a: -1\n"), &decoded))
+}
+
 func TestAlertStatus_ToTrayIcon(t *testing.T) {
 	tests := []struct {
 		status       AlertStatus
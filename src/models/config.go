@@ -1,11 +1,21 @@
 package models
 
 import (
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"cc-dailyuse-bar/src/lib"
 )
 
+// RedSoundSystem as red_sound plays the platform's alert sound.
+const RedSoundSystem = "system"
+
 // Config represents the application configuration structure
 type Config struct {
 	CCUsagePath     string  `yaml:"ccusage_path"`
@@ -13,8 +23,162 @@ type Config struct {
 	YellowThreshold float64 `yaml:"yellow_threshold"`
 	RedThreshold    float64 `yaml:"red_threshold"`
 	DebugLevel      string  `yaml:"debug_level"`
-	CacheWindow     int     `yaml:"cache_window"` // Cache window in seconds
-	CmdTimeout      int     `yaml:"cmd_timeout"`  // Command timeout in seconds
+	CacheWindow     int     `yaml:"cache_window"`    // Cache window in seconds
+	CmdTimeout      int     `yaml:"cmd_timeout"`     // Command timeout in seconds
+	WatchDataDirs   bool    `yaml:"watch_data_dirs"` // Refresh as soon as Claude writes usage JSONL
+	WeeklyBudget    float64 `yaml:"weekly_budget"`   // Weekly spend goal in dollars; 0 disables
+	MonthlyBudget   float64 `yaml:"monthly_budget"`  // Monthly spend goal the forecast is compared to; 0 disables
+	ScreenReader    bool    `yaml:"screen_reader"`   // Words instead of emoji in the title and menu
+
+	// AlertLevels replaces the yellow/red pair with an ordered ladder of
+	// thresholds when non-empty.
+	AlertLevels []AlertLevel `yaml:"alert_levels,omitempty"`
+
+	// DayThresholds overrides the yellow/red pair on specific days, keyed by
+	// day name (monday..sunday) or group (weekdays, weekends).
+	DayThresholds map[string]ThresholdPair `yaml:"day_thresholds,omitempty"`
+
+	// ModelThresholds alerts when today's spend on models matching a name
+	// pattern (case-insensitive substring, e.g. "opus") reaches a limit.
+	ModelThresholds map[string]float64 `yaml:"model_thresholds,omitempty"`
+
+	// SessionCap alerts when a single session's cost reaches it, however
+	// far today's total is from the thresholds; 0 disables.
+	SessionCap float64 `yaml:"session_cap,omitempty"`
+
+	// ProjectTags allocates each session's cost to a tag by its project
+	// path; the tray and exports then split spend per tag.
+	ProjectTags []ProjectTag `yaml:"project_tags,omitempty"`
+
+	// QuietUntil (YYYY-MM-DD, inclusive) keeps collecting data but holds the
+	// status at green and suppresses notifications until the day after.
+	QuietUntil string `yaml:"quiet_until,omitempty"`
+
+	// QuietHours ("23:00-07:00") is a daily window during which polling and
+	// notifications pause; an immediate refresh follows its end.
+	QuietHours string `yaml:"quiet_hours,omitempty"`
+
+	// StatusNotifications sends a notification on reaching yellow or red,
+	// optionally repeated until acknowledged; alert_levels entries can
+	// override it with their own notify setting.
+	StatusNotifications StatusNotifications `yaml:"status_notifications,omitempty"`
+
+	// FocusNotifications decides what happens to notifications while
+	// Do Not Disturb or a Focus mode is on: defer (default), suppress, or
+	// send.
+	FocusNotifications FocusPolicy `yaml:"focus_notifications,omitempty"`
+
+	// NotificationSinks lists the channels notifications are fanned out
+	// to, each with its own minimum level. Empty sends them to the
+	// desktop only.
+	NotificationSinks []NotificationSink `yaml:"notification_sinks,omitempty"`
+	// AlertCooldown is the minimum number of minutes between two alerts
+	// of the same kind, e.g. reminders, on every sink; 0 turns it off.
+	AlertCooldown int `yaml:"alert_cooldown,omitempty"`
+
+	// RedSound plays when today's status enters red, for users in
+	// full-screen apps who miss the menu bar: RedSoundSystem for the
+	// platform's alert sound, or an absolute path to an audio file. Empty
+	// disables it; RedSoundMuted, toggled from the tray, silences it
+	// without losing the choice.
+	RedSound      string `yaml:"red_sound,omitempty"`
+	RedSoundMuted bool   `yaml:"red_sound_muted,omitempty"`
+
+	// TeamDir is a shared folder of teammates' `ccusage daily --json`
+	// exports (one <name>.json each); when set the tray shows a team total
+	// with a per-person submenu.
+	TeamDir string `yaml:"team_dir,omitempty"`
+
+	// ClaudeDataDir pins ccusage to one Claude config directory (passed as
+	// CLAUDE_CONFIG_DIR) when usage data exists in both ~/.config/claude
+	// and the legacy ~/.claude.
+	ClaudeDataDir string `yaml:"claude_data_dir,omitempty"`
+
+	// OTLPEndpoint is an OpenTelemetry collector's OTLP/HTTP base URL
+	// (e.g. http://localhost:4318); when set, every poll is exported as a
+	// trace.
+	OTLPEndpoint string `yaml:"otlp_endpoint,omitempty"`
+
+	// PollReliabilityWarning (percent) sends a warning notification when
+	// fewer than this share of the last day's polls succeeded; 0 disables.
+	PollReliabilityWarning int `yaml:"poll_reliability_warning,omitempty"`
+
+	// PollFailureAlert (minutes) sends a notification once polls have
+	// failed without a break for this long, and another when they work
+	// again; 0 disables.
+	PollFailureAlert int `yaml:"poll_failure_alert,omitempty"`
+
+	// LogOutputLength caps how many bytes of ccusage output a warning
+	// quotes when a run fails or can't be parsed; 0 uses the default of 128.
+	LogOutputLength int `yaml:"log_output_length,omitempty"`
+
+	// CCUsageDumpFile, when set, receives every raw ccusage response in
+	// full, with its command line and any stderr, for troubleshooting
+	// parser issues.
+	CCUsageDumpFile string `yaml:"ccusage_dump_file,omitempty"`
+
+	// RecordDir, when set, saves each distinct raw daily report ccusage
+	// returns there, named by its arrival time, for `run --replay`.
+	RecordDir string `yaml:"record_dir,omitempty"`
+
+	// LedgerCSV and LedgerWebhook keep a spreadsheet ledger: after each day
+	// ends, its totals are appended as a row to the CSV file (e.g. in a
+	// synced folder) and/or posted as JSON to the webhook.
+	LedgerCSV     string `yaml:"ledger_csv,omitempty"`
+	LedgerWebhook string `yaml:"ledger_webhook,omitempty"`
+
+	// HTTPListen, when set, serves the status for editor plugins over HTTP
+	// on this loopback host:port (e.g. 127.0.0.1:7399).
+	HTTPListen string `yaml:"http_listen,omitempty"`
+
+	// HTTPToken, when set, must accompany every HTTP API request, as a
+	// bearer token or via the dashboard's cookie, so other users on a
+	// shared machine can't read spend data over loopback.
+	HTTPToken string `yaml:"http_token,omitempty"`
+
+	// HTTPPprof serves Go's runtime profiles under /debug/pprof/ on the
+	// HTTP API, for chasing a leak the Diagnostics item reports. It needs
+	// HTTPToken, since profiles expose the process's memory.
+	HTTPPprof bool `yaml:"http_pprof,omitempty"`
+
+	// TelegramBotToken and TelegramChatID, set together, send yellow and
+	// red alerts and each finished day's summary to a Telegram chat, for
+	// watching an unattended agent from a phone.
+	TelegramBotToken string `yaml:"telegram_bot_token,omitempty"`
+	TelegramChatID   string `yaml:"telegram_chat_id,omitempty"`
+
+	// StatusSymbols replaces the 🟢🟡🔴⚪️ status dots in the title and
+	// menu, e.g. with ASCII.
+	StatusSymbols StatusSymbols `yaml:"status_symbols,omitempty"`
+	// StatusPalette swaps the dots for shapes or blue and orange, which
+	// stay distinguishable with color blindness; status_symbols entries
+	// still win.
+	StatusPalette StatusPalette `yaml:"status_palette,omitempty"`
+
+	// Display formatting. Nil precisions fall back to DefaultCostPrecision;
+	// TitleCostPrecision lets the menu bar show whole dollars while the
+	// detail menu keeps cents.
+	CostPrecision      *int         `yaml:"cost_precision,omitempty"`
+	TitleCostPrecision *int         `yaml:"title_cost_precision,omitempty"`
+	CostRounding       RoundingMode `yaml:"cost_rounding,omitempty"`
+	TitleMode          TitleMode    `yaml:"title_mode,omitempty"`    // "full" (default) or "compact", the status symbol alone
+	TitleDisplay       TitleDisplay `yaml:"title_display,omitempty"` // Figure after the status: cost (default), tokens, percent, or none
+	// TitleMaxWidth truncates longer titles to this many display cells,
+	// counting wide CJK characters and emoji as two; 0 means no limit.
+	TitleMaxWidth   int             `yaml:"title_max_width,omitempty"`
+	TitleTruncation TitleTruncation `yaml:"title_truncation,omitempty"` // How: end (default), middle, or segments
+	// ActivityIndicator ends the title with ● while Claude Code is
+	// generating and ○ otherwise, to see at a glance if an agent is busy.
+	ActivityIndicator bool `yaml:"activity_indicator,omitempty"`
+	// DisplayFormat replaces the title with a template over TemplateData,
+	// or a preset named "@name" from DisplayPresets or DisplayTemplates.
+	DisplayFormat    string            `yaml:"display_format,omitempty"`
+	DisplayTemplates map[string]string `yaml:"display_templates,omitempty"` // Custom presets, by name
+
+	// Locale formats numbers, dates, and times for a language and region:
+	// a BCP 47 tag such as "de-DE", or "auto" to follow the environment.
+	// Empty keeps "$1234.56", ISO dates, and 24-hour times.
+	Locale string `yaml:"locale,omitempty"`
 }
 
 // ConfigDefaults returns a Config struct with default values
@@ -53,6 +217,94 @@ func (c *Config) Validate() error {
 	if c.RedThreshold <= c.YellowThreshold {
 		return lib.ValidationError("red_threshold must be greater than yellow_threshold")
 	}
+	if c.WeeklyBudget < 0 {
+		return lib.ValidationError("weekly_budget must not be negative")
+	}
+	if c.MonthlyBudget < 0 {
+		return lib.ValidationError("monthly_budget must not be negative")
+	}
+	if c.SessionCap < 0 {
+		return lib.ValidationError("session_cap must not be negative")
+	}
+	if err := ValidateAlertLevels(c.AlertLevels); err != nil {
+		return err
+	}
+	if err := ValidateProjectTags(c.ProjectTags); err != nil {
+		return err
+	}
+	if err := ValidateDayThresholds(c.DayThresholds); err != nil {
+		return err
+	}
+	if err := ValidateModelThresholds(c.ModelThresholds); err != nil {
+		return err
+	}
+	if _, err := ParseQuietUntil(c.QuietUntil); err != nil {
+		return err
+	}
+	if _, err := ParseQuietHours(c.QuietHours); err != nil {
+		return err
+	}
+	if err := c.StatusNotifications.Yellow.Validate("status_notifications.yellow"); err != nil {
+		return err
+	}
+	if err := c.StatusNotifications.Red.Validate("status_notifications.red"); err != nil {
+		return err
+	}
+	if !IsValidFocusPolicy(c.FocusNotifications) {
+		return lib.ValidationError("focus_notifications must be one of: defer, suppress, send")
+	}
+	if c.AlertCooldown < 0 || c.AlertCooldown > MaxAlertCooldown {
+		return lib.ValidationError(fmt.Sprintf("alert_cooldown must be between 0 and %d minutes", MaxAlertCooldown))
+	}
+	for i, sink := range c.NotificationSinks {
+		if err := sink.Validate(i, c); err != nil {
+			return err
+		}
+	}
+	if c.RedSound != "" && c.RedSound != RedSoundSystem && !filepath.IsAbs(c.RedSound) {
+		return lib.ValidationError(`red_sound must be "system" or an absolute path to an audio file`)
+	}
+	if c.PollReliabilityWarning < 0 || c.PollReliabilityWarning > 100 {
+		return lib.ValidationError("poll_reliability_warning must be between 0 and 100")
+	}
+	if c.PollFailureAlert < 0 || c.PollFailureAlert > MaxPollFailureAlert {
+		return lib.ValidationError(fmt.Sprintf("poll_failure_alert must be between 0 and %d minutes", MaxPollFailureAlert))
+	}
+	if c.LogOutputLength < 0 {
+		return lib.ValidationError("log_output_length must be positive")
+	}
+	if c.OTLPEndpoint != "" {
+		u, err := url.Parse(c.OTLPEndpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return lib.ValidationError("otlp_endpoint must be an http(s) URL such as http://localhost:4318")
+		}
+	}
+	// Encrypted values are checked once ConfigService has decrypted them.
+	if c.LedgerWebhook != "" && !IsSecretRef(c.LedgerWebhook) {
+		u, err := url.Parse(c.LedgerWebhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return lib.ValidationError("ledger_webhook must be an http(s) URL")
+		}
+	}
+
+	if c.HTTPListen != "" && !IsLoopbackAddr(c.HTTPListen) {
+		return lib.ValidationError("http_listen must be a loopback host:port such as 127.0.0.1:7399")
+	}
+	if c.HTTPToken != "" && len(c.HTTPToken) < MinHTTPTokenLength {
+		return lib.ValidationError(fmt.Sprintf("http_token must be at least %d characters", MinHTTPTokenLength))
+	}
+	if c.HTTPPprof && (c.HTTPListen == "" || c.HTTPToken == "") {
+		return lib.ValidationError("http_pprof needs http_listen and http_token")
+	}
+	if (c.TelegramBotToken == "") != (c.TelegramChatID == "") {
+		return lib.ValidationError("telegram_bot_token and telegram_chat_id must be set together")
+	}
+	if c.TelegramBotToken != "" && !IsSecretRef(c.TelegramBotToken) && !telegramTokenPattern.MatchString(c.TelegramBotToken) {
+		return lib.ValidationError("telegram_bot_token must look like 123456:ABC-DEF… as given by @BotFather")
+	}
+	if c.TelegramChatID != "" && !telegramChatPattern.MatchString(c.TelegramChatID) {
+		return lib.ValidationError("telegram_chat_id must be a numeric chat id or an @channel name")
+	}
 
 	// Validate debug level
 	validLevels := []string{"DEBUG", "INFO", "WARN", "ERROR", "FATAL"}
@@ -78,9 +330,151 @@ func (c *Config) Validate() error {
 		return lib.ValidationError("cmd_timeout must be between 1 and 60 seconds")
 	}
 
+	// Validate cost formatting
+	if !validCostPrecision(c.CostPrecision) {
+		return lib.ValidationError("cost_precision must be between 0 and 4")
+	}
+	if !validCostPrecision(c.TitleCostPrecision) {
+		return lib.ValidationError("title_cost_precision must be between 0 and 4")
+	}
+	if !IsValidRoundingMode(c.CostRounding) {
+		return lib.ValidationError("cost_rounding must be one of: nearest, up, down")
+	}
+	if !IsValidTitleMode(c.TitleMode) {
+		return lib.ValidationError("title_mode must be one of: full, compact")
+	}
+	if !IsValidTitleDisplay(c.TitleDisplay) {
+		return lib.ValidationError("title_display must be one of: cost, tokens, percent, none")
+	}
+	if c.TitleMaxWidth != 0 && (c.TitleMaxWidth < MinTitleMaxWidth || c.TitleMaxWidth > MaxTitleMaxWidth) {
+		return lib.ValidationError(fmt.Sprintf("title_max_width must be 0 (no limit) or between %d and %d",
+			MinTitleMaxWidth, MaxTitleMaxWidth))
+	}
+	if !IsValidTitleTruncation(c.TitleTruncation) {
+		return lib.ValidationError("title_truncation must be one of: end, middle, segments")
+	}
+	if err := c.ValidateDisplayTemplates(); err != nil {
+		return err
+	}
+	if !IsValidStatusPalette(c.StatusPalette) {
+		return lib.ValidationError("status_palette must be one of: default, shapes, blue-orange")
+	}
+	if _, err := ParseLocale(c.Locale); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// ThresholdsFor returns the yellow/red thresholds in effect on day.
+func (c *Config) ThresholdsFor(day time.Weekday) (float64, float64) {
+	return ResolveDayThresholds(c.DayThresholds, day, c.YellowThreshold, c.RedThreshold)
+}
+
+// QuietHoursWindow returns the parsed quiet_hours window; invalid values,
+// which Validate rejects, read as disabled.
+func (c *Config) QuietHoursWindow() QuietHours {
+	q, _ := ParseQuietHours(c.QuietHours)
+	return q
+}
+
+// RedThresholdFor returns the cost at which status turns red on day: the
+// first red alert level when alert_levels is set, otherwise the red
+// threshold after day overrides. It reports false when no level is red.
+func (c *Config) RedThresholdFor(day time.Weekday) (float64, bool) {
+	if len(c.AlertLevels) > 0 {
+		for _, level := range c.AlertLevels {
+			if level.Status == Red {
+				return level.Threshold, true
+			}
+		}
+		return 0, false
+	}
+	_, red := c.ThresholdsFor(day)
+	return red, true
+}
+
+// QuietActive reports whether quiet mode covers now.
+func (c *Config) QuietActive(now time.Time) bool {
+	return QuietActive(c.QuietUntil, now)
+}
+
+// MaxPollFailureAlert caps poll_failure_alert at a day.
+const MaxPollFailureAlert = 1440
+
+// MinHTTPTokenLength keeps http_token long enough not to be guessed.
+const MinHTTPTokenLength = 16
+
+// Telegram bot tokens are "<bot id>:<secret>"; chats are numeric ids,
+// negative for groups, or a public channel's @name.
+var (
+	telegramTokenPattern = regexp.MustCompile(`^[0-9]+:[A-Za-z0-9_-]+$`)
+	telegramChatPattern  = regexp.MustCompile(`^(-?[0-9]+|@[A-Za-z0-9_]{5,})$`)
+)
+
+// IsLoopbackAddr reports whether hostport is a host:port on the loopback
+// interface: localhost, 127.0.0.0/8, or ::1.
+func IsLoopbackAddr(hostport string) bool {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil || port == "" {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func validCostPrecision(p *int) bool {
+	return p == nil || (*p >= MinCostPrecision && *p <= MaxCostPrecision)
+}
+
+// CostFormat returns the formatting used for costs in menus and notifications
+func (c *Config) CostFormat() CostFormat {
+	format := DefaultCostFormat()
+	if c.CostPrecision != nil {
+		format.Precision = *c.CostPrecision
+	}
+	if c.CostRounding != "" {
+		format.Rounding = c.CostRounding
+	}
+	format.Locale = c.DisplayLocale()
+	return format
+}
+
+// Symbols returns the status symbols to show: the status_symbols entries
+// over the status_palette's.
+func (c *Config) Symbols() StatusSymbols {
+	return c.StatusSymbols.WithPalette(c.StatusPalette)
+}
+
+// DisplayLocale returns the locale numbers, dates, and times are shown in,
+// or nil for the locale-neutral formats. Every cost formatted asks for it,
+// so each setting is parsed once, and LocaleAuto reads the environment
+// only the first time.
+func (c *Config) DisplayLocale() *Locale {
+	if cached, ok := displayLocales.Load(c.Locale); ok {
+		return cached.(*Locale)
+	}
+	locale, _ := ParseLocale(c.Locale) // Validate rejects bad tags
+	cached, _ := displayLocales.LoadOrStore(c.Locale, locale)
+	return cached.(*Locale)
+}
+
+// displayLocales holds DisplayLocale's *Locale for each locale setting.
+var displayLocales sync.Map
+
+// TitleCostFormat returns the formatting used for the menu bar title,
+// falling back to CostFormat when no title-specific precision is set
+func (c *Config) TitleCostFormat() CostFormat {
+	format := c.CostFormat()
+	if c.TitleCostPrecision != nil {
+		format.Precision = *c.TitleCostPrecision
+	}
+	return format
+}
+
 // GetLogLevel converts the debug level string to a LogLevel enum
 // Returns INFO level if the string is invalid
 func (c *Config) GetLogLevel() int {
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigChange is one setting that differs between two configs, keyed by
+// its YAML name.
+type ConfigChange struct {
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+// String renders the change as "field: old -> new", or just "field
+// changed" for settings that may hold credentials.
+func (c ConfigChange) String() string {
+	if sensitiveKeys[c.Field] {
+		return c.Field + " changed"
+	}
+	return fmt.Sprintf("%s: %v -> %v", c.Field, displayValue(c.Old), displayValue(c.New))
+}
+
+func displayValue(v interface{}) interface{} {
+	if v == nil {
+		return "(unset)"
+	}
+	return v
+}
+
+// DiffConfig lists the settings that differ between old and new, sorted by
+// field name. Fields are compared through their YAML form so the names
+// match the config file.
+func DiffConfig(old, new *Config) ([]ConfigChange, error) {
+	oldFields, err := configFields(old)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := configFields(new)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]struct{}, len(oldFields)+len(newFields))
+	for name := range oldFields {
+		names[name] = struct{}{}
+	}
+	for name := range newFields {
+		names[name] = struct{}{}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var changes []ConfigChange
+	for _, name := range sorted {
+		if !reflect.DeepEqual(oldFields[name], newFields[name]) {
+			changes = append(changes, ConfigChange{Field: name, Old: oldFields[name], New: newFields[name]})
+		}
+	}
+	return changes, nil
+}
+
+func configFields(c *Config) (map[string]interface{}, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
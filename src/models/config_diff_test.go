@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffConfig(t *testing.T) {
+	old := ConfigDefaults()
+	updated := ConfigDefaults()
+
+	changes, err := DiffConfig(old, updated)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	updated.RedThreshold = 30
+	updated.UpdateInterval = 60
+	updated.QuietUntil = "2025-03-21"
+
+	changes, err = DiffConfig(old, updated)
+	require.NoError(t, err)
+
+	rendered := make([]string, 0, len(changes))
+	for _, change := range changes {
+		rendered = append(rendered, change.String())
+	}
+	assert.Equal(t, []string{
+		"quiet_until: (unset) -> 2025-03-21",
+		"red_threshold: 20 -> 30",
+		"update_interval: 30 -> 60",
+	}, rendered)
+}
+
+func TestConfigChangeHidesSensitiveValues(t *testing.T) {
+	old := ConfigDefaults()
+	updated := ConfigDefaults()
+	updated.HTTPToken = "0123456789abcdef"
+
+	changes, err := DiffConfig(old, updated)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "http_token changed", changes[0].String())
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	_ "embed"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"cc-dailyuse-bar/src/lib"
+)
+
+// configSchemaJSON is the JSON Schema for config.yaml. Editors using
+// yaml-language-server can pick it up via a
+// "# yaml-language-server: $schema=<path>" comment at the top of the file.
+//
+//go:embed config.schema.json
+var configSchemaJSON []byte
+
+var (
+	configSchemaOnce sync.Once
+	configSchema     *lib.Schema
+	configSchemaErr  error
+)
+
+// ConfigSchema returns the embedded JSON Schema document.
+func ConfigSchema() []byte {
+	return configSchemaJSON
+}
+
+// ValidateConfigYAML checks a config file's contents against the schema,
+// returning every violation with its line and column. When the structure is
+// valid it also applies Config.Validate, whose cross-field rules (e.g. red
+// above yellow) the schema can't express; that failure is returned as err.
+func ValidateConfigYAML(data []byte) ([]lib.SchemaViolation, error) {
+	configSchemaOnce.Do(func() {
+		configSchema, configSchemaErr = lib.ParseSchema(configSchemaJSON)
+	})
+	if configSchemaErr != nil {
+		return nil, configSchemaErr
+	}
+
+	violations, err := configSchema.ValidateYAML(data)
+	if err != nil || len(violations) > 0 {
+		return violations, err
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeConfig, "failed to decode config")
+	}
+	return nil, config.Validate()
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// TestConfigSchema_CoversConfig keeps the schema in step with Config: every
+// yaml field must be described, and nothing else.
+func TestConfigSchema_CoversConfig(t *testing.T) {
+	var schema struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(ConfigSchema(), &schema))
+
+	var fields []string
+	typ := reflect.TypeOf(Config{})
+	for i := 0; i < typ.NumField(); i++ {
+		name := strings.Split(typ.Field(i).Tag.Get("yaml"), ",")[0]
+		fields = append(fields, name)
+		assert.Contains(t, schema.Properties, name, "schema is missing %s", name)
+	}
+	assert.Len(t, schema.Properties, len(fields))
+}
+
+func TestValidateConfigYAML(t *testing.T) {
+	defaults, err := yaml.Marshal(ConfigDefaults())
+	require.NoError(t, err)
+	violations, err := ValidateConfigYAML(defaults)
+	require.NoError(t, err)
+	assert.Empty(t, violations, "defaults must satisfy the schema")
+
+	full := string(defaults) + `alert_levels:
+  - {name: half, threshold: 10, status: yellow, notify: {remind_every: 30}}
+day_thresholds:
+  weekends: {yellow_threshold: 2, red_threshold: 5}
+model_thresholds:
+  opus: 10
+session_cap: 5
+project_tags:
+  - {path: ~/work/client-a, tag: client-A}
+quiet_until: 2025-03-20
+quiet_hours: 23:00-07:00
+status_notifications:
+  yellow: {}
+  red: {remind_every: 15}
+focus_notifications: suppress
+notification_sinks:
+  - type: desktop
+  - type: ntfy
+    url: https://ntfy.sh/claude-spend
+    min_level: red
+    max_per_hour: 4
+  - type: command
+    command: [/usr/local/bin/page-me, --loud]
+alert_cooldown: 30
+red_sound: system
+red_sound_muted: true
+claude_data_dir: ~/.claude
+otlp_endpoint: http://localhost:4318
+poll_reliability_warning: 90
+poll_failure_alert: 10
+log_output_length: 1024
+ccusage_dump_file: /tmp/ccusage-dump.log
+record_dir: /tmp/ccusage-recordings
+ledger_csv: /home/me/Sync/claude-ledger.csv
+ledger_webhook: https://script.google.com/macros/s/abc/exec
+http_listen: 127.0.0.1:7399
+http_token: 0123456789abcdef
+telegram_bot_token: "123456:ABC-DEF1234ghIkl"
+telegram_chat_id: "-1001234567890"
+title_mode: compact
+title_display: percent
+title_max_width: 24
+display_format: "@spend"
+display_templates:
+  spend: "{{.Symbol}} {{.Cost}} ({{.Percent}})"
+title_truncation: segments
+activity_indicator: true
+status_symbols:
+  green: "[OK]"
+  yellow: "[!]"
+  red: "[!!]"
+  unknown: "[?]"
+status_palette: shapes
+cost_precision: 0
+cost_rounding: up
+locale: de_DE.UTF-8
+`
+	violations, err = ValidateConfigYAML([]byte(full))
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	violations, err = ValidateConfigYAML([]byte("ccusage_path: ccusage\nupdate_interval: 5\ncost_rounding: sideways\nbogus: 1\n"))
+	require.NoError(t, err)
+	require.Len(t, violations, 3)
+	assert.Equal(t, "line 2, column 18: update_interval: must be >= 10", violations[0].String())
+	assert.Equal(t, "cost_rounding", violations[1].Path)
+	assert.Equal(t, `unknown field "bogus"`, violations[2].Message)
+
+	// Structurally valid but semantically wrong: falls through to Validate.
+	bad := strings.Replace(string(defaults), "red_threshold: 20", "red_threshold: 5", 1)
+	violations, err = ValidateConfigYAML([]byte(bad))
+	assert.Empty(t, violations)
+	assert.ErrorContains(t, err, "red_threshold must be greater than yellow_threshold")
+}
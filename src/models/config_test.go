@@ -1,6 +1,7 @@
 package models
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -295,3 +296,270 @@ func TestConfig_Validate_RealWorldScenarios(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_Validate_WeeklyBudget(t *testing.T) {
+	config := ConfigDefaults()
+	assert.NoError(t, config.Validate(), "zero disables the weekly budget")
+
+	config.WeeklyBudget = 75
+	assert.NoError(t, config.Validate())
+
+	config.WeeklyBudget = -1
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "weekly_budget must not be negative")
+}
+
+func TestConfig_Validate_MonthlyBudget(t *testing.T) {
+	config := ConfigDefaults()
+	config.MonthlyBudget = 400
+	assert.NoError(t, config.Validate())
+
+	config.MonthlyBudget = -1
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "monthly_budget must not be negative")
+}
+
+func TestConfig_Validate_SessionCap(t *testing.T) {
+	config := ConfigDefaults()
+	config.SessionCap = 5
+	assert.NoError(t, config.Validate())
+
+	config.SessionCap = -1
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "session_cap must not be negative")
+}
+
+func TestConfig_Validate_PollReliabilityWarning(t *testing.T) {
+	for _, percent := range []int{0, 90, 100} {
+		config := ConfigDefaults()
+		config.PollReliabilityWarning = percent
+		assert.NoError(t, config.Validate(), percent)
+	}
+	for _, percent := range []int{-1, 101} {
+		config := ConfigDefaults()
+		config.PollReliabilityWarning = percent
+		assert.ErrorContains(t, config.Validate(), "poll_reliability_warning must be between 0 and 100", percent)
+	}
+}
+
+func TestConfig_Validate_PollFailureAlert(t *testing.T) {
+	for _, minutes := range []int{0, 10, MaxPollFailureAlert} {
+		config := ConfigDefaults()
+		config.PollFailureAlert = minutes
+		assert.NoError(t, config.Validate(), minutes)
+	}
+	for _, minutes := range []int{-1, MaxPollFailureAlert + 1} {
+		config := ConfigDefaults()
+		config.PollFailureAlert = minutes
+		assert.ErrorContains(t, config.Validate(), "poll_failure_alert must be between 0 and 1440 minutes", minutes)
+	}
+}
+
+func TestConfig_Validate_LogOutputLength(t *testing.T) {
+	config := ConfigDefaults()
+	config.LogOutputLength = 4096
+	assert.NoError(t, config.Validate())
+
+	config.LogOutputLength = -1
+	assert.ErrorContains(t, config.Validate(), "log_output_length must be positive")
+}
+
+func TestConfig_Validate_OTLPEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		valid    bool
+	}{
+		{"", true},
+		{"http://localhost:4318", true},
+		{"https://otel.example.com/", true},
+		{"localhost:4318", false},
+		{"grpc://localhost:4317", false},
+		{"http://", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			config := ConfigDefaults()
+			config.OTLPEndpoint = tt.endpoint
+
+			err := config.Validate()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, "otlp_endpoint must be an http(s) URL")
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_HTTPListen(t *testing.T) {
+	tests := []struct {
+		addr  string
+		valid bool
+	}{
+		{"", true},
+		{"127.0.0.1:7399", true},
+		{"localhost:7399", true},
+		{"[::1]:7399", true},
+		{"0.0.0.0:7399", false},
+		{"192.168.1.5:7399", false},
+		{"127.0.0.1", false},
+		{":7399", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			config := ConfigDefaults()
+			config.HTTPListen = tt.addr
+
+			err := config.Validate()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, "http_listen must be a loopback host:port")
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_LedgerWebhook(t *testing.T) {
+	config := ConfigDefaults()
+	config.LedgerWebhook = "https://script.google.com/macros/s/abc/exec"
+	assert.NoError(t, config.Validate())
+
+	config.LedgerWebhook = "script.google.com/macros"
+	assert.ErrorContains(t, config.Validate(), "ledger_webhook must be an http(s) URL")
+}
+
+func TestConfig_Validate_HTTPToken(t *testing.T) {
+	config := ConfigDefaults()
+	config.HTTPToken = "0123456789abcdef"
+	assert.NoError(t, config.Validate())
+
+	config.HTTPToken = "short"
+	assert.ErrorContains(t, config.Validate(), "http_token must be at least 16 characters")
+}
+
+func TestConfig_Validate_HTTPPprof(t *testing.T) {
+	config := ConfigDefaults()
+	config.HTTPPprof = true
+	config.HTTPListen = "127.0.0.1:7399"
+	assert.ErrorContains(t, config.Validate(), "http_pprof needs http_listen and http_token")
+
+	config.HTTPToken = "0123456789abcdef"
+	assert.NoError(t, config.Validate())
+}
+
+func TestConfig_Validate_Telegram(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		chatID  string
+		wantErr string
+	}{
+		{"unset", "", "", ""},
+		{"user chat", "123456:ABC-DEF1234ghIkl", "987654321", ""},
+		{"group chat", "123456:ABC-DEF1234ghIkl", "-1001234567890", ""},
+		{"channel", "123456:ABC-DEF1234ghIkl", "@spend_alerts", ""},
+		{"token only", "123456:ABC-DEF1234ghIkl", "", "must be set together"},
+		{"chat only", "", "987654321", "must be set together"},
+		{"malformed token", "ABC-DEF1234ghIkl", "987654321", "telegram_bot_token must look like"},
+		{"malformed chat", "123456:ABC-DEF1234ghIkl", "spend alerts", "telegram_chat_id must be"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := ConfigDefaults()
+			config.TelegramBotToken, config.TelegramChatID = tt.token, tt.chatID
+			if tt.wantErr == "" {
+				assert.NoError(t, config.Validate())
+			} else {
+				assert.ErrorContains(t, config.Validate(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_TitleMode(t *testing.T) {
+	config := ConfigDefaults()
+	for _, mode := range []TitleMode{"", TitleModeFull, TitleModeCompact} {
+		config.TitleMode = mode
+		assert.NoError(t, config.Validate(), string(mode))
+	}
+	config.TitleMode = "tiny"
+	assert.ErrorContains(t, config.Validate(), "title_mode must be one of: full, compact")
+}
+
+func TestConfig_Validate_TitleDisplay(t *testing.T) {
+	config := ConfigDefaults()
+	config.TitleDisplay = TitleDisplayNone
+	assert.NoError(t, config.Validate())
+	config.TitleDisplay = "calls"
+	assert.ErrorContains(t, config.Validate(), "title_display must be one of")
+}
+
+func TestConfig_Validate_TitleMaxWidth(t *testing.T) {
+	config := ConfigDefaults()
+	for _, width := range []int{0, MinTitleMaxWidth, 24, MaxTitleMaxWidth} {
+		config.TitleMaxWidth = width
+		assert.NoError(t, config.Validate(), width)
+	}
+	for _, width := range []int{-1, 1, MaxTitleMaxWidth + 1} {
+		config.TitleMaxWidth = width
+		assert.ErrorContains(t, config.Validate(), "title_max_width must be 0 (no limit) or between 2 and 200", width)
+	}
+}
+
+func TestConfig_Validate_TitleTruncation(t *testing.T) {
+	config := ConfigDefaults()
+	for _, policy := range []TitleTruncation{"", TitleTruncationEnd, TitleTruncationMiddle, TitleTruncationSegments} {
+		config.TitleTruncation = policy
+		assert.NoError(t, config.Validate(), string(policy))
+	}
+	config.TitleTruncation = "start"
+	assert.ErrorContains(t, config.Validate(), "title_truncation must be one of: end, middle, segments")
+}
+
+func TestConfig_Validate_FocusNotifications(t *testing.T) {
+	config := ConfigDefaults()
+	for _, policy := range []FocusPolicy{"", FocusDefer, FocusSuppress, FocusSend} {
+		config.FocusNotifications = policy
+		assert.NoError(t, config.Validate(), string(policy))
+	}
+	config.FocusNotifications = "queue"
+	assert.ErrorContains(t, config.Validate(), "focus_notifications must be one of: defer, suppress, send")
+}
+
+func TestConfig_Validate_RedSound(t *testing.T) {
+	config := ConfigDefaults()
+	for _, sound := range []string{"", RedSoundSystem, filepath.Join(t.TempDir(), "alarm.wav")} {
+		config.RedSound = sound
+		assert.NoError(t, config.Validate(), sound)
+	}
+	for _, sound := range []string{"beep", "sounds/alarm.wav"} {
+		config.RedSound = sound
+		assert.ErrorContains(t, config.Validate(), `red_sound must be "system" or an absolute path to an audio file`, sound)
+	}
+}
+
+func TestConfig_Validate_StatusPalette(t *testing.T) {
+	config := ConfigDefaults()
+	for _, palette := range []StatusPalette{"", StatusPaletteDefault, StatusPaletteShapes, StatusPaletteBlueOrange} {
+		config.StatusPalette = palette
+		assert.NoError(t, config.Validate(), string(palette))
+	}
+	config.StatusPalette = "rainbow"
+	assert.ErrorContains(t, config.Validate(), "status_palette must be one of: default, shapes, blue-orange")
+}
+
+func TestConfig_Validate_Locale(t *testing.T) {
+	config := ConfigDefaults()
+	for _, locale := range []string{"", LocaleAuto, "de-DE", "en_US.UTF-8"} {
+		config.Locale = locale
+		assert.NoError(t, config.Validate(), locale)
+	}
+	config.Locale = "deutsch please"
+	assert.ErrorContains(t, config.Validate(), "not a language tag")
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+// CostComparison is a previous day's total that today's spend is compared
+// against in the menu.
+type CostComparison struct {
+	Date string  `json:"date"` // YYYY-MM-DD
+	Cost float64 `json:"cost"`
+}
+
+// PercentChange returns how much current differs from the comparison as a
+// percentage of it. It reports false when there is no baseline to divide by.
+func (c *CostComparison) PercentChange(current float64) (float64, bool) {
+	if c == nil || c.Cost <= 0 {
+		return 0, false
+	}
+	return (current - c.Cost) / c.Cost * 100, true
+}
+
+// FormatPercentChange renders a change as "▲ +32%", "▼ −10%" or "= 0%",
+// using a real minus sign so the sign reads clearly in menus.
+func FormatPercentChange(pct float64) string {
+	rounded := math.Round(pct)
+	switch {
+	case rounded > 0:
+		return fmt.Sprintf("▲ +%.0f%%", rounded)
+	case rounded < 0:
+		return fmt.Sprintf("▼ −%.0f%%", -rounded)
+	default:
+		return "= 0%"
+	}
+}
+
+// ComparisonLines renders today's spend relative to yesterday and the same
+// weekday last week, e.g. "vs yesterday: ▲ +32%", skipping days ccusage has
+// no entry for.
+func (u *UsageState) ComparisonLines() []string {
+	var lines []string
+	for _, c := range []struct {
+		label string
+		prev  *CostComparison
+	}{
+		{"vs yesterday", u.Yesterday},
+		{"vs same day last week", u.LastWeek},
+	} {
+		if c.prev == nil {
+			continue
+		}
+		if pct, ok := c.prev.PercentChange(u.DailyCost); ok {
+			lines = append(lines, fmt.Sprintf("%s: %s", c.label, FormatPercentChange(pct)))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s: no spend then", c.label))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCostComparison_PercentChange(t *testing.T) {
+	c := &CostComparison{Date: "2025-03-11", Cost: 10}
+	pct, ok := c.PercentChange(13.2)
+	assert.True(t, ok)
+	assert.InDelta(t, 32, pct, 1e-9)
+
+	_, ok = (&CostComparison{Cost: 0}).PercentChange(5)
+	assert.False(t, ok, "no baseline to compare against")
+	_, ok = (*CostComparison)(nil).PercentChange(5)
+	assert.False(t, ok)
+}
+
+func TestFormatPercentChange(t *testing.T) {
+	tests := []struct {
+		pct  float64
+		want string
+	}{
+		{32, "▲ +32%"},
+		{-10.4, "▼ −10%"},
+		{0.3, "= 0%"},
+		{-0.4, "= 0%"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, FormatPercentChange(tt.pct))
+	}
+}
+
+func TestUsageState_ComparisonLines(t *testing.T) {
+	state := &UsageState{
+		DailyCost: 13.2,
+		Yesterday: &CostComparison{Date: "2025-03-13", Cost: 10},
+		LastWeek:  &CostComparison{Date: "2025-03-07", Cost: 0},
+	}
+	assert.Equal(t, []string{
+		"vs yesterday: ▲ +32%",
+		"vs same day last week: no spend then",
+	}, state.ComparisonLines())
+
+	state.DailyCost = 9
+	state.LastWeek = nil
+	assert.Equal(t, []string{"vs yesterday: ▼ −10%"}, state.ComparisonLines())
+	assert.Empty(t, (&UsageState{}).ComparisonLines())
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"math"
+)
+
+// RoundingMode controls how costs are rounded to the configured precision.
+type RoundingMode string
+
+// Supported rounding modes.
+const (
+	RoundNearest RoundingMode = "nearest" // Half away from zero
+	RoundUp      RoundingMode = "up"      // Toward +Inf (never under-reports spend)
+	RoundDown    RoundingMode = "down"    // Toward -Inf
+)
+
+// Cost precision bounds and default.
+const (
+	MinCostPrecision     = 0
+	MaxCostPrecision     = 4
+	DefaultCostPrecision = 2
+)
+
+// roundingEpsilon absorbs binary floating point noise (1.10*100 is
+// 110.00000000000001) so up/down rounding doesn't skip a step.
+const roundingEpsilon = 1e-9
+
+// CostFormat describes how a dollar amount is rendered for display.
+type CostFormat struct {
+	Precision int
+	Rounding  RoundingMode
+	Locale    *Locale // nil for "$1234.56" whatever the environment
+}
+
+// DefaultCostFormat returns the historical "$12.34" formatting.
+func DefaultCostFormat() CostFormat {
+	return CostFormat{Precision: DefaultCostPrecision, Rounding: RoundNearest}
+}
+
+// IsValidRoundingMode reports whether mode is one of the supported values.
+// The empty string is accepted and treated as RoundNearest.
+func IsValidRoundingMode(mode RoundingMode) bool {
+	switch mode {
+	case "", RoundNearest, RoundUp, RoundDown:
+		return true
+	default:
+		return false
+	}
+}
+
+// Round applies the rounding mode at the configured precision.
+func (f CostFormat) Round(cost float64) float64 {
+	scale := math.Pow(10, float64(f.Precision))
+	switch f.Rounding {
+	case RoundUp:
+		return math.Ceil(cost*scale-roundingEpsilon) / scale
+	case RoundDown:
+		return math.Floor(cost*scale+roundingEpsilon) / scale
+	default:
+		return math.Round(cost*scale) / scale
+	}
+}
+
+// Format renders cost as a dollar string, e.g. "$12.34" or "$13", or
+// "12,34 $" with a German Locale.
+func (f CostFormat) Format(cost float64) string {
+	return f.Locale.FormatCost(f.Round(cost), f.Precision)
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCostFormat_Format(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   CostFormat
+		cost     float64
+		expected string
+	}{
+		{"default", DefaultCostFormat(), 12.345, "$12.35"},
+		{"default zero", DefaultCostFormat(), 0, "$0.00"},
+		{"whole dollars nearest", CostFormat{Precision: 0, Rounding: RoundNearest}, 12.5, "$13"},
+		{"whole dollars down", CostFormat{Precision: 0, Rounding: RoundDown}, 12.99, "$12"},
+		{"whole dollars up", CostFormat{Precision: 0, Rounding: RoundUp}, 12.01, "$13"},
+		{"up exact value stays", CostFormat{Precision: 2, Rounding: RoundUp}, 1.10, "$1.10"},
+		{"down exact value stays", CostFormat{Precision: 2, Rounding: RoundDown}, 0.29, "$0.29"},
+		{"four decimals", CostFormat{Precision: 4, Rounding: RoundNearest}, 1.23456, "$1.2346"},
+		{"one decimal up", CostFormat{Precision: 1, Rounding: RoundUp}, 3.01, "$3.1"},
+		{"empty mode is nearest", CostFormat{Precision: 1}, 3.04, "$3.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.format.Format(tt.cost))
+		})
+	}
+}
+
+func TestIsValidRoundingMode(t *testing.T) {
+	assert.True(t, IsValidRoundingMode(""))
+	assert.True(t, IsValidRoundingMode(RoundNearest))
+	assert.True(t, IsValidRoundingMode(RoundUp))
+	assert.True(t, IsValidRoundingMode(RoundDown))
+	assert.False(t, IsValidRoundingMode("bankers"))
+}
+
+func TestConfig_CostFormats(t *testing.T) {
+	config := ConfigDefaults()
+	assert.Equal(t, DefaultCostFormat(), config.CostFormat())
+	assert.Equal(t, DefaultCostFormat(), config.TitleCostFormat())
+
+	detail, title := 3, 0
+	config.CostPrecision = &detail
+	config.TitleCostPrecision = &title
+	config.CostRounding = RoundDown
+
+	assert.Equal(t, CostFormat{Precision: 3, Rounding: RoundDown}, config.CostFormat())
+	assert.Equal(t, CostFormat{Precision: 0, Rounding: RoundDown}, config.TitleCostFormat())
+
+	config.Locale = "de-DE"
+	assert.Equal(t, "1.234,567\u00a0$", config.CostFormat().Format(1234.5678))
+	assert.Equal(t, "1.234\u00a0$", config.TitleCostFormat().Format(1234.5678))
+	assert.Same(t, config.DisplayLocale(), config.Clone().DisplayLocale(), "each setting is parsed once")
+}
+
+func TestConfig_Validate_CostFormatting(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	tests := []struct {
+		name     string
+		mutate   func(*Config)
+		expected string
+	}{
+		{"precision too high", func(c *Config) { c.CostPrecision = intPtr(5) }, "cost_precision must be between 0 and 4"},
+		{"precision negative", func(c *Config) { c.CostPrecision = intPtr(-1) }, "cost_precision must be between 0 and 4"},
+		{"title precision too high", func(c *Config) { c.TitleCostPrecision = intPtr(9) }, "title_cost_precision must be between 0 and 4"},
+		{"bad rounding", func(c *Config) { c.CostRounding = "sideways" }, "cost_rounding must be one of"},
+		{"valid", func(c *Config) { c.CostPrecision = intPtr(0); c.CostRounding = RoundUp }, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := ConfigDefaults()
+			tt.mutate(config)
+			err := config.Validate()
+			if tt.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+)
+
+// ThresholdPair overrides the yellow/red thresholds for a day of the week.
+type ThresholdPair struct {
+	YellowThreshold float64 `yaml:"yellow_threshold" json:"yellow_threshold"`
+	RedThreshold    float64 `yaml:"red_threshold" json:"red_threshold"`
+}
+
+// Group keys accepted in day_thresholds alongside individual day names.
+const (
+	DayGroupWeekdays = "weekdays"
+	DayGroupWeekends = "weekends"
+)
+
+// dayThresholdKey normalises a day_thresholds key ("Saturday", "sat ") to
+// the lowercase full day name or group name, reporting whether it is valid.
+func dayThresholdKey(key string) (string, bool) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if key == DayGroupWeekdays || key == DayGroupWeekends {
+		return key, true
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if key == name || key == name[:3] {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// ResolveDayThresholds returns the thresholds in effect on day. A specific
+// day ("saturday") wins over its group ("weekends"), which wins over the
+// base pair.
+func ResolveDayThresholds(overrides map[string]ThresholdPair, day time.Weekday, yellow, red float64) (float64, float64) {
+	if len(overrides) == 0 {
+		return yellow, red
+	}
+
+	group := DayGroupWeekdays
+	if day == time.Saturday || day == time.Sunday {
+		group = DayGroupWeekends
+	}
+	dayName := strings.ToLower(day.String())
+
+	var groupMatch *ThresholdPair
+	for key, pair := range overrides {
+		name, ok := dayThresholdKey(key)
+		if !ok {
+			continue
+		}
+		if name == dayName {
+			return pair.YellowThreshold, pair.RedThreshold
+		}
+		if name == group {
+			p := pair
+			groupMatch = &p
+		}
+	}
+
+	if groupMatch != nil {
+		return groupMatch.YellowThreshold, groupMatch.RedThreshold
+	}
+	return yellow, red
+}
+
+// ValidateDayThresholds checks day names and that each pair is well ordered.
+func ValidateDayThresholds(overrides map[string]ThresholdPair) error {
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys) // deterministic error for the first bad entry
+
+	seen := make(map[string]string, len(keys))
+	for _, key := range keys {
+		name, ok := dayThresholdKey(key)
+		if !ok {
+			return lib.ValidationError(fmt.Sprintf("day_thresholds: unknown day %q (use monday..sunday, weekdays, or weekends)", key))
+		}
+		if prev, dup := seen[name]; dup {
+			return lib.ValidationError(fmt.Sprintf("day_thresholds: %q and %q refer to the same day", prev, key))
+		}
+		seen[name] = key
+		pair := overrides[key]
+		if pair.YellowThreshold < 0 || pair.RedThreshold < 0 {
+			return lib.ValidationError(fmt.Sprintf("day_thresholds.%s: thresholds must be positive", key))
+		}
+		if pair.RedThreshold <= pair.YellowThreshold {
+			return lib.ValidationError(fmt.Sprintf("day_thresholds.%s: red_threshold must be greater than yellow_threshold", key))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveDayThresholds(t *testing.T) {
+	overrides := map[string]ThresholdPair{
+		"Weekends": {YellowThreshold: 2, RedThreshold: 5},
+		"sat":      {YellowThreshold: 1, RedThreshold: 3},
+		"friday":   {YellowThreshold: 15, RedThreshold: 30},
+	}
+
+	tests := []struct {
+		name       string
+		day        time.Weekday
+		wantYellow float64
+		wantRed    float64
+	}{
+		{"specific day beats group", time.Saturday, 1, 3},
+		{"group applies", time.Sunday, 2, 5},
+		{"weekday override", time.Friday, 15, 30},
+		{"falls back to base", time.Monday, 10, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yellow, red := ResolveDayThresholds(overrides, tt.day, 10, 20)
+			assert.Equal(t, tt.wantYellow, yellow)
+			assert.Equal(t, tt.wantRed, red)
+		})
+	}
+
+	yellow, red := ResolveDayThresholds(nil, time.Saturday, 10, 20)
+	assert.Equal(t, 10.0, yellow)
+	assert.Equal(t, 20.0, red)
+}
+
+func TestValidateDayThresholds(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]ThresholdPair
+		wantErr   string
+	}{
+		{"nil", nil, ""},
+		{"valid", map[string]ThresholdPair{"weekdays": {10, 20}, "Sun": {1, 2}}, ""},
+		{"unknown day", map[string]ThresholdPair{"funday": {1, 2}}, "unknown day"},
+		{"duplicate day", map[string]ThresholdPair{"sat": {1, 2}, "saturday": {1, 2}}, "same day"},
+		{"negative", map[string]ThresholdPair{"monday": {-1, 2}}, "must be positive"},
+		{"misordered", map[string]ThresholdPair{"monday": {5, 5}}, "greater than"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDayThresholds(tt.overrides)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestUsageState_UpdateStatusFromConfig_DayThresholds(t *testing.T) {
+	config := ConfigDefaults()
+	config.DayThresholds = map[string]ThresholdPair{"weekends": {YellowThreshold: 2, RedThreshold: 5}}
+
+	state := &UsageState{DailyCost: 3, LastUpdate: time.Date(2025, 3, 15, 9, 0, 0, 0, time.Local)}
+	state.UpdateStatusFromConfig(config)
+	assert.Equal(t, Yellow, state.Status)
+
+	state.LastUpdate = time.Date(2025, 3, 17, 9, 0, 0, 0, time.Local)
+	state.UpdateStatusFromConfig(config)
+	assert.Equal(t, Green, state.Status)
+}
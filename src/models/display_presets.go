@@ -0,0 +1,132 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"cc-dailyuse-bar/src/lib"
+)
+
+// DisplayPresets are the built-in display_format templates, chosen with
+// "@name", e.g. display_format: "@percent".
+var DisplayPresets = map[string]string{
+	"minimal":  "{{.Symbol}} {{.Cost}}",
+	"detailed": "CC {{.Symbol}} {{.Cost}} · {{.Tokens}} · {{.Calls}} calls",
+	"percent":  "CC {{.Symbol}} {{.Percent}}",
+	"tokens":   "CC {{.Symbol}} {{.Tokens}}",
+}
+
+var displayTemplateName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// displayEngineCache holds the engine for the latest display settings, so
+// the title's parsed template survives between renders. Its key covers
+// everything the engine is built from; a config change that alters any of
+// it gets a fresh engine.
+var displayEngineCache struct {
+	sync.Mutex
+	key    string
+	engine *lib.TemplateEngine
+}
+
+// DisplayTemplateEngine returns a template engine that resolves the
+// built-in presets and the config's display_templates by name, with the
+// status and threshold helpers.
+func (c *Config) DisplayTemplateEngine() *lib.TemplateEngine {
+	key := c.displayEngineKey()
+	displayEngineCache.Lock()
+	defer displayEngineCache.Unlock()
+	if displayEngineCache.engine != nil && displayEngineCache.key == key {
+		return displayEngineCache.engine
+	}
+
+	engine := lib.NewTemplateEngine()
+	engine.RegisterFuncs(displayTemplateFuncs(c))
+	for name, tmpl := range DisplayPresets {
+		engine.RegisterPreset(name, tmpl)
+	}
+	for name, tmpl := range c.DisplayTemplates {
+		engine.RegisterPreset(name, tmpl)
+	}
+	displayEngineCache.key, displayEngineCache.engine = key, engine
+	return engine
+}
+
+// displayEngineKey identifies the settings DisplayTemplateEngine's result
+// depends on: the custom presets and what colorize prints.
+func (c *Config) displayEngineKey() string {
+	names := make([]string, 0, len(c.DisplayTemplates))
+	for name := range c.DisplayTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%t %q", c.ScreenReader, c.Symbols())
+	for _, name := range names {
+		fmt.Fprintf(&b, " %q=%q", name, c.DisplayTemplates[name])
+	}
+	return b.String()
+}
+
+// ValidateDisplayTemplates checks display_templates and display_format:
+// names are lowercase and don't reuse a built-in preset's, and every
+// template parses and only uses fields TemplateData has.
+func (c *Config) ValidateDisplayTemplates() error {
+	for name, tmpl := range c.DisplayTemplates {
+		if !displayTemplateName.MatchString(name) {
+			return lib.ValidationError(fmt.Sprintf("display_templates name %q must be lowercase letters, digits, - and _", name))
+		}
+		if _, ok := DisplayPresets[name]; ok {
+			return lib.ValidationError(fmt.Sprintf("display_templates name %q is a built-in preset; pick another name", name))
+		}
+		if strings.HasPrefix(tmpl, lib.PresetPrefix) {
+			return lib.ValidationError(fmt.Sprintf("display_templates %q must be a template, not a preset name", name))
+		}
+		if err := checkDisplayTemplate(c, tmpl); err != nil {
+			return lib.WrapError(err, lib.ErrCodeValidation, fmt.Sprintf("display_templates %q is invalid", name))
+		}
+	}
+	if c.DisplayFormat != "" {
+		if err := checkDisplayTemplate(c, c.DisplayFormat); err != nil {
+			return lib.WrapError(err, lib.ErrCodeValidation, "display_format is invalid")
+		}
+	}
+	return nil
+}
+
+// checkDisplayTemplate parses tmpl, or the preset it names, and renders it
+// strictly for each status, so a misspelt field is an error here rather
+// than "<no value>" in the menu bar.
+func checkDisplayTemplate(c *Config, tmpl string) error {
+	engine := c.DisplayTemplateEngine()
+	if err := engine.Validate(tmpl); err != nil {
+		return err
+	}
+	for _, status := range []AlertStatus{Green, Yellow, Red, Unknown} {
+		if err := engine.ValidateStrict(tmpl, sampleTemplateData(status, c)); err != nil {
+			return lib.WrapError(err, lib.ErrCodeTemplate, "fields are "+strings.Join(TemplateFields(), ", "))
+		}
+	}
+	return nil
+}
+
+// displayFormatTitle renders display_format for the title, with symbol as
+// {{.Symbol}} and the costs in title_cost_precision. ok is false when the
+// template fails, so the built-in title is shown instead.
+func displayFormatTitle(state *UsageState, config *Config, symbol string) (string, bool) {
+	data := NewTemplateDataForConfig(state, config)
+	data.Symbol = symbol
+	format := config.TitleCostFormat()
+	data.Cost = format.Format(state.DailyCost)
+	data.WeekCost = format.Format(state.WeeklyCost)
+	data.MonthCost = format.Format(state.MonthlyCost)
+	data.MonthForecast = format.Format(state.Forecast)
+	text, err := config.DisplayTemplateEngine().Execute(config.DisplayFormat, data)
+	text = strings.Join(strings.Fields(text), " ")
+	if err != nil || text == "" {
+		return "", false
+	}
+	return text, true
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTitle_DisplayPresets(t *testing.T) {
+	config := ConfigDefaults()
+	state := &UsageState{DailyCost: 12.4, DailyCount: 1_234_000, DailyCalls: 37, Status: Yellow, IsAvailable: true}
+
+	tests := map[string]string{
+		"@minimal":  "🟡 $12.40",
+		"@detailed": "CC 🟡 $12.40 · 1.2M · 37 calls",
+		"@percent":  "CC 🟡 62%",
+		"@tokens":   "CC 🟡 1.2M",
+		"{{.Status}}: {{.Cost}} of {{.RemainingToRed}}": "High: $12.40 of $7.60",
+	}
+	for format, want := range tests {
+		config.DisplayFormat = format
+		assert.Equal(t, want, FormatTitle(state, config), format)
+	}
+}
+
+func TestFormatTitle_DisplayFormat(t *testing.T) {
+	config := ConfigDefaults()
+	config.DisplayTemplates = map[string]string{"spend": "{{.Symbol}} {{.Cost}} {{.Percent}}"}
+	config.DisplayFormat = "@spend"
+	state := &UsageState{DailyCost: 5, Status: Green, IsAvailable: true}
+	assert.Equal(t, "🟢 $5.00 25%", FormatTitle(state, config), "custom presets resolve by name")
+
+	config.ScreenReader = true
+	state.Paused = true
+	assert.Equal(t, "Paused $5.00 25%", FormatTitle(state, config), "the symbol follows screen_reader and quiet_hours")
+
+	config.ScreenReader = false
+	state.Paused = false
+	state.Demo = true
+	config.ActivityIndicator = true
+	assert.Equal(t, "DEMO 🟢 $5.00 25% ○", FormatTitle(state, config), "demo label and activity indicator stay")
+
+	state.Demo = false
+	config.ActivityIndicator = false
+	config.RedThreshold = 0
+	config.YellowThreshold = 0
+	assert.Equal(t, "🟢 $5.00", FormatTitle(state, config), "an empty field leaves no stray space")
+
+	config.DisplayFormat = "{{.Nope}}"
+	assert.Equal(t, "CC 🟢 $5.00", FormatTitle(state, config), "a failing template falls back to the built-in title")
+}
+
+func TestConfig_Validate_DisplayFormat(t *testing.T) {
+	config := ConfigDefaults()
+	for _, format := range []string{"", "@minimal", "@detailed", "@percent", "@tokens", "{{.Symbol}} {{.Models}}"} {
+		config.DisplayFormat = format
+		assert.NoError(t, config.Validate(), format)
+	}
+
+	config.DisplayFormat = "@fancy"
+	assert.ErrorContains(t, config.Validate(), `unknown template preset "@fancy" (available: @detailed, @minimal, @percent, @tokens)`)
+	config.DisplayFormat = "{{.Cost"
+	assert.ErrorContains(t, config.Validate(), "display_format is invalid")
+	config.DisplayFormat = "{{.Nope}}"
+	assert.ErrorContains(t, config.Validate(), "display_format is invalid")
+
+	config.DisplayFormat = "@fancy"
+	config.DisplayTemplates = map[string]string{"fancy": "✨ {{.Cost}}"}
+	assert.NoError(t, config.Validate())
+}
+
+func TestConfig_Validate_DisplayTemplates(t *testing.T) {
+	tests := map[string]struct {
+		templates map[string]string
+		wantErr   string
+	}{
+		"bad name":      {map[string]string{"My Spend": "{{.Cost}}"}, `display_templates name "My Spend" must be lowercase`},
+		"built-in name": {map[string]string{"percent": "{{.Cost}}"}, `"percent" is a built-in preset`},
+		"preset alias":  {map[string]string{"short": "@minimal"}, `display_templates "short" must be a template`},
+		"unknown field": {map[string]string{"short": "{{.Spend}}"}, `display_templates "short" is invalid`},
+		"bad syntax":    {map[string]string{"short": "{{if}}"}, `display_templates "short" is invalid`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := ConfigDefaults()
+			config.DisplayTemplates = tt.templates
+			assert.ErrorContains(t, config.Validate(), tt.wantErr)
+		})
+	}
+}
+
+func TestConfig_DisplayTemplateEngine_Cache(t *testing.T) {
+	config := ConfigDefaults()
+	config.DisplayTemplates = map[string]string{"spend": "{{.Cost}}"}
+	config.DisplayFormat = "@spend"
+	engine := config.DisplayTemplateEngine()
+	assert.Same(t, engine, config.DisplayTemplateEngine(), "unchanged settings reuse the engine")
+
+	copied := *config
+	assert.Same(t, engine, copied.DisplayTemplateEngine(), "an equal config shares it")
+
+	state := &UsageState{DailyCost: 5, Status: Green, IsAvailable: true}
+	assert.Equal(t, "$5.00", FormatTitle(state, config))
+	config.DisplayTemplates = map[string]string{"spend": "spent {{.Cost}}"}
+	assert.Equal(t, "spent $5.00", FormatTitle(state, config), "changing a custom preset takes effect")
+	assert.NotSame(t, engine, config.DisplayTemplateEngine())
+
+	engine = config.DisplayTemplateEngine()
+	config.StatusSymbols = StatusSymbols{Green: "[ok]"}
+	assert.NotSame(t, engine, config.DisplayTemplateEngine(), "colorize's symbols are part of the key")
+}
+
+// BenchmarkFormatTitle_DisplayFormat renders a display_format title as
+// each poll does; the template is parsed once and reused.
+func BenchmarkFormatTitle_DisplayFormat(b *testing.B) {
+	config := ConfigDefaults()
+	config.DisplayFormat = `{{.Symbol}}{{ifAlert .Status (print " " .Cost)}}`
+	state := &UsageState{DailyCost: 12.4, DailyCount: 1_234_000, Status: Yellow, IsAvailable: true}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		FormatTitle(state, config)
+	}
+}
+
+func TestConfig_Validate_DisplayFormatFields(t *testing.T) {
+	config := ConfigDefaults()
+	config.DisplayFormat = "{{.Symbol}} {{.InvalidField}}"
+	err := config.Validate()
+	assert.ErrorContains(t, err, "display_format is invalid")
+	assert.ErrorContains(t, err, "can't evaluate field InvalidField")
+	assert.ErrorContains(t, err, "fields are .Calls, .Color, .Cost, .CostRaw")
+
+	config.DisplayFormat = `{{.Symbol}}{{if eq .Color "red"}} {{.Cots}}{{end}}`
+	assert.ErrorContains(t, config.Validate(), "can't evaluate field Cots", "every status is tried")
+
+	config.DisplayFormat = `{{.Symbol}}{{with .Models}} {{.}}{{end}}{{with .RemainingToRed}} ({{.}} left){{end}}`
+	assert.NoError(t, config.Validate())
+}
+
+func TestTemplateFields(t *testing.T) {
+	fields := TemplateFields()
+	assert.Contains(t, fields, ".Cost")
+	assert.Contains(t, fields, ".RemainingToRed")
+	assert.IsIncreasing(t, fields)
+}
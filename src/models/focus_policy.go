@@ -0,0 +1,22 @@
+package models
+
+// FocusPolicy says what happens to a notification raised while the
+// desktop is in Do Not Disturb or a Focus mode.
+type FocusPolicy string
+
+const (
+	FocusDefer    FocusPolicy = "defer"    // hold it until focus ends (default)
+	FocusSuppress FocusPolicy = "suppress" // drop it
+	FocusSend     FocusPolicy = "send"     // send it anyway, leaving it to the OS
+)
+
+// IsValidFocusPolicy reports whether policy is a known policy or empty
+// (defer).
+func IsValidFocusPolicy(policy FocusPolicy) bool {
+	switch policy {
+	case "", FocusDefer, FocusSuppress, FocusSend:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// LiveConfig is the running app's configuration, shared by the tray, the
+// control socket, the dashboard, and reloads. Readers get a snapshot that
+// is never modified; changes build a new one and swap it in whole, so a
+// reader on any goroutine sees either the old config or the new one.
+type LiveConfig struct {
+	mu      sync.Mutex // serializes Set and Update
+	current atomic.Pointer[Config]
+}
+
+// NewLiveConfig shares config, which must not be modified afterwards.
+func NewLiveConfig(config *Config) *LiveConfig {
+	live := &LiveConfig{}
+	live.current.Store(config)
+	return live
+}
+
+// Get returns the current snapshot. Callers must not modify it.
+func (l *LiveConfig) Get() *Config {
+	return l.current.Load()
+}
+
+// Set replaces the snapshot with config, e.g. a reloaded file. config must
+// not be modified afterwards.
+func (l *LiveConfig) Set(config *Config) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.current.Store(config)
+}
+
+// Update applies change to a copy of the current config and swaps it in
+// if change succeeds and the result is valid, returning the new snapshot.
+// Concurrent updates run one at a time, so none is lost.
+func (l *LiveConfig) Update(change func(*Config) error) (*Config, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	next := l.current.Load().Clone()
+	if err := change(next); err != nil {
+		return nil, err
+	}
+	if err := next.Validate(); err != nil {
+		return nil, err
+	}
+	l.current.Store(next)
+	return next, nil
+}
+
+// Clone returns a copy of c with its own slices and maps, so changing
+// either leaves the other alone. Pointer fields and the entries themselves
+// are shared, so replace them rather than changing them in place.
+func (c *Config) Clone() *Config {
+	clone := *c
+	clone.AlertLevels = slices.Clone(c.AlertLevels)
+	clone.DayThresholds = maps.Clone(c.DayThresholds)
+	clone.ModelThresholds = maps.Clone(c.ModelThresholds)
+	clone.ProjectTags = slices.Clone(c.ProjectTags)
+	clone.NotificationSinks = slices.Clone(c.NotificationSinks)
+	clone.DisplayTemplates = maps.Clone(c.DisplayTemplates)
+	return &clone
+}
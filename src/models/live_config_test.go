@@ -0,0 +1,57 @@
+package models
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiveConfig_Update(t *testing.T) {
+	original := ConfigDefaults()
+	original.DayThresholds = map[string]ThresholdPair{"weekends": {YellowThreshold: 2, RedThreshold: 4}}
+	live := NewLiveConfig(original)
+
+	updated, err := live.Update(func(c *Config) error {
+		c.YellowThreshold = 15
+		c.DayThresholds["weekdays"] = ThresholdPair{YellowThreshold: 5, RedThreshold: 8}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Same(t, updated, live.Get())
+	assert.Equal(t, 15.0, live.Get().YellowThreshold)
+	assert.Equal(t, 10.0, original.YellowThreshold, "the earlier snapshot is untouched")
+	assert.Len(t, original.DayThresholds, 1)
+
+	_, err = live.Update(func(c *Config) error {
+		c.RedThreshold = 1
+		return nil
+	})
+	assert.Error(t, err, "invalid results are rejected")
+	_, err = live.Update(func(c *Config) error {
+		c.RedThreshold = 100
+		return errors.New("no")
+	})
+	assert.Error(t, err)
+	assert.Same(t, updated, live.Get(), "failed updates swap nothing in")
+}
+
+func TestLiveConfig_ConcurrentUpdates(t *testing.T) {
+	live := NewLiveConfig(ConfigDefaults())
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = live.Update(func(c *Config) error {
+				c.RedThreshold++
+				return nil
+			})
+			_ = live.Get().YellowThreshold
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 70.0, live.Get().RedThreshold, "no update is lost")
+}
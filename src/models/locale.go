@@ -0,0 +1,217 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+
+	"cc-dailyuse-bar/src/lib"
+)
+
+// LocaleAuto as the locale setting picks the locale from the environment.
+const LocaleAuto = "auto"
+
+// Locale formats numbers, dates, and times the way a language and region
+// write them, e.g. "1.234,56 $" and "14:05" for de-DE. Costs stay in
+// dollars; only their presentation changes. A nil *Locale keeps the
+// locale-neutral formats: "$1234.56", ISO dates, and 24-hour times.
+type Locale struct {
+	tag     language.Tag
+	printer *message.Printer
+}
+
+// currencyAfterLanguages write the currency symbol after the amount
+// ("12,34 $"). x/text doesn't expose CLDR's currency patterns, so this
+// covers the common languages by hand; currencyPlacementRegions lists the
+// regions that differ from their language.
+var currencyAfterLanguages = map[string]bool{
+	"bg": true, "cs": true, "da": true, "de": true, "el": true, "es": true, "et": true, "fi": true,
+	"fr": true, "hr": true, "hu": true, "it": true, "lt": true, "lv": true, "nb": true, "no": true,
+	"pl": true, "ro": true, "ru": true, "sk": true, "sl": true, "sv": true, "uk": true, "vi": true,
+}
+
+var currencyPlacementRegions = map[string]bool{
+	"de-AT": false, "de-CH": false, "de-LI": false, "it-CH": false,
+	"es-419": false, "es-AR": false, "es-CO": false, "es-MX": false, "es-US": false,
+	"pt-PT": true,
+}
+
+// dateLayouts is the short numeric date per language; others use ISO
+// dates. English depends on the region, see dateLayout.
+var dateLayouts = map[string]string{
+	"cs": "2. 1. 2006", "da": "02.01.2006", "de": "02.01.2006", "el": "2/1/2006", "es": "2/1/2006",
+	"fi": "2.1.2006", "fr": "02/01/2006", "hu": "2006. 01. 02.", "it": "02/01/2006", "ja": "2006/01/02",
+	"ko": "2006. 1. 2.", "nb": "02.01.2006", "nl": "02-01-2006", "pl": "02.01.2006", "pt": "02/01/2006",
+	"ru": "02.01.2006", "tr": "02.01.2006", "uk": "02.01.2006", "vi": "02/01/2006", "zh": "2006/1/2",
+}
+
+// clock12Regions use a 12-hour clock in English; everywhere else, and in
+// every other language, times are 24-hour.
+var clock12Regions = map[string]bool{
+	"US": true, "CA": true, "AU": true, "NZ": true, "IN": true, "PH": true,
+}
+
+// ParseLocale resolves the locale setting: empty for the locale-neutral
+// formats (nil), LocaleAuto to detect it from the environment, or a BCP 47
+// tag such as "de-DE". POSIX names like "de_DE.UTF-8" are accepted too.
+func ParseLocale(setting string) (*Locale, error) {
+	switch setting {
+	case "":
+		return nil, nil
+	case LocaleAuto:
+		return DetectLocale(), nil
+	}
+	tag, err := language.Parse(posixToBCP47(setting))
+	if err != nil {
+		return nil, lib.ValidationError(fmt.Sprintf("locale %q is not a language tag such as de-DE", setting))
+	}
+	return NewLocale(tag), nil
+}
+
+// NewLocale creates a Locale for tag.
+func NewLocale(tag language.Tag) *Locale {
+	return &Locale{tag: tag, printer: message.NewPrinter(tag)}
+}
+
+// DetectLocale reads the locale from LC_ALL, LC_NUMERIC, or LANG, the
+// first one set. It returns nil for the C and POSIX locales or when none
+// is set, as for apps started from a macOS or Windows login session; set
+// locale explicitly there.
+func DetectLocale() *Locale {
+	for _, name := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		if value == "C" || value == "POSIX" || strings.HasPrefix(value, "C.") {
+			return nil
+		}
+		tag, err := language.Parse(posixToBCP47(value))
+		if err != nil {
+			return nil
+		}
+		return NewLocale(tag)
+	}
+	return nil
+}
+
+// posixToBCP47 turns "de_DE.UTF-8@euro" into "de-DE".
+func posixToBCP47(value string) string {
+	if i := strings.IndexAny(value, ".@"); i >= 0 {
+		value = value[:i]
+	}
+	return strings.ReplaceAll(value, "_", "-")
+}
+
+// String returns the locale's BCP 47 tag, or "" for nil.
+func (l *Locale) String() string {
+	if l == nil {
+		return ""
+	}
+	return l.tag.String()
+}
+
+// FormatNumber renders v with precision decimals and the locale's
+// separators: "1,234.56" in en-US, "1.234,56" in de-DE.
+func (l *Locale) FormatNumber(v float64, precision int) string {
+	if l == nil {
+		return fmt.Sprintf("%.*f", precision, v)
+	}
+	return l.printer.Sprint(number.Decimal(v, number.Scale(precision)))
+}
+
+// FormatCost renders an amount already rounded to precision as dollars,
+// with the symbol where the locale puts it.
+func (l *Locale) FormatCost(cost float64, precision int) string {
+	amount := l.FormatNumber(cost, precision)
+	if l != nil && l.currencyAfter() {
+		return amount + "\u00a0$" // a no-break space keeps the symbol with its amount
+	}
+	return "$" + amount
+}
+
+func (l *Locale) currencyAfter() bool {
+	base, _ := l.tag.Base()
+	region, _ := l.tag.Region()
+	if after, ok := currencyPlacementRegions[base.String()+"-"+region.String()]; ok {
+		return after
+	}
+	return currencyAfterLanguages[base.String()]
+}
+
+// FormatTokens abbreviates a token count like FormatTokens, with the
+// locale's decimal separator: "12,3K" in de-DE.
+func (l *Locale) FormatTokens(tokens int) string {
+	formatted := FormatTokens(tokens)
+	if l == nil || tokens < 1_000 {
+		return formatted
+	}
+	// FormatTokens leaves at most one decimal, so its only "." is the
+	// decimal point.
+	return strings.Replace(formatted, ".", l.decimalSeparator(), 1)
+}
+
+// decimalSeparator is "." or "," (or another mark) as the locale writes
+// it.
+func (l *Locale) decimalSeparator() string {
+	return strings.TrimSuffix(strings.TrimPrefix(l.FormatNumber(0.5, 1), "0"), "5")
+}
+
+// FormatDate renders t's date in the locale's short numeric form, e.g.
+// "14.03.2025" in de-DE and "03/14/2025" in en-US.
+func (l *Locale) FormatDate(t time.Time) string {
+	return t.Format(l.dateLayout())
+}
+
+// FormatTime renders t's time of day, e.g. "14:05", or "2:05 PM" where
+// English uses a 12-hour clock.
+func (l *Locale) FormatTime(t time.Time) string {
+	return t.Format(l.timeLayout(false))
+}
+
+// FormatDateTime renders t's date and time to the second, e.g.
+// "14.03.2025 14:05:09".
+func (l *Locale) FormatDateTime(t time.Time) string {
+	return t.Format(l.dateLayout() + " " + l.timeLayout(true))
+}
+
+func (l *Locale) dateLayout() string {
+	if l == nil {
+		return "2006-01-02"
+	}
+	base, _ := l.tag.Base()
+	if base.String() == "en" {
+		switch region, _ := l.tag.Region(); region.String() {
+		case "US", "PH":
+			return "01/02/2006"
+		case "CA":
+			return "2006-01-02"
+		default:
+			return "02/01/2006"
+		}
+	}
+	if layout, ok := dateLayouts[base.String()]; ok {
+		return layout
+	}
+	return "2006-01-02"
+}
+
+func (l *Locale) timeLayout(seconds bool) string {
+	clock, suffix := "15:04", ""
+	if l != nil {
+		base, _ := l.tag.Base()
+		region, _ := l.tag.Region()
+		if base.String() == "en" && clock12Regions[region.String()] {
+			clock, suffix = "3:04", " PM"
+		}
+	}
+	if seconds {
+		clock += ":05"
+	}
+	return clock + suffix
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLocale(t *testing.T) {
+	locale, err := ParseLocale("")
+	require.NoError(t, err)
+	assert.Nil(t, locale)
+
+	for setting, want := range map[string]string{"de-DE": "de-DE", "de_DE.UTF-8": "de-DE", "fr_CH@euro": "fr-CH", "en": "en"} {
+		locale, err := ParseLocale(setting)
+		require.NoError(t, err, setting)
+		assert.Equal(t, want, locale.String(), setting)
+	}
+
+	_, err = ParseLocale("not a locale")
+	assert.ErrorContains(t, err, "not a language tag")
+}
+
+func TestDetectLocale(t *testing.T) {
+	tests := []struct {
+		name                 string
+		lcAll, numeric, lang string
+		want                 string
+	}{
+		{"none set", "", "", "", ""},
+		{"LANG", "", "", "de_DE.UTF-8", "de-DE"},
+		{"LC_NUMERIC over LANG", "", "fr_FR.UTF-8", "en_US.UTF-8", "fr-FR"},
+		{"LC_ALL over everything", "en_GB.UTF-8", "fr_FR.UTF-8", "de_DE.UTF-8", "en-GB"},
+		{"C locale is neutral", "", "", "C.UTF-8", ""},
+		{"POSIX locale is neutral", "POSIX", "", "de_DE.UTF-8", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LC_ALL", tt.lcAll)
+			t.Setenv("LC_NUMERIC", tt.numeric)
+			t.Setenv("LANG", tt.lang)
+			assert.Equal(t, tt.want, DetectLocale().String())
+
+			locale, err := ParseLocale(LocaleAuto)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, locale.String())
+		})
+	}
+}
+
+func TestLocale_Formats(t *testing.T) {
+	at := time.Date(2025, 3, 14, 14, 5, 9, 0, time.Local)
+
+	tests := []struct {
+		locale   string
+		cost     string
+		tokens   string
+		date     string
+		time     string
+		dateTime string
+	}{
+		{"", "$1234.56", "12.3K", "2025-03-14", "14:05", "2025-03-14 14:05:09"},
+		{"en-US", "$1,234.56", "12.3K", "03/14/2025", "2:05 PM", "03/14/2025 2:05:09 PM"},
+		{"en-GB", "$1,234.56", "12.3K", "14/03/2025", "14:05", "14/03/2025 14:05:09"},
+		{"de-DE", "1.234,56\u00a0$", "12,3K", "14.03.2025", "14:05", "14.03.2025 14:05:09"},
+		{"de-CH", "$1’234.56", "12.3K", "14.03.2025", "14:05", "14.03.2025 14:05:09"},
+		{"fr-FR", "1\u00a0234,56\u00a0$", "12,3K", "14/03/2025", "14:05", "14/03/2025 14:05:09"},
+		{"pt-BR", "$1.234,56", "12,3K", "14/03/2025", "14:05", "14/03/2025 14:05:09"},
+		{"ja-JP", "$1,234.56", "12.3K", "2025/03/14", "14:05", "2025/03/14 14:05:09"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			locale, err := ParseLocale(tt.locale)
+			require.NoError(t, err)
+			assert.Equal(t, tt.cost, locale.FormatCost(1234.56, 2))
+			assert.Equal(t, tt.tokens, locale.FormatTokens(12_345))
+			assert.Equal(t, "950", locale.FormatTokens(950))
+			assert.Equal(t, tt.date, locale.FormatDate(at))
+			assert.Equal(t, tt.time, locale.FormatTime(at))
+			assert.Equal(t, tt.dateTime, locale.FormatDateTime(at))
+		})
+	}
+}
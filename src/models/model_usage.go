@@ -0,0 +1,110 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"cc-dailyuse-bar/src/lib"
+)
+
+// ModelUsage is one model's share of today's usage.
+type ModelUsage struct {
+	Name   string  `json:"name"`
+	Tokens int     `json:"tokens"`
+	Cost   float64 `json:"cost"`
+}
+
+// modelDateSuffix matches the release date on model IDs such as
+// claude-sonnet-4-5-20250929.
+var modelDateSuffix = regexp.MustCompile(`-\d{8}$`)
+
+// modelVersion matches a dashed minor version ("4-5") to render as "4.5".
+var modelVersion = regexp.MustCompile(`(\d)-(\d)(-|$)`)
+
+// ShortModelName trims a model ID for display: claude-sonnet-4-5-20250929
+// becomes sonnet-4.5 and claude-opus-4-20250514 becomes opus-4.
+func ShortModelName(name string) string {
+	short := strings.TrimPrefix(name, "claude-")
+	short = modelDateSuffix.ReplaceAllString(short, "")
+	return modelVersion.ReplaceAllString(short, "$1.$2$3")
+}
+
+// ModelNames lists the models used today by short name, in ccusage's
+// order, without duplicates.
+func (u *UsageState) ModelNames() []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, m := range u.Models {
+		name := ShortModelName(m.Name)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ModelAlert records a model_thresholds entry that today's spend reached.
+type ModelAlert struct {
+	Pattern   string  `json:"pattern"`
+	Cost      float64 `json:"cost"`
+	Threshold float64 `json:"threshold"`
+}
+
+// modelMatches reports whether a model name falls under a threshold
+// pattern: a case-insensitive substring, so "opus" covers every Opus
+// release.
+func modelMatches(name, pattern string) bool {
+	return strings.Contains(strings.ToLower(name), strings.ToLower(strings.TrimSpace(pattern)))
+}
+
+// MatchModelThresholds sums today's cost for each pattern across matching
+// models and returns the patterns at or over their threshold, sorted by
+// pattern.
+func MatchModelThresholds(usage []ModelUsage, thresholds map[string]float64) []ModelAlert {
+	if len(thresholds) == 0 || len(usage) == 0 {
+		return nil
+	}
+
+	patterns := make([]string, 0, len(thresholds))
+	for pattern := range thresholds {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	var alerts []ModelAlert
+	for _, pattern := range patterns {
+		var cost float64
+		for _, m := range usage {
+			if modelMatches(m.Name, pattern) {
+				cost += m.Cost
+			}
+		}
+		if threshold := thresholds[pattern]; cost >= threshold {
+			alerts = append(alerts, ModelAlert{Pattern: pattern, Cost: cost, Threshold: threshold})
+		}
+	}
+	return alerts
+}
+
+// ValidateModelThresholds checks that every pattern is non-empty and has a
+// positive threshold.
+func ValidateModelThresholds(thresholds map[string]float64) error {
+	patterns := make([]string, 0, len(thresholds))
+	for pattern := range thresholds {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			return lib.ValidationError("model_thresholds: model pattern cannot be empty")
+		}
+		if thresholds[pattern] <= 0 {
+			return lib.ValidationError(fmt.Sprintf("model_thresholds.%s: threshold must be greater than zero", pattern))
+		}
+	}
+	return nil
+}
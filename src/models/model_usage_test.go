@@ -0,0 +1,68 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchModelThresholds(t *testing.T) {
+	usage := []ModelUsage{
+		{Name: "claude-opus-4-20250514", Cost: 7},
+		{Name: "claude-opus-4-1-20250805", Cost: 4},
+		{Name: "claude-sonnet-4-20250514", Cost: 3},
+	}
+
+	alerts := MatchModelThresholds(usage, map[string]float64{
+		"Opus":   10, // both Opus releases count: $11
+		"sonnet": 5,
+		"haiku":  1,
+	})
+	assert.Equal(t, []ModelAlert{{Pattern: "Opus", Cost: 11, Threshold: 10}}, alerts)
+
+	assert.Nil(t, MatchModelThresholds(usage, nil))
+	assert.Nil(t, MatchModelThresholds(nil, map[string]float64{"opus": 1}))
+}
+
+func TestValidateModelThresholds(t *testing.T) {
+	assert.NoError(t, ValidateModelThresholds(nil))
+	assert.NoError(t, ValidateModelThresholds(map[string]float64{"opus": 10}))
+	assert.ErrorContains(t, ValidateModelThresholds(map[string]float64{" ": 10}), "cannot be empty")
+	assert.ErrorContains(t, ValidateModelThresholds(map[string]float64{"opus": 0}), "greater than zero")
+}
+
+func TestUsageState_ModelAlertsFromConfig(t *testing.T) {
+	config := ConfigDefaults()
+	config.ModelThresholds = map[string]float64{"opus": 10}
+
+	state := &UsageState{DailyCost: 12, Models: []ModelUsage{{Name: "claude-opus-4", Cost: 12}}}
+	state.UpdateStatusFromConfig(config)
+	assert.Len(t, state.ModelAlerts, 1)
+
+	config.QuietUntil = "2999-01-01"
+	state.UpdateStatusFromConfig(config)
+	assert.Empty(t, state.ModelAlerts, "quiet mode silences model alerts")
+}
+
+func TestShortModelName(t *testing.T) {
+	tests := map[string]string{
+		"claude-sonnet-4-5-20250929": "sonnet-4.5",
+		"claude-opus-4-20250514":     "opus-4",
+		"claude-opus-4-1-20250805":   "opus-4.1",
+		"claude-3-5-haiku-20241022":  "3.5-haiku",
+		"gpt-4o":                     "gpt-4o",
+	}
+	for name, want := range tests {
+		assert.Equal(t, want, ShortModelName(name), name)
+	}
+}
+
+func TestUsageState_ModelNames(t *testing.T) {
+	state := &UsageState{Models: []ModelUsage{
+		{Name: "claude-opus-4-20250514"},
+		{Name: "claude-sonnet-4-5-20250929"},
+		{Name: "claude-opus-4-20250601"},
+	}}
+	assert.Equal(t, []string{"opus-4", "sonnet-4.5"}, state.ModelNames())
+	assert.Nil(t, (&UsageState{}).ModelNames())
+}
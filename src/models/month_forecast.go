@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// ForecastMonth projects month-end spend from the month-to-date total and
+// the last seven days' spend, both including today. The rest of the month
+// is assumed to run at the average of the month's daily rate and the
+// trailing week's, so a recent change in habits shows up without one busy
+// day swinging the estimate.
+func ForecastMonth(monthToDate, lastSevenDays float64, now time.Time) float64 {
+	day := now.Day()
+	daysInMonth := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, now.Location()).Day()
+	rate := (monthToDate/float64(day) + lastSevenDays/7) / 2
+	return monthToDate + rate*float64(daysInMonth-day)
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForecastMonth(t *testing.T) {
+	tests := []struct {
+		name                string
+		monthToDate, recent float64
+		now                 time.Time
+		want                float64
+	}{
+		{"steady spend", 100, 70, time.Date(2025, 4, 10, 12, 0, 0, 0, time.Local), 300},
+		{"recent slowdown", 100, 35, time.Date(2025, 4, 10, 12, 0, 0, 0, time.Local), 250},
+		{"last day of the month", 412, 80, time.Date(2025, 3, 31, 12, 0, 0, 0, time.Local), 412},
+		{"leap February", 29, 7, time.Date(2024, 2, 1, 12, 0, 0, 0, time.Local), 29 + 15*28},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, ForecastMonth(tt.monthToDate, tt.recent, tt.now), 1e-9)
+		})
+	}
+}
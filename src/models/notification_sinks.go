@@ -0,0 +1,119 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+
+	"cc-dailyuse-bar/src/lib"
+)
+
+// SinkType names a notification channel in notification_sinks.
+type SinkType string
+
+const (
+	SinkDesktop  SinkType = "desktop"  // the platform's notification centre
+	SinkWebhook  SinkType = "webhook"  // JSON POST to url
+	SinkSlack    SinkType = "slack"    // Slack incoming webhook at url
+	SinkTelegram SinkType = "telegram" // telegram_bot_token's chat
+	SinkNtfy     SinkType = "ntfy"     // ntfy topic url, e.g. https://ntfy.sh/my-topic
+	SinkCommand  SinkType = "command"  // runs command with the alert in its environment
+)
+
+// SinkLevel ranks notifications for per-sink filtering: info for news
+// such as a new day or an archived month, yellow for warnings, and red
+// for critical spend.
+type SinkLevel string
+
+const (
+	SinkLevelInfo   SinkLevel = "info"
+	SinkLevelYellow SinkLevel = "yellow"
+	SinkLevelRed    SinkLevel = "red"
+)
+
+// MaxAlertCooldown caps alert_cooldown at a day, after which every alert
+// may be sent again anyway.
+const MaxAlertCooldown = 1440
+
+var sinkLevelRanks = map[SinkLevel]int{"": 0, SinkLevelInfo: 0, SinkLevelYellow: 1, SinkLevelRed: 2}
+
+// Allows reports whether a sink whose min_level is l receives a
+// notification of level.
+func (l SinkLevel) Allows(level SinkLevel) bool {
+	return sinkLevelRanks[level] >= sinkLevelRanks[l]
+}
+
+// SinkLevelFor is the level of a status alert: red for red, yellow
+// otherwise.
+func SinkLevelFor(status AlertStatus) SinkLevel {
+	if status == Red {
+		return SinkLevelRed
+	}
+	return SinkLevelYellow
+}
+
+// NotificationSink is one notification_sinks entry: a channel alerts are
+// fanned out to, and the least severe level it receives.
+type NotificationSink struct {
+	Type     SinkType  `yaml:"type"`
+	URL      string    `yaml:"url,omitempty"`     // webhook, slack, and ntfy
+	Command  []string  `yaml:"command,omitempty"` // command: program and arguments
+	MinLevel SinkLevel `yaml:"min_level,omitempty"`
+
+	// MaxPerHour caps the alerts sent to the sink in any hour; 0 is
+	// unlimited.
+	MaxPerHour int `yaml:"max_per_hour,omitempty"`
+}
+
+// Equal reports whether s and other describe the same sink.
+func (s NotificationSink) Equal(other NotificationSink) bool {
+	return s.Type == other.Type && s.URL == other.URL && slices.Equal(s.Command, other.Command) &&
+		s.MinLevel == other.MinLevel && s.MaxPerHour == other.MaxPerHour
+}
+
+// Validate checks the sink, the index-th entry of config's
+// notification_sinks.
+func (s NotificationSink) Validate(index int, config *Config) error {
+	field := fmt.Sprintf("notification_sinks[%d]", index)
+	if _, ok := sinkLevelRanks[s.MinLevel]; !ok {
+		return lib.ValidationError(field + ".min_level must be one of: info, yellow, red")
+	}
+	if s.MaxPerHour < 0 {
+		return lib.ValidationError(field + ".max_per_hour must not be negative")
+	}
+	switch s.Type {
+	case SinkDesktop:
+		return nil
+	case SinkWebhook, SinkSlack, SinkNtfy:
+		if IsSecretRef(s.URL) {
+			return nil
+		}
+		u, err := url.Parse(s.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return lib.ValidationError(field + ".url must be an http(s) URL")
+		}
+		return nil
+	case SinkTelegram:
+		if config.TelegramBotToken == "" {
+			return lib.ValidationError(field + " needs telegram_bot_token and telegram_chat_id")
+		}
+		return nil
+	case SinkCommand:
+		if len(s.Command) == 0 || s.Command[0] == "" {
+			return lib.ValidationError(field + ".command must name a program to run")
+		}
+		return nil
+	default:
+		return lib.ValidationError(field + ".type must be one of: desktop, webhook, slack, telegram, ntfy, command")
+	}
+}
+
+// HasSink reports whether notification_sinks has an entry of type t.
+func (c *Config) HasSink(t SinkType) bool {
+	for _, sink := range c.NotificationSinks {
+		if sink.Type == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationSink_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		sink     NotificationSink
+		telegram bool
+		wantErr  string
+	}{
+		{"desktop", NotificationSink{Type: SinkDesktop, MinLevel: SinkLevelRed}, false, ""},
+		{"webhook", NotificationSink{Type: SinkWebhook, URL: "https://example.com/hook"}, false, ""},
+		{"slack", NotificationSink{Type: SinkSlack, URL: "https://hooks.slack.com/services/T/B/x"}, false, ""},
+		{"ntfy", NotificationSink{Type: SinkNtfy, URL: "https://ntfy.sh/spend"}, false, ""},
+		{"telegram", NotificationSink{Type: SinkTelegram}, true, ""},
+		{"command", NotificationSink{Type: SinkCommand, Command: []string{"/usr/local/bin/page-me"}}, false, ""},
+		{"unknown type", NotificationSink{Type: "pager"}, false, "notification_sinks[0].type must be one of"},
+		{"missing url", NotificationSink{Type: SinkNtfy}, false, "notification_sinks[0].url must be an http(s) URL"},
+		{"telegram unset", NotificationSink{Type: SinkTelegram}, false, "needs telegram_bot_token"},
+		{"empty command", NotificationSink{Type: SinkCommand}, false, "command must name a program"},
+		{"bad level", NotificationSink{Type: SinkDesktop, MinLevel: "orange"}, false, "min_level must be one of"},
+		{"quota", NotificationSink{Type: SinkDesktop, MaxPerHour: 4}, false, ""},
+		{"negative quota", NotificationSink{Type: SinkDesktop, MaxPerHour: -1}, false, "max_per_hour must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := ConfigDefaults()
+			if tt.telegram {
+				config.TelegramBotToken, config.TelegramChatID = "123456:ABC-DEF1234ghIkl", "1"
+			}
+			config.NotificationSinks = []NotificationSink{tt.sink}
+			if tt.wantErr == "" {
+				assert.NoError(t, config.Validate())
+			} else {
+				assert.ErrorContains(t, config.Validate(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_AlertCooldown(t *testing.T) {
+	config := ConfigDefaults()
+	for _, minutes := range []int{0, 30, MaxAlertCooldown} {
+		config.AlertCooldown = minutes
+		assert.NoError(t, config.Validate())
+	}
+	for _, minutes := range []int{-1, MaxAlertCooldown + 1} {
+		config.AlertCooldown = minutes
+		assert.ErrorContains(t, config.Validate(), "alert_cooldown must be between 0 and 1440 minutes")
+	}
+}
+
+func TestSinkLevel_Allows(t *testing.T) {
+	assert.True(t, SinkLevel("").Allows(SinkLevelInfo))
+	assert.True(t, SinkLevelYellow.Allows(SinkLevelRed))
+	assert.False(t, SinkLevelYellow.Allows(SinkLevelInfo))
+	assert.False(t, SinkLevelRed.Allows(SinkLevelYellow))
+	assert.Equal(t, SinkLevelRed, SinkLevelFor(Red))
+	assert.Equal(t, SinkLevelYellow, SinkLevelFor(Yellow))
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// statusWords names the default status dots, so plain text keeps their
+// meaning rather than dropping them.
+var statusWords = strings.NewReplacer(
+	"🟢", Green.String(),
+	"🟡", Yellow.String(),
+	"🔴", Red.String(),
+	"⚪️", Unknown.String(),
+	"⚪", Unknown.String(),
+)
+
+// PlainText rewrites a menu line for screen readers: status dots become
+// words ("🟡" → "High") and other emoji and pictographs are dropped, e.g.
+// "💰 Daily Cost: $12.40" → "Daily Cost: $12.40".
+func PlainText(s string) string {
+	s = statusWords.Replace(s)
+	s = strings.Map(func(r rune) rune {
+		// Variation selectors and joiners are left over from emoji.
+		if unicode.Is(unicode.So, r) || r == '\uFE0F' || r == '\u200D' {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
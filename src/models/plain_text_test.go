@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"💰 Daily Cost: $12.40", "Daily Cost: $12.40"},
+		{"⚙️ Current settings", "Current settings"},
+		{"11:05 🟢 → 🟡 at $10.20", "11:05 OK → High at $10.20"},
+		{"CC ⚪️ Unknown", "CC Unknown Unknown"},
+		{"09:00 ▇▇▇ $1.20", "09:00 $1.20"},
+		{"Update every: 30s", "Update every: 30s"},
+		{"👨‍💻 Team", "Team"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, PlainText(tt.in), tt.in)
+	}
+}
+
+func TestFormatTitle_ScreenReader(t *testing.T) {
+	config := ConfigDefaults()
+	config.ScreenReader = true
+	config.StatusSymbols = StatusSymbols{Yellow: "[!]"}
+
+	state := &UsageState{DailyCost: 12.4, Status: Yellow, LevelSymbol: "🟠", IsAvailable: true}
+	assert.Equal(t, "CC High $12.40", FormatTitle(state, config))
+	state.Paused = true
+	assert.Equal(t, "CC Paused $12.40", FormatTitle(state, config))
+	assert.Equal(t, "CC Unknown", FormatUnknownTitle(config))
+}
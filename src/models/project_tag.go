@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cc-dailyuse-bar/src/lib"
+)
+
+// UntaggedTag collects the cost of sessions no project_tags rule matches.
+const UntaggedTag = "untagged"
+
+// ProjectTag allocates the cost of Claude Code sessions run in Path, or
+// any directory below it, to Tag (e.g. a client name) for billing.
+type ProjectTag struct {
+	Path string `yaml:"path" json:"path"`
+	Tag  string `yaml:"tag" json:"tag"`
+}
+
+// TagCost is one tag's share of spend.
+type TagCost struct {
+	Tag  string  `json:"tag"`
+	Cost float64 `json:"cost"`
+}
+
+// ValidateProjectTags checks that every rule names a path and a tag.
+func ValidateProjectTags(tags []ProjectTag) error {
+	for i, rule := range tags {
+		if strings.TrimSpace(rule.Path) == "" {
+			return lib.ValidationError(fmt.Sprintf("project_tags[%d]: path cannot be empty", i))
+		}
+		if strings.TrimSpace(rule.Tag) == "" {
+			return lib.ValidationError(fmt.Sprintf("project_tags[%d]: tag cannot be empty", i))
+		}
+		if rule.Tag == UntaggedTag {
+			return lib.ValidationError(fmt.Sprintf("project_tags[%d]: tag %q is reserved for unmatched sessions", i, UntaggedTag))
+		}
+	}
+	return nil
+}
+
+// EncodeProjectPath returns the name Claude Code gives a project's log
+// directory, and ccusage its session ID: the path with every
+// non-alphanumeric byte replaced by "-", so "/Users/me/refactor-api"
+// becomes "-Users-me-refactor-api".
+func EncodeProjectPath(path string) string {
+	encoded := []byte(path)
+	for i, c := range encoded {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			encoded[i] = '-'
+		}
+	}
+	return string(encoded)
+}
+
+// MatchProjectTag returns the tag of the first rule whose path holds the
+// session's project, or UntaggedTag. A leading "~" in a rule's path is
+// home. Sessions only carry the encoded path, so "~/work/client-a" also
+// covers a sibling named "~/work/client-a-old"; list the longer path
+// first when that matters.
+func MatchProjectTag(rules []ProjectTag, sessionID, home string) string {
+	for _, rule := range rules {
+		path := rule.Path
+		if rest, ok := strings.CutPrefix(path, "~"); ok && home != "" {
+			path = home + rest
+		}
+		prefix := EncodeProjectPath(strings.TrimRight(path, `/\`))
+		if sessionID == prefix || strings.HasPrefix(sessionID, prefix+"-") {
+			return rule.Tag
+		}
+	}
+	return UntaggedTag
+}
+
+// SortTagCosts turns per-tag totals into a list from the most expensive
+// down, with ties broken by name.
+func SortTagCosts(costs map[string]float64) []TagCost {
+	sorted := make([]TagCost, 0, len(costs))
+	for tag, cost := range costs {
+		sorted = append(sorted, TagCost{Tag: tag, Cost: cost})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Cost != sorted[j].Cost {
+			return sorted[i].Cost > sorted[j].Cost
+		}
+		return sorted[i].Tag < sorted[j].Tag
+	})
+	return sorted
+}
+
+// FormatTagCosts renders costs on one line, e.g. "client-A $3.10,
+// personal $1.20".
+func FormatTagCosts(costs []TagCost, format CostFormat) string {
+	parts := make([]string, 0, len(costs))
+	for _, tc := range costs {
+		parts = append(parts, tc.Tag+" "+format.Format(tc.Cost))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateProjectTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []ProjectTag
+		wantErr string
+	}{
+		{"none", nil, ""},
+		{"valid", []ProjectTag{{Path: "~/work/client-a", Tag: "client-A"}}, ""},
+		{"empty path", []ProjectTag{{Tag: "oss"}}, "project_tags[0]: path cannot be empty"},
+		{"empty tag", []ProjectTag{{Path: "~/oss"}}, "project_tags[0]: tag cannot be empty"},
+		{"reserved tag", []ProjectTag{{Path: "~/oss", Tag: UntaggedTag}}, "reserved"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProjectTags(tt.tags)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatchProjectTag(t *testing.T) {
+	rules := []ProjectTag{
+		{Path: "~/work/client-a/", Tag: "client-A"},
+		{Path: "~/work", Tag: "work"},
+		{Path: "/srv/oss", Tag: "oss"},
+	}
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"-Users-me-work-client-a", "client-A"},
+		{"-Users-me-work-client-a-api", "client-A"},
+		{"-Users-me-work-billing", "work"},
+		{"-Users-me-workshop", UntaggedTag},
+		{"-srv-oss-cc-dailyuse-bar", "oss"},
+		{"-Users-me-personal", UntaggedTag},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MatchProjectTag(rules, tt.id, "/Users/me"), tt.id)
+	}
+}
+
+func TestSortTagCosts(t *testing.T) {
+	costs := SortTagCosts(map[string]float64{"personal": 1.2, "client-A": 3.1, "oss": 1.2})
+	assert.Equal(t, []TagCost{{"client-A", 3.1}, {"oss", 1.2}, {"personal", 1.2}}, costs)
+	assert.Equal(t, "client-A $3.10, oss $1.20, personal $1.20", FormatTagCosts(costs, DefaultCostFormat()))
+}
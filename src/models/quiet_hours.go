@@ -0,0 +1,88 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+)
+
+// QuietHours is a daily window, such as 23:00–07:00, during which polling
+// and notifications pause. Start and End are minutes after midnight; a
+// window whose end is before its start wraps past midnight. The zero value
+// is disabled.
+type QuietHours struct {
+	Start, End int
+	enabled    bool
+}
+
+// ParseQuietHours parses a quiet_hours value of the form "HH:MM-HH:MM"
+// (an en dash is also accepted). An empty value disables quiet hours.
+func ParseQuietHours(value string) (QuietHours, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return QuietHours{}, nil
+	}
+	invalid := lib.ValidationError(fmt.Sprintf("quiet_hours must look like 23:00-07:00, got %q", value))
+
+	start, end, ok := strings.Cut(strings.ReplaceAll(value, "–", "-"), "-")
+	if !ok {
+		return QuietHours{}, invalid
+	}
+	startMin, err := parseClock(start)
+	if err != nil {
+		return QuietHours{}, invalid
+	}
+	endMin, err := parseClock(end)
+	if err != nil {
+		return QuietHours{}, invalid
+	}
+	if startMin == endMin {
+		return QuietHours{}, lib.ValidationError("quiet_hours start and end must differ")
+	}
+	return QuietHours{Start: startMin, End: endMin, enabled: true}, nil
+}
+
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Enabled reports whether a window is configured.
+func (q QuietHours) Enabled() bool {
+	return q.enabled
+}
+
+// Active reports whether now falls inside the window. The start is
+// inclusive and the end exclusive.
+func (q QuietHours) Active(now time.Time) bool {
+	if !q.enabled {
+		return false
+	}
+	minute := now.Hour()*60 + now.Minute()
+	if q.Start < q.End {
+		return minute >= q.Start && minute < q.End
+	}
+	return minute >= q.Start || minute < q.End
+}
+
+// NextEnd returns the first end of the window after now.
+func (q QuietHours) NextEnd(now time.Time) time.Time {
+	end := time.Date(now.Year(), now.Month(), now.Day(), q.End/60, q.End%60, 0, 0, now.Location())
+	if !end.After(now) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// String renders the window as "HH:MM–HH:MM", or "" when disabled.
+func (q QuietHours) String() string {
+	if !q.enabled {
+		return ""
+	}
+	return fmt.Sprintf("%02d:%02d–%02d:%02d", q.Start/60, q.Start%60, q.End/60, q.End%60)
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuietHours(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"23:00-07:00", "23:00–07:00", false},
+		{" 12:30 – 13:15 ", "12:30–13:15", false},
+		{"7:00-9:00", "07:00–09:00", false},
+		{"23:00", "", true},
+		{"25:00-07:00", "", true},
+		{"08:00-08:00", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			q, err := ParseQuietHours(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, q.String())
+			assert.Equal(t, tt.want != "", q.Enabled())
+		})
+	}
+}
+
+func TestQuietHours_Active(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2025, 3, 14, h, m, 0, 0, time.Local) }
+
+	overnight, err := ParseQuietHours("23:00-07:00")
+	require.NoError(t, err)
+	assert.True(t, overnight.Active(at(23, 0)))
+	assert.True(t, overnight.Active(at(3, 30)))
+	assert.False(t, overnight.Active(at(7, 0)), "end is exclusive")
+	assert.False(t, overnight.Active(at(12, 0)))
+
+	lunch, err := ParseQuietHours("12:00-13:00")
+	require.NoError(t, err)
+	assert.True(t, lunch.Active(at(12, 59)))
+	assert.False(t, lunch.Active(at(11, 59)))
+
+	assert.False(t, QuietHours{}.Active(at(3, 0)), "disabled")
+}
+
+func TestConfig_Validate_QuietHours(t *testing.T) {
+	config := ConfigDefaults()
+	config.QuietHours = "23:00-07:00"
+	assert.NoError(t, config.Validate())
+	assert.Equal(t, "23:00–07:00", config.QuietHoursWindow().String())
+
+	config.QuietHours = "overnight"
+	assert.Error(t, config.Validate())
+	assert.False(t, config.QuietHoursWindow().Enabled())
+}
+
+func TestQuietHours_NextEnd(t *testing.T) {
+	q, err := ParseQuietHours("23:00-07:00")
+	require.NoError(t, err)
+
+	late := time.Date(2025, 3, 14, 23, 30, 0, 0, time.Local)
+	assert.Equal(t, time.Date(2025, 3, 15, 7, 0, 0, 0, time.Local), q.NextEnd(late))
+	early := time.Date(2025, 3, 15, 2, 0, 0, 0, time.Local)
+	assert.Equal(t, time.Date(2025, 3, 15, 7, 0, 0, 0, time.Local), q.NextEnd(early))
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+)
+
+// QuietDateFormat is the layout for quiet_until dates.
+const QuietDateFormat = "2006-01-02"
+
+// ParseQuietUntil parses a quiet_until date in local time. An empty value
+// returns the zero time, meaning quiet mode is off.
+func ParseQuietUntil(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	until, err := time.ParseInLocation(QuietDateFormat, value, time.Local)
+	if err != nil {
+		return time.Time{}, lib.ValidationError(fmt.Sprintf("quiet_until must be a date in YYYY-MM-DD format, got %q", value))
+	}
+	return until, nil
+}
+
+// QuietActive reports whether now falls on or before the quiet_until date.
+// The end date is inclusive; empty or malformed values are never active.
+func QuietActive(quietUntil string, now time.Time) bool {
+	until, err := ParseQuietUntil(quietUntil)
+	if err != nil || until.IsZero() {
+		return false
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	return !today.After(until)
+}
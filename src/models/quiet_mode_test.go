@@ -0,0 +1,71 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuietUntil(t *testing.T) {
+	until, err := ParseQuietUntil("")
+	require.NoError(t, err)
+	assert.True(t, until.IsZero())
+
+	until, err = ParseQuietUntil(" 2025-03-21 ")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 21, 0, 0, 0, 0, time.Local), until)
+
+	_, err = ParseQuietUntil("21/03/2025")
+	assert.ErrorContains(t, err, "YYYY-MM-DD")
+}
+
+func TestQuietActive(t *testing.T) {
+	tests := []struct {
+		name       string
+		quietUntil string
+		now        time.Time
+		want       bool
+	}{
+		{"unset", "", time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local), false},
+		{"before end", "2025-03-21", time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local), true},
+		{"end date is inclusive", "2025-03-21", time.Date(2025, 3, 21, 23, 59, 0, 0, time.Local), true},
+		{"after end", "2025-03-21", time.Date(2025, 3, 22, 0, 0, 0, 0, time.Local), false},
+		{"malformed", "soon", time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, QuietActive(tt.quietUntil, tt.now))
+		})
+	}
+}
+
+func TestConfig_Validate_QuietUntil(t *testing.T) {
+	config := ConfigDefaults()
+	config.QuietUntil = "2025-03-21"
+	assert.NoError(t, config.Validate())
+
+	config.QuietUntil = "next week"
+	assert.Error(t, config.Validate())
+}
+
+func TestUsageState_UpdateStatusFromConfig_Quiet(t *testing.T) {
+	config := ConfigDefaults()
+	config.QuietUntil = "2025-03-21"
+	config.AlertLevels = []AlertLevel{{Name: "max", Threshold: 20, Status: Red, Symbol: "🔥"}}
+
+	state := &UsageState{DailyCost: 50, LastUpdate: time.Date(2025, 3, 18, 9, 0, 0, 0, time.Local)}
+	state.UpdateStatusFromConfig(config)
+	assert.Equal(t, Green, state.Status)
+	assert.True(t, state.Quiet)
+	assert.Empty(t, state.Level)
+	assert.Equal(t, 50.0, state.DailyCost, "data keeps flowing while quiet")
+
+	state.LastUpdate = time.Date(2025, 3, 22, 9, 0, 0, 0, time.Local)
+	state.UpdateStatusFromConfig(config)
+	assert.Equal(t, Red, state.Status)
+	assert.False(t, state.Quiet)
+	assert.Equal(t, "max", state.Level)
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SecretPrefix marks a sensitive config value that's stored encrypted, as
+// "enc:keychain:<account>" or "enc:age:<ciphertext>"; ConfigService
+// decrypts it on load.
+const SecretPrefix = "enc:"
+
+// IsSecretRef reports whether value is an encrypted reference rather than
+// the secret itself.
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, SecretPrefix)
+}
+
+// sensitiveKeys are the top-level settings SensitiveFields draws from,
+// whose values are kept out of logs.
+var sensitiveKeys = map[string]bool{
+	"ledger_webhook":     true,
+	"http_token":         true,
+	"telegram_bot_token": true,
+	"notification_sinks": true,
+}
+
+// SensitiveFields returns pointers to the config's values that may hold
+// credentials, keyed by their YAML path, e.g. "ledger_webhook" or
+// "notification_sinks[0].url". Empty values are left out.
+func (c *Config) SensitiveFields() map[string]*string {
+	fields := map[string]*string{}
+	for name, value := range map[string]*string{
+		"ledger_webhook":     &c.LedgerWebhook,
+		"http_token":         &c.HTTPToken,
+		"telegram_bot_token": &c.TelegramBotToken,
+	} {
+		if *value != "" {
+			fields[name] = value
+		}
+	}
+	for i := range c.NotificationSinks {
+		if c.NotificationSinks[i].URL != "" {
+			fields[fmt.Sprintf("notification_sinks[%d].url", i)] = &c.NotificationSinks[i].URL
+		}
+	}
+	return fields
+}
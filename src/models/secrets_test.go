@@ -0,0 +1,46 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSensitiveFields(t *testing.T) {
+	config := ConfigDefaults()
+	assert.Empty(t, config.SensitiveFields())
+
+	config.LedgerWebhook = "https://example.com/hook?token=abc"
+	config.NotificationSinks = []NotificationSink{
+		{Type: SinkDesktop},
+		{Type: SinkSlack, URL: "https://hooks.slack.com/services/T/B/x"},
+	}
+	fields := config.SensitiveFields()
+	assert.Len(t, fields, 2)
+	assert.Equal(t, "https://example.com/hook?token=abc", *fields["ledger_webhook"])
+
+	*fields["notification_sinks[1].url"] = "enc:keychain:notification_sinks[1].url"
+	assert.Equal(t, "enc:keychain:notification_sinks[1].url", config.NotificationSinks[1].URL)
+}
+
+func TestIsSecretRef(t *testing.T) {
+	assert.True(t, IsSecretRef("enc:age:YWdl"))
+	assert.False(t, IsSecretRef("https://example.com"))
+	assert.False(t, IsSecretRef(""))
+}
+
+func TestValidateAcceptsSecretRefs(t *testing.T) {
+	config := ConfigDefaults()
+	config.LedgerWebhook = "enc:keychain:ledger_webhook"
+	config.TelegramBotToken = "enc:age:YWdlLWVuY3J5cHRpb24ub3Jn"
+	config.TelegramChatID = "-1001234567890"
+	config.NotificationSinks = []NotificationSink{{Type: SinkSlack, URL: "enc:keychain:notification_sinks[0].url"}}
+	assert.NoError(t, config.Validate())
+
+	data, err := yaml.Marshal(config)
+	assert.NoError(t, err)
+	violations, err := ValidateConfigYAML(data)
+	assert.NoError(t, err)
+	assert.Empty(t, violations)
+}
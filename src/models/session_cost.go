@@ -0,0 +1,26 @@
+package models
+
+import "sort"
+
+// SessionCost is one Claude Code session's spend, as reported by
+// `ccusage session`.
+type SessionCost struct {
+	Name string  `json:"name"` // Project directory, relative to the home directory
+	Cost float64 `json:"cost"`
+}
+
+// SessionsOverCap returns the sessions whose cost has reached limit, most
+// expensive first, or nil when limit is 0.
+func SessionsOverCap(sessions []SessionCost, limit float64) []SessionCost {
+	if limit <= 0 {
+		return nil
+	}
+	var over []SessionCost
+	for _, s := range sessions {
+		if s.Cost >= limit {
+			over = append(over, s)
+		}
+	}
+	sort.SliceStable(over, func(i, j int) bool { return over[i].Cost > over[j].Cost })
+	return over
+}
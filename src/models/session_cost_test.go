@@ -0,0 +1,14 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionsOverCap(t *testing.T) {
+	sessions := []SessionCost{{Name: "docs", Cost: 1}, {Name: "api", Cost: 5}, {Name: "runaway", Cost: 9.5}}
+	assert.Equal(t, []SessionCost{{Name: "runaway", Cost: 9.5}, {Name: "api", Cost: 5}}, SessionsOverCap(sessions, 5))
+	assert.Nil(t, SessionsOverCap(sessions, 10))
+	assert.Nil(t, SessionsOverCap(sessions, 0), "0 disables the cap")
+}
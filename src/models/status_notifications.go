@@ -0,0 +1,62 @@
+package models
+
+import (
+	"fmt"
+
+	"cc-dailyuse-bar/src/lib"
+)
+
+// MaxRemindEvery caps remind_every at a day, in minutes.
+const MaxRemindEvery = 24 * 60
+
+// NotifyPolicy says how reaching a status or alert level is announced: one
+// notification, then, with RemindEvery, a reminder every so many minutes
+// while spend keeps rising, until the alert is acknowledged from the tray.
+type NotifyPolicy struct {
+	RemindEvery int `yaml:"remind_every,omitempty" json:"remind_every,omitempty"` // minutes; 0 notifies once
+}
+
+// StatusNotifications turns on notifications for reaching yellow or red.
+// A nil entry sends none for that status.
+type StatusNotifications struct {
+	Yellow *NotifyPolicy `yaml:"yellow,omitempty" json:"yellow,omitempty"`
+	Red    *NotifyPolicy `yaml:"red,omitempty" json:"red,omitempty"`
+}
+
+// For returns the entry for status, or nil.
+func (s StatusNotifications) For(status AlertStatus) *NotifyPolicy {
+	switch status {
+	case Yellow:
+		return s.Yellow
+	case Red:
+		return s.Red
+	default:
+		return nil
+	}
+}
+
+// Validate checks that remind_every is a number of minutes up to a day.
+func (p *NotifyPolicy) Validate(field string) error {
+	if p != nil && (p.RemindEvery < 0 || p.RemindEvery > MaxRemindEvery) {
+		return lib.ValidationError(fmt.Sprintf("%s.remind_every must be between 0 and %d minutes", field, MaxRemindEvery))
+	}
+	return nil
+}
+
+// NotifyPolicyFor returns how state's status is announced and the name it
+// goes by: the matched alert level's notify setting, falling back to the
+// status_notifications entry for its status. It returns nil when nothing
+// should be sent.
+func (c *Config) NotifyPolicyFor(state *UsageState) (string, *NotifyPolicy) {
+	if state.Level != "" {
+		for _, level := range c.AlertLevels {
+			if level.Name == state.Level && level.Notify != nil {
+				return level.Name, level.Notify
+			}
+		}
+	}
+	if policy := c.StatusNotifications.For(state.Status); policy != nil {
+		return state.Status.String(), policy
+	}
+	return "", nil
+}
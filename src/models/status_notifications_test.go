@@ -0,0 +1,57 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfig_NotifyPolicyFor(t *testing.T) {
+	once, remind := &NotifyPolicy{}, &NotifyPolicy{RemindEvery: 15}
+	config := ConfigDefaults()
+	config.StatusNotifications = StatusNotifications{Yellow: once, Red: remind}
+	config.AlertLevels = []AlertLevel{
+		{Name: "50%", Threshold: 10, Status: Yellow},
+		{Name: "75%", Threshold: 15, Status: Yellow, Notify: &NotifyPolicy{RemindEvery: 60}},
+	}
+	tests := []struct {
+		name     string
+		state    UsageState
+		wantName string
+		want     *NotifyPolicy
+	}{
+		{"green", UsageState{Status: Green}, "", nil},
+		{"yellow", UsageState{Status: Yellow}, "High", once},
+		{"red", UsageState{Status: Red}, "Critical", remind},
+		{"level without notify", UsageState{Status: Yellow, Level: "50%"}, "High", once},
+		{"level with notify", UsageState{Status: Yellow, Level: "75%"}, "75%", config.AlertLevels[1].Notify},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, policy := config.NotifyPolicyFor(&tt.state)
+			assert.Equal(t, tt.wantName, name)
+			assert.Same(t, tt.want, policy)
+		})
+	}
+}
+
+func TestStatusNotifications_YAML(t *testing.T) {
+	var config Config
+	assert.NoError(t, yaml.Unmarshal([]byte("status_notifications:\n  yellow: {}\n  red: {remind_every: 15}\n"), &config))
+	assert.Equal(t, &NotifyPolicy{}, config.StatusNotifications.Yellow, "an empty entry still notifies")
+	assert.Equal(t, 15, config.StatusNotifications.Red.RemindEvery)
+}
+
+func TestConfig_Validate_RemindEvery(t *testing.T) {
+	config := ConfigDefaults()
+	config.StatusNotifications.Red = &NotifyPolicy{RemindEvery: MaxRemindEvery}
+	assert.NoError(t, config.Validate())
+
+	config.StatusNotifications.Red.RemindEvery = -1
+	assert.ErrorContains(t, config.Validate(), "status_notifications.red.remind_every must be between 0 and 1440 minutes")
+
+	config.StatusNotifications.Red = nil
+	config.AlertLevels = []AlertLevel{{Name: "half", Threshold: 10, Status: Yellow, Notify: &NotifyPolicy{RemindEvery: MaxRemindEvery + 1}}}
+	assert.ErrorContains(t, config.Validate(), "alert_levels[0].notify.remind_every must be between 0 and 1440 minutes")
+}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// SchemaVersion is the schema_version of every JSON status the app
+// publishes: the status file, the Plasma widget feed, GET /v1/status,
+// `ctl --json`, and `run --print --format json`. They share it and the
+// green/yellow/red/unknown status names. It is bumped only when a field is
+// removed or renamed, changes type, unit, or meaning, or becomes nullable;
+// adding fields does not bump it, so consumers should ignore fields they
+// don't know.
+const SchemaVersion = 1
+
+// StatusSnapshotVersion is the status file's "version", which predates
+// schema_version and always equals it.
+const StatusSnapshotVersion = SchemaVersion
+
+// StatusSnapshot is the latest state as written to the status file for
+// widgets and scripts. Its JSON shape is a public interface documented in
+// the README; keep it stable.
+type StatusSnapshot struct {
+	SchemaVersion   int       `json:"schema_version"`
+	Version         int       `json:"version"` // StatusSnapshotVersion, for readers of earlier releases
+	UpdatedAt       time.Time `json:"updated_at"`
+	Available       bool      `json:"available"`
+	Status          string    `json:"status"`       // green, yellow, red, or unknown
+	StatusLabel     string    `json:"status_label"` // OK, High, Critical, or Unknown
+	Level           string    `json:"level,omitempty"`
+	Title           string    `json:"title"` // menu bar title, e.g. "CC 🟡 $12.40"
+	DailyCost       float64   `json:"daily_cost"`
+	DailyTokens     int       `json:"daily_tokens"`
+	DailyCalls      int       `json:"daily_calls"`
+	WeeklyCost      float64   `json:"weekly_cost"`
+	WeeklyTokens    int       `json:"weekly_tokens"`
+	MonthlyCost     float64   `json:"monthly_cost"`
+	Forecast        float64   `json:"forecast"`         // projected month-end cost
+	YellowThreshold float64   `json:"yellow_threshold"` // today's effective thresholds
+	RedThreshold    float64   `json:"red_threshold"`
+	WeeklyBudget    float64   `json:"weekly_budget,omitempty"`
+	MonthlyBudget   float64   `json:"monthly_budget,omitempty"`
+	Quiet           bool      `json:"quiet,omitempty"`
+	Demo            bool      `json:"demo,omitempty"`
+}
+
+// NewStatusSnapshot summarises state for the status file. Unavailable
+// states keep their last known costs but report status "unknown".
+func NewStatusSnapshot(state *UsageState, config *Config) StatusSnapshot {
+	yellow, red := config.ThresholdsFor(state.LastUpdate.Weekday())
+	snapshot := StatusSnapshot{
+		SchemaVersion:   SchemaVersion,
+		Version:         StatusSnapshotVersion,
+		UpdatedAt:       state.LastUpdate,
+		Available:       state.IsAvailable,
+		Status:          "unknown",
+		StatusLabel:     Unknown.String(),
+		Title:           FormatUnknownTitle(config),
+		DailyCost:       state.DailyCost,
+		DailyTokens:     state.DailyCount,
+		DailyCalls:      state.DailyCalls,
+		WeeklyCost:      state.WeeklyCost,
+		WeeklyTokens:    state.WeeklyCount,
+		MonthlyCost:     state.MonthlyCost,
+		Forecast:        state.Forecast,
+		YellowThreshold: yellow,
+		RedThreshold:    red,
+		WeeklyBudget:    config.WeeklyBudget,
+		MonthlyBudget:   config.MonthlyBudget,
+		Demo:            state.Demo,
+	}
+	if state.IsAvailable && state.Status != Unknown {
+		snapshot.Status = state.Status.ColorName()
+		snapshot.StatusLabel = state.Status.String()
+		snapshot.Level = state.Level
+		snapshot.Title = FormatTitle(state, config)
+		snapshot.Quiet = state.Quiet
+	}
+	return snapshot
+}
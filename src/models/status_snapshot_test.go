@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStatusSnapshot(t *testing.T) {
+	config := ConfigDefaults()
+	config.WeeklyBudget = 100
+	config.MonthlyBudget = 400
+	config.DayThresholds = map[string]ThresholdPair{"weekends": {YellowThreshold: 2, RedThreshold: 4}}
+	saturday := time.Date(2025, 3, 15, 10, 30, 0, 0, time.UTC)
+
+	state := &UsageState{
+		LastUpdate:  saturday,
+		DailyCost:   3,
+		DailyCount:  1200,
+		DailyCalls:  12,
+		WeeklyCost:  40,
+		WeeklyCount: 9000,
+		MonthlyCost: 130,
+		Forecast:    390,
+		Status:      Yellow,
+		Level:       "busy",
+		IsAvailable: true,
+	}
+	snapshot := NewStatusSnapshot(state, config)
+
+	assert.Equal(t, StatusSnapshot{
+		SchemaVersion:   SchemaVersion,
+		Version:         StatusSnapshotVersion,
+		UpdatedAt:       saturday,
+		Available:       true,
+		Status:          "yellow",
+		StatusLabel:     "High",
+		Level:           "busy",
+		Title:           "CC 🟡 $3.00",
+		DailyCost:       3,
+		DailyTokens:     1200,
+		DailyCalls:      12,
+		WeeklyCost:      40,
+		WeeklyTokens:    9000,
+		MonthlyCost:     130,
+		Forecast:        390,
+		YellowThreshold: 2,
+		RedThreshold:    4,
+		WeeklyBudget:    100,
+		MonthlyBudget:   400,
+	}, snapshot)
+
+	data, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"status":"yellow"`)
+	assert.Contains(t, string(data), `"updated_at":"2025-03-15T10:30:00Z"`)
+	assert.NotContains(t, string(data), "quiet", "false flags are omitted")
+}
+
+func TestNewStatusSnapshot_Unavailable(t *testing.T) {
+	state := &UsageState{DailyCost: 5, Status: Green, Level: "ok", IsAvailable: false}
+	snapshot := NewStatusSnapshot(state, ConfigDefaults())
+
+	assert.False(t, snapshot.Available)
+	assert.Equal(t, "unknown", snapshot.Status)
+	assert.Equal(t, "Unknown", snapshot.StatusLabel)
+	assert.Equal(t, UnknownTitle, snapshot.Title)
+	assert.Empty(t, snapshot.Level)
+	assert.Equal(t, 5.0, snapshot.DailyCost, "last known cost is kept")
+}
@@ -0,0 +1,77 @@
+package models
+
+// StatusSymbols overrides the characters shown for each status, e.g.
+// "[OK]", "[!]", "[!!]" for fonts that render emoji poorly. Empty entries
+// keep the default emoji.
+type StatusSymbols struct {
+	Green   string `yaml:"green,omitempty" json:"green,omitempty"`
+	Yellow  string `yaml:"yellow,omitempty" json:"yellow,omitempty"`
+	Red     string `yaml:"red,omitempty" json:"red,omitempty"`
+	Unknown string `yaml:"unknown,omitempty" json:"unknown,omitempty"`
+}
+
+// Symbol returns the override for status, falling back to StatusEmoji.
+func (s StatusSymbols) Symbol(status AlertStatus) string {
+	var override string
+	switch status {
+	case Green:
+		override = s.Green
+	case Yellow:
+		override = s.Yellow
+	case Red:
+		override = s.Red
+	default:
+		override = s.Unknown
+	}
+	if override != "" {
+		return override
+	}
+	return StatusEmoji(status)
+}
+
+// StatusPalette is a built-in set of status symbols. The colored dots are
+// hard to tell apart with red-green color blindness, so the alternatives
+// differ in shape or use blue and orange.
+type StatusPalette string
+
+const (
+	StatusPaletteDefault    StatusPalette = "default"     // 🟢 🟡 🔴 ⚪️
+	StatusPaletteShapes     StatusPalette = "shapes"      // ● ▲ ■ ○
+	StatusPaletteBlueOrange StatusPalette = "blue-orange" // 🔵 🟠 🟥 ⚪️
+)
+
+// paletteSymbols holds each palette's symbols. Critical is a square in
+// blue-orange so it differs from high by shape as well as hue.
+var paletteSymbols = map[StatusPalette]StatusSymbols{
+	StatusPaletteShapes:     {Green: "●", Yellow: "▲", Red: "■", Unknown: "○"},
+	StatusPaletteBlueOrange: {Green: "🔵", Yellow: "🟠", Red: "🟥", Unknown: "⚪️"},
+}
+
+// IsValidStatusPalette reports whether palette is a known palette or
+// empty (the default).
+func IsValidStatusPalette(palette StatusPalette) bool {
+	switch palette {
+	case "", StatusPaletteDefault, StatusPaletteShapes, StatusPaletteBlueOrange:
+		return true
+	default:
+		return false
+	}
+}
+
+// WithPalette fills the entries s leaves empty from palette.
+func (s StatusSymbols) WithPalette(palette StatusPalette) StatusSymbols {
+	base := paletteSymbols[palette]
+	if s.Green == "" {
+		s.Green = base.Green
+	}
+	if s.Yellow == "" {
+		s.Yellow = base.Yellow
+	}
+	if s.Red == "" {
+		s.Red = base.Red
+	}
+	if s.Unknown == "" {
+		s.Unknown = base.Unknown
+	}
+	return s
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestStatusSymbols_Symbol(t *testing.T) {
+	symbols := StatusSymbols{Green: "[OK]", Red: "[!!]"}
+	tests := []struct {
+		status AlertStatus
+		want   string
+	}{
+		{Green, "[OK]"},
+		{Yellow, "🟡"}, // unset entries keep the emoji
+		{Red, "[!!]"},
+		{Unknown, "⚪️"},
+		{AlertStatus(99), "⚪️"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, symbols.Symbol(tt.status), tt.status.String())
+	}
+	assert.Equal(t, "🟢", StatusSymbols{}.Symbol(Green))
+}
+
+func TestStatusSymbols_YAML(t *testing.T) {
+	var config Config
+	assert.NoError(t, yaml.Unmarshal([]byte("status_symbols:\n  yellow: \"[!]\"\n"), &config))
+	assert.Equal(t, StatusSymbols{Yellow: "[!]"}, config.StatusSymbols)
+
+	out, err := yaml.Marshal(ConfigDefaults())
+	assert.NoError(t, err)
+	assert.NotContains(t, string(out), "status_symbols", "unset symbols aren't written")
+}
+
+func TestStatusSymbols_WithPalette(t *testing.T) {
+	tests := []struct {
+		palette StatusPalette
+		want    [4]string // green, yellow, red, unknown
+	}{
+		{"", [4]string{"🟢", "🟡", "🔴", "⚪️"}},
+		{StatusPaletteDefault, [4]string{"🟢", "🟡", "🔴", "⚪️"}},
+		{StatusPaletteShapes, [4]string{"●", "▲", "■", "○"}},
+		{StatusPaletteBlueOrange, [4]string{"🔵", "🟠", "🟥", "⚪️"}},
+	}
+	for _, tt := range tests {
+		symbols := StatusSymbols{}.WithPalette(tt.palette)
+		got := [4]string{symbols.Symbol(Green), symbols.Symbol(Yellow), symbols.Symbol(Red), symbols.Symbol(Unknown)}
+		assert.Equal(t, tt.want, got, string(tt.palette))
+	}
+
+	// status_symbols entries win over the palette.
+	symbols := StatusSymbols{Red: "[!!]"}.WithPalette(StatusPaletteShapes)
+	assert.Equal(t, "[!!]", symbols.Symbol(Red))
+	assert.Equal(t, "▲", symbols.Symbol(Yellow))
+}
+
+func TestFormatTitle_StatusPalette(t *testing.T) {
+	config := ConfigDefaults()
+	config.StatusPalette = StatusPaletteShapes
+	state := &UsageState{DailyCost: 12.4, Status: Yellow, IsAvailable: true}
+	assert.Equal(t, "CC ▲ $12.40", FormatTitle(state, config))
+	assert.Equal(t, "CC ○ Unknown", FormatUnknownTitle(config))
+}
@@ -0,0 +1,33 @@
+package models
+
+// TeamMember is one teammate's usage as read from their exported
+// `ccusage daily --json` file in the shared team directory.
+type TeamMember struct {
+	Name       string  `json:"name"`
+	DailyCount int     `json:"daily_count"`
+	DailyCost  float64 `json:"daily_cost"`
+	WeeklyCost float64 `json:"weekly_cost"`
+	HasToday   bool    `json:"has_today"`       // Export includes an entry for today
+	LatestDate string  `json:"latest_date"`     // Most recent day in the export
+	Error      string  `json:"error,omitempty"` // Set when the export could not be read
+}
+
+// TeamUsage aggregates every teammate's exported usage for today.
+type TeamUsage struct {
+	Members    []TeamMember `json:"members"`
+	DailyCount int          `json:"daily_count"`
+	DailyCost  float64      `json:"daily_cost"`
+	WeeklyCost float64      `json:"weekly_cost"`
+}
+
+// AddMember appends m and folds its usage into the team totals. Members
+// whose export failed to parse are listed but contribute nothing.
+func (t *TeamUsage) AddMember(m TeamMember) {
+	t.Members = append(t.Members, m)
+	if m.Error != "" {
+		return
+	}
+	t.DailyCount += m.DailyCount
+	t.DailyCost += m.DailyCost
+	t.WeeklyCost += m.WeeklyCost
+}
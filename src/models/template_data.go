@@ -1,42 +1,167 @@
 package models
 
 import (
-	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 )
 
 // TemplateData represents data available to display format templates
 type TemplateData struct {
-	Cost   string `json:"cost"`
-	Status string `json:"status"`
-	Date   string `json:"date"`
-	Time   string `json:"time"`
-	Count  int    `json:"count"`
+	Cost           string `json:"cost"`
+	Status         string `json:"status"`
+	Color          string `json:"color"`  // green, yellow, red, or unknown
+	Symbol         string `json:"symbol"` // Status dot, honouring status_symbols
+	Date           string `json:"date"`
+	Time           string `json:"time"`
+	Count          int    `json:"count"`            // Tokens today
+	Tokens         string `json:"tokens"`           // Tokens today, abbreviated, e.g. "1.2M"
+	Calls          int    `json:"calls"`            // API requests today
+	Models         string `json:"models"`           // Models used today, e.g. "opus-4, sonnet-4.5"
+	RemainingToRed string `json:"remaining_to_red"` // Spend left before red, floored at zero; empty without a config
+	Percent        string `json:"percent"`          // Cost as a share of today's red threshold, e.g. "62%"; empty without one
+
+	// The week runs from Monday and the month from the 1st, both including
+	// today.
+	WeekCost      string `json:"week_cost"`
+	WeekTokens    string `json:"week_tokens"`  // Abbreviated, e.g. "2.3M"
+	WeekPercent   string `json:"week_percent"` // Share of weekly_budget spent, e.g. "40%"; empty without one
+	MonthCost     string `json:"month_cost"`
+	MonthForecast string `json:"month_forecast"` // Projected month-end cost
+	MonthPercent  string `json:"month_percent"`  // Share of monthly_budget spent; empty without one
+
+	// Unformatted figures, for arithmetic, comparisons such as
+	// {{if atLeast .CostRaw .RedThreshold}}, and custom formatting such as
+	// {{printf "%.1f" .CostRaw}}.
+	CostRaw          float64 `json:"cost_raw"`         // Today's cost in dollars
+	TokensRaw        int     `json:"tokens_raw"`       // Tokens today, the same as Count
+	PercentRaw       float64 `json:"percent_raw"`      // Cost as a percentage of the spend at which status turns red; 0 without one
+	YellowThreshold  float64 `json:"yellow_threshold"` // Today's thresholds after day_thresholds; 0 without a config
+	RedThreshold     float64 `json:"red_threshold"`
+	WeekCostRaw      float64 `json:"week_cost_raw"`
+	WeekTokensRaw    int     `json:"week_tokens_raw"`
+	WeekPercentRaw   float64 `json:"week_percent_raw"` // 0 without weekly_budget
+	WeeklyBudget     float64 `json:"weekly_budget"`    // 0 when unset
+	MonthCostRaw     float64 `json:"month_cost_raw"`
+	MonthForecastRaw float64 `json:"month_forecast_raw"`
+	MonthPercentRaw  float64 `json:"month_percent_raw"` // 0 without monthly_budget
+	MonthlyBudget    float64 `json:"monthly_budget"`    // 0 when unset
 }
 
 // NewTemplateData creates TemplateData from a UsageState
 func NewTemplateData(usage *UsageState) *TemplateData {
+	return NewTemplateDataWithCostFormat(usage, DefaultCostFormat())
+}
+
+// NewTemplateDataWithCostFormat creates TemplateData from a UsageState,
+// rendering the cost with the given precision and rounding mode, and the
+// date and time in the format's locale
+func NewTemplateDataWithCostFormat(usage *UsageState, format CostFormat) *TemplateData {
 	now := time.Now()
 
 	return &TemplateData{
-		Count:  usage.DailyCount,
-		Cost:   fmt.Sprintf("$%.2f", usage.DailyCost),
-		Status: usage.Status.String(),
-		Date:   now.Format("2006-01-02"),
-		Time:   now.Format("15:04"),
+		Count:     usage.DailyCount,
+		Tokens:    FormatTokens(usage.DailyCount),
+		Calls:     usage.DailyCalls,
+		Models:    strings.Join(usage.ModelNames(), ", "),
+		Cost:      format.Format(usage.DailyCost),
+		Status:    usage.Status.String(),
+		Color:     usage.Status.ColorName(),
+		Symbol:    usage.StatusSymbol(StatusSymbols{}),
+		CostRaw:   usage.DailyCost,
+		TokensRaw: usage.DailyCount,
+		Date:      format.Locale.FormatDate(now),
+		Time:      format.Locale.FormatTime(now),
+
+		WeekCost:         format.Format(usage.WeeklyCost),
+		WeekTokens:       FormatTokens(usage.WeeklyCount),
+		MonthCost:        format.Format(usage.MonthlyCost),
+		MonthForecast:    format.Format(usage.Forecast),
+		WeekCostRaw:      usage.WeeklyCost,
+		WeekTokensRaw:    usage.WeeklyCount,
+		MonthCostRaw:     usage.MonthlyCost,
+		MonthForecastRaw: usage.Forecast,
 	}
 }
 
+// NewTemplateDataForConfig creates TemplateData from a UsageState using the
+// config's cost format, and fills in RemainingToRed, Percent, and the
+// thresholds and budgets from it
+func NewTemplateDataForConfig(usage *UsageState, config *Config) *TemplateData {
+	format := config.CostFormat()
+	data := NewTemplateDataWithCostFormat(usage, format)
+	data.Symbol = usage.StatusSymbol(config.Symbols())
+	if remaining, ok := usage.RemainingToRed(config); ok {
+		data.RemainingToRed = format.Format(math.Max(remaining, 0))
+	}
+	if percent, ok := percentOfRedValue(usage, config); ok {
+		data.PercentRaw = percent
+		data.Percent = formatPercent(percent)
+	}
+	day := usage.LastUpdate
+	if day.IsZero() {
+		day = time.Now()
+	}
+	data.YellowThreshold, data.RedThreshold = config.ThresholdsFor(day.Weekday())
+
+	data.WeeklyBudget, data.MonthlyBudget = config.WeeklyBudget, config.MonthlyBudget
+	if config.WeeklyBudget > 0 {
+		data.WeekPercentRaw = 100 * usage.WeeklyCost / config.WeeklyBudget
+		data.WeekPercent = formatPercent(data.WeekPercentRaw)
+	}
+	if config.MonthlyBudget > 0 {
+		data.MonthPercentRaw = 100 * usage.MonthlyCost / config.MonthlyBudget
+		data.MonthPercent = formatPercent(data.MonthPercentRaw)
+	}
+	return data
+}
+
 // NewTemplateDataWithCustomValues creates TemplateData with specific values
 // Used for testing and custom scenarios
 func NewTemplateDataWithCustomValues(count int, cost float64, status AlertStatus) *TemplateData {
 	now := time.Now()
 
 	return &TemplateData{
-		Count:  count,
-		Cost:   fmt.Sprintf("$%.2f", cost),
-		Status: status.String(),
-		Date:   now.Format("2006-01-02"),
-		Time:   now.Format("15:04"),
+		Count:     count,
+		Tokens:    FormatTokens(count),
+		Cost:      DefaultCostFormat().Format(cost),
+		Status:    status.String(),
+		Color:     status.ColorName(),
+		Symbol:    StatusEmoji(status),
+		CostRaw:   cost,
+		TokensRaw: count,
+		Date:      now.Format("2006-01-02"),
+		Time:      now.Format("15:04"),
+	}
+}
+
+// TemplateFields lists the fields templates can use, e.g. ".Cost", sorted.
+func TemplateFields() []string {
+	t := reflect.TypeOf(TemplateData{})
+	fields := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		fields = append(fields, "."+t.Field(i).Name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// sampleTemplateData is plausible data in status for checking templates:
+// every field set, so templates take the branches real data would.
+func sampleTemplateData(status AlertStatus, config *Config) *TemplateData {
+	state := &UsageState{
+		DailyCost:   12.4,
+		DailyCount:  1_234_000,
+		DailyCalls:  37,
+		WeeklyCost:  61.2,
+		WeeklyCount: 2_310_000,
+		MonthlyCost: 130.5,
+		Forecast:    412,
+		Status:      status,
+		IsAvailable: status != Unknown,
+		Models:      []ModelUsage{{Name: "claude-opus-4", Cost: 12.4}},
 	}
+	return NewTemplateDataForConfig(state, config)
 }
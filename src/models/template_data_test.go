@@ -7,6 +7,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/lib"
 )
 
 func TestNewTemplateData(t *testing.T) {
@@ -99,6 +101,12 @@ func TestTemplateData_DateTimeFormatting(t *testing.T) {
 
 	// Should be today's date (allowing for test execution time)
 	assert.Equal(t, expectedDate, data.Date)
+
+	locale, err := ParseLocale("de-DE")
+	require.NoError(t, err)
+	data = NewTemplateDataWithCostFormat(&UsageState{DailyCost: 1234.5}, CostFormat{Precision: 2, Locale: locale})
+	assert.Regexp(t, `^\d{2}\.\d{2}\.\d{4}$`, data.Date)
+	assert.Equal(t, "1.234,50\u00a0$", data.Cost)
 }
 
 func TestTemplateData_ZeroCostEdgeCase(t *testing.T) {
@@ -318,3 +326,113 @@ func TestTemplateData_RealWorldScenarios(t *testing.T) {
 func formatCost(cost float64) string {
 	return fmt.Sprintf("$%.2f", cost)
 }
+
+func TestNewTemplateDataForConfig_RemainingToRed(t *testing.T) {
+	config := ConfigDefaults()
+	precision := 1
+	config.CostPrecision = &precision
+	state := &UsageState{DailyCost: 12.4, Status: Yellow}
+
+	data := NewTemplateDataForConfig(state, config)
+	assert.Equal(t, "$7.6", data.RemainingToRed)
+	assert.Equal(t, "$12.4", data.Cost)
+
+	state.DailyCost = 25
+	assert.Equal(t, "$0.0", NewTemplateDataForConfig(state, config).RemainingToRed, "floored at zero")
+
+	result, err := lib.NewTemplateEngine().Execute("{{.RemainingToRed}} left", NewTemplateDataForConfig(&UsageState{DailyCost: 5}, config))
+	require.NoError(t, err)
+	assert.Equal(t, "$15.0 left", result)
+
+	config.AlertLevels = []AlertLevel{{Name: "busy", Threshold: 5, Status: Yellow}}
+	assert.Empty(t, NewTemplateDataForConfig(state, config).RemainingToRed)
+}
+
+func TestNewTemplateData_Models(t *testing.T) {
+	state := &UsageState{Models: []ModelUsage{
+		{Name: "claude-opus-4-20250514"},
+		{Name: "claude-sonnet-4-5-20250929"},
+	}}
+	result, err := lib.NewTemplateEngine().Execute("Models: {{.Models}}", NewTemplateData(state))
+	require.NoError(t, err)
+	assert.Equal(t, "Models: opus-4, sonnet-4.5", result)
+	assert.Empty(t, NewTemplateData(&UsageState{}).Models)
+}
+
+func TestNewTemplateData_Symbol(t *testing.T) {
+	state := &UsageState{DailyCost: 25, Status: Red}
+	assert.Equal(t, "🔴", NewTemplateData(state).Symbol)
+
+	config := ConfigDefaults()
+	config.StatusSymbols = StatusSymbols{Red: "[!!]"}
+	result, err := lib.NewTemplateEngine().Execute("{{.Symbol}} {{.Cost}}", NewTemplateDataForConfig(state, config))
+	require.NoError(t, err)
+	assert.Equal(t, "[!!] $25.00", result)
+}
+
+func TestNewTemplateDataForConfig_RawFields(t *testing.T) {
+	config := ConfigDefaults()
+	config.DayThresholds = map[string]ThresholdPair{"weekends": {YellowThreshold: 2, RedThreshold: 4}}
+	monday := time.Date(2025, 3, 17, 9, 0, 0, 0, time.UTC)
+	state := &UsageState{DailyCost: 15.75, DailyCount: 1_234_567, Status: Yellow, LastUpdate: monday}
+
+	data := NewTemplateDataForConfig(state, config)
+	assert.Equal(t, 15.75, data.CostRaw)
+	assert.Equal(t, 1_234_567, data.TokensRaw)
+	assert.InDelta(t, 78.75, data.PercentRaw, 1e-9)
+	assert.Equal(t, "79%", data.Percent)
+	assert.Equal(t, 10.0, data.YellowThreshold)
+	assert.Equal(t, 20.0, data.RedThreshold)
+
+	result, err := config.DisplayTemplateEngine().Execute(
+		`{{printf "%.1f" .CostRaw}} of {{printf "%.0f" .RedThreshold}} ({{printf "%.1f" .PercentRaw}}%), {{.TokensRaw}} tokens`, data)
+	require.NoError(t, err)
+	assert.Equal(t, "15.8 of 20 (78.8%), 1234567 tokens", result)
+
+	state.LastUpdate = monday.AddDate(0, 0, 5)
+	data = NewTemplateDataForConfig(state, config)
+	assert.Equal(t, 4.0, data.RedThreshold, "thresholds follow day_thresholds for the data's day")
+	assert.InDelta(t, 393.75, data.PercentRaw, 1e-9, "past red the percentage keeps climbing")
+
+	plain := NewTemplateData(state)
+	assert.Equal(t, 15.75, plain.CostRaw)
+	assert.Zero(t, plain.RedThreshold, "no thresholds without a config")
+}
+
+func TestNewTemplateDataForConfig_WeekAndMonth(t *testing.T) {
+	config := ConfigDefaults()
+	config.WeeklyBudget = 150
+	config.MonthlyBudget = 400
+	state := &UsageState{
+		DailyCost: 4, WeeklyCost: 61.2, WeeklyCount: 2_310_000,
+		MonthlyCost: 180, Forecast: 412.5, Status: Green,
+	}
+
+	data := NewTemplateDataForConfig(state, config)
+	assert.Equal(t, "$61.20", data.WeekCost)
+	assert.Equal(t, "2.3M", data.WeekTokens)
+	assert.Equal(t, "41%", data.WeekPercent)
+	assert.Equal(t, "$180.00", data.MonthCost)
+	assert.Equal(t, "$412.50", data.MonthForecast)
+	assert.Equal(t, "45%", data.MonthPercent)
+	assert.Equal(t, 61.2, data.WeekCostRaw)
+	assert.Equal(t, 2_310_000, data.WeekTokensRaw)
+	assert.InDelta(t, 40.8, data.WeekPercentRaw, 1e-9)
+	assert.Equal(t, 180.0, data.MonthCostRaw)
+	assert.Equal(t, 412.5, data.MonthForecastRaw)
+	assert.Equal(t, 45.0, data.MonthPercentRaw)
+	assert.Equal(t, 150.0, data.WeeklyBudget)
+	assert.Equal(t, 400.0, data.MonthlyBudget)
+
+	config.DisplayFormat = "today {{.Cost}} / month {{.MonthCost}}"
+	whole := 0
+	config.TitleCostPrecision = &whole
+	state.IsAvailable = true
+	assert.Equal(t, "today $4 / month $180", FormatTitle(state, config), "the title uses title_cost_precision throughout")
+
+	config.WeeklyBudget, config.MonthlyBudget = 0, 0
+	data = NewTemplateDataForConfig(state, config)
+	assert.Empty(t, data.WeekPercent, "no share without a budget")
+	assert.Empty(t, data.MonthPercent)
+	assert.Zero(t, data.WeekPercentRaw)
+}
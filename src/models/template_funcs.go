@@ -0,0 +1,93 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// displayTemplateFuncs are the helpers display templates can call to
+// render differently per status or against thresholds, e.g.
+// "{{.Symbol}}{{ifAlert .Status (print " " .Cost)}}" for the dot alone
+// while green and the cost too once yellow or red. Statuses are given as
+// {{.Status}} or {{.Color}}.
+func displayTemplateFuncs(config *Config) template.FuncMap {
+	symbols := config.Symbols()
+	return template.FuncMap{
+		"ifGreen":  statusFunc(Green),
+		"ifYellow": statusFunc(Yellow),
+		"ifRed":    statusFunc(Red),
+		"ifAlert": func(status string, text interface{}) string {
+			if s := templateStatus(status); s == Yellow || s == Red {
+				return fmt.Sprint(text)
+			}
+			return ""
+		},
+		// colorize puts the status's symbol, honouring status_symbols and
+		// screen_reader, before text.
+		"colorize": func(status string, text interface{}) string {
+			s := templateStatus(status)
+			if config.ScreenReader {
+				return s.String() + " " + fmt.Sprint(text)
+			}
+			return symbols.Symbol(s) + " " + fmt.Sprint(text)
+		},
+		"atLeast": func(a, b interface{}) (bool, error) {
+			x, y, err := templateNumbers("atLeast", a, b)
+			return x >= y, err
+		},
+		"below": func(a, b interface{}) (bool, error) {
+			x, y, err := templateNumbers("below", a, b)
+			return x < y, err
+		},
+	}
+}
+
+// statusFunc returns a helper that renders text only for want.
+func statusFunc(want AlertStatus) func(string, interface{}) string {
+	return func(status string, text interface{}) string {
+		if templateStatus(status) == want {
+			return fmt.Sprint(text)
+		}
+		return ""
+	}
+}
+
+// templateStatus reads a status label ("High") or color name ("yellow"),
+// ignoring case; anything else is Unknown.
+func templateStatus(s string) AlertStatus {
+	for _, status := range []AlertStatus{Green, Yellow, Red} {
+		if strings.EqualFold(s, status.String()) || strings.EqualFold(s, status.ColorName()) {
+			return status
+		}
+	}
+	return Unknown
+}
+
+// templateNumbers converts a and b for a comparison helper, so costs
+// (float) and counts (int) compare with each other and with literals.
+func templateNumbers(helper string, a, b interface{}) (float64, float64, error) {
+	x, ok := templateNumber(a)
+	if !ok {
+		return 0, 0, fmt.Errorf("%s: %v is not a number", helper, a)
+	}
+	y, ok := templateNumber(b)
+	if !ok {
+		return 0, 0, fmt.Errorf("%s: %v is not a number", helper, b)
+	}
+	return x, y, nil
+}
+
+func templateNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	}
+	return 0, false
+}
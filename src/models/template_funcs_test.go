@@ -0,0 +1,81 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisplayTemplateFuncs_Status(t *testing.T) {
+	config := ConfigDefaults()
+	config.DisplayFormat = `{{.Symbol}}{{ifAlert .Status (print " " .Cost)}}`
+
+	tests := []struct {
+		status AlertStatus
+		cost   float64
+		want   string
+	}{
+		{Green, 4, "🟢"},
+		{Yellow, 12.4, "🟡 $12.40"},
+		{Red, 25, "🔴 $25.00"},
+	}
+	for _, tt := range tests {
+		state := &UsageState{DailyCost: tt.cost, Status: tt.status, IsAvailable: true}
+		assert.Equal(t, tt.want, FormatTitle(state, config), tt.status.String())
+	}
+
+	engine := config.DisplayTemplateEngine()
+	data := NewTemplateDataForConfig(&UsageState{DailyCost: 25, DailyCalls: 9, Status: Red}, config)
+	for tmpl, want := range map[string]string{
+		`{{ifRed .Status "hot"}}`:               "hot",
+		`{{ifRed .Color .Calls}}`:               "9",
+		`{{ifYellow .Status "warm"}}`:           "",
+		`{{ifGreen "ok" "fine"}}`:               "fine",
+		`{{ifRed "CRITICAL" "any case"}}`:       "any case",
+		`{{colorize "yellow" .Cost}}`:           "🟡 $25.00",
+		`{{colorize .Color "spent"}}`:           "🔴 spent",
+		`{{colorize "bogus" "unknown status"}}`: "⚪️ unknown status",
+	} {
+		got, err := engine.Execute(tmpl, data)
+		require.NoError(t, err, tmpl)
+		assert.Equal(t, want, got, tmpl)
+	}
+}
+
+func TestDisplayTemplateFuncs_ColorizeSymbols(t *testing.T) {
+	config := ConfigDefaults()
+	config.StatusSymbols = StatusSymbols{Yellow: "[!]"}
+	got, err := config.DisplayTemplateEngine().Execute(`{{colorize "yellow" "x"}}`, &TemplateData{})
+	require.NoError(t, err)
+	assert.Equal(t, "[!] x", got)
+
+	config.ScreenReader = true
+	got, err = config.DisplayTemplateEngine().Execute(`{{colorize "yellow" "x"}}`, &TemplateData{})
+	require.NoError(t, err)
+	assert.Equal(t, "High x", got)
+}
+
+func TestDisplayTemplateFuncs_Comparisons(t *testing.T) {
+	config := ConfigDefaults()
+	engine := config.DisplayTemplateEngine()
+	data := NewTemplateDataForConfig(&UsageState{DailyCost: 15, DailyCount: 2_000_000, Status: Yellow}, config)
+
+	for tmpl, want := range map[string]string{
+		`{{if atLeast .CostRaw .YellowThreshold}}over{{end}}`: "over",
+		`{{if atLeast .CostRaw .RedThreshold}}red{{end}}`:     "",
+		`{{if below .CostRaw 20}}under{{end}}`:                "under",
+		`{{if atLeast .Count 1000000}}busy{{end}}`:            "busy",
+		`{{if below 1 2.5}}mixed{{end}}`:                      "mixed",
+	} {
+		got, err := engine.Execute(tmpl, data)
+		require.NoError(t, err, tmpl)
+		assert.Equal(t, want, got, tmpl)
+	}
+
+	_, err := engine.Execute(`{{atLeast .Cost 5}}`, data)
+	assert.ErrorContains(t, err, "failed to execute template")
+
+	config.DisplayFormat = `{{if atLeast .Cost 5}}x{{end}}`
+	assert.ErrorContains(t, config.Validate(), "display_format is invalid", "comparing formatted text is caught up front")
+}
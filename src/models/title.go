@@ -0,0 +1,243 @@
+package models
+
+import "fmt"
+
+// UnknownTitle is the menu bar title while no usage data is available,
+// with the default symbols; see FormatUnknownTitle.
+const UnknownTitle = "CC ⚪️ Unknown"
+
+// TitleMode chooses how much the menu bar title shows.
+type TitleMode string
+
+// Supported title modes.
+const (
+	TitleModeFull    TitleMode = "full"    // "CC 🟡 $12.40"
+	TitleModeCompact TitleMode = "compact" // "🟡"; the cost stays in the tooltip and menu
+)
+
+// IsValidTitleMode reports whether mode is one of the supported values.
+// The empty string is accepted and treated as TitleModeFull.
+func IsValidTitleMode(mode TitleMode) bool {
+	switch mode {
+	case "", TitleModeFull, TitleModeCompact:
+		return true
+	default:
+		return false
+	}
+}
+
+// TitleDisplay chooses the figure shown after the status in the menu bar
+// title.
+type TitleDisplay string
+
+// Supported title displays, in the order the tray's Cycle display item
+// steps through them.
+const (
+	TitleDisplayCost    TitleDisplay = "cost"    // "CC 🟡 $12.40"
+	TitleDisplayTokens  TitleDisplay = "tokens"  // "CC 🟡 1.2M"
+	TitleDisplayPercent TitleDisplay = "percent" // "CC 🟡 62%" of today's red threshold
+	TitleDisplayNone    TitleDisplay = "none"    // "CC 🟡"
+)
+
+var titleDisplays = []TitleDisplay{TitleDisplayCost, TitleDisplayTokens, TitleDisplayPercent, TitleDisplayNone}
+
+// IsValidTitleDisplay reports whether display is one of the supported
+// values. The empty string is accepted and treated as TitleDisplayCost.
+func IsValidTitleDisplay(display TitleDisplay) bool {
+	if display == "" {
+		return true
+	}
+	for _, d := range titleDisplays {
+		if d == display {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the display after d, wrapping from none back to cost.
+func (d TitleDisplay) Next() TitleDisplay {
+	for i, candidate := range titleDisplays {
+		if candidate == d {
+			return titleDisplays[(i+1)%len(titleDisplays)]
+		}
+	}
+	return titleDisplays[1] // "" is cost
+}
+
+// TitleTruncation chooses how a title wider than title_max_width is
+// shortened.
+type TitleTruncation string
+
+// Supported truncation policies.
+const (
+	TitleTruncationEnd      TitleTruncation = "end"      // "CC 🟡 $12.…"
+	TitleTruncationMiddle   TitleTruncation = "middle"   // "CC 🟡…2.40"
+	TitleTruncationSegments TitleTruncation = "segments" // "🟡 $12.40": drop "CC", then the figure, then cut the end
+)
+
+// IsValidTitleTruncation reports whether policy is one of the supported
+// values. The empty string is accepted and treated as TitleTruncationEnd.
+func IsValidTitleTruncation(policy TitleTruncation) bool {
+	switch policy {
+	case "", TitleTruncationEnd, TitleTruncationMiddle, TitleTruncationSegments:
+		return true
+	default:
+		return false
+	}
+}
+
+// StatusEmoji returns the colored dot shown for status in the menu bar.
+func StatusEmoji(status AlertStatus) string {
+	switch status {
+	case Green:
+		return "🟢"
+	case Yellow:
+		return "🟡"
+	case Red:
+		return "🔴"
+	default:
+		return "⚪️"
+	}
+}
+
+// StatusSymbol prefers the matched alert level's symbol, falling back to
+// the status's entry in symbols.
+func (u *UsageState) StatusSymbol(symbols StatusSymbols) string {
+	if u.LevelSymbol != "" {
+		return u.LevelSymbol
+	}
+	return symbols.Symbol(u.Status)
+}
+
+// FormatTitle renders the compact menu bar title for an available state.
+// Demo data is labelled so screenshots can't be mistaken for real spend.
+// With screen_reader the status is a word ("CC High $12.40"); the compact
+// title_mode shows the status alone, and a display_format replaces
+// everything but the demo label and activity indicator. Titles wider
+// than title_max_width are shortened as title_truncation says.
+func FormatTitle(state *UsageState, config *Config) string {
+	return fitTitle(titleSegments(state, config), config)
+}
+
+// titleSegments splits the title into its space-separated parts.
+func titleSegments(state *UsageState, config *Config) []titleSegment {
+	symbol := titleSymbol(state, config)
+	if config.DisplayFormat != "" {
+		if text, ok := displayFormatTitle(state, config, symbol); ok {
+			var segments []titleSegment
+			if state.Demo {
+				segments = append(segments, titleSegment{"DEMO", segmentDemo})
+			}
+			segments = append(segments, titleSegment{text, segmentStatus})
+			return appendActivity(segments, state, config)
+		}
+	}
+
+	var segments []titleSegment
+	if config.TitleMode != TitleModeCompact {
+		segments = append(segments, titleSegment{"CC", segmentPrefix})
+	}
+	if state.Demo {
+		segments = append(segments, titleSegment{"DEMO", segmentDemo})
+	}
+	segments = append(segments, titleSegment{symbol, segmentStatus})
+	if config.TitleMode == TitleModeCompact {
+		return appendActivity(segments, state, config)
+	}
+	if value := titleValue(state, config); value != "" {
+		segments = append(segments, titleSegment{value, segmentValue})
+	}
+	return appendActivity(segments, state, config)
+}
+
+// titleSymbol is the status part of the title: the status symbol, or with
+// screen_reader its word, dimmed while quiet_hours suspends polling.
+func titleSymbol(state *UsageState, config *Config) string {
+	symbol := state.StatusSymbol(config.Symbols())
+	if state.Paused {
+		symbol = "💤"
+	}
+	if config.ScreenReader {
+		symbol = state.Status.String()
+		if state.Paused {
+			symbol = "Paused"
+		}
+	}
+	return symbol
+}
+
+// appendActivity adds the activity_indicator dot, or with screen_reader
+// "Working" or "Idle". Nothing is added while polling is paused.
+func appendActivity(segments []titleSegment, state *UsageState, config *Config) []titleSegment {
+	if !config.ActivityIndicator || state.Paused {
+		return segments
+	}
+	marker := "○"
+	if state.Active {
+		marker = "●"
+	}
+	if config.ScreenReader {
+		marker = "Idle"
+		if state.Active {
+			marker = "Working"
+		}
+	}
+	return append(segments, titleSegment{marker, segmentActivity})
+}
+
+// titleValue renders the config's title_display figure. Percent falls back
+// to cost when no red threshold applies.
+func titleValue(state *UsageState, config *Config) string {
+	switch config.TitleDisplay {
+	case TitleDisplayTokens:
+		return FormatTokens(state.DailyCount)
+	case TitleDisplayPercent:
+		if percent, ok := percentOfRed(state, config); ok {
+			return percent
+		}
+	case TitleDisplayNone:
+		return ""
+	}
+	return config.TitleCostFormat().Format(state.DailyCost)
+}
+
+// percentOfRed renders today's cost as a share of the spend at which the
+// status turns red, e.g. "62%". ok is false when no red threshold applies.
+func percentOfRed(state *UsageState, config *Config) (string, bool) {
+	percent, ok := percentOfRedValue(state, config)
+	if !ok {
+		return "", false
+	}
+	return formatPercent(percent), true
+}
+
+// percentOfRedValue is percentOfRed's figure, e.g. 62.0.
+func percentOfRedValue(state *UsageState, config *Config) (float64, bool) {
+	remaining, ok := state.RemainingToRed(config)
+	if !ok || state.DailyCost+remaining <= 0 {
+		return 0, false
+	}
+	return 100 * state.DailyCost / (state.DailyCost + remaining), true
+}
+
+func formatPercent(percent float64) string {
+	return fmt.Sprintf("%.0f%%", percent)
+}
+
+// FormatUnknownTitle renders the menu bar title for when no usage data is
+// available, using the config's unknown symbol.
+func FormatUnknownTitle(config *Config) string {
+	symbol := config.Symbols().Symbol(Unknown)
+	if config.ScreenReader {
+		symbol = Unknown.String()
+	}
+	if config.TitleMode == TitleModeCompact {
+		return fitTitle([]titleSegment{{symbol, segmentStatus}}, config)
+	}
+	segments := []titleSegment{{"CC", segmentPrefix}, {symbol, segmentStatus}}
+	if !config.ScreenReader {
+		segments = append(segments, titleSegment{"Unknown", segmentValue})
+	}
+	return fitTitle(segments, config)
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusEmoji(t *testing.T) {
+	assert.Equal(t, "🟢", StatusEmoji(Green))
+	assert.Equal(t, "🟡", StatusEmoji(Yellow))
+	assert.Equal(t, "🔴", StatusEmoji(Red))
+	assert.Equal(t, "⚪️", StatusEmoji(Unknown))
+	assert.Equal(t, "⚪️", StatusEmoji(AlertStatus(99)))
+}
+
+func TestFormatTitle(t *testing.T) {
+	config := ConfigDefaults()
+	state := &UsageState{DailyCost: 16, Status: Yellow, LevelSymbol: "🟠", IsAvailable: true}
+	assert.Equal(t, "CC 🟠 $16.00", FormatTitle(state, config))
+
+	whole := 0
+	config.TitleCostPrecision = &whole
+	state.LevelSymbol = ""
+	assert.Equal(t, "CC 🟡 $16", FormatTitle(state, config))
+}
+
+func TestFormatTitle_Demo(t *testing.T) {
+	state := &UsageState{DailyCost: 3, Status: Green, IsAvailable: true, Demo: true}
+	assert.Equal(t, "CC DEMO 🟢 $3.00", FormatTitle(state, ConfigDefaults()))
+}
+
+func TestFormatTitle_Paused(t *testing.T) {
+	state := &UsageState{DailyCost: 12.4, Status: Yellow, IsAvailable: true, Paused: true}
+	assert.Equal(t, "CC 💤 $12.40", FormatTitle(state, ConfigDefaults()))
+}
+
+func TestFormatTitle_StatusSymbols(t *testing.T) {
+	config := ConfigDefaults()
+	config.StatusSymbols = StatusSymbols{Green: "[OK]", Yellow: "[!]", Red: "[!!]", Unknown: "[?]"}
+
+	state := &UsageState{DailyCost: 16, Status: Yellow, IsAvailable: true}
+	assert.Equal(t, "CC [!] $16.00", FormatTitle(state, config))
+	state.LevelSymbol = "🟠"
+	assert.Equal(t, "CC 🟠 $16.00", FormatTitle(state, config), "alert level symbols still win")
+
+	assert.Equal(t, "CC [?] Unknown", FormatUnknownTitle(config))
+	assert.Equal(t, UnknownTitle, FormatUnknownTitle(ConfigDefaults()))
+}
+
+func TestFormatTitle_Compact(t *testing.T) {
+	config := ConfigDefaults()
+	config.TitleMode = TitleModeCompact
+
+	state := &UsageState{DailyCost: 12.4, Status: Yellow, IsAvailable: true}
+	assert.Equal(t, "🟡", FormatTitle(state, config))
+	state.Demo = true
+	assert.Equal(t, "DEMO 🟡", FormatTitle(state, config))
+	assert.Equal(t, "⚪️", FormatUnknownTitle(config))
+
+	config.ScreenReader = true
+	state.Demo = false
+	assert.Equal(t, "High", FormatTitle(state, config))
+	assert.Equal(t, "Unknown", FormatUnknownTitle(config))
+}
+
+func TestFormatTitle_ActivityIndicator(t *testing.T) {
+	config := ConfigDefaults()
+	state := &UsageState{DailyCost: 12.4, Status: Yellow, IsAvailable: true, Active: true}
+	assert.Equal(t, "CC 🟡 $12.40", FormatTitle(state, config), "off by default")
+
+	config.ActivityIndicator = true
+	assert.Equal(t, "CC 🟡 $12.40 ●", FormatTitle(state, config))
+	state.Active = false
+	assert.Equal(t, "CC 🟡 $12.40 ○", FormatTitle(state, config))
+
+	config.TitleMode = TitleModeCompact
+	assert.Equal(t, "🟡 ○", FormatTitle(state, config))
+
+	config.ScreenReader = true
+	assert.Equal(t, "High Idle", FormatTitle(state, config))
+	state.Active = true
+	assert.Equal(t, "High Working", FormatTitle(state, config))
+
+	config.TitleMode = TitleModeFull
+	config.ScreenReader = false
+	state.Paused = true
+	assert.Equal(t, "CC 💤 $12.40", FormatTitle(state, config), "no dot while paused")
+}
+
+func TestFormatTitle_TitleDisplay(t *testing.T) {
+	config := ConfigDefaults() // red at $20
+	state := &UsageState{DailyCost: 12.4, DailyCount: 1_234_567, Status: Yellow, IsAvailable: true}
+
+	tests := []struct {
+		display TitleDisplay
+		want    string
+	}{
+		{"", "CC 🟡 $12.40"},
+		{TitleDisplayCost, "CC 🟡 $12.40"},
+		{TitleDisplayTokens, "CC 🟡 1.2M"},
+		{TitleDisplayPercent, "CC 🟡 62%"},
+		{TitleDisplayNone, "CC 🟡"},
+	}
+	for _, tt := range tests {
+		config.TitleDisplay = tt.display
+		assert.Equal(t, tt.want, FormatTitle(state, config), string(tt.display))
+	}
+
+	config.TitleDisplay = TitleDisplayPercent
+	config.AlertLevels = []AlertLevel{{Name: "busy", Threshold: 5, Status: Yellow}}
+	assert.Equal(t, "CC 🟡 $12.40", FormatTitle(state, config), "no red threshold falls back to cost")
+}
+
+func TestTitleDisplay_Next(t *testing.T) {
+	assert.Equal(t, TitleDisplayTokens, TitleDisplay("").Next())
+	assert.Equal(t, TitleDisplayTokens, TitleDisplayCost.Next())
+	assert.Equal(t, TitleDisplayPercent, TitleDisplayTokens.Next())
+	assert.Equal(t, TitleDisplayNone, TitleDisplayPercent.Next())
+	assert.Equal(t, TitleDisplayCost, TitleDisplayNone.Next())
+}
@@ -0,0 +1,276 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/bidi"
+	"golang.org/x/text/width"
+)
+
+// Title width bounds; zero leaves the title as long as it comes out.
+const (
+	MinTitleMaxWidth = 2 // one wide character, or a narrow one and "…"
+	MaxTitleMaxWidth = 200
+)
+
+// titleEllipsis marks a truncated title. It is one cell wide.
+const titleEllipsis = "…"
+
+// Bidi controls that TruncateTitle keeps balanced.
+const (
+	rlm = '\u200F' // right-to-left mark
+	pdf = '\u202C' // pops an embedding or override
+	pdi = '\u2069' // pops an isolate
+)
+
+// Title segment importance, least important first: the segments policy
+// drops them in this order.
+const (
+	segmentPrefix   = iota // "CC"
+	segmentActivity        // the activity_indicator dot
+	segmentValue           // the title_display figure, or "Unknown"
+	segmentDemo            // "DEMO", which screenshots must keep
+	segmentStatus          // the status symbol or word
+)
+
+// titleSegment is one space-separated part of the title.
+type titleSegment struct {
+	text       string
+	importance int
+}
+
+func joinTitle(segments []titleSegment) string {
+	parts := make([]string, len(segments))
+	for i, segment := range segments {
+		parts[i] = segment.text
+	}
+	return strings.Join(parts, " ")
+}
+
+// fitTitle joins segments and fits the title into config's
+// title_max_width as its title_truncation says.
+func fitTitle(segments []titleSegment, config *Config) string {
+	title := joinTitle(segments)
+	maxCells := config.TitleMaxWidth
+	if maxCells <= 0 || DisplayWidth(title) <= maxCells {
+		return title
+	}
+	switch config.TitleTruncation {
+	case TitleTruncationMiddle:
+		return TruncateTitleMiddle(title, maxCells)
+	case TitleTruncationSegments:
+		for len(segments) > 1 && DisplayWidth(joinTitle(segments)) > maxCells {
+			least := 0
+			for i, segment := range segments {
+				if segment.importance < segments[least].importance {
+					least = i
+				}
+			}
+			segments = append(segments[:least:least], segments[least+1:]...)
+		}
+		return TruncateTitle(joinTitle(segments), maxCells)
+	}
+	return TruncateTitle(title, maxCells)
+}
+
+// titleClusters splits s into the units a cut must not separate: a base
+// character with its combining marks, variation selectors, emoji skin
+// tone modifiers and tags, anything joined to it by a zero-width joiner,
+// and regional indicator pairs (flags). It approximates Unicode grapheme
+// clusters closely enough for menu bar titles.
+func titleClusters(s string) []string {
+	var clusters []string
+	start, joined := 0, false
+	var prev rune
+	for i, r := range s {
+		extends := i > 0 && (joined || extendsCluster(r) ||
+			isRegionalIndicator(prev) && isRegionalIndicator(r) && !pairedIndicator(s[start:i]))
+		if !extends && i > 0 {
+			clusters = append(clusters, s[start:i])
+			start = i
+		}
+		joined = r == '\u200D'
+		prev = r
+	}
+	if start < len(s) {
+		clusters = append(clusters, s[start:])
+	}
+	return clusters
+}
+
+// extendsCluster reports whether r attaches to the character before it.
+func extendsCluster(r rune) bool {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc):
+		return true
+	case r == '\u200D', // zero-width joiner
+		r >= '\uFE00' && r <= '\uFE0F', // variation selectors
+		r >= 0x1F3FB && r <= 0x1F3FF,   // emoji skin tones
+		r >= 0xE0020 && r <= 0xE007F,   // emoji tags
+		r >= 0xE0100 && r <= 0xE01EF:   // variation selectors supplement
+		return true
+	}
+	return false
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// pairedIndicator reports whether cluster already holds a full flag.
+func pairedIndicator(cluster string) bool {
+	return utf8.RuneCountInString(cluster) >= 2
+}
+
+// clusterWidth is the number of cells a cluster occupies: two for East
+// Asian wide and fullwidth characters, emoji shown as emoji (with U+FE0F
+// or joined into a sequence) and flags; zero for controls and format
+// characters such as bidi marks; one otherwise.
+func clusterWidth(cluster string) int {
+	base, _ := utf8.DecodeRuneInString(cluster)
+	if unicode.In(base, unicode.Cc, unicode.Cf) {
+		return 0
+	}
+	if isRegionalIndicator(base) || strings.ContainsAny(cluster, "\uFE0F\u200D") {
+		return 2
+	}
+	switch width.LookupRune(base).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// DisplayWidth returns how many cells s takes up in a monospaced menu bar
+// or terminal, counting wide CJK characters and emoji as two.
+func DisplayWidth(s string) int {
+	cells := 0
+	for _, cluster := range titleClusters(s) {
+		cells += clusterWidth(cluster)
+	}
+	return cells
+}
+
+// TruncateTitle shortens title to at most maxCells cells (see
+// DisplayWidth), ending it with "…". It cuts between whole characters, so
+// neither a multi-byte rune, an accent, nor an emoji sequence is split.
+// When the kept text ends in right-to-left script, a right-to-left mark
+// keeps the ellipsis beside the cut, and bidi embeddings or isolates left
+// open by the cut are closed so they don't reorder what follows the
+// title. maxCells of zero or less leaves title unchanged.
+func TruncateTitle(title string, maxCells int) string {
+	if maxCells <= 0 || DisplayWidth(title) <= maxCells {
+		return title
+	}
+	head, closers, _ := cutTitle(titleClusters(title), maxCells-1)
+	return strings.TrimRight(head, " ") + titleEllipsis + closers
+}
+
+// TruncateTitleMiddle shortens title like TruncateTitle but keeps its
+// start and end, with "…" in between, e.g. "CC 🟡…2.40".
+func TruncateTitleMiddle(title string, maxCells int) string {
+	if maxCells <= 0 || DisplayWidth(title) <= maxCells {
+		return title
+	}
+	clusters := titleClusters(title)
+	budget := maxCells - 1
+	head, closers, used := cutTitle(clusters, budget-budget/2)
+
+	// The tail gets what the head left, taken whole clusters at a time
+	// from the end.
+	start, cells := len(clusters), 0
+	for start > 0 && used+cells+clusterWidth(clusters[start-1]) <= budget {
+		start--
+		cells += clusterWidth(clusters[start])
+	}
+	tail := strings.TrimLeft(strings.Join(clusters[start:], ""), " ")
+
+	// Isolates and embeddings the tail closes itself need no closer.
+	pending := []rune(closers)
+	depth := 0
+	for _, r := range tail {
+		switch r {
+		case '\u202A', '\u202B', '\u202D', '\u202E', '\u2066', '\u2067', '\u2068':
+			depth++
+		case pdf, pdi:
+			if depth > 0 {
+				depth--
+			} else if len(pending) > 0 && pending[0] == r {
+				pending = pending[1:]
+			}
+		}
+	}
+	return strings.TrimRight(head, " ") + titleEllipsis + string(pending) + tail
+}
+
+// cutTitle keeps the leading clusters that fit in cells. It returns them,
+// followed by a right-to-left mark when they end in right-to-left script
+// so an ellipsis after them stays beside the cut; the closers for the
+// bidi embeddings and isolates they leave open, innermost first; and the
+// cells they take.
+func cutTitle(clusters []string, cells int) (string, string, int) {
+	var kept strings.Builder
+	used := 0
+	var open []rune  // closers for the embeddings and isolates still open
+	var outer []bool // rtl outside each open isolate
+	rtl := false     // whether the last strong character is right-to-left
+	for _, cluster := range clusters {
+		w := clusterWidth(cluster)
+		if used+w > cells {
+			break
+		}
+		used += w
+		kept.WriteString(cluster)
+		for _, r := range cluster {
+			open = trackBidiControl(open, r)
+			switch props, _ := bidi.LookupRune(r); props.Class() {
+			case bidi.R, bidi.AL:
+				rtl = true
+			case bidi.L:
+				rtl = false
+			case bidi.LRI, bidi.RLI, bidi.FSI:
+				outer = append(outer, rtl)
+			case bidi.PDI:
+				// A closed isolate reads as a neutral from outside.
+				if n := len(outer); n > 0 {
+					rtl, outer = outer[n-1], outer[:n-1]
+				}
+			}
+		}
+	}
+
+	head := kept.String()
+	if rtl {
+		head = strings.TrimRight(head, " ") + string(rlm)
+	}
+	var closers strings.Builder
+	for i := len(open) - 1; i >= 0; i-- {
+		closers.WriteRune(open[i])
+	}
+	return head, closers.String(), used
+}
+
+// trackBidiControl updates the stack of closers for the explicit bidi
+// embeddings, overrides, and isolates opened so far.
+func trackBidiControl(open []rune, r rune) []rune {
+	switch r {
+	case '\u202A', '\u202B', '\u202D', '\u202E': // LRE, RLE, LRO, RLO
+		return append(open, pdf)
+	case '\u2066', '\u2067', '\u2068': // LRI, RLI, FSI
+		return append(open, pdi)
+	case pdf:
+		if n := len(open); n > 0 && open[n-1] == pdf {
+			return open[:n-1]
+		}
+	case pdi:
+		// A PDI also closes any embeddings opened inside its isolate.
+		for n := len(open); n > 0; n-- {
+			if open[n-1] == pdi {
+				return open[:n-1]
+			}
+		}
+	}
+	return open
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"CC $12.40", 9},
+		{"CC 🟡 $12.40", 12},
+		{"⚪\uFE0F", 2},
+		{"日本語", 6},
+		{"ＡＢ", 4},
+		{"e\u0301", 1},          // e + combining acute
+		{"👩\u200D💻", 2},         // ZWJ sequence
+		{"👍🏽", 2},               // skin tone modifier
+		{"🇩🇪🇫🇷", 4},             // two flags
+		{"\u2067שלום\u2069", 4}, // isolate controls take no space
+		{"", 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, DisplayWidth(tt.text), tt.text)
+	}
+}
+
+func TestTruncateTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		title    string
+		maxCells int
+		want     string
+	}{
+		{"no limit", "CC 🟡 $12.40 over budget", 0, "CC 🟡 $12.40 over budget"},
+		{"fits", "CC 🟡 $12.40", 12, "CC 🟡 $12.40"},
+		{"cut mid-figure", "CC 🟡 $12.40 over budget", 12, "CC 🟡 $12.4…"},
+		{"trailing space dropped", "CC 🟡 $12.40 over budget", 13, "CC 🟡 $12.40…"},
+		{"emoji not split", "CC 🟡 $12", 4, "CC…"},
+		{"wide CJK not split", "CC 予算超過 $12", 8, "CC 予算…"},
+		{"wide CJK at the edge", "CC 予算超過 $12", 10, "CC 予算超…"},
+		{"accent kept with its letter", "Cafe\u0301 budget", 5, "Cafe\u0301…"},
+		{"flag not split", "🇩🇪🇫🇷🇮🇹", 5, "🇩🇪🇫🇷…"},
+		{"ZWJ sequence not split", "👩\u200D💻👩\u200D💻", 3, "👩\u200D💻…"},
+		{"rtl gets a mark before the ellipsis", "CC שלום עולם", 7, "CC שלו\u200F…"},
+		{"open isolate closed", "CC \u2067שלום עולם\u2069 $3", 7, "CC \u2067שלו\u200F…\u2069"},
+		{"closed isolate left alone", "CC \u2067של\u2069 $12.40", 9, "CC \u2067של\u2069 $1…"},
+		{"open embedding closed", "CC \u202Babc def\u202C", 6, "CC \u202Bab…\u202C"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateTitle(tt.title, tt.maxCells)
+			assert.Equal(t, tt.want, got)
+			if tt.maxCells > 0 {
+				assert.LessOrEqual(t, DisplayWidth(got), tt.maxCells)
+			}
+		})
+	}
+}
+
+func TestFormatTitle_MaxWidth(t *testing.T) {
+	config := ConfigDefaults()
+	config.StatusSymbols = StatusSymbols{Yellow: "[warning]", Unknown: "[no data yet]"}
+	config.TitleMaxWidth = 12
+
+	state := &UsageState{DailyCost: 12.4, Status: Yellow, IsAvailable: true}
+	assert.Equal(t, "CC [warning…", FormatTitle(state, config))
+	assert.Equal(t, "CC [no data…", FormatUnknownTitle(config))
+
+	config.TitleMaxWidth = 0
+	assert.Equal(t, "CC [warning] $12.40", FormatTitle(state, config))
+}
+
+func TestTruncateTitleMiddle(t *testing.T) {
+	tests := []struct {
+		name     string
+		title    string
+		maxCells int
+		want     string
+	}{
+		{"fits", "CC 🟡 $12.40", 12, "CC 🟡 $12.40"},
+		{"keeps both ends", "CC 🟡 $12.40", 10, "CC 🟡…2.40"},
+		{"space at the cut dropped", "CC [warning] $12.40", 9, "CC […2.40"},
+		{"wide CJK not split", "予算超過予算超過", 7, "予…超過"},
+		{"open isolate closed before the tail", "\u2067שלום עולם\u2069 $3", 7, "\u2067שלו\u200F…\u2069 $3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateTitleMiddle(tt.title, tt.maxCells)
+			assert.Equal(t, tt.want, got)
+			assert.LessOrEqual(t, DisplayWidth(got), tt.maxCells)
+		})
+	}
+}
+
+func TestFormatTitle_Truncation(t *testing.T) {
+	state := &UsageState{DailyCost: 12.4, Status: Yellow, IsAvailable: true}
+	tests := []struct {
+		name     string
+		policy   TitleTruncation
+		maxCells int
+		demo     bool
+		want     string
+	}{
+		{"end", TitleTruncationEnd, 10, false, "CC 🟡 $12…"},
+		{"default is end", "", 10, false, "CC 🟡 $12…"},
+		{"middle", TitleTruncationMiddle, 10, false, "CC 🟡…2.40"},
+		{"segments drops the prefix first", TitleTruncationSegments, 10, false, "🟡 $12.40"},
+		{"segments then drops the figure", TitleTruncationSegments, 6, false, "🟡"},
+		{"segments keeps the demo label", TitleTruncationSegments, 12, true, "DEMO 🟡"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := ConfigDefaults()
+			config.TitleMaxWidth = tt.maxCells
+			config.TitleTruncation = tt.policy
+			state.Demo = tt.demo
+			assert.Equal(t, tt.want, FormatTitle(state, config))
+		})
+	}
+
+	config := ConfigDefaults()
+	config.TitleTruncation = TitleTruncationSegments
+	config.ActivityIndicator = true
+	state.Demo = false
+	config.TitleMaxWidth = 12
+	assert.Equal(t, "🟡 $12.40 ○", FormatTitle(state, config), "the prefix goes first")
+	config.TitleMaxWidth = 10
+	assert.Equal(t, "🟡 $12.40", FormatTitle(state, config), "then the activity dot")
+
+	config = ConfigDefaults()
+	config.TitleMaxWidth = 12
+	config.TitleTruncation = TitleTruncationSegments
+	config.StatusSymbols = StatusSymbols{Unknown: "[?]"}
+	assert.Equal(t, "[?] Unknown", FormatUnknownTitle(config))
+	config.TitleMaxWidth = 8
+	config.StatusSymbols = StatusSymbols{Unknown: "[no data yet]"}
+	assert.Equal(t, "[no dat…", FormatUnknownTitle(config), "a lone segment is cut at the end")
+}
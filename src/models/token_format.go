@@ -0,0 +1,29 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// FormatTokens abbreviates a token count for display: 950, 12.3K, 1.2M.
+func FormatTokens(tokens int) string {
+	value, suffix := float64(tokens), ""
+	switch {
+	case tokens >= 1_000_000_000:
+		value, suffix = value/1_000_000_000, "B"
+	case tokens >= 1_000_000:
+		value, suffix = value/1_000_000, "M"
+	case tokens >= 1_000:
+		value, suffix = value/1_000, "K"
+	default:
+		return fmt.Sprintf("%d", tokens)
+	}
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", value), ".0") + suffix
+}
+
+// FormatTokenRate renders a rate of tokens per minute for the tooltip,
+// e.g. "~3.1K tok/min", in locale's number format.
+func FormatTokenRate(perMinute float64, locale *Locale) string {
+	return "~" + locale.FormatTokens(int(math.Round(perMinute))) + " tok/min"
+}
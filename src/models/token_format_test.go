@@ -0,0 +1,34 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTokens(t *testing.T) {
+	tests := []struct {
+		tokens int
+		want   string
+	}{
+		{0, "0"},
+		{950, "950"},
+		{1000, "1K"},
+		{12_345, "12.3K"},
+		{1_200_000, "1.2M"},
+		{3_000_000, "3M"},
+		{2_500_000_000, "2.5B"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, FormatTokens(tt.tokens), "tokens %d", tt.tokens)
+	}
+}
+
+func TestFormatTokenRate(t *testing.T) {
+	assert.Equal(t, "~3.1K tok/min", FormatTokenRate(3_120.4, nil))
+	assert.Equal(t, "~850 tok/min", FormatTokenRate(849.6, nil))
+
+	german, err := ParseLocale("de-DE")
+	assert.NoError(t, err)
+	assert.Equal(t, "~3,1K tok/min", FormatTokenRate(3_120, german))
+}
@@ -0,0 +1,90 @@
+package models
+
+import "time"
+
+// HeatMapWeekdays lists weekdays in the heat map's row order, Monday first.
+var HeatMapWeekdays = []time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday,
+}
+
+// HeatMapCell is the usage observed in one weekday and hour, summed over
+// every such hour in the range.
+type HeatMapCell struct {
+	Tokens int
+	Cost   float64
+}
+
+// UsageHeatMap splits usage over a range of days by weekday and local
+// hour, showing when in the week tokens are burnt.
+type UsageHeatMap struct {
+	Since     time.Time          // first day covered
+	Until     time.Time          // last day covered
+	Cells     [7][24]HeatMapCell // indexed by time.Weekday, then hour
+	Untracked HeatMapCell        // usage observed after a gap, which can't be placed
+}
+
+// BuildHeatMap attributes each increase in cumulative daily usage to the
+// weekday and hour it was observed in, like BuildHourlyHistogram does for
+// a single day. samples must be in time order; ones before since only
+// serve as a baseline.
+func BuildHeatMap(samples []UsageSample, since, until time.Time) UsageHeatMap {
+	h := UsageHeatMap{Since: since, Until: until}
+
+	var prev *UsageSample
+	for i := range samples {
+		s := &samples[i]
+		if s.Time.Before(since) {
+			prev = s
+			continue
+		}
+
+		// Daily usage restarts at midnight, so only a same-day sample is
+		// a baseline.
+		var baseline HeatMapCell
+		tracked := prev != nil && s.Time.Sub(prev.Time) <= MaxSampleGap
+		if prev != nil && sameDate(prev.Time, s.Time) {
+			baseline = HeatMapCell{Tokens: prev.Tokens, Cost: prev.Cost}
+		}
+
+		delta := HeatMapCell{Tokens: max(s.Tokens-baseline.Tokens, 0), Cost: max(s.Cost-baseline.Cost, 0)}
+		cell := &h.Untracked
+		if tracked {
+			cell = &h.Cells[s.Time.Weekday()][s.Time.Hour()]
+		}
+		cell.Tokens += delta.Tokens
+		cell.Cost += delta.Cost
+		prev = s
+	}
+	return h
+}
+
+// MaxTokens returns the highest token count of any cell, for scaling.
+func (h UsageHeatMap) MaxTokens() int {
+	best := 0
+	for _, hours := range h.Cells {
+		for _, cell := range hours {
+			best = max(best, cell.Tokens)
+		}
+	}
+	return best
+}
+
+// Peak returns the weekday and hour with the most tokens, or false when
+// no tracked hour saw any.
+func (h UsageHeatMap) Peak() (time.Weekday, int, bool) {
+	peakDay, peakHour, best := time.Sunday, -1, 0
+	for _, day := range HeatMapWeekdays {
+		for hour, cell := range h.Cells[day] {
+			if cell.Tokens > best {
+				peakDay, peakHour, best = day, hour, cell.Tokens
+			}
+		}
+	}
+	return peakDay, peakHour, peakHour >= 0
+}
+
+func sameDate(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildHeatMap(t *testing.T) {
+	friday := time.Date(2025, 3, 14, 0, 0, 0, 0, time.Local)
+	at := func(day time.Time, h, m int) time.Time {
+		return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute)
+	}
+	saturday := friday.AddDate(0, 0, 1)
+
+	samples := []UsageSample{
+		{Time: at(friday, -1, 50), Tokens: 900, Cost: 9},   // before since: baseline only
+		{Time: at(friday, 0, 5), Tokens: 100, Cost: 1},     // new day starts from 0
+		{Time: at(friday, 9, 0), Tokens: 300, Cost: 3},     // gap: untracked
+		{Time: at(friday, 9, 30), Tokens: 1000, Cost: 5},   // Fri 09: +700
+		{Time: at(friday, 23, 50), Tokens: 1500, Cost: 6},  // gap: untracked
+		{Time: at(saturday, 0, 10), Tokens: 400, Cost: 2},  // Sat 00: +400
+		{Time: at(saturday, 0, 40), Tokens: 400, Cost: 2},  // unchanged
+		{Time: at(saturday, 1, 10), Tokens: 1400, Cost: 4}, // Sat 01: +1000
+	}
+
+	h := BuildHeatMap(samples, friday, saturday)
+	assert.Equal(t, 100, h.Cells[time.Friday][0].Tokens)
+	assert.Equal(t, 700, h.Cells[time.Friday][9].Tokens)
+	assert.InDelta(t, 2.0, h.Cells[time.Friday][9].Cost, 1e-9)
+	assert.Equal(t, 400, h.Cells[time.Saturday][0].Tokens)
+	assert.Equal(t, 1000, h.Cells[time.Saturday][1].Tokens)
+	assert.Equal(t, 700, h.Untracked.Tokens)
+	assert.Equal(t, 1000, h.MaxTokens())
+
+	day, hour, ok := h.Peak()
+	assert.True(t, ok)
+	assert.Equal(t, time.Saturday, day)
+	assert.Equal(t, 1, hour)
+}
+
+func TestUsageHeatMap_PeakEmpty(t *testing.T) {
+	_, _, ok := BuildHeatMap(nil, time.Now(), time.Now()).Peak()
+	assert.False(t, ok)
+}
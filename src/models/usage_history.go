@@ -0,0 +1,199 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// UsageSample is one recorded observation of today's cumulative usage.
+type UsageSample struct {
+	Version int       `json:"v,omitempty"` // UsageSampleVersion when written; 0 in older files
+	Time    time.Time `json:"t"`
+	Cost    float64   `json:"cost"`
+	Tokens  int       `json:"tokens"`
+	Status  string    `json:"status,omitempty"` // AlertStatus.ColorName; empty in older files
+	// Pricing is the ccusage pricing version the cost was computed with;
+	// empty in older files.
+	Pricing string `json:"pricing,omitempty"`
+}
+
+// UsageSampleVersion is the history line format this build writes. Adding
+// an omitempty field that older lines simply lack needs no bump; changing
+// what an existing field means does, along with a sampleMigrations step.
+const UsageSampleVersion = 1
+
+// sampleMigrations[v] upgrades a sample from version v to v+1, so lines
+// written by older builds are read with today's meaning instead of being
+// dropped.
+var sampleMigrations = []func(*UsageSample){
+	// 0 → 1: lines from before versioning have the same fields and meaning.
+	0: func(*UsageSample) {},
+}
+
+// Migrate upgrades s in place to UsageSampleVersion. It returns false and
+// leaves s as read when a newer build wrote it; its known fields are still
+// usable, but rewriting it could lose the ones this build doesn't know.
+func (s *UsageSample) Migrate() bool {
+	if s.Version > UsageSampleVersion {
+		return false
+	}
+	for s.Version < UsageSampleVersion {
+		sampleMigrations[s.Version](s)
+		s.Version++
+	}
+	return true
+}
+
+// MaxSampleGap is the longest gap between consecutive samples over which
+// a cost increase is still attributed to the later sample's hour. Longer
+// gaps mean the app wasn't running, so the spend can't be placed.
+const MaxSampleGap = time.Hour
+
+// HourlyHistogram splits a day's spend by local hour.
+type HourlyHistogram struct {
+	Hours     [24]float64
+	Untracked float64 // Spend observed after a gap (e.g. before the app started)
+}
+
+// BuildHourlyHistogram attributes each increase in cumulative daily cost to
+// the hour it was observed in. samples must be in time order and may
+// include the tail of the previous day, which only serves as a baseline
+// for the first sample of day.
+func BuildHourlyHistogram(samples []UsageSample, day time.Time) HourlyHistogram {
+	var h HourlyHistogram
+	y, m, d := day.Date()
+
+	var prev *UsageSample
+	for i := range samples {
+		s := &samples[i]
+		sy, sm, sd := s.Time.Date()
+		if sy != y || sm != m || sd != d {
+			prev = s
+			continue
+		}
+
+		// Daily cost restarts at midnight, so only a same-day sample is a
+		// baseline; yesterday's sample just shows the app was running.
+		baseline := 0.0
+		tracked := prev != nil && s.Time.Sub(prev.Time) <= MaxSampleGap
+		if prev != nil {
+			if py, pm, pd := prev.Time.Date(); py == y && pm == m && pd == d {
+				baseline = prev.Cost
+			}
+		}
+
+		if delta := s.Cost - baseline; delta > 0 {
+			if tracked {
+				h.Hours[s.Time.Hour()] += delta
+			} else {
+				h.Untracked += delta
+			}
+		}
+		prev = s
+	}
+	return h
+}
+
+// Peak returns the hour with the highest spend, or false when nothing was
+// spent in any tracked hour.
+func (h HourlyHistogram) Peak() (int, float64, bool) {
+	peak, best := -1, 0.0
+	for hour, cost := range h.Hours {
+		if cost > best {
+			peak, best = hour, cost
+		}
+	}
+	return peak, best, peak >= 0
+}
+
+// StatusChange is a transition between alert statuses, observed at Time
+// when today's cost was Cost.
+type StatusChange struct {
+	Time time.Time
+	From AlertStatus
+	To   AlertStatus
+	Cost float64
+}
+
+// BuildStatusChanges lists the status transitions observed on day. Every
+// day starts Green, so a first sample in another status counts as a change
+// from Green. Samples without a recorded status are skipped.
+func BuildStatusChanges(samples []UsageSample, day time.Time) []StatusChange {
+	var changes []StatusChange
+	y, m, d := day.Date()
+	current := Green
+	for _, s := range samples {
+		if sy, sm, sd := s.Time.Date(); sy != y || sm != m || sd != d || s.Status == "" {
+			continue
+		}
+		status, err := ParseAlertStatus(s.Status)
+		if err != nil || status == current {
+			continue
+		}
+		changes = append(changes, StatusChange{Time: s.Time, From: current, To: status, Cost: s.Cost})
+		current = status
+	}
+	return changes
+}
+
+// UnderBudgetStreak counts the consecutive days before day that ended
+// under budget, i.e. whose last recorded status wasn't Red. It stops at
+// the first day that ended Red or has no sample with a status, since the
+// app can't vouch for a day it didn't observe. Day itself isn't counted
+// until it is over.
+func UnderBudgetStreak(samples []UsageSample, day time.Time) int {
+	final := map[string]string{} // YYYY-MM-DD -> last recorded status
+	for _, s := range samples {
+		if s.Status != "" {
+			final[s.Time.Format("2006-01-02")] = s.Status
+		}
+	}
+
+	streak := 0
+	for d := day.AddDate(0, 0, -1); ; d = d.AddDate(0, 0, -1) {
+		name, ok := final[d.Format("2006-01-02")]
+		if !ok {
+			return streak
+		}
+		if status, err := ParseAlertStatus(name); err != nil || status == Red {
+			return streak
+		}
+		streak++
+	}
+}
+
+// DailyTotal is one day's final recorded spend.
+type DailyTotal struct {
+	Date    string  `json:"date"` // YYYY-MM-DD
+	Cost    float64 `json:"cost"`
+	Tokens  int     `json:"tokens"`
+	Status  string  `json:"status,omitempty"` // last recorded AlertStatus.ColorName
+	Tracked bool    `json:"tracked"`          // false when the app recorded nothing that day
+}
+
+// BuildDailyTotals returns the days-long run of days ending with day,
+// oldest first, each with the last sample recorded on it. Days without a
+// sample are included untracked, so charts keep an even time axis.
+func BuildDailyTotals(samples []UsageSample, day time.Time, days int) []DailyTotal {
+	last := map[string]UsageSample{}
+	for _, s := range samples {
+		last[s.Time.Format("2006-01-02")] = s
+	}
+
+	totals := make([]DailyTotal, 0, max(days, 0))
+	for i := days - 1; i >= 0; i-- {
+		date := day.AddDate(0, 0, -i).Format("2006-01-02")
+		total := DailyTotal{Date: date}
+		if s, ok := last[date]; ok {
+			total.Cost, total.Tokens, total.Status, total.Tracked = s.Cost, s.Tokens, s.Status, true
+		}
+		totals = append(totals, total)
+	}
+	return totals
+}
+
+// FormatStreak renders a streak for the menu and notifications, e.g.
+// "🔥 6-day streak under budget".
+func FormatStreak(days int) string {
+	return fmt.Sprintf("🔥 %d-day streak under budget", days)
+}
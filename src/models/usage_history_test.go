@@ -0,0 +1,148 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildHourlyHistogram(t *testing.T) {
+	day := time.Date(2025, 3, 14, 0, 0, 0, 0, time.Local)
+	at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+
+	samples := []UsageSample{
+		{Time: day.Add(-10 * time.Minute), Cost: 40}, // yesterday: baseline only
+		{Time: at(0, 5), Cost: 0.5},                  // new day starts from 0
+		{Time: at(9, 0), Cost: 2},                    // gap: untracked
+		{Time: at(9, 30), Cost: 3},
+		{Time: at(10, 0), Cost: 3},
+		{Time: at(10, 20), Cost: 5.5},
+		{Time: at(14, 10), Cost: 6}, // gap: untracked
+		{Time: at(14, 40), Cost: 9},
+	}
+
+	h := BuildHourlyHistogram(samples, day)
+	assert.InDelta(t, 0.5, h.Hours[0], 1e-9)
+	assert.InDelta(t, 1.0, h.Hours[9], 1e-9)
+	assert.InDelta(t, 2.5, h.Hours[10], 1e-9)
+	assert.InDelta(t, 3.0, h.Hours[14], 1e-9)
+	assert.InDelta(t, 2.0, h.Untracked, 1e-9)
+
+	hour, cost, ok := h.Peak()
+	assert.True(t, ok)
+	assert.Equal(t, 14, hour)
+	assert.InDelta(t, 3.0, cost, 1e-9)
+}
+
+func TestHourlyHistogram_PeakEmpty(t *testing.T) {
+	_, _, ok := BuildHourlyHistogram(nil, time.Now()).Peak()
+	assert.False(t, ok)
+}
+
+func TestBuildStatusChanges(t *testing.T) {
+	day := time.Date(2025, 3, 14, 0, 0, 0, 0, time.Local)
+	at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+
+	samples := []UsageSample{
+		{Time: day.Add(-time.Hour), Cost: 30, Status: "red"}, // yesterday
+		{Time: at(8, 0), Cost: 1, Status: "green"},
+		{Time: at(9, 0), Cost: 4}, // pre-status sample: ignored
+		{Time: at(11, 5), Cost: 10.2, Status: "yellow"},
+		{Time: at(11, 35), Cost: 12, Status: "yellow"},
+		{Time: at(15, 40), Cost: 20.5, Status: "red"},
+		{Time: at(16, 0), Cost: 20.5, Status: "green"}, // e.g. thresholds raised
+	}
+
+	assert.Equal(t, []StatusChange{
+		{Time: at(11, 5), From: Green, To: Yellow, Cost: 10.2},
+		{Time: at(15, 40), From: Yellow, To: Red, Cost: 20.5},
+		{Time: at(16, 0), From: Red, To: Green, Cost: 20.5},
+	}, BuildStatusChanges(samples, day))
+
+	// The first observation of the day counts as a change from Green.
+	late := []UsageSample{{Time: at(15, 0), Cost: 25, Status: "red"}}
+	assert.Equal(t, []StatusChange{{Time: at(15, 0), From: Green, To: Red, Cost: 25}}, BuildStatusChanges(late, day))
+	assert.Empty(t, BuildStatusChanges(nil, day))
+}
+
+func TestUnderBudgetStreak(t *testing.T) {
+	day := time.Date(2025, 3, 14, 10, 0, 0, 0, time.Local)
+	on := func(daysAgo int, status string) UsageSample {
+		return UsageSample{Time: time.Date(2025, 3, 14-daysAgo, 18, 0, 0, 0, time.Local), Status: status}
+	}
+
+	tests := []struct {
+		name    string
+		samples []UsageSample
+		want    int
+	}{
+		{"no history", nil, 0},
+		{
+			name:    "consecutive days",
+			samples: []UsageSample{on(3, "green"), on(2, "yellow"), on(1, "green"), on(0, "red")},
+			want:    3,
+		},
+		{
+			name:    "a red day ends the streak",
+			samples: []UsageSample{on(3, "green"), on(2, "red"), on(1, "green")},
+			want:    1,
+		},
+		{
+			name:    "the day's last status counts",
+			samples: []UsageSample{on(1, "red"), {Time: time.Date(2025, 3, 13, 19, 0, 0, 0, time.Local), Status: "green"}},
+			want:    1,
+		},
+		{
+			name:    "an unobserved day ends the streak",
+			samples: []UsageSample{on(3, "green"), on(1, "green")},
+			want:    1,
+		},
+		{
+			name:    "pre-status samples don't count",
+			samples: []UsageSample{on(2, "green"), {Time: time.Date(2025, 3, 13, 9, 0, 0, 0, time.Local), Cost: 4}},
+			want:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, UnderBudgetStreak(tt.samples, day))
+		})
+	}
+}
+
+func TestBuildDailyTotals(t *testing.T) {
+	day := time.Date(2025, 3, 14, 15, 0, 0, 0, time.Local)
+	samples := []UsageSample{
+		{Time: day.AddDate(0, 0, -5), Cost: 99}, // before the range
+		{Time: day.AddDate(0, 0, -2).Add(-time.Hour), Cost: 3, Tokens: 30, Status: "green"},
+		{Time: day.AddDate(0, 0, -2), Cost: 12, Tokens: 120, Status: "yellow"},
+		{Time: day, Cost: 4.5, Tokens: 45, Status: "green"},
+	}
+
+	totals := BuildDailyTotals(samples, day, 3)
+	assert.Equal(t, []DailyTotal{
+		{Date: "2025-03-12", Cost: 12, Tokens: 120, Status: "yellow", Tracked: true},
+		{Date: "2025-03-13"},
+		{Date: "2025-03-14", Cost: 4.5, Tokens: 45, Status: "green", Tracked: true},
+	}, totals)
+
+	assert.Empty(t, BuildDailyTotals(samples, day, 0))
+}
+
+func TestFormatStreak(t *testing.T) {
+	assert.Equal(t, "🔥 6-day streak under budget", FormatStreak(6))
+}
+
+func TestUsageSample_Migrate(t *testing.T) {
+	assert.Len(t, sampleMigrations, UsageSampleVersion, "every version needs a step up from the one before")
+
+	old := UsageSample{Cost: 2, Tokens: 20}
+	assert.True(t, old.Migrate())
+	assert.Equal(t, UsageSample{Version: UsageSampleVersion, Cost: 2, Tokens: 20}, old)
+
+	newer := UsageSample{Version: UsageSampleVersion + 1, Cost: 3}
+	assert.False(t, newer.Migrate())
+	assert.Equal(t, UsageSampleVersion+1, newer.Version)
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	_ "embed"
+	"time"
+)
+
+// usageOutputSchemaJSON describes UsageOutput's JSON for consumers that
+// want to validate it.
+//
+//go:embed usage_output.schema.json
+var usageOutputSchemaJSON []byte
+
+// UsageOutputSchema returns the embedded JSON Schema for UsageOutput.
+func UsageOutputSchema() []byte {
+	return usageOutputSchemaJSON
+}
+
+// UsageOutput is the machine-readable result of `run --print --format
+// json`. Its shape is a public interface documented in the README and
+// usage_output.schema.json: every field is always present, with null where
+// a value is unknown or not configured. Costs are US dollars as ccusage
+// reports them, not rounded to cost_precision.
+type UsageOutput struct {
+	SchemaVersion int                 `json:"schema_version"`
+	GeneratedAt   time.Time           `json:"generated_at"`
+	UpdatedAt     *time.Time          `json:"updated_at"` // null when unavailable
+	Available     bool                `json:"available"`
+	Status        string              `json:"status"`       // green, yellow, red, or unknown
+	StatusLabel   string              `json:"status_label"` // OK, High, Critical, or Unknown
+	Level         *string             `json:"level"`        // matched alert_levels name
+	Title         string              `json:"title"`
+	Today         *UsageOutputDay     `json:"today"` // null when unavailable
+	Week          *UsageOutputPeriod  `json:"week"`
+	Month         *UsageOutputMonth   `json:"month"`
+	Thresholds    UsageOutputLimits   `json:"thresholds"`
+	Budgets       UsageOutputBudgets  `json:"budgets"`
+	TopSession    *UsageOutputSession `json:"top_session"`
+	Quiet         bool                `json:"quiet"`
+	Demo          bool                `json:"demo"`
+}
+
+// UsageOutputDay is today's usage.
+type UsageOutputDay struct {
+	CostUSD float64 `json:"cost_usd"`
+	Tokens  int     `json:"tokens"`
+	Calls   int     `json:"calls"`
+}
+
+// UsageOutputPeriod is usage since Monday, including today.
+type UsageOutputPeriod struct {
+	CostUSD float64 `json:"cost_usd"`
+	Tokens  int     `json:"tokens"`
+}
+
+// UsageOutputMonth is usage since the 1st, including today.
+type UsageOutputMonth struct {
+	CostUSD     float64 `json:"cost_usd"`
+	ForecastUSD float64 `json:"forecast_usd"` // projected month-end cost
+}
+
+// UsageOutputLimits are today's effective thresholds.
+type UsageOutputLimits struct {
+	YellowUSD float64 `json:"yellow_usd"`
+	RedUSD    float64 `json:"red_usd"`
+}
+
+// UsageOutputBudgets are the configured budgets, null when unset.
+type UsageOutputBudgets struct {
+	WeeklyUSD  *float64 `json:"weekly_usd"`
+	MonthlyUSD *float64 `json:"monthly_usd"`
+}
+
+// UsageOutputSession is today's most expensive session.
+type UsageOutputSession struct {
+	Name    string  `json:"name"`
+	CostUSD float64 `json:"cost_usd"`
+}
+
+// NewUsageOutput summarises state as of now. Unlike the status file, an
+// unavailable state reports no usage rather than the last known values.
+func NewUsageOutput(state *UsageState, config *Config, now time.Time) UsageOutput {
+	day := now
+	if !state.LastUpdate.IsZero() {
+		day = state.LastUpdate
+	}
+	yellow, red := config.ThresholdsFor(day.Weekday())
+	output := UsageOutput{
+		SchemaVersion: SchemaVersion,
+		GeneratedAt:   now,
+		Available:     state.IsAvailable,
+		Status:        "unknown",
+		StatusLabel:   Unknown.String(),
+		Title:         FormatUnknownTitle(config),
+		Thresholds:    UsageOutputLimits{YellowUSD: yellow, RedUSD: red},
+		Demo:          state.Demo,
+	}
+	if config.WeeklyBudget > 0 {
+		budget := config.WeeklyBudget
+		output.Budgets.WeeklyUSD = &budget
+	}
+	if config.MonthlyBudget > 0 {
+		budget := config.MonthlyBudget
+		output.Budgets.MonthlyUSD = &budget
+	}
+	if !state.IsAvailable || state.Status == Unknown {
+		return output
+	}
+
+	updated := state.LastUpdate
+	output.UpdatedAt = &updated
+	output.Status = state.Status.ColorName()
+	output.StatusLabel = state.Status.String()
+	if state.Level != "" {
+		level := state.Level
+		output.Level = &level
+	}
+	output.Title = FormatTitle(state, config)
+	output.Today = &UsageOutputDay{CostUSD: state.DailyCost, Tokens: state.DailyCount, Calls: state.DailyCalls}
+	output.Week = &UsageOutputPeriod{CostUSD: state.WeeklyCost, Tokens: state.WeeklyCount}
+	output.Month = &UsageOutputMonth{CostUSD: state.MonthlyCost, ForecastUSD: state.Forecast}
+	if state.TopSession != nil {
+		output.TopSession = &UsageOutputSession{Name: state.TopSession.Name, CostUSD: state.TopSession.Cost}
+	}
+	output.Quiet = state.Quiet
+	return output
+}
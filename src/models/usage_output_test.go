@@ -0,0 +1,114 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/lib"
+)
+
+func TestNewUsageOutput(t *testing.T) {
+	config := ConfigDefaults()
+	config.MonthlyBudget = 400
+	config.DayThresholds = map[string]ThresholdPair{"weekends": {YellowThreshold: 2, RedThreshold: 4}}
+	saturday := time.Date(2025, 3, 15, 10, 30, 0, 0, time.UTC)
+
+	state := &UsageState{
+		LastUpdate:  saturday,
+		DailyCost:   3,
+		DailyCount:  1200,
+		DailyCalls:  12,
+		WeeklyCost:  40,
+		WeeklyCount: 9000,
+		MonthlyCost: 130,
+		Forecast:    390,
+		Status:      Yellow,
+		Level:       "busy",
+		TopSession:  &SessionCost{Name: "src/app", Cost: 1.5},
+		IsAvailable: true,
+	}
+	output := NewUsageOutput(state, config, saturday.Add(time.Minute))
+
+	data, err := json.Marshal(output)
+	require.NoError(t, err)
+	// The exact encoding is the compatibility contract: a change here needs
+	// a schema_version bump unless it only adds fields.
+	assert.JSONEq(t, `{
+		"schema_version": 1,
+		"generated_at": "2025-03-15T10:31:00Z",
+		"updated_at": "2025-03-15T10:30:00Z",
+		"available": true,
+		"status": "yellow",
+		"status_label": "High",
+		"level": "busy",
+		"title": "CC 🟡 $3.00",
+		"today": {"cost_usd": 3, "tokens": 1200, "calls": 12},
+		"week": {"cost_usd": 40, "tokens": 9000},
+		"month": {"cost_usd": 130, "forecast_usd": 390},
+		"thresholds": {"yellow_usd": 2, "red_usd": 4},
+		"budgets": {"weekly_usd": null, "monthly_usd": 400},
+		"top_session": {"name": "src/app", "cost_usd": 1.5},
+		"quiet": false,
+		"demo": false
+	}`, string(data))
+}
+
+func TestNewUsageOutput_Unavailable(t *testing.T) {
+	now := time.Date(2025, 3, 17, 9, 0, 0, 0, time.UTC)
+	state := &UsageState{LastUpdate: now, DailyCost: 12, Status: Unknown}
+
+	output := NewUsageOutput(state, ConfigDefaults(), now)
+
+	data, err := json.Marshal(output)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"schema_version": 1,
+		"generated_at": "2025-03-17T09:00:00Z",
+		"updated_at": null,
+		"available": false,
+		"status": "unknown",
+		"status_label": "Unknown",
+		"level": null,
+		"title": "CC ⚪️ Unknown",
+		"today": null,
+		"week": null,
+		"month": null,
+		"thresholds": {"yellow_usd": 10, "red_usd": 20},
+		"budgets": {"weekly_usd": null, "monthly_usd": null},
+		"top_session": null,
+		"quiet": false,
+		"demo": false
+	}`, string(data), "last known costs aren't reported as current")
+}
+
+func TestUsageOutputSchema_MatchesOutput(t *testing.T) {
+	schema, err := lib.ParseSchema(UsageOutputSchema())
+	require.NoError(t, err)
+
+	config := ConfigDefaults()
+	config.WeeklyBudget = 100
+	states := map[string]*UsageState{
+		"available": {
+			LastUpdate: time.Now(), DailyCost: 3, Status: Red, Level: "wild",
+			TopSession: &SessionCost{Name: "x", Cost: 1}, IsAvailable: true,
+		},
+		"unavailable": {Status: Unknown},
+	}
+	for name, state := range states {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(NewUsageOutput(state, config, time.Now()))
+			require.NoError(t, err)
+			violations, err := schema.ValidateYAML(data)
+			require.NoError(t, err)
+			assert.Empty(t, violations)
+		})
+	}
+
+	violations, err := schema.ValidateYAML([]byte(`{"schema_version": 2, "today": {"cost_usd": "3"}}`))
+	require.NoError(t, err)
+	assert.NotEmpty(t, violations, "the schema rejects a different version and wrong types")
+}
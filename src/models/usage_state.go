@@ -6,12 +6,38 @@ import "time"
 type UsageState struct {
 	LastUpdate  time.Time   `json:"last_update"`
 	LastReset   time.Time   `json:"last_reset"`
-	DailyCount  int         `json:"daily_count"`
+	DailyCount  int         `json:"daily_count"` // Tokens today
+	DailyCalls  int         `json:"daily_calls"` // API requests today, from Claude's usage logs
 	DailyCost   float64     `json:"daily_cost"`
+	WeeklyCount int         `json:"weekly_count"` // Tokens since Monday, including today
+	WeeklyCost  float64     `json:"weekly_cost"`  // Cost since Monday, including today
+	MonthlyCost float64     `json:"monthly_cost"` // Cost since the 1st, including today
+	Forecast    float64     `json:"forecast"`     // Projected month-end cost, see ForecastMonth
 	Status      AlertStatus `json:"status"`
+	Level       string      `json:"level,omitempty"`        // Matched custom alert level name, if any
+	LevelSymbol string      `json:"level_symbol,omitempty"` // Symbol override for the matched level
+	Quiet       bool        `json:"quiet,omitempty"`        // Alerts silenced by quiet_until
+	Demo        bool        `json:"demo,omitempty"`         // Synthetic data from demo mode
+	Paused      bool        `json:"paused,omitempty"`       // Polling suspended by quiet_hours
+	Active      bool        `json:"active,omitempty"`       // Claude Code looked busy at the last poll; see UsageService
 	IsAvailable bool        `json:"is_available"`
+
+	Models      []ModelUsage `json:"models,omitempty"`       // Today's per-model breakdown
+	ModelAlerts []ModelAlert `json:"model_alerts,omitempty"` // model_thresholds reached today
+
+	// Previous days' totals from ccusage; nil when that day has no entry.
+	Yesterday *CostComparison `json:"yesterday,omitempty"`
+	LastWeek  *CostComparison `json:"last_week,omitempty"` // Same weekday, seven days ago
+
+	TopSession    *SessionCost  `json:"top_session,omitempty"`    // The most expensive session active today, by total cost
+	SessionAlerts []SessionCost `json:"session_alerts,omitempty"` // Sessions active today whose total cost reached session_cap
+	TagCosts      []TagCost     `json:"tag_costs,omitempty"`      // Today's spend per project_tags tag
 }
 
+// WeeklyBudgetYellowRatio is the fraction of the weekly budget left at which
+// the remaining-budget line turns yellow; it turns red once nothing is left.
+const WeeklyBudgetYellowRatio = 0.25
+
 // NewUsageState creates a new UsageState with default values
 func NewUsageState() *UsageState {
 	now := time.Now()
@@ -27,6 +53,9 @@ func NewUsageState() *UsageState {
 
 // UpdateStatus calculates and updates the alert status based on cost thresholds
 func (u *UsageState) UpdateStatus(yellowThreshold, redThreshold float64) {
+	u.Level = ""
+	u.LevelSymbol = ""
+	u.Quiet = false
 	switch {
 	case u.DailyCost >= redThreshold:
 		u.Status = Red
@@ -37,10 +66,107 @@ func (u *UsageState) UpdateStatus(yellowThreshold, redThreshold float64) {
 	}
 }
 
-// Reset resets the daily counters while preserving other state
+// UpdateLevels evaluates cost against an ordered alert level ladder. Below
+// the first threshold the state is Green with no level name.
+func (u *UsageState) UpdateLevels(levels []AlertLevel) {
+	u.Quiet = false
+	level, ok := MatchAlertLevel(levels, u.DailyCost)
+	if !ok {
+		u.Status = Green
+		u.Level = ""
+		u.LevelSymbol = ""
+		return
+	}
+	u.Status = level.Status
+	u.Level = level.Name
+	u.LevelSymbol = level.Symbol
+}
+
+// UpdateStatusFromConfig applies custom alert levels when configured and
+// falls back to the yellow/red threshold pair otherwise, honouring any
+// day-of-week override and quiet period for the day the data was last
+// updated.
+func (u *UsageState) UpdateStatusFromConfig(config *Config) {
+	day := u.LastUpdate
+	if day.IsZero() {
+		day = time.Now()
+	}
+	if len(config.AlertLevels) > 0 {
+		u.UpdateLevels(config.AlertLevels)
+	} else {
+		u.UpdateStatus(config.ThresholdsFor(day.Weekday()))
+	}
+	u.UpdateModelAlerts(config.ModelThresholds)
+	if config.QuietActive(day) {
+		u.Silence()
+	}
+}
+
+// UpdateModelAlerts re-evaluates per-model thresholds against Models.
+func (u *UsageState) UpdateModelAlerts(thresholds map[string]float64) {
+	u.ModelAlerts = MatchModelThresholds(u.Models, thresholds)
+}
+
+// Silence holds the status at Green while quiet mode is active. Cost and
+// token counts are left untouched so data collection continues.
+func (u *UsageState) Silence() {
+	u.Status = Green
+	u.Level = ""
+	u.LevelSymbol = ""
+	u.ModelAlerts = nil
+	u.SessionAlerts = nil
+	u.Quiet = true
+}
+
+// WeeklyRemaining returns how much of budget is left this week. The result
+// is negative once the budget has been exceeded.
+func (u *UsageState) WeeklyRemaining(budget float64) float64 {
+	return budget - u.WeeklyCost
+}
+
+// RemainingToRed returns how much more can be spent today before status
+// turns red. The result is zero or negative once red has been reached; ok
+// is false when the config has no red level.
+func (u *UsageState) RemainingToRed(config *Config) (remaining float64, ok bool) {
+	red, ok := config.RedThresholdFor(u.LastUpdate.Weekday())
+	if !ok {
+		return 0, false
+	}
+	return red - u.DailyCost, true
+}
+
+// WeeklyBudgetStatus colors the weekly remaining budget: Green while more
+// than WeeklyBudgetYellowRatio of it is left, Yellow as it runs low, and Red
+// once it is used up. A non-positive budget is treated as disabled (Green).
+func (u *UsageState) WeeklyBudgetStatus(budget float64) AlertStatus {
+	if budget <= 0 {
+		return Green
+	}
+	remaining := u.WeeklyRemaining(budget)
+	switch {
+	case remaining <= 0:
+		return Red
+	case remaining <= budget*WeeklyBudgetYellowRatio:
+		return Yellow
+	default:
+		return Green
+	}
+}
+
+// Reset resets the daily counters while preserving other state,
+// including Quiet, which follows quiet_until rather than the day.
 func (u *UsageState) Reset() {
 	u.DailyCount = 0
+	u.DailyCalls = 0
 	u.DailyCost = 0.0
 	u.Status = Green
+	u.Level = ""
+	u.LevelSymbol = ""
+	u.Models = nil
+	u.ModelAlerts = nil
+	u.Yesterday = nil
+	u.LastWeek = nil
+	u.TopSession = nil
+	u.SessionAlerts = nil
 	u.LastReset = time.Now()
 }
@@ -320,6 +320,15 @@ func TestUsageState_ResetPreservesAvailability(t *testing.T) {
 	assert.Equal(t, Green, state.Status)
 }
 
+func TestUsageState_ResetKeepsQuiet(t *testing.T) {
+	state := NewUsageState()
+	state.DailyCost = 50.0
+	state.Silence()
+
+	state.Reset()
+	assert.True(t, state.Quiet, "quiet mode outlasts the day's counters")
+}
+
 func TestUsageState_UpdateStatusWithDifferentThresholds(t *testing.T) {
 	state := NewUsageState()
 	state.DailyCost = 15.0
@@ -346,3 +355,61 @@ func TestUsageState_UpdateStatusWithDifferentThresholds(t *testing.T) {
 		})
 	}
 }
+
+func TestUsageState_WeeklyBudgetStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		spent    float64
+		budget   float64
+		expected AlertStatus
+	}{
+		{"disabled", 500, 0, Green},
+		{"plenty left", 10, 50, Green},
+		{"exactly a quarter left", 37.5, 50, Yellow},
+		{"running low", 45, 50, Yellow},
+		{"used up", 50, 50, Red},
+		{"over budget", 60, 50, Red},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &UsageState{WeeklyCost: tt.spent}
+			assert.Equal(t, tt.expected, state.WeeklyBudgetStatus(tt.budget))
+		})
+	}
+}
+
+func TestUsageState_WeeklyRemaining(t *testing.T) {
+	state := &UsageState{WeeklyCost: 11.8}
+	assert.InDelta(t, 38.2, state.WeeklyRemaining(50), 1e-9)
+	assert.InDelta(t, -1.8, state.WeeklyRemaining(10), 1e-9)
+}
+
+func TestUsageState_RemainingToRed(t *testing.T) {
+	config := ConfigDefaults()
+	config.DayThresholds = map[string]ThresholdPair{"saturday": {YellowThreshold: 2, RedThreshold: 4}}
+	friday := time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)
+
+	state := &UsageState{DailyCost: 12.4, LastUpdate: friday}
+	remaining, ok := state.RemainingToRed(config)
+	assert.True(t, ok)
+	assert.InDelta(t, 7.6, remaining, 1e-9)
+
+	state.LastUpdate = friday.AddDate(0, 0, 1)
+	remaining, ok = state.RemainingToRed(config)
+	assert.True(t, ok)
+	assert.InDelta(t, -8.4, remaining, 1e-9, "day override applies")
+
+	config.AlertLevels = []AlertLevel{
+		{Name: "busy", Threshold: 5, Status: Yellow},
+		{Name: "stop", Threshold: 15, Status: Red},
+		{Name: "way over", Threshold: 30, Status: Red},
+	}
+	remaining, ok = state.RemainingToRed(config)
+	assert.True(t, ok)
+	assert.InDelta(t, 2.6, remaining, 1e-9, "first red level wins")
+
+	config.AlertLevels = config.AlertLevels[:1]
+	_, ok = state.RemainingToRed(config)
+	assert.False(t, ok)
+}
@@ -0,0 +1,12 @@
+package ccmonitor
+
+import (
+	"os"
+	"testing"
+
+	"cc-dailyuse-bar/src/internal/testhelpers"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testhelpers.RunSilenced(m))
+}
@@ -0,0 +1,188 @@
+// Package ccmonitor embeds cc-dailyuse-bar's usage monitoring in other Go
+// programs. It wraps the same ccusage polling, state tracking, and
+// threshold logic the tray uses, without any GUI dependency, so status
+// bars, editors, and other tools show exactly what the menu bar would.
+//
+// A minimal embedding:
+//
+//	config, err := ccmonitor.LoadConfig("")
+//	if err != nil {
+//		return err
+//	}
+//	monitor, err := ccmonitor.New(config)
+//	if err != nil {
+//		return err
+//	}
+//	defer monitor.Stop()
+//	return monitor.Start(func(state *ccmonitor.State) {
+//		fmt.Println(monitor.Title(state))
+//	})
+package ccmonitor
+
+import (
+	"sync"
+	"time"
+
+	"cc-dailyuse-bar/src/models"
+	"cc-dailyuse-bar/src/services"
+)
+
+// Config is the monitor configuration, identical to the config file.
+type Config = models.Config
+
+// State is a snapshot of today's usage and its alert status.
+type State = models.UsageState
+
+// Status is the alert color derived from the configured thresholds.
+type Status = models.AlertStatus
+
+// AlertLevel is one rung of a custom alert ladder.
+type AlertLevel = models.AlertLevel
+
+// PollingStatus is the lifecycle stage of a monitor's poller: stopped,
+// starting, running, paused (quiet_hours), degraded (last poll failed), or
+// stopping.
+type PollingStatus = services.PollingStatus
+
+// Alert statuses, in increasing severity after Unknown.
+const (
+	Unknown = models.Unknown
+	Green   = models.Green
+	Yellow  = models.Yellow
+	Red     = models.Red
+)
+
+// DefaultConfig returns the built-in defaults used when no config file
+// exists.
+func DefaultConfig() *Config {
+	return models.ConfigDefaults()
+}
+
+// LoadConfig reads a config file (YAML, TOML, or JSON by extension) with
+// its optional .local overlay. An empty path uses the default location.
+func LoadConfig(path string) (*Config, error) {
+	configService := services.NewConfigService()
+	if path != "" {
+		configService.SetConfigPath(path)
+	}
+	return configService.Load()
+}
+
+// Monitor polls ccusage and keeps the latest usage state. It is safe for
+// concurrent use.
+type Monitor struct {
+	mutex  sync.RWMutex
+	config *Config
+	usage  *services.UsageService
+}
+
+// New creates a monitor for config, or the defaults when config is nil.
+// The config is validated and copied; later changes to the caller's value
+// have no effect until passed to SetConfig.
+func New(config *Config) (*Monitor, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	copied := *config
+	return &Monitor{
+		config: &copied,
+		usage:  services.NewUsageService(&copied),
+	}, nil
+}
+
+// Current returns the latest state, querying ccusage only when the cached
+// result is older than the config's cache window.
+func (m *Monitor) Current() (*State, error) {
+	return m.usage.GetDailyUsage()
+}
+
+// Refresh queries ccusage immediately and passes the result to the Start
+// callback, if any.
+func (m *Monitor) Refresh() (*State, error) {
+	return m.usage.Refresh()
+}
+
+// Start polls every update_interval seconds and calls onUpdate with each
+// new state, including unavailable ones after a failed poll. It also
+// resets daily counters at midnight. onUpdate runs on its own goroutine,
+// one state at a time, so a slow callback doesn't delay polling; if it
+// falls several states behind, the oldest waiting ones are skipped (see
+// DroppedUpdates). Start returns immediately; call Stop to end polling.
+// Calling Start again replaces the callback.
+func (m *Monitor) Start(onUpdate func(*State)) error {
+	m.mutex.RLock()
+	interval := m.config.UpdateInterval
+	m.mutex.RUnlock()
+
+	if err := m.usage.StartPolling(interval, onUpdate); err != nil {
+		return err
+	}
+	m.usage.StartDailyResetMonitor()
+	return nil
+}
+
+// Stop ends polling started by Start, returning once pending states have
+// been delivered. It is safe to call more than once, but not from the
+// Start callback.
+func (m *Monitor) Stop() {
+	m.usage.StopPolling()
+}
+
+// DroppedUpdates reports how many states were skipped because the Start
+// callback fell behind.
+func (m *Monitor) DroppedUpdates() uint64 {
+	return m.usage.DroppedUIUpdates()
+}
+
+// PollingStatus reports what the poller is doing.
+func (m *Monitor) PollingStatus() PollingStatus {
+	return m.usage.PollingStatus()
+}
+
+// SetConfig validates and applies a new config without restarting polling.
+func (m *Monitor) SetConfig(config *Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	copied := *config
+
+	m.mutex.Lock()
+	m.config = &copied
+	m.mutex.Unlock()
+
+	m.usage.ApplyConfig(&copied)
+	return nil
+}
+
+// Title renders state the way the tray's menu bar title shows it.
+func (m *Monitor) Title(state *State) string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	if state == nil || !state.IsAvailable {
+		return models.FormatUnknownTitle(m.config)
+	}
+	return models.FormatTitle(state, m.config)
+}
+
+// Evaluate runs a saved `ccusage daily --json` report through the monitor
+// pipeline as of the given day, without starting ccusage. Use it to feed
+// usage data obtained some other way. The returned error matches what a
+// live poll of the same report would return.
+func Evaluate(config *Config, report []byte, day time.Time) (*State, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	usage := services.NewUsageService(config)
+	usage.SetClock(fixedClock{now: day})
+	return usage.ApplyFixture(report)
+}
+
+type fixedClock struct{ now time.Time }
+
+func (c fixedClock) Now() time.Time { return c.now }
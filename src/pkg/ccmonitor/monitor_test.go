@@ -0,0 +1,98 @@
+package ccmonitor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/internal/testhelpers"
+)
+
+func report(date string, cost float64) []byte {
+	return []byte(fmt.Sprintf(`{"daily":[{"date":%q,"totalTokens":1000,"totalCost":%g}]}`, date, cost))
+}
+
+func TestNew_ValidatesConfig(t *testing.T) {
+	monitor, err := New(nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultConfig().RedThreshold, monitor.config.RedThreshold)
+
+	config := DefaultConfig()
+	config.YellowThreshold = 30
+	_, err = New(config)
+	assert.Error(t, err, "yellow above red")
+}
+
+func TestEvaluate(t *testing.T) {
+	day := time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name   string
+		cost   float64
+		status Status
+	}{
+		{"green", 4, Green},
+		{"yellow", 12, Yellow},
+		{"red", 25, Red},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := Evaluate(nil, report("2025-03-14", tt.cost), day)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, state.Status)
+			assert.Equal(t, tt.cost, state.DailyCost)
+		})
+	}
+
+	_, err := Evaluate(nil, []byte("not json"), day)
+	assert.Error(t, err)
+}
+
+func TestMonitor_Title(t *testing.T) {
+	monitor, err := New(nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "CC ⚪️ Unknown", monitor.Title(nil))
+	state := &State{DailyCost: 12, Status: Yellow, IsAvailable: true}
+	assert.Equal(t, "CC 🟡 $12.00", monitor.Title(state))
+
+	config := DefaultConfig()
+	whole := 0
+	config.TitleCostPrecision = &whole
+	require.NoError(t, monitor.SetConfig(config))
+	assert.Equal(t, "CC 🟡 $12", monitor.Title(state))
+}
+
+func TestMonitor_RefreshAndStart(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+	script := testhelpers.WriteFakeCommand(t, t.TempDir(), "ccusage", string(report(today, 15))+"\n", 0)
+
+	config := DefaultConfig()
+	config.CCUsagePath = script
+	monitor, err := New(config)
+	require.NoError(t, err)
+
+	assert.Equal(t, "stopped", monitor.PollingStatus().String())
+	updates := make(chan *State, 1)
+	require.NoError(t, monitor.Start(func(state *State) { updates <- state }))
+	defer monitor.Stop()
+	assert.Equal(t, "running", monitor.PollingStatus().String())
+
+	state, err := monitor.Refresh()
+	require.NoError(t, err)
+	assert.Equal(t, Yellow, state.Status)
+
+	select {
+	case got := <-updates:
+		assert.Equal(t, 15.0, got.DailyCost)
+	case <-time.After(time.Second):
+		t.Fatal("Refresh did not notify the Start callback")
+	}
+
+	cached, err := monitor.Current()
+	require.NoError(t, err)
+	assert.Equal(t, 15.0, cached.DailyCost)
+}
@@ -0,0 +1,22 @@
+package services
+
+import "time"
+
+// minActivityWindow is how recently Claude's usage logs must have been
+// written for Claude Code to count as busy when polls are closer together
+// than this, or polling hasn't started.
+const minActivityWindow = time.Minute
+
+// activeLocked infers whether Claude Code is generating: today's cost rose
+// at this poll, or a usage log was written (lastWrite) within the last
+// poll interval.
+func (us *UsageService) activeLocked(costRose bool, lastWrite time.Time) bool {
+	if costRose {
+		return true
+	}
+	window := time.Duration(us.pollInterval.Load())
+	if window < minActivityWindow {
+		window = minActivityWindow
+	}
+	return !lastWrite.IsZero() && us.clock.Now().Sub(lastWrite) <= window
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// SoundPlayer plays the red_sound setting: models.RedSoundSystem for the
+// platform's alert sound, or the path of an audio file.
+type SoundPlayer interface {
+	Play(sound string) error
+}
+
+// systemSoundPlayer starts the platform's audio tool without waiting for
+// the sound to finish: afplay on macOS, canberra-gtk-play or paplay on
+// Linux, and PowerShell on Windows.
+type systemSoundPlayer struct{}
+
+// macAlertSound is the system sound macOS plays for alerts by default.
+const macAlertSound = "/System/Library/Sounds/Sosumi.aiff"
+
+func (systemSoundPlayer) Play(sound string) error {
+	name, args, err := soundCommand(runtime.GOOS, sound)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+	go func() { _ = cmd.Wait() }() // reap it once the sound ends
+	return nil
+}
+
+// soundCommand returns the command that plays sound on goos.
+func soundCommand(goos, sound string) (string, []string, error) {
+	system := sound == models.RedSoundSystem
+	switch goos {
+	case "darwin":
+		if system {
+			sound = macAlertSound
+		}
+		return "afplay", []string{sound}, nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		if system {
+			return "canberra-gtk-play", []string{"--id=dialog-warning", "--description=cc-dailyuse-bar"}, nil
+		}
+		return "paplay", []string{sound}, nil
+	case "windows":
+		script := "[System.Media.SystemSounds]::Exclamation.Play(); Start-Sleep -Milliseconds 500"
+		if !system {
+			script = "(New-Object System.Media.SoundPlayer " + powerShellString(sound) + ").PlaySync()"
+		}
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", script}, nil
+	default:
+		return "", nil, fmt.Errorf("sound alerts are not supported on %s", goos)
+	}
+}
+
+// powerShellString quotes s as a single-quoted PowerShell string.
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// AlertSound plays red_sound when today's status enters red, once per
+// crossing: it sounds again only after usage has dropped back below red,
+// e.g. when a threshold is raised or a new day starts.
+type AlertSound struct {
+	player SoundPlayer
+	logger *lib.Logger
+	mutex  sync.Mutex
+	last   models.AlertStatus // status at the previous Observe
+}
+
+// NewAlertSound creates an AlertSound using the platform's audio tool.
+func NewAlertSound() *AlertSound {
+	return &AlertSound{
+		player: systemSoundPlayer{},
+		logger: lib.NewLogger("alert-sound"),
+		last:   models.Unknown,
+	}
+}
+
+// SetPlayer overrides the audio tool, primarily for tests.
+func (as *AlertSound) SetPlayer(player SoundPlayer) {
+	as.mutex.Lock()
+	defer as.mutex.Unlock()
+	as.player = player
+}
+
+// Observe plays config's red_sound when state has just turned red from
+// green or yellow. Nothing plays when red_sound is unset or muted, during
+// quiet mode or quiet hours, or for the first status seen after startup,
+// so restarting an app that is already red stays silent.
+func (as *AlertSound) Observe(state *models.UsageState, config *models.Config) {
+	if state == nil || !state.IsAvailable {
+		return
+	}
+	as.mutex.Lock()
+	defer as.mutex.Unlock()
+
+	entered := state.Status == models.Red && (as.last == models.Green || as.last == models.Yellow)
+	as.last = state.Status
+	if !entered || config.RedSound == "" || config.RedSoundMuted || state.Quiet || state.Paused {
+		return
+	}
+	if err := as.player.Play(config.RedSound); err != nil {
+		as.logger.Warn("Failed to play red alert sound", map[string]interface{}{
+			"sound": config.RedSound,
+			"error": err.Error(),
+		})
+		return
+	}
+	as.logger.Info("Played red alert sound", map[string]interface{}{
+		"sound": config.RedSound,
+	})
+}
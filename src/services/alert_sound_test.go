@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+type recordingPlayer struct {
+	played []string
+	err    error
+}
+
+func (r *recordingPlayer) Play(sound string) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.played = append(r.played, sound)
+	return nil
+}
+
+func TestAlertSound_PlaysOnEnteringRed(t *testing.T) {
+	player := &recordingPlayer{}
+	sound := NewAlertSound()
+	sound.SetPlayer(player)
+	config := models.ConfigDefaults()
+	config.RedSound = models.RedSoundSystem
+
+	observe := func(status models.AlertStatus) {
+		sound.Observe(&models.UsageState{Status: status, IsAvailable: true}, config)
+	}
+	observe(models.Red) // already red at startup
+	assert.Empty(t, player.played)
+
+	observe(models.Yellow)
+	observe(models.Red)
+	observe(models.Red)
+	assert.Equal(t, []string{"system"}, player.played, "once per crossing")
+
+	observe(models.Green)
+	observe(models.Red)
+	assert.Len(t, player.played, 2, "dropping below red re-arms it")
+}
+
+func TestAlertSound_Silenced(t *testing.T) {
+	tests := []struct {
+		name   string
+		config func(*models.Config)
+		state  models.UsageState
+	}{
+		{"unset", func(c *models.Config) { c.RedSound = "" }, models.UsageState{}},
+		{"muted", func(c *models.Config) { c.RedSoundMuted = true }, models.UsageState{}},
+		{"quiet mode", func(*models.Config) {}, models.UsageState{Quiet: true}},
+		{"quiet hours", func(*models.Config) {}, models.UsageState{Paused: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player := &recordingPlayer{}
+			sound := NewAlertSound()
+			sound.SetPlayer(player)
+			config := models.ConfigDefaults()
+			config.RedSound = "/usr/share/sounds/alarm.oga"
+			tt.config(config)
+
+			sound.Observe(&models.UsageState{Status: models.Yellow, IsAvailable: true}, config)
+			state := tt.state
+			state.Status, state.IsAvailable = models.Red, true
+			sound.Observe(&state, config)
+			assert.Empty(t, player.played)
+		})
+	}
+}
+
+func TestAlertSound_PlayFailureIsLogged(t *testing.T) {
+	sound := NewAlertSound()
+	sound.SetPlayer(&recordingPlayer{err: errors.New("paplay: not found")})
+	config := models.ConfigDefaults()
+	config.RedSound = models.RedSoundSystem
+
+	sound.Observe(&models.UsageState{Status: models.Yellow, IsAvailable: true}, config)
+	assert.NotPanics(t, func() {
+		sound.Observe(&models.UsageState{Status: models.Red, IsAvailable: true}, config)
+	})
+}
+
+func TestSoundCommand(t *testing.T) {
+	tests := []struct {
+		goos, sound string
+		name        string
+		args        []string
+	}{
+		{"darwin", "system", "afplay", []string{"/System/Library/Sounds/Sosumi.aiff"}},
+		{"darwin", "/Users/me/alarm.mp3", "afplay", []string{"/Users/me/alarm.mp3"}},
+		{"linux", "system", "canberra-gtk-play", []string{"--id=dialog-warning", "--description=cc-dailyuse-bar"}},
+		{"linux", "/home/me/alarm.oga", "paplay", []string{"/home/me/alarm.oga"}},
+		{"windows", `C:\Users\me\it's.wav`, "powershell", []string{"-NoProfile", "-NonInteractive", "-Command",
+			`(New-Object System.Media.SoundPlayer 'C:\Users\me\it''s.wav').PlaySync()`}},
+	}
+	for _, tt := range tests {
+		name, args, err := soundCommand(tt.goos, tt.sound)
+		assert.NoError(t, err, tt.goos)
+		assert.Equal(t, tt.name, name, tt.goos)
+		assert.Equal(t, tt.args, args, tt.goos)
+	}
+
+	_, _, err := soundCommand("plan9", "system")
+	assert.ErrorContains(t, err, "not supported on plan9")
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// dataFingerprint summarises the Claude JSONL files ccusage reads. If none of
+// these values change between polls, ccusage cannot produce a different
+// answer for the same date range, so spawning it again is wasted work.
+type dataFingerprint struct {
+	Files     int       `json:"files"`
+	TotalSize int64     `json:"total_size"`
+	LatestMod time.Time `json:"latest_mod"`
+}
+
+// cachedCCUsageResult is the on-disk record of the last successful run.
+type cachedCCUsageResult struct {
+	CCUsagePath string          `json:"ccusage_path"`
+	Args        []string        `json:"args"`
+	Fingerprint dataFingerprint `json:"fingerprint"`
+	Output      []byte          `json:"output"`
+	StoredAt    time.Time       `json:"stored_at"`
+}
+
+// ccusageCache persists ccusage output keyed by the JSONL fingerprint.
+type ccusageCache struct {
+	path     string
+	dataDirs []string
+}
+
+func newCCUsageCache(dataDirs []string) *ccusageCache {
+	return &ccusageCache{
+		path:     filepath.Join(DefaultPaths().CacheDir(), "ccusage-daily.json"),
+		dataDirs: dataDirs,
+	}
+}
+
+// claudeDataDirs lists the directories Claude Code writes usage JSONL to:
+// the projects folder of each of claudeConfigDirs.
+func claudeDataDirs(configured string) []string {
+	var dirs []string
+	for _, dir := range claudeConfigDirs(configured) {
+		dirs = append(dirs, filepath.Join(dir, "projects"))
+	}
+	return dirs
+}
+
+// claudeConfigDirs lists the Claude config directories ccusage reads. A
+// configured claude_data_dir wins; otherwise CLAUDE_CONFIG_DIR
+// (comma-separated, as understood by ccusage) takes precedence over the
+// default new and legacy locations.
+func claudeConfigDirs(configured string) []string {
+	if configured != "" {
+		return []string{configured}
+	}
+	if env := strings.TrimSpace(os.Getenv("CLAUDE_CONFIG_DIR")); env != "" {
+		var dirs []string
+		for _, dir := range strings.Split(env, ",") {
+			if dir = strings.TrimSpace(dir); dir != "" {
+				dirs = append(dirs, dir)
+			}
+		}
+		return dirs
+	}
+	return defaultClaudeConfigDirs()
+}
+
+// defaultClaudeConfigDirs returns the new and legacy Claude config
+// directories ccusage reads when CLAUDE_CONFIG_DIR is unset.
+func defaultClaudeConfigDirs() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{
+		filepath.Join(home, ".config", "claude"),
+		filepath.Join(home, ".claude"),
+	}
+}
+
+// fingerprint walks the data directories. It returns false when none of
+// them exist, in which case caching is skipped and ccusage always runs.
+func (c *ccusageCache) fingerprint() (dataFingerprint, bool) {
+	var fp dataFingerprint
+	found := false
+
+	for _, dir := range c.dataDirs {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		found = true
+
+		_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".jsonl") {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			fp.Files++
+			fp.TotalSize += info.Size()
+			if info.ModTime().After(fp.LatestMod) {
+				fp.LatestMod = info.ModTime()
+			}
+			return nil
+		})
+	}
+
+	return fp, found
+}
+
+// load returns the cached output if it was produced by the same command for
+// the same data fingerprint.
+func (c *ccusageCache) load(ccusagePath string, args []string, fp dataFingerprint) ([]byte, bool) {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return nil, false
+	}
+
+	var cached cachedCCUsageResult
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, false
+	}
+
+	if cached.CCUsagePath != ccusagePath || !slices.Equal(cached.Args, args) ||
+		cached.Fingerprint.Files != fp.Files || cached.Fingerprint.TotalSize != fp.TotalSize ||
+		!cached.Fingerprint.LatestMod.Equal(fp.LatestMod) {
+		return nil, false
+	}
+
+	return cached.Output, true
+}
+
+// store writes the result atomically so a crash mid-write can't leave a
+// truncated cache behind.
+func (c *ccusageCache) store(ccusagePath string, args []string, fp dataFingerprint, output []byte) error {
+	data, err := json.Marshal(cachedCCUsageResult{
+		CCUsagePath: ccusagePath,
+		Args:        args,
+		Fingerprint: fp,
+		Output:      output,
+		StoredAt:    time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return errors.Join(err, os.Remove(tmp))
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/internal/testhelpers"
+)
+
+func newTestCCUsageCache(t *testing.T) (*ccusageCache, string) {
+	t.Helper()
+	root := t.TempDir()
+	dataDir := filepath.Join(root, "projects")
+	require.NoError(t, os.MkdirAll(filepath.Join(dataDir, "proj"), 0o755))
+	return &ccusageCache{
+		path:     filepath.Join(root, "cache", "ccusage-daily.json"),
+		dataDirs: []string{dataDir, filepath.Join(root, "missing")},
+	}, dataDir
+}
+
+func TestClaudeDataDirs_EnvOverride(t *testing.T) {
+	t.Setenv("CLAUDE_CONFIG_DIR", "/a, /b ,")
+	assert.Equal(t, []string{filepath.Join("/a", "projects"), filepath.Join("/b", "projects")}, claudeDataDirs(""))
+	assert.Equal(t, []string{filepath.Join("/c", "projects")}, claudeDataDirs("/c"), "claude_data_dir wins")
+}
+
+func TestCCUsageCache_FingerprintNoDirs(t *testing.T) {
+	cache := &ccusageCache{dataDirs: []string{filepath.Join(t.TempDir(), "missing")}}
+	_, ok := cache.fingerprint()
+	assert.False(t, ok)
+}
+
+func TestCCUsageCache_FingerprintTracksJSONL(t *testing.T) {
+	cache, dataDir := newTestCCUsageCache(t)
+	file := filepath.Join(dataDir, "proj", "session.jsonl")
+
+	empty, ok := cache.fingerprint()
+	require.True(t, ok)
+	assert.Equal(t, 0, empty.Files)
+
+	require.NoError(t, os.WriteFile(file, []byte("{}\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "proj", "notes.txt"), []byte("ignored"), 0o644))
+
+	first, _ := cache.fingerprint()
+	assert.Equal(t, 1, first.Files)
+	assert.Equal(t, int64(3), first.TotalSize)
+
+	require.NoError(t, os.WriteFile(file, []byte("{}\n{}\n"), 0o644))
+	second, _ := cache.fingerprint()
+	assert.NotEqual(t, first, second)
+}
+
+func TestCCUsageCache_StoreAndLoad(t *testing.T) {
+	cache, _ := newTestCCUsageCache(t)
+	fp := dataFingerprint{Files: 2, TotalSize: 10, LatestMod: time.Unix(1700000000, 0)}
+	args := []string{"daily", "--json"}
+
+	_, ok := cache.load("ccusage", args, fp)
+	assert.False(t, ok, "empty cache must miss")
+
+	require.NoError(t, cache.store("ccusage", args, fp, []byte(`{"daily":[]}`)))
+
+	out, ok := cache.load("ccusage", args, fp)
+	require.True(t, ok)
+	assert.Equal(t, `{"daily":[]}`, string(out))
+
+	_, ok = cache.load("other-ccusage", args, fp)
+	assert.False(t, ok, "different binary must miss")
+
+	_, ok = cache.load("ccusage", []string{"daily", "--json", "--since", "20250101"}, fp)
+	assert.False(t, ok, "different args must miss")
+
+	changed := fp
+	changed.LatestMod = fp.LatestMod.Add(time.Second)
+	_, ok = cache.load("ccusage", args, changed)
+	assert.False(t, ok, "changed fingerprint must miss")
+}
+
+func TestUsageService_DiskCacheSkipsUnchangedData(t *testing.T) {
+	service := newTestUsageService()
+	cache, dataDir := newTestCCUsageCache(t)
+	service.diskCache = cache
+	service.SetClock(fixedClock{now: time.Date(2025, 3, 12, 15, 30, 0, 0, time.Local)})
+
+	jsonl := filepath.Join(dataDir, "proj", "session.jsonl")
+	require.NoError(t, os.WriteFile(jsonl, []byte("{}\n"), 0o644))
+
+	testhelpers.RequireShell(t)
+	tempDir := t.TempDir()
+	countFile := filepath.Join(tempDir, "count")
+	scriptPath := filepath.Join(tempDir, "counting-ccusage")
+	scriptContent := `#!/bin/bash
+[ "$1" = daily ] && echo run >> '` + countFile + `'
+echo '{"daily":[{"date":"2025-03-12","totalTokens":10,"totalCost":1.5}]}'`
+	require.NoError(t, os.WriteFile(scriptPath, []byte(scriptContent), 0755))
+	service.ccusagePath = scriptPath
+
+	runs := func() int {
+		data, err := os.ReadFile(countFile)
+		require.NoError(t, err)
+		return strings.Count(string(data), "run")
+	}
+
+	_, err := service.UpdateUsage()
+	require.NoError(t, err)
+	state, err := service.UpdateUsage()
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, state.DailyCost)
+	assert.Equal(t, 1, runs(), "unchanged data should reuse the cached output")
+
+	require.NoError(t, os.WriteFile(jsonl, []byte("{}\n{}\n"), 0o644))
+	_, err = service.UpdateUsage()
+	require.NoError(t, err)
+	assert.Equal(t, 2, runs(), "changed data must re-run ccusage")
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// SplitCCUsageCommand turns a ccusage_path value into an executable and
+// leading arguments, so package runners work as well as plain paths:
+//
+//	"ccusage"                   -> ccusage
+//	"bunx ccusage"              -> bunx [ccusage]
+//	"npx -y ccusage@latest"     -> npx [-y ccusage@latest]
+//	"'/opt/my tools/ccusage'"   -> /opt/my tools/ccusage
+//
+// Words are split on whitespace with shell-style single quotes, double
+// quotes, and backslash escapes; on Windows backslashes are path
+// separators, not escapes, so "C:\Tools\ccusage.cmd" needs no doubling. A
+// value naming an existing file is taken verbatim so unquoted paths
+// containing spaces keep working.
+func SplitCCUsageCommand(value string) (string, []string, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return "", nil, errors.New("ccusage command is empty")
+	}
+	if info, err := os.Stat(trimmed); err == nil && !info.IsDir() {
+		return trimmed, nil, nil
+	}
+
+	words, err := splitCommandWords(trimmed, runtime.GOOS != "windows")
+	if err != nil {
+		return "", nil, err
+	}
+	if len(words) == 0 {
+		return "", nil, errors.New("ccusage command is empty")
+	}
+	return words[0], words[1:], nil
+}
+
+// splitCommandWords splits s into words, treating backslashes as escapes
+// when escapes is set.
+func splitCommandWords(s string, escapes bool) ([]string, error) {
+	var (
+		words   []string
+		current strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && escapes && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in ccusage command", quote)
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash in ccusage command")
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
+
+// ccusageCandidates are the ccusage_path values FindCCUsage tries, in
+// order: an installed ccusage, then running it through a package runner.
+var ccusageCandidates = []string{"ccusage", "bunx ccusage", "npx -y ccusage@latest"}
+
+// FindCCUsage returns a ccusage_path value that works on this machine, or
+// "" when neither ccusage nor bunx or npx is on PATH.
+func FindCCUsage() string {
+	for _, candidate := range ccusageCandidates {
+		bin, _, err := SplitCCUsageCommand(candidate)
+		if err != nil {
+			continue
+		}
+		if path, err := exec.LookPath(bin); err == nil {
+			if len(candidate) == len(bin) {
+				return path
+			}
+			return candidate
+		}
+	}
+	return ""
+}
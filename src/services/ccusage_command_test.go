@@ -0,0 +1,106 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/internal/testhelpers"
+)
+
+func TestSplitCCUsageCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		wantBin  string
+		wantArgs []string
+		wantErr  bool
+	}{
+		{"bare name", "ccusage", "ccusage", nil, false},
+		{"bunx", "bunx ccusage", "bunx", []string{"ccusage"}, false},
+		{"npx with flags", "  npx -y ccusage@latest ", "npx", []string{"-y", "ccusage@latest"}, false},
+		{"single quoted path", "'/opt/my tools/ccusage' --offline", "/opt/my tools/ccusage", []string{"--offline"}, false},
+		{"double quotes and escapes", `"C:\\Tools\\ccusage" a\ b`, `C:\Tools\ccusage`, []string{"a b"}, false},
+		{"empty quoted arg", `npx ""`, "npx", []string{""}, false},
+		{"empty", "   ", "", nil, true},
+		{"unterminated quote", `npx "ccusage`, "", nil, true},
+		{"trailing backslash", `npx ccusage\`, "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if runtime.GOOS == "windows" && strings.Contains(tt.value, `\`) {
+				t.Skip("backslashes are path separators on Windows")
+			}
+			bin, args, err := SplitCCUsageCommand(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBin, bin)
+			if len(tt.wantArgs) == 0 {
+				assert.Empty(t, args)
+			} else {
+				assert.Equal(t, tt.wantArgs, args)
+			}
+		})
+	}
+}
+
+func TestSplitCommandWords_WindowsPaths(t *testing.T) {
+	words, err := splitCommandWords(`"C:\Program Files\nodejs\npx.cmd" -y ccusage@latest`, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`C:\Program Files\nodejs\npx.cmd`, "-y", "ccusage@latest"}, words)
+
+	words, err = splitCommandWords(`C:\Tools\ccusage.cmd`, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`C:\Tools\ccusage.cmd`}, words, "backslashes are kept as they are")
+}
+
+func TestSplitCCUsageCommand_ExistingPathWithSpaces(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "My Tools")
+	require.NoError(t, os.Mkdir(dir, 0o755))
+	path := filepath.Join(dir, "ccusage")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/bash\n"), 0o755))
+
+	bin, args, err := SplitCCUsageCommand(path)
+	require.NoError(t, err)
+	assert.Equal(t, path, bin)
+	assert.Empty(t, args)
+}
+
+func TestUsageService_RunnerCommand(t *testing.T) {
+	// Stands in for a package runner: it must receive its own argument
+	// ("ccusage") ahead of the daily report arguments.
+	runner := writeCCUsageScript(t, `[ "$1" = "ccusage" ] && [ "$2" = "daily" ] || exit 9
+echo '{"daily": []}'`)
+
+	service := newTestUsageService()
+	service.ccusagePath = runner + " ccusage"
+	assert.True(t, service.IsAvailable(), "availability checks the runner binary")
+
+	output, err := service.runCCUsage(ccusageDailyArgs(service.clock.Now()))
+	require.NoError(t, err)
+	assert.Equal(t, `{"daily": []}`, strings.TrimSpace(string(output)))
+
+	service.ccusagePath = "definitely-not-a-runner ccusage"
+	assert.False(t, service.IsAvailable())
+}
+
+func TestFindCCUsage(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	assert.Empty(t, FindCCUsage())
+
+	testhelpers.WriteFakeCommand(t, dir, "npx", "", 0)
+	assert.Equal(t, "npx -y ccusage@latest", FindCCUsage())
+
+	ccusage := testhelpers.WriteFakeCommand(t, dir, "ccusage", "", 0)
+	assert.Equal(t, ccusage, FindCCUsage(), "on Windows, ccusage.cmd through PATHEXT")
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// dailyScan summarises a streamed pass over the ccusage "daily" array.
+// Only the entry matching the requested date is materialised (including its
+// per-model breakdown); every other entry is decoded into a reused scratch
+// value and discarded.
+type dailyScan struct {
+	Today      CCUsageOutput
+	Found      bool
+	Entries    int
+	LatestDate string
+	// Week* sum every entry dated between weekStart and today inclusive.
+	WeekCost   float64
+	WeekTokens int
+	// Compare holds the totals for each extra date passed to
+	// scanDailyOutput, in the same order.
+	Compare []dayTotal
+	// Days is every entry's totals by date, for totals over other periods
+	// and for spotting pricing changes.
+	Days map[string]dayTotal
+}
+
+// dayTotal is one day's totals from the daily array.
+type dayTotal struct {
+	Cost   float64
+	Tokens int
+	Found  bool
+}
+
+// scanDailyOutput streams through ccusage's JSON output looking for the
+// entry whose date equals today, accumulating week totals along the way
+// (dates are YYYY-MM-DD so string comparison orders them). Unlike
+// json.Unmarshal into CCUsageResponse it never builds the full Daily slice,
+// which matters once ccusage returns months of history with per-model
+// breakdowns on every poll. Totals for any compare dates (e.g. yesterday)
+// are collected into scan.Compare.
+func scanDailyOutput(output []byte, today, weekStart string, compare ...string) (*dailyScan, error) {
+	dec := json.NewDecoder(bytes.NewReader(output))
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	scan := &dailyScan{Compare: make([]dayTotal, len(compare)), Days: map[string]dayTotal{}}
+	var entry dailyEntry
+	var latest dateBuf
+
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		if key != "daily" {
+			// Skip the value for keys we don't care about (e.g. "totals").
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if err := expectDelim(dec, '['); err != nil {
+			return nil, err
+		}
+		for dec.More() {
+			entry = dailyEntry{}
+			start := dec.InputOffset()
+			if err := dec.Decode(&entry); err != nil {
+				return nil, err
+			}
+			scan.Entries++
+			date := entry.Date.bytes()
+			if bytes.Compare(date, latest.bytes()) > 0 {
+				latest = entry.Date
+			}
+			scan.Days[string(date)] = dayTotal{Cost: entry.TotalCost, Tokens: entry.TotalTokens, Found: true}
+			if string(date) >= weekStart && string(date) <= today {
+				scan.WeekCost += entry.TotalCost
+				scan.WeekTokens += entry.TotalTokens
+			}
+			for i, c := range compare {
+				if string(date) == c {
+					scan.Compare[i] = dayTotal{Cost: entry.TotalCost, Tokens: entry.TotalTokens, Found: true}
+				}
+			}
+			if !scan.Found && string(date) == today {
+				// Re-decode just this element in full; the scratch decode
+				// skips modelBreakdowns so other days stay allocation-free.
+				raw := bytes.TrimLeft(output[start:dec.InputOffset()], " \t\r\n,")
+				if err := json.Unmarshal(raw, &scan.Today); err != nil {
+					return nil, err
+				}
+				scan.Found = true
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+	scan.LatestDate = string(latest.bytes())
+
+	// Reject trailing garbage so this stays as strict as json.Unmarshal.
+	if _, err := dec.Token(); err != io.EOF {
+		if err == nil {
+			return nil, fmt.Errorf("invalid character after top-level value")
+		}
+		return nil, err
+	}
+
+	return scan, nil
+}
+
+// dailyEntry is the scratch value each "daily" element is decoded into.
+// The date is held in a fixed-size buffer so non-matching days cost no
+// heap allocation.
+type dailyEntry struct {
+	Date        dateBuf `json:"date"`
+	TotalTokens int     `json:"totalTokens"`
+	TotalCost   float64 `json:"totalCost"`
+}
+
+// dateBuf stores a short JSON string (ccusage dates are YYYY-MM-DD) inline.
+type dateBuf struct {
+	buf [16]byte
+	n   int
+}
+
+func (d *dateBuf) bytes() []byte {
+	return d.buf[:d.n]
+}
+
+// UnmarshalJSON copies the quoted date into the inline buffer.
+func (d *dateBuf) UnmarshalJSON(data []byte) error {
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return fmt.Errorf("date must be a JSON string, got %s", data)
+	}
+	raw := data[1 : len(data)-1]
+	if len(raw) > len(d.buf) {
+		return fmt.Errorf("date %q is longer than %d bytes", raw, len(d.buf))
+	}
+	d.n = copy(d.buf[:], raw)
+	return nil
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("unexpected JSON token %v, expected %q", tok, want)
+	}
+	return nil
+}
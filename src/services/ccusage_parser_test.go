@@ -0,0 +1,212 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildCCUsageHistory renders a ccusage-shaped payload with the given number
+// of days, each carrying a realistic modelBreakdowns section. The final entry
+// is dated "today" so scans have to walk the whole array.
+func buildCCUsageHistory(tb testing.TB, days int, today time.Time) []byte {
+	tb.Helper()
+
+	type breakdown struct {
+		ModelName           string  `json:"modelName"`
+		InputTokens         int     `json:"inputTokens"`
+		OutputTokens        int     `json:"outputTokens"`
+		CacheCreationTokens int     `json:"cacheCreationTokens"`
+		CacheReadTokens     int     `json:"cacheReadTokens"`
+		Cost                float64 `json:"cost"`
+	}
+	type day struct {
+		Date            string      `json:"date"`
+		InputTokens     int         `json:"inputTokens"`
+		OutputTokens    int         `json:"outputTokens"`
+		TotalTokens     int         `json:"totalTokens"`
+		TotalCost       float64     `json:"totalCost"`
+		ModelsUsed      []string    `json:"modelsUsed"`
+		ModelBreakdowns []breakdown `json:"modelBreakdowns"`
+	}
+
+	payload := struct {
+		Daily  []day `json:"daily"`
+		Totals struct {
+			TotalTokens int     `json:"totalTokens"`
+			TotalCost   float64 `json:"totalCost"`
+		} `json:"totals"`
+	}{}
+
+	for i := days - 1; i >= 0; i-- {
+		d := day{
+			Date:        today.AddDate(0, 0, -i).Format("2006-01-02"),
+			TotalTokens: 1000 + i,
+			TotalCost:   float64(i) + 0.5,
+			ModelsUsed:  []string{"claude-opus-4", "claude-sonnet-4"},
+		}
+		for _, m := range d.ModelsUsed {
+			d.ModelBreakdowns = append(d.ModelBreakdowns, breakdown{
+				ModelName: m, InputTokens: 400, OutputTokens: 100,
+				CacheCreationTokens: 2000, CacheReadTokens: 50000, Cost: 1.25,
+			})
+		}
+		payload.Daily = append(payload.Daily, d)
+		payload.Totals.TotalTokens += d.TotalTokens
+		payload.Totals.TotalCost += d.TotalCost
+	}
+
+	data, err := json.MarshalIndent(payload, "", "  ")
+	require.NoError(tb, err)
+	return data
+}
+
+func TestScanDailyOutput_FindsToday(t *testing.T) {
+	today := time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)
+	data := buildCCUsageHistory(t, 30, today)
+
+	scan, err := scanDailyOutput(data, "2025-03-14", "2025-03-10")
+	require.NoError(t, err)
+
+	assert.True(t, scan.Found)
+	assert.Equal(t, "2025-03-14", scan.Today.Date)
+	assert.Equal(t, 1000, scan.Today.TotalTokens)
+	assert.Equal(t, 0.5, scan.Today.TotalCost)
+	assert.Equal(t, 30, scan.Entries)
+	assert.Equal(t, "2025-03-14", scan.LatestDate)
+
+	// 2025-03-10..14 are the last five entries: costs 4.5, 3.5, 2.5, 1.5, 0.5.
+	assert.InDelta(t, 12.5, scan.WeekCost, 1e-9)
+	assert.Equal(t, 1004+1003+1002+1001+1000, scan.WeekTokens)
+}
+
+func TestScanDailyOutput_CompareDates(t *testing.T) {
+	today := time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)
+	data := buildCCUsageHistory(t, 30, today)
+
+	scan, err := scanDailyOutput(data, "2025-03-14", "2025-03-10", "2025-03-13", "2025-03-07", "2024-01-01")
+	require.NoError(t, err)
+	require.Len(t, scan.Compare, 3)
+	assert.Equal(t, dayTotal{Cost: 1.5, Tokens: 1001, Found: true}, scan.Compare[0])
+	assert.Equal(t, dayTotal{Cost: 7.5, Tokens: 1007, Found: true}, scan.Compare[1])
+	assert.False(t, scan.Compare[2].Found)
+}
+
+func TestScanDailyOutput_NotFound(t *testing.T) {
+	today := time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)
+	data := buildCCUsageHistory(t, 3, today)
+
+	scan, err := scanDailyOutput(data, "2025-03-15", "2025-03-10")
+	require.NoError(t, err)
+
+	assert.False(t, scan.Found)
+	assert.Equal(t, 3, scan.Entries)
+	assert.Equal(t, "2025-03-14", scan.LatestDate)
+}
+
+func TestScanDailyOutput_MatchesUnmarshal(t *testing.T) {
+	today := time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)
+	data := buildCCUsageHistory(t, 10, today)
+
+	var full CCUsageResponse
+	require.NoError(t, json.Unmarshal(data, &full))
+
+	for _, d := range full.Daily {
+		scan, err := scanDailyOutput(data, d.Date, d.Date)
+		require.NoError(t, err)
+		assert.True(t, scan.Found)
+		assert.Equal(t, d, scan.Today)
+	}
+}
+
+func TestScanDailyOutput_InvalidJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"plain text", "invalid json"},
+		{"empty", ""},
+		{"array top level", `[1,2,3]`},
+		{"daily not array", `{"daily": {}}`},
+		{"truncated", `{"daily": [{"date": "2025-03-14"`},
+		{"trailing garbage", `{"daily": []} {}`},
+		{"bad entry type", `{"daily": [{"date": 12}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := scanDailyOutput([]byte(tt.input), "2025-03-14", "2025-03-10")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestScanDailyOutput_IgnoresUnknownKeys(t *testing.T) {
+	input := `{"totals": {"totalCost": 9}, "extra": [1, {"a": 2}], "daily": [{"date": "2025-03-14", "totalTokens": 5, "totalCost": 1.5}]}`
+
+	scan, err := scanDailyOutput([]byte(input), "2025-03-14", "2025-03-10")
+	require.NoError(t, err)
+	assert.True(t, scan.Found)
+	assert.Equal(t, 5, scan.Today.TotalTokens)
+}
+
+func benchmarkHistory(b *testing.B, days int) ([]byte, string) {
+	b.Helper()
+	today := time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)
+	return buildCCUsageHistory(b, days, today), today.Format("2006-01-02")
+}
+
+// BenchmarkParseFullResponse is the baseline: unmarshal everything, then
+// search the materialised slice.
+func BenchmarkParseFullResponse(b *testing.B) {
+	for _, days := range []int{30, 365} {
+		b.Run(fmt.Sprintf("days=%d", days), func(b *testing.B) {
+			data, today := benchmarkHistory(b, days)
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				var response CCUsageResponse
+				if err := json.Unmarshal(data, &response); err != nil {
+					b.Fatal(err)
+				}
+				for _, d := range response.Daily {
+					if d.Date == today {
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkScanDailyOutput measures the streaming parser used in production.
+func BenchmarkScanDailyOutput(b *testing.B) {
+	for _, days := range []int{30, 365} {
+		b.Run(fmt.Sprintf("days=%d", days), func(b *testing.B) {
+			data, today := benchmarkHistory(b, days)
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if _, err := scanDailyOutput(data, today, today); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestScanDailyOutput_TodayModelBreakdowns(t *testing.T) {
+	today := time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)
+	data := buildCCUsageHistory(t, 5, today)
+
+	scan, err := scanDailyOutput(data, "2025-03-14", "2025-03-10")
+	require.NoError(t, err)
+	require.Len(t, scan.Today.ModelBreakdowns, 2)
+	assert.Equal(t, "claude-opus-4", scan.Today.ModelBreakdowns[0].ModelName)
+	assert.Equal(t, 1.25, scan.Today.ModelBreakdowns[0].Cost)
+	assert.Equal(t, 50000, scan.Today.ModelBreakdowns[0].CacheReadTokens)
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"encoding/json"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// DailyHistory runs ccusage for the given date range and returns every day
+// it reports, oldest first. It bypasses the poll cache and leaves the
+// tracked state untouched, so it is safe to call from one-off commands.
+func (us *UsageService) DailyHistory(since, until time.Time) ([]CCUsageOutput, error) {
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+
+	output, err := us.runCCUsage([]string{
+		"daily", "--json",
+		"--since", since.Format(ccusageDateFormat),
+		"--until", until.Format(ccusageDateFormat),
+	})
+	if err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeCCUsage, "failed to run ccusage")
+	}
+
+	var response CCUsageResponse
+	if err := json.Unmarshal(output, &response); err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeCCUsage, "failed to parse ccusage JSON output")
+	}
+	return response.Daily, nil
+}
+
+// ApplyFixture runs canned `ccusage daily --json` output through the same
+// parsing and threshold logic as a live poll, without spawning ccusage.
+// The error matches what a poll returning that output would report.
+func (us *UsageService) ApplyFixture(output []byte) (*models.UsageState, error) {
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+
+	if _, err := us.applyCCUsageOutputLocked(output); err != nil {
+		return us.getStateCopyLocked(), err
+	}
+	return us.getStateCopyLocked(), nil
+}
+
+// LatestReportDate returns the newest date (YYYY-MM-DD) in a ccusage daily
+// report, or "" when it has no entries.
+func LatestReportDate(output []byte) (string, error) {
+	scan, err := scanDailyOutput(output, "", "")
+	if err != nil {
+		return "", lib.WrapError(err, lib.ErrCodeCCUsage, "failed to parse ccusage JSON output")
+	}
+	return scan.LatestDate, nil
+}
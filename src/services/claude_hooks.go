@@ -0,0 +1,347 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"cc-dailyuse-bar/src/lib"
+)
+
+// HookNotifyArgs are the arguments after the binary in the command the
+// Claude Code hook runs.
+const HookNotifyArgs = "hook notify"
+
+// DefaultHookEvent is the Claude Code hook event that fires when Claude
+// finishes responding.
+const DefaultHookEvent = "Stop"
+
+// ClaudeSettingsPath returns Claude Code's user settings file: settings.json
+// in the first CLAUDE_CONFIG_DIR entry, or else in ~/.claude.
+func ClaudeSettingsPath() (string, error) {
+	if env := strings.TrimSpace(os.Getenv("CLAUDE_CONFIG_DIR")); env != "" {
+		if dir := strings.TrimSpace(strings.Split(env, ",")[0]); dir != "" {
+			return filepath.Join(dir, "settings.json"), nil
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", lib.WrapError(err, lib.ErrCodeSystem, "failed to resolve home directory")
+	}
+	return filepath.Join(home, ".claude", "settings.json"), nil
+}
+
+// HookCommand returns the shell command a hook runs to notify the app at
+// binPath. The path is always single-quoted, so spaces, $, &, ; and the
+// like in it are taken literally; a ' in it is closed, escaped, and
+// reopened.
+func HookCommand(binPath string) string {
+	return "'" + strings.ReplaceAll(binPath, "'", `'\''`) + "' " + HookNotifyArgs
+}
+
+// hookMarker is the key InstallClaudeHook adds to its hook entries, set to
+// appDirName, so they are found again whatever the binary is called or
+// wherever it has moved. Claude Code ignores keys it doesn't know.
+const hookMarker = "installedBy"
+
+// isLegacyAppHook reports whether command is one HookCommand wrote for an
+// entry from before hookMarker, which only a binary path naming the app
+// identifies.
+func isLegacyAppHook(command string) bool {
+	return strings.HasSuffix(command, " "+HookNotifyArgs) && strings.Contains(command, appDirName)
+}
+
+// InstallClaudeHook adds a command hook running command for each event to
+// the Claude Code settings file at path, creating it if needed. Hooks a
+// previous install wrote are replaced; the rest of the file is kept.
+func InstallClaudeHook(path string, events []string, command string) error {
+	settings, mode, err := readClaudeSettings(path)
+	if err != nil {
+		return err
+	}
+	hooks := removeAppHooks(settings.hooks())
+	for _, event := range events {
+		groups, _ := hooks[event].([]interface{})
+		hooks[event] = append(groups, map[string]interface{}{
+			"hooks": []interface{}{
+				map[string]interface{}{"type": "command", "command": command, hookMarker: appDirName},
+			},
+		})
+	}
+	if err := settings.setHooks(hooks); err != nil {
+		return err
+	}
+	return writeClaudeSettings(path, settings, mode)
+}
+
+// UninstallClaudeHook removes the hooks InstallClaudeHook wrote from the
+// Claude Code settings file at path. It reports whether there were any.
+func UninstallClaudeHook(path string) (bool, error) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	settings, mode, err := readClaudeSettings(path)
+	if err != nil {
+		return false, err
+	}
+	hooks := settings.hooks()
+	before, _ := json.Marshal(hooks)
+	hooks = removeAppHooks(hooks)
+	after, _ := json.Marshal(hooks)
+	if string(before) == string(after) {
+		return false, nil
+	}
+	if err := settings.setHooks(hooks); err != nil {
+		return false, err
+	}
+	return true, writeClaudeSettings(path, settings, mode)
+}
+
+// ClaudeHookEvents lists the events the settings file at path runs an
+// installed hook on.
+func ClaudeHookEvents(path string) ([]string, error) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	settings, _, err := readClaudeSettings(path)
+	if err != nil {
+		return nil, err
+	}
+	var events []string
+	for event, groups := range settings.hooks() {
+		list, _ := groups.([]interface{})
+		for _, group := range list {
+			if groupHasAppHook(group) {
+				events = append(events, event)
+				break
+			}
+		}
+	}
+	sort.Strings(events)
+	return events, nil
+}
+
+// removeAppHooks returns hooks without the app's, dropping groups and
+// events left empty.
+func removeAppHooks(hooks map[string]interface{}) map[string]interface{} {
+	if hooks == nil {
+		return map[string]interface{}{}
+	}
+	for event, groups := range hooks {
+		list, ok := groups.([]interface{})
+		if !ok {
+			continue
+		}
+		kept := list[:0]
+		for _, group := range list {
+			if entry, ok := group.(map[string]interface{}); ok {
+				if commands, ok := entry["hooks"].([]interface{}); ok {
+					others := commands[:0]
+					for _, command := range commands {
+						if !isAppHookEntry(command) {
+							others = append(others, command)
+						}
+					}
+					if len(others) == 0 {
+						continue
+					}
+					entry["hooks"] = others
+				}
+			}
+			kept = append(kept, group)
+		}
+		if len(kept) == 0 {
+			delete(hooks, event)
+		} else {
+			hooks[event] = kept
+		}
+	}
+	return hooks
+}
+
+func groupHasAppHook(group interface{}) bool {
+	entry, _ := group.(map[string]interface{})
+	commands, _ := entry["hooks"].([]interface{})
+	for _, command := range commands {
+		if isAppHookEntry(command) {
+			return true
+		}
+	}
+	return false
+}
+
+func isAppHookEntry(hook interface{}) bool {
+	entry, _ := hook.(map[string]interface{})
+	if marker, _ := entry[hookMarker].(string); marker == appDirName {
+		return true
+	}
+	command, _ := entry["command"].(string)
+	return isLegacyAppHook(command)
+}
+
+// claudeSettings is a parsed settings file. Only "hooks" is ever decoded;
+// every other top-level value is kept byte for byte in file order, so
+// saving leaves the user's keys, their order, and their numbers alone.
+type claudeSettings struct {
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+// parseClaudeSettings reads a settings file's top-level object. Empty data
+// is an empty object.
+func parseClaudeSettings(data []byte) (*claudeSettings, error) {
+	settings := &claudeSettings{values: map[string]json.RawMessage{}}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return settings, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		settings.set(key, value)
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the settings object")
+	}
+	return settings, nil
+}
+
+func (s *claudeSettings) set(key string, value json.RawMessage) {
+	if _, ok := s.values[key]; !ok {
+		s.keys = append(s.keys, key)
+	}
+	s.values[key] = value
+}
+
+// hooks decodes the "hooks" object, with numbers as json.Number so they
+// are written back exactly. It is nil when there is none.
+func (s *claudeSettings) hooks() map[string]interface{} {
+	var hooks map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(s.values["hooks"]))
+	dec.UseNumber()
+	if err := dec.Decode(&hooks); err != nil {
+		return nil
+	}
+	return hooks
+}
+
+// setHooks replaces the "hooks" object, in place so the key keeps its
+// position, dropping it when hooks is empty.
+func (s *claudeSettings) setHooks(hooks map[string]interface{}) error {
+	if len(hooks) == 0 {
+		if _, ok := s.values["hooks"]; ok {
+			delete(s.values, "hooks")
+			s.keys = slices.DeleteFunc(s.keys, func(key string) bool { return key == "hooks" })
+		}
+		return nil
+	}
+	value, err := marshalSettingsValue(hooks)
+	if err != nil {
+		return err
+	}
+	s.set("hooks", value)
+	return nil
+}
+
+// encode formats the settings as the file is written: an object indented
+// by two spaces, keys in the order they were read.
+func (s *claudeSettings) encode() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range s.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := marshalSettingsValue(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("\n  ")
+		buf.Write(name)
+		buf.WriteString(": ")
+		if err := json.Indent(&buf, s.values[key], "  ", "  "); err != nil {
+			return nil, err
+		}
+	}
+	if len(s.keys) > 0 {
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// marshalSettingsValue is json.Marshal without escaping &, <, and >, which
+// hook commands use and which Claude Code writes as they are.
+func marshalSettingsValue(v interface{}) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// readClaudeSettings parses the settings file at path, returning an empty
+// one when it doesn't exist, and its permissions.
+func readClaudeSettings(path string) (*claudeSettings, os.FileMode, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		settings, _ := parseClaudeSettings(nil)
+		return settings, 0o644, nil
+	}
+	if err != nil {
+		return nil, 0, lib.WrapError(err, lib.ErrCodeSystem, "failed to read "+path)
+	}
+	settings, err := parseClaudeSettings(data)
+	if err != nil {
+		return nil, 0, lib.WrapError(err, lib.ErrCodeValidation, path+" isn't valid JSON; fix it before installing the hook")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, 0, lib.WrapError(err, lib.ErrCodeSystem, "failed to read "+path)
+	}
+	return settings, info.Mode().Perm(), nil
+}
+
+// writeClaudeSettings replaces the settings file, or the file it links to
+// for dotfile managers that symlink it.
+func writeClaudeSettings(path string, settings *claudeSettings, mode os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	data, err := settings.encode()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to create "+filepath.Dir(path))
+	}
+	if err := lib.WriteFileAtomic(path, append(data, '\n')); err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to write "+path)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to set permissions on "+path)
+	}
+	return nil
+}
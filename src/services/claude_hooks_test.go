@@ -0,0 +1,171 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readSettingsJSON(t *testing.T, path string) map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var settings map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &settings))
+	return settings
+}
+
+func TestInstallClaudeHook_KeepsOtherSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+  "model": "opus",
+  "hooks": {
+    "Stop": [{"hooks": [{"type": "command", "command": "say done"}]}],
+    "PreToolUse": [{"matcher": "Bash", "hooks": [{"type": "command", "command": "audit"}]}]
+  }
+}`), 0o600))
+
+	command := HookCommand("/opt/cc-dailyuse-bar/bin/cc-dailyuse-bar")
+	require.NoError(t, InstallClaudeHook(path, []string{"Stop"}, command))
+	require.NoError(t, InstallClaudeHook(path, []string{"Stop", "SubagentStop"}, command), "reinstalling replaces the hook")
+
+	settings := readSettingsJSON(t, path)
+	assert.Equal(t, "opus", settings["model"])
+	hooks := settings["hooks"].(map[string]interface{})
+	assert.Len(t, hooks["Stop"], 2, "the user's hook and one of ours")
+	assert.Len(t, hooks["SubagentStop"], 1)
+	assert.Len(t, hooks["PreToolUse"], 1)
+
+	events, err := ClaudeHookEvents(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Stop", "SubagentStop"}, events)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "permissions are kept")
+
+	removed, err := UninstallClaudeHook(path)
+	require.NoError(t, err)
+	assert.True(t, removed)
+	settings = readSettingsJSON(t, path)
+	hooks = settings["hooks"].(map[string]interface{})
+	assert.Len(t, hooks["Stop"], 1)
+	assert.NotContains(t, hooks, "SubagentStop")
+
+	removed, err = UninstallClaudeHook(path)
+	require.NoError(t, err)
+	assert.False(t, removed)
+}
+
+func TestInstallClaudeHook_NewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "claude", "settings.json")
+	require.NoError(t, InstallClaudeHook(path, []string{DefaultHookEvent}, HookCommand("/Applications/CC Daily Use Bar.app/Contents/MacOS/cc-dailyuse-bar")))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"command": "'/Applications/CC Daily Use Bar.app/Contents/MacOS/cc-dailyuse-bar' hook notify"`)
+
+	removed, err := UninstallClaudeHook(path)
+	require.NoError(t, err)
+	assert.True(t, removed)
+	assert.NotContains(t, readSettingsJSON(t, path), "hooks", "an emptied hooks section is dropped")
+
+	removed, err = UninstallClaudeHook(filepath.Join(t.TempDir(), "missing.json"))
+	require.NoError(t, err)
+	assert.False(t, removed)
+}
+
+func TestInstallClaudeHook_FindsOwnHooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+  "hooks": {
+    "Stop": [{"hooks": [{"type": "command", "command": "/usr/bin/cc-dailyuse-bar hook notify"}]}]
+  }
+}`), 0o600))
+
+	// A renamed binary is still recognised by the marker, and the entry an
+	// earlier version wrote without one is replaced.
+	require.NoError(t, InstallClaudeHook(path, []string{"Stop"}, HookCommand("/usr/local/bin/ccbar")))
+	stop := readSettingsJSON(t, path)["hooks"].(map[string]interface{})["Stop"].([]interface{})
+	require.Len(t, stop, 1)
+	entry := stop[0].(map[string]interface{})["hooks"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "'/usr/local/bin/ccbar' hook notify", entry["command"])
+	assert.Equal(t, appDirName, entry[hookMarker])
+
+	events, err := ClaudeHookEvents(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Stop"}, events)
+	removed, err := UninstallClaudeHook(path)
+	require.NoError(t, err)
+	assert.True(t, removed)
+}
+
+func TestInstallClaudeHook_KeepsFileLayout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+  "model": "opus",
+  "cleanupPeriodDays": 9007199254740993,
+  "hooks": {
+    "Stop": [{"hooks": [{"type": "command", "command": "say done", "timeout": 12345678901234567}]}]
+  },
+  "env": {"B": "2", "A": "1"}
+}`), 0o600))
+
+	require.NoError(t, InstallClaudeHook(path, []string{"Stop"}, HookCommand("/usr/bin/cc-dailyuse-bar")))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	text := string(data)
+	assert.Contains(t, text, `"cleanupPeriodDays": 9007199254740993`, "large integers keep their precision")
+	assert.Contains(t, text, `"timeout": 12345678901234567`, "including inside hooks")
+	assert.Contains(t, text, `"env": {
+    "B": "2",
+    "A": "1"
+  }`, "other values are kept as written")
+	model, hooks, env := strings.Index(text, `"model"`), strings.Index(text, `"hooks"`), strings.Index(text, `"env"`)
+	assert.True(t, model < hooks && hooks < env, "top-level keys keep their order:\n%s", text)
+
+	removed, err := UninstallClaudeHook(path)
+	require.NoError(t, err)
+	assert.True(t, removed)
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"cleanupPeriodDays": 9007199254740993`)
+}
+
+func TestHookCommand(t *testing.T) {
+	assert.Equal(t, "'/usr/bin/cc-dailyuse-bar' hook notify", HookCommand("/usr/bin/cc-dailyuse-bar"))
+	assert.Equal(t, `'/home/o'\''brien/$HOME & co; bin/ccbar' hook notify`, HookCommand("/home/o'brien/$HOME & co; bin/ccbar"))
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh to run the command with")
+	}
+	path := "/tmp/o'brien/$HOME & co; `id` \"ccbar\""
+	out, err := exec.Command("sh", "-c", strings.Replace(HookCommand(path), "'", "printf %s '", 1)).Output()
+	require.NoError(t, err)
+	assert.Equal(t, path+"hooknotify", string(out), "the shell sees the path as one literal word")
+}
+
+func TestInstallClaudeHook_InvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"model": `), 0o644))
+	err := InstallClaudeHook(path, []string{DefaultHookEvent}, HookCommand("/usr/bin/cc-dailyuse-bar"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "isn't valid JSON")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"model": `, string(data), "the file is left alone")
+}
+
+func TestClaudeSettingsPath(t *testing.T) {
+	t.Setenv("CLAUDE_CONFIG_DIR", "/tmp/claude-a, /tmp/claude-b")
+	path, err := ClaudeSettingsPath()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/tmp/claude-a", "settings.json"), path)
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// CommandRunner runs an external program. UsageService runs ccusage
+// through one, so tests can stand in for ccusage without writing scripts.
+type CommandRunner interface {
+	// Run runs name with args until it exits or ctx is done, with env
+	// ("KEY=value") added to the inherited environment. err is non-nil
+	// whenever the program didn't exit 0; exitCode is -1 when it didn't
+	// start or was killed.
+	Run(ctx context.Context, name string, args []string, env ...string) (stdout, stderr []byte, exitCode int, err error)
+}
+
+// pathResolver is implemented by runners that can locate a program before
+// it runs, which the ccusage availability check uses. Runners without it,
+// such as test fakes, are taken to have every program.
+type pathResolver interface {
+	LookPath(name string) (string, error)
+}
+
+// ExecRunner is the CommandRunner that starts real processes.
+type ExecRunner struct{}
+
+// Run implements CommandRunner with os/exec.
+func (ExecRunner) Run(ctx context.Context, name string, args []string, env ...string) ([]byte, []byte, int, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	exitCode := 0
+	if err != nil {
+		exitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+	}
+	return stdout.Bytes(), stderr.Bytes(), exitCode, err
+}
+
+// LookPath finds name the way Run will: on PATH for bare names, never in
+// the working directory.
+func (ExecRunner) LookPath(name string) (string, error) {
+	return exec.LookPath(name)
+}
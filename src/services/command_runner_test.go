@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+// fakeCall is one invocation a fakeRunner received.
+type fakeCall struct {
+	name string
+	args []string
+	env  []string
+}
+
+// fakeRunner stands in for ccusage: respond answers each invocation with
+// its stdout and exit code.
+type fakeRunner struct {
+	calls   []fakeCall
+	respond func(args []string) (string, int)
+}
+
+func (f *fakeRunner) Run(_ context.Context, name string, args []string, env ...string) ([]byte, []byte, int, error) {
+	f.calls = append(f.calls, fakeCall{name: name, args: args, env: env})
+	stdout, code := f.respond(args)
+	if code != 0 {
+		return []byte(stdout), []byte("ccusage failed"), code, fmt.Errorf("exit status %d", code)
+	}
+	return []byte(stdout), nil, 0, nil
+}
+
+func TestExecRunner_Run(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	stdout, stderr, code, err := ExecRunner{}.Run(context.Background(), "sh",
+		[]string{"-c", `echo "$GREETING"; echo oops >&2; exit 3`}, "GREETING=hello")
+	require.Error(t, err)
+	assert.Equal(t, "hello\n", string(stdout))
+	assert.Equal(t, "oops\n", string(stderr))
+	assert.Equal(t, 3, code)
+
+	_, _, code, err = ExecRunner{}.Run(context.Background(), "/nonexistent/ccusage", nil)
+	require.Error(t, err)
+	assert.Equal(t, -1, code, "a program that never started has no exit code")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, _, code, err = ExecRunner{}.Run(ctx, "sleep", []string{"5"})
+	require.Error(t, err)
+	assert.Equal(t, -1, code, "killed by the deadline")
+}
+
+func TestUsageService_CommandRunnerRetries(t *testing.T) {
+	failures := 1
+	runner := &fakeRunner{respond: func(args []string) (string, int) {
+		if args[0] == "session" {
+			return `{"sessions":[]}`, 0
+		}
+		if failures > 0 {
+			failures--
+			return "", 1
+		}
+		return `{"daily":[{"date":"2025-03-14","totalTokens":1200,"totalCost":7.5}]}`, 0
+	}}
+	var slept []time.Duration
+	service := newTestUsageService()
+	service.SetCommandRunner(runner)
+	service.sleep = func(d time.Duration) { slept = append(slept, d) }
+	service.SetClock(fixedClock{now: time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)})
+	service.claudeDataDir = "/data/claude"
+
+	state, err := service.updateWithRetry(3)
+	require.NoError(t, err)
+	assert.Equal(t, 7.5, state.DailyCost)
+	assert.Equal(t, models.Green, state.Status)
+	assert.Equal(t, []time.Duration{time.Second}, slept, "one retry after the failed run")
+
+	require.NotEmpty(t, runner.calls)
+	first := runner.calls[0]
+	assert.Equal(t, "ccusage", first.name, "no PATH lookup with a fake runner")
+	assert.Equal(t, ccusageDailyArgs(time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)), first.args)
+	assert.Equal(t, []string{"CLAUDE_CONFIG_DIR=/data/claude"}, first.env)
+}
+
+func TestUsageService_CommandRunnerParseFailure(t *testing.T) {
+	runner := &fakeRunner{respond: func([]string) (string, int) { return "not json", 0 }}
+	service := newTestUsageService()
+	service.SetCommandRunner(runner)
+	service.sleep = func(time.Duration) { t.Fatal("a malformed report isn't retried") }
+
+	state, err := service.updateWithRetry(2)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse ccusage JSON output")
+	assert.Equal(t, models.Unknown, state.Status)
+	assert.Len(t, runner.calls, 1)
+}
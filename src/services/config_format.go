@@ -0,0 +1,174 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// ConfigFormat is the on-disk encoding of the config file.
+type ConfigFormat string
+
+// Supported config file formats, chosen by file extension.
+const (
+	FormatYAML ConfigFormat = "yaml"
+	FormatTOML ConfigFormat = "toml"
+	FormatJSON ConfigFormat = "json"
+)
+
+// configFileNames are the default config file names in lookup order.
+var configFileNames = []string{"config.yaml", "config.toml", "config.json"}
+
+// ConfigFormatFor picks the format from path's extension, defaulting to
+// YAML for .yaml, .yml, and anything unrecognised.
+func ConfigFormatFor(path string) ConfigFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		return FormatTOML
+	case ".json":
+		return FormatJSON
+	default:
+		return FormatYAML
+	}
+}
+
+// DecodeConfig parses data in the given format into config. TOML and JSON
+// are converted to a generic document and decoded through the YAML tags,
+// so Config keeps a single set of field names and custom (un)marshalers.
+func DecodeConfig(format ConfigFormat, data []byte, config *models.Config) error {
+	if format == FormatYAML {
+		return yaml.Unmarshal(data, config)
+	}
+	converted, err := ConfigToYAML(format, data)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(converted, config)
+}
+
+// ConfigToYAML re-encodes a TOML or JSON config document as YAML.
+func ConfigToYAML(format ConfigFormat, data []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	switch format {
+	case FormatYAML:
+		return data, nil
+	case FormatTOML:
+		if _, err := toml.Decode(string(data), &doc); err != nil {
+			return nil, err
+		}
+	case FormatJSON:
+		if len(bytes.TrimSpace(data)) == 0 {
+			return nil, nil
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, lib.ValidationError("unsupported config format " + string(format))
+	}
+	return yaml.Marshal(normalizeTOMLValues(doc))
+}
+
+// EncodeConfig serialises config in the given format.
+func EncodeConfig(format ConfigFormat, config *models.Config) ([]byte, error) {
+	if format == FormatYAML {
+		return yaml.Marshal(config)
+	}
+	doc, err := configDocument(config)
+	if err != nil {
+		return nil, err
+	}
+	return encodeDocument(format, doc)
+}
+
+// configDocument renders config as a generic document keyed by YAML field
+// name, omitting empty optional fields.
+func configDocument(config *models.Config) (map[string]interface{}, error) {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	return decodeDocument(FormatYAML, data)
+}
+
+// decodeDocument parses a config file into a generic document.
+func decodeDocument(format ConfigFormat, data []byte) (map[string]interface{}, error) {
+	data, err := ConfigToYAML(format, data)
+	if err != nil {
+		return nil, err
+	}
+	doc := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+	return doc, nil
+}
+
+func encodeDocument(format ConfigFormat, doc map[string]interface{}) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		return yaml.Marshal(doc)
+	case FormatTOML:
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(doc); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case FormatJSON:
+		out, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(out, '\n'), nil
+	default:
+		return nil, lib.ValidationError("unsupported config format " + string(format))
+	}
+}
+
+// normalizeTOMLValues turns TOML's local date/time values into strings so
+// an unquoted `quiet_until = 2025-03-20` reads like its YAML equivalent.
+func normalizeTOMLValues(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = normalizeTOMLValues(item)
+		}
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = normalizeTOMLValues(item)
+		}
+		return val
+	case []map[string]interface{}:
+		items := make([]interface{}, len(val))
+		for i, item := range val {
+			items[i] = normalizeTOMLValues(item)
+		}
+		return items
+	case time.Time:
+		// The toml package marks local values with named zones.
+		switch val.Location().String() {
+		case "date-local":
+			return val.Format("2006-01-02")
+		case "datetime-local":
+			return val.Format("2006-01-02T15:04:05.999999999")
+		case "time-local":
+			return val.Format("15:04:05.999999999")
+		default:
+			return val.Format(time.RFC3339Nano)
+		}
+	default:
+		return v
+	}
+}
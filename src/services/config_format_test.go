@@ -0,0 +1,130 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+func TestConfigFormatFor(t *testing.T) {
+	assert.Equal(t, FormatYAML, ConfigFormatFor("config.yaml"))
+	assert.Equal(t, FormatYAML, ConfigFormatFor("config.yml"))
+	assert.Equal(t, FormatYAML, ConfigFormatFor("config"))
+	assert.Equal(t, FormatTOML, ConfigFormatFor("/etc/ccdb/Config.TOML"))
+	assert.Equal(t, FormatJSON, ConfigFormatFor("config.json"))
+}
+
+func TestDecodeConfig_TOMLAndJSON(t *testing.T) {
+	toml := `ccusage_path = "bunx ccusage"
+update_interval = 60
+yellow_threshold = 5
+red_threshold = 12.5
+debug_level = "INFO"
+cache_window = 10
+cmd_timeout = 30
+quiet_until = 2025-03-20
+
+[[alert_levels]]
+name = "half"
+threshold = 10
+status = "yellow"
+
+[day_thresholds.weekends]
+yellow_threshold = 2
+red_threshold = 5
+
+[model_thresholds]
+opus = 10
+`
+	json := `{
+  "ccusage_path": "bunx ccusage",
+  "update_interval": 60,
+  "yellow_threshold": 5,
+  "red_threshold": 12.5,
+  "debug_level": "INFO",
+  "cache_window": 10,
+  "cmd_timeout": 30,
+  "quiet_until": "2025-03-20",
+  "alert_levels": [{"name": "half", "threshold": 10, "status": "yellow"}],
+  "day_thresholds": {"weekends": {"yellow_threshold": 2, "red_threshold": 5}},
+  "model_thresholds": {"opus": 10}
+}`
+
+	for format, data := range map[ConfigFormat]string{FormatTOML: toml, FormatJSON: json} {
+		t.Run(string(format), func(t *testing.T) {
+			var config models.Config
+			require.NoError(t, DecodeConfig(format, []byte(data), &config))
+			assert.Equal(t, "bunx ccusage", config.CCUsagePath)
+			assert.Equal(t, 60, config.UpdateInterval)
+			assert.Equal(t, 12.5, config.RedThreshold)
+			assert.Equal(t, "2025-03-20", config.QuietUntil)
+			assert.Equal(t, []models.AlertLevel{{Name: "half", Threshold: 10, Status: models.Yellow}}, config.AlertLevels)
+			assert.Equal(t, models.ThresholdPair{YellowThreshold: 2, RedThreshold: 5}, config.DayThresholds["weekends"])
+			assert.Equal(t, 10.0, config.ModelThresholds["opus"])
+			assert.NoError(t, config.Validate())
+		})
+	}
+}
+
+func TestEncodeConfig_RoundTrips(t *testing.T) {
+	precision := 0
+	original := models.ConfigDefaults()
+	original.AlertLevels = []models.AlertLevel{{Name: "high", Threshold: 15, Status: models.Red, Symbol: "🟠"}}
+	original.ModelThresholds = map[string]float64{"opus": 7.5}
+	original.TitleCostPrecision = &precision
+
+	for _, format := range []ConfigFormat{FormatYAML, FormatTOML, FormatJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			data, err := EncodeConfig(format, original)
+			require.NoError(t, err)
+
+			var decoded models.Config
+			require.NoError(t, DecodeConfig(format, data, &decoded))
+			assert.Equal(t, *original, decoded)
+		})
+	}
+}
+
+func TestDecodeConfig_Errors(t *testing.T) {
+	var config models.Config
+	assert.Error(t, DecodeConfig(FormatTOML, []byte("update_interval = "), &config))
+	assert.Error(t, DecodeConfig(FormatJSON, []byte("{"), &config))
+	assert.NoError(t, DecodeConfig(FormatJSON, []byte(" "), &config), "empty file reads as zero config")
+}
+
+func TestConfigService_LoadSaveByExtension(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"config.toml", "config.json"} {
+		t.Run(name, func(t *testing.T) {
+			svc := NewConfigService()
+			svc.SetConfigPath(filepath.Join(dir, name))
+
+			config := models.ConfigDefaults()
+			config.WeeklyBudget = 150
+			require.NoError(t, svc.Save(config))
+
+			loaded, err := svc.Load()
+			require.NoError(t, err)
+			assert.Equal(t, config, loaded)
+		})
+	}
+}
+
+func TestFindConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, filepath.Join(dir, "config.yaml"), findConfigFile(dir), "defaults to YAML")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte("{}"), 0o644))
+	assert.Equal(t, filepath.Join(dir, "config.json"), findConfigFile(dir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.toml"), nil, 0o644))
+	assert.Equal(t, filepath.Join(dir, "config.toml"), findConfigFile(dir), "TOML wins over JSON")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), nil, 0o644))
+	assert.Equal(t, filepath.Join(dir, "config.yaml"), findConfigFile(dir), "YAML wins over both")
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// legacyConfigNames are config files kept in the home directory before the
+// app moved to the XDG config directory, in the order they're looked for.
+var legacyConfigNames = []string{
+	".cc-dailyuse-bar.yaml",
+	".cc-dailyuse-bar.yml",
+	".cc-dailyuse-bar.toml",
+	".cc-dailyuse-bar.json",
+}
+
+// ConfigMigration describes a legacy config file MigrateLegacyConfig found.
+type ConfigMigration struct {
+	From    string // the legacy file
+	To      string // where it was copied; "" when Ignored
+	Backup  string // what the legacy file was renamed to; "" when Ignored
+	Ignored bool   // a config already exists in the config directory, so it was left alone
+}
+
+// MigrateLegacyConfig moves the first legacy config found in home to the
+// config directory, keeping its format, and renames the original with a
+// .bak suffix so it's kept but not migrated again. A file that doesn't
+// parse is left in place and reported as an error. It returns nil when
+// there's no legacy config.
+func MigrateLegacyConfig(paths *PathResolver, home string) (*ConfigMigration, error) {
+	var from string
+	for _, name := range legacyConfigNames {
+		path := filepath.Join(home, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			from = path
+			break
+		}
+	}
+	if from == "" {
+		return nil, nil
+	}
+
+	dir := paths.ConfigDir()
+	for _, name := range configFileNames {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return &ConfigMigration{From: from, Ignored: true}, nil
+		}
+	}
+
+	info, err := os.Stat(from)
+	if err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeConfig, "failed to read legacy config "+from)
+	}
+	data, err := os.ReadFile(from)
+	if err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeConfig, "failed to read legacy config "+from)
+	}
+	format := ConfigFormatFor(from)
+	var config models.Config
+	if err := DecodeConfig(format, data, &config); err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeConfig, "legacy config "+from+" doesn't parse; not migrated")
+	}
+
+	migration := &ConfigMigration{
+		From:   from,
+		To:     filepath.Join(dir, "config."+string(format)),
+		Backup: from + ".bak",
+	}
+	if _, err := os.Stat(migration.Backup); err == nil {
+		return nil, lib.ConfigError("can't migrate " + from + ": its backup " + migration.Backup + " already exists")
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, lib.WrapError(err, lib.ErrCodeConfig, "failed to check "+migration.Backup)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeConfig, "failed to create config directory")
+	}
+	// O_EXCL so a config written since the check above is never replaced.
+	file, err := os.OpenFile(migration.To, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeConfig, "failed to create "+migration.To)
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(migration.To)
+		return nil, lib.WrapError(err, lib.ErrCodeConfig, "failed to write "+migration.To)
+	}
+	if err := os.Rename(from, migration.Backup); err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeConfig, "failed to back up "+from)
+	}
+	return migration, nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateLegacyConfig(t *testing.T) {
+	home := t.TempDir()
+	paths := NewPathResolverAt(t.TempDir())
+
+	migration, err := MigrateLegacyConfig(paths, home)
+	require.NoError(t, err)
+	assert.Nil(t, migration, "nothing to migrate")
+
+	legacy := filepath.Join(home, ".cc-dailyuse-bar.yml")
+	require.NoError(t, os.WriteFile(legacy, []byte("red_threshold: 42\n"), 0o600))
+
+	migration, err = MigrateLegacyConfig(paths, home)
+	require.NoError(t, err)
+	require.NotNil(t, migration)
+	assert.Equal(t, filepath.Join(paths.ConfigDir(), "config.yaml"), migration.To)
+	assert.Equal(t, legacy+".bak", migration.Backup)
+	data, err := os.ReadFile(migration.To)
+	require.NoError(t, err)
+	assert.Equal(t, "red_threshold: 42\n", string(data))
+	info, err := os.Stat(migration.To)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "keeps the legacy file's permissions")
+	assert.NoFileExists(t, legacy)
+	assert.FileExists(t, migration.Backup)
+
+	migration, err = MigrateLegacyConfig(paths, home)
+	require.NoError(t, err)
+	assert.Nil(t, migration, "migrated only once")
+}
+
+func TestMigrateLegacyConfig_ExistingConfigWins(t *testing.T) {
+	home := t.TempDir()
+	paths := NewPathResolverAt(t.TempDir())
+	require.NoError(t, os.MkdirAll(paths.ConfigDir(), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(paths.ConfigDir(), "config.toml"), []byte("red_threshold = 30\n"), 0o644))
+	legacy := filepath.Join(home, ".cc-dailyuse-bar.yaml")
+	require.NoError(t, os.WriteFile(legacy, []byte("red_threshold: 42\n"), 0o644))
+
+	migration, err := MigrateLegacyConfig(paths, home)
+	require.NoError(t, err)
+	assert.Equal(t, &ConfigMigration{From: legacy, Ignored: true}, migration)
+	assert.FileExists(t, legacy)
+}
+
+func TestMigrateLegacyConfig_LeavesInvalidFile(t *testing.T) {
+	home := t.TempDir()
+	paths := NewPathResolverAt(t.TempDir())
+	legacy := filepath.Join(home, ".cc-dailyuse-bar.json")
+	require.NoError(t, os.WriteFile(legacy, []byte("{not json"), 0o644))
+
+	_, err := MigrateLegacyConfig(paths, home)
+	assert.ErrorContains(t, err, "doesn't parse")
+	assert.FileExists(t, legacy)
+	assert.NoDirExists(t, paths.ConfigDir())
+}
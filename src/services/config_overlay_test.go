@@ -0,0 +1,113 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const overlayBaseConfig = `ccusage_path: ccusage
+update_interval: 30
+yellow_threshold: 10
+red_threshold: 20
+debug_level: INFO
+cache_window: 10
+cmd_timeout: 30
+day_thresholds:
+  weekends: {yellow_threshold: 2, red_threshold: 5}
+`
+
+func writeOverlayFiles(t *testing.T, base, local string) (*ConfigService, string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "config.yaml")
+	localPath := filepath.Join(dir, "config.local.yaml")
+	if base != "" {
+		require.NoError(t, os.WriteFile(basePath, []byte(base), 0o644))
+	}
+	if local != "" {
+		require.NoError(t, os.WriteFile(localPath, []byte(local), 0o644))
+	}
+	svc := NewConfigService()
+	svc.SetConfigPath(basePath)
+	return svc, basePath, localPath
+}
+
+func TestConfigService_LocalConfigPath(t *testing.T) {
+	svc := NewConfigService()
+	svc.SetConfigPath("/etc/ccdb/config.toml")
+	assert.Equal(t, "/etc/ccdb/config.local.toml", svc.LocalConfigPath())
+}
+
+func TestConfigService_LoadAppliesLocalOverlay(t *testing.T) {
+	svc, _, _ := writeOverlayFiles(t, overlayBaseConfig, `ccusage_path: /opt/work/bin/ccusage
+day_thresholds:
+  friday: {yellow_threshold: 15, red_threshold: 30}
+`)
+
+	config, err := svc.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "/opt/work/bin/ccusage", config.CCUsagePath, "overlay wins")
+	assert.Equal(t, 30, config.UpdateInterval, "base kept where not overridden")
+	assert.Len(t, config.DayThresholds, 2, "maps merge key by key")
+}
+
+func TestConfigService_LoadOverlayErrors(t *testing.T) {
+	svc, _, _ := writeOverlayFiles(t, overlayBaseConfig, "update_interval: [")
+	_, err := svc.Load()
+	assert.ErrorContains(t, err, "config.local.yaml")
+
+	svc, _, _ = writeOverlayFiles(t, overlayBaseConfig, "update_interval: 1\n")
+	_, err = svc.Load()
+	assert.Error(t, err, "merged result is validated")
+
+	svc, _, _ = writeOverlayFiles(t, "", "weekly_budget: 50\n")
+	config, err := svc.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, config.WeeklyBudget, "overlay applies on top of defaults without a base file")
+}
+
+func TestConfigService_SaveKeepsOverlaySettingsLocal(t *testing.T) {
+	svc, basePath, localPath := writeOverlayFiles(t, overlayBaseConfig, "ccusage_path: /opt/work/bin/ccusage\nquiet_until: \"2025-03-01\"\n")
+
+	config, err := svc.Load()
+	require.NoError(t, err)
+	config.QuietUntil = "2025-03-20" // defined by the overlay
+	config.WeeklyBudget = 100        // not in the overlay
+	require.NoError(t, svc.Save(config))
+
+	var base, local map[string]interface{}
+	data, err := os.ReadFile(basePath)
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(data, &base))
+	data, err = os.ReadFile(localPath)
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(data, &local))
+
+	assert.Equal(t, "ccusage", base["ccusage_path"], "work path stays out of the base file")
+	assert.NotContains(t, base, "quiet_until")
+	assert.Equal(t, 100, base["weekly_budget"])
+	assert.Equal(t, "/opt/work/bin/ccusage", local["ccusage_path"])
+	assert.Equal(t, "2025-03-20", local["quiet_until"])
+
+	reloaded, err := svc.Load()
+	require.NoError(t, err)
+	assert.Equal(t, config, reloaded)
+}
+
+func TestConfigService_SaveLeavesUnchangedOverlayAlone(t *testing.T) {
+	svc, _, localPath := writeOverlayFiles(t, overlayBaseConfig, "# work laptop\nccusage_path: /opt/work/bin/ccusage\n")
+
+	config, err := svc.Load()
+	require.NoError(t, err)
+	config.UpdateInterval = 60
+	require.NoError(t, svc.Save(config))
+
+	data, err := os.ReadFile(localPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# work laptop\nccusage_path: /opt/work/bin/ccusage\n", string(data), "comments survive when nothing changed")
+}
@@ -1,12 +1,16 @@
 package services
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"os"
 	"path/filepath"
-
-	"github.com/adrg/xdg"
-	"gopkg.in/yaml.v3"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 
 	"cc-dailyuse-bar/src/lib"
 	"cc-dailyuse-bar/src/models"
@@ -19,6 +23,17 @@ type ConfigService struct {
 	readFile   func(string) ([]byte, error)
 	writeFile  func(string, []byte, os.FileMode) error
 	mkdirAll   func(string, os.FileMode) error
+
+	secretStores map[string]SecretStore  // by backend; see secretStore
+	secretsMu    sync.Mutex              // guards secrets: reloads and the tray load and save concurrently
+	secrets      map[string]openedSecret // by field path, as last loaded or encrypted
+}
+
+// openedSecret is an encrypted config value and what it decrypted to, so
+// Save can write the reference back while the value is unchanged.
+type openedSecret struct {
+	ref   string
+	plain string
 }
 
 // NewConfigService creates a new ConfigService instance
@@ -28,52 +43,104 @@ func NewConfigService() *ConfigService {
 		readFile:  os.ReadFile,
 		writeFile: os.WriteFile,
 		mkdirAll:  os.MkdirAll,
+		secrets:   map[string]openedSecret{},
 	}
 }
 
-// Load reads configuration from XDG-compliant storage
-// Returns default config if file doesn't exist
+// Load reads configuration from XDG-compliant storage, then applies the
+// optional local overlay (see LocalConfigPath) on top.
+// Returns default config if neither file exists
 // Returns error for permission/system issues, corrupted files, or invalid configurations
 func (cs *ConfigService) Load() (*models.Config, error) {
+	config, err := cs.LoadUnchecked()
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate the loaded config - propagate validation errors (invalid config)
+	if err := cs.Validate(config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// LoadUnchecked is Load without validation, so doctor can repair a config
+// whose values are out of range. Unreadable or unparseable files are still
+// errors.
+func (cs *ConfigService) LoadUnchecked() (*models.Config, error) {
 	configPath := cs.GetConfigPath()
 
 	data, err := cs.readFile(configPath)
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return models.ConfigDefaults(), nil
-		}
+	baseMissing := errors.Is(err, os.ErrNotExist)
+	if err != nil && !baseMissing {
 		return nil, err
 	}
 
-	// Parse YAML - propagate parsing errors (corrupted file)
-	var config models.Config
-	err = yaml.Unmarshal(data, &config)
-	if err != nil {
+	localPath := cs.LocalConfigPath()
+	local, err := cs.readFile(localPath)
+	localMissing := errors.Is(err, os.ErrNotExist)
+	if err != nil && !localMissing {
 		return nil, err
 	}
 
-	// Validate the loaded config - propagate validation errors (invalid config)
-	if err := cs.Validate(&config); err != nil {
+	if baseMissing && localMissing {
+		return models.ConfigDefaults(), nil
+	}
+
+	// Parse - propagate parsing errors (corrupted file). Decoding the
+	// overlay into the same struct replaces only the fields it sets; maps
+	// such as day_thresholds gain or replace individual keys.
+	var config models.Config
+	if baseMissing {
+		config = *models.ConfigDefaults()
+	} else if err := DecodeConfig(ConfigFormatFor(configPath), data, &config); err != nil {
 		return nil, err
 	}
+	if !localMissing {
+		if err := DecodeConfig(ConfigFormatFor(localPath), local, &config); err != nil {
+			return nil, lib.WrapError(err, lib.ErrCodeConfig, "failed to parse "+localPath)
+		}
+		cs.logger.Debug("Applied local config overlay", map[string]interface{}{
+			"path": localPath,
+		})
+	}
 
+	if err := cs.openSecrets(&config); err != nil {
+		return nil, err
+	}
 	return &config, nil
 }
 
-// Save writes the configuration to disk
+// Save writes the configuration to disk. When a local overlay exists, each
+// setting it defines is updated there and everything else goes to the base
+// file, so machine-specific overrides never leak into a shared config.
+// Values that were encrypted are written back encrypted unless they've
+// changed since.
 func (cs *ConfigService) Save(config *models.Config) error {
 	// Validate before saving
 	if err := cs.Validate(config); err != nil {
 		return err
 	}
+	config = cs.sealSecrets(config)
 
-	data, err := yaml.Marshal(config)
+	configPath := cs.GetConfigPath()
+	localPath := cs.LocalConfigPath()
+	local, err := cs.readFile(localPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return lib.WrapError(err, lib.ErrCodeConfig, "failed to read "+localPath)
+	}
+
+	var data []byte
+	if err != nil {
+		data, err = EncodeConfig(ConfigFormatFor(configPath), config)
+	} else {
+		data, local, err = cs.splitForOverlay(config, configPath, localPath, local)
+	}
 	if err != nil {
 		return lib.WrapError(err, lib.ErrCodeConfig, "failed to marshal config")
 	}
 
-	configPath := cs.GetConfigPath()
-
 	// Ensure directory exists
 	if err := cs.EnsureConfigDir(); err != nil {
 		return err
@@ -82,10 +149,179 @@ func (cs *ConfigService) Save(config *models.Config) error {
 	if err := cs.writeFile(configPath, data, 0644); err != nil {
 		return lib.WrapError(err, lib.ErrCodeConfig, "failed to write config file")
 	}
+	if local != nil {
+		if err := cs.writeFile(localPath, local, 0644); err != nil {
+			return lib.WrapError(err, lib.ErrCodeConfig, "failed to write local config file")
+		}
+	}
 
 	return nil
 }
 
+// openSecrets decrypts the config's "enc:" values in place, remembering
+// each so Save can keep it encrypted.
+func (cs *ConfigService) openSecrets(config *models.Config) error {
+	opened := map[string]openedSecret{}
+	for path, value := range config.SensitiveFields() {
+		if !models.IsSecretRef(*value) {
+			continue
+		}
+		backend, payload, err := parseSecretRef(*value)
+		if err != nil {
+			return lib.WrapError(err, lib.ErrCodeConfig, path)
+		}
+		store, err := cs.secretStore(backend)
+		if err != nil {
+			return lib.WrapError(err, lib.ErrCodeConfig, path)
+		}
+		plain, err := store.Open(payload)
+		if err != nil {
+			return lib.WrapError(err, lib.ErrCodeConfig, "failed to decrypt "+path)
+		}
+		opened[path] = openedSecret{ref: *value, plain: plain}
+		*value = plain
+	}
+	if len(opened) > 0 {
+		cs.logger.Debug("Decrypted config values", map[string]interface{}{
+			"count": len(opened),
+		})
+	}
+	cs.secretsMu.Lock()
+	cs.secrets = opened
+	cs.secretsMu.Unlock()
+	return nil
+}
+
+// sealSecrets returns a copy of config with each value that still matches
+// what was decrypted replaced by its encrypted reference.
+func (cs *ConfigService) sealSecrets(config *models.Config) *models.Config {
+	cs.secretsMu.Lock()
+	defer cs.secretsMu.Unlock()
+	if len(cs.secrets) == 0 {
+		return config
+	}
+	sealed := *config
+	sealed.NotificationSinks = append([]models.NotificationSink(nil), config.NotificationSinks...)
+	for path, value := range sealed.SensitiveFields() {
+		if secret, ok := cs.secrets[path]; ok && secret.plain == *value {
+			*value = secret.ref
+		}
+	}
+	return &sealed
+}
+
+// Encrypt stores the config's value at field (a key of
+// Config.SensitiveFields) with backend and saves the config with the
+// encrypted reference in its place.
+func (cs *ConfigService) Encrypt(config *models.Config, field, backend string) error {
+	value, ok := config.SensitiveFields()[field]
+	if !ok {
+		return lib.ValidationError("no sensitive value set at " + field)
+	}
+	if models.IsSecretRef(*value) {
+		return lib.ValidationError(field + " is already encrypted")
+	}
+	cs.secretsMu.Lock()
+	secret, ok := cs.secrets[field]
+	cs.secretsMu.Unlock()
+	if ok && secret.plain == *value {
+		return lib.ValidationError(field + " is already encrypted")
+	}
+	store, err := cs.secretStore(backend)
+	if err != nil {
+		return err
+	}
+	payload, err := store.Seal(cs.secretAccount(field), *value)
+	if err != nil {
+		return err
+	}
+	cs.secretsMu.Lock()
+	cs.secrets[field] = openedSecret{ref: models.SecretPrefix + backend + ":" + payload, plain: *value}
+	cs.secretsMu.Unlock()
+	return cs.Save(config)
+}
+
+// secretStore returns the store for backend: the OS keychain, or age with
+// the identity beside the config file.
+func (cs *ConfigService) secretStore(backend string) (SecretStore, error) {
+	if store, ok := cs.secretStores[backend]; ok {
+		return store, nil
+	}
+	switch backend {
+	case SecretBackendKeychain:
+		return cachedStore{SecretStore: keychainStore{}, key: backend}, nil
+	case SecretBackendAge:
+		identity := cs.AgeIdentityPath()
+		return cachedStore{SecretStore: ageStore{identity: identity}, key: backend + ":" + identity}, nil
+	default:
+		return nil, lib.ValidationError("unknown secret backend " + strconv.Quote(backend) + " (use keychain or age)")
+	}
+}
+
+// secretAccount names field's keychain item: the field plus a hash of the
+// config file's path, so configs chosen with --config don't overwrite each
+// other's items.
+func (cs *ConfigService) secretAccount(field string) string {
+	path := cs.GetConfigPath()
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	sum := sha256.Sum256([]byte(path))
+	return field + "@" + hex.EncodeToString(sum[:6])
+}
+
+// AgeIdentityPath is the age key that decrypts "enc:age:" values.
+func (cs *ConfigService) AgeIdentityPath() string {
+	return filepath.Join(filepath.Dir(cs.GetConfigPath()), ageIdentityFile)
+}
+
+// splitForOverlay returns the new base file contents and, when any of its
+// settings changed, the new overlay contents (nil otherwise). Top-level
+// settings the overlay defines keep their previous base value.
+func (cs *ConfigService) splitForOverlay(config *models.Config, configPath, localPath string, local []byte) ([]byte, []byte, error) {
+	full, err := configDocument(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	overlay, err := decodeDocument(ConfigFormatFor(localPath), local)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	base := map[string]interface{}{}
+	if data, err := cs.readFile(configPath); err == nil {
+		if base, err = decodeDocument(ConfigFormatFor(configPath), data); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	overlayChanged := false
+	for key, value := range full {
+		if prev, ok := overlay[key]; ok {
+			if !reflect.DeepEqual(prev, value) {
+				overlay[key] = value
+				overlayChanged = true
+			}
+			continue
+		}
+		base[key] = value
+	}
+	for key := range base {
+		if _, ok := full[key]; !ok {
+			if _, ok := overlay[key]; !ok {
+				delete(base, key) // cleared (e.g. omitempty field now empty)
+			}
+		}
+	}
+
+	baseData, err := encodeDocument(ConfigFormatFor(configPath), base)
+	if err != nil || !overlayChanged {
+		return baseData, nil, err
+	}
+	localData, err := encodeDocument(ConfigFormatFor(localPath), overlay)
+	return baseData, localData, err
+}
+
 // EnsureConfigDir ensures the configuration directory exists
 func (cs *ConfigService) EnsureConfigDir() error {
 	dir := filepath.Dir(cs.GetConfigPath())
@@ -110,7 +346,44 @@ func (cs *ConfigService) GetConfigPath() string {
 	if cs.configPath != "" {
 		return cs.configPath
 	}
-	return filepath.Join(xdg.ConfigHome, "cc-dailyuse-bar", "config.yaml")
+	return findConfigFile(DefaultPaths().ConfigDir())
+}
+
+// findConfigFile returns the first of config.yaml, config.toml, and
+// config.json that exists in dir, or config.yaml when none do.
+func findConfigFile(dir string) string {
+	for _, name := range configFileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(dir, configFileNames[0])
+}
+
+// LocalConfigPath returns the optional overlay that sits next to the config
+// file with ".local" before the extension (config.local.yaml for
+// config.yaml).
+func (cs *ConfigService) LocalConfigPath() string {
+	path := cs.GetConfigPath()
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + ".local" + ext
+}
+
+// ReadableByOthers lists the config file and its overlay, where they
+// exist, that users other than the owner can read. Windows permissions
+// don't map onto these bits, so it always reports none there.
+func (cs *ConfigService) ReadableByOthers() []string {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	var paths []string
+	for _, path := range []string{cs.GetConfigPath(), cs.LocalConfigPath()} {
+		if info, err := os.Stat(path); err == nil && info.Mode().Perm()&0o044 != 0 {
+			paths = append(paths, path)
+		}
+	}
+	return paths
 }
 
 // SetConfigPath sets a custom config path for testing
@@ -118,6 +391,15 @@ func (cs *ConfigService) SetConfigPath(path string) {
 	cs.configPath = path
 }
 
+// SetSecretStore replaces the store for backend, e.g. with a fake in tests
+// or age with an explicit recipient.
+func (cs *ConfigService) SetSecretStore(backend string, store SecretStore) {
+	if cs.secretStores == nil {
+		cs.secretStores = map[string]SecretStore{}
+	}
+	cs.secretStores[backend] = store
+}
+
 // SetReadFile allows tests to override the file reader logic.
 func (cs *ConfigService) SetReadFile(reader func(string) ([]byte, error)) {
 	if reader == nil {
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -29,6 +30,25 @@ func TestConfigService_GetConfigPath(t *testing.T) {
 	assert.True(t, filepath.IsAbs(path))
 }
 
+func TestConfigService_ReadableByOthers(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes don't apply on Windows")
+	}
+	svc := NewConfigService()
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	svc.SetConfigPath(configPath)
+
+	assert.Empty(t, svc.ReadableByOthers(), "missing files aren't reported")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("{}"), 0o600))
+	require.NoError(t, os.Chmod(configPath, 0o600))
+	assert.Empty(t, svc.ReadableByOthers())
+
+	require.NoError(t, os.Chmod(configPath, 0o644))
+	require.NoError(t, os.WriteFile(svc.LocalConfigPath(), []byte("{}"), 0o600))
+	assert.Equal(t, []string{configPath}, svc.ReadableByOthers())
+}
+
 func TestConfigService_LoadDefaultsWhenFileMissing(t *testing.T) {
 	svc := newTestConfigService(func(string) ([]byte, error) {
 		return nil, os.ErrNotExist
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// ledgerCatchUpDays bounds how far back a catch-up reaches after the app
+// hasn't run for a while.
+const ledgerCatchUpDays = 7
+
+// ledgerHeader is the first line of a new ledger CSV.
+var ledgerHeader = []string{"date", "cost", "tokens", "models"}
+
+// LedgerRow is one finished day in the spend ledger.
+type LedgerRow struct {
+	Date   string             `json:"date"` // YYYY-MM-DD
+	Cost   float64            `json:"cost"`
+	Tokens int                `json:"tokens"`
+	Models map[string]float64 `json:"models"` // cost per model
+}
+
+// NewLedgerRow builds the ledger row for one day of ccusage output.
+func NewLedgerRow(day CCUsageOutput) LedgerRow {
+	row := LedgerRow{Date: day.Date, Cost: day.TotalCost, Tokens: day.TotalTokens, Models: map[string]float64{}}
+	for _, model := range day.ModelBreakdowns {
+		row.Models[model.ModelName] += model.Cost
+	}
+	return row
+}
+
+// csvRecord renders the row for the CSV; models become
+// "name=cost; name=cost", sorted by name.
+func (r LedgerRow) csvRecord() []string {
+	names := make([]string, 0, len(r.Models))
+	for name := range r.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	models := make([]string, 0, len(names))
+	for _, name := range names {
+		models = append(models, name+"="+ledgerAmount(r.Models[name]))
+	}
+	return []string{r.Date, ledgerAmount(r.Cost), strconv.Itoa(r.Tokens), strings.Join(models, "; ")}
+}
+
+// ledgerAmount formats dollars without float noise, to a hundredth of a
+// cent.
+func ledgerAmount(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1e4)/1e4, 'f', -1, 64)
+}
+
+// ledgerState records the last date delivered to each destination, by
+// ledgerDestination.key, so restarts neither skip nor repeat a day and a
+// destination that replaces another starts afresh.
+type ledgerState struct {
+	Delivered map[string]string `json:"delivered,omitempty"`
+
+	// CSV, Webhook, and Telegram are the dates earlier versions kept, one
+	// per kind of destination; they stand for whichever destination of
+	// that kind is configured until it has an entry in Delivered.
+	CSV      string `json:"csv,omitempty"`
+	Webhook  string `json:"webhook,omitempty"`
+	Telegram string `json:"telegram,omitempty"`
+}
+
+// ledgerDestination is one place finished days are delivered to.
+type ledgerDestination struct {
+	key     string // e.g. "csv:/home/me/Dropbox/ledger.csv"; secrets are hashed
+	legacy  func(ledgerState) string
+	deliver func(LedgerRow) error
+}
+
+// ledgerSecretKey identifies a destination whose address is a secret, such
+// as a webhook URL, without writing the secret to the state file.
+func ledgerSecretKey(kind, secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return kind + ":" + hex.EncodeToString(sum[:8])
+}
+
+// DailyLedger keeps a spreadsheet ledger of finished days by appending a
+// row per day to a CSV file (typically in a synced folder) and/or posting
+// it as JSON to a webhook, such as a Google Apps Script web app. It can
+// also send each day's summary to Telegram.
+type DailyLedger struct {
+	csvPath    string
+	webhookURL string
+	telegram   *TelegramBot
+	telegramID string            // the chat summaries go to, for its ledgerState key
+	format     models.CostFormat // for Telegram summaries
+	statePath  string
+	client     *http.Client
+	logger     *lib.Logger
+}
+
+// NewDailyLedger creates a ledger for the given destinations; either may
+// be empty.
+func NewDailyLedger(csvPath, webhookURL string) *DailyLedger {
+	return &DailyLedger{
+		csvPath:    csvPath,
+		webhookURL: webhookURL,
+		statePath:  filepath.Join(DefaultPaths().DataDir(), "ledger-state.json"),
+		client:     &http.Client{Timeout: 10 * time.Second},
+		logger:     lib.NewLogger("daily-ledger"),
+	}
+}
+
+// SetTelegram sends each finished day's summary to bot, with costs in
+// format; a nil bot sends none.
+func (l *DailyLedger) SetTelegram(bot *TelegramBot, format models.CostFormat) {
+	l.telegram, l.format = bot, format
+	if bot != nil {
+		l.telegramID = bot.chatID
+	}
+}
+
+// Enabled reports whether any destination is configured.
+func (l *DailyLedger) Enabled() bool {
+	return l.csvPath != "" || l.webhookURL != "" || l.telegram != nil
+}
+
+// CatchUp delivers every finished day since the last one delivered, at
+// most ledgerCatchUpDays back and only yesterday on first use, fetching
+// the totals with history. Days without usage are skipped. A destination
+// that fails stops there and is retried from that day on the next call.
+func (l *DailyLedger) CatchUp(now time.Time, history func(since, until time.Time) ([]CCUsageOutput, error)) error {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	yesterday := today.AddDate(0, 0, -1)
+	state := l.loadState()
+	destinations := l.destinations()
+	delivered := make(map[string]string, len(destinations))
+	for _, dest := range destinations {
+		delivered[dest.key] = state.Delivered[dest.key]
+		if delivered[dest.key] == "" {
+			delivered[dest.key] = dest.legacy(state)
+		}
+	}
+
+	// Fetch from the earliest day any destination still needs.
+	since := yesterday
+	pending := false
+	for _, dest := range destinations {
+		last := delivered[dest.key]
+		if last >= yesterday.Format("2006-01-02") {
+			continue
+		}
+		pending = true
+		if day, err := time.ParseInLocation("2006-01-02", last, now.Location()); err == nil && day.Before(since) {
+			since = day.AddDate(0, 0, 1)
+		}
+	}
+	if !pending {
+		return nil
+	}
+	if earliest := yesterday.AddDate(0, 0, 1-ledgerCatchUpDays); since.Before(earliest) {
+		since = earliest
+	}
+
+	days, err := history(since, yesterday)
+	if err != nil {
+		return err
+	}
+	byDate := make(map[string]CCUsageOutput, len(days))
+	for _, day := range days {
+		byDate[day.Date] = day
+	}
+
+	var errs []error
+	for _, dest := range destinations {
+		last := delivered[dest.key]
+		start := since
+		if last == "" {
+			start = yesterday // a new destination starts with yesterday
+		}
+		for day := start; !day.After(yesterday); day = day.AddDate(0, 0, 1) {
+			date := day.Format("2006-01-02")
+			if last != "" && date <= last {
+				continue
+			}
+			if usage, ok := byDate[date]; ok {
+				if err := dest.deliver(NewLedgerRow(usage)); err != nil {
+					errs = append(errs, err)
+					break
+				}
+			}
+			last = date
+		}
+		delivered[dest.key] = last
+	}
+
+	// Keep other destinations' dates, in case they are configured again.
+	if state.Delivered == nil {
+		state.Delivered = map[string]string{}
+	}
+	for key, last := range delivered {
+		if last != "" {
+			state.Delivered[key] = last
+		}
+	}
+	state.CSV, state.Webhook, state.Telegram = "", "", ""
+	if err := l.saveState(state); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// destinations lists the configured destinations.
+func (l *DailyLedger) destinations() []ledgerDestination {
+	var destinations []ledgerDestination
+	if l.csvPath != "" {
+		path := l.csvPath
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		destinations = append(destinations, ledgerDestination{
+			key:     "csv:" + path,
+			legacy:  func(s ledgerState) string { return s.CSV },
+			deliver: l.appendCSV,
+		})
+	}
+	if l.webhookURL != "" {
+		destinations = append(destinations, ledgerDestination{
+			key:     ledgerSecretKey("webhook", l.webhookURL),
+			legacy:  func(s ledgerState) string { return s.Webhook },
+			deliver: l.post,
+		})
+	}
+	if l.telegram != nil {
+		destinations = append(destinations, ledgerDestination{
+			key:    "telegram:" + l.telegramID,
+			legacy: func(s ledgerState) string { return s.Telegram },
+			deliver: func(row LedgerRow) error {
+				return l.telegram.Send(TelegramSummary(row, l.format))
+			},
+		})
+	}
+	return destinations
+}
+
+// appendCSV adds row to the ledger file, writing the header first when the
+// file is new or empty.
+func (l *DailyLedger) appendCSV(row LedgerRow) error {
+	if err := os.MkdirAll(filepath.Dir(l.csvPath), 0o755); err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to create the ledger directory")
+	}
+	file, err := os.OpenFile(l.csvPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to open the ledger CSV")
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if info, err := file.Stat(); err == nil && info.Size() == 0 {
+		_ = w.Write(ledgerHeader)
+	}
+	_ = w.Write(row.csvRecord())
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to append to the ledger CSV")
+	}
+	return nil
+}
+
+// post sends row to the webhook as JSON; any non-2xx answer is a failure.
+func (l *DailyLedger) post(row LedgerRow) error {
+	body, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	resp, err := l.client.Post(l.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to post the ledger row")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return lib.SystemError(fmt.Sprintf("ledger webhook answered %s", resp.Status))
+	}
+	l.logger.Info("Posted ledger row", map[string]interface{}{
+		"date": row.Date,
+	})
+	return nil
+}
+
+func (l *DailyLedger) loadState() ledgerState {
+	var state ledgerState
+	data, err := os.ReadFile(l.statePath)
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		l.logger.Warn("Ignoring unreadable ledger state", map[string]interface{}{
+			"path":  l.statePath,
+			"error": err.Error(),
+		})
+		return ledgerState{}
+	}
+	return state
+}
+
+func (l *DailyLedger) saveState(state ledgerState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.statePath), 0o755); err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to create the ledger state directory")
+	}
+	if err := lib.WriteFileAtomic(l.statePath, data); err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to save the ledger state")
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+func newTestLedger(t *testing.T, csvPath, webhookURL string) *DailyLedger {
+	t.Helper()
+	ledger := NewDailyLedger(csvPath, webhookURL)
+	ledger.statePath = filepath.Join(t.TempDir(), "ledger-state.json")
+	return ledger
+}
+
+// fakeHistory serves days from a fixed report and records each request.
+type fakeHistory struct {
+	days  []CCUsageOutput
+	calls [][2]string
+}
+
+func (f *fakeHistory) fetch(since, until time.Time) ([]CCUsageOutput, error) {
+	f.calls = append(f.calls, [2]string{since.Format("2006-01-02"), until.Format("2006-01-02")})
+	return f.days, nil
+}
+
+// lastDelivered returns the state's date for ledger's i'th destination.
+func lastDelivered(t *testing.T, ledger *DailyLedger, i int) string {
+	t.Helper()
+	destinations := ledger.destinations()
+	require.Greater(t, len(destinations), i)
+	return ledger.loadState().Delivered[destinations[i].key]
+}
+
+func TestDailyLedger_CSV(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "sync", "claude-ledger.csv")
+	ledger := newTestLedger(t, csvPath, "")
+	history := &fakeHistory{days: []CCUsageOutput{
+		{Date: "2025-03-11", TotalCost: 1, TotalTokens: 10},
+		{Date: "2025-03-13", TotalCost: 12.3400000001, TotalTokens: 4800, ModelBreakdowns: []CCUsageModelSummary{
+			{ModelName: "claude-sonnet-4", Cost: 2.34},
+			{ModelName: "claude-opus-4", Cost: 10},
+		}},
+	}}
+	now := time.Date(2025, 3, 14, 0, 5, 0, 0, time.Local)
+
+	require.NoError(t, ledger.CatchUp(now, history.fetch))
+	data, err := os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, "date,cost,tokens,models\n2025-03-13,12.34,4800,claude-opus-4=10; claude-sonnet-4=2.34\n", string(data),
+		"the first run only appends yesterday")
+
+	require.NoError(t, ledger.CatchUp(now.Add(time.Hour), history.fetch))
+	assert.Len(t, history.calls, 1, "a day already delivered isn't fetched again")
+
+	history.days = []CCUsageOutput{{Date: "2025-03-16", TotalCost: 3, TotalTokens: 30}}
+	require.NoError(t, ledger.CatchUp(now.AddDate(0, 0, 3), history.fetch))
+	assert.Equal(t, [2]string{"2025-03-14", "2025-03-16"}, history.calls[1], "missed days are caught up")
+	data, err = os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "\n2025-03-16,3,30,\n")
+}
+
+func TestDailyLedger_CatchUpIsBounded(t *testing.T) {
+	ledger := newTestLedger(t, filepath.Join(t.TempDir(), "ledger.csv"), "")
+	require.NoError(t, ledger.saveState(ledgerState{CSV: "2025-01-01"}), "an earlier version's state")
+	history := &fakeHistory{}
+
+	require.NoError(t, ledger.CatchUp(time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local), history.fetch))
+	assert.Equal(t, [2]string{"2025-03-07", "2025-03-13"}, history.calls[0])
+	assert.Equal(t, "2025-03-13", lastDelivered(t, ledger, 0), "days without usage still count as done")
+	assert.Empty(t, ledger.loadState().CSV)
+}
+
+func TestDailyLedger_StateByDestination(t *testing.T) {
+	dir := t.TempDir()
+	ledger := newTestLedger(t, filepath.Join(dir, "a.csv"), "")
+	history := &fakeHistory{days: []CCUsageOutput{{Date: "2025-03-13", TotalCost: 1, TotalTokens: 10}}}
+	now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)
+	require.NoError(t, ledger.CatchUp(now, history.fetch))
+
+	moved := NewDailyLedger(filepath.Join(dir, "b.csv"), "")
+	moved.statePath = ledger.statePath
+	require.NoError(t, moved.CatchUp(now, history.fetch))
+	data, err := os.ReadFile(filepath.Join(dir, "b.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "2025-03-13,1,10,", "a new ledger file gets yesterday too")
+	assert.Equal(t, "2025-03-13", lastDelivered(t, ledger, 0), "the old file's date is kept")
+
+	webhook := NewDailyLedger("", "https://example.com/secret-hook")
+	assert.NotContains(t, webhook.destinations()[0].key, "secret-hook", "webhook URLs stay out of the state file")
+}
+
+func TestDailyLedger_Webhook(t *testing.T) {
+	var mu sync.Mutex
+	var rows []LedgerRow
+	fail := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		if fail {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var row LedgerRow
+		assert.NoError(t, json.Unmarshal(body, &row))
+		rows = append(rows, row)
+	}))
+	t.Cleanup(server.Close)
+
+	ledger := newTestLedger(t, "", server.URL)
+	history := &fakeHistory{days: []CCUsageOutput{{Date: "2025-03-13", TotalCost: 2.5, TotalTokens: 25,
+		ModelBreakdowns: []CCUsageModelSummary{{ModelName: "claude-opus-4", Cost: 2.5}}}}}
+	now := time.Date(2025, 3, 14, 0, 5, 0, 0, time.Local)
+
+	assert.ErrorContains(t, ledger.CatchUp(now, history.fetch), "502")
+	assert.Empty(t, lastDelivered(t, ledger, 0), "a failed post is retried")
+
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+	require.NoError(t, ledger.CatchUp(now, history.fetch))
+	assert.Equal(t, []LedgerRow{{Date: "2025-03-13", Cost: 2.5, Tokens: 25, Models: map[string]float64{"claude-opus-4": 2.5}}}, rows)
+}
+
+func TestDailyLedger_Telegram(t *testing.T) {
+	fake := newFakeTelegram(t)
+	ledger := newTestLedger(t, "", "")
+	assert.False(t, ledger.Enabled())
+	ledger.SetTelegram(NewTelegramBot(testTelegramToken, "1"), models.ConfigDefaults().CostFormat())
+	assert.True(t, ledger.Enabled())
+
+	history := &fakeHistory{days: []CCUsageOutput{{Date: "2025-03-13", TotalCost: 2.5, TotalTokens: 25}}}
+	require.NoError(t, ledger.CatchUp(time.Date(2025, 3, 14, 0, 5, 0, 0, time.Local), history.fetch))
+	require.Len(t, fake.messages, 1)
+	assert.Equal(t, "📊 Claude Code, Thu 2025-03-13: $2.50, 25 tokens", fake.messages[0]["text"])
+	assert.Equal(t, "2025-03-13", lastDelivered(t, ledger, 0))
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DataDirOverlap describes Claude usage data found in both the new and the
+// legacy default locations. ccusage reads every directory it finds, so
+// sessions copied or synced into both are counted twice.
+type DataDirOverlap struct {
+	Dirs        []string // Claude config directories holding usage JSONL
+	SharedFiles int      // JSONL files present at the same relative path in more than one
+}
+
+// Warning is a one-line explanation with the fix.
+func (o *DataDirOverlap) Warning() string {
+	msg := fmt.Sprintf("Claude usage data found in %s", strings.Join(o.Dirs, " and "))
+	if o.SharedFiles > 0 {
+		msg += fmt.Sprintf(" (%d session files in both)", o.SharedFiles)
+	}
+	return msg + "; ccusage may double count. Set claude_data_dir to the one Claude Code uses."
+}
+
+// DetectDataDirOverlap reports whether ccusage will read usage data from
+// more than one default Claude directory. It returns nil when a single
+// directory is chosen, either by claudeDataDir (the claude_data_dir
+// setting) or by CLAUDE_CONFIG_DIR, since the user has already picked.
+func DetectDataDirOverlap(claudeDataDir string) *DataDirOverlap {
+	if claudeDataDir != "" || os.Getenv("CLAUDE_CONFIG_DIR") != "" {
+		return nil
+	}
+	return detectDataDirOverlap(defaultClaudeConfigDirs())
+}
+
+func detectDataDirOverlap(configDirs []string) *DataDirOverlap {
+	var withData []string
+	visited := map[string]bool{}
+	counts := map[string]int{}
+	for _, dir := range configDirs {
+		// ~/.claude is often a symlink to ~/.config/claude; that's one
+		// directory, not two.
+		real, err := filepath.EvalSymlinks(dir)
+		if err != nil || visited[real] {
+			continue
+		}
+		visited[real] = true
+
+		files := usageFiles(filepath.Join(dir, "projects"))
+		if len(files) == 0 {
+			continue
+		}
+		withData = append(withData, dir)
+		for _, rel := range files {
+			counts[rel]++
+		}
+	}
+	if len(withData) < 2 {
+		return nil
+	}
+
+	overlap := &DataDirOverlap{Dirs: withData}
+	for _, n := range counts {
+		if n > 1 {
+			overlap.SharedFiles++
+		}
+	}
+	return overlap
+}
+
+// usageFiles lists the JSONL files under root, relative to it. Unreadable
+// entries are skipped.
+func usageFiles(root string) []string {
+	var files []string
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), ".jsonl") {
+			if rel, err := filepath.Rel(root, path); err == nil {
+				files = append(files, rel)
+			}
+		}
+		return nil
+	})
+	return files
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSession(t *testing.T, configDir, rel string) {
+	t.Helper()
+	path := filepath.Join(configDir, "projects", rel)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte("{}\n"), 0o644))
+}
+
+func TestDetectDataDirOverlap(t *testing.T) {
+	root := t.TempDir()
+	current := filepath.Join(root, "config-claude")
+	legacy := filepath.Join(root, "claude")
+	empty := filepath.Join(root, "empty")
+	require.NoError(t, os.MkdirAll(filepath.Join(empty, "projects"), 0o755))
+
+	writeSession(t, current, filepath.Join("proj", "a.jsonl"))
+	writeSession(t, current, filepath.Join("proj", "b.jsonl"))
+	assert.Nil(t, detectDataDirOverlap([]string{current, empty, filepath.Join(root, "missing")}),
+		"one directory with data is not an overlap")
+
+	writeSession(t, legacy, filepath.Join("proj", "a.jsonl"))
+	writeSession(t, legacy, filepath.Join("other", "c.jsonl"))
+	writeSession(t, legacy, filepath.Join("other", "notes.txt"))
+	overlap := detectDataDirOverlap([]string{current, legacy})
+	require.NotNil(t, overlap)
+	assert.Equal(t, []string{current, legacy}, overlap.Dirs)
+	assert.Equal(t, 1, overlap.SharedFiles)
+	assert.Contains(t, overlap.Warning(), "1 session files in both")
+	assert.Contains(t, overlap.Warning(), "claude_data_dir")
+}
+
+func TestDetectDataDirOverlap_Symlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on Windows")
+	}
+	root := t.TempDir()
+	current := filepath.Join(root, "config-claude")
+	legacy := filepath.Join(root, "claude")
+	writeSession(t, current, filepath.Join("proj", "a.jsonl"))
+	require.NoError(t, os.Symlink(current, legacy))
+
+	assert.Nil(t, detectDataDirOverlap([]string{current, legacy}), "a symlinked directory is read once")
+}
+
+func TestDetectDataDirOverlap_Chosen(t *testing.T) {
+	assert.Nil(t, DetectDataDirOverlap("/somewhere"), "claude_data_dir picks one directory")
+
+	t.Setenv("CLAUDE_CONFIG_DIR", "/a")
+	assert.Nil(t, DetectDataDirOverlap(""), "CLAUDE_CONFIG_DIR picks the directories")
+}
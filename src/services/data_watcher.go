@@ -0,0 +1,191 @@
+package services
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"cc-dailyuse-bar/src/lib"
+)
+
+// defaultWatchDebounce coalesces bursts of JSONL appends (Claude Code writes
+// several lines per response) into a single refresh.
+const defaultWatchDebounce = 2 * time.Second
+
+// dataWatcher watches the Claude data directories and invokes onChange,
+// debounced, whenever a usage JSONL file is written. fsnotify is not
+// recursive, so every existing subdirectory is added up front and newly
+// created ones are added as they appear.
+type dataWatcher struct {
+	watcher  *fsnotify.Watcher
+	debounce time.Duration
+	onChange func()
+	logger   *lib.Logger
+
+	mutex sync.Mutex
+	timer *time.Timer
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newDataWatcher(dirs []string, debounce time.Duration, onChange func()) (*dataWatcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeSystem, "failed to create file watcher")
+	}
+
+	dw := &dataWatcher{
+		watcher:  fsw,
+		debounce: debounce,
+		onChange: onChange,
+		logger:   lib.NewLogger("data-watcher"),
+		done:     make(chan struct{}),
+	}
+
+	watched := 0
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		watched += dw.addTree(dir)
+	}
+	if watched == 0 {
+		_ = fsw.Close()
+		return nil, lib.NewError(lib.ErrCodeSystem, "no Claude data directories found to watch")
+	}
+
+	dw.wg.Add(1)
+	go dw.loop()
+
+	dw.logger.Info("Watching Claude data directories", map[string]interface{}{
+		"dirs":        dirs,
+		"watchedDirs": watched,
+	})
+	return dw, nil
+}
+
+// addTree registers root and all of its subdirectories, returning how many
+// were added successfully.
+func (dw *dataWatcher) addTree(root string) int {
+	added := 0
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if err := dw.watcher.Add(path); err != nil {
+			dw.logger.Warn("Failed to watch directory", map[string]interface{}{
+				"path":  path,
+				"error": err.Error(),
+			})
+			return nil
+		}
+		added++
+		return nil
+	})
+	return added
+}
+
+func (dw *dataWatcher) loop() {
+	defer dw.wg.Done()
+
+	for {
+		select {
+		case event, ok := <-dw.watcher.Events:
+			if !ok {
+				return
+			}
+			dw.handleEvent(event)
+
+		case err, ok := <-dw.watcher.Errors:
+			if !ok {
+				return
+			}
+			dw.logger.Warn("File watcher error", map[string]interface{}{
+				"error": err.Error(),
+			})
+
+		case <-dw.done:
+			return
+		}
+	}
+}
+
+func (dw *dataWatcher) handleEvent(event fsnotify.Event) {
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			// New project directory: watch it and anything already inside.
+			dw.addTree(event.Name)
+			dw.schedule()
+			return
+		}
+	}
+
+	if !strings.HasSuffix(event.Name, ".jsonl") {
+		return
+	}
+	if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Rename) {
+		dw.schedule()
+	}
+}
+
+// schedule (re)arms the debounce timer so onChange runs once the burst of
+// writes has settled.
+func (dw *dataWatcher) schedule() {
+	dw.mutex.Lock()
+	defer dw.mutex.Unlock()
+
+	select {
+	case <-dw.done:
+		return
+	default:
+	}
+
+	if dw.timer != nil {
+		dw.timer.Stop()
+	}
+	dw.timer = time.AfterFunc(dw.debounce, dw.fire)
+}
+
+// fire runs onChange when the debounce timer goes off, unless Close ran
+// first: stopping the timer can't recall a callback already started. Close
+// waits for a running onChange, so none runs after it returns.
+func (dw *dataWatcher) fire() {
+	dw.mutex.Lock()
+	select {
+	case <-dw.done:
+		dw.mutex.Unlock()
+		return
+	default:
+	}
+	dw.wg.Add(1)
+	dw.mutex.Unlock()
+
+	defer dw.wg.Done()
+	dw.onChange()
+}
+
+// Close stops watching and cancels any pending debounced refresh, waiting
+// for one already running.
+func (dw *dataWatcher) Close() {
+	dw.mutex.Lock()
+	select {
+	case <-dw.done:
+		dw.mutex.Unlock()
+		return
+	default:
+	}
+	close(dw.done)
+	if dw.timer != nil {
+		dw.timer.Stop()
+	}
+	dw.mutex.Unlock()
+
+	_ = dw.watcher.Close()
+	dw.wg.Wait()
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+func TestNewDataWatcher_NoDirectories(t *testing.T) {
+	_, err := newDataWatcher([]string{filepath.Join(t.TempDir(), "missing")}, time.Millisecond, func() {})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no Claude data directories")
+}
+
+func TestDataWatcher_DebouncesJSONLWrites(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "proj")
+	require.NoError(t, os.MkdirAll(project, 0o755))
+
+	var calls atomic.Int32
+	dw, err := newDataWatcher([]string{root}, 100*time.Millisecond, func() { calls.Add(1) })
+	require.NoError(t, err)
+	defer dw.Close()
+
+	file := filepath.Join(project, "session.jsonl")
+	for i := 0; i < 3; i++ {
+		require.NoError(t, os.WriteFile(file, []byte("{}\n"), 0o644))
+	}
+
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, 2*time.Second, 20*time.Millisecond)
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load(), "a burst of writes should trigger one refresh")
+
+	// Non-JSONL files are ignored.
+	require.NoError(t, os.WriteFile(filepath.Join(project, "notes.txt"), []byte("x"), 0o644))
+	time.Sleep(250 * time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestDataWatcher_WatchesNewSubdirectories(t *testing.T) {
+	root := t.TempDir()
+
+	var calls atomic.Int32
+	dw, err := newDataWatcher([]string{root}, 50*time.Millisecond, func() { calls.Add(1) })
+	require.NoError(t, err)
+	defer dw.Close()
+
+	project := filepath.Join(root, "new-proj")
+	require.NoError(t, os.MkdirAll(project, 0o755))
+	assert.Eventually(t, func() bool { return calls.Load() >= 1 }, 2*time.Second, 20*time.Millisecond)
+
+	before := calls.Load()
+	require.NoError(t, os.WriteFile(filepath.Join(project, "session.jsonl"), []byte("{}\n"), 0o644))
+	assert.Eventually(t, func() bool { return calls.Load() > before }, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestDataWatcher_CloseIsIdempotent(t *testing.T) {
+	dw, err := newDataWatcher([]string{t.TempDir()}, time.Millisecond, func() {})
+	require.NoError(t, err)
+	dw.Close()
+	dw.Close()
+}
+
+func TestUsageService_WatcherTriggersRefresh(t *testing.T) {
+	service := newTestUsageService()
+	service.ccusagePath = "/non/existent/path"
+	service.watchDataDirs = true
+	service.dataDirs = []string{t.TempDir()}
+
+	var calls atomic.Int32
+	require.NoError(t, service.StartPolling(300, func(*models.UsageState) { calls.Add(1) }))
+	defer service.StopPolling()
+
+	service.mutex.RLock()
+	require.NotNil(t, service.watcher)
+	service.mutex.RUnlock()
+
+	require.NoError(t, os.WriteFile(filepath.Join(service.dataDirs[0], "s.jsonl"), []byte("{}\n"), 0o644))
+	assert.Eventually(t, func() bool { return calls.Load() >= 1 }, 5*time.Second, 50*time.Millisecond)
+
+	service.StopPolling()
+	service.mutex.RLock()
+	assert.Nil(t, service.watcher)
+	service.mutex.RUnlock()
+}
+
+func TestDataWatcher_NoRefreshAfterClose(t *testing.T) {
+	var calls atomic.Int32
+	dw, err := newDataWatcher([]string{t.TempDir()}, time.Millisecond, func() { calls.Add(1) })
+	require.NoError(t, err)
+	dw.Close()
+
+	dw.fire() // a timer that went off just before Close stopped it
+	assert.Zero(t, calls.Load())
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+// demoSteps is how many polls one Green→Yellow→Red cycle takes.
+const demoSteps = 12
+
+// DemoFeed generates synthetic `ccusage daily --json` reports that ramp
+// today's cost from zero to past the red threshold, then start over. It
+// stands in for ccusage in demo mode so screenshots and development don't
+// need a Claude account.
+type DemoFeed struct {
+	mutex sync.Mutex
+	step  int
+	peak  float64
+}
+
+// NewDemoFeed creates a feed whose cycle peaks a little above the highest
+// configured threshold, so every status is shown along the way.
+func NewDemoFeed(config *models.Config) *DemoFeed {
+	top := config.RedThreshold
+	for _, level := range config.AlertLevels {
+		top = math.Max(top, level.Threshold)
+	}
+	if top <= 0 {
+		top = models.ConfigDefaults().RedThreshold
+	}
+	return &DemoFeed{peak: top * 1.25}
+}
+
+// Next returns the report for the next step of the cycle, dated around now.
+func (d *DemoFeed) Next(now time.Time) []byte {
+	d.mutex.Lock()
+	step := d.step
+	d.step = (d.step + 1) % (demoSteps + 1)
+	d.mutex.Unlock()
+
+	cost := math.Round(d.peak*float64(step)/demoSteps*100) / 100
+	day := func(offset int) string { return now.AddDate(0, 0, offset).Format("2006-01-02") }
+	report := CCUsageResponse{Daily: []CCUsageOutput{
+		{Date: day(-7), TotalTokens: 410_000, TotalCost: math.Round(d.peak*0.6*100) / 100},
+		{Date: day(-1), TotalTokens: 520_000, TotalCost: math.Round(d.peak*0.5*100) / 100},
+		{Date: day(0), TotalTokens: int(cost * 40_000), TotalCost: cost},
+	}}
+	data, _ := json.Marshal(report) // plain structs; cannot fail
+	return data
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+func TestDemoFeed_CyclesThroughStatuses(t *testing.T) {
+	config := models.ConfigDefaults()
+	service := newTestUsageService()
+	service.SetClock(fixedClock{now: time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)})
+	service.ccusagePath = "/nonexistent/ccusage" // never run in demo mode
+	service.SetDemoFeed(NewDemoFeed(config))
+
+	seen := map[models.AlertStatus]bool{}
+	var costs []float64
+	for i := 0; i <= demoSteps; i++ {
+		state, err := service.UpdateUsage()
+		if i == 0 {
+			assert.Error(t, err, "the cycle starts at zero, which reads as no usage yet")
+		} else {
+			require.NoError(t, err)
+		}
+		assert.True(t, state.Demo)
+		seen[state.Status] = true
+		costs = append(costs, state.DailyCost)
+		if i == demoSteps {
+			require.NotNil(t, state.Yesterday)
+			assert.Equal(t, 12.5, state.Yesterday.Cost)
+		}
+	}
+
+	assert.True(t, seen[models.Green] && seen[models.Yellow] && seen[models.Red])
+	assert.Equal(t, 25.0, costs[demoSteps], "peaks 25% above red")
+	state, _ := service.UpdateUsage()
+	assert.Zero(t, state.DailyCost, "wraps around after the peak")
+
+	service.SetDemoFeed(nil)
+	assert.False(t, service.state.Demo)
+}
+
+func TestNewDemoFeed_UsesHighestAlertLevel(t *testing.T) {
+	config := models.ConfigDefaults()
+	config.AlertLevels = []models.AlertLevel{{Name: "max", Threshold: 40, Status: models.Red}}
+	assert.Equal(t, 50.0, NewDemoFeed(config).peak)
+}
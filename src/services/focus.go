@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FocusDetector reports whether the desktop is in Do Not Disturb or a
+// Focus mode, during which notifications are held back.
+type FocusDetector interface {
+	FocusActive() (bool, error)
+}
+
+// defaultFocusDetector is the detector new NotificationServices use; tests
+// replace it so the developer's own Do Not Disturb can't affect them.
+var defaultFocusDetector FocusDetector = &cachedFocusDetector{detector: systemFocusDetector{}}
+
+// focusCacheTTL is how long cachedFocusDetector reuses an answer.
+const focusCacheTTL = 15 * time.Second
+
+// cachedFocusDetector remembers detector's answer for focusCacheTTL, so
+// a burst of alerts runs gsettings or defaults once rather than per alert.
+type cachedFocusDetector struct {
+	detector FocusDetector
+	mu       sync.Mutex // held while asking, so callers share one query
+	checked  time.Time
+	active   bool
+	err      error
+}
+
+func (c *cachedFocusDetector) FocusActive() (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if now := time.Now(); c.checked.IsZero() || now.Sub(c.checked) >= focusCacheTTL {
+		c.active, c.err = c.detector.FocusActive()
+		c.checked = now
+	}
+	return c.active, c.err
+}
+
+// systemFocusDetector reads the platform's Do Not Disturb state: the Focus
+// assertions file on macOS 12 and later, the notificationcenterui default
+// before that, and GNOME's show-banners setting on Linux. Elsewhere focus
+// is never active.
+type systemFocusDetector struct{}
+
+func (systemFocusDetector) FocusActive() (bool, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return false, err
+		}
+		active, err := focusAssertionsActive(filepath.Join(home, "Library", "DoNotDisturb", "DB", "Assertions.json"))
+		if !errors.Is(err, os.ErrNotExist) {
+			return active, err
+		}
+		// macOS 11 and earlier keep Do Not Disturb in a default.
+		out, err := focusCommand("defaults", "-currentHost", "read", "com.apple.notificationcenterui", "doNotDisturb")
+		if err != nil {
+			return false, nil // unset until Do Not Disturb is first used
+		}
+		return out == "1", nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		out, err := focusCommand("gsettings", "get", "org.gnome.desktop.notifications", "show-banners")
+		if err != nil {
+			return false, nil // not GNOME
+		}
+		return out == "false", nil
+	default:
+		return false, nil
+	}
+}
+
+// focusCommand runs a short query and returns its trimmed output.
+func focusCommand(name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// focusAssertions is the part of macOS's Assertions.json that lists the
+// Focus modes turned on by hand or from Control Center.
+type focusAssertions struct {
+	Data []struct {
+		StoreAssertionRecords []json.RawMessage `json:"storeAssertionRecords"`
+	} `json:"data"`
+}
+
+// focusAssertionsActive reports whether the Assertions.json at path has
+// any Focus mode turned on. Reading it needs Full Disk Access on recent
+// macOS versions; scheduled Focus modes aren't recorded there.
+func focusAssertionsActive(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	var assertions focusAssertions
+	if err := json.Unmarshal(data, &assertions); err != nil {
+		return false, err
+	}
+	for _, entry := range assertions.Data {
+		if len(entry.StoreAssertionRecords) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
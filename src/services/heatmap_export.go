@@ -0,0 +1,158 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// Heat map export formats.
+const (
+	HeatMapCSV  = "csv"
+	HeatMapHTML = "html"
+)
+
+// DefaultHeatMapDays is the range a heat map covers unless told otherwise:
+// four whole weeks, so every weekday is counted the same number of times.
+const DefaultHeatMapDays = 28
+
+// ExportHeatMap renders h in format to heatmap.<format> in the exports
+// folder under the XDG data directory, where the tray's Export submenu
+// puts it, and returns the file's path.
+func ExportHeatMap(h models.UsageHeatMap, format string, costFormat models.CostFormat) (string, error) {
+	data, err := RenderHeatMap(h, format, costFormat)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(DefaultPaths().DataDir(), "exports")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", lib.WrapError(err, lib.ErrCodeSystem, "failed to create the exports directory")
+	}
+	path := filepath.Join(dir, "heatmap."+format)
+	if err := lib.WriteFileAtomic(path, data); err != nil {
+		return "", lib.WrapError(err, lib.ErrCodeSystem, "failed to write heat map")
+	}
+	return path, nil
+}
+
+// RenderHeatMap renders h as a weekday × hour grid of tokens: a CSV with a
+// row per weekday and a column per hour, or a self-contained HTML page
+// shading each cell by its share of the busiest one.
+func RenderHeatMap(h models.UsageHeatMap, format string, costFormat models.CostFormat) ([]byte, error) {
+	switch format {
+	case HeatMapCSV:
+		return renderHeatMapCSV(h)
+	case HeatMapHTML:
+		return renderHeatMapHTML(h, costFormat)
+	default:
+		return nil, lib.ValidationError(fmt.Sprintf("unknown heat map format %q (want %s or %s)", format, HeatMapCSV, HeatMapHTML))
+	}
+}
+
+func renderHeatMapCSV(h models.UsageHeatMap) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := []string{"weekday"}
+	for hour := 0; hour < 24; hour++ {
+		header = append(header, fmt.Sprintf("%02d", hour))
+	}
+	_ = w.Write(header)
+	for _, day := range models.HeatMapWeekdays {
+		record := []string{day.String()}
+		for _, cell := range h.Cells[day] {
+			record = append(record, strconv.Itoa(cell.Tokens))
+		}
+		_ = w.Write(record)
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// heatMapPage is the data heatMapTemplate renders.
+type heatMapPage struct {
+	Since, Until string
+	Hours        []string
+	Rows         []heatMapRow
+	Peak         string // empty when nothing was used
+	Untracked    string // empty when all usage was placed
+}
+
+type heatMapRow struct {
+	Day   string
+	Cells []heatMapCell
+}
+
+type heatMapCell struct {
+	Title string
+	Alpha string // background opacity, 0 to 1
+}
+
+var heatMapTemplate = template.Must(template.New("heatmap").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Claude Code usage by hour</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; }
+th { font-weight: normal; font-size: 0.8em; color: #666; padding: 2px 4px; }
+td { width: 1.8em; height: 1.8em; border: 1px solid #eee; }
+</style>
+</head>
+<body>
+<h1>Claude Code usage by hour</h1>
+<p>Tokens per weekday and hour, {{.Since}} to {{.Until}}.{{if .Peak}} Busiest: {{.Peak}}.{{end}}</p>
+<table>
+<tr><th></th>{{range .Hours}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr><th>{{.Day}}</th>{{range .Cells}}<td title="{{.Title}}" style="background: rgba(204, 85, 0, {{.Alpha}})"></td>{{end}}</tr>
+{{end}}</table>
+{{if .Untracked}}<p>{{.Untracked}} could not be placed because the app wasn't running when it was used.</p>
+{{end}}<p><small>Generated by cc-dailyuse-bar from its usage history.</small></p>
+</body>
+</html>
+`))
+
+func renderHeatMapHTML(h models.UsageHeatMap, costFormat models.CostFormat) ([]byte, error) {
+	page := heatMapPage{
+		Since: h.Since.Format("2006-01-02"),
+		Until: h.Until.Format("2006-01-02"),
+	}
+	for hour := 0; hour < 24; hour++ {
+		page.Hours = append(page.Hours, fmt.Sprintf("%02d", hour))
+	}
+	busiest := h.MaxTokens()
+	for _, day := range models.HeatMapWeekdays {
+		row := heatMapRow{Day: day.String()[:3]}
+		for hour, cell := range h.Cells[day] {
+			alpha := 0.0
+			if busiest > 0 {
+				alpha = float64(cell.Tokens) / float64(busiest)
+			}
+			row.Cells = append(row.Cells, heatMapCell{
+				Title: fmt.Sprintf("%s %02d:00: %s tokens, %s", day.String()[:3], hour,
+					models.FormatTokens(cell.Tokens), costFormat.Format(cell.Cost)),
+				Alpha: strconv.FormatFloat(alpha, 'f', 2, 64),
+			})
+		}
+		page.Rows = append(page.Rows, row)
+	}
+	if day, hour, ok := h.Peak(); ok {
+		page.Peak = fmt.Sprintf("%s %02d:00 (%s tokens)", day, hour, models.FormatTokens(h.Cells[day][hour].Tokens))
+	}
+	if h.Untracked.Tokens > 0 {
+		page.Untracked = models.FormatTokens(h.Untracked.Tokens) + " tokens"
+	}
+
+	var buf bytes.Buffer
+	if err := heatMapTemplate.Execute(&buf, page); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+func testHeatMap() models.UsageHeatMap {
+	h := models.UsageHeatMap{
+		Since: time.Date(2025, 2, 15, 0, 0, 0, 0, time.Local),
+		Until: time.Date(2025, 3, 14, 0, 0, 0, 0, time.Local),
+	}
+	h.Cells[time.Monday][9] = models.HeatMapCell{Tokens: 2000, Cost: 4}
+	h.Cells[time.Sunday][23] = models.HeatMapCell{Tokens: 500, Cost: 1.5}
+	return h
+}
+
+func TestRenderHeatMap_CSV(t *testing.T) {
+	data, err := RenderHeatMap(testHeatMap(), HeatMapCSV, models.DefaultCostFormat())
+	require.NoError(t, err)
+
+	records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 8)
+	assert.Len(t, records[0], 25)
+	assert.Equal(t, []string{"weekday", "00", "01"}, records[0][:3])
+	assert.Equal(t, "Monday", records[1][0])
+	assert.Equal(t, "2000", records[1][10])
+	assert.Equal(t, "Sunday", records[7][0])
+	assert.Equal(t, "500", records[7][24])
+	assert.Equal(t, "0", records[3][5])
+}
+
+func TestRenderHeatMap_HTML(t *testing.T) {
+	data, err := RenderHeatMap(testHeatMap(), HeatMapHTML, models.DefaultCostFormat())
+	require.NoError(t, err)
+	page := string(data)
+
+	assert.Contains(t, page, "2025-02-15 to 2025-03-14")
+	assert.Contains(t, page, "Busiest: Monday 09:00 (2K tokens)")
+	assert.Contains(t, page, `title="Mon 09:00: 2K tokens, $4.00" style="background: rgba(204, 85, 0, 1.00)"`)
+	assert.Contains(t, page, `title="Sun 23:00: 500 tokens, $1.50" style="background: rgba(204, 85, 0, 0.25)"`)
+	assert.NotContains(t, page, "could not be placed")
+}
+
+func TestRenderHeatMap_UnknownFormat(t *testing.T) {
+	_, err := RenderHeatMap(testHeatMap(), "png", models.DefaultCostFormat())
+	assert.Error(t, err)
+}
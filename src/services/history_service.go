@@ -0,0 +1,353 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+const (
+	// defaultHistoryRetention is how long samples are kept on disk.
+	defaultHistoryRetention = 90 * 24 * time.Hour
+	// historyHeartbeat re-records an unchanged value so gaps in the file
+	// mean the app wasn't running (see models.MaxSampleGap).
+	historyHeartbeat = 30 * time.Minute
+)
+
+var errNewerHistory = errors.New("usage history was written by a newer version of cc-dailyuse-bar")
+
+// HistoryService keeps a local JSONL log of usage samples under the XDG data
+// directory, one line per observation, so views like the hourly histogram
+// can look back beyond what ccusage reports.
+type HistoryService struct {
+	path      string
+	retention time.Duration
+	clock     Clock
+	logger    *lib.Logger
+	mutex     sync.Mutex
+	loaded    bool
+	last      *models.UsageSample
+	prunedOn  string // date of the last prune, YYYY-MM-DD
+	newer     bool   // the file has lines from a newer build; never rewrite it
+
+	// recent caches the samples since recentSince, the start of today less
+	// models.MaxSampleGap, so the views redrawn on every update don't
+	// re-read the whole file. It is nil until first read and dropped
+	// whenever the file is rewritten.
+	recent      []models.UsageSample
+	recentSince time.Time
+
+	// streak caches UnderBudgetStreak on streakDay (YYYY-MM-DD): it
+	// counts only finished days, which today's samples can't change.
+	// streakDay is "" until counted and whenever the file is rewritten.
+	streak    int
+	streakDay string
+
+	pricing       pricingState // see ObservePricing
+	pricingLoaded bool
+}
+
+// NewHistoryService creates a HistoryService at the default location.
+func NewHistoryService() *HistoryService {
+	return NewHistoryServiceAt(filepath.Join(DefaultPaths().DataDir(), "history.jsonl"))
+}
+
+// NewHistoryServiceAt creates a HistoryService backed by path.
+func NewHistoryServiceAt(path string) *HistoryService {
+	return &HistoryService{
+		path:      path,
+		retention: defaultHistoryRetention,
+		clock:     systemClock{},
+		logger:    lib.NewLogger("history-service"),
+	}
+}
+
+// SetClock overrides the time source, primarily for tests.
+func (hs *HistoryService) SetClock(clock Clock) {
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+	if clock == nil {
+		clock = systemClock{}
+	}
+	hs.clock = clock
+}
+
+// Record appends a sample for an available state. Unchanged values and
+// status are skipped unless historyHeartbeat has passed since the last
+// sample.
+func (hs *HistoryService) Record(state *models.UsageState) error {
+	if state == nil || !state.IsAvailable {
+		return nil
+	}
+
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+
+	hs.loadLastLocked()
+	hs.loadPricingLocked()
+	now := hs.clock.Now()
+	sample := models.UsageSample{
+		Version: models.UsageSampleVersion,
+		Time:    now,
+		Cost:    state.DailyCost,
+		Tokens:  state.DailyCount,
+		Status:  state.Status.ColorName(),
+		Pricing: hs.pricing.Version,
+	}
+
+	if last := hs.last; last != nil && sameDay(last.Time, now) &&
+		last.Cost == sample.Cost && last.Tokens == sample.Tokens && last.Status == sample.Status &&
+		last.Pricing == sample.Pricing && now.Sub(last.Time) < historyHeartbeat {
+		return nil
+	}
+
+	if today := now.Format("2006-01-02"); hs.prunedOn != today {
+		if err := hs.pruneLocked(now); err != nil {
+			hs.logger.Warn("Failed to prune usage history", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		hs.prunedOn = today
+	}
+
+	if err := hs.appendLocked(sample); err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to record usage history")
+	}
+	hs.last = &sample
+	if hs.streakDay != "" && sample.Time.Format("2006-01-02") != hs.streakDay {
+		hs.streakDay = "" // the clock moved; count again
+	}
+	if hs.recent != nil && !sample.Time.Before(hs.recentSince) {
+		hs.recent = append(hs.recent, sample)
+	}
+	return nil
+}
+
+// Samples returns every sample recorded at or after since, in time order.
+// Since today's are cached, only looking further back reads the file.
+func (hs *HistoryService) Samples(since time.Time) ([]models.UsageSample, error) {
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+	return hs.samplesLocked(since)
+}
+
+func (hs *HistoryService) samplesLocked(since time.Time) ([]models.UsageSample, error) {
+	cutoff := recentCutoff(hs.clock.Now())
+	if hs.recent != nil && cutoff.After(hs.recentSince) {
+		// A new day: yesterday's samples are no longer needed.
+		hs.recent = append([]models.UsageSample{}, samplesSince(hs.recent, cutoff)...)
+		hs.recentSince = cutoff
+	}
+	if hs.recent != nil && !since.Before(hs.recentSince) {
+		return slices.Clone(samplesSince(hs.recent, since)), nil
+	}
+
+	all, err := hs.readLocked()
+	if err != nil {
+		return nil, err
+	}
+	hs.recent = append([]models.UsageSample{}, samplesSince(all, cutoff)...)
+	hs.recentSince = cutoff
+	return samplesSince(all, since), nil
+}
+
+// recentCutoff is where HistoryService.recent starts on now's day.
+func recentCutoff(now time.Time) time.Time {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return midnight.Add(-models.MaxSampleGap)
+}
+
+// samplesSince returns the tail of samples, which are in time order, at
+// or after since.
+func samplesSince(samples []models.UsageSample, since time.Time) []models.UsageSample {
+	for i, s := range samples {
+		if !s.Time.Before(since) {
+			return samples[i:]
+		}
+	}
+	return nil
+}
+
+// HourlyHistogram builds today's hourly spend from recorded samples,
+// including the last hour of yesterday as a baseline.
+func (hs *HistoryService) HourlyHistogram() (models.HourlyHistogram, error) {
+	now := hs.now()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	samples, err := hs.Samples(midnight.Add(-models.MaxSampleGap))
+	if err != nil {
+		return models.HourlyHistogram{}, err
+	}
+	return models.BuildHourlyHistogram(samples, now), nil
+}
+
+// HeatMap builds the usage heat map of the last days days, ending today,
+// from recorded samples.
+func (hs *HistoryService) HeatMap(days int) (models.UsageHeatMap, error) {
+	now := hs.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	since := today.AddDate(0, 0, 1-days)
+	samples, err := hs.Samples(since.Add(-models.MaxSampleGap))
+	if err != nil {
+		return models.UsageHeatMap{}, err
+	}
+	return models.BuildHeatMap(samples, since, today), nil
+}
+
+// StatusChangesToday lists today's recorded status transitions in time
+// order.
+func (hs *HistoryService) StatusChangesToday() ([]models.StatusChange, error) {
+	now := hs.now()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	samples, err := hs.Samples(midnight)
+	if err != nil {
+		return nil, err
+	}
+	return models.BuildStatusChanges(samples, now), nil
+}
+
+// UnderBudgetStreak counts the consecutive days before today that ended
+// under budget, as far back as the history goes. The whole file is read
+// once a day; later calls that day reuse the count.
+func (hs *HistoryService) UnderBudgetStreak() (int, error) {
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+
+	now := hs.clock.Now()
+	today := now.Format("2006-01-02")
+	if hs.streakDay == today {
+		return hs.streak, nil
+	}
+	samples, err := hs.samplesLocked(time.Time{})
+	if err != nil {
+		return 0, err
+	}
+	hs.streak, hs.streakDay = models.UnderBudgetStreak(samples, now), today
+	return hs.streak, nil
+}
+
+func (hs *HistoryService) now() time.Time {
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+	return hs.clock.Now()
+}
+
+func (hs *HistoryService) loadLastLocked() {
+	if hs.loaded {
+		return
+	}
+	hs.loaded = true
+	samples, err := hs.readLocked()
+	if err != nil || len(samples) == 0 {
+		return
+	}
+	last := samples[len(samples)-1]
+	hs.last = &last
+}
+
+// readLocked decodes the history file, skipping malformed lines (e.g. a
+// partial write from a crash) and migrating lines from older builds.
+func (hs *HistoryService) readLocked() ([]models.UsageSample, error) {
+	data, err := os.ReadFile(hs.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, lib.WrapError(err, lib.ErrCodeSystem, "failed to read usage history")
+	}
+
+	var samples []models.UsageSample
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var s models.UsageSample
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			continue
+		}
+		if !s.Migrate() && !hs.newer {
+			hs.newer = true
+			hs.logger.Warn("Usage history was written by a newer version; it will only be appended to", map[string]interface{}{
+				"path":    hs.path,
+				"version": s.Version,
+			})
+		}
+		samples = append(samples, s)
+	}
+	return samples, scanner.Err()
+}
+
+func (hs *HistoryService) appendLocked(sample models.UsageSample) error {
+	line, err := json.Marshal(sample)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(hs.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(hs.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return errors.Join(err, f.Close())
+	}
+	return f.Close()
+}
+
+// pruneLocked rewrites the file without samples older than the retention
+// window.
+func (hs *HistoryService) pruneLocked(now time.Time) error {
+	samples, err := hs.readLocked()
+	if err != nil || len(samples) == 0 {
+		return err
+	}
+	cutoff := now.Add(-hs.retention)
+	keep := 0
+	for keep < len(samples) && samples[keep].Time.Before(cutoff) {
+		keep++
+	}
+	if keep == 0 {
+		return nil
+	}
+	return hs.writeAllLocked(samples[keep:])
+}
+
+// writeAllLocked replaces the file with samples. The rewrite is atomic so
+// a crash can't lose recent history, and it is refused when a newer build
+// wrote part of the file, since fields this build doesn't know would be
+// lost.
+func (hs *HistoryService) writeAllLocked(samples []models.UsageSample) error {
+	if hs.newer {
+		return errNewerHistory
+	}
+	hs.recent = nil
+	hs.streakDay = ""
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, s := range samples {
+		if err := enc.Encode(s); err != nil {
+			return err
+		}
+	}
+	tmp := hs.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, hs.path); err != nil {
+		return errors.Join(err, os.Remove(tmp))
+	}
+	return nil
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+func TestHistoryService_RecordSkipsUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	hs := NewHistoryServiceAt(path)
+	clock := &fixedClock{now: time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)}
+	hs.SetClock(clock)
+
+	state := &models.UsageState{IsAvailable: true, DailyCost: 1.5, DailyCount: 100}
+	require.NoError(t, hs.Record(state))
+	clock.now = clock.now.Add(5 * time.Minute)
+	require.NoError(t, hs.Record(state)) // unchanged: skipped
+	clock.now = clock.now.Add(historyHeartbeat)
+	require.NoError(t, hs.Record(state)) // heartbeat
+	require.NoError(t, hs.Record(&models.UsageState{IsAvailable: false}))
+
+	samples, err := hs.Samples(time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, samples, 2)
+
+	// A fresh instance picks up the last sample from disk.
+	reopened := NewHistoryServiceAt(path)
+	reopened.SetClock(clock)
+	require.NoError(t, reopened.Record(state))
+	samples, err = reopened.Samples(time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, samples, 2)
+}
+
+func TestHistoryService_HourlyHistogram(t *testing.T) {
+	hs := NewHistoryServiceAt(filepath.Join(t.TempDir(), "history.jsonl"))
+	clock := &fixedClock{now: time.Date(2025, 3, 14, 13, 50, 0, 0, time.Local)}
+	hs.SetClock(clock)
+
+	for _, step := range []struct {
+		minutes int
+		cost    float64
+	}{{0, 1}, {20, 2}, {40, 4.5}} {
+		clock.now = time.Date(2025, 3, 14, 13, 50, 0, 0, time.Local).Add(time.Duration(step.minutes) * time.Minute)
+		require.NoError(t, hs.Record(&models.UsageState{IsAvailable: true, DailyCost: step.cost}))
+	}
+
+	h, err := hs.HourlyHistogram()
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0, h.Untracked, 1e-9)
+	assert.InDelta(t, 3.5, h.Hours[14], 1e-9)
+}
+
+func TestHistoryService_HeatMap(t *testing.T) {
+	hs := NewHistoryServiceAt(filepath.Join(t.TempDir(), "history.jsonl"))
+	clock := &fixedClock{}
+	hs.SetClock(clock)
+
+	for _, step := range []struct {
+		at     time.Time
+		tokens int
+	}{
+		{time.Date(2025, 2, 1, 10, 0, 0, 0, time.Local), 100}, // outside the range
+		{time.Date(2025, 3, 13, 10, 0, 0, 0, time.Local), 200},
+		{time.Date(2025, 3, 13, 10, 30, 0, 0, time.Local), 800},
+		{time.Date(2025, 3, 14, 16, 0, 0, 0, time.Local), 50},
+	} {
+		clock.now = step.at
+		require.NoError(t, hs.Record(&models.UsageState{IsAvailable: true, DailyCount: step.tokens, DailyCost: float64(step.tokens) / 100}))
+	}
+
+	h, err := hs.HeatMap(7)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 8, 0, 0, 0, 0, time.Local), h.Since)
+	assert.Equal(t, 600, h.Cells[time.Thursday][10].Tokens)
+	assert.Equal(t, 250, h.Untracked.Tokens)
+}
+
+func TestHistoryService_StatusChangesToday(t *testing.T) {
+	hs := NewHistoryServiceAt(filepath.Join(t.TempDir(), "history.jsonl"))
+	start := time.Date(2025, 3, 14, 10, 0, 0, 0, time.Local)
+	clock := &fixedClock{now: start}
+	hs.SetClock(clock)
+
+	state := &models.UsageState{IsAvailable: true, DailyCost: 12, Status: models.Yellow}
+	require.NoError(t, hs.Record(state))
+	clock.now = start.Add(5 * time.Minute)
+	state.Status = models.Red // same cost, lower threshold: still recorded
+	require.NoError(t, hs.Record(state))
+
+	changes, err := hs.StatusChangesToday()
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.True(t, changes[0].Time.Equal(start))
+	assert.Equal(t, []models.AlertStatus{models.Green, models.Yellow}, []models.AlertStatus{changes[0].From, changes[0].To})
+	assert.True(t, changes[1].Time.Equal(start.Add(5*time.Minute)))
+	assert.Equal(t, []models.AlertStatus{models.Yellow, models.Red}, []models.AlertStatus{changes[1].From, changes[1].To})
+	assert.Equal(t, 12.0, changes[1].Cost)
+}
+
+func TestHistoryService_UnderBudgetStreak(t *testing.T) {
+	hs := NewHistoryServiceAt(filepath.Join(t.TempDir(), "history.jsonl"))
+	clock := &fixedClock{}
+	hs.SetClock(clock)
+
+	for day, status := range []models.AlertStatus{models.Red, models.Green, models.Yellow, models.Green} {
+		clock.now = time.Date(2025, 3, 10+day, 17, 0, 0, 0, time.Local)
+		require.NoError(t, hs.Record(&models.UsageState{IsAvailable: true, DailyCost: 5, Status: status}))
+	}
+
+	streak, err := hs.UnderBudgetStreak()
+	require.NoError(t, err)
+	assert.Equal(t, 2, streak, "today (the 13th) isn't over yet")
+
+	clock.now = time.Date(2025, 3, 14, 0, 1, 0, 0, time.Local)
+	streak, err = hs.UnderBudgetStreak()
+	require.NoError(t, err)
+	assert.Equal(t, 3, streak)
+
+	// The rest of the day reuses the count rather than reading the file.
+	require.NoError(t, os.Remove(hs.path))
+	streak, err = hs.UnderBudgetStreak()
+	require.NoError(t, err)
+	assert.Equal(t, 3, streak)
+
+	clock.now = time.Date(2025, 3, 15, 0, 1, 0, 0, time.Local)
+	streak, err = hs.UnderBudgetStreak()
+	require.NoError(t, err)
+	assert.Zero(t, streak, "a new day counts again")
+}
+
+func TestHistoryService_PrunesOldSamples(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	old := `{"t":"2024-01-01T09:00:00Z","cost":1,"tokens":10}` + "\n" +
+		"not json\n" +
+		`{"t":"2025-03-13T09:00:00Z","cost":2,"tokens":20}` + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(old), 0o600))
+
+	hs := NewHistoryServiceAt(path)
+	hs.SetClock(&fixedClock{now: time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)})
+	require.NoError(t, hs.Record(&models.UsageState{IsAvailable: true, DailyCost: 3}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "2024-01-01")
+	assert.Equal(t, 2, strings.Count(string(data), "\n"))
+}
+
+func TestHistoryService_MigratesOlderLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	old := `{"t":"2024-01-01T09:00:00Z","cost":1,"tokens":10}` + "\n" +
+		`{"t":"2025-03-13T09:00:00Z","cost":2,"tokens":20,"status":"green"}` + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(old), 0o600))
+
+	hs := NewHistoryServiceAt(path)
+	hs.SetClock(&fixedClock{now: time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)})
+	samples, err := hs.Samples(time.Time{})
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.Equal(t, models.UsageSampleVersion, samples[1].Version)
+	assert.Equal(t, 2.0, samples[1].Cost)
+
+	// Pruning rewrites the kept line in the current format.
+	require.NoError(t, hs.Record(&models.UsageState{IsAvailable: true, DailyCost: 3}))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"v":1,"t":"2025-03-13T09:00:00Z","cost":2,"tokens":20,"status":"green"}`,
+		strings.Split(string(data), "\n")[0])
+}
+
+func TestHistoryService_KeepsNewerLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	newer := `{"t":"2024-01-01T09:00:00Z","cost":1,"tokens":10}` + "\n" +
+		`{"v":99,"t":"2025-03-13T09:00:00Z","cost":2,"tokens":20,"cache_tokens":5}` + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(newer), 0o600))
+
+	hs := NewHistoryServiceAt(path)
+	hs.SetClock(&fixedClock{now: time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)})
+	samples, err := hs.Samples(time.Time{})
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.Equal(t, 2.0, samples[1].Cost, "known fields are still read")
+
+	// The prune is skipped rather than dropping cache_tokens; new samples
+	// are still appended.
+	require.NoError(t, hs.Record(&models.UsageState{IsAvailable: true, DailyCost: 3}))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), newer))
+	assert.Equal(t, 3, strings.Count(string(data), "\n"))
+}
+
+func TestHistoryService_CachesToday(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	hs := NewHistoryServiceAt(path)
+	clock := &fixedClock{now: time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)}
+	hs.SetClock(clock)
+	require.NoError(t, hs.Record(&models.UsageState{IsAvailable: true, DailyCost: 1, Status: models.Green}))
+
+	_, err := hs.StatusChangesToday()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte("not json\n"), 0o600))
+
+	clock.now = clock.now.Add(time.Hour)
+	require.NoError(t, hs.Record(&models.UsageState{IsAvailable: true, DailyCost: 12, Status: models.Yellow}))
+	changes, err := hs.StatusChangesToday()
+	require.NoError(t, err)
+	assert.Len(t, changes, 1, "today's samples come from memory, with new ones added")
+
+	all, err := hs.Samples(time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, all, 1, "looking further back reads the file")
+
+	clock.now = clock.now.AddDate(0, 0, 1)
+	samples, err := hs.Samples(recentCutoff(clock.now))
+	require.NoError(t, err)
+	assert.Empty(t, samples, "yesterday's samples leave the cache at midnight")
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+const (
+	icsDateFormat     = "20060102"
+	icsDateTimeFormat = "20060102T150405Z"
+)
+
+// RenderICS builds an iCalendar feed with one all-day event per day whose
+// title is that day's spend. UIDs are derived from the date so calendar
+// apps update existing events when the feed is regenerated instead of
+// duplicating them. Days with an entry in tags also list the spend per
+// project tag.
+func RenderICS(days []CCUsageOutput, tags map[string][]models.TagCost, format models.CostFormat, now time.Time) ([]byte, error) {
+	sorted := make([]CCUsageOutput, len(days))
+	copy(sorted, days)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date < sorted[j].Date })
+
+	var buf bytes.Buffer
+	line := func(s string) { buf.WriteString(s + "\r\n") }
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//cc-dailyuse-bar//Daily Spend//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:Claude Code spend")
+
+	stamp := now.UTC().Format(icsDateTimeFormat)
+	for _, day := range sorted {
+		date, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q in ccusage output: %w", day.Date, err)
+		}
+		line("BEGIN:VEVENT")
+		line("UID:" + date.Format(icsDateFormat) + "@cc-dailyuse-bar")
+		line("DTSTAMP:" + stamp)
+		line("DTSTART;VALUE=DATE:" + date.Format(icsDateFormat))
+		line("DTEND;VALUE=DATE:" + date.AddDate(0, 0, 1).Format(icsDateFormat))
+		line("SUMMARY:" + icsText("Claude Code "+format.Format(day.TotalCost)))
+		description := fmt.Sprintf("%d tokens", day.TotalTokens)
+		if dayTags := tags[day.Date]; len(dayTags) > 0 {
+			description += "\n" + models.FormatTagCosts(dayTags, format)
+		}
+		line("DESCRIPTION:" + icsText(description))
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+	return buf.Bytes(), nil
+}
+
+// icsText escapes a TEXT property value (RFC 5545 section 3.3.11).
+func icsText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
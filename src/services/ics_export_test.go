@@ -0,0 +1,57 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+func TestRenderICS(t *testing.T) {
+	now := time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC)
+	days := []CCUsageOutput{
+		{Date: "2025-03-14", TotalCost: 3.5, TotalTokens: 1200},
+		{Date: "2025-03-13", TotalCost: 12.345, TotalTokens: 99},
+	}
+
+	tags := map[string][]models.TagCost{"2025-03-13": {{Tag: "client-A", Cost: 10}, {Tag: "oss", Cost: 2.345}}}
+	data, err := RenderICS(days, tags, models.DefaultCostFormat(), now)
+	require.NoError(t, err)
+	ics := string(data)
+
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(ics, "END:VCALENDAR\r\n"))
+	assert.Equal(t, 2, strings.Count(ics, "BEGIN:VEVENT"))
+	assert.Less(t, strings.Index(ics, "UID:20250313@"), strings.Index(ics, "UID:20250314@"), "events are sorted by date")
+	assert.Contains(t, ics, "DTSTART;VALUE=DATE:20250314\r\nDTEND;VALUE=DATE:20250315\r\n")
+	assert.Contains(t, ics, "SUMMARY:Claude Code $12.35\r\n")
+	assert.Contains(t, ics, "DESCRIPTION:1200 tokens\r\n")
+	assert.Contains(t, ics, `DESCRIPTION:99 tokens\nclient-A $10.00\, oss $2.35`+"\r\n")
+	assert.Contains(t, ics, "DTSTAMP:20250314T093000Z\r\n")
+
+	_, err = RenderICS([]CCUsageOutput{{Date: "14/03/2025"}}, nil, models.DefaultCostFormat(), now)
+	assert.Error(t, err)
+}
+
+func TestICSText(t *testing.T) {
+	assert.Equal(t, `a\, b\; c\\d\ne`, icsText("a, b; c\\d\ne"))
+}
+
+func TestUsageService_DailyHistory(t *testing.T) {
+	service := newTestUsageService()
+	service.ccusagePath = writeCCUsageScript(t, `[ "$4" = 20250201 ] && [ "$6" = 20250314 ] || exit 9
+echo '{"daily":[{"date":"2025-03-13","totalTokens":5,"totalCost":1.25}]}'`)
+
+	days, err := service.DailyHistory(time.Date(2025, 2, 1, 0, 0, 0, 0, time.Local), time.Date(2025, 3, 14, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	require.Len(t, days, 1)
+	assert.Equal(t, 1.25, days[0].TotalCost)
+
+	service.ccusagePath = writeCCUsageScript(t, "echo 'not json'")
+	_, err = service.DailyHistory(time.Now(), time.Now())
+	assert.Error(t, err)
+}
@@ -8,5 +8,6 @@ import (
 )
 
 func TestMain(m *testing.M) {
+	defaultFocusDetector = staticFocus(false)
 	os.Exit(testhelpers.RunSilenced(m))
 }
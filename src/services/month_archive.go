@@ -0,0 +1,171 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// MonthArchive is the finalized record of one calendar month's spend.
+type MonthArchive struct {
+	Month         string             `json:"month"` // YYYY-MM
+	GeneratedAt   time.Time          `json:"generated_at"`
+	TotalCost     float64            `json:"total_cost"`
+	TotalTokens   int                `json:"total_tokens"`
+	ActiveDays    int                `json:"active_days"` // days with any usage
+	MonthlyBudget float64            `json:"monthly_budget,omitempty"`
+	Models        map[string]float64 `json:"models"` // cost per model over the month
+	Days          []LedgerRow        `json:"days"`   // days with usage, in order
+}
+
+// NewMonthArchive summarizes days, ccusage's daily entries for month
+// (YYYY-MM).
+func NewMonthArchive(month string, days []CCUsageOutput, budget float64, now time.Time) *MonthArchive {
+	archive := &MonthArchive{Month: month, GeneratedAt: now, MonthlyBudget: budget, Models: map[string]float64{}, Days: []LedgerRow{}}
+	for _, day := range days {
+		if !strings.HasPrefix(day.Date, month+"-") {
+			continue
+		}
+		row := NewLedgerRow(day)
+		archive.Days = append(archive.Days, row)
+		archive.TotalCost += row.Cost
+		archive.TotalTokens += row.Tokens
+		for model, cost := range row.Models {
+			archive.Models[model] += cost
+		}
+	}
+	sort.Slice(archive.Days, func(i, j int) bool { return archive.Days[i].Date < archive.Days[j].Date })
+	archive.ActiveDays = len(archive.Days)
+	return archive
+}
+
+// MonthName renders the archive's month as "February 2025".
+func (a *MonthArchive) MonthName() string {
+	if t, err := time.Parse("2006-01", a.Month); err == nil {
+		return t.Format("January 2006")
+	}
+	return a.Month
+}
+
+// Markdown renders a human-readable summary: totals, the spend per model,
+// and a table of days.
+func (a *MonthArchive) Markdown(format models.CostFormat) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Claude Code spend: %s\n\n", a.MonthName())
+
+	total := format.Format(a.TotalCost)
+	if a.MonthlyBudget > 0 {
+		total += fmt.Sprintf(" (budget %s, %.0f%%)", format.Format(a.MonthlyBudget), a.TotalCost/a.MonthlyBudget*100)
+	}
+	fmt.Fprintf(&b, "- Total: %s\n", total)
+	fmt.Fprintf(&b, "- Tokens: %s\n", models.FormatTokens(a.TotalTokens))
+	fmt.Fprintf(&b, "- Active days: %d\n", a.ActiveDays)
+	if a.ActiveDays > 0 {
+		busiest := a.Days[0]
+		for _, day := range a.Days[1:] {
+			if day.Cost > busiest.Cost {
+				busiest = day
+			}
+		}
+		fmt.Fprintf(&b, "- Average per active day: %s\n", format.Format(a.TotalCost/float64(a.ActiveDays)))
+		fmt.Fprintf(&b, "- Busiest day: %s (%s)\n", busiest.Date, format.Format(busiest.Cost))
+	}
+
+	if len(a.Models) > 0 {
+		names := make([]string, 0, len(a.Models))
+		for name := range a.Models {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if a.Models[names[i]] != a.Models[names[j]] {
+				return a.Models[names[i]] > a.Models[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		b.WriteString("\n## By model\n\n| Model | Cost | Share |\n|---|---:|---:|\n")
+		for _, name := range names {
+			share := 0.0
+			if a.TotalCost > 0 {
+				share = a.Models[name] / a.TotalCost * 100
+			}
+			fmt.Fprintf(&b, "| %s | %s | %.0f%% |\n", name, format.Format(a.Models[name]), share)
+		}
+	}
+
+	if len(a.Days) > 0 {
+		b.WriteString("\n## By day\n\n| Date | Cost | Tokens |\n|---|---:|---:|\n")
+		for _, day := range a.Days {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", day.Date, format.Format(day.Cost), models.FormatTokens(day.Tokens))
+		}
+	}
+
+	fmt.Fprintf(&b, "\n_Generated %s by cc-dailyuse-bar from ccusage._\n", a.GeneratedAt.Format("2006-01-02 15:04"))
+	return b.String()
+}
+
+// MonthArchiver writes a finalized archive of each completed month, as
+// <YYYY-MM>.json and <YYYY-MM>.md, under the XDG data directory.
+type MonthArchiver struct {
+	dir string
+}
+
+// NewMonthArchiver creates a MonthArchiver at the default location.
+func NewMonthArchiver() *MonthArchiver {
+	return NewMonthArchiverAt(filepath.Join(DefaultPaths().DataDir(), "archive"))
+}
+
+// NewMonthArchiverAt creates a MonthArchiver writing to dir.
+func NewMonthArchiverAt(dir string) *MonthArchiver {
+	return &MonthArchiver{dir: dir}
+}
+
+// Paths returns where month's (YYYY-MM) JSON and Markdown archives go.
+func (ma *MonthArchiver) Paths(month string) (string, string) {
+	base := filepath.Join(ma.dir, month)
+	return base + ".json", base + ".md"
+}
+
+// ArchivePreviousMonth writes the archive of the month before now's,
+// fetching its days with history, unless it already exists or the month
+// had no usage. It returns the archive it wrote, or nil.
+func (ma *MonthArchiver) ArchivePreviousMonth(now time.Time, budget float64, format models.CostFormat,
+	history func(since, until time.Time) ([]CCUsageOutput, error)) (*MonthArchive, error) {
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -1, 0)
+	month := first.Format("2006-01")
+	jsonPath, mdPath := ma.Paths(month)
+	if _, err := os.Stat(jsonPath); err == nil {
+		return nil, nil
+	}
+
+	days, err := history(first, first.AddDate(0, 1, -1))
+	if err != nil {
+		return nil, err
+	}
+	archive := NewMonthArchive(month, days, budget, now)
+	if archive.ActiveDays == 0 {
+		return nil, nil
+	}
+
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(ma.dir, 0o755); err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeSystem, "failed to create the archive directory")
+	}
+	// The JSON file marks the month as done, so it goes last.
+	if err := lib.WriteFileAtomic(mdPath, []byte(archive.Markdown(format))); err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeSystem, "failed to write the month summary")
+	}
+	if err := lib.WriteFileAtomic(jsonPath, append(data, '\n')); err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeSystem, "failed to write the month archive")
+	}
+	return archive, nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+func TestMonthArchive_Markdown(t *testing.T) {
+	archive := NewMonthArchive("2025-02", []CCUsageOutput{
+		{Date: "2025-02-14", TotalCost: 40, TotalTokens: 2_000_000, ModelBreakdowns: []CCUsageModelSummary{{ModelName: "claude-opus-4", Cost: 40}}},
+		{Date: "2025-02-03", TotalCost: 10, TotalTokens: 500_000, ModelBreakdowns: []CCUsageModelSummary{{ModelName: "claude-sonnet-4", Cost: 10}}},
+		{Date: "2025-03-01", TotalCost: 99, TotalTokens: 1},
+	}, 100, time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, 50.0, archive.TotalCost, "days outside the month are left out")
+	assert.Equal(t, 2, archive.ActiveDays)
+	assert.Equal(t, "2025-02-03", archive.Days[0].Date)
+
+	md := archive.Markdown(models.DefaultCostFormat())
+	assert.Contains(t, md, "# Claude Code spend: February 2025\n")
+	assert.Contains(t, md, "- Total: $50.00 (budget $100.00, 50%)\n")
+	assert.Contains(t, md, "- Busiest day: 2025-02-14 ($40.00)\n")
+	assert.Contains(t, md, "| claude-opus-4 | $40.00 | 80% |\n| claude-sonnet-4 | $10.00 | 20% |\n")
+	assert.Contains(t, md, "| 2025-02-03 | $10.00 | 500K |\n")
+}
+
+func TestMonthArchiver_ArchivePreviousMonth(t *testing.T) {
+	archiver := NewMonthArchiverAt(t.TempDir())
+	now := time.Date(2025, 3, 1, 0, 10, 0, 0, time.Local)
+	var requested []string
+	history := func(since, until time.Time) ([]CCUsageOutput, error) {
+		requested = append(requested, since.Format("2006-01-02")+".."+until.Format("2006-01-02"))
+		return []CCUsageOutput{{Date: "2025-02-28", TotalCost: 5, TotalTokens: 50}}, nil
+	}
+
+	archive, err := archiver.ArchivePreviousMonth(now, 0, models.DefaultCostFormat(), history)
+	require.NoError(t, err)
+	require.NotNil(t, archive)
+	assert.Equal(t, []string{"2025-02-01..2025-02-28"}, requested)
+
+	jsonPath, mdPath := archiver.Paths("2025-02")
+	data, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	var saved MonthArchive
+	require.NoError(t, json.Unmarshal(data, &saved))
+	assert.Equal(t, 5.0, saved.TotalCost)
+	assert.FileExists(t, mdPath)
+
+	archive, err = archiver.ArchivePreviousMonth(now.AddDate(0, 0, 1), 0, models.DefaultCostFormat(), history)
+	require.NoError(t, err)
+	assert.Nil(t, archive, "an existing archive is final")
+	assert.Len(t, requested, 1)
+}
+
+func TestMonthArchiver_NothingToArchive(t *testing.T) {
+	archiver := NewMonthArchiverAt(t.TempDir())
+	now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)
+
+	archive, err := archiver.ArchivePreviousMonth(now, 0, models.DefaultCostFormat(),
+		func(_, _ time.Time) ([]CCUsageOutput, error) { return nil, nil })
+	require.NoError(t, err)
+	assert.Nil(t, archive, "a month without usage isn't archived")
+	jsonPath, _ := archiver.Paths("2025-02")
+	assert.NoFileExists(t, jsonPath)
+
+	_, err = archiver.ArchivePreviousMonth(now, 0, models.DefaultCostFormat(),
+		func(_, _ time.Time) ([]CCUsageOutput, error) { return nil, errors.New("ccusage failed") })
+	assert.Error(t, err)
+}
@@ -0,0 +1,642 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// NotificationService turns alert conditions in a UsageState into desktop
+// notifications, sending each alert at most once per day and nothing at all
+// while quiet mode is active. Alerts raised during Do Not Disturb or a
+// Focus mode are deferred, dropped, or sent as its focus policy says.
+// Status alerts carry action buttons where the platform supports them.
+// Alerts go to the desktop, or to the notification_sinks configured with
+// SetSinks.
+type NotificationService struct {
+	notifier Notifier
+	sinks    *SinkDispatcher
+	built    *sinkSettings // the settings sinks was built from; nil before SetSinks
+	outbox   []delivery    // alerts waiting for sendQueued
+	lastSend chan struct{} // closed when the latest batch sendQueued started is done
+	sending  sync.WaitGroup
+	clock    Clock
+	focus    FocusDetector
+	policy   models.FocusPolicy
+	focusOn  bool // what checkFocus last found
+	logger   *lib.Logger
+	mutex    sync.Mutex
+	sent     map[string]string // alert key -> day (YYYY-MM-DD) it was sent
+	deferred []deferredNotification
+	escalation
+	outage time.Time // start of the polling failure announced, zero when none
+
+	snoozedUntil time.Time        // alerts are deferred until then
+	onAction     func(key string) // handles clicked actions other than snooze; nil offers only snooze
+	canReport    bool             // whether onAction can open a report
+}
+
+// escalation tracks the status or alert level being announced and its
+// reminders.
+type escalation struct {
+	name         string // alert level name or status, e.g. "Critical"
+	day          string // YYYY-MM-DD it was reached
+	remindEvery  time.Duration
+	lastSent     time.Time
+	lastCost     float64 // today's cost at the last notification
+	acknowledged bool
+	reminding    bool // a reminder is on its way to the sinks
+}
+
+// sinkSettings are the settings SetSinks builds the sinks from.
+type sinkSettings struct {
+	sinks         []models.NotificationSink
+	cooldown      int
+	token, chatID string
+}
+
+func newSinkSettings(config *models.Config) *sinkSettings {
+	return &sinkSettings{
+		sinks:    slices.Clone(config.NotificationSinks),
+		cooldown: config.AlertCooldown,
+		token:    config.TelegramBotToken,
+		chatID:   config.TelegramChatID,
+	}
+}
+
+func (s *sinkSettings) equal(other *sinkSettings) bool {
+	return other != nil && slices.EqualFunc(s.sinks, other.sinks, models.NotificationSink.Equal) &&
+		s.cooldown == other.cooldown && s.token == other.token && s.chatID == other.chatID
+}
+
+// delivery is a queued alert and what to do with the result.
+type delivery struct {
+	alert Alert
+	done  func(err error) // runs holding the mutex
+}
+
+// deferredNotification is an alert held back until focus or a snooze
+// ends.
+type deferredNotification struct {
+	alert Alert
+	day   string // YYYY-MM-DD it was raised
+}
+
+// SnoozeDuration is how long the Snooze action holds alerts back.
+const SnoozeDuration = time.Hour
+
+// NewNotificationService creates a NotificationService using the
+// platform's notification tool.
+func NewNotificationService() *NotificationService {
+	ns := &NotificationService{
+		notifier: systemNotifier{},
+		clock:    systemClock{},
+		focus:    defaultFocusDetector,
+		logger:   lib.NewLogger("notification-service"),
+		sent:     make(map[string]string),
+	}
+	ns.sinks = NewSinkDispatcherForConfig(&models.Config{}, ns.desktopSink())
+	ns.sinks.SetClock(ns.clock)
+	return ns
+}
+
+// desktopSink delivers alerts with the service's notifier.
+func (ns *NotificationService) desktopSink() NotificationSink {
+	return desktopSink{notifier: func() Notifier { return ns.notifier }, chosen: ns.handleAction}
+}
+
+// SetSinks registers config's notification_sinks and alert_cooldown. It
+// rebuilds the sinks only when these, or the Telegram settings they may
+// use, have changed, so it can be called on every update to follow config
+// reloads; cooldowns and quotas carry over.
+func (ns *NotificationService) SetSinks(config *models.Config) {
+	settings := newSinkSettings(config)
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	if settings.equal(ns.built) {
+		return
+	}
+	ns.built = settings
+	sinks := NewSinkDispatcherForConfig(config, ns.desktopSink())
+	sinks.SetClock(ns.clock)
+	sinks.KeepHistory(ns.sinks)
+	ns.sinks = sinks
+}
+
+// SetNotifier overrides the delivery mechanism, primarily for tests.
+func (ns *NotificationService) SetNotifier(notifier Notifier) {
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	ns.notifier = notifier
+}
+
+// SetClock overrides the time source, primarily for tests.
+func (ns *NotificationService) SetClock(clock Clock) {
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	if clock == nil {
+		clock = systemClock{}
+	}
+	ns.clock = clock
+	ns.sinks.SetClock(clock)
+}
+
+// SetFocusDetector overrides how Do Not Disturb is detected, primarily for
+// tests.
+func (ns *NotificationService) SetFocusDetector(focus FocusDetector) {
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	ns.focus = focus
+}
+
+// SetFocusPolicy applies the focus_notifications setting.
+func (ns *NotificationService) SetFocusPolicy(policy models.FocusPolicy) {
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	ns.policy = policy
+}
+
+// SetActionHandler routes clicks on notification actions other than
+// Snooze, which the service handles itself, to handler. canReport says
+// whether handler can act on ActionOpenReport, so the button is offered.
+func (ns *NotificationService) SetActionHandler(handler func(key string), canReport bool) {
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	ns.onAction, ns.canReport = handler, canReport
+}
+
+// Snooze defers alerts, and skips reminders, for d.
+func (ns *NotificationService) Snooze(d time.Duration) {
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	ns.snoozedUntil = ns.clock.Now().Add(d)
+	ns.logger.Info("Notifications snoozed", map[string]interface{}{
+		"until": ns.snoozedUntil.Format(time.RFC3339),
+	})
+}
+
+// handleAction runs a clicked notification action.
+func (ns *NotificationService) handleAction(key string) {
+	if key == ActionSnooze {
+		ns.Snooze(SnoozeDuration)
+		return
+	}
+	ns.mutex.Lock()
+	handler := ns.onAction
+	ns.mutex.Unlock()
+	if handler != nil {
+		handler(key)
+	}
+}
+
+// statusActions are the buttons offered on a status alert.
+func (ns *NotificationService) statusActions(state *models.UsageState, config *models.Config) []NotificationAction {
+	actions := []NotificationAction{{ActionSnooze, "Snooze 1h"}}
+	if ns.onAction == nil {
+		return actions
+	}
+	if ns.canReport {
+		actions = append(actions, NotificationAction{ActionOpenReport, "Open report"})
+	}
+	// Alert levels replace the yellow/red pair, so there is none to raise.
+	if len(config.AlertLevels) == 0 {
+		switch state.Status {
+		case models.Yellow:
+			actions = append(actions, NotificationAction{ActionRaiseYellow, "Raise threshold $5"})
+		case models.Red:
+			actions = append(actions, NotificationAction{ActionRaiseRed, "Raise threshold $5"})
+		}
+	}
+	return actions
+}
+
+// deliver queues alert for the sinks and calls done with the result. The
+// caller holds the mutex, and must call sendQueued after releasing it.
+func (ns *NotificationService) deliver(alert Alert, done func(err error)) {
+	ns.outbox = append(ns.outbox, delivery{alert: alert, done: done})
+}
+
+// sendQueued sends the queued alerts in the background, after those queued
+// earlier: sinks post over the network with a timeout of seconds, and the
+// service is called on the UI path. Call it without holding the mutex.
+func (ns *NotificationService) sendQueued() {
+	ns.mutex.Lock()
+	queued, sinks, previous := ns.outbox, ns.sinks, ns.lastSend
+	if len(queued) == 0 {
+		ns.mutex.Unlock()
+		return
+	}
+	done := make(chan struct{})
+	ns.outbox, ns.lastSend = nil, done
+	ns.mutex.Unlock()
+
+	ns.sending.Add(1)
+	go func() {
+		defer ns.sending.Done()
+		defer close(done)
+		if previous != nil {
+			<-previous
+		}
+		for _, d := range queued {
+			err := sinks.Dispatch(d.alert)
+			ns.mutex.Lock()
+			d.done(err)
+			ns.mutex.Unlock()
+		}
+	}()
+}
+
+// Wait blocks until the alerts being sent have been delivered or have
+// failed.
+func (ns *NotificationService) Wait() {
+	ns.sending.Wait()
+}
+
+// snoozed reports whether a Snooze action is holding alerts back.
+func (ns *NotificationService) snoozed() bool {
+	return ns.clock.Now().Before(ns.snoozedUntil)
+}
+
+// FlushDeferred sends the alerts deferred during Do Not Disturb, a Focus
+// mode, or a snooze once it has ended; call it on every update. Alerts
+// raised on an earlier day are dropped, since their figures are out of
+// date.
+func (ns *NotificationService) FlushDeferred() {
+	defer ns.sendQueued()
+	ns.checkFocus()
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	if len(ns.deferred) == 0 || ns.snoozed() || ns.focusActive() {
+		return
+	}
+
+	today := ns.clock.Now().Format("2006-01-02")
+	pending := ns.deferred
+	ns.deferred = nil
+	for _, n := range pending {
+		if n.day != today {
+			ns.logger.Debug("Dropping deferred notification from an earlier day", map[string]interface{}{
+				"key": n.alert.Key,
+				"day": n.day,
+			})
+			continue
+		}
+		ns.deliver(n.alert, func(err error) {
+			switch {
+			case errors.Is(err, errAlertThrottled):
+				ns.deferred = append(ns.deferred, n) // until the cooldown or quota allows it
+			case err != nil && !errors.Is(err, errNotificationsUnsupported):
+				ns.logger.Warn("Failed to send deferred notification", map[string]interface{}{
+					"key":   n.alert.Key,
+					"error": err.Error(),
+				})
+			case err == nil:
+				ns.logger.Info("Deferred notification sent", map[string]interface{}{
+					"key":   n.alert.Key,
+					"title": n.alert.Title,
+				})
+			}
+		})
+	}
+}
+
+// checkFocus asks the focus detector whether Do Not Disturb is on, for
+// focusActive. Asking may run a command, so call it before taking the
+// mutex. Detection errors are logged and count as no focus, so alerts
+// aren't lost.
+func (ns *NotificationService) checkFocus() {
+	ns.mutex.Lock()
+	focus, policy := ns.focus, ns.policy
+	ns.mutex.Unlock()
+	active := false
+	if policy != models.FocusSend && focus != nil {
+		var err error
+		if active, err = focus.FocusActive(); err != nil {
+			ns.logger.Debug("Failed to detect Do Not Disturb", map[string]interface{}{
+				"error": err.Error(),
+			})
+			active = false
+		}
+	}
+	ns.mutex.Lock()
+	ns.focusOn = active
+	ns.mutex.Unlock()
+}
+
+// focusActive reports whether alerts should be held back now, as found by
+// the checkFocus call that preceded taking the mutex.
+func (ns *NotificationService) focusActive() bool {
+	return ns.focusOn && ns.policy != models.FocusSend
+}
+
+// NotifyModelAlerts sends one notification per model threshold reached
+// today, formatting costs with format.
+func (ns *NotificationService) NotifyModelAlerts(state *models.UsageState, format models.CostFormat) {
+	if state == nil || state.Quiet {
+		return
+	}
+	for _, alert := range state.ModelAlerts {
+		title := fmt.Sprintf("Claude Code: %s spend", alert.Pattern)
+		message := fmt.Sprintf("%s models have used %s today (limit %s)",
+			alert.Pattern, format.Format(alert.Cost), format.Format(alert.Threshold))
+		ns.notifyOncePerDay(Alert{Key: "model:" + alert.Pattern, Title: title, Message: message, Level: models.SinkLevelYellow})
+	}
+}
+
+// NotifySessionAlerts sends one notification per session that reached
+// session_cap today, separate from the daily status alerts: one runaway
+// conversation can cost more than it should while the day is still green.
+func (ns *NotificationService) NotifySessionAlerts(state *models.UsageState, config *models.Config) {
+	if state == nil || state.Quiet {
+		return
+	}
+	format := config.CostFormat()
+	for _, session := range state.SessionAlerts {
+		message := fmt.Sprintf("%s has cost %s in a single session (cap %s)",
+			session.Name, format.Format(session.Cost), format.Format(config.SessionCap))
+		ns.notifyOncePerDay(Alert{Key: "session:" + session.Name, Title: "Claude Code: runaway session",
+			Message: message, Level: models.SinkLevelRed})
+	}
+}
+
+// NotifyStatus announces reaching a status or alert level that
+// status_notifications or the level's notify setting asks for, once, and
+// with remind_every keeps reminding every so many minutes while today's
+// cost is still rising, until Acknowledge. A reminder falls due only when
+// spend has grown since the last notification, so idle time is quiet.
+// Nothing is sent in quiet mode; reminders are skipped, not deferred,
+// during Do Not Disturb.
+func (ns *NotificationService) NotifyStatus(state *models.UsageState, config *models.Config) {
+	if state == nil || !state.IsAvailable || state.Quiet {
+		return
+	}
+	name, policy := config.NotifyPolicyFor(state)
+	format := config.CostFormat()
+
+	defer ns.sendQueued()
+	ns.checkFocus()
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	now := ns.clock.Now()
+	today := now.Format("2006-01-02")
+	if policy == nil {
+		ns.escalation = escalation{}
+		return
+	}
+	if ns.escalation.name != name || ns.escalation.day != today {
+		ns.escalation = escalation{name: name, day: today, lastSent: now, lastCost: state.DailyCost}
+	}
+	e := &ns.escalation
+	e.remindEvery = time.Duration(policy.RemindEvery) * time.Minute // follows config reloads
+
+	if key := "status:" + name; ns.sent[key] != today {
+		// The first notification, or a retry after it failed.
+		message := fmt.Sprintf("Today's spend has reached %s", format.Format(state.DailyCost))
+		if policy.RemindEvery > 0 {
+			message += "; acknowledge it in the menu to stop reminders"
+		}
+		ns.sendOncePerDay(Alert{Key: key, Title: "Claude Code: " + name + " spend", Message: message,
+			Level: models.SinkLevelFor(state.Status), Actions: ns.statusActions(state, config)})
+		e.lastSent, e.lastCost = now, state.DailyCost
+		return
+	}
+	if e.remindEvery == 0 || e.acknowledged || e.reminding || now.Sub(e.lastSent) < e.remindEvery || state.DailyCost <= e.lastCost {
+		return
+	}
+	if ns.snoozed() || ns.focusActive() {
+		return
+	}
+	message := fmt.Sprintf("Still rising: %s today, up %s since the last alert",
+		format.Format(state.DailyCost), format.Format(state.DailyCost-e.lastCost))
+	reminder := Alert{Key: "status:" + name + ":reminder", Title: "Claude Code: " + name + " spend reminder", Message: message,
+		Level: models.SinkLevelFor(state.Status), Actions: ns.statusActions(state, config)}
+	e.reminding = true
+	sent := *e
+	cost := state.DailyCost
+	ns.deliver(reminder, func(err error) {
+		e := &ns.escalation
+		if e.name != sent.name || e.day != sent.day {
+			return // the status has moved on since
+		}
+		e.reminding = false
+		if err != nil {
+			// A failed or throttled reminder stays due and is tried again on the next update.
+			if !errors.Is(err, errNotificationsUnsupported) && !errors.Is(err, errAlertThrottled) {
+				ns.logger.Warn("Failed to send reminder", map[string]interface{}{
+					"level": name,
+					"error": err.Error(),
+				})
+			}
+			return
+		}
+		e.lastSent, e.lastCost = now, cost
+		ns.logger.Info("Reminder sent", map[string]interface{}{
+			"level": name,
+			"cost":  cost,
+		})
+	})
+}
+
+// Reminding reports whether NotifyStatus may still send reminders for the
+// current status, so the tray can offer to acknowledge it.
+func (ns *NotificationService) Reminding() bool {
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	return ns.escalation.remindEvery > 0 && !ns.escalation.acknowledged
+}
+
+// Acknowledge stops reminders for the current status or alert level. The
+// next one reached, or the same one tomorrow, is announced again.
+func (ns *NotificationService) Acknowledge() {
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	if ns.escalation.name == "" {
+		return
+	}
+	ns.escalation.acknowledged = true
+	ns.logger.Info("Alert acknowledged", map[string]interface{}{
+		"level": ns.escalation.name,
+	})
+}
+
+// NotifyDailyReset greets a new day with the under-budget streak that
+// ended yesterday. Without a streak there is nothing to celebrate, so it
+// sends nothing.
+func (ns *NotificationService) NotifyDailyReset(state *models.UsageState, streak int) {
+	if state == nil || state.Quiet || streak < 1 {
+		return
+	}
+	ns.notifyOncePerDay(Alert{Key: "daily-reset", Title: "Claude Code: new day", Message: models.FormatStreak(streak), Level: models.SinkLevelInfo})
+}
+
+// NotifyMonthArchived announces a completed month's archive with a link
+// to its Markdown summary at mdPath.
+func (ns *NotificationService) NotifyMonthArchived(state *models.UsageState, archive *MonthArchive, mdPath string, format models.CostFormat) {
+	if (state != nil && state.Quiet) || archive == nil {
+		return
+	}
+	message := fmt.Sprintf("%s over %d days. Summary: %s", format.Format(archive.TotalCost), archive.ActiveDays, fileLink(mdPath))
+	ns.notifyOncePerDay(Alert{Key: "month-archive:" + archive.Month, Title: "Claude Code: " + archive.MonthName() + " archived",
+		Message: message, Level: models.SinkLevelInfo})
+}
+
+// NotifyExported tells where an export picked from the menu, such as the
+// usage heat map, was written. It was asked for, so it is sent every time,
+// even in quiet mode or Do Not Disturb.
+func (ns *NotificationService) NotifyExported(what, path string) {
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	if err := ns.notifier.Notify("Claude Code: "+what+" exported", fileLink(path)); err != nil && !errors.Is(err, errNotificationsUnsupported) {
+		ns.logger.Warn("Failed to send notification", map[string]interface{}{
+			"path":  path,
+			"error": err.Error(),
+		})
+	}
+}
+
+// fileLink turns path into a file:// URL that notification centres make
+// clickable.
+func fileLink(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// NotifyPollReliability warns, once a day, when the share of successful
+// polls (see UsageService.PollReliability) has fallen below threshold
+// percent, which usually means a ccusage or Node.js upgrade broke polling.
+// A threshold of 0 disables the warning.
+func (ns *NotificationService) NotifyPollReliability(state *models.UsageState, percent, polls, threshold int) {
+	if (state != nil && state.Quiet) || !PollReliabilityLow(percent, polls, threshold) {
+		return
+	}
+	message := fmt.Sprintf("Only %d%% of the last %d polls succeeded; check that ccusage still runs", percent, polls)
+	ns.notifyOncePerDay(Alert{Key: "poll-reliability", Title: "Claude Code: usage polling is failing", Message: message, Level: models.SinkLevelYellow})
+}
+
+// NotifyPollFailure announces, once, that polls have failed without a
+// break since failingSince for at least grace, and then that they work
+// again once failingSince is zero. A grace of 0 disables both. The
+// failure isn't announced in quiet mode, and so neither is its recovery.
+func (ns *NotificationService) NotifyPollFailure(state *models.UsageState, failingSince time.Time, grace time.Duration) {
+	defer ns.sendQueued()
+	ns.checkFocus()
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	now := ns.clock.Now()
+	if failingSince.IsZero() {
+		if ns.outage.IsZero() {
+			return
+		}
+		message := fmt.Sprintf("ccusage is working again after %s; spend figures are up to date", outageLength(now.Sub(ns.outage)))
+		ns.sendOncePerDay(Alert{Key: "poll-recovered:" + ns.outage.Format(time.RFC3339), Title: "Claude Code: usage polling recovered",
+			Message: message, Level: models.SinkLevelInfo})
+		ns.outage = time.Time{}
+		return
+	}
+	if grace <= 0 || now.Sub(failingSince) < grace || (state != nil && state.Quiet) {
+		return
+	}
+	key := "poll-failure:" + failingSince.Format(time.RFC3339)
+	if ns.outage.Equal(failingSince) && ns.sent[key] != "" {
+		return // announced, or on its way; a failed send clears sent
+	}
+	message := fmt.Sprintf("ccusage has been unreachable for %s; spend shown is out of date", outageLength(now.Sub(failingSince)))
+	ns.sendOncePerDay(Alert{Key: key, Title: "Claude Code: usage polling is failing", Message: message, Level: models.SinkLevelYellow})
+	ns.outage = failingSince
+}
+
+// outageLength renders d in whole minutes, or hours and minutes, e.g.
+// "10 minutes" or "2h 5m".
+func outageLength(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	switch {
+	case minutes == 1:
+		return "1 minute"
+	case minutes < 60:
+		return fmt.Sprintf("%d minutes", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%dh", minutes/60)
+	default:
+		return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+	}
+}
+
+// notifyOncePerDay sends alert unless its key was already sent today.
+// Failures, and alerts the sinks' throttles held back, are retried on the
+// next call.
+func (ns *NotificationService) notifyOncePerDay(alert Alert) {
+	defer ns.sendQueued()
+	ns.checkFocus()
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	ns.sendOncePerDay(alert)
+}
+
+// sendOncePerDay is notifyOncePerDay for callers holding the mutex, who
+// must call sendQueued after releasing it. The alert counts as sent while
+// it is on its way, so it isn't sent twice.
+func (ns *NotificationService) sendOncePerDay(alert Alert) {
+	key := alert.Key
+	today := ns.clock.Now().Format("2006-01-02")
+	if ns.sent[key] == today {
+		return
+	}
+
+	if ns.snoozed() {
+		ns.sent[key] = today
+		ns.deferred = append(ns.deferred, deferredNotification{alert: alert, day: today})
+		ns.logger.Debug("Deferring notification while snoozed", map[string]interface{}{
+			"key": key,
+		})
+		return
+	}
+	if ns.focusActive() {
+		// Either way it counts as sent today, so it isn't raised again.
+		ns.sent[key] = today
+		if ns.policy == models.FocusSuppress {
+			ns.logger.Debug("Suppressing notification during Do Not Disturb", map[string]interface{}{
+				"key": key,
+			})
+			return
+		}
+		ns.deferred = append(ns.deferred, deferredNotification{alert: alert, day: today})
+		ns.logger.Debug("Deferring notification until Do Not Disturb ends", map[string]interface{}{
+			"key": key,
+		})
+		return
+	}
+
+	ns.sent[key] = today
+	ns.deliver(alert, func(err error) {
+		switch {
+		case err == nil:
+			ns.logger.Info("Notification sent", map[string]interface{}{
+				"key":   key,
+				"title": alert.Title,
+			})
+		case errors.Is(err, errNotificationsUnsupported):
+			// Retrying every poll can't help; count it as handled.
+			ns.logger.Debug("Skipping notification", map[string]interface{}{
+				"key":   key,
+				"error": err.Error(),
+			})
+		default:
+			// Not sent, so the next call tries again.
+			if ns.sent[key] == today {
+				delete(ns.sent, key)
+			}
+			if !errors.Is(err, errAlertThrottled) {
+				ns.logger.Warn("Failed to send notification", map[string]interface{}{
+					"key":   key,
+					"error": err.Error(),
+				})
+			}
+		}
+	})
+}
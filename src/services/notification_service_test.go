@@ -0,0 +1,597 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+type recordingNotifier struct {
+	titles   []string
+	messages []string
+	err      error
+}
+
+func (r *recordingNotifier) Notify(title, message string) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.titles = append(r.titles, title)
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func TestNotificationService_ModelAlertsOncePerDay(t *testing.T) {
+	notifier := &recordingNotifier{}
+	service := NewNotificationService()
+	service.SetNotifier(notifier)
+	clock := &fixedClock{now: time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)}
+	service.SetClock(clock)
+
+	state := &models.UsageState{ModelAlerts: []models.ModelAlert{{Pattern: "opus", Cost: 12.5, Threshold: 10}}}
+	format := models.DefaultCostFormat()
+
+	service.NotifyModelAlerts(state, format)
+	service.NotifyModelAlerts(state, format)
+	service.Wait()
+	assert.Equal(t, []string{"Claude Code: opus spend"}, notifier.titles)
+	assert.Equal(t, []string{"opus models have used $12.50 today (limit $10.00)"}, notifier.messages)
+
+	clock.now = clock.now.AddDate(0, 0, 1)
+	service.NotifyModelAlerts(state, format)
+	service.Wait()
+	assert.Len(t, notifier.titles, 2, "a new day re-arms the alert")
+}
+
+func TestNotificationService_SessionAlerts(t *testing.T) {
+	notifier := &recordingNotifier{}
+	service := NewNotificationService()
+	service.SetNotifier(notifier)
+	service.SetClock(&fixedClock{now: time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)})
+	config := models.ConfigDefaults()
+	config.SessionCap = 5
+
+	state := &models.UsageState{Status: models.Green, SessionAlerts: []models.SessionCost{{Name: "refactor-api", Cost: 6.2}}}
+	service.NotifySessionAlerts(state, config)
+	service.NotifySessionAlerts(state, config)
+	service.Wait()
+	assert.Equal(t, []string{"Claude Code: runaway session"}, notifier.titles)
+	assert.Equal(t, []string{"refactor-api has cost $6.20 in a single session (cap $5.00)"}, notifier.messages)
+
+	state.SessionAlerts = append(state.SessionAlerts, models.SessionCost{Name: "docs", Cost: 5})
+	service.NotifySessionAlerts(state, config)
+	service.Wait()
+	assert.Len(t, notifier.titles, 2, "each session alerts once")
+
+	state.Quiet = true
+	state.SessionAlerts = []models.SessionCost{{Name: "scratch", Cost: 9}}
+	service.NotifySessionAlerts(state, config)
+	service.Wait()
+	assert.Len(t, notifier.titles, 2, "quiet mode sends nothing")
+}
+
+func TestNotificationService_QuietAndFailures(t *testing.T) {
+	notifier := &recordingNotifier{}
+	service := NewNotificationService()
+	service.SetNotifier(notifier)
+	state := &models.UsageState{Quiet: true, ModelAlerts: []models.ModelAlert{{Pattern: "opus", Cost: 12, Threshold: 10}}}
+
+	service.NotifyModelAlerts(state, models.DefaultCostFormat())
+	service.Wait()
+	assert.Empty(t, notifier.titles, "quiet mode sends nothing")
+
+	state.Quiet = false
+	notifier.err = errors.New("notify-send missing")
+	service.NotifyModelAlerts(state, models.DefaultCostFormat())
+	service.Wait()
+	notifier.err = nil
+	service.NotifyModelAlerts(state, models.DefaultCostFormat())
+	service.Wait()
+	assert.Len(t, notifier.titles, 1, "a failed send is retried")
+}
+
+func TestNotificationService_DailyReset(t *testing.T) {
+	notifier := &recordingNotifier{}
+	service := NewNotificationService()
+	service.SetNotifier(notifier)
+	state := &models.UsageState{}
+
+	service.NotifyDailyReset(state, 0)
+	service.NotifyDailyReset(&models.UsageState{Quiet: true}, 6)
+	service.Wait()
+	assert.Empty(t, notifier.titles, "no streak or quiet mode sends nothing")
+
+	service.NotifyDailyReset(state, 6)
+	service.NotifyDailyReset(state, 6)
+	service.Wait()
+	assert.Equal(t, []string{"Claude Code: new day"}, notifier.titles)
+	assert.Equal(t, []string{"🔥 6-day streak under budget"}, notifier.messages)
+}
+
+func TestNotificationService_MonthArchived(t *testing.T) {
+	notifier := &recordingNotifier{}
+	service := NewNotificationService()
+	service.SetNotifier(notifier)
+	archive := &MonthArchive{Month: "2025-02", TotalCost: 312.4, ActiveDays: 20}
+
+	service.NotifyMonthArchived(&models.UsageState{Quiet: true}, archive, "/data/2025-02.md", models.DefaultCostFormat())
+	service.NotifyMonthArchived(&models.UsageState{}, nil, "", models.DefaultCostFormat())
+	service.Wait()
+	assert.Empty(t, notifier.titles, "quiet mode or no archive sends nothing")
+
+	service.NotifyMonthArchived(&models.UsageState{}, archive, "/data/2025-02.md", models.DefaultCostFormat())
+	service.Wait()
+	assert.Equal(t, []string{"Claude Code: February 2025 archived"}, notifier.titles)
+	assert.Equal(t, []string{"$312.40 over 20 days. Summary: file:///data/2025-02.md"}, notifier.messages)
+}
+
+func TestNotificationService_Exported(t *testing.T) {
+	notifier := &recordingNotifier{}
+	service := NewNotificationService()
+	service.SetNotifier(notifier)
+
+	service.NotifyExported("Usage heat map", "/data/exports/heatmap.html")
+	service.NotifyExported("Usage heat map", "/data/exports/heatmap.html")
+	assert.Equal(t, []string{"Claude Code: Usage heat map exported", "Claude Code: Usage heat map exported"}, notifier.titles,
+		"every export is announced")
+	assert.Equal(t, "file:///data/exports/heatmap.html", notifier.messages[0])
+}
+
+func TestNotificationService_PollReliability(t *testing.T) {
+	notifier := &recordingNotifier{}
+	service := NewNotificationService()
+	service.SetNotifier(notifier)
+	state := &models.UsageState{}
+
+	service.NotifyPollReliability(state, 95, 200, 90)
+	service.NotifyPollReliability(state, 50, 200, 0)
+	service.NotifyPollReliability(&models.UsageState{Quiet: true}, 50, 200, 90)
+	service.Wait()
+	assert.Empty(t, notifier.titles)
+
+	service.NotifyPollReliability(state, 85, 200, 90)
+	service.NotifyPollReliability(state, 80, 210, 90)
+	service.Wait()
+	assert.Equal(t, []string{"Claude Code: usage polling is failing"}, notifier.titles)
+	assert.Equal(t, []string{"Only 85% of the last 200 polls succeeded; check that ccusage still runs"}, notifier.messages)
+}
+
+func TestNotificationService_StatusEscalation(t *testing.T) {
+	notifier := &recordingNotifier{}
+	service := NewNotificationService()
+	service.SetNotifier(notifier)
+	clock := &fixedClock{now: time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)}
+	service.SetClock(clock)
+	config := models.ConfigDefaults()
+	config.StatusNotifications = models.StatusNotifications{
+		Yellow: &models.NotifyPolicy{},
+		Red:    &models.NotifyPolicy{RemindEvery: 15},
+	}
+	observe := func(status models.AlertStatus, cost float64, after time.Duration) {
+		clock.now = clock.now.Add(after)
+		service.NotifyStatus(&models.UsageState{Status: status, DailyCost: cost, IsAvailable: true}, config)
+		service.Wait()
+	}
+
+	observe(models.Yellow, 12, 0)
+	observe(models.Yellow, 15, time.Hour)
+	assert.Equal(t, []string{"Claude Code: High spend"}, notifier.titles, "yellow notifies once")
+	assert.False(t, service.Reminding())
+
+	observe(models.Red, 21, time.Minute)
+	assert.Equal(t, "Claude Code: Critical spend", notifier.titles[1])
+	assert.Equal(t, "Today's spend has reached $21.00; acknowledge it in the menu to stop reminders", notifier.messages[1])
+	assert.True(t, service.Reminding())
+
+	observe(models.Red, 23, 10*time.Minute)
+	assert.Len(t, notifier.titles, 2, "not due yet")
+	observe(models.Red, 24, 5*time.Minute)
+	assert.Equal(t, "Claude Code: Critical spend reminder", notifier.titles[2])
+	assert.Equal(t, "Still rising: $24.00 today, up $3.00 since the last alert", notifier.messages[2])
+
+	observe(models.Red, 24, time.Hour)
+	assert.Len(t, notifier.titles, 3, "no reminder while spend is flat")
+	observe(models.Red, 25, time.Minute)
+	assert.Len(t, notifier.titles, 4, "rising again resumes reminders")
+
+	service.Acknowledge()
+	assert.False(t, service.Reminding())
+	observe(models.Red, 30, time.Hour)
+	assert.Len(t, notifier.titles, 4, "acknowledged")
+
+	observe(models.Red, 1, 24*time.Hour)
+	assert.Len(t, notifier.titles, 5, "a new day announces red again")
+	assert.True(t, service.Reminding())
+}
+
+func TestNotificationService_StatusQuiet(t *testing.T) {
+	notifier := &recordingNotifier{}
+	service := NewNotificationService()
+	service.SetNotifier(notifier)
+	config := models.ConfigDefaults()
+	config.StatusNotifications.Red = &models.NotifyPolicy{RemindEvery: 15}
+
+	service.NotifyStatus(&models.UsageState{Status: models.Red, DailyCost: 25, IsAvailable: true, Quiet: true}, config)
+	service.NotifyStatus(&models.UsageState{Status: models.Red}, config)
+	service.NotifyStatus(nil, config)
+	service.Wait()
+	assert.Empty(t, notifier.titles)
+
+	config.StatusNotifications.Red = nil
+	service.NotifyStatus(&models.UsageState{Status: models.Red, DailyCost: 25, IsAvailable: true}, config)
+	service.Wait()
+	assert.Empty(t, notifier.titles, "off unless configured")
+}
+
+// actionNotifier records the actions offered and keeps the callback, so
+// a test can click one.
+type actionNotifier struct {
+	recordingNotifier
+	actions [][]NotificationAction
+	chosen  func(key string)
+}
+
+func (a *actionNotifier) NotifyWithActions(title, message string, actions []NotificationAction, chosen func(key string)) error {
+	a.actions = append(a.actions, actions)
+	a.chosen = chosen
+	return a.Notify(title, message)
+}
+
+func actionKeys(actions []NotificationAction) []string {
+	keys := make([]string, len(actions))
+	for i, action := range actions {
+		keys[i] = action.Key
+	}
+	return keys
+}
+
+func TestNotificationService_StatusActions(t *testing.T) {
+	red := &models.UsageState{Status: models.Red, DailyCost: 25, IsAvailable: true}
+	tests := []struct {
+		name      string
+		handler   bool
+		canReport bool
+		levels    []models.AlertLevel
+		want      []string
+	}{
+		{"no handler", false, false, nil, []string{ActionSnooze}},
+		{"no report", true, false, nil, []string{ActionSnooze, ActionRaiseRed}},
+		{"all", true, true, nil, []string{ActionSnooze, ActionOpenReport, ActionRaiseRed}},
+		{"alert levels", true, true, []models.AlertLevel{{Name: "max", Threshold: 20, Status: models.Red}}, []string{ActionSnooze, ActionOpenReport}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &actionNotifier{}
+			service := NewNotificationService()
+			service.SetNotifier(notifier)
+			if tt.handler {
+				service.SetActionHandler(func(string) {}, tt.canReport)
+			}
+			config := models.ConfigDefaults()
+			config.AlertLevels = tt.levels
+			config.StatusNotifications.Red = &models.NotifyPolicy{}
+			state := *red
+			if tt.levels != nil {
+				state.Level = "max"
+			}
+
+			service.NotifyStatus(&state, config)
+			service.Wait()
+			require.Len(t, notifier.actions, 1)
+			assert.Equal(t, tt.want, actionKeys(notifier.actions[0]))
+		})
+	}
+}
+
+func TestNotificationService_ActionRouting(t *testing.T) {
+	notifier := &actionNotifier{}
+	service := NewNotificationService()
+	service.SetNotifier(notifier)
+	clock := &fixedClock{now: time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)}
+	service.SetClock(clock)
+	var handled []string
+	service.SetActionHandler(func(key string) { handled = append(handled, key) }, true)
+	config := models.ConfigDefaults()
+	config.StatusNotifications = models.StatusNotifications{Yellow: &models.NotifyPolicy{}, Red: &models.NotifyPolicy{RemindEvery: 15}}
+
+	service.NotifyStatus(&models.UsageState{Status: models.Yellow, DailyCost: 12, IsAvailable: true}, config)
+	service.Wait()
+	notifier.chosen(ActionRaiseYellow)
+	assert.Equal(t, []string{ActionRaiseYellow}, handled)
+
+	notifier.chosen(ActionSnooze)
+	assert.Equal(t, []string{ActionRaiseYellow}, handled, "snooze is handled by the service")
+
+	clock.now = clock.now.Add(10 * time.Minute)
+	service.NotifyStatus(&models.UsageState{Status: models.Red, DailyCost: 21, IsAvailable: true}, config)
+	service.FlushDeferred()
+	service.Wait()
+	assert.Len(t, notifier.titles, 1, "held back while snoozed")
+
+	clock.now = clock.now.Add(SnoozeDuration)
+	service.FlushDeferred()
+	service.Wait()
+	assert.Equal(t, []string{"Claude Code: High spend", "Claude Code: Critical spend"}, notifier.titles)
+	assert.Equal(t, []string{ActionSnooze, ActionOpenReport, ActionRaiseRed}, actionKeys(notifier.actions[1]),
+		"deferred alerts keep their actions")
+}
+
+func TestLibnotifyHasActions(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"notify-send 0.7.9", true},
+		{"notify-send 0.8.3", true},
+		{"notify-send 1.0", true},
+		{"notify-send 0.7.8", false},
+		{"notify-send 0.6.0", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, libnotifyHasActions(tt.version), tt.version)
+	}
+}
+
+func TestWaiterLimit(t *testing.T) {
+	limit := &waiterLimit{max: 2}
+	ended := map[int]bool{}
+	add := func(n int) func() {
+		return limit.add(func() { ended[n] = true })
+	}
+
+	doneFirst := add(1)
+	add(2)
+	assert.Empty(t, ended)
+
+	add(3)
+	assert.True(t, ended[1], "a third waiter ends the oldest")
+	assert.False(t, ended[2])
+
+	doneFirst()
+	assert.Len(t, limit.cancels, 2, "an ended waiter isn't tracked twice")
+	add(4)
+	assert.True(t, ended[2])
+	assert.False(t, ended[3])
+}
+
+func TestOpenCommand(t *testing.T) {
+	name, args := openCommand("darwin", "http://127.0.0.1:7399/")
+	assert.Equal(t, "open", name)
+	assert.Equal(t, []string{"http://127.0.0.1:7399/"}, args)
+	name, args = openCommand("windows", "http://127.0.0.1:7399/")
+	assert.Equal(t, "rundll32", name)
+	assert.Equal(t, []string{"url.dll,FileProtocolHandler", "http://127.0.0.1:7399/"}, args)
+	name, _ = openCommand("linux", "/tmp/heatmap.html")
+	assert.Equal(t, "xdg-open", name)
+}
+
+// staticFocus is a FocusDetector stuck on or off.
+type staticFocus bool
+
+func (f staticFocus) FocusActive() (bool, error) { return bool(f), nil }
+
+// toggleFocus is a FocusDetector switched by the test.
+type toggleFocus struct{ active bool }
+
+func (f *toggleFocus) FocusActive() (bool, error) { return f.active, nil }
+
+func TestNotificationService_FocusPolicies(t *testing.T) {
+	state := &models.UsageState{ModelAlerts: []models.ModelAlert{{Pattern: "opus", Cost: 12, Threshold: 10}}}
+	tests := []struct {
+		policy    models.FocusPolicy
+		duringDND int // notifications sent while focus is on
+		afterDND  int // after it ends and FlushDeferred runs
+	}{
+		{"", 0, 1},
+		{models.FocusDefer, 0, 1},
+		{models.FocusSuppress, 0, 0},
+		{models.FocusSend, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			notifier := &recordingNotifier{}
+			focus := &toggleFocus{active: true}
+			service := NewNotificationService()
+			service.SetNotifier(notifier)
+			service.SetFocusDetector(focus)
+			service.SetFocusPolicy(tt.policy)
+
+			service.NotifyModelAlerts(state, models.DefaultCostFormat())
+			service.NotifyModelAlerts(state, models.DefaultCostFormat())
+			service.FlushDeferred()
+			service.Wait()
+			assert.Len(t, notifier.titles, tt.duringDND)
+
+			focus.active = false
+			service.FlushDeferred()
+			service.NotifyModelAlerts(state, models.DefaultCostFormat())
+			service.Wait()
+			assert.Len(t, notifier.titles, tt.afterDND, "held back at most once, never repeated")
+		})
+	}
+}
+
+func TestNotificationService_DeferredExpireAtMidnight(t *testing.T) {
+	notifier := &recordingNotifier{}
+	focus := &toggleFocus{active: true}
+	service := NewNotificationService()
+	service.SetNotifier(notifier)
+	service.SetFocusDetector(focus)
+	clock := &fixedClock{now: time.Date(2025, 3, 14, 23, 0, 0, 0, time.Local)}
+	service.SetClock(clock)
+
+	service.NotifyDailyReset(&models.UsageState{}, 3)
+	service.Wait()
+	clock.now = clock.now.Add(2 * time.Hour)
+	focus.active = false
+	service.FlushDeferred()
+	service.Wait()
+	assert.Empty(t, notifier.titles, "yesterday's alerts are out of date")
+}
+
+func TestNotificationService_ExportedIgnoresFocus(t *testing.T) {
+	notifier := &recordingNotifier{}
+	service := NewNotificationService()
+	service.SetNotifier(notifier)
+	service.SetFocusDetector(staticFocus(true))
+
+	service.NotifyExported("Usage heat map", "/tmp/heatmap.html")
+	assert.Len(t, notifier.titles, 1, "a requested export is always announced")
+}
+
+// countingFocus is a FocusDetector that counts how often it is asked.
+type countingFocus struct{ calls int }
+
+func (f *countingFocus) FocusActive() (bool, error) {
+	f.calls++
+	return true, nil
+}
+
+func TestCachedFocusDetector(t *testing.T) {
+	detector := &countingFocus{}
+	notifier := &recordingNotifier{}
+	service := NewNotificationService()
+	service.SetNotifier(notifier)
+	service.SetFocusDetector(&cachedFocusDetector{detector: detector})
+
+	state := &models.UsageState{ModelAlerts: []models.ModelAlert{
+		{Pattern: "opus", Cost: 12, Threshold: 10},
+		{Pattern: "sonnet", Cost: 6, Threshold: 5},
+	}}
+	service.NotifyModelAlerts(state, models.DefaultCostFormat())
+	service.FlushDeferred()
+	service.Wait()
+	assert.Empty(t, notifier.titles)
+	assert.Equal(t, 1, detector.calls, "asked once within focusCacheTTL")
+}
+
+func TestFocusAssertionsActive(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name, json string
+		want       bool
+	}{
+		{"focus on", `{"data":[{"storeAssertionRecords":[{"assertionDetails":{"assertionDetailsModeIdentifier":"com.apple.donotdisturb.mode.default"}}]}]}`, true},
+		{"focus off", `{"data":[{"storeAssertionRecords":[]}]}`, false},
+		{"no data", `{"data":[]}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "Assertions.json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.json), 0o644))
+			active, err := focusAssertionsActive(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, active)
+		})
+	}
+
+	_, err := focusAssertionsActive(filepath.Join(dir, "missing.json"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestAppleScriptString(t *testing.T) {
+	assert.Equal(t, `"say \"hi\" \\ bye"`, appleScriptString(`say "hi" \ bye`))
+}
+
+func TestNotificationService_PollFailure(t *testing.T) {
+	notifier := &recordingNotifier{}
+	service := NewNotificationService()
+	service.SetNotifier(notifier)
+	start := time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)
+	clock := &fixedClock{now: start.Add(5 * time.Minute)}
+	service.SetClock(clock)
+	state := &models.UsageState{}
+
+	service.NotifyPollFailure(state, start, 10*time.Minute)
+	service.Wait()
+	assert.Empty(t, notifier.titles, "still within the grace period")
+
+	clock.now = start.Add(10 * time.Minute)
+	service.NotifyPollFailure(state, start, 10*time.Minute)
+	service.Wait()
+	clock.now = start.Add(30 * time.Minute)
+	service.NotifyPollFailure(state, start, 10*time.Minute)
+	service.Wait()
+	assert.Equal(t, []string{"Claude Code: usage polling is failing"}, notifier.titles, "announced once")
+	assert.Equal(t, "ccusage has been unreachable for 10 minutes; spend shown is out of date", notifier.messages[0])
+
+	clock.now = start.Add(2*time.Hour + 5*time.Minute)
+	service.NotifyPollFailure(state, time.Time{}, 10*time.Minute)
+	service.NotifyPollFailure(state, time.Time{}, 10*time.Minute)
+	service.Wait()
+	assert.Equal(t, []string{"Claude Code: usage polling is failing", "Claude Code: usage polling recovered"}, notifier.titles)
+	assert.Equal(t, "ccusage is working again after 2h 5m; spend figures are up to date", notifier.messages[1])
+}
+
+func TestNotificationService_PollFailureDisabledOrQuiet(t *testing.T) {
+	notifier := &recordingNotifier{}
+	service := NewNotificationService()
+	service.SetNotifier(notifier)
+	start := time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)
+	service.SetClock(&fixedClock{now: start.Add(time.Hour)})
+
+	service.NotifyPollFailure(&models.UsageState{}, start, 0)
+	service.NotifyPollFailure(&models.UsageState{Quiet: true}, start, 10*time.Minute)
+	service.NotifyPollFailure(&models.UsageState{}, time.Time{}, 10*time.Minute)
+	service.Wait()
+	assert.Empty(t, notifier.titles, "no recovery without an announced failure")
+}
+
+func TestNotificationService_ThrottledAlertsRetried(t *testing.T) {
+	notifier := &recordingNotifier{}
+	service := NewNotificationService()
+	service.SetNotifier(notifier)
+	clock := &fixedClock{now: time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)}
+	service.SetClock(clock)
+	config := models.ConfigDefaults()
+	config.NotificationSinks = []models.NotificationSink{{Type: models.SinkDesktop, MaxPerHour: 1}}
+	service.SetSinks(config)
+
+	service.NotifyDailyReset(&models.UsageState{}, 6)
+	service.Wait()
+	state := &models.UsageState{ModelAlerts: []models.ModelAlert{{Pattern: "opus", Cost: 12, Threshold: 10}}}
+	service.NotifyModelAlerts(state, models.DefaultCostFormat())
+	service.Wait()
+	assert.Equal(t, []string{"Claude Code: new day"}, notifier.titles, "the quota holds the second alert back")
+
+	clock.now = clock.now.Add(time.Hour)
+	service.NotifyModelAlerts(state, models.DefaultCostFormat())
+	service.Wait()
+	assert.Equal(t, []string{"Claude Code: new day", "Claude Code: opus spend"}, notifier.titles,
+		"a held-back alert isn't counted as sent")
+}
+
+// blockingNotifier holds every notification until release is closed.
+type blockingNotifier struct {
+	recordingNotifier
+	release chan struct{}
+}
+
+func (b *blockingNotifier) Notify(title, message string) error {
+	<-b.release
+	return b.recordingNotifier.Notify(title, message)
+}
+
+func TestNotificationService_SendsInBackground(t *testing.T) {
+	notifier := &blockingNotifier{release: make(chan struct{})}
+	service := NewNotificationService()
+	service.SetNotifier(notifier)
+	config := models.ConfigDefaults()
+	config.StatusNotifications.Red = &models.NotifyPolicy{RemindEvery: 15}
+
+	service.NotifyStatus(&models.UsageState{Status: models.Red, DailyCost: 25, IsAvailable: true}, config)
+	assert.True(t, service.Reminding(), "a slow sink doesn't hold the service up")
+	service.NotifyStatus(&models.UsageState{Status: models.Red, DailyCost: 26, IsAvailable: true}, config)
+
+	close(notifier.release)
+	service.Wait()
+	assert.Equal(t, []string{"Claude Code: Critical spend"}, notifier.titles, "an alert on its way isn't sent twice")
+}
@@ -0,0 +1,402 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// Alert is a notification on its way to the configured sinks.
+type Alert struct {
+	Key     string // identifies the alert, e.g. "status:Critical"
+	Title   string
+	Message string
+	Level   models.SinkLevel
+	Actions []NotificationAction // buttons, on sinks that can show them
+}
+
+// NotificationSink delivers alerts to one channel, such as the desktop or
+// a chat service.
+type NotificationSink interface {
+	Send(alert Alert) error
+}
+
+// sinkClient posts alerts to the HTTP-based sinks.
+var sinkClient = &http.Client{Timeout: 10 * time.Second}
+
+// NewNotificationSink creates the sink a notification_sinks entry
+// describes. Desktop entries are built by the caller, which owns the
+// desktop notifier and its action buttons.
+func NewNotificationSink(sink models.NotificationSink, config *models.Config) (NotificationSink, error) {
+	switch sink.Type {
+	case models.SinkWebhook:
+		return webhookSink{url: sink.URL}, nil
+	case models.SinkSlack:
+		return slackSink{url: sink.URL}, nil
+	case models.SinkNtfy:
+		return ntfySink{url: sink.URL}, nil
+	case models.SinkTelegram:
+		bot := NewTelegramBotForConfig(config)
+		if bot == nil {
+			return nil, lib.ValidationError("the telegram sink needs telegram_bot_token and telegram_chat_id")
+		}
+		return telegramSink{bot: bot}, nil
+	case models.SinkCommand:
+		if len(sink.Command) == 0 {
+			return nil, lib.ValidationError("the command sink needs a command")
+		}
+		return commandSink{argv: sink.Command}, nil
+	default:
+		return nil, lib.ValidationError(fmt.Sprintf("unknown notification sink type %q", sink.Type))
+	}
+}
+
+// desktopSink shows alerts with a Notifier, with action buttons when it
+// supports them.
+type desktopSink struct {
+	notifier func() Notifier // looked up per alert, so SetNotifier applies
+	chosen   func(key string)
+}
+
+func (s desktopSink) Send(alert Alert) error {
+	notifier := s.notifier()
+	if n, ok := notifier.(ActionNotifier); ok && len(alert.Actions) > 0 {
+		return n.NotifyWithActions(alert.Title, alert.Message, alert.Actions, s.chosen)
+	}
+	return notifier.Notify(alert.Title, alert.Message)
+}
+
+// webhookSink posts alerts as JSON: {"key", "level", "title", "message"}.
+type webhookSink struct{ url string }
+
+func (s webhookSink) Send(alert Alert) error {
+	body, err := json.Marshal(map[string]string{
+		"key":     alert.Key,
+		"level":   string(alert.Level),
+		"title":   alert.Title,
+		"message": alert.Message,
+	})
+	if err != nil {
+		return err
+	}
+	return postAlert(s.url, "application/json", body, nil)
+}
+
+// slackSink posts alerts to a Slack incoming webhook.
+type slackSink struct{ url string }
+
+func (s slackSink) Send(alert Alert) error {
+	body, err := json.Marshal(map[string]string{"text": "*" + alert.Title + "*\n" + alert.Message})
+	if err != nil {
+		return err
+	}
+	return postAlert(s.url, "application/json", body, nil)
+}
+
+// ntfySink publishes alerts to an ntfy topic, red ones at urgent priority
+// so the phone app can break through its own quiet settings.
+type ntfySink struct{ url string }
+
+var ntfyPriorities = map[models.SinkLevel]string{
+	models.SinkLevelInfo:   "default",
+	models.SinkLevelYellow: "high",
+	models.SinkLevelRed:    "urgent",
+}
+
+func (s ntfySink) Send(alert Alert) error {
+	headers := map[string]string{"Title": alert.Title}
+	if priority, ok := ntfyPriorities[alert.Level]; ok {
+		headers["Priority"] = priority
+	}
+	return postAlert(s.url, "text/plain; charset=utf-8", []byte(alert.Message), headers)
+}
+
+// telegramSink sends alerts to telegram_bot_token's chat.
+type telegramSink struct{ bot *TelegramBot }
+
+func (s telegramSink) Send(alert Alert) error {
+	return s.bot.Send(alert.Title + "\n" + alert.Message)
+}
+
+// commandSink runs a program for each alert, passing it in the
+// CC_ALERT_KEY, CC_ALERT_LEVEL, CC_ALERT_TITLE, and CC_ALERT_MESSAGE
+// environment variables.
+type commandSink struct{ argv []string }
+
+func (s commandSink) Send(alert Alert) error {
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, s.argv[0], s.argv[1:]...)
+	cmd.Env = append(os.Environ(),
+		"CC_ALERT_KEY="+alert.Key,
+		"CC_ALERT_LEVEL="+string(alert.Level),
+		"CC_ALERT_TITLE="+alert.Title,
+		"CC_ALERT_MESSAGE="+alert.Message,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %w (%s)", s.argv[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// postAlert posts body to url and expects a 2xx answer.
+func postAlert(url, contentType string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := sinkClient.Do(req)
+	if err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to post the alert")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return lib.SystemError(fmt.Sprintf("alert endpoint answered %s", resp.Status))
+	}
+	return nil
+}
+
+// DedupWindow is how long an alert identical to one already delivered,
+// in level, title, and message, is dropped.
+const DedupWindow = time.Hour
+
+// registeredSink is a sink with its name, for logs, level filter, and
+// quota.
+type registeredSink struct {
+	name       string
+	sink       NotificationSink
+	minLevel   models.SinkLevel
+	maxPerHour int // 0 is unlimited
+}
+
+// SinkDispatcher fans alerts out to every registered sink whose level
+// filter lets them through. It also throttles them for all sinks at once:
+// an alert key sent within the cooldown, or an alert identical to one
+// sent within DedupWindow, is dropped, and a sink that has had its
+// max_per_hour skips alerts until the hour has passed.
+type SinkDispatcher struct {
+	sinks    []registeredSink
+	cooldown time.Duration
+	clock    Clock
+	history  *alertHistory
+	logger   *lib.Logger
+}
+
+// alertHistory remembers recent deliveries for the throttles. It outlives
+// a dispatcher rebuilt after a config reload, see KeepHistory.
+type alertHistory struct {
+	mutex     sync.Mutex
+	byKey     map[string]time.Time   // alert key -> last delivered
+	byPayload map[string]time.Time   // level, title, and message -> last delivered
+	bySink    map[string][]time.Time // sink name -> deliveries in the last hour
+}
+
+// NewSinkDispatcher creates a dispatcher without sinks or cooldown.
+func NewSinkDispatcher() *SinkDispatcher {
+	return &SinkDispatcher{
+		clock: systemClock{},
+		history: &alertHistory{
+			byKey:     make(map[string]time.Time),
+			byPayload: make(map[string]time.Time),
+			bySink:    make(map[string][]time.Time),
+		},
+		logger: lib.NewLogger("notification-sinks"),
+	}
+}
+
+// NewSinkDispatcherForConfig registers config's notification_sinks, using
+// desktop for its desktop entries, or desktop alone when there are none,
+// and applies its alert_cooldown. Entries that can't be built are logged
+// and skipped.
+func NewSinkDispatcherForConfig(config *models.Config, desktop NotificationSink) *SinkDispatcher {
+	d := NewSinkDispatcher()
+	d.SetCooldown(time.Duration(config.AlertCooldown) * time.Minute)
+	if len(config.NotificationSinks) == 0 {
+		d.Register(string(models.SinkDesktop), desktop, models.SinkLevelInfo, 0)
+		return d
+	}
+	for i, entry := range config.NotificationSinks {
+		name := fmt.Sprintf("%s[%d]", entry.Type, i)
+		if entry.Type == models.SinkDesktop {
+			d.Register(name, desktop, entry.MinLevel, entry.MaxPerHour)
+			continue
+		}
+		sink, err := NewNotificationSink(entry, config)
+		if err != nil {
+			d.logger.Warn("Skipping notification sink", map[string]interface{}{
+				"sink":  name,
+				"error": err.Error(),
+			})
+			continue
+		}
+		d.Register(name, sink, entry.MinLevel, entry.MaxPerHour)
+	}
+	return d
+}
+
+// Register adds sink, which receives alerts of minLevel and above, at most
+// maxPerHour of them in any hour unless it is 0.
+func (d *SinkDispatcher) Register(name string, sink NotificationSink, minLevel models.SinkLevel, maxPerHour int) {
+	d.sinks = append(d.sinks, registeredSink{name: name, sink: sink, minLevel: minLevel, maxPerHour: maxPerHour})
+}
+
+// SetCooldown sets how long after an alert others with the same key are
+// dropped; 0 turns the cooldown off.
+func (d *SinkDispatcher) SetCooldown(cooldown time.Duration) {
+	d.cooldown = cooldown
+}
+
+// SetClock overrides the time source, primarily for tests.
+func (d *SinkDispatcher) SetClock(clock Clock) {
+	d.clock = clock
+}
+
+// KeepHistory carries previous's deliveries over, so rebuilding the
+// dispatcher for a new config doesn't reset cooldowns and quotas.
+func (d *SinkDispatcher) KeepHistory(previous *SinkDispatcher) {
+	if previous != nil {
+		d.history = previous.history
+	}
+}
+
+// errAlertThrottled is returned by Dispatch when the cooldown, dedup, or
+// every quota held an alert back, so the caller can try it again later
+// instead of counting it as sent.
+var errAlertThrottled = errors.New("alert held back by alert_cooldown or max_per_hour")
+
+// Dispatch sends alert to the sinks that take its level and are within
+// their quota, all at once, unless the cooldown or dedup drops it. It
+// returns an error only when every sink it went to failed, so a retry
+// can't repeat the alert on a sink that got it; failures of single sinks
+// are logged instead. A dropped alert, or one that every sink taking its
+// level is over quota for, returns errAlertThrottled.
+func (d *SinkDispatcher) Dispatch(alert Alert) error {
+	now := d.clock.Now()
+	payload := string(alert.Level) + "\x00" + alert.Title + "\x00" + alert.Message
+	h := d.history
+
+	h.mutex.Lock()
+	h.prune(now, d.cooldown)
+	if last, ok := h.byKey[alert.Key]; ok && d.cooldown > 0 && now.Sub(last) < d.cooldown {
+		h.mutex.Unlock()
+		d.logger.Info("Holding alert back during its cooldown", map[string]interface{}{
+			"key": alert.Key,
+		})
+		return errAlertThrottled
+	}
+	if last, ok := h.byPayload[payload]; ok && now.Sub(last) < DedupWindow {
+		h.mutex.Unlock()
+		d.logger.Info("Holding back duplicate alert", map[string]interface{}{
+			"key": alert.Key,
+		})
+		return errAlertThrottled
+	}
+	var targets []registeredSink
+	overQuota := 0
+	for _, r := range d.sinks {
+		if !r.minLevel.Allows(alert.Level) {
+			continue
+		}
+		if r.maxPerHour > 0 && len(h.bySink[r.name]) >= r.maxPerHour {
+			d.logger.Info("Notification sink quota reached", map[string]interface{}{
+				"sink": r.name,
+				"key":  alert.Key,
+			})
+			overQuota++
+			continue
+		}
+		targets = append(targets, r)
+	}
+	h.mutex.Unlock()
+	if len(targets) == 0 && overQuota > 0 {
+		return errAlertThrottled
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	failed := make(map[string]error)
+	for _, r := range targets {
+		wg.Add(1)
+		go func(r registeredSink) {
+			defer wg.Done()
+			if err := r.sink.Send(alert); err != nil {
+				mutex.Lock()
+				failed[r.name] = err
+				mutex.Unlock()
+			}
+		}(r)
+	}
+	wg.Wait()
+
+	if len(targets) > 0 && len(failed) == len(targets) {
+		errs := make([]error, 0, len(failed))
+		for _, err := range failed {
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	}
+
+	h.mutex.Lock()
+	h.byKey[alert.Key] = now
+	h.byPayload[payload] = now
+	for _, r := range targets {
+		if _, ok := failed[r.name]; !ok {
+			h.bySink[r.name] = append(h.bySink[r.name], now)
+		}
+	}
+	h.mutex.Unlock()
+
+	for name, err := range failed {
+		if errors.Is(err, errNotificationsUnsupported) {
+			continue
+		}
+		d.logger.Warn("Notification sink failed", map[string]interface{}{
+			"sink":  name,
+			"key":   alert.Key,
+			"error": err.Error(),
+		})
+	}
+	return nil
+}
+
+// prune forgets deliveries too old to throttle anything.
+func (h *alertHistory) prune(now time.Time, cooldown time.Duration) {
+	for key, at := range h.byKey {
+		if now.Sub(at) >= cooldown {
+			delete(h.byKey, key)
+		}
+	}
+	for payload, at := range h.byPayload {
+		if now.Sub(at) >= DedupWindow {
+			delete(h.byPayload, payload)
+		}
+	}
+	for name, times := range h.bySink {
+		kept := times[:0]
+		for _, at := range times {
+			if now.Sub(at) < time.Hour {
+				kept = append(kept, at)
+			}
+		}
+		if len(kept) == 0 {
+			delete(h.bySink, name)
+		} else {
+			h.bySink[name] = kept
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+// recordingSink keeps the alerts it receives.
+type recordingSink struct {
+	mu     sync.Mutex
+	alerts []Alert
+	err    error
+}
+
+func (r *recordingSink) Send(alert Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+func (r *recordingSink) keys() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keys := make([]string, len(r.alerts))
+	for i, alert := range r.alerts {
+		keys[i] = alert.Key
+	}
+	return keys
+}
+
+// capturedRequest is a request received by captureServer.
+type capturedRequest struct {
+	header http.Header
+	body   string
+}
+
+func captureServer(t *testing.T) (*httptest.Server, func() []capturedRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []capturedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, capturedRequest{header: r.Header, body: string(body)})
+		mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []capturedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]capturedRequest(nil), requests...)
+	}
+}
+
+func TestSinkDispatcher_LevelFilters(t *testing.T) {
+	all, critical := &recordingSink{}, &recordingSink{}
+	d := NewSinkDispatcher()
+	d.Register("all", all, "", 0)
+	d.Register("critical", critical, models.SinkLevelRed, 0)
+
+	for _, alert := range []Alert{
+		{Key: "daily-reset", Level: models.SinkLevelInfo},
+		{Key: "status:High", Level: models.SinkLevelYellow},
+		{Key: "status:Critical", Level: models.SinkLevelRed},
+	} {
+		require.NoError(t, d.Dispatch(alert))
+	}
+	assert.Equal(t, []string{"daily-reset", "status:High", "status:Critical"}, all.keys())
+	assert.Equal(t, []string{"status:Critical"}, critical.keys())
+}
+
+func TestSinkDispatcher_FailsOnlyWhenEverySinkFails(t *testing.T) {
+	ok, broken := &recordingSink{}, &recordingSink{err: errors.New("boom")}
+	d := NewSinkDispatcher()
+	d.Register("ok", ok, "", 0)
+	d.Register("broken", broken, "", 0)
+	assert.NoError(t, d.Dispatch(Alert{Key: "a", Title: "a"}), "one sink got it, so it mustn't be retried")
+
+	ok.err = errors.New("down")
+	assert.Error(t, d.Dispatch(Alert{Key: "b", Title: "b"}))
+
+	d = NewSinkDispatcher()
+	d.Register("critical", broken, models.SinkLevelRed, 0)
+	assert.NoError(t, d.Dispatch(Alert{Key: "d", Level: models.SinkLevelYellow}), "no sink wanted it")
+}
+
+func TestSinkDispatcher_Throttling(t *testing.T) {
+	clock := &fixedClock{now: time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)}
+	phone, slack := &recordingSink{}, &recordingSink{}
+	d := NewSinkDispatcher()
+	d.SetClock(clock)
+	d.SetCooldown(15 * time.Minute)
+	d.Register("phone", phone, "", 2)
+	d.Register("slack", slack, "", 0)
+
+	reminder := func(cost string) Alert {
+		return Alert{Key: "status:Critical:reminder", Title: "Claude Code: Critical spend reminder", Message: cost, Level: models.SinkLevelRed}
+	}
+	require.NoError(t, d.Dispatch(reminder("$25")))
+	clock.now = clock.now.Add(5 * time.Minute)
+	assert.ErrorIs(t, d.Dispatch(reminder("$26")), errAlertThrottled)
+	assert.Len(t, slack.keys(), 1, "the same kind of alert waits out the cooldown")
+
+	clock.now = clock.now.Add(15 * time.Minute)
+	require.NoError(t, d.Dispatch(reminder("$27")))
+	clock.now = clock.now.Add(15 * time.Minute)
+	require.NoError(t, d.Dispatch(reminder("$28")))
+	assert.Len(t, slack.keys(), 3)
+	assert.Len(t, phone.keys(), 2, "the phone's quota of 2 an hour is used up")
+
+	clock.now = clock.now.Add(time.Hour)
+	poll := Alert{Key: "poll-reliability", Title: "Claude Code: usage polling is failing", Level: models.SinkLevelYellow}
+	require.NoError(t, d.Dispatch(poll))
+	assert.Len(t, phone.keys(), 3, "the quota frees up after an hour")
+
+	poll.Key = "poll-reliability:retry"
+	clock.now = clock.now.Add(30 * time.Minute)
+	assert.ErrorIs(t, d.Dispatch(poll), errAlertThrottled)
+	assert.Len(t, slack.keys(), 4, "an identical payload is dropped within DedupWindow")
+	clock.now = clock.now.Add(DedupWindow)
+	require.NoError(t, d.Dispatch(poll))
+	assert.Len(t, slack.keys(), 5)
+}
+
+func TestSinkDispatcher_KeepHistory(t *testing.T) {
+	clock := &fixedClock{now: time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)}
+	sink := &recordingSink{}
+	d := NewSinkDispatcher()
+	d.SetClock(clock)
+	d.Register("phone", sink, "", 1)
+	require.NoError(t, d.Dispatch(Alert{Key: "a", Title: "a"}))
+
+	rebuilt := NewSinkDispatcher()
+	rebuilt.SetClock(clock)
+	rebuilt.Register("phone", sink, "", 1)
+	rebuilt.KeepHistory(d)
+	assert.ErrorIs(t, rebuilt.Dispatch(Alert{Key: "b", Title: "b"}), errAlertThrottled)
+	assert.Equal(t, []string{"a"}, sink.keys(), "a config reload doesn't reset the quota")
+}
+
+func TestNewSinkDispatcherForConfig(t *testing.T) {
+	desktop := &recordingSink{}
+	d := NewSinkDispatcherForConfig(models.ConfigDefaults(), desktop)
+	require.NoError(t, d.Dispatch(Alert{Key: "daily-reset", Level: models.SinkLevelInfo}))
+	assert.Equal(t, []string{"daily-reset"}, desktop.keys(), "without sinks, alerts go to the desktop")
+
+	server, requests := captureServer(t)
+	config := models.ConfigDefaults()
+	config.NotificationSinks = []models.NotificationSink{
+		{Type: models.SinkWebhook, URL: server.URL, MinLevel: models.SinkLevelYellow},
+		{Type: models.SinkTelegram}, // skipped: no token
+	}
+	desktop = &recordingSink{}
+	d = NewSinkDispatcherForConfig(config, desktop)
+	require.NoError(t, d.Dispatch(Alert{Key: "status:Critical", Title: "Claude Code: Critical spend", Message: "$25.00", Level: models.SinkLevelRed}))
+	assert.Empty(t, desktop.keys(), "listing sinks replaces the desktop")
+	require.Len(t, requests(), 1)
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal([]byte(requests()[0].body), &payload))
+	assert.Equal(t, map[string]string{"key": "status:Critical", "level": "red", "title": "Claude Code: Critical spend", "message": "$25.00"}, payload)
+}
+
+func TestNotificationSinks_Formats(t *testing.T) {
+	alert := Alert{Key: "status:Critical", Title: "Claude Code: Critical spend", Message: "$25.00 today", Level: models.SinkLevelRed}
+
+	t.Run("slack", func(t *testing.T) {
+		server, requests := captureServer(t)
+		sink, err := NewNotificationSink(models.NotificationSink{Type: models.SinkSlack, URL: server.URL}, models.ConfigDefaults())
+		require.NoError(t, err)
+		require.NoError(t, sink.Send(alert))
+		assert.JSONEq(t, `{"text": "*Claude Code: Critical spend*\n$25.00 today"}`, requests()[0].body)
+	})
+
+	t.Run("ntfy", func(t *testing.T) {
+		server, requests := captureServer(t)
+		sink, err := NewNotificationSink(models.NotificationSink{Type: models.SinkNtfy, URL: server.URL}, models.ConfigDefaults())
+		require.NoError(t, err)
+		require.NoError(t, sink.Send(alert))
+		got := requests()[0]
+		assert.Equal(t, "$25.00 today", got.body)
+		assert.Equal(t, "Claude Code: Critical spend", got.header.Get("Title"))
+		assert.Equal(t, "urgent", got.header.Get("Priority"))
+	})
+
+	t.Run("telegram", func(t *testing.T) {
+		fake := newFakeTelegram(t)
+		config := models.ConfigDefaults()
+		config.TelegramBotToken, config.TelegramChatID = testTelegramToken, "1"
+		sink, err := NewNotificationSink(models.NotificationSink{Type: models.SinkTelegram}, config)
+		require.NoError(t, err)
+		require.NoError(t, sink.Send(alert))
+		assert.Equal(t, "Claude Code: Critical spend\n$25.00 today", fake.messages[0]["text"])
+	})
+
+	t.Run("command", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("uses a shell script")
+		}
+		out := filepath.Join(t.TempDir(), "alert.txt")
+		sink, err := NewNotificationSink(models.NotificationSink{Type: models.SinkCommand,
+			Command: []string{"/bin/sh", "-c", `printf '%s|%s|%s' "$CC_ALERT_LEVEL" "$CC_ALERT_TITLE" "$CC_ALERT_MESSAGE" > "$0"`, out}},
+			models.ConfigDefaults())
+		require.NoError(t, err)
+		require.NoError(t, sink.Send(alert))
+		data, err := os.ReadFile(out)
+		require.NoError(t, err)
+		assert.Equal(t, "red|Claude Code: Critical spend|$25.00 today", string(data))
+	})
+
+	t.Run("failing endpoint", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+		sink, err := NewNotificationSink(models.NotificationSink{Type: models.SinkWebhook, URL: server.URL}, models.ConfigDefaults())
+		require.NoError(t, err)
+		assert.ErrorContains(t, sink.Send(alert), "403")
+	})
+}
+
+func TestNotificationService_Sinks(t *testing.T) {
+	server, requests := captureServer(t)
+	config := models.ConfigDefaults()
+	config.NotificationSinks = []models.NotificationSink{
+		{Type: models.SinkDesktop},
+		{Type: models.SinkWebhook, URL: server.URL, MinLevel: models.SinkLevelRed},
+	}
+	notifier := &recordingNotifier{}
+	service := NewNotificationService()
+	service.SetNotifier(notifier)
+	service.SetClock(&fixedClock{now: time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)})
+	service.SetSinks(config)
+
+	config.StatusNotifications.Red = &models.NotifyPolicy{}
+	service.NotifyDailyReset(&models.UsageState{}, 3)
+	service.NotifyStatus(&models.UsageState{Status: models.Red, DailyCost: 25, IsAvailable: true}, config)
+	service.Wait()
+	assert.Equal(t, []string{"Claude Code: new day", "Claude Code: Critical spend"}, notifier.titles)
+	require.Len(t, requests(), 1, "the webhook only takes red alerts")
+	assert.Contains(t, requests()[0].body, `"key":"status:Critical"`)
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// notifyTimeout bounds how long a desktop notification command may run.
+const notifyTimeout = 5 * time.Second
+
+// errNotificationsUnsupported is returned on platforms without a known
+// notification tool.
+var errNotificationsUnsupported = errors.New("desktop notifications are not supported on this platform")
+
+// Notifier delivers a desktop notification.
+type Notifier interface {
+	Notify(title, message string) error
+}
+
+// NotificationAction is a button on a notification. Key is passed back to
+// the action handler when it is clicked.
+type NotificationAction struct {
+	Key   string
+	Label string
+}
+
+// Notification action keys.
+const (
+	ActionSnooze      = "snooze"       // hold notifications back for an hour
+	ActionOpenReport  = "open-report"  // open the dashboard or usage report
+	ActionRaiseYellow = "raise-yellow" // raise the yellow threshold by $5
+	ActionRaiseRed    = "raise-red"    // raise the red threshold by $5
+)
+
+// ActionNotifier is a Notifier that can attach action buttons. It returns
+// once the notification is shown; when a button is clicked later, chosen
+// is called with its key from another goroutine.
+type ActionNotifier interface {
+	Notifier
+	NotifyWithActions(title, message string, actions []NotificationAction, chosen func(key string)) error
+}
+
+// systemNotifier shells out to the platform's notification tool:
+// osascript on macOS and notify-send on Linux.
+type systemNotifier struct{}
+
+func (systemNotifier) Notify(title, message string) error {
+	var name string
+	var args []string
+	switch runtime.GOOS {
+	case "darwin":
+		name = "osascript"
+		args = []string{"-e", fmt.Sprintf("display notification %s with title %s",
+			appleScriptString(message), appleScriptString(title))}
+	case "linux", "freebsd", "openbsd", "netbsd":
+		name = "notify-send"
+		args = []string{"--app-name=cc-dailyuse-bar", title, message}
+	default:
+		return fmt.Errorf("%w (%s)", errNotificationsUnsupported, runtime.GOOS)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, name, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %w (%s)", name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// NotifyWithActions adds the buttons with notify-send 0.7.9 or later, which
+// prints the clicked action's key; elsewhere it sends a plain notification,
+// as neither osascript nor older notify-send versions take actions.
+func (n systemNotifier) NotifyWithActions(title, message string, actions []NotificationAction, chosen func(key string)) error {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" || !notifySendActions() {
+		return n.Notify(title, message)
+	}
+	args := []string{"--app-name=cc-dailyuse-bar", "--wait"}
+	for _, action := range actions {
+		args = append(args, "--action="+action.Key+"="+action.Label)
+	}
+	args = append(args, title, message)
+
+	// --wait keeps notify-send running until the notification is closed,
+	// so it isn't bound by notifyTimeout, only by actionWaitLimit and
+	// newer notifications taking its place.
+	ctx, cancel := context.WithTimeout(context.Background(), actionWaitLimit)
+	cmd := exec.CommandContext(ctx, "notify-send", args...)
+	var out strings.Builder
+	cmd.Stdout = &out
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return fmt.Errorf("notify-send failed: %w", err)
+	}
+	done := actionWaiters.add(cancel)
+	go func() {
+		defer done()
+		if cmd.Wait() == nil {
+			if key := strings.TrimSpace(out.String()); key != "" {
+				chosen(key)
+			}
+		}
+	}()
+	return nil
+}
+
+// Limits on the notify-send --wait processes behind action buttons. An
+// ended process leaves its notification on screen, but its buttons do
+// nothing.
+const (
+	maxActionWaiters = 3         // starting another ends the oldest
+	actionWaitLimit  = time.Hour // an unanswered notification is given up on
+)
+
+var actionWaiters = &waiterLimit{max: maxActionWaiters}
+
+// waiterLimit keeps at most max waiting processes, oldest first, ending
+// the oldest to make room.
+type waiterLimit struct {
+	mu      sync.Mutex
+	max     int
+	cancels []*context.CancelFunc
+}
+
+// add tracks a process ended by cancel. The returned func is called once
+// the process has exited.
+func (w *waiterLimit) add(cancel context.CancelFunc) (done func()) {
+	entry := &cancel
+	w.mu.Lock()
+	for len(w.cancels) >= w.max {
+		(*w.cancels[0])()
+		w.cancels = w.cancels[1:]
+	}
+	w.cancels = append(w.cancels, entry)
+	w.mu.Unlock()
+
+	return func() {
+		w.mu.Lock()
+		for i, c := range w.cancels {
+			if c == entry {
+				w.cancels = append(w.cancels[:i], w.cancels[i+1:]...)
+				break
+			}
+		}
+		w.mu.Unlock()
+		cancel()
+	}
+}
+
+var (
+	notifySendActionsOnce sync.Once
+	notifySendHasActions  bool
+)
+
+// notifySendActions reports whether the installed notify-send accepts
+// --action, which arrived in libnotify 0.7.9.
+func notifySendActions() bool {
+	notifySendActionsOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "notify-send", "--version").Output()
+		notifySendHasActions = err == nil && libnotifyHasActions(strings.TrimSpace(string(out)))
+	})
+	return notifySendHasActions
+}
+
+// libnotifyHasActions parses "notify-send 0.8.3" and reports whether that
+// version is 0.7.9 or later.
+func libnotifyHasActions(version string) bool {
+	var major, minor, patch int
+	if _, err := fmt.Sscanf(strings.TrimPrefix(version, "notify-send "), "%d.%d.%d", &major, &minor, &patch); err != nil {
+		if _, err := fmt.Sscanf(strings.TrimPrefix(version, "notify-send "), "%d.%d", &major, &minor); err != nil {
+			return false
+		}
+	}
+	return major > 0 || minor > 7 || (minor == 7 && patch >= 9)
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// OpenURL opens target, a URL or a file path, in the default browser or
+// application, without waiting for it.
+func OpenURL(target string) error {
+	name, args := openCommand(runtime.GOOS, target)
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// openCommand returns the command that opens target on goos.
+func openCommand(goos, target string) (string, []string) {
+	switch goos {
+	case "darwin":
+		return "open", []string{target}
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", target}
+	default:
+		return "xdg-open", []string{target}
+	}
+}
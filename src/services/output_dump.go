@@ -0,0 +1,82 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultLoggedOutputLength is how many bytes of ccusage output a warning
+// quotes when log_output_length is unset.
+const defaultLoggedOutputLength = 128
+
+// maxDumpFileSize bounds ccusage_dump_file: once it has grown past this
+// the next response starts it afresh, so a forgotten debug setting can't
+// fill the disk.
+const maxDumpFileSize = 10 << 20
+
+// loggedOutputLength resolves log_output_length, where 0 means the default.
+func loggedOutputLength(configured int) int {
+	if configured > 0 {
+		return configured
+	}
+	return defaultLoggedOutputLength
+}
+
+// truncateOutput shortens output to the configured log length, marking
+// the cut with "...".
+func (us *UsageService) truncateOutput(output []byte) string {
+	limit := loggedOutputLength(us.logOutputLength)
+	if len(output) <= limit {
+		return string(output)
+	}
+	return string(output[:limit]) + "..."
+}
+
+// dumpCCUsageOutput appends one ccusage response, untruncated, to the dump
+// file when ccusage_dump_file is set: a header with the time, command
+// line, and outcome, then stdout, then stderr when the run failed.
+// Failing to write only logs a warning.
+func (us *UsageService) dumpCCUsageOutput(args []string, output, stderr []byte, runErr error) {
+	if us.dumpFile == "" {
+		return
+	}
+
+	outcome := "ok"
+	if runErr != nil {
+		outcome = runErr.Error()
+	}
+	var entry strings.Builder
+	fmt.Fprintf(&entry, "=== %s %s %s (%s) ===\n",
+		us.clock.Now().Format(time.RFC3339), us.ccusagePath, strings.Join(args, " "), outcome)
+	entry.Write(output)
+	if len(output) > 0 && output[len(output)-1] != '\n' {
+		entry.WriteByte('\n')
+	}
+	if runErr != nil && len(stderr) > 0 {
+		entry.WriteString("--- stderr ---\n")
+		entry.Write(stderr)
+		if stderr[len(stderr)-1] != '\n' {
+			entry.WriteByte('\n')
+		}
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if info, err := os.Stat(us.dumpFile); err == nil && info.Size() > maxDumpFileSize {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	}
+	file, err := os.OpenFile(us.dumpFile, flags, 0o600)
+	if err == nil {
+		_, err = file.WriteString(entry.String())
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		us.logger.Warn("Failed to write ccusage dump file", map[string]interface{}{
+			"error": err.Error(),
+			"path":  us.dumpFile,
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageService_TruncateOutput(t *testing.T) {
+	long := []byte(strings.Repeat("x", 300))
+
+	tests := []struct {
+		name       string
+		configured int
+		output     []byte
+		want       string
+	}{
+		{"short output is kept", 0, []byte(`{"daily":[]}`), `{"daily":[]}`},
+		{"default length", 0, long, strings.Repeat("x", 128) + "..."},
+		{"configured length", 200, long, strings.Repeat("x", 200) + "..."},
+		{"configured length covers output", 1024, long, string(long)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestUsageService()
+			service.logOutputLength = tt.configured
+			assert.Equal(t, tt.want, service.truncateOutput(tt.output))
+		})
+	}
+}
+
+func TestUsageService_DumpsCCUsageOutput(t *testing.T) {
+	dump := filepath.Join(t.TempDir(), "ccusage-dump.log")
+	service := newTestUsageService()
+	service.SetClock(fixedClock{time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)})
+	service.dumpFile = dump
+
+	service.ccusagePath = writeCCUsageScript(t, `echo '{"daily":[{"date":"2025-03-14"'`)
+	_, err := service.runCCUsage([]string{"daily", "--json"})
+	require.NoError(t, err)
+
+	service.ccusagePath = writeCCUsageScript(t, `echo 'partial'; echo 'Error: unknown flag' >&2; exit 1`)
+	_, err = service.runCCUsage([]string{"daily", "--json"})
+	require.Error(t, err)
+
+	data, err := os.ReadFile(dump)
+	require.NoError(t, err)
+	text := string(data)
+	assert.Contains(t, text, "=== 2025-03-14T12:00:00Z ")
+	assert.Contains(t, text, " daily --json (ok) ===\n"+`{"daily":[{"date":"2025-03-14"`+"\n")
+	assert.Contains(t, text, " daily --json (exit status 1) ===\npartial\n--- stderr ---\nError: unknown flag\n")
+}
+
+func TestUsageService_DumpFileStartsOverWhenLarge(t *testing.T) {
+	dump := filepath.Join(t.TempDir(), "ccusage-dump.log")
+	require.NoError(t, os.WriteFile(dump, make([]byte, maxDumpFileSize+1), 0o600))
+
+	service := newTestUsageService()
+	service.dumpFile = dump
+	service.dumpCCUsageOutput([]string{"daily", "--json"}, []byte("{}"), nil, nil)
+
+	info, err := os.Stat(dump)
+	require.NoError(t, err)
+	assert.Less(t, info.Size(), int64(1024))
+}
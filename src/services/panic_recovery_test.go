@@ -0,0 +1,66 @@
+package services
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+// panickyClock panics on the first Now call after being armed.
+type panickyClock struct {
+	now   time.Time
+	armed atomic.Bool
+}
+
+func (c *panickyClock) Now() time.Time {
+	if c.armed.CompareAndSwap(true, false) {
+		panic("clock exploded")
+	}
+	return c.now
+}
+
+func TestUsageService_PollingLoopRestartsAfterPanic(t *testing.T) {
+	service := newTestUsageService()
+	clock := &panickyClock{now: time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)}
+	service.SetClock(clock)
+	service.ccusagePath = writeCCUsageScript(t, `echo '{"daily":[{"date":"2025-03-14","totalTokens":1,"totalCost":1}]}'`)
+
+	var polls atomic.Int32
+	require.NoError(t, service.StartPolling(60, func(*models.UsageState) { polls.Add(1) }))
+	defer service.StopPolling()
+	wake := service.signalWake
+
+	clock.armed.Store(true)
+	wake()
+	require.Eventually(t, func() bool { return service.PollingStatus() == PollingDegraded }, time.Second, 10*time.Millisecond)
+	assert.Zero(t, polls.Load())
+
+	wake()
+	require.Eventually(t, func() bool { return polls.Load() == 1 }, 5*time.Second, 10*time.Millisecond,
+		"the restarted loop keeps polling")
+	assert.Equal(t, PollingRunning, service.PollingStatus())
+}
+
+func TestUsageService_CallbackPanicIsContained(t *testing.T) {
+	service := newTestUsageService()
+	service.SetClock(fixedClock{now: time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)})
+	service.ccusagePath = writeCCUsageScript(t, `echo '{"daily":[{"date":"2025-03-14","totalTokens":1,"totalCost":1.5}]}'`)
+	service.updateCallback = func(*models.UsageState) { panic("render failed") }
+
+	state, err := service.Refresh()
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, state.DailyCost)
+}
+
+func TestRunGuarded(t *testing.T) {
+	service := newTestUsageService()
+	assert.False(t, service.runGuarded("test", func() {}))
+	assert.True(t, service.runGuarded("test", func() { panic("boom") }))
+	var m map[string]int
+	assert.True(t, service.runGuarded("test", func() { m["x"] = 1 }), "runtime errors are recovered too")
+}
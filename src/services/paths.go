@@ -0,0 +1,172 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/adrg/xdg"
+)
+
+// appDirName is the directory the app keeps its files in under each base
+// directory.
+const appDirName = "cc-dailyuse-bar"
+
+// PathResolver locates the app's directories under the XDG base
+// directories, with the xdg package's fallbacks where they're unset
+// (~/.config, ~/Library/Application Support, %LOCALAPPDATA%, and so on).
+type PathResolver struct {
+	configHome string
+	dataHome   string
+	cacheHome  string
+	runtimeDir string
+	logDir     string // "" where the app has no log directory of its own
+	configFile string // set by --config
+}
+
+// defaultPaths is the resolver for the platform's base directories.
+var defaultPaths = NewPathResolver()
+
+// DefaultPaths returns the resolver for the platform's base directories.
+func DefaultPaths() *PathResolver {
+	return defaultPaths
+}
+
+// NewPathResolver creates a resolver for the platform's base directories.
+// On macOS, logs from the launch agent go to ~/Library/Logs.
+func NewPathResolver() *PathResolver {
+	p := &PathResolver{
+		configHome: xdg.ConfigHome,
+		dataHome:   xdg.DataHome,
+		cacheHome:  xdg.CacheHome,
+		runtimeDir: xdg.RuntimeDir,
+	}
+	if home, err := os.UserHomeDir(); err == nil && runtime.GOOS == "darwin" {
+		p.logDir = filepath.Join(home, "Library", "Logs", appDirName)
+	}
+	return p
+}
+
+// NewPathResolverAt creates a resolver whose base directories are the
+// config, data, cache, and runtime directories under root, e.g. for tests.
+func NewPathResolverAt(root string) *PathResolver {
+	return &PathResolver{
+		configHome: filepath.Join(root, "config"),
+		dataHome:   filepath.Join(root, "data"),
+		cacheHome:  filepath.Join(root, "cache"),
+		runtimeDir: filepath.Join(root, "runtime"),
+	}
+}
+
+// WithConfigFile returns a copy of p whose config directory is the one
+// holding path, as given with --config; "" keeps the default.
+func (p *PathResolver) WithConfigFile(path string) *PathResolver {
+	resolved := *p
+	resolved.configFile = path
+	return &resolved
+}
+
+// ConfigDir holds config.yaml and its overlay.
+func (p *PathResolver) ConfigDir() string {
+	if p.configFile != "" {
+		return filepath.Dir(p.configFile)
+	}
+	return filepath.Join(p.configHome, appDirName)
+}
+
+// DataDir holds history, archives, exports, and the ledger state.
+func (p *PathResolver) DataDir() string {
+	return filepath.Join(p.dataHome, appDirName)
+}
+
+// CacheDir holds cached ccusage output and the last status.
+func (p *PathResolver) CacheDir() string {
+	return filepath.Join(p.cacheHome, appDirName)
+}
+
+// RuntimeDir holds the control socket and the status files widgets read.
+func (p *PathResolver) RuntimeDir() string {
+	return filepath.Join(p.runtimeDir, appDirName)
+}
+
+// LogDir holds the launch agent's logs on macOS; it is "" elsewhere.
+func (p *PathResolver) LogDir() string {
+	return p.logDir
+}
+
+// AppDir is one of the app's directories and what it is for.
+type AppDir struct {
+	Role string // config, data, cache, runtime, or logs
+	Path string
+}
+
+// Dirs lists the app's directories.
+func (p *PathResolver) Dirs() []AppDir {
+	dirs := []AppDir{
+		{"config", p.ConfigDir()},
+		{"data", p.DataDir()},
+		{"cache", p.CacheDir()},
+		{"runtime", p.RuntimeDir()},
+	}
+	if p.logDir != "" {
+		dirs = append(dirs, AppDir{"logs", p.logDir})
+	}
+	return dirs
+}
+
+// DirIssue is a permission problem with one of the app's directories.
+type DirIssue struct {
+	Dir     AppDir
+	Mode    os.FileMode
+	Problem string // e.g. "writable by group and others"
+	Fixable bool   // Fix can correct it: we own the directory
+}
+
+// CheckPermissions reports the app's directories that other users could
+// tamper with: ones writable by the group or others, and ones owned by
+// someone else. Missing directories are fine, as the app creates them.
+// Windows ACLs don't map onto these bits, so it reports none there.
+func (p *PathResolver) CheckPermissions() []DirIssue {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	var issues []DirIssue
+	for _, dir := range p.Dirs() {
+		info, err := os.Stat(dir.Path)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		mode := info.Mode().Perm()
+		owned := true
+		if uid, ok := fileOwner(info); ok && uid != os.Getuid() {
+			owned = false
+			issues = append(issues, DirIssue{Dir: dir, Mode: mode, Problem: "owned by another user"})
+		}
+		if problem := writableBy(mode); problem != "" {
+			issues = append(issues, DirIssue{Dir: dir, Mode: mode, Problem: problem, Fixable: owned})
+		}
+	}
+	return issues
+}
+
+// writableBy describes who besides the owner can write to a directory
+// with mode, or returns "" when nobody can.
+func writableBy(mode os.FileMode) string {
+	var who []string
+	if mode&0o020 != 0 {
+		who = append(who, "group")
+	}
+	if mode&0o002 != 0 {
+		who = append(who, "others")
+	}
+	if len(who) == 0 {
+		return ""
+	}
+	return "writable by " + strings.Join(who, " and ")
+}
+
+// Fix removes group and world write access from the directory.
+func (i DirIssue) Fix() error {
+	return os.Chmod(i.Dir.Path, i.Mode&^0o022)
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathResolver_Dirs(t *testing.T) {
+	root := t.TempDir()
+	paths := NewPathResolverAt(root)
+	assert.Equal(t, []AppDir{
+		{"config", filepath.Join(root, "config", "cc-dailyuse-bar")},
+		{"data", filepath.Join(root, "data", "cc-dailyuse-bar")},
+		{"cache", filepath.Join(root, "cache", "cc-dailyuse-bar")},
+		{"runtime", filepath.Join(root, "runtime", "cc-dailyuse-bar")},
+	}, paths.Dirs())
+
+	custom := paths.WithConfigFile(filepath.Join(root, "dotfiles", "cc.yaml"))
+	assert.Equal(t, filepath.Join(root, "dotfiles"), custom.ConfigDir(), "--config moves the config directory")
+	assert.Equal(t, filepath.Join(root, "config", "cc-dailyuse-bar"), paths.ConfigDir(), "the original is unchanged")
+}
+
+func TestPathResolver_CheckPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits don't apply on Windows")
+	}
+	paths := NewPathResolverAt(t.TempDir())
+	assert.Empty(t, paths.CheckPermissions(), "missing directories are fine")
+
+	require.NoError(t, os.MkdirAll(paths.ConfigDir(), 0o700))
+	require.NoError(t, os.MkdirAll(paths.DataDir(), 0o700))
+	require.NoError(t, os.Chmod(paths.DataDir(), 0o777))
+	require.NoError(t, os.MkdirAll(paths.CacheDir(), 0o700))
+	require.NoError(t, os.Chmod(paths.CacheDir(), 0o775))
+
+	issues := paths.CheckPermissions()
+	require.Len(t, issues, 2)
+	assert.Equal(t, "data", issues[0].Dir.Role)
+	assert.Equal(t, "writable by group and others", issues[0].Problem)
+	assert.True(t, issues[0].Fixable)
+	assert.Equal(t, "writable by group", issues[1].Problem)
+
+	for _, issue := range issues {
+		require.NoError(t, issue.Fix())
+	}
+	assert.Empty(t, paths.CheckPermissions())
+	info, err := os.Stat(paths.DataDir())
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm(), "only write access is removed")
+}
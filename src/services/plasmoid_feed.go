@@ -0,0 +1,91 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// PlasmoidSnapshot is one entry of the KDE Plasma widget feed: the status
+// file's snapshot plus hints for drawing it in the user's Plasma theme.
+// Its JSON shape is a public interface documented in the README.
+type PlasmoidSnapshot struct {
+	models.StatusSnapshot
+	Icon           string `json:"icon"`            // Breeze icon name, e.g. "data-warning"
+	IconColor      string `json:"icon_color"`      // Kirigami.Theme color role: positive, neutral, negative, or disabled
+	RefreshSeconds int    `json:"refresh_seconds"` // how often the feed is rewritten
+}
+
+// plasmoidHints maps a snapshot status to its theme icon and color role.
+// Both are names rather than colors, so the widget follows light and dark
+// themes without the feed knowing which is active.
+var plasmoidHints = map[string][2]string{
+	"green":   {"data-success", "positive"},
+	"yellow":  {"data-warning", "neutral"},
+	"red":     {"data-error", "negative"},
+	"unknown": {"data-information", "disabled"},
+}
+
+// NewPlasmoidSnapshot summarises state for the Plasma widget feed.
+func NewPlasmoidSnapshot(state *models.UsageState, config *models.Config) PlasmoidSnapshot {
+	snapshot := PlasmoidSnapshot{
+		StatusSnapshot: models.NewStatusSnapshot(state, config),
+		RefreshSeconds: config.UpdateInterval,
+	}
+	hints, ok := plasmoidHints[snapshot.Status]
+	if !ok {
+		hints = plasmoidHints["unknown"]
+	}
+	snapshot.Icon, snapshot.IconColor = hints[0], hints[1]
+	return snapshot
+}
+
+// PlasmoidFeed keeps a JSON file up to date for a KDE Plasma widget, for
+// `run --plasmoid-feed` on desktops where a tray icon is unwanted. Each
+// write replaces the file atomically.
+type PlasmoidFeed struct {
+	path string
+}
+
+// DefaultPlasmoidFeedPath is plasmoid.json beside the status file.
+func DefaultPlasmoidFeedPath() string {
+	return filepath.Join(DefaultPaths().RuntimeDir(), "plasmoid.json")
+}
+
+// NewPlasmoidFeedAt creates a PlasmoidFeed backed by path.
+func NewPlasmoidFeedAt(path string) *PlasmoidFeed {
+	return &PlasmoidFeed{path: path}
+}
+
+// Path returns the file location.
+func (pf *PlasmoidFeed) Path() string {
+	return pf.path
+}
+
+// Write replaces the file with snapshot.
+func (pf *PlasmoidFeed) Write(snapshot PlasmoidSnapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to encode plasmoid feed")
+	}
+	if err := os.MkdirAll(filepath.Dir(pf.path), 0o700); err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to create plasmoid feed directory")
+	}
+	if err := lib.WriteFileAtomic(pf.path, append(data, '\n')); err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to write plasmoid feed")
+	}
+	return nil
+}
+
+// Remove deletes the file so the widget can tell the feed has stopped. A
+// missing file is not an error.
+func (pf *PlasmoidFeed) Remove() error {
+	if err := os.Remove(pf.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to remove plasmoid feed")
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+func TestNewPlasmoidSnapshot_Hints(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     *models.UsageState
+		wantIcon  string
+		wantColor string
+	}{
+		{"green", &models.UsageState{Status: models.Green, IsAvailable: true}, "data-success", "positive"},
+		{"yellow", &models.UsageState{Status: models.Yellow, IsAvailable: true}, "data-warning", "neutral"},
+		{"red", &models.UsageState{Status: models.Red, IsAvailable: true}, "data-error", "negative"},
+		{"unavailable", &models.UsageState{Status: models.Red}, "data-information", "disabled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := models.ConfigDefaults()
+			snapshot := NewPlasmoidSnapshot(tt.state, config)
+			assert.Equal(t, tt.wantIcon, snapshot.Icon)
+			assert.Equal(t, tt.wantColor, snapshot.IconColor)
+			assert.Equal(t, config.UpdateInterval, snapshot.RefreshSeconds)
+		})
+	}
+}
+
+func TestPlasmoidFeed_WriteAndRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cc-dailyuse-bar", "plasmoid.json")
+	pf := NewPlasmoidFeedAt(path)
+	assert.Equal(t, path, pf.Path())
+
+	state := &models.UsageState{DailyCost: 12.4, Status: models.Yellow, IsAvailable: true}
+	require.NoError(t, pf.Write(NewPlasmoidSnapshot(state, models.ConfigDefaults())))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, "yellow", got["status"], "status file fields are inlined")
+	assert.Equal(t, "CC 🟡 $12.40", got["title"])
+	assert.Equal(t, "data-warning", got["icon"])
+	assert.Equal(t, "neutral", got["icon_color"])
+	assert.EqualValues(t, models.ConfigDefaults().UpdateInterval, got["refresh_seconds"])
+
+	require.NoError(t, pf.Remove())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, pf.Remove(), "removing a missing file is fine")
+}
+
+func TestDefaultPlasmoidFeedPath(t *testing.T) {
+	assert.Equal(t, "plasmoid.json", filepath.Base(DefaultPlasmoidFeedPath()))
+	assert.Equal(t, filepath.Dir(DefaultStatusFilePath()), filepath.Dir(DefaultPlasmoidFeedPath()))
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"math"
+	"time"
+)
+
+// pollReliabilityWindow is how far back poll outcomes count toward the
+// success rate.
+const pollReliabilityWindow = 24 * time.Hour
+
+// minReliabilityPolls is how many polls the window needs before a low
+// success rate is worth a warning; one early failure isn't a trend.
+const minReliabilityPolls = 10
+
+type pollOutcome struct {
+	at time.Time
+	ok bool
+}
+
+// recordPollOutcomeLocked notes whether a poll succeeded and forgets
+// outcomes older than pollReliabilityWindow.
+func (us *UsageService) recordPollOutcomeLocked(now time.Time, ok bool) {
+	cutoff := now.Add(-pollReliabilityWindow)
+	drop := 0
+	for drop < len(us.pollOutcomes) && us.pollOutcomes[drop].at.Before(cutoff) {
+		drop++
+	}
+	us.pollOutcomes = append(us.pollOutcomes[:0], us.pollOutcomes[drop:]...)
+	us.pollOutcomes = append(us.pollOutcomes, pollOutcome{at: now, ok: ok})
+}
+
+// PollReliability reports the percentage of polls in the last 24 hours
+// that succeeded, rounded down so any failure shows below 100, and how
+// many polls that covers.
+func (us *UsageService) PollReliability() (percent, polls int) {
+	us.mutex.RLock()
+	defer us.mutex.RUnlock()
+
+	cutoff := us.clock.Now().Add(-pollReliabilityWindow)
+	succeeded := 0
+	for _, outcome := range us.pollOutcomes {
+		if outcome.at.Before(cutoff) {
+			continue
+		}
+		polls++
+		if outcome.ok {
+			succeeded++
+		}
+	}
+	if polls == 0 {
+		return 0, 0
+	}
+	return int(math.Floor(float64(succeeded) * 100 / float64(polls))), polls
+}
+
+// FailingSince returns when the current run of failed polls began, or the
+// zero time when the last poll succeeded or none has run. Runs longer than
+// pollReliabilityWindow are reported from the window's start.
+func (us *UsageService) FailingSince() time.Time {
+	us.mutex.RLock()
+	defer us.mutex.RUnlock()
+
+	var since time.Time
+	for i := len(us.pollOutcomes) - 1; i >= 0 && !us.pollOutcomes[i].ok; i-- {
+		since = us.pollOutcomes[i].at
+	}
+	return since
+}
+
+// PollReliabilityLow reports whether percent of polls succeeding is below
+// the threshold percent, given enough polls to judge. A threshold of 0
+// never is.
+func PollReliabilityLow(percent, polls, threshold int) bool {
+	return threshold > 0 && polls >= minReliabilityPolls && percent < threshold
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageService_PollReliability(t *testing.T) {
+	service := newTestUsageService()
+	clock := &fixedClock{now: time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)}
+	service.SetClock(clock)
+
+	percent, polls := service.PollReliability()
+	assert.Equal(t, []int{0, 0}, []int{percent, polls})
+
+	service.ccusagePath = writeCCUsageScript(t, `echo '{"daily":[{"date":"2025-03-14","totalTokens":10,"totalCost":1}]}'`)
+	for i := 0; i < 2; i++ {
+		_, _ = service.Refresh()
+	}
+	service.ccusagePath = writeCCUsageScript(t, `echo '{"daily":[]}'`)
+	_, err := service.Refresh()
+	assert.Error(t, err)
+	service.ccusagePath = filepath.Join(t.TempDir(), "missing")
+	_, _ = service.Refresh()
+
+	percent, polls = service.PollReliability()
+	assert.Equal(t, 4, polls)
+	assert.Equal(t, 75, percent, "no data for today still counts as a working poll")
+
+	clock.now = clock.now.Add(pollReliabilityWindow + time.Minute)
+	_, _ = service.Refresh()
+	percent, polls = service.PollReliability()
+	assert.Equal(t, []int{0, 1}, []int{percent, polls}, "polls older than a day drop out")
+	assert.Len(t, service.pollOutcomes, 1)
+}
+
+func TestUsageService_FailingSince(t *testing.T) {
+	service := newTestUsageService()
+	start := time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)
+	clock := &fixedClock{now: start}
+	service.SetClock(clock)
+	assert.True(t, service.FailingSince().IsZero(), "no polls yet")
+
+	service.ccusagePath = filepath.Join(t.TempDir(), "missing")
+	_, _ = service.Refresh()
+	clock.now = start.Add(time.Minute)
+	_, _ = service.Refresh()
+	assert.Equal(t, start, service.FailingSince())
+
+	service.ccusagePath = writeCCUsageScript(t, `echo '{"daily":[{"date":"2025-03-14","totalTokens":10,"totalCost":1}]}'`)
+	_, _ = service.Refresh()
+	assert.True(t, service.FailingSince().IsZero(), "a working poll ends the run")
+}
+
+func TestPollReliabilityLow(t *testing.T) {
+	tests := []struct {
+		name                      string
+		percent, polls, threshold int
+		want                      bool
+	}{
+		{"below threshold", 80, 20, 90, true},
+		{"at threshold", 90, 20, 90, false},
+		{"too few polls", 0, minReliabilityPolls - 1, 90, false},
+		{"disabled", 0, 20, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, PollReliabilityLow(tt.percent, tt.polls, tt.threshold))
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"fmt"
+
+	"cc-dailyuse-bar/src/lib"
+)
+
+// PollingStatus is the lifecycle stage of a UsageService's poller.
+type PollingStatus int
+
+// Polling lifecycle stages. A poller goes Stopped → Starting → Running,
+// moves between Running, Paused, and Degraded while it runs, and leaves
+// through Stopping back to Stopped.
+const (
+	PollingStopped  PollingStatus = iota
+	PollingStarting               // StartPolling is setting up the ticker and watcher
+	PollingRunning                // polling, and the last poll succeeded
+	PollingPaused                 // scheduled polls are suspended by quiet_hours
+	PollingDegraded               // polling, but the last poll failed
+	PollingStopping               // StopPolling is tearing down
+)
+
+// pollingTransitions lists the stages each stage may move to.
+var pollingTransitions = map[PollingStatus][]PollingStatus{
+	PollingStopped:  {PollingStarting},
+	PollingStarting: {PollingRunning, PollingStopping},
+	PollingRunning:  {PollingPaused, PollingDegraded, PollingStopping},
+	PollingPaused:   {PollingRunning, PollingDegraded, PollingStopping},
+	PollingDegraded: {PollingRunning, PollingPaused, PollingStopping},
+	PollingStopping: {PollingStopped},
+}
+
+// String returns the lower-case stage name, e.g. "running".
+func (s PollingStatus) String() string {
+	switch s {
+	case PollingStopped:
+		return "stopped"
+	case PollingStarting:
+		return "starting"
+	case PollingRunning:
+		return "running"
+	case PollingPaused:
+		return "paused"
+	case PollingDegraded:
+		return "degraded"
+	case PollingStopping:
+		return "stopping"
+	default:
+		return fmt.Sprintf("PollingStatus(%d)", int(s))
+	}
+}
+
+// MarshalText renders the stage name, so JSON carries "running" rather
+// than a number.
+func (s PollingStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Active reports whether the poller is running in any form: Running,
+// Paused, or Degraded.
+func (s PollingStatus) Active() bool {
+	return s == PollingRunning || s == PollingPaused || s == PollingDegraded
+}
+
+// CanTransitionTo reports whether the lifecycle allows moving from s to
+// next. Staying in the same stage is always allowed.
+func (s PollingStatus) CanTransitionTo(next PollingStatus) bool {
+	if s == next {
+		return true
+	}
+	for _, allowed := range pollingTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// PollingStatus returns the poller's current lifecycle stage.
+func (us *UsageService) PollingStatus() PollingStatus {
+	us.mutex.RLock()
+	defer us.mutex.RUnlock()
+	return us.pollingStatus
+}
+
+// setPollingStatusLocked moves the poller to next, rejecting transitions
+// the lifecycle doesn't allow and leaving the stage unchanged.
+func (us *UsageService) setPollingStatusLocked(next PollingStatus) error {
+	current := us.pollingStatus
+	if !current.CanTransitionTo(next) {
+		us.logger.Warn("Rejected polling status transition", map[string]interface{}{
+			"from": current.String(),
+			"to":   next.String(),
+		})
+		return lib.ValidationError(fmt.Sprintf("polling cannot go from %s to %s", current, next))
+	}
+	if current != next {
+		us.logger.Debug("Polling status changed", map[string]interface{}{
+			"from": current.String(),
+			"to":   next.String(),
+		})
+	}
+	us.pollingStatus = next
+	return nil
+}
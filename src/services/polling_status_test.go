@@ -0,0 +1,118 @@
+package services
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+func TestPollingStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to PollingStatus
+		want     bool
+	}{
+		{PollingStopped, PollingStarting, true},
+		{PollingStopped, PollingRunning, false},
+		{PollingStarting, PollingRunning, true},
+		{PollingRunning, PollingPaused, true},
+		{PollingRunning, PollingDegraded, true},
+		{PollingDegraded, PollingRunning, true},
+		{PollingPaused, PollingRunning, true},
+		{PollingPaused, PollingStarting, false},
+		{PollingRunning, PollingStopped, false}, // must pass through Stopping
+		{PollingStopping, PollingStopped, true},
+		{PollingStopping, PollingRunning, false},
+		{PollingRunning, PollingRunning, true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.from.CanTransitionTo(tt.to), "%s → %s", tt.from, tt.to)
+	}
+}
+
+func TestPollingStatus_String(t *testing.T) {
+	assert.Equal(t, "degraded", PollingDegraded.String())
+	assert.Equal(t, "PollingStatus(42)", PollingStatus(42).String())
+
+	data, err := json.Marshal(map[string]PollingStatus{"polling": PollingPaused})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"polling":"paused"}`, string(data))
+
+	assert.True(t, PollingDegraded.Active())
+	assert.False(t, PollingStopping.Active())
+}
+
+func TestUsageService_SetPollingStatusRejectsInvalid(t *testing.T) {
+	service := newTestUsageService()
+	service.mutex.Lock()
+	err := service.setPollingStatusLocked(PollingRunning)
+	service.mutex.Unlock()
+
+	assert.ErrorContains(t, err, "polling cannot go from stopped to running")
+	assert.Equal(t, PollingStopped, service.PollingStatus())
+}
+
+func TestUsageService_PollingLifecycle(t *testing.T) {
+	service := newTestUsageService()
+	service.SetClock(fixedClock{now: time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)})
+	service.ccusagePath = writeCCUsageScript(t, "exit 1")
+	assert.Equal(t, PollingStopped, service.PollingStatus())
+
+	require.NoError(t, service.StartPolling(60, nil))
+	assert.Equal(t, PollingRunning, service.PollingStatus())
+
+	_, err := service.pollOnce(1)
+	require.Error(t, err)
+	assert.Equal(t, PollingDegraded, service.PollingStatus())
+
+	service.ccusagePath = writeCCUsageScript(t, `echo '{"daily":[{"date":"2025-03-14","totalTokens":1,"totalCost":1}]}'`)
+	_, err = service.pollOnce(1)
+	require.NoError(t, err)
+	assert.Equal(t, PollingRunning, service.PollingStatus())
+
+	service.StopPolling()
+	assert.Equal(t, PollingStopped, service.PollingStatus())
+	service.StopPolling() // no-op when already stopped
+	assert.Equal(t, PollingStopped, service.PollingStatus())
+
+	_, err = service.Refresh()
+	require.NoError(t, err)
+	assert.Equal(t, PollingStopped, service.PollingStatus(), "refreshes don't start the poller")
+}
+
+func TestUsageService_StopPollingWaitsForLoops(t *testing.T) {
+	service := newTestUsageService()
+	service.ccusagePath = writeCCUsageScript(t, "exit 1")
+
+	started := make(chan struct{}, 1)
+	var finished atomic.Bool
+	require.NoError(t, service.StartPolling(60, func(*models.UsageState) {
+		signal(started)
+		time.Sleep(100 * time.Millisecond)
+		finished.Store(true)
+	}))
+	service.StartDailyResetMonitor()
+	service.StartDailyResetMonitor() // already running: no second loop
+
+	service.signalWake()
+	<-started
+
+	service.StopPolling()
+	assert.True(t, finished.Load(), "StopPolling returned before the in-flight poll finished")
+	assert.Nil(t, service.poll.Load())
+	assert.Nil(t, service.resetCancel)
+
+	// Restarting after a stop gets fresh channels and stops cleanly again.
+	finished.Store(false)
+	require.NoError(t, service.StartPolling(60, func(*models.UsageState) { finished.Store(true) }))
+	service.signalWake()
+	require.Eventually(t, finished.Load, time.Second, 10*time.Millisecond)
+	service.StopPolling()
+	service.StopPolling()
+	assert.Equal(t, PollingStopped, service.PollingStatus())
+}
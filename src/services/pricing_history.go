@@ -0,0 +1,217 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+)
+
+// pricingChangeTolerance is how far a finished day's cost may move, with
+// its tokens unchanged, before it counts as a pricing change rather than
+// rounding noise.
+const pricingChangeTolerance = 0.005
+
+// pricedDay is a finished day's totals as ccusage last reported them.
+type pricedDay struct {
+	Tokens int     `json:"tokens"`
+	Cost   float64 `json:"cost"`
+}
+
+// pricingState is what HistoryService knows about ccusage's prices,
+// persisted next to the history file. ccusage doesn't report a pricing
+// version, so a change is inferred when a finished day's cost moves while
+// its tokens stay put, and the version is the time that was first seen.
+type pricingState struct {
+	Version string               `json:"version"` // RFC 3339
+	Days    map[string]pricedDay `json:"days"`
+	Pending bool                 `json:"recalculate_pending,omitempty"` // rows priced with an older version remain
+}
+
+// ObservePricing compares the finished days in a ccusage daily report
+// with the previous report, starting a new pricing version when ccusage
+// now prices the same tokens differently. Samples recorded from then on
+// carry the new version.
+func (hs *HistoryService) ObservePricing(days map[string]dayTotal) error {
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+
+	hs.loadPricingLocked()
+	now := hs.clock.Now()
+	today := now.Format("2006-01-02")
+
+	finished := map[string]pricedDay{}
+	var repriced []string
+	for date, day := range days {
+		if date >= today {
+			continue // today's cost moves as usage comes in
+		}
+		finished[date] = pricedDay{Tokens: day.Tokens, Cost: day.Cost}
+		if prev, ok := hs.pricing.Days[date]; ok && prev.Tokens == day.Tokens &&
+			math.Abs(prev.Cost-day.Cost) > pricingChangeTolerance {
+			repriced = append(repriced, date)
+		}
+	}
+
+	switch {
+	case hs.pricing.Version == "":
+		hs.pricing.Version = now.Format(time.RFC3339)
+	case len(repriced) > 0:
+		sort.Strings(repriced)
+		hs.logger.Warn("ccusage pricing changed; recorded history uses the old prices", map[string]interface{}{
+			"previous_pricing": hs.pricing.Version,
+			"pricing":          now.Format(time.RFC3339),
+			"repriced_days":    repriced,
+		})
+		hs.pricing.Version = now.Format(time.RFC3339)
+		hs.pricing.Pending = true
+	case maps.Equal(finished, hs.pricing.Days):
+		return nil
+	}
+
+	hs.pricing.Days = finished
+	return hs.savePricingLocked()
+}
+
+// PricingChangePending reports whether ccusage's prices changed since some
+// recorded history was priced, so RecalculateHistory would change it.
+func (hs *HistoryService) PricingChangePending() bool {
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+	hs.loadPricingLocked()
+	return hs.pricing.Pending
+}
+
+// RecalculateHistory reprices recorded history with ccusage's current
+// prices. fetch returns ccusage's daily report for a date range, e.g.
+// UsageService.DailyHistory. Each finished day with rows from an older
+// pricing version is scaled so it ends at the day's total as ccusage now
+// reports it, keeping the shape of the day. It returns the number of days
+// changed and logs each one with the pricing it used.
+func (hs *HistoryService) RecalculateHistory(fetch func(since, until time.Time) ([]CCUsageOutput, error)) (int, error) {
+	samples, err := hs.Samples(time.Time{})
+	if err != nil || len(samples) == 0 {
+		return 0, err
+	}
+	// Run ccusage before taking the lock so polls can keep recording.
+	reported, err := fetch(samples[0].Time, hs.now())
+	if err != nil {
+		return 0, err
+	}
+	totals := map[string]float64{}
+	for _, day := range reported {
+		totals[day.Date] = day.TotalCost
+	}
+
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+	hs.loadPricingLocked()
+	if samples, err = hs.readLocked(); err != nil {
+		return 0, err
+	}
+
+	today := hs.clock.Now().Format("2006-01-02")
+	type dayRows struct {
+		final    float64
+		versions []string
+	}
+	byDay := map[string]*dayRows{}
+	for _, s := range samples {
+		date := s.Time.Format("2006-01-02")
+		rows := byDay[date]
+		if rows == nil {
+			rows = &dayRows{}
+			byDay[date] = rows
+		}
+		rows.final = s.Cost
+		if s.Pricing != hs.pricing.Version && !slices.Contains(rows.versions, s.Pricing) {
+			rows.versions = append(rows.versions, s.Pricing)
+		}
+	}
+
+	factors := map[string]float64{}
+	for date, rows := range byDay {
+		total, ok := totals[date]
+		if date >= today || !ok || rows.final <= 0 || len(rows.versions) == 0 {
+			continue
+		}
+		factors[date] = total / rows.final
+		hs.logger.Info("Recalculated history day with current pricing", map[string]interface{}{
+			"date":        date,
+			"old_pricing": rows.versions, // "" for rows recorded before versions were tracked
+			"pricing":     hs.pricing.Version,
+			"old_cost":    rows.final,
+			"cost":        total,
+		})
+	}
+
+	if len(factors) > 0 {
+		for i, s := range samples {
+			if factor, ok := factors[s.Time.Format("2006-01-02")]; ok {
+				samples[i].Cost = s.Cost * factor
+				samples[i].Pricing = hs.pricing.Version
+			}
+		}
+		if err := hs.writeAllLocked(samples); err != nil {
+			return 0, lib.WrapError(err, lib.ErrCodeSystem, "failed to rewrite usage history")
+		}
+		if hs.last != nil {
+			last := samples[len(samples)-1]
+			hs.last = &last
+		}
+	}
+
+	hs.pricing.Pending = false
+	if err := hs.savePricingLocked(); err != nil {
+		return len(factors), err
+	}
+	return len(factors), nil
+}
+
+func (hs *HistoryService) pricingPath() string {
+	return filepath.Join(filepath.Dir(hs.path), "pricing.json")
+}
+
+func (hs *HistoryService) loadPricingLocked() {
+	if hs.pricingLoaded {
+		return
+	}
+	hs.pricingLoaded = true
+	data, err := os.ReadFile(hs.pricingPath())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			hs.logger.Warn("Failed to read pricing state", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &hs.pricing); err != nil {
+		hs.logger.Warn("Ignoring malformed pricing state", map[string]interface{}{
+			"error": err.Error(),
+		})
+		hs.pricing = pricingState{}
+	}
+}
+
+func (hs *HistoryService) savePricingLocked() error {
+	data, err := json.Marshal(hs.pricing)
+	if err != nil {
+		return err
+	}
+	path := hs.pricingPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to save pricing state")
+	}
+	if err := lib.WriteFileAtomic(path, data); err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to save pricing state")
+	}
+	return nil
+}
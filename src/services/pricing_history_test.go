@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+func TestHistoryService_ObservePricing(t *testing.T) {
+	dir := t.TempDir()
+	hs := NewHistoryServiceAt(filepath.Join(dir, "history.jsonl"))
+	first := time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)
+	clock := &fixedClock{now: first}
+	hs.SetClock(clock)
+
+	report := map[string]dayTotal{
+		"2025-03-13": {Cost: 10, Tokens: 1000, Found: true},
+		"2025-03-14": {Cost: 2, Tokens: 100, Found: true},
+	}
+	require.NoError(t, hs.ObservePricing(report))
+	require.NoError(t, hs.Record(&models.UsageState{IsAvailable: true, DailyCost: 2}))
+	assert.False(t, hs.PricingChangePending())
+
+	// New usage today and yesterday's tokens growing aren't price changes.
+	clock.now = first.Add(time.Hour)
+	report["2025-03-14"] = dayTotal{Cost: 5, Tokens: 300, Found: true}
+	require.NoError(t, hs.ObservePricing(report))
+	report["2025-03-13"] = dayTotal{Cost: 11, Tokens: 1100, Found: true}
+	require.NoError(t, hs.ObservePricing(report))
+	assert.False(t, hs.PricingChangePending())
+
+	// The same tokens at a new price are.
+	changed := first.Add(2 * time.Hour)
+	clock.now = changed
+	report["2025-03-13"] = dayTotal{Cost: 8.8, Tokens: 1100, Found: true}
+	require.NoError(t, hs.ObservePricing(report))
+	assert.True(t, hs.PricingChangePending())
+	require.NoError(t, hs.Record(&models.UsageState{IsAvailable: true, DailyCost: 2}))
+
+	samples, err := hs.Samples(time.Time{})
+	require.NoError(t, err)
+	require.Len(t, samples, 2, "a new pricing version is recorded even with the same cost")
+	assert.Equal(t, first.Format(time.RFC3339), samples[0].Pricing)
+	assert.Equal(t, changed.Format(time.RFC3339), samples[1].Pricing)
+
+	// The state survives a restart.
+	reopened := NewHistoryServiceAt(filepath.Join(dir, "history.jsonl"))
+	assert.True(t, reopened.PricingChangePending())
+}
+
+func TestHistoryService_RecalculateHistory(t *testing.T) {
+	hs := NewHistoryServiceAt(filepath.Join(t.TempDir(), "history.jsonl"))
+	clock := &fixedClock{}
+	hs.SetClock(clock)
+
+	record := func(at time.Time, cost float64) {
+		clock.now = at
+		require.NoError(t, hs.Record(&models.UsageState{IsAvailable: true, DailyCost: cost}))
+	}
+	day := func(d, h int) time.Time { return time.Date(2025, 3, d, h, 0, 0, 0, time.Local) }
+
+	record(day(12, 10), 2) // before pricing was tracked
+	record(day(12, 18), 8)
+	clock.now = day(13, 9)
+	require.NoError(t, hs.ObservePricing(map[string]dayTotal{"2025-03-12": {Cost: 8, Tokens: 800}}))
+	record(day(13, 10), 5)
+	record(day(13, 18), 10)
+
+	clock.now = day(14, 9)
+	require.NoError(t, hs.ObservePricing(map[string]dayTotal{
+		"2025-03-12": {Cost: 8, Tokens: 800},
+		"2025-03-13": {Cost: 10, Tokens: 1000},
+	}))
+	clock.now = day(14, 10)
+	require.NoError(t, hs.ObservePricing(map[string]dayTotal{
+		"2025-03-12": {Cost: 6, Tokens: 800},
+		"2025-03-13": {Cost: 12, Tokens: 1000},
+	}))
+	require.True(t, hs.PricingChangePending())
+	record(day(14, 11), 3)
+
+	_, err := hs.RecalculateHistory(func(since, until time.Time) ([]CCUsageOutput, error) {
+		return nil, errors.New("ccusage failed")
+	})
+	assert.Error(t, err)
+	assert.True(t, hs.PricingChangePending(), "a failed recalculation stays on offer")
+
+	var since time.Time
+	days, err := hs.RecalculateHistory(func(s, until time.Time) ([]CCUsageOutput, error) {
+		since = s
+		return []CCUsageOutput{
+			{Date: "2025-03-12", TotalCost: 6},
+			{Date: "2025-03-13", TotalCost: 12},
+			{Date: "2025-03-14", TotalCost: 9}, // today: left alone
+		}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, days)
+	assert.True(t, since.Equal(day(12, 10)))
+	assert.False(t, hs.PricingChangePending())
+
+	samples, err := hs.Samples(time.Time{})
+	require.NoError(t, err)
+	var costs []float64
+	for _, s := range samples {
+		costs = append(costs, s.Cost)
+		assert.Equal(t, day(14, 10).Format(time.RFC3339), s.Pricing)
+	}
+	assert.InDeltaSlice(t, []float64{1.5, 6, 6, 12, 3}, costs, 1e-9)
+}
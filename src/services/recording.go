@@ -0,0 +1,69 @@
+package services
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+)
+
+// recordingLayout names each file in record_dir after the moment its
+// response arrived, sortable and without characters Windows rejects.
+const recordingLayout = "20060102T150405.000Z0700"
+
+// Recording is one raw `ccusage daily --json` response saved by record
+// mode.
+type Recording struct {
+	Time time.Time
+	Path string
+}
+
+// recordResponseLocked saves a daily report the pipeline is about to parse
+// into record_dir when it differs from the last one saved, so an idle day
+// of cache hits doesn't fill the directory. Failing to save only logs a
+// warning.
+func (us *UsageService) recordResponseLocked(output []byte) {
+	if us.recordDir == "" || bytes.Equal(output, us.lastRecorded) {
+		return
+	}
+	path := filepath.Join(us.recordDir, us.clock.Now().Format(recordingLayout)+".json")
+	err := os.MkdirAll(us.recordDir, 0o700)
+	if err == nil {
+		err = os.WriteFile(path, output, 0o600)
+	}
+	if err != nil {
+		us.logger.Warn("Failed to record ccusage response", map[string]interface{}{
+			"error": err.Error(),
+			"path":  path,
+		})
+		return
+	}
+	us.lastRecorded = append(us.lastRecorded[:0], output...)
+}
+
+// LoadRecordings lists the responses saved in dir by record mode, oldest
+// first. Files not named by record mode are ignored.
+func LoadRecordings(dir string) ([]Recording, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeConfig, "failed to read recordings")
+	}
+	var recordings []Recording
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		at, err := time.Parse(recordingLayout, strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			continue
+		}
+		recordings = append(recordings, Recording{Time: at, Path: filepath.Join(dir, name)})
+	}
+	sort.Slice(recordings, func(i, j int) bool { return recordings[i].Time.Before(recordings[j].Time) })
+	return recordings, nil
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageService_RecordsDistinctResponses(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "recordings")
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	report := `{"daily":[{"date":"2025-03-14","totalTokens":100,"totalCost":4}]}`
+
+	service := newTestUsageService()
+	service.recordDir = dir
+	service.ccusagePath = writeCCUsageScript(t, `[ "$1" = daily ] && echo '`+report+`'`)
+	service.SetClock(fixedClock{now})
+	_, err := service.UpdateUsage()
+	require.NoError(t, err)
+
+	// Unchanged output isn't saved again; a new one is.
+	service.SetClock(fixedClock{now.Add(time.Minute)})
+	service.mutex.Lock()
+	service.recordResponseLocked([]byte(report + "\n"))
+	service.mutex.Unlock()
+	service.SetClock(fixedClock{now.Add(2 * time.Minute)})
+	service.mutex.Lock()
+	service.recordResponseLocked([]byte(`{"daily":[]}`))
+	service.mutex.Unlock()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o600))
+	recordings, err := LoadRecordings(dir)
+	require.NoError(t, err)
+	require.Len(t, recordings, 2)
+	assert.True(t, recordings[0].Time.Equal(now))
+	assert.Equal(t, filepath.Join(dir, "20250314T120000.000Z.json"), recordings[0].Path)
+	assert.True(t, recordings[1].Time.Equal(now.Add(2*time.Minute)))
+
+	data, err := os.ReadFile(recordings[0].Path)
+	require.NoError(t, err)
+	assert.JSONEq(t, report, string(data))
+}
+
+func TestLoadRecordings_MissingDir(t *testing.T) {
+	_, err := LoadRecordings(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorContains(t, err, "failed to read recordings")
+}
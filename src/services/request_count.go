@@ -0,0 +1,152 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// usageLogEntry is the part of a Claude Code JSONL line needed to count
+// API requests.
+type usageLogEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	RequestID string    `json:"requestId"`
+	Message   struct {
+		ID    string          `json:"id"`
+		Usage json.RawMessage `json:"usage"`
+	} `json:"message"`
+}
+
+// requestCounter counts the API requests made on the current local day,
+// as recorded in Claude Code's usage logs. ccusage reports tokens and cost
+// but not requests, so this reads the logs directly: each line carrying
+// token usage is one request, de-duplicated by message and request ID the
+// same way ccusage de-duplicates its totals. It remembers how far it has
+// read each log and which requests it has seen, so a poll reads only what
+// was appended since the last one. The zero value is ready to use.
+type requestCounter struct {
+	mutex sync.Mutex
+	day   time.Time        // start of the day counted
+	dirs  []string         // the data directories counted
+	read  map[string]int64 // log path -> bytes counted
+	seen  map[string]bool  // message and request ID pairs counted
+	count int
+}
+
+// reset forgets the counts, to start over on day for dirs.
+func (rc *requestCounter) reset(dirs []string, day time.Time) {
+	rc.day, rc.dirs = day, slices.Clone(dirs)
+	rc.read = map[string]int64{}
+	rc.seen = map[string]bool{}
+	rc.count = 0
+}
+
+// Count returns the requests made on now's local day in the logs under
+// dataDirs, and when any log was last written, for telling whether Claude
+// Code is busy. Logs last written before today are skipped. A new day, a
+// change of directories, or a log that shrank starts the count over.
+func (rc *requestCounter) Count(dataDirs []string, now time.Time) (int, time.Time) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if !rc.day.Equal(dayStart) || !slices.Equal(rc.dirs, dataDirs) || rc.read == nil {
+		rc.reset(dataDirs, dayStart)
+	}
+	lastWrite, rewritten := rc.scan(dataDirs, dayStart)
+	if rewritten {
+		rc.reset(dataDirs, dayStart)
+		lastWrite, _ = rc.scan(dataDirs, dayStart)
+	}
+	return rc.count, lastWrite
+}
+
+// scan counts what was appended to the logs under dataDirs since the last
+// scan. It reports whether a log is shorter than what was counted from it,
+// i.e. it was rewritten and the counts are no longer right.
+func (rc *requestCounter) scan(dataDirs []string, dayStart time.Time) (lastWrite time.Time, rewritten bool) {
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	for _, dir := range dataDirs {
+		_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".jsonl") {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil || info.ModTime().Before(dayStart) {
+				return nil
+			}
+			if info.ModTime().After(lastWrite) {
+				lastWrite = info.ModTime()
+			}
+			offset := rc.read[path]
+			switch {
+			case info.Size() < offset:
+				rewritten = true
+				return filepath.SkipAll
+			case info.Size() == offset:
+				return nil
+			}
+			count, read := countFileRequests(path, offset, dayStart, dayEnd, rc.seen)
+			rc.count += count
+			rc.read[path] = read
+			return nil
+		})
+		if rewritten {
+			return lastWrite, true
+		}
+	}
+	return lastWrite, false
+}
+
+// countFileRequests counts the requests in path from offset on, returning
+// them and the offset to continue from: the end of the last complete line,
+// or of a final unterminated line that already holds a whole request.
+func countFileRequests(path string, offset int64, dayStart, dayEnd time.Time, seen map[string]bool) (int, int64) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, offset
+	}
+	defer file.Close()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0, offset
+	}
+
+	count := 0
+	// Lines holding tool output can run to megabytes, beyond what
+	// bufio.Scanner accepts by default.
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		counted := false
+		if bytes.Contains(line, []byte(`"usage"`)) {
+			var entry usageLogEntry
+			if json.Unmarshal(line, &entry) == nil && hasUsage(entry.Message.Usage) {
+				counted = true
+				if !entry.Timestamp.Before(dayStart) && entry.Timestamp.Before(dayEnd) {
+					key := entry.Message.ID + ":" + entry.RequestID
+					if entry.Message.ID == "" || entry.RequestID == "" || !seen[key] {
+						seen[key] = true
+						count++
+					}
+				}
+			}
+		}
+		if err == nil || counted {
+			offset += int64(len(line))
+		}
+		if err != nil {
+			return count, offset
+		}
+	}
+}
+
+func hasUsage(usage json.RawMessage) bool {
+	return len(usage) > 0 && string(usage) != "null"
+}
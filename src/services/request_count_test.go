@@ -0,0 +1,86 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountRequests(t *testing.T) {
+	now := time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	session := filepath.Join(dir, "proj", "session.jsonl")
+	require.NoError(t, os.MkdirAll(filepath.Dir(session), 0o755))
+	lines := `{"type":"user","timestamp":"2025-03-14T09:00:00Z","message":{"role":"user","content":"hi"}}
+{"type":"assistant","timestamp":"2025-03-14T09:00:05Z","requestId":"req_1","message":{"id":"msg_1","usage":{"input_tokens":10}}}
+{"type":"assistant","timestamp":"2025-03-14T09:00:05Z","requestId":"req_1","message":{"id":"msg_1","usage":{"input_tokens":10}}}
+{"type":"assistant","timestamp":"2025-03-14T10:00:00Z","requestId":"req_2","message":{"id":"msg_2","usage":{"input_tokens":5}}}
+{"type":"assistant","timestamp":"2025-03-14T11:00:00Z","message":{"usage":{"input_tokens":1}}}
+{"type":"assistant","timestamp":"2025-03-13T23:59:00Z","requestId":"req_0","message":{"id":"msg_0","usage":{"input_tokens":7}}}
+{"type":"assistant","timestamp":"2025-03-14T12:00:00Z","requestId":"req_3","message":{"id":"msg_3","usage":null}}
+not json with "usage"
+{"type":"assistant","timestamp":"2025-03-14T13:00:00Z","requestId":"req_4","message":{"id":"msg_4","usage":{"input_tokens":2}}}`
+	require.NoError(t, os.WriteFile(session, []byte(lines), 0o644))
+
+	// A file last written yesterday is not read at all.
+	old := filepath.Join(dir, "proj", "old.jsonl")
+	require.NoError(t, os.WriteFile(old,
+		[]byte(`{"timestamp":"2025-03-14T09:00:00Z","requestId":"r","message":{"id":"m","usage":{}}}`+"\n"), 0o644))
+	require.NoError(t, os.Chtimes(old, now.AddDate(0, 0, -1), now.AddDate(0, 0, -1)))
+	require.NoError(t, os.Chtimes(session, now, now))
+
+	// Duplicated request, unpaired IDs, previous day, null usage, and the
+	// unterminated last line are handled like ccusage does.
+	var counter requestCounter
+	count, lastWrite := counter.Count([]string{dir, filepath.Join(dir, "missing")}, now)
+	assert.Equal(t, 4, count)
+	assert.True(t, now.Equal(lastWrite), "the newest log's modification time")
+	count, lastWrite = counter.Count(nil, now)
+	assert.Equal(t, 0, count)
+	assert.True(t, lastWrite.IsZero())
+}
+
+func TestRequestCounter_ReadsAppendedLines(t *testing.T) {
+	now := time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	session := filepath.Join(dir, "session.jsonl")
+	request := func(id, timestamp string) string {
+		return `{"timestamp":"` + timestamp + `","requestId":"req_` + id + `","message":{"id":"msg_` + id + `","usage":{"input_tokens":1}}}`
+	}
+	write := func(data string) {
+		t.Helper()
+		file, err := os.OpenFile(session, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		require.NoError(t, err)
+		_, err = file.WriteString(data)
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+		require.NoError(t, os.Chtimes(session, now, now))
+	}
+
+	var counter requestCounter
+	dirs := []string{dir}
+	write(request("1", "2025-03-14T09:00:00Z") + "\n" + `{"timestamp":"2025-03-14T09:01:00Z","requestId":"req_2","mess`)
+	count, _ := counter.Count(dirs, now)
+	assert.Equal(t, 1, count, "a line still being written isn't counted")
+	assert.EqualValues(t, len(request("1", ""))+len("2025-03-14T09:00:00Z")+1, counter.read[session])
+
+	write(`age":{"id":"msg_2","usage":{"input_tokens":1}}}` + "\n" + request("1", "2025-03-14T09:00:00Z") + "\n" + request("3", "2025-03-14T10:00:00Z"))
+	count, _ = counter.Count(dirs, now)
+	assert.Equal(t, 3, count, "appended lines are counted once each, duplicates of earlier ones skipped")
+	count, _ = counter.Count(dirs, now)
+	assert.Equal(t, 3, count, "an unterminated line holding a whole request isn't counted again")
+
+	require.NoError(t, os.WriteFile(session, []byte(request("4", "2025-03-14T11:00:00Z")+"\n"), 0o644))
+	require.NoError(t, os.Chtimes(session, now, now))
+	count, _ = counter.Count(dirs, now)
+	assert.Equal(t, 1, count, "a rewritten log starts the count over")
+
+	tomorrow := now.AddDate(0, 0, 1)
+	require.NoError(t, os.Chtimes(session, tomorrow, tomorrow))
+	count, _ = counter.Count(dirs, tomorrow)
+	assert.Equal(t, 0, count, "a new day starts the count over")
+}
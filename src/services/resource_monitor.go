@@ -0,0 +1,198 @@
+package services
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+)
+
+// Resource monitor defaults: one sample a minute, and an alarm when the
+// last 15 samples only ever rose and the rise exceeds the bound. Earlier
+// polling leaks added a goroutine or two per poll, which this catches
+// within a quarter of an hour without tripping on GC sawtooth.
+const (
+	resourceSampleInterval  = time.Minute
+	resourceWindow          = 15
+	resourceGoroutineGrowth = 50
+	resourceHeapGrowthBytes = 64 << 20
+)
+
+// ResourceSample is one reading of the process's goroutines and heap.
+type ResourceSample struct {
+	Time       time.Time
+	Goroutines int
+	HeapBytes  uint64 // bytes of allocated heap objects
+}
+
+// ResourceMonitor periodically samples goroutine count and heap size and
+// raises an alarm when either grows steadily past its bound, the signature
+// of a leak rather than normal load.
+type ResourceMonitor struct {
+	interval        time.Duration
+	window          int
+	goroutineGrowth int
+	heapGrowth      uint64
+	read            func() ResourceSample
+	logger          *lib.Logger
+
+	mutex         sync.RWMutex
+	samples       []ResourceSample // oldest first, at most window long
+	goroutineLeak string           // current warning; empty when none
+	heapLeak      string
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+// NewResourceMonitor creates a monitor with the default sampling interval
+// and bounds. Call Start to begin sampling.
+func NewResourceMonitor() *ResourceMonitor {
+	return &ResourceMonitor{
+		interval:        resourceSampleInterval,
+		window:          resourceWindow,
+		goroutineGrowth: resourceGoroutineGrowth,
+		heapGrowth:      resourceHeapGrowthBytes,
+		read:            readResourceSample,
+		logger:          lib.NewLogger("resource-monitor"),
+	}
+}
+
+func readResourceSample() ResourceSample {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return ResourceSample{Time: time.Now(), Goroutines: runtime.NumGoroutine(), HeapBytes: mem.HeapAlloc}
+}
+
+// Start takes a first sample and keeps sampling in the background until
+// Stop. Starting a running monitor does nothing.
+func (rm *ResourceMonitor) Start() {
+	rm.mutex.Lock()
+	if rm.stop != nil {
+		rm.mutex.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	rm.stop = stop
+	rm.mutex.Unlock()
+
+	rm.record(rm.read())
+	rm.done.Add(1)
+	go func() {
+		defer rm.done.Done()
+		ticker := time.NewTicker(rm.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				rm.record(rm.read())
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends sampling and waits for the sampler to exit. It is safe to call
+// more than once.
+func (rm *ResourceMonitor) Stop() {
+	rm.mutex.Lock()
+	stop := rm.stop
+	rm.stop = nil
+	rm.mutex.Unlock()
+	if stop != nil {
+		close(stop)
+		rm.done.Wait()
+	}
+}
+
+// Latest returns the most recent sample; ok is false before the first.
+func (rm *ResourceMonitor) Latest() (sample ResourceSample, ok bool) {
+	rm.mutex.RLock()
+	defer rm.mutex.RUnlock()
+	if len(rm.samples) == 0 {
+		return ResourceSample{}, false
+	}
+	return rm.samples[len(rm.samples)-1], true
+}
+
+// Warnings describes each resource currently growing like a leak, e.g.
+// "Goroutines rose from 12 to 80 in 14 min"; empty when all is well.
+func (rm *ResourceMonitor) Warnings() []string {
+	rm.mutex.RLock()
+	defer rm.mutex.RUnlock()
+	var warnings []string
+	for _, w := range []string{rm.goroutineLeak, rm.heapLeak} {
+		if w != "" {
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings
+}
+
+// record adds a sample, re-evaluates the alarm, and logs an ERROR when a
+// resource starts looking like a leak.
+func (rm *ResourceMonitor) record(sample ResourceSample) {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	rm.samples = append(rm.samples, sample)
+	if len(rm.samples) > rm.window {
+		rm.samples = rm.samples[len(rm.samples)-rm.window:]
+	}
+
+	goroutineLeak, heapLeak := rm.leakWarningsLocked()
+	for _, w := range []struct{ was, now string }{{rm.goroutineLeak, goroutineLeak}, {rm.heapLeak, heapLeak}} {
+		if w.was == "" && w.now != "" {
+			rm.logger.Error("Possible resource leak", map[string]interface{}{
+				"warning":    w.now,
+				"goroutines": sample.Goroutines,
+				"heap_bytes": sample.HeapBytes,
+			})
+		}
+	}
+	rm.goroutineLeak, rm.heapLeak = goroutineLeak, heapLeak
+}
+
+// leakWarningsLocked checks the window for a resource that never dropped
+// and grew past its bound.
+func (rm *ResourceMonitor) leakWarningsLocked() (goroutineLeak, heapLeak string) {
+	if len(rm.samples) < rm.window {
+		return "", ""
+	}
+	first, last := rm.samples[0], rm.samples[len(rm.samples)-1]
+	minutes := int(last.Time.Sub(first.Time).Minutes())
+
+	goroutinesRising, heapRising := true, true
+	for i := 1; i < len(rm.samples); i++ {
+		prev, cur := rm.samples[i-1], rm.samples[i]
+		goroutinesRising = goroutinesRising && cur.Goroutines >= prev.Goroutines
+		heapRising = heapRising && cur.HeapBytes >= prev.HeapBytes
+	}
+
+	if goroutinesRising && last.Goroutines-first.Goroutines > rm.goroutineGrowth {
+		goroutineLeak = fmt.Sprintf("Goroutines rose from %d to %d in %d min",
+			first.Goroutines, last.Goroutines, minutes)
+	}
+	if heapRising && last.HeapBytes-first.HeapBytes > rm.heapGrowth {
+		heapLeak = fmt.Sprintf("Heap rose from %s to %s in %d min",
+			FormatBytes(first.HeapBytes), FormatBytes(last.HeapBytes), minutes)
+	}
+	return goroutineLeak, heapLeak
+}
+
+// FormatBytes renders a byte count in binary units, e.g. "12.5 MB".
+func FormatBytes(n uint64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
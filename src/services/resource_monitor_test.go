@@ -0,0 +1,86 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestResourceMonitor() *ResourceMonitor {
+	rm := NewResourceMonitor()
+	rm.window = 4
+	rm.goroutineGrowth = 10
+	rm.heapGrowth = 1 << 20
+	return rm
+}
+
+func recordSamples(rm *ResourceMonitor, goroutines []int, heap []uint64) {
+	start := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	for i := range goroutines {
+		rm.record(ResourceSample{Time: start.Add(time.Duration(i) * time.Minute), Goroutines: goroutines[i], HeapBytes: heap[i]})
+	}
+}
+
+func TestResourceMonitor_Warnings(t *testing.T) {
+	tests := []struct {
+		name       string
+		goroutines []int
+		heap       []uint64
+		want       []string
+	}{
+		{"steady", []int{10, 10, 11, 10}, []uint64{1 << 20, 1 << 20, 1 << 20, 1 << 20}, nil},
+		{"too few samples", []int{10, 30, 50}, []uint64{0, 0, 0}, nil},
+		{"goroutine leak", []int{10, 14, 18, 22}, []uint64{1 << 20, 1 << 20, 1 << 20, 1 << 20},
+			[]string{"Goroutines rose from 10 to 22 in 3 min"}},
+		{"growth within bound", []int{10, 12, 14, 16}, []uint64{0, 0, 0, 0}, nil},
+		{"growth with a dip", []int{10, 30, 25, 40}, []uint64{0, 0, 0, 0}, nil},
+		{"heap leak", []int{10, 10, 10, 10}, []uint64{1 << 20, 2 << 20, 3 << 20, 4 << 20},
+			[]string{"Heap rose from 1.0 MB to 4.0 MB in 3 min"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rm := newTestResourceMonitor()
+			recordSamples(rm, tt.goroutines, tt.heap)
+			assert.Equal(t, tt.want, rm.Warnings())
+		})
+	}
+}
+
+func TestResourceMonitor_SlidingWindow(t *testing.T) {
+	rm := newTestResourceMonitor()
+	recordSamples(rm, []int{10, 14, 18, 22}, []uint64{0, 0, 0, 0})
+	require.Len(t, rm.Warnings(), 1)
+
+	// The leak stopping clears the warning once the growth drops out.
+	recordSamples(rm, []int{22, 22, 22, 22}, []uint64{0, 0, 0, 0})
+	assert.Empty(t, rm.Warnings())
+	latest, ok := rm.Latest()
+	require.True(t, ok)
+	assert.Equal(t, 22, latest.Goroutines)
+}
+
+func TestResourceMonitor_StartStop(t *testing.T) {
+	rm := NewResourceMonitor()
+	_, ok := rm.Latest()
+	assert.False(t, ok)
+
+	rm.Start()
+	rm.Start() // already running
+	latest, ok := rm.Latest()
+	require.True(t, ok, "Start samples immediately")
+	assert.Positive(t, latest.Goroutines)
+	assert.Positive(t, latest.HeapBytes)
+
+	rm.Stop()
+	rm.Stop()
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", FormatBytes(512))
+	assert.Equal(t, "1.5 KB", FormatBytes(1536))
+	assert.Equal(t, "12.5 MB", FormatBytes(25<<19))
+	assert.Equal(t, "2.0 GB", FormatBytes(2<<30))
+}
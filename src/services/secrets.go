@@ -0,0 +1,214 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// Secret backends, the second part of an "enc:<backend>:<payload>" value.
+const (
+	SecretBackendKeychain = "keychain"
+	SecretBackendAge      = "age"
+)
+
+// ageIdentityFile is the age key, beside the config file, that encrypted
+// values are decrypted with and, by default, encrypted to.
+const ageIdentityFile = "age-identity.txt"
+
+// secretTimeout bounds each keychain or age invocation.
+const secretTimeout = 10 * time.Second
+
+// SecretStore encrypts config values and decrypts them again. Seal returns
+// the payload stored after "enc:<backend>:"; Open takes it back.
+type SecretStore interface {
+	Seal(name, plain string) (string, error)
+	Open(payload string) (string, error)
+}
+
+// secretCommand runs a keychain or age command; tests replace it.
+var secretCommand = defaultSecretCommand
+
+// defaultSecretCommand runs name with stdin and returns its output, or an
+// error carrying what it printed to stderr.
+func defaultSecretCommand(stdin []byte, name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %s", name, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return out, nil
+}
+
+// keychainStore keeps secrets in the OS keychain, one item per config
+// field: the login keychain through security(1) on macOS and the Secret
+// Service through secret-tool(1) on Linux. The payload is the item's
+// account, which ConfigService makes the field name plus a hash of the
+// config file's path (see secretAccount). The secret always travels on
+// stdin, never on a command line where ps would show it.
+type keychainStore struct{}
+
+// keychainService is the service the app's keychain items are filed under.
+const keychainService = appDirName
+
+func (keychainStore) Seal(name, plain string) (string, error) {
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		// security only takes a password as an argument, so the command
+		// goes to its interactive mode on stdin instead.
+		if strings.ContainsAny(plain, "\r\n") {
+			return "", lib.ValidationError(name + " can't go in the keychain: it spans several lines")
+		}
+		command := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+			securityQuote(keychainService), securityQuote(name), securityQuote(plain))
+		_, err = secretCommand([]byte(command), "security", "-i")
+	case "linux", "freebsd", "openbsd", "netbsd":
+		_, err = secretCommand([]byte(plain), "secret-tool", "store",
+			"--label", keychainService+" "+name, "service", keychainService, "account", name)
+	default:
+		return "", lib.SystemError("the OS keychain isn't supported on " + runtime.GOOS + "; use --backend age")
+	}
+	if err != nil {
+		return "", lib.WrapError(err, lib.ErrCodeSystem, "failed to store "+name+" in the keychain")
+	}
+	return name, nil
+}
+
+func (keychainStore) Open(payload string) (string, error) {
+	var out []byte
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		out, err = secretCommand(nil, "security", "find-generic-password",
+			"-s", keychainService, "-a", payload, "-w")
+	case "linux", "freebsd", "openbsd", "netbsd":
+		out, err = secretCommand(nil, "secret-tool", "lookup", "service", keychainService, "account", payload)
+	default:
+		return "", lib.SystemError("the OS keychain isn't supported on " + runtime.GOOS)
+	}
+	if err != nil {
+		return "", lib.WrapError(err, lib.ErrCodeSystem, "failed to read "+payload+" from the keychain")
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// securityQuote quotes s as one argument for security(1)'s interactive
+// mode.
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// secretCache remembers what each encrypted value decrypted to, so the
+// keychain or age runs once per value per process rather than on every
+// Load, which each tray save and reload repeats.
+var secretCache = struct {
+	sync.Mutex
+	plain map[string]string
+}{plain: map[string]string{}}
+
+// cachedStore is a SecretStore whose Open results go through secretCache.
+// key tells apart stores that could read the same payload differently,
+// such as age with different identities.
+type cachedStore struct {
+	SecretStore
+	key string
+}
+
+func (s cachedStore) Seal(name, plain string) (string, error) {
+	payload, err := s.SecretStore.Seal(name, plain)
+	if err == nil {
+		secretCache.Lock()
+		secretCache.plain[s.key+"\x00"+payload] = plain
+		secretCache.Unlock()
+	}
+	return payload, err
+}
+
+func (s cachedStore) Open(payload string) (string, error) {
+	cacheKey := s.key + "\x00" + payload
+	secretCache.Lock()
+	plain, ok := secretCache.plain[cacheKey]
+	secretCache.Unlock()
+	if ok {
+		return plain, nil
+	}
+
+	plain, err := s.SecretStore.Open(payload)
+	if err != nil {
+		return "", err
+	}
+	secretCache.Lock()
+	secretCache.plain[cacheKey] = plain
+	secretCache.Unlock()
+	return plain, nil
+}
+
+// ageStore encrypts secrets with age(1). The payload is the base64
+// ciphertext, so the config file stays portable between machines that
+// share the identity.
+type ageStore struct {
+	identity  string // key file that decrypts
+	recipient string // public key to encrypt to; "" derives it from identity
+}
+
+// NewAgeStore creates an age store that decrypts with the key file at
+// identity and encrypts to recipient, or to identity's own public key when
+// recipient is "".
+func NewAgeStore(identity, recipient string) SecretStore {
+	return ageStore{identity: identity, recipient: recipient}
+}
+
+func (s ageStore) Seal(name, plain string) (string, error) {
+	recipient := s.recipient
+	if recipient == "" {
+		out, err := secretCommand(nil, "age-keygen", "-y", s.identity)
+		if err != nil {
+			return "", lib.WrapError(err, lib.ErrCodeSystem, "failed to read the age recipient from "+s.identity)
+		}
+		recipient = strings.TrimSpace(string(out))
+	}
+	out, err := secretCommand([]byte(plain), "age", "--encrypt", "-r", recipient)
+	if err != nil {
+		return "", lib.WrapError(err, lib.ErrCodeSystem, "failed to encrypt "+name+" with age")
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+func (s ageStore) Open(payload string) (string, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", lib.WrapError(err, lib.ErrCodeConfig, "age payload isn't valid base64")
+	}
+	out, err := secretCommand(ciphertext, "age", "--decrypt", "-i", s.identity)
+	if err != nil {
+		return "", lib.WrapError(err, lib.ErrCodeSystem, "failed to decrypt with "+s.identity)
+	}
+	return string(out), nil
+}
+
+// parseSecretRef splits an "enc:<backend>:<payload>" value.
+func parseSecretRef(value string) (backend, payload string, err error) {
+	backend, payload, ok := strings.Cut(strings.TrimPrefix(value, models.SecretPrefix), ":")
+	if !ok || payload == "" {
+		return "", "", lib.ValidationError("encrypted value must look like enc:<backend>:<payload>")
+	}
+	return backend, payload, nil
+}
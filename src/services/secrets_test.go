@@ -0,0 +1,255 @@
+package services
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// fakeSecretStore keeps secrets in memory, keyed by name.
+type fakeSecretStore struct {
+	secrets map[string]string
+}
+
+func newFakeSecretStore() *fakeSecretStore {
+	return &fakeSecretStore{secrets: map[string]string{}}
+}
+
+func (s *fakeSecretStore) Seal(name, plain string) (string, error) {
+	s.secrets[name] = plain
+	return name, nil
+}
+
+func (s *fakeSecretStore) Open(payload string) (string, error) {
+	plain, ok := s.secrets[payload]
+	if !ok {
+		return "", lib.SystemError("no secret " + payload)
+	}
+	return plain, nil
+}
+
+func newSecretsConfigService(t *testing.T, store SecretStore) (*ConfigService, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	svc := NewConfigService()
+	svc.SetConfigPath(path)
+	svc.SetSecretStore(SecretBackendKeychain, store)
+	return svc, path
+}
+
+// writeSecretsConfig writes the default config with extra YAML appended.
+func writeSecretsConfig(t *testing.T, path, extra string) {
+	t.Helper()
+	data, err := EncodeConfig(FormatYAML, models.ConfigDefaults())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, append(data, extra...), 0o600))
+}
+
+func TestConfigService_LoadDecryptsSecrets(t *testing.T) {
+	store := newFakeSecretStore()
+	store.secrets["ledger_webhook"] = "https://example.com/hook?token=abc"
+	store.secrets["notification_sinks[0].url"] = "https://hooks.slack.com/services/T/B/x"
+	svc, path := newSecretsConfigService(t, store)
+	writeSecretsConfig(t, path, `ledger_webhook: "enc:keychain:ledger_webhook"
+notification_sinks:
+  - type: slack
+    url: "enc:keychain:notification_sinks[0].url"
+`)
+
+	config, err := svc.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/hook?token=abc", config.LedgerWebhook)
+	assert.Equal(t, "https://hooks.slack.com/services/T/B/x", config.NotificationSinks[0].URL)
+
+	// Unchanged values are written back encrypted; changed ones aren't.
+	config.NotificationSinks[0].URL = "https://hooks.slack.com/services/T/B/y"
+	require.NoError(t, svc.Save(config))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "enc:keychain:ledger_webhook")
+	assert.Contains(t, string(data), "services/T/B/y")
+	assert.NotContains(t, string(data), "token=abc")
+	assert.Equal(t, "https://example.com/hook?token=abc", config.LedgerWebhook, "Save must not touch the caller's config")
+}
+
+func TestConfigService_ConcurrentLoads(t *testing.T) {
+	store := newFakeSecretStore()
+	store.secrets["ledger_webhook"] = "https://example.com/hook?token=abc"
+	svc, path := newSecretsConfigService(t, store)
+	writeSecretsConfig(t, path, "ledger_webhook: \"enc:keychain:ledger_webhook\"\n")
+
+	// The tray and config reloads load and save on their own goroutines.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			config, err := svc.Load()
+			if assert.NoError(t, err) {
+				assert.Equal(t, "enc:keychain:ledger_webhook", svc.sealSecrets(config).LedgerWebhook)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConfigService_LoadSecretErrors(t *testing.T) {
+	for name, value := range map[string]string{
+		"missing secret":  "enc:keychain:ledger_webhook",
+		"unknown backend": "enc:vault:ledger_webhook",
+		"no payload":      "enc:keychain",
+	} {
+		t.Run(name, func(t *testing.T) {
+			svc, path := newSecretsConfigService(t, newFakeSecretStore())
+			writeSecretsConfig(t, path, "ledger_webhook: \""+value+"\"\n")
+
+			_, err := svc.Load()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "ledger_webhook")
+		})
+	}
+}
+
+func TestConfigService_Encrypt(t *testing.T) {
+	store := newFakeSecretStore()
+	svc, path := newSecretsConfigService(t, store)
+	config := models.ConfigDefaults()
+	config.HTTPToken = "0123456789abcdef"
+
+	require.NoError(t, svc.Encrypt(config, "http_token", SecretBackendKeychain))
+	account := svc.secretAccount("http_token")
+	assert.Regexp(t, `^http_token@[0-9a-f]{12}$`, account)
+	assert.Equal(t, "0123456789abcdef", store.secrets[account])
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "enc:keychain:"+account)
+	assert.NotContains(t, string(data), "0123456789abcdef")
+
+	err = svc.Encrypt(config, "http_token", SecretBackendKeychain)
+	assert.ErrorContains(t, err, "already encrypted")
+	err = svc.Encrypt(config, "ledger_webhook", SecretBackendKeychain)
+	assert.ErrorContains(t, err, "no sensitive value")
+	config.LedgerWebhook = "https://example.com/hook"
+	err = svc.Encrypt(config, "ledger_webhook", "vault")
+	assert.ErrorContains(t, err, "unknown secret backend")
+
+	loaded, err := svc.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789abcdef", loaded.HTTPToken)
+}
+
+func TestAgeStore(t *testing.T) {
+	var calls []string
+	var stdins []string
+	secretCommand = func(stdin []byte, name string, args ...string) ([]byte, error) {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		stdins = append(stdins, string(stdin))
+		switch name {
+		case "age-keygen":
+			return []byte("age1recipient\n"), nil
+		case "age":
+			if args[0] == "--encrypt" {
+				return []byte("ciphertext"), nil
+			}
+			return []byte("plain"), nil
+		}
+		return nil, nil
+	}
+	t.Cleanup(func() { secretCommand = defaultSecretCommand })
+
+	store := NewAgeStore("/cfg/age-identity.txt", "")
+	payload, err := store.Seal("http_token", "plain")
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("ciphertext")), payload)
+
+	plain, err := store.Open(payload)
+	require.NoError(t, err)
+	assert.Equal(t, "plain", plain)
+
+	assert.Equal(t, []string{
+		"age-keygen -y /cfg/age-identity.txt",
+		"age --encrypt -r age1recipient",
+		"age --decrypt -i /cfg/age-identity.txt",
+	}, calls)
+	assert.Equal(t, []string{"", "plain", "ciphertext"}, stdins)
+
+	_, err = store.Open("not base64!")
+	assert.ErrorContains(t, err, "base64")
+}
+
+func TestConfigService_SecretAccountPerConfig(t *testing.T) {
+	dir := t.TempDir()
+	work, home := NewConfigService(), NewConfigService()
+	work.SetConfigPath(filepath.Join(dir, "work.yaml"))
+	home.SetConfigPath(filepath.Join(dir, "home.yaml"))
+
+	assert.NotEqual(t, work.secretAccount("http_token"), home.secretAccount("http_token"),
+		"two configs keep separate keychain items")
+	assert.Equal(t, work.secretAccount("http_token"), work.secretAccount("http_token"))
+}
+
+func TestKeychainStore_SecretOnStdin(t *testing.T) {
+	if runtime.GOOS != "darwin" && runtime.GOOS != "linux" {
+		t.Skip("no keychain backend on " + runtime.GOOS)
+	}
+	var args, stdin string
+	secretCommand = func(in []byte, name string, a ...string) ([]byte, error) {
+		args, stdin = name+" "+strings.Join(a, " "), string(in)
+		return nil, nil
+	}
+	t.Cleanup(func() { secretCommand = defaultSecretCommand })
+
+	_, err := keychainStore{}.Seal("http_token@abc", `s3cr"et`)
+	require.NoError(t, err)
+	assert.NotContains(t, args, "s3cr", "the secret never goes on the command line")
+	assert.Contains(t, stdin, "s3cr")
+	if runtime.GOOS == "darwin" {
+		assert.Equal(t, "security -i", args)
+		assert.Equal(t, `add-generic-password -U -s "cc-dailyuse-bar" -a "http_token@abc" -w "s3cr\"et"`+"\n", stdin)
+	}
+}
+
+func TestCachedStore_OpensOnce(t *testing.T) {
+	inner := &countingSecretStore{fakeSecretStore: newFakeSecretStore()}
+	inner.secrets["acct"] = "plain"
+	store := cachedStore{SecretStore: inner, key: t.Name()}
+
+	for i := 0; i < 3; i++ {
+		plain, err := store.Open("acct")
+		require.NoError(t, err)
+		assert.Equal(t, "plain", plain)
+	}
+	assert.Equal(t, 1, inner.opens, "later loads reuse the decrypted value")
+
+	_, err := store.Seal("acct", "changed")
+	require.NoError(t, err)
+	plain, err := store.Open("acct")
+	require.NoError(t, err)
+	assert.Equal(t, "changed", plain, "sealing a new value replaces the cached one")
+
+	_, err = store.Open("missing")
+	assert.Error(t, err)
+	_, err = store.Open("missing")
+	assert.Error(t, err, "failures aren't cached")
+	assert.Equal(t, 3, inner.opens)
+}
+
+type countingSecretStore struct {
+	*fakeSecretStore
+	opens int
+}
+
+func (s *countingSecretStore) Open(payload string) (string, error) {
+	s.opens++
+	return s.fakeSecretStore.Open(payload)
+}
@@ -0,0 +1,77 @@
+package services
+
+import "fmt"
+
+// SelfCheckStage names the step of a self-check that failed.
+type SelfCheckStage string
+
+// Self-check stages, in the order they run.
+const (
+	StageBinary SelfCheckStage = "binary"
+	StageFetch  SelfCheckStage = "fetch"
+	StageParse  SelfCheckStage = "parse"
+)
+
+// SelfCheckError reports which stage of a self-check failed.
+type SelfCheckError struct {
+	Stage SelfCheckStage
+	Err   error
+}
+
+func (e *SelfCheckError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Stage, e.Err)
+}
+
+func (e *SelfCheckError) Unwrap() error {
+	return e.Err
+}
+
+// SelfCheckResult describes a successful self-check.
+type SelfCheckResult struct {
+	ResolvedPath string
+	OutputBytes  int
+	Entries      int
+	TodayFound   bool
+	Today        CCUsageOutput
+	Overlap      *DataDirOverlap // non-nil when ccusage may double count
+	Sources      []SourceUsage   // today per source; nil unless other clients' data was found
+}
+
+// SelfCheck resolves the ccusage binary, runs one fetch (bypassing the
+// on-disk cache), and parses the result, stopping at the first failing
+// stage, then breaks today down by usage source. Unlike UpdateUsage it
+// does not touch the tracked state, and "no data for today" is not a
+// failure.
+func (us *UsageService) SelfCheck() (*SelfCheckResult, error) {
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+
+	resolved, err := us.resolveCCUsage()
+	if err != nil {
+		return nil, &SelfCheckError{Stage: StageBinary,
+			Err: fmt.Errorf("%w: %q: %v", errCCUsageUnavailable, us.ccusagePath, err)}
+	}
+
+	now := us.clock.Now()
+	output, err := us.runCCUsage(ccusageDailyArgs(now))
+	if err != nil {
+		return nil, &SelfCheckError{Stage: StageFetch, Err: err}
+	}
+
+	weekStart, _ := currentWeekRange(now)
+	scan, err := scanDailyOutput(output, now.Format("2006-01-02"), weekStart.Format("2006-01-02"))
+	if err != nil {
+		return nil, &SelfCheckError{Stage: StageParse,
+			Err: fmt.Errorf("%w (output: %s)", err, us.truncateOutput(output))}
+	}
+
+	return &SelfCheckResult{
+		ResolvedPath: resolved,
+		OutputBytes:  len(output),
+		Entries:      scan.Entries,
+		TodayFound:   scan.Found,
+		Today:        scan.Today,
+		Overlap:      DetectDataDirOverlap(us.claudeDataDir),
+		Sources:      us.sourceBreakdownLocked(),
+	}, nil
+}
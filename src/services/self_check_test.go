@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/internal/testhelpers"
+	"cc-dailyuse-bar/src/models"
+)
+
+func writeCCUsageScript(t *testing.T, body string) string {
+	t.Helper()
+	testhelpers.RequireShell(t)
+	path := filepath.Join(t.TempDir(), "ccusage")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/bash\n"+body+"\n"), 0o755))
+	return path
+}
+
+func TestUsageService_SelfCheck(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name      string
+		path      string
+		wantStage SelfCheckStage
+	}{
+		{"missing binary", filepath.Join(t.TempDir(), "missing"), StageBinary},
+		{"command fails", testhelpers.WriteFakeCommand(t, t.TempDir(), "ccusage", "", 3), StageFetch},
+		{"bad json", testhelpers.WriteFakeCommand(t, t.TempDir(), "ccusage", "not json\n", 0), StageParse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestUsageService()
+			service.SetClock(fixedClock{now: now})
+			service.ccusagePath = tt.path
+
+			_, err := service.SelfCheck()
+			var checkErr *SelfCheckError
+			require.True(t, errors.As(err, &checkErr), "got %v", err)
+			assert.Equal(t, tt.wantStage, checkErr.Stage)
+		})
+	}
+}
+
+func TestUsageService_SelfCheck_Success(t *testing.T) {
+	service := newTestUsageService()
+	service.SetClock(fixedClock{now: time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)})
+	service.ccusagePath = writeCCUsageScript(t,
+		`echo '{"daily": [{"date": "2025-03-13", "totalTokens": 1, "totalCost": 1}, {"date": "2025-03-14", "totalTokens": 5, "totalCost": 2.5}]}'`)
+
+	result, err := service.SelfCheck()
+	require.NoError(t, err)
+	assert.Equal(t, service.ccusagePath, result.ResolvedPath)
+	assert.Equal(t, 2, result.Entries)
+	assert.True(t, result.TodayFound)
+	assert.Equal(t, 2.5, result.Today.TotalCost)
+	assert.False(t, service.state.IsAvailable, "self-check leaves tracked state alone")
+}
+
+func TestUsageService_ClaudeDataDirEnv(t *testing.T) {
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+	config := models.ConfigDefaults()
+	config.ClaudeDataDir = "/data/claude"
+	service := NewUsageService(config)
+	service.diskCache = nil
+	service.ccusagePath = writeCCUsageScript(t, `echo "$CLAUDE_CONFIG_DIR"`)
+
+	output, err := service.runCCUsage(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "/data/claude\n", string(output))
+	assert.Equal(t, []string{filepath.Join("/data/claude", "projects")}, service.dataDirs)
+
+	config.ClaudeDataDir = ""
+	service.ApplyConfig(config)
+	assert.Empty(t, service.claudeDataDir)
+	assert.Len(t, service.dataDirs, 2, "back to the default directories")
+}
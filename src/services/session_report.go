@@ -0,0 +1,219 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// sessionRefreshInterval is how often the session report is re-run. It is
+// slower to produce than the daily report and the top session rarely
+// changes between polls, so it refreshes on a slower cadence.
+const sessionRefreshInterval = 10 * time.Minute
+
+// ccusageSessionReport is the part of `ccusage session --json` output used
+// to find the most expensive sessions. ccusage lists the sessions active
+// in the range, each once with its total cost, including days before it.
+type ccusageSessionReport struct {
+	Sessions []struct {
+		SessionID string  `json:"sessionId"`
+		TotalCost float64 `json:"totalCost"`
+	} `json:"sessions"`
+}
+
+// ccusageProjectReport is the part of `ccusage daily --json --instances`
+// output used to split spend by project tag: each project's cost per day.
+type ccusageProjectReport struct {
+	Projects map[string][]struct {
+		Date      string  `json:"date"` // YYYY-MM-DD
+		TotalCost float64 `json:"totalCost"`
+	} `json:"projects"`
+}
+
+// ccusageSessionArgs builds the session report invocation for now's day.
+func ccusageSessionArgs(now time.Time) []string {
+	day := now.Format(ccusageDateFormat)
+	return []string{"session", "--json", "--since", day, "--until", day}
+}
+
+// ccusageProjectArgs builds the per-project daily report invocation
+// covering since through until.
+func ccusageProjectArgs(since, until time.Time) []string {
+	return []string{"daily", "--json", "--instances", "--since", since.Format(ccusageDateFormat), "--until", until.Format(ccusageDateFormat)}
+}
+
+func parseSessionReport(output []byte) (*ccusageSessionReport, error) {
+	var report ccusageSessionReport
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func parseProjectReport(output []byte) (*ccusageProjectReport, error) {
+	var report ccusageProjectReport
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// topSessionFrom returns the session with the highest total cost in a
+// ccusage session report, or nil when no session spent anything.
+func topSessionFrom(report *ccusageSessionReport, home string) *models.SessionCost {
+	var top *models.SessionCost
+	for _, s := range report.Sessions {
+		if s.TotalCost > 0 && (top == nil || s.TotalCost > top.Cost) {
+			top = &models.SessionCost{Name: sessionName(s.SessionID, home), Cost: s.TotalCost}
+		}
+	}
+	return top
+}
+
+// sessionCostsFrom lists every session in a ccusage session report that
+// spent anything, with its total cost.
+func sessionCostsFrom(report *ccusageSessionReport, home string) []models.SessionCost {
+	var sessions []models.SessionCost
+	for _, s := range report.Sessions {
+		if s.TotalCost > 0 {
+			sessions = append(sessions, models.SessionCost{Name: sessionName(s.SessionID, home), Cost: s.TotalCost})
+		}
+	}
+	return sessions
+}
+
+// dailyTagCostsFrom totals the report's spend per project tag for each
+// YYYY-MM-DD day, most expensive first.
+func dailyTagCostsFrom(report *ccusageProjectReport, rules []models.ProjectTag, home string) map[string][]models.TagCost {
+	totals := map[string]map[string]float64{}
+	for project, days := range report.Projects {
+		tag := models.MatchProjectTag(rules, project, home)
+		for _, day := range days {
+			if day.TotalCost <= 0 {
+				continue
+			}
+			if totals[day.Date] == nil {
+				totals[day.Date] = map[string]float64{}
+			}
+			totals[day.Date][tag] += day.TotalCost
+		}
+	}
+	days := make(map[string][]models.TagCost, len(totals))
+	for day, tags := range totals {
+		days[day] = models.SortTagCosts(tags)
+	}
+	return days
+}
+
+// sessionName shortens a ccusage session ID for display. Claude Code names
+// each project directory after its path with every non-alphanumeric byte
+// replaced by "-", so "/Users/me/refactor-api" becomes
+// "-Users-me-refactor-api"; dropping the encoded home directory leaves
+// "refactor-api".
+func sessionName(id, home string) string {
+	if home != "" {
+		if rest, ok := strings.CutPrefix(id, models.EncodeProjectPath(home)+"-"); ok && rest != "" {
+			return rest
+		}
+	}
+	if trimmed := strings.TrimLeft(id, "-"); trimmed != "" {
+		return trimmed
+	}
+	return id
+}
+
+// refreshTopSessionLocked re-runs the session report, and the per-project
+// report when project_tags is set, when sessionRefreshInterval has passed
+// or the day has changed, and copies the latest top session, sessions
+// over session_cap, and today's per-tag totals into the state. A failed
+// run keeps the previous result; the daily figures matter more than this
+// detail.
+func (us *UsageService) refreshTopSessionLocked(now time.Time) {
+	day := now.Format(ccusageDateFormat)
+	if day != us.sessionDay {
+		us.sessionDay = day
+		us.topSession = nil // yesterday's session is no answer for today
+		us.sessionCosts = nil
+		us.tagCosts = nil
+		us.lastSessionQuery = time.Time{}
+	}
+	if now.Sub(us.lastSessionQuery) >= sessionRefreshInterval {
+		span := us.span.Child("ccusage.session")
+		report, err := us.querySessions(ccusageSessionArgs(now))
+		span.SetError(err)
+		span.End()
+		if err != nil {
+			us.logger.Warn("ccusage session report failed", map[string]interface{}{
+				"error": err.Error(),
+			})
+		} else {
+			home, _ := os.UserHomeDir()
+			us.topSession = topSessionFrom(report, home)
+			us.sessionCosts = sessionCostsFrom(report, home)
+		}
+		us.refreshTagCostsLocked(now)
+		us.lastSessionQuery = now
+	}
+	us.state.TopSession = us.topSession
+	us.state.SessionAlerts = models.SessionsOverCap(us.sessionCosts, us.sessionCap)
+	us.state.TagCosts = us.tagCosts
+}
+
+// refreshTagCostsLocked re-runs the per-project report for today's
+// per-tag totals. A failed run keeps the previous result.
+func (us *UsageService) refreshTagCostsLocked(now time.Time) {
+	if len(us.projectTags) == 0 {
+		us.tagCosts = nil
+		return
+	}
+	span := us.span.Child("ccusage.projects")
+	report, err := us.queryProjects(ccusageProjectArgs(now, now))
+	span.SetError(err)
+	span.End()
+	if err != nil {
+		us.logger.Warn("ccusage per-project report failed", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	home, _ := os.UserHomeDir()
+	us.tagCosts = dailyTagCostsFrom(report, us.projectTags, home)[now.Format("2006-01-02")]
+}
+
+func (us *UsageService) queryProjects(args []string) (*ccusageProjectReport, error) {
+	output, err := us.runCCUsage(args)
+	if err != nil {
+		return nil, err
+	}
+	return parseProjectReport(output)
+}
+
+func (us *UsageService) querySessions(args []string) (*ccusageSessionReport, error) {
+	output, err := us.runCCUsage(args)
+	if err != nil {
+		return nil, err
+	}
+	return parseSessionReport(output)
+}
+
+// DailyTagCosts returns the spend per project tag for each day from since
+// through until, keyed by YYYY-MM-DD. It returns nil when project_tags is
+// empty.
+func (us *UsageService) DailyTagCosts(since, until time.Time) (map[string][]models.TagCost, error) {
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+
+	if len(us.projectTags) == 0 {
+		return nil, nil
+	}
+	report, err := us.queryProjects(ccusageProjectArgs(since, until))
+	if err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeCCUsage, "failed to run the ccusage per-project report")
+	}
+	home, _ := os.UserHomeDir()
+	return dailyTagCostsFrom(report, us.projectTags, home), nil
+}
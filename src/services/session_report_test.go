@@ -0,0 +1,204 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+func TestCCUsageSessionArgs(t *testing.T) {
+	now := time.Date(2025, 3, 14, 15, 0, 0, 0, time.Local)
+	assert.Equal(t, []string{"session", "--json", "--since", "20250314", "--until", "20250314"}, ccusageSessionArgs(now))
+}
+
+func TestTopSessionFrom(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   *models.SessionCost
+	}{
+		{
+			name: "highest cost wins",
+			output: `{"sessions":[
+				{"sessionId":"-Users-me-docs","totalCost":1.1},
+				{"sessionId":"-Users-me-refactor-api","totalCost":4.2},
+				{"sessionId":"-Users-me-scratch","totalCost":0.3}]}`,
+			want: &models.SessionCost{Name: "refactor-api", Cost: 4.2},
+		},
+		{
+			name:   "no sessions",
+			output: `{"sessions":[]}`,
+		},
+		{
+			name:   "nothing spent",
+			output: `{"sessions":[{"sessionId":"-Users-me-docs","totalCost":0}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := parseSessionReport([]byte(tt.output))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, topSessionFrom(report, "/Users/me"))
+		})
+	}
+
+	_, err := parseSessionReport([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestDailyTagCostsFrom(t *testing.T) {
+	report, err := parseProjectReport([]byte(`{"projects":{
+		"-Users-me-work-client-a-api":[{"date":"2025-03-13","totalCost":2},{"date":"2025-03-14","totalCost":0.5}],
+		"-Users-me-work-client-a-web":[{"date":"2025-03-14","totalCost":1}],
+		"-Users-me-oss-cli":[{"date":"2025-03-14","totalCost":1}],
+		"-Users-me-scratch":[{"date":"2025-03-14","totalCost":0.5}],
+		"-Users-me-docs":[{"date":"2025-03-14","totalCost":0}]}}`))
+	require.NoError(t, err)
+	rules := []models.ProjectTag{{Path: "~/work/client-a", Tag: "client-A"}, {Path: "~/oss", Tag: "oss"}}
+
+	assert.Equal(t, map[string][]models.TagCost{
+		"2025-03-13": {{Tag: "client-A", Cost: 2}},
+		"2025-03-14": {{Tag: "client-A", Cost: 1.5}, {Tag: "oss", Cost: 1}, {Tag: models.UntaggedTag, Cost: 0.5}},
+	}, dailyTagCostsFrom(report, rules, "/Users/me"), "a project's spend counts towards each day it was spent on")
+	assert.Equal(t, []string{"daily", "--json", "--instances", "--since", "20250313", "--until", "20250314"},
+		ccusageProjectArgs(time.Date(2025, 3, 13, 0, 0, 0, 0, time.Local), time.Date(2025, 3, 14, 0, 0, 0, 0, time.Local)))
+}
+
+func TestSessionName(t *testing.T) {
+	tests := []struct {
+		id, home, want string
+	}{
+		{"-Users-me-refactor-api", "/Users/me", "refactor-api"},
+		{"-home-first-last-code-app", "/home/first.last", "code-app"},
+		{"-srv-build", "/Users/me", "srv-build"},
+		{"-Users-me", "/Users/me", "Users-me"},
+		{"abc123", "", "abc123"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, sessionName(tt.id, tt.home), tt.id)
+	}
+}
+
+func TestUsageService_RefreshesTopSessionLessOften(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	project := strings.Map(func(r rune) rune {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' {
+			return r
+		}
+		return '-'
+	}, filepath.Join(home, "refactor-api"))
+
+	countFile := filepath.Join(t.TempDir(), "sessions")
+	service := newTestUsageService()
+	service.ccusagePath = writeCCUsageScript(t, fmt.Sprintf(`if [ "$1" = session ]; then
+  echo run >> %q
+  echo '{"sessions":[{"sessionId":"%s","totalCost":4.2}]}'
+else
+  echo '{"daily":[{"date":"2025-03-14","totalTokens":10,"totalCost":6}]}'
+fi`, countFile, project))
+	clock := &fixedClock{now: time.Date(2025, 3, 14, 15, 0, 0, 0, time.Local)}
+	service.SetClock(clock)
+
+	sessionRuns := func() int {
+		data, err := os.ReadFile(countFile)
+		require.NoError(t, err)
+		return strings.Count(string(data), "run")
+	}
+
+	state, err := service.UpdateUsage()
+	require.NoError(t, err)
+	assert.Equal(t, &models.SessionCost{Name: "refactor-api", Cost: 4.2}, state.TopSession)
+
+	clock.now = clock.now.Add(sessionRefreshInterval - time.Second)
+	state, err = service.UpdateUsage()
+	require.NoError(t, err)
+	assert.Equal(t, 1, sessionRuns(), "the session report runs less often than polls")
+	assert.NotNil(t, state.TopSession, "the last result is kept between refreshes")
+
+	clock.now = clock.now.Add(time.Second)
+	_, err = service.UpdateUsage()
+	require.NoError(t, err)
+	assert.Equal(t, 2, sessionRuns())
+}
+
+func TestUsageService_TagCosts(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	project := models.EncodeProjectPath(filepath.Join(home, "work", "client-a"))
+
+	service := newTestUsageService()
+	service.ccusagePath = writeCCUsageScript(t, fmt.Sprintf(`if [ "$3" = --instances ]; then
+  echo '{"projects":{"%s":[{"date":"2025-03-13","totalCost":9},{"date":"2025-03-14","totalCost":4.2}],"-tmp-scratch":[{"date":"2025-03-14","totalCost":1}]}}'
+elif [ "$1" = session ]; then
+  echo '{"sessions":[{"sessionId":"%[1]s","totalCost":13.2},{"sessionId":"-tmp-scratch","totalCost":1}]}'
+else
+  echo '{"daily":[{"date":"2025-03-14","totalTokens":10,"totalCost":6}]}'
+fi`, project))
+	service.SetClock(&fixedClock{now: time.Date(2025, 3, 14, 15, 0, 0, 0, time.Local)})
+
+	state, err := service.UpdateUsage()
+	require.NoError(t, err)
+	assert.Nil(t, state.TagCosts, "no project_tags, no split")
+
+	config := models.ConfigDefaults()
+	config.CCUsagePath = service.ccusagePath
+	config.ProjectTags = []models.ProjectTag{{Path: "~/work/client-a", Tag: "client-A"}}
+	service.ApplyConfig(config)
+	state, err = service.UpdateUsage()
+	require.NoError(t, err)
+	assert.Equal(t, []models.TagCost{{Tag: "client-A", Cost: 4.2}, {Tag: models.UntaggedTag, Cost: 1}}, state.TagCosts,
+		"new rules re-split today without waiting for the next session refresh, leaving out earlier days")
+}
+
+func TestUsageService_SessionAlerts(t *testing.T) {
+	service := newTestUsageService()
+	service.ccusagePath = writeCCUsageScript(t, `if [ "$1" = session ]; then
+  echo '{"sessions":[{"sessionId":"-tmp-runaway","totalCost":7.5},{"sessionId":"-tmp-docs","totalCost":1}]}'
+else
+  echo '{"daily":[{"date":"2025-03-14","totalTokens":10,"totalCost":8.5}]}'
+fi`)
+	service.SetClock(&fixedClock{now: time.Date(2025, 3, 14, 15, 0, 0, 0, time.Local)})
+
+	state, err := service.UpdateUsage()
+	require.NoError(t, err)
+	assert.Nil(t, state.SessionAlerts, "no session_cap, no alerts")
+
+	config := models.ConfigDefaults()
+	config.CCUsagePath = service.ccusagePath
+	config.SessionCap = 5
+	service.ApplyConfig(config)
+	state, err = service.UpdateUsage()
+	require.NoError(t, err)
+	assert.Equal(t, models.Green, state.Status, "the day is still under its thresholds")
+	assert.Equal(t, []models.SessionCost{{Name: "tmp-runaway", Cost: 7.5}}, state.SessionAlerts,
+		"a new cap applies to the last session report")
+}
+
+func TestUsageService_TopSessionFailureKeepsLastResult(t *testing.T) {
+	service := newTestUsageService()
+	service.ccusagePath = writeCCUsageScript(t, `if [ "$1" = session ]; then exit 1; fi
+echo '{"daily":[{"date":"2025-03-14","totalTokens":10,"totalCost":6}]}'`)
+	clock := &fixedClock{now: time.Date(2025, 3, 14, 15, 0, 0, 0, time.Local)}
+	service.SetClock(clock)
+	previous := &models.SessionCost{Name: "docs", Cost: 1}
+	service.topSession = previous
+	service.sessionDay = "20250314"
+
+	state, err := service.UpdateUsage()
+	require.NoError(t, err, "a failed session report doesn't fail the poll")
+	assert.Equal(t, previous, state.TopSession)
+
+	clock.now = clock.now.AddDate(0, 0, 1)
+	state, _ = service.UpdateUsage()
+	assert.Nil(t, state.TopSession, "a new day drops yesterday's top session")
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// SettingsArchiveVersion is the layout ExportSettings writes; importing a
+// newer one is refused.
+const SettingsArchiveVersion = 1
+
+// maxSettingsEntry bounds each file read from a settings archive.
+const maxSettingsEntry = 64 << 20
+
+// Entry names in a settings archive, a .tar.gz holding the manifest, the
+// config file and its overlay under config/, and with history the data
+// directory under data/.
+const (
+	settingsManifestName = "manifest.json"
+	settingsConfigPrefix = "config/"
+	settingsDataPrefix   = "data/"
+)
+
+// SettingsManifest describes a settings archive.
+type SettingsManifest struct {
+	Version    int       `json:"version"`
+	Created    time.Time `json:"created"`
+	AppVersion string    `json:"app_version,omitempty"`
+	OS         string    `json:"os"`
+	Config     string    `json:"config,omitempty"`  // base config file name, e.g. config.yaml
+	Overlay    string    `json:"overlay,omitempty"` // local overlay file name
+	DataFiles  []string  `json:"data_files,omitempty"`
+}
+
+// ExportSettings writes a settings archive of the config file and its
+// local overlay, where they exist, and with includeHistory every file in
+// the data directory (usage history, monthly archives, ledger state).
+// Config values are copied as they are, so secrets in them travel along
+// unless encrypted first.
+func ExportSettings(w io.Writer, configService *ConfigService, paths *PathResolver, includeHistory bool, appVersion string, now time.Time) (*SettingsManifest, error) {
+	manifest := &SettingsManifest{
+		Version:    SettingsArchiveVersion,
+		Created:    now.UTC(),
+		AppVersion: appVersion,
+		OS:         runtime.GOOS,
+	}
+	files := map[string]string{} // archive name -> source path
+	for _, source := range []struct {
+		path string
+		name *string
+	}{
+		{configService.GetConfigPath(), &manifest.Config},
+		{configService.LocalConfigPath(), &manifest.Overlay},
+	} {
+		if _, err := os.Stat(source.path); err == nil {
+			*source.name = filepath.Base(source.path)
+			files[settingsConfigPrefix+*source.name] = source.path
+		}
+	}
+	if includeHistory {
+		dataDir := paths.DataDir()
+		err := filepath.WalkDir(dataDir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) && p == dataDir {
+					return nil
+				}
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(dataDir, p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			manifest.DataFiles = append(manifest.DataFiles, rel)
+			files[settingsDataPrefix+rel] = p
+			return nil
+		})
+		if err != nil {
+			return nil, lib.WrapError(err, lib.ErrCodeSystem, "failed to read the data directory")
+		}
+	}
+	if len(files) == 0 {
+		return nil, lib.ValidationError("no config file at " + configService.GetConfigPath() + " to export")
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeSettingsEntry(tw, settingsManifestName, manifestData, now); err != nil {
+		return nil, err
+	}
+	for _, name := range append(configEntryNames(manifest), dataEntryNames(manifest)...) {
+		data, err := os.ReadFile(files[name])
+		if err != nil {
+			return nil, lib.WrapError(err, lib.ErrCodeSystem, "failed to read "+files[name])
+		}
+		if err := writeSettingsEntry(tw, name, data, now); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeSystem, "failed to write settings archive")
+	}
+	if err := gz.Close(); err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeSystem, "failed to write settings archive")
+	}
+	return manifest, nil
+}
+
+func writeSettingsEntry(tw *tar.Writer, name string, data []byte, now time.Time) error {
+	header := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), ModTime: now}
+	if err := tw.WriteHeader(header); err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to write settings archive")
+	}
+	if _, err := tw.Write(data); err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to write settings archive")
+	}
+	return nil
+}
+
+func configEntryNames(m *SettingsManifest) []string {
+	var names []string
+	for _, name := range []string{m.Config, m.Overlay} {
+		if name != "" {
+			names = append(names, settingsConfigPrefix+name)
+		}
+	}
+	return names
+}
+
+func dataEntryNames(m *SettingsManifest) []string {
+	names := make([]string, 0, len(m.DataFiles))
+	for _, name := range m.DataFiles {
+		names = append(names, settingsDataPrefix+name)
+	}
+	return names
+}
+
+// ImportSettings restores a settings archive: the config goes to
+// configService's config file and overlay, converted to their format when
+// the archive's differ, and any data files into the data directory. The
+// config is validated and nothing is written unless every file is
+// readable; existing files are replaced only with overwrite.
+func ImportSettings(r io.Reader, configService *ConfigService, paths *PathResolver, overwrite bool) (*SettingsManifest, error) {
+	manifest, entries, err := readSettingsArchive(r)
+	if err != nil {
+		return nil, err
+	}
+
+	writes := map[string][]byte{} // destination -> contents
+	var order []string
+	add := func(dest string, data []byte) {
+		writes[dest] = data
+		order = append(order, dest)
+	}
+
+	var config models.Config
+	if manifest.Config != "" {
+		dest := configService.GetConfigPath()
+		data, err := convertConfigFile(manifest.Config, dest, entries[settingsConfigPrefix+manifest.Config])
+		if err != nil {
+			return nil, err
+		}
+		if err := DecodeConfig(ConfigFormatFor(dest), data, &config); err != nil {
+			return nil, lib.WrapError(err, lib.ErrCodeConfig, "archived "+manifest.Config+" is invalid")
+		}
+		add(dest, data)
+	} else {
+		config = *models.ConfigDefaults()
+	}
+	if manifest.Overlay != "" {
+		dest := configService.LocalConfigPath()
+		data, err := convertConfigFile(manifest.Overlay, dest, entries[settingsConfigPrefix+manifest.Overlay])
+		if err != nil {
+			return nil, err
+		}
+		if err := DecodeConfig(ConfigFormatFor(dest), data, &config); err != nil {
+			return nil, lib.WrapError(err, lib.ErrCodeConfig, "archived "+manifest.Overlay+" is invalid")
+		}
+		add(dest, data)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeConfig, "archived config is invalid")
+	}
+	for _, name := range manifest.DataFiles {
+		add(filepath.Join(paths.DataDir(), filepath.FromSlash(name)), entries[settingsDataPrefix+name])
+	}
+
+	if !overwrite {
+		var existing []string
+		for _, dest := range order {
+			if _, err := os.Stat(dest); err == nil {
+				existing = append(existing, dest)
+			}
+		}
+		if len(existing) > 0 {
+			return nil, lib.ValidationError("would replace " + strings.Join(existing, ", ") + " (use --force to overwrite)")
+		}
+	}
+
+	for _, dest := range order {
+		if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+			return nil, lib.WrapError(err, lib.ErrCodeSystem, "failed to create "+filepath.Dir(dest))
+		}
+		if err := os.WriteFile(dest, writes[dest], 0o600); err != nil {
+			return nil, lib.WrapError(err, lib.ErrCodeSystem, "failed to write "+dest)
+		}
+	}
+	return manifest, nil
+}
+
+// readSettingsArchive reads the manifest and every file it lists, checking
+// that nothing is missing and no entry names a path outside its directory.
+func readSettingsArchive(r io.Reader) (*SettingsManifest, map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, lib.WrapError(err, lib.ErrCodeValidation, "not a settings archive")
+	}
+	defer gz.Close()
+
+	entries := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, lib.WrapError(err, lib.ErrCodeValidation, "settings archive is corrupt")
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := header.Name
+		if !localArchiveName(name) {
+			return nil, nil, lib.ValidationError(fmt.Sprintf("settings archive entry %q is outside the archive", name))
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxSettingsEntry+1))
+		if err != nil {
+			return nil, nil, lib.WrapError(err, lib.ErrCodeValidation, "settings archive is corrupt")
+		}
+		if len(data) > maxSettingsEntry {
+			return nil, nil, lib.ValidationError(fmt.Sprintf("settings archive entry %q is too large", name))
+		}
+		entries[name] = data
+	}
+
+	data, ok := entries[settingsManifestName]
+	if !ok {
+		return nil, nil, lib.ValidationError("not a settings archive: no " + settingsManifestName)
+	}
+	var manifest SettingsManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, nil, lib.WrapError(err, lib.ErrCodeValidation, "settings archive has an invalid manifest")
+	}
+	if manifest.Version < 1 || manifest.Version > SettingsArchiveVersion {
+		return nil, nil, lib.ValidationError(fmt.Sprintf("settings archive version %d isn't supported; upgrade cc-dailyuse-bar", manifest.Version))
+	}
+	for _, name := range []string{manifest.Config, manifest.Overlay} {
+		if name != "" && (!localArchiveName(name) || filepath.Base(filepath.FromSlash(name)) != filepath.FromSlash(name)) {
+			return nil, nil, lib.ValidationError(fmt.Sprintf("settings archive config name %q isn't a file name", name))
+		}
+	}
+	for _, name := range manifest.DataFiles {
+		if !localArchiveName(name) {
+			return nil, nil, lib.ValidationError(fmt.Sprintf("settings archive data file %q is outside the data directory", name))
+		}
+	}
+	for _, name := range append(configEntryNames(&manifest), dataEntryNames(&manifest)...) {
+		if _, ok := entries[name]; !ok {
+			return nil, nil, lib.ValidationError("settings archive is missing " + name)
+		}
+	}
+	return &manifest, entries, nil
+}
+
+// localArchiveName reports whether an archive path, which uses slashes,
+// stays inside the directory it is restored to on this platform; on
+// Windows that also rules out names like ..\evil and C:evil.
+func localArchiveName(name string) bool {
+	return name == path.Clean(name) && filepath.IsLocal(filepath.FromSlash(name))
+}
+
+// convertConfigFile returns the archived config file name's contents in
+// the format dest uses.
+func convertConfigFile(name, dest string, data []byte) ([]byte, error) {
+	from, to := ConfigFormatFor(name), ConfigFormatFor(dest)
+	if from == to {
+		return data, nil
+	}
+	doc, err := decodeDocument(from, data)
+	if err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeConfig, "archived "+name+" is invalid")
+	}
+	converted, err := encodeDocument(to, doc)
+	if err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeConfig, "failed to convert "+name+" to "+string(to))
+	}
+	return converted, nil
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+// newSettingsMachine sets up a config service and data directory under a
+// temp dir, as on one machine.
+func newSettingsMachine(t *testing.T, configName string) (*ConfigService, *PathResolver) {
+	t.Helper()
+	paths := NewPathResolverAt(t.TempDir())
+	svc := NewConfigService()
+	svc.SetConfigPath(filepath.Join(paths.ConfigDir(), configName))
+	return svc, paths.WithConfigFile(svc.GetConfigPath())
+}
+
+func TestSettingsArchive_RoundTrip(t *testing.T) {
+	from, fromPaths := newSettingsMachine(t, "config.yaml")
+	config := models.ConfigDefaults()
+	config.RedThreshold = 42
+	require.NoError(t, from.Save(config))
+	require.NoError(t, os.WriteFile(from.LocalConfigPath(), []byte("yellow_threshold: 12\n"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(fromPaths.DataDir(), "archive"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(fromPaths.DataDir(), "history.jsonl"), []byte("{}\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(fromPaths.DataDir(), "archive", "2025-03.json"), []byte("{}"), 0o600))
+
+	var archive bytes.Buffer
+	now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	manifest, err := ExportSettings(&archive, from, fromPaths, true, "1.2.3", now)
+	require.NoError(t, err)
+	assert.Equal(t, "config.yaml", manifest.Config)
+	assert.Equal(t, "config.local.yaml", manifest.Overlay)
+	assert.Equal(t, []string{"archive/2025-03.json", "history.jsonl"}, manifest.DataFiles)
+
+	// The other machine keeps its config as TOML.
+	to, toPaths := newSettingsMachine(t, "config.toml")
+	imported, err := ImportSettings(bytes.NewReader(archive.Bytes()), to, toPaths, false)
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3", imported.AppVersion)
+	assert.True(t, now.Equal(imported.Created))
+
+	loaded, err := to.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 42.0, loaded.RedThreshold)
+	assert.Equal(t, 12.0, loaded.YellowThreshold)
+	assert.FileExists(t, filepath.Join(toPaths.ConfigDir(), "config.local.toml"))
+	assert.FileExists(t, filepath.Join(toPaths.DataDir(), "archive", "2025-03.json"))
+
+	_, err = ImportSettings(bytes.NewReader(archive.Bytes()), to, toPaths, false)
+	assert.ErrorContains(t, err, "use --force")
+	_, err = ImportSettings(bytes.NewReader(archive.Bytes()), to, toPaths, true)
+	assert.NoError(t, err)
+}
+
+func TestExportSettings_WithoutHistoryOrConfig(t *testing.T) {
+	svc, paths := newSettingsMachine(t, "config.yaml")
+	var archive bytes.Buffer
+	_, err := ExportSettings(&archive, svc, paths, false, "dev", time.Now())
+	assert.ErrorContains(t, err, "no config file")
+
+	require.NoError(t, svc.Save(models.ConfigDefaults()))
+	require.NoError(t, os.MkdirAll(paths.DataDir(), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(paths.DataDir(), "history.jsonl"), []byte("{}\n"), 0o600))
+	manifest, err := ExportSettings(&archive, svc, paths, false, "dev", time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, manifest.DataFiles)
+}
+
+// settingsArchive builds an archive from a manifest and entries by hand.
+func settingsArchive(t *testing.T, manifest SettingsManifest, entries map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	entries[settingsManifestName] = string(data)
+	for name, body := range entries {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(body))}))
+		_, err := tw.Write([]byte(body))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestImportSettings_Rejects(t *testing.T) {
+	valid, err := EncodeConfig(FormatYAML, models.ConfigDefaults())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		manifest SettingsManifest
+		entries  map[string]string
+		want     string
+	}{
+		{
+			name:     "newer version",
+			manifest: SettingsManifest{Version: SettingsArchiveVersion + 1},
+			want:     "isn't supported",
+		},
+		{
+			name:     "missing file",
+			manifest: SettingsManifest{Version: 1, Config: "config.yaml"},
+			want:     "missing config/config.yaml",
+		},
+		{
+			name:     "invalid config",
+			manifest: SettingsManifest{Version: 1, Config: "config.yaml"},
+			entries:  map[string]string{"config/config.yaml": "update_interval: 1\n"},
+			want:     "archived config is invalid",
+		},
+		{
+			name:     "data outside data dir",
+			manifest: SettingsManifest{Version: 1, Config: "config.yaml", DataFiles: []string{"../evil"}},
+			entries:  map[string]string{"config/config.yaml": string(valid), "data/../evil": "x"},
+			want:     "outside",
+		},
+		{
+			name:     "config name with a directory",
+			manifest: SettingsManifest{Version: 1, Config: "../config.yaml"},
+			entries:  map[string]string{"config/../config.yaml": string(valid)},
+			want:     "outside",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.entries == nil {
+				tt.entries = map[string]string{}
+			}
+			svc, paths := newSettingsMachine(t, "config.yaml")
+			_, err := ImportSettings(bytes.NewReader(settingsArchive(t, tt.manifest, tt.entries)), svc, paths, false)
+			assert.ErrorContains(t, err, tt.want)
+			assert.NoFileExists(t, svc.GetConfigPath())
+		})
+	}
+
+	svc, paths := newSettingsMachine(t, "config.yaml")
+	_, err = ImportSettings(bytes.NewReader([]byte("not gzip")), svc, paths, false)
+	assert.ErrorContains(t, err, "not a settings archive")
+}
+
+func TestLocalArchiveName(t *testing.T) {
+	for _, name := range []string{"config.yaml", "history/2025-03.jsonl"} {
+		assert.True(t, localArchiveName(name), name)
+	}
+	for _, name := range []string{"", "..", "../evil", "a/../../evil", "/etc/passwd", "./config.yaml"} {
+		assert.False(t, localArchiveName(name), name)
+	}
+	// Backslashes only separate paths on Windows.
+	assert.Equal(t, runtime.GOOS != "windows", localArchiveName(`..\evil`))
+	assert.Equal(t, runtime.GOOS != "windows", localArchiveName(`C:evil`))
+}
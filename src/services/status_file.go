@@ -0,0 +1,82 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// StatusFile publishes the latest state as JSON at a well-known path so
+// widgets (WidgetKit, Übersicht) and scripts can read it without talking
+// to the control socket. Each write replaces the file atomically.
+type StatusFile struct {
+	path string
+}
+
+// DefaultStatusFilePath is status.json beside the control socket:
+// $XDG_RUNTIME_DIR/cc-dailyuse-bar on Linux and
+// ~/Library/Application Support/cc-dailyuse-bar on macOS.
+func DefaultStatusFilePath() string {
+	return filepath.Join(DefaultPaths().RuntimeDir(), "status.json")
+}
+
+// NewStatusFile creates a StatusFile at the default location.
+func NewStatusFile() *StatusFile {
+	return NewStatusFileAt(DefaultStatusFilePath())
+}
+
+// NewStatusFileAt creates a StatusFile backed by path.
+func NewStatusFileAt(path string) *StatusFile {
+	return &StatusFile{path: path}
+}
+
+// Path returns the file location.
+func (sf *StatusFile) Path() string {
+	return sf.path
+}
+
+// Write replaces the file with snapshot.
+func (sf *StatusFile) Write(snapshot models.StatusSnapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to encode status file")
+	}
+	if err := os.MkdirAll(filepath.Dir(sf.path), 0o700); err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to create status file directory")
+	}
+	if err := lib.WriteFileAtomic(sf.path, append(data, '\n')); err != nil {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to write status file")
+	}
+	return nil
+}
+
+// Read returns the last snapshot written and when it was written.
+func (sf *StatusFile) Read() (models.StatusSnapshot, time.Time, error) {
+	var snapshot models.StatusSnapshot
+	info, err := os.Stat(sf.path)
+	if err != nil {
+		return snapshot, time.Time{}, lib.WrapError(err, lib.ErrCodeSystem, "failed to read status file")
+	}
+	data, err := os.ReadFile(sf.path)
+	if err != nil {
+		return snapshot, time.Time{}, lib.WrapError(err, lib.ErrCodeSystem, "failed to read status file")
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, time.Time{}, lib.WrapError(err, lib.ErrCodeSystem, "failed to decode status file")
+	}
+	return snapshot, info.ModTime(), nil
+}
+
+// Remove deletes the file so readers can tell the app is no longer
+// running. A missing file is not an error.
+func (sf *StatusFile) Remove() error {
+	if err := os.Remove(sf.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to remove status file")
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+func TestStatusFile_WriteAndRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cc-dailyuse-bar", "status.json")
+	sf := NewStatusFileAt(path)
+	assert.Equal(t, path, sf.Path())
+
+	state := &models.UsageState{DailyCost: 12.4, Status: models.Yellow, IsAvailable: true}
+	require.NoError(t, sf.Write(models.NewStatusSnapshot(state, models.ConfigDefaults())))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var got models.StatusSnapshot
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, "yellow", got.Status)
+	assert.Equal(t, "CC 🟡 $12.40", got.Title)
+	assert.Equal(t, models.StatusSnapshotVersion, got.Version)
+
+	read, written, err := sf.Read()
+	require.NoError(t, err)
+	assert.Equal(t, got, read)
+	assert.WithinDuration(t, time.Now(), written, time.Minute)
+
+	require.NoError(t, sf.Remove())
+	_, _, err = sf.Read()
+	assert.Error(t, err)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, sf.Remove(), "removing a missing file is fine")
+}
+
+func TestDefaultStatusFilePath(t *testing.T) {
+	assert.Equal(t, "status.json", filepath.Base(DefaultStatusFilePath()))
+	assert.Equal(t, "cc-dailyuse-bar", filepath.Base(filepath.Dir(DefaultStatusFilePath())))
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// teamExportExt is the extension of teammates' exported ccusage files.
+const teamExportExt = ".json"
+
+// TeamService aggregates ccusage exports that teammates drop into a shared
+// directory (one `ccusage daily --json > <dir>/<name>.json` file each), so a
+// lead can watch a shared budget without any server infrastructure.
+type TeamService struct {
+	dir    string
+	clock  Clock
+	logger *lib.Logger
+}
+
+// NewTeamService creates a TeamService reading exports from dir.
+func NewTeamService(dir string) *TeamService {
+	return &TeamService{
+		dir:    dir,
+		clock:  systemClock{},
+		logger: lib.NewLogger("team-service"),
+	}
+}
+
+// SetClock overrides the time source, primarily for tests.
+func (ts *TeamService) SetClock(clock Clock) {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	ts.clock = clock
+}
+
+// Collect reads every export in the team directory and returns per-person
+// usage for today, sorted by name. A malformed export is reported on its
+// member rather than failing the whole team; only an unreadable directory
+// is an error.
+func (ts *TeamService) Collect() (*models.TeamUsage, error) {
+	entries, err := os.ReadDir(ts.dir)
+	if err != nil {
+		return nil, lib.WrapError(err, lib.ErrCodeSystem, "failed to read team directory")
+	}
+
+	weekStart, today := currentWeekRange(ts.clock.Now())
+	todayKey := today.Format("2006-01-02")
+	weekKey := weekStart.Format("2006-01-02")
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.EqualFold(filepath.Ext(name), teamExportExt) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	team := &models.TeamUsage{}
+	for _, name := range names {
+		team.AddMember(ts.readMember(name, todayKey, weekKey))
+	}
+	return team, nil
+}
+
+func (ts *TeamService) readMember(file, today, weekStart string) models.TeamMember {
+	member := models.TeamMember{Name: strings.TrimSuffix(file, filepath.Ext(file))}
+
+	data, err := os.ReadFile(filepath.Join(ts.dir, file))
+	if err != nil {
+		return ts.skipMember(member, file, err)
+	}
+	scan, err := scanDailyOutput(data, today, weekStart)
+	if err != nil {
+		return ts.skipMember(member, file, err)
+	}
+
+	member.DailyCount = scan.Today.TotalTokens
+	member.DailyCost = scan.Today.TotalCost
+	member.WeeklyCost = scan.WeekCost
+	member.HasToday = scan.Found
+	member.LatestDate = scan.LatestDate
+	return member
+}
+
+func (ts *TeamService) skipMember(member models.TeamMember, file string, err error) models.TeamMember {
+	ts.logger.Warn("Skipping unreadable team export", map[string]interface{}{
+		"file":  file,
+		"error": err.Error(),
+	})
+	member.Error = err.Error()
+	return member
+}
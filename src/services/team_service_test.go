@@ -0,0 +1,67 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTeamExport(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+}
+
+func TestTeamService_Collect(t *testing.T) {
+	dir := t.TempDir()
+	today := time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local) // Friday
+
+	writeTeamExport(t, dir, "bob.json", string(buildCCUsageHistory(t, 7, today)))
+	writeTeamExport(t, dir, "alice.json", `{"daily": [
+		{"date": "2025-03-10", "totalTokens": 100, "totalCost": 1.0},
+		{"date": "2025-03-14", "totalTokens": 500, "totalCost": 4.25}
+	]}`)
+	writeTeamExport(t, dir, "carol.json", `{"daily": [{"date": "2025-03-12", "totalTokens": 9, "totalCost": 2}]}`)
+	writeTeamExport(t, dir, "dave.json", "not json")
+	writeTeamExport(t, dir, "notes.txt", "ignored")
+	writeTeamExport(t, dir, ".eve.json", "ignored")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "archive.json"), 0o755))
+
+	service := NewTeamService(dir)
+	service.SetClock(fixedClock{now: today})
+
+	team, err := service.Collect()
+	require.NoError(t, err)
+	require.Len(t, team.Members, 4)
+
+	names := make([]string, 0, len(team.Members))
+	for _, m := range team.Members {
+		names = append(names, m.Name)
+	}
+	assert.Equal(t, []string{"alice", "bob", "carol", "dave"}, names)
+
+	alice := team.Members[0]
+	assert.True(t, alice.HasToday)
+	assert.Equal(t, 4.25, alice.DailyCost)
+	assert.Equal(t, 5.25, alice.WeeklyCost)
+
+	carol := team.Members[2]
+	assert.False(t, carol.HasToday)
+	assert.Equal(t, "2025-03-12", carol.LatestDate)
+	assert.Equal(t, 2.0, carol.WeeklyCost)
+
+	assert.NotEmpty(t, team.Members[3].Error)
+
+	// bob's export puts $0.50 on today.
+	assert.InDelta(t, 4.75, team.DailyCost, 1e-9)
+	assert.Equal(t, 1500, team.DailyCount)
+}
+
+func TestTeamService_Collect_MissingDir(t *testing.T) {
+	service := NewTeamService(filepath.Join(t.TempDir(), "missing"))
+	_, err := service.Collect()
+	assert.Error(t, err)
+}
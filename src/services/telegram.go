@@ -0,0 +1,210 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// telegramAPI is the Bot API's base URL; tests point it at a fake server.
+var telegramAPI = "https://api.telegram.org"
+
+// TelegramBot sends messages to one Telegram chat through the Bot API, for
+// alerts on a phone while an agent runs unattended.
+type TelegramBot struct {
+	token  string
+	chatID string
+	client *http.Client
+	logger *lib.Logger
+}
+
+// NewTelegramBot creates a bot that posts to chatID with token, both from
+// @BotFather and the config.
+func NewTelegramBot(token, chatID string) *TelegramBot {
+	return &TelegramBot{
+		token:  token,
+		chatID: chatID,
+		client: &http.Client{Timeout: 10 * time.Second},
+		logger: lib.NewLogger("telegram"),
+	}
+}
+
+// NewTelegramBotForConfig returns the config's bot, or nil when Telegram
+// isn't set up.
+func NewTelegramBotForConfig(config *models.Config) *TelegramBot {
+	if config.TelegramBotToken == "" || config.TelegramChatID == "" {
+		return nil
+	}
+	return NewTelegramBot(config.TelegramBotToken, config.TelegramChatID)
+}
+
+// telegramResponse is the envelope every Bot API answer comes in.
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+// Send posts text to the chat. Errors never include the token, which is
+// part of the request URL.
+func (b *TelegramBot) Send(text string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"chat_id":                  b.chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := b.client.Post(telegramAPI+"/bot"+b.token+"/sendMessage", "application/json", bytes.NewReader(body))
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return lib.WrapError(err, lib.ErrCodeSystem, "failed to reach Telegram")
+	}
+	defer resp.Body.Close()
+
+	var answer telegramResponse
+	if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil || !answer.OK {
+		if answer.Description == "" {
+			answer.Description = resp.Status
+		}
+		return lib.SystemError("Telegram refused the message: " + answer.Description)
+	}
+	b.logger.Debug("Sent Telegram message", map[string]interface{}{
+		"chat_id": b.chatID,
+	})
+	return nil
+}
+
+// TelegramSummary renders a finished day for the ledger's Telegram
+// destination: its total, tokens, and cost per model, largest first.
+func TelegramSummary(row LedgerRow, format models.CostFormat) string {
+	date := row.Date
+	if day, err := time.ParseInLocation("2006-01-02", row.Date, time.Local); err == nil {
+		date = day.Weekday().String()[:3] + " " + format.Locale.FormatDate(day)
+	}
+	var text strings.Builder
+	fmt.Fprintf(&text, "📊 Claude Code, %s: %s, %s tokens", date, format.Format(row.Cost), format.Locale.FormatTokens(row.Tokens))
+
+	names := make([]string, 0, len(row.Models))
+	for name := range row.Models {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if row.Models[names[i]] != row.Models[names[j]] {
+			return row.Models[names[i]] > row.Models[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		fmt.Fprintf(&text, "\n• %s: %s", name, format.Format(row.Models[name]))
+	}
+	return text.String()
+}
+
+// TelegramAlerts sends an alert to the telegram sink the first time each
+// day that today's status reaches yellow and red. Alerts are sent in the
+// background; a failed one is retried on the next Observe.
+type TelegramAlerts struct {
+	mutex  sync.Mutex
+	sent   map[models.AlertStatus]string // status -> day (YYYY-MM-DD) it was sent or is being sent
+	send   func(sink NotificationSink, alert Alert) error
+	logger *lib.Logger
+}
+
+// NewTelegramAlerts creates a TelegramAlerts.
+func NewTelegramAlerts() *TelegramAlerts {
+	return &TelegramAlerts{
+		sent:   make(map[models.AlertStatus]string),
+		send:   NotificationSink.Send,
+		logger: lib.NewLogger("telegram"),
+	}
+}
+
+// Observe alerts when state has reached yellow or red for the first time
+// today. A jump straight to red sends only the red alert. Nothing is sent
+// when Telegram isn't configured, in quiet mode, or during quiet hours,
+// nor when a telegram entry in notification_sinks takes over alerting.
+func (ta *TelegramAlerts) Observe(state *models.UsageState, config *models.Config, now time.Time) {
+	if config.HasSink(models.SinkTelegram) || state == nil || !state.IsAvailable || state.Quiet || state.Paused {
+		return
+	}
+	sink, err := NewNotificationSink(models.NotificationSink{Type: models.SinkTelegram}, config)
+	if err != nil {
+		return // not configured
+	}
+	if state.Status != models.Yellow && state.Status != models.Red {
+		return
+	}
+
+	today := now.Format("2006-01-02")
+	ta.mutex.Lock()
+	if ta.sent[state.Status] == today {
+		ta.mutex.Unlock()
+		return
+	}
+	// The levels this send stands for: a jump to red covers yellow too,
+	// unless yellow was already sent today.
+	covered := []models.AlertStatus{state.Status}
+	if state.Status == models.Red && ta.sent[models.Yellow] != today {
+		covered = append(covered, models.Yellow)
+	}
+	for _, status := range covered {
+		ta.sent[status] = today
+	}
+	ta.mutex.Unlock()
+
+	alert, status := TelegramAlert(state, config), state.Status
+	go func() {
+		if err := ta.send(sink, alert); err != nil {
+			ta.mutex.Lock()
+			for _, status := range covered {
+				delete(ta.sent, status)
+			}
+			ta.mutex.Unlock()
+			ta.logger.Warn("Failed to send Telegram alert", map[string]interface{}{
+				"status": status.ColorName(),
+				"error":  err.Error(),
+			})
+		}
+	}()
+}
+
+// TelegramAlert builds the alert for state's status, e.g. "🔴 Claude Code:
+// Critical spend" with "$25.00 today (red at $20.00)".
+func TelegramAlert(state *models.UsageState, config *models.Config) Alert {
+	format := config.CostFormat()
+	alert := Alert{
+		Key:     "telegram:" + state.Status.ColorName(),
+		Title:   fmt.Sprintf("%s Claude Code: %s spend", state.StatusSymbol(config.Symbols()), state.Status.String()),
+		Message: format.Format(state.DailyCost) + " today",
+		Level:   models.SinkLevelFor(state.Status),
+	}
+	if state.Level != "" {
+		alert.Message += " (level " + state.Level + ")"
+		return alert
+	}
+	day := state.LastUpdate
+	if day.IsZero() {
+		day = time.Now()
+	}
+	yellow, red := config.ThresholdsFor(day.Weekday())
+	threshold := yellow
+	if state.Status == models.Red {
+		threshold = red
+	}
+	alert.Message += fmt.Sprintf(" (%s at %s)", state.Status.ColorName(), format.Format(threshold))
+	return alert
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+const testTelegramToken = "123456:ABC-DEF1234ghIkl"
+
+// fakeTelegram serves the Bot API's sendMessage and records the messages.
+type fakeTelegram struct {
+	mu       sync.Mutex
+	messages []map[string]interface{}
+	refuse   string // description to refuse messages with, when set
+}
+
+func newFakeTelegram(t *testing.T) *fakeTelegram {
+	t.Helper()
+	fake := &fakeTelegram{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bot"+testTelegramToken+"/sendMessage" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"ok":false,"description":"Not Found"}`))
+			return
+		}
+		var message map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&message)
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		if fake.refuse != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "description": fake.refuse})
+			return
+		}
+		fake.messages = append(fake.messages, message)
+		_, _ = w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	original := telegramAPI
+	telegramAPI = server.URL
+	t.Cleanup(func() { telegramAPI = original })
+	return fake
+}
+
+func TestTelegramBot_Send(t *testing.T) {
+	fake := newFakeTelegram(t)
+	bot := NewTelegramBot(testTelegramToken, "-100123")
+
+	require.NoError(t, bot.Send("🔴 Claude Code: Critical spend"))
+	require.Len(t, fake.messages, 1)
+	assert.Equal(t, "-100123", fake.messages[0]["chat_id"])
+	assert.Equal(t, "🔴 Claude Code: Critical spend", fake.messages[0]["text"])
+
+	fake.refuse = "Bad Request: chat not found"
+	assert.ErrorContains(t, bot.Send("hi"), "chat not found")
+}
+
+func TestTelegramBot_ErrorsOmitToken(t *testing.T) {
+	telegramAPI = "http://127.0.0.1:1"
+	t.Cleanup(func() { telegramAPI = "https://api.telegram.org" })
+
+	err := NewTelegramBot(testTelegramToken, "1").Send("hi")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), testTelegramToken)
+}
+
+func TestNewTelegramBotForConfig(t *testing.T) {
+	config := models.ConfigDefaults()
+	assert.Nil(t, NewTelegramBotForConfig(config))
+
+	config.TelegramBotToken, config.TelegramChatID = testTelegramToken, "1"
+	assert.NotNil(t, NewTelegramBotForConfig(config))
+}
+
+func TestTelegramSummary(t *testing.T) {
+	row := LedgerRow{Date: "2025-03-14", Cost: 12.34, Tokens: 48210, Models: map[string]float64{
+		"claude-sonnet-4": 2.34,
+		"claude-opus-4":   10,
+	}}
+	assert.Equal(t, "📊 Claude Code, Fri 2025-03-14: $12.34, 48.2K tokens\n• claude-opus-4: $10.00\n• claude-sonnet-4: $2.34",
+		TelegramSummary(row, models.ConfigDefaults().CostFormat()))
+}
+
+func TestTelegramAlert(t *testing.T) {
+	config := models.ConfigDefaults()
+	config.YellowThreshold, config.RedThreshold = 10, 20
+	state := &models.UsageState{Status: models.Red, DailyCost: 25, IsAvailable: true, LastUpdate: time.Now()}
+
+	alert := TelegramAlert(state, config)
+	assert.Equal(t, "🔴 Claude Code: Critical spend", alert.Title)
+	assert.Equal(t, "$25.00 today (red at $20.00)", alert.Message)
+	assert.Equal(t, models.SinkLevelRed, alert.Level)
+
+	state.Level = "overtime"
+	assert.Equal(t, "$25.00 today (level overtime)", TelegramAlert(state, config).Message)
+}
+
+func TestTelegramAlerts_Observe(t *testing.T) {
+	config := models.ConfigDefaults()
+	config.TelegramBotToken, config.TelegramChatID = testTelegramToken, "1"
+
+	var mu sync.Mutex
+	var sent []string
+	fail := false
+	alerts := NewTelegramAlerts()
+	alerts.send = func(sink NotificationSink, alert Alert) error {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.IsType(t, telegramSink{}, sink)
+		if fail {
+			return assert.AnError
+		}
+		sent = append(sent, alert.Title)
+		return nil
+	}
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(sent)
+	}
+
+	now := time.Date(2025, 3, 14, 10, 0, 0, 0, time.Local)
+	state := &models.UsageState{Status: models.Green, IsAvailable: true, LastUpdate: now}
+	alerts.Observe(state, config, now)
+
+	state.Status = models.Yellow
+	alerts.Observe(state, config, now)
+	alerts.Observe(state, config, now)
+	assert.Eventually(t, func() bool { return count() == 1 }, time.Second, 10*time.Millisecond)
+
+	state.Status = models.Red
+	alerts.Observe(state, config, now)
+	assert.Eventually(t, func() bool { return count() == 2 }, time.Second, 10*time.Millisecond)
+
+	state.Paused = true
+	alerts.Observe(state, config, now.AddDate(0, 0, 1))
+	state.Paused = false
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 2, count(), "nothing is sent during quiet hours")
+
+	mu.Lock()
+	fail = true
+	mu.Unlock()
+	tomorrow := now.AddDate(0, 0, 1)
+	alerts.Observe(state, config, tomorrow)
+	assert.Eventually(t, func() bool {
+		alerts.mutex.Lock()
+		defer alerts.mutex.Unlock()
+		return alerts.sent[models.Red] == "" && alerts.sent[models.Yellow] == ""
+	}, time.Second, 10*time.Millisecond, "a failed alert is forgotten, with the yellow one it covered")
+
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+	alerts.Observe(state, config, tomorrow)
+	assert.Eventually(t, func() bool { return count() == 3 }, time.Second, 10*time.Millisecond, "and retried")
+
+	// A failed red after a delivered yellow leaves the yellow marked sent.
+	mu.Lock()
+	fail = true
+	mu.Unlock()
+	later := tomorrow.AddDate(0, 0, 1)
+	state.Status = models.Yellow
+	alerts.Observe(state, config, later)
+	assert.Eventually(t, func() bool { return count() == 3 }, time.Second, 10*time.Millisecond)
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+	alerts.Observe(state, config, later)
+	assert.Eventually(t, func() bool { return count() == 4 }, time.Second, 10*time.Millisecond)
+	mu.Lock()
+	fail = true
+	mu.Unlock()
+	state.Status = models.Red
+	alerts.Observe(state, config, later)
+	assert.Eventually(t, func() bool {
+		alerts.mutex.Lock()
+		defer alerts.mutex.Unlock()
+		return alerts.sent[models.Red] == ""
+	}, time.Second, 10*time.Millisecond)
+	alerts.mutex.Lock()
+	assert.Equal(t, later.Format("2006-01-02"), alerts.sent[models.Yellow])
+	alerts.mutex.Unlock()
+}
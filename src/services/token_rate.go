@@ -0,0 +1,54 @@
+package services
+
+import "time"
+
+// tokenRateSamples is how many polls the token rate is averaged over.
+const tokenRateSamples = 5
+
+// tokenRateWindow is how far back samples count toward the token rate, so
+// a rate never spans a long gap such as a sleeping laptop.
+const tokenRateWindow = 15 * time.Minute
+
+type usageSample struct {
+	at     time.Time
+	tokens int
+	cost   float64
+}
+
+// recordUsageSampleLocked notes today's totals after a successful poll,
+// keeping the last tokenRateSamples. Totals going down mean a new day has
+// started, so earlier samples are dropped.
+func (us *UsageService) recordUsageSampleLocked(now time.Time, tokens int, cost float64) {
+	if n := len(us.usageSamples); n > 0 && tokens < us.usageSamples[n-1].tokens {
+		us.usageSamples = us.usageSamples[:0]
+	}
+	us.usageSamples = append(us.usageSamples, usageSample{at: now, tokens: tokens, cost: cost})
+	if extra := len(us.usageSamples) - tokenRateSamples; extra > 0 {
+		us.usageSamples = append(us.usageSamples[:0], us.usageSamples[extra:]...)
+	}
+}
+
+// TokenRate returns tokens per minute over the last few polls while a
+// session is active, meaning cost rose at the latest poll. ok is false
+// otherwise, or before there are two recent polls to compare.
+func (us *UsageService) TokenRate() (perMinute float64, ok bool) {
+	us.mutex.RLock()
+	defer us.mutex.RUnlock()
+
+	cutoff := us.clock.Now().Add(-tokenRateWindow)
+	var recent []usageSample
+	for _, sample := range us.usageSamples {
+		if !sample.at.Before(cutoff) {
+			recent = append(recent, sample)
+		}
+	}
+	if len(recent) < 2 {
+		return 0, false
+	}
+	first, prev, last := recent[0], recent[len(recent)-2], recent[len(recent)-1]
+	minutes := last.at.Sub(first.at).Minutes()
+	if last.cost <= prev.cost || minutes <= 0 {
+		return 0, false
+	}
+	return float64(last.tokens-first.tokens) / minutes, true
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageService_TokenRate(t *testing.T) {
+	service := newTestUsageService()
+	start := time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)
+	clock := &fixedClock{now: start}
+	service.SetClock(clock)
+
+	_, ok := service.TokenRate()
+	assert.False(t, ok, "no polls yet")
+
+	service.ccusagePath = writeCCUsageScript(t, `echo '{"daily":[{"date":"2025-03-14","totalTokens":1000,"totalCost":1}]}'`)
+	_, _ = service.Refresh()
+	_, ok = service.TokenRate()
+	assert.False(t, ok, "one poll has nothing to compare")
+
+	// Cost rising over the last polls: 9,000 tokens in three minutes.
+	for i, tokens := range []int{4000, 7000, 10000} {
+		clock.now = start.Add(time.Duration(i+1) * time.Minute)
+		service.mutex.Lock()
+		service.recordUsageSampleLocked(clock.now, tokens, float64(tokens)/1000)
+		service.mutex.Unlock()
+	}
+	rate, ok := service.TokenRate()
+	assert.True(t, ok)
+	assert.InDelta(t, 3000, rate, 0.01)
+
+	// Idle: the latest poll's cost didn't move.
+	clock.now = start.Add(4 * time.Minute)
+	service.mutex.Lock()
+	service.recordUsageSampleLocked(clock.now, 10000, 10)
+	service.mutex.Unlock()
+	_, ok = service.TokenRate()
+	assert.False(t, ok)
+
+	// Samples beyond the window or before midnight don't count.
+	clock.now = start.Add(4*time.Minute + tokenRateWindow + time.Second)
+	service.mutex.Lock()
+	service.recordUsageSampleLocked(clock.now, 12000, 12)
+	service.mutex.Unlock()
+	_, ok = service.TokenRate()
+	assert.False(t, ok, "only one sample in the window")
+
+	service.mutex.Lock()
+	service.recordUsageSampleLocked(clock.now.Add(time.Minute), 500, 0.5)
+	assert.Len(t, service.usageSamples, 1, "a new day starts over")
+	for i := 0; i < tokenRateSamples+2; i++ {
+		service.recordUsageSampleLocked(clock.now.Add(time.Duration(i+2)*time.Minute), 600+i, 0.6)
+	}
+	assert.Len(t, service.usageSamples, tokenRateSamples)
+	service.mutex.Unlock()
+}
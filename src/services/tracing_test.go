@@ -0,0 +1,74 @@
+package services
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+func TestUsageService_PollTrace(t *testing.T) {
+	var mutex sync.Mutex
+	var names []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []struct {
+						Name string `json:"name"`
+					} `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		data, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(data, &body))
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, s := range body.ResourceSpans[0].ScopeSpans[0].Spans {
+			names = append(names, s.Name)
+		}
+	}))
+	defer server.Close()
+
+	service := newTestUsageService()
+	service.SetClock(fixedClock{now: time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)})
+	service.ccusagePath = writeCCUsageScript(t, `echo '{"daily":[{"date":"2025-03-14","totalTokens":1,"totalCost":1}]}'`)
+	service.tracer = lib.NewTracer(server.URL, "cc-dailyuse-bar")
+	service.updateCallback = func(*models.UsageState) {}
+
+	_, err := service.Refresh()
+	require.NoError(t, err)
+	service.ui.flush() // the trace ends once the callback has run
+	service.tracer.Shutdown()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, []string{"ccusage.exec", "ccusage.parse", "state.update", "ccusage.session", "ui.render", "poll"}, names)
+}
+
+func TestUsageService_ApplyConfigTracer(t *testing.T) {
+	service := newTestUsageService()
+	assert.Nil(t, service.tracer, "tracing is off by default")
+
+	config := models.ConfigDefaults()
+	config.OTLPEndpoint = "http://localhost:4318"
+	service.ApplyConfig(config)
+	tracer := service.tracer
+	assert.NotNil(t, tracer)
+
+	service.ApplyConfig(config)
+	assert.Same(t, tracer, service.tracer, "unchanged endpoint keeps the tracer")
+
+	config.OTLPEndpoint = ""
+	service.ApplyConfig(config)
+	assert.Nil(t, service.tracer)
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"cc-dailyuse-bar/src/lib"
+	"cc-dailyuse-bar/src/models"
+)
+
+// uiQueueSize bounds the updates waiting for the update callback. Only the
+// newest state matters to a display, so when the UI falls this far behind
+// the oldest waiting update is dropped.
+const uiQueueSize = 4
+
+// uiUpdate is one state waiting to be handed to the update callback.
+type uiUpdate struct {
+	callback func(*models.UsageState)
+	state    *models.UsageState
+	span     *lib.Span // the poll's trace, ended once the update is rendered or dropped; may be nil
+}
+
+// uiDispatcher runs update callbacks one at a time, in order, on a worker
+// goroutine, so a slow tray call can't hold up polling. The worker starts
+// when an update arrives and exits once the queue is empty, so there is
+// nothing to shut down.
+type uiDispatcher struct {
+	run     func(uiUpdate)
+	size    int
+	dropped atomic.Uint64
+
+	mutex   sync.Mutex
+	queue   []uiUpdate // oldest first
+	running bool       // a worker is draining queue
+	idle    *sync.Cond // broadcast when the worker exits
+}
+
+func newUIDispatcher(run func(uiUpdate)) *uiDispatcher {
+	d := &uiDispatcher{run: run, size: uiQueueSize}
+	d.idle = sync.NewCond(&d.mutex)
+	return d
+}
+
+// dispatch queues u, dropping the oldest waiting update when the queue is
+// full, and returns without waiting for the callback.
+func (d *uiDispatcher) dispatch(u uiUpdate) {
+	d.mutex.Lock()
+	var dropped *uiUpdate
+	if len(d.queue) == d.size {
+		dropped = &d.queue[0]
+		d.queue = d.queue[1:]
+		d.dropped.Add(1)
+	}
+	d.queue = append(d.queue, u)
+	start := !d.running
+	d.running = true
+	d.mutex.Unlock()
+
+	if dropped != nil {
+		dropped.span.SetAttribute("ui.dropped", true)
+		dropped.span.End()
+	}
+	if start {
+		go d.work()
+	}
+}
+
+func (d *uiDispatcher) work() {
+	for {
+		d.mutex.Lock()
+		if len(d.queue) == 0 {
+			d.running = false
+			d.idle.Broadcast()
+			d.mutex.Unlock()
+			return
+		}
+		u := d.queue[0]
+		d.queue = d.queue[1:]
+		d.mutex.Unlock()
+
+		d.run(u)
+	}
+}
+
+// flush waits until every queued update has been handled. It must not be
+// called from a callback, which would wait for itself.
+func (d *uiDispatcher) flush() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for d.running {
+		d.idle.Wait()
+	}
+}
+
+// dispatchUpdate hands state to callback, if any, on the UI worker. It
+// takes ownership of span, ending it once the callback has run.
+func (us *UsageService) dispatchUpdate(callback func(*models.UsageState), state *models.UsageState, span *lib.Span) {
+	if callback == nil {
+		span.End()
+		return
+	}
+	us.ui.dispatch(uiUpdate{callback: callback, state: state, span: span})
+}
+
+// renderUpdate runs on the UI worker.
+func (us *UsageService) renderUpdate(u uiUpdate) {
+	renderSpan := u.span.Child("ui.render")
+	us.runCallback(u.callback, u.state)
+	renderSpan.End()
+	u.span.End()
+}
+
+// DroppedUIUpdates reports how many updates were discarded because the
+// update callback fell behind.
+func (us *UsageService) DroppedUIUpdates() uint64 {
+	return us.ui.dropped.Load()
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+func TestUIDispatcher_DropsOldestWhenFull(t *testing.T) {
+	release := make(chan struct{})
+	var mutex sync.Mutex
+	var rendered []float64
+	d := newUIDispatcher(func(u uiUpdate) { u.callback(u.state) })
+	callback := func(state *models.UsageState) {
+		if state.DailyCost == 0 {
+			<-release // a slow tray call
+		}
+		mutex.Lock()
+		rendered = append(rendered, state.DailyCost)
+		mutex.Unlock()
+	}
+
+	for cost := 0; cost <= uiQueueSize+2; cost++ {
+		d.dispatch(uiUpdate{callback: callback, state: &models.UsageState{DailyCost: float64(cost)}})
+		if cost == 0 {
+			// Let the worker pick up the first update before queueing more.
+			require.Eventually(t, func() bool {
+				d.mutex.Lock()
+				defer d.mutex.Unlock()
+				return len(d.queue) == 0
+			}, time.Second, time.Millisecond)
+		}
+	}
+	close(release)
+	d.flush()
+
+	assert.Equal(t, []float64{0, 3, 4, 5, 6}, rendered, "updates 1 and 2 were dropped")
+	assert.Equal(t, uint64(2), d.dropped.Load())
+}
+
+func TestUsageService_PollDoesNotWaitForCallback(t *testing.T) {
+	service := newTestUsageService()
+	service.SetClock(fixedClock{now: time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)})
+	service.ccusagePath = writeCCUsageScript(t, `echo '{"daily":[{"date":"2025-03-14","totalTokens":1,"totalCost":1.5}]}'`)
+	release := make(chan struct{})
+	var rendered []*models.UsageState
+	service.updateCallback = func(state *models.UsageState) {
+		<-release
+		rendered = append(rendered, state)
+	}
+
+	state, err := service.Refresh()
+	require.NoError(t, err, "returns while the callback is still blocked")
+	assert.Equal(t, 1.5, state.DailyCost)
+
+	close(release)
+	service.ui.flush()
+	require.Len(t, rendered, 1)
+	assert.Equal(t, 1.5, rendered[0].DailyCost)
+	assert.Zero(t, service.DroppedUIUpdates())
+}
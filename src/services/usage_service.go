@@ -2,59 +2,160 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
+	"runtime"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"cc-dailyuse-bar/src/lib"
 	"cc-dailyuse-bar/src/models"
 )
 
-const maxLoggedOutputLength = 128
-
 var errCCUsageUnavailable = errors.New("ccusage is not available")
 
+// ccusageDateFormat is the YYYYMMDD layout ccusage expects for --since/--until.
+const ccusageDateFormat = "20060102"
+
+// pollRetries is how many attempts a timer-driven poll makes.
+const pollRetries = 3
+
+// Clock abstracts the current time so date-dependent logic can be tested.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
 // UsageService implements Claude Code usage tracking via ccusage integration
 type UsageService struct {
-	lastQuery       time.Time
-	state           *models.UsageState
-	logger          *lib.Logger
-	ticker          *time.Ticker
-	pollStopChan    chan struct{}
-	resetStopChan   chan struct{}
-	updateCallback  func(*models.UsageState)
-	ccusagePath     string
-	cacheWindow     time.Duration
-	mutex           sync.RWMutex // Protect shared state access
-	cmdTimeout      time.Duration
-	yellowThreshold float64
-	redThreshold    float64
+	lastQuery        time.Time
+	state            *models.UsageState
+	logger           *lib.Logger
+	pollingStatus    PollingStatus
+	lifecycle        sync.Mutex               // serialises StartPolling, StopPolling, and StartDailyResetMonitor
+	loops            sync.WaitGroup           // polling and daily reset goroutines, joined by StopPolling
+	poll             atomic.Pointer[pollLoop] // the running polling loop; nil when stopped
+	resetCancel      context.CancelFunc       // ends the daily reset loop; nil when stopped
+	pollInterval     atomic.Int64             // nanoseconds; set by StartPolling and resetTickerLocked
+	stallAfter       atomic.Int64             // stallThresholdLocked in nanoseconds, for the watchdog
+	lastActivity     atomic.Int64             // UnixNano when the polling loop last finished handling an event
+	watchdogCancel   context.CancelFunc       // ends the stall watchdog; nil when stopped
+	watchdog         sync.WaitGroup           // the stall watchdog, joined by StopPolling
+	stallRecoveries  atomic.Int64
+	lastRecovery     atomic.Int64  // UnixNano of the last stall recovery
+	pollOutcomes     []pollOutcome // the last pollReliabilityWindow of polls, oldest first
+	usageSamples     []usageSample // today's totals at the last few successful polls, oldest first
+	updateCallback   func(*models.UsageState)
+	ui               *uiDispatcher // runs updateCallback off the polling goroutine
+	ccusagePath      string
+	cacheWindow      time.Duration
+	mutex            sync.RWMutex // Protect shared state access
+	cmdTimeout       time.Duration
+	yellowThreshold  float64
+	redThreshold     float64
+	alertLevels      []models.AlertLevel
+	dayThresholds    map[string]models.ThresholdPair
+	modelThresholds  map[string]float64
+	sessionCap       float64
+	quietUntil       string
+	quietHours       models.QuietHours
+	resumeTimer      *time.Timer // ends a quiet_hours pause; nil when not paused
+	clock            Clock
+	runner           CommandRunner       // runs ccusage
+	sleep            func(time.Duration) // waits between retries
+	diskCache        *ccusageCache       // nil disables the JSONL-fingerprint cache
+	history          *HistoryService
+	demo             *DemoFeed            // replaces ccusage with synthetic data when set
+	topSession       *models.SessionCost  // from the last successful session report
+	sessionCosts     []models.SessionCost // every session active today, with its total cost, from the same report
+	tagCosts         []models.TagCost     // today per project tag, from the last per-project report
+	projectTags      []models.ProjectTag
+	sessionDay       string // ccusageDateFormat day topSession belongs to
+	lastSessionQuery time.Time
+	reportDays       map[string]dayTotal // every day in the last daily report, for pricing change checks
+	otlpEndpoint     string
+	tracer           *lib.Tracer // nil unless otlp_endpoint is set
+	span             *lib.Span   // the poll in progress; nil outside pollOnce or when tracing is off
+	watchDataDirs    bool
+	claudeDataDir    string        // passed to ccusage as CLAUDE_CONFIG_DIR when set
+	sources          []UsageSource // Claude Code's directories plus other clients' found at startup or reload
+	logOutputLength  int           // log_output_length; 0 means defaultLoggedOutputLength
+	dumpFile         string        // ccusage_dump_file; empty disables dumping
+	recordDir        string        // record_dir; empty disables record mode
+	lastRecorded     []byte        // the response last saved to recordDir
+	dataDirs         []string
+	requests         requestCounter // counted outside mutex, see countRequests
+	watcher          *dataWatcher
 }
 
 // NewUsageService creates a new UsageService instance
 func NewUsageService(config *models.Config) *UsageService {
-	return &UsageService{
+	sources := usageSources(config.ClaudeDataDir)
+	us := &UsageService{
 		ccusagePath:     config.CCUsagePath,
 		state:           models.NewUsageState(),
 		cacheWindow:     time.Duration(config.CacheWindow) * time.Second,
 		logger:          lib.NewLogger("usage-service"),
-		pollStopChan:    make(chan struct{}),
-		resetStopChan:   make(chan struct{}),
 		cmdTimeout:      time.Duration(config.CmdTimeout) * time.Second,
 		yellowThreshold: config.YellowThreshold,
 		redThreshold:    config.RedThreshold,
+		alertLevels:     config.AlertLevels,
+		dayThresholds:   config.DayThresholds,
+		modelThresholds: config.ModelThresholds,
+		sessionCap:      config.SessionCap,
+		projectTags:     config.ProjectTags,
+		quietUntil:      config.QuietUntil,
+		quietHours:      config.QuietHoursWindow(),
+		clock:           systemClock{},
+		runner:          ExecRunner{},
+		sleep:           time.Sleep,
+		diskCache:       newCCUsageCache(sourceDataDirs(sources)),
+		watchDataDirs:   config.WatchDataDirs,
+		claudeDataDir:   config.ClaudeDataDir,
+		sources:         sources,
+		logOutputLength: config.LogOutputLength,
+		dumpFile:        config.CCUsageDumpFile,
+		recordDir:       config.RecordDir,
+		dataDirs:        sourceDataDirs(sources),
+		otlpEndpoint:    config.OTLPEndpoint,
+		tracer:          newTracer(config.OTLPEndpoint),
+	}
+	us.ui = newUIDispatcher(us.renderUpdate)
+	return us
+}
+
+// newTracer returns a tracer for the OTLP endpoint, or nil when tracing is
+// off.
+func newTracer(endpoint string) *lib.Tracer {
+	if endpoint == "" {
+		return nil
 	}
+	return lib.NewTracer(endpoint, "cc-dailyuse-bar")
 }
 
 // CCUsageOutput represents the JSON structure returned by ccusage
 type CCUsageOutput struct {
-	Date        string  `json:"date"`
-	TotalTokens int     `json:"totalTokens"`
-	TotalCost   float64 `json:"totalCost"`
+	Date            string                `json:"date"`
+	TotalTokens     int                   `json:"totalTokens"`
+	TotalCost       float64               `json:"totalCost"`
+	ModelsUsed      []string              `json:"modelsUsed,omitempty"`
+	ModelBreakdowns []CCUsageModelSummary `json:"modelBreakdowns,omitempty"`
+}
+
+// CCUsageModelSummary is one model's share of a day in ccusage's output.
+type CCUsageModelSummary struct {
+	ModelName           string  `json:"modelName"`
+	InputTokens         int     `json:"inputTokens"`
+	OutputTokens        int     `json:"outputTokens"`
+	CacheCreationTokens int     `json:"cacheCreationTokens"`
+	CacheReadTokens     int     `json:"cacheReadTokens"`
+	Cost                float64 `json:"cost"`
 }
 
 // CCUsageResponse represents the full JSON response from ccusage
@@ -80,6 +181,7 @@ func (us *UsageService) GetDailyUsage() (*models.UsageState, error) {
 	}
 	us.mutex.RUnlock()
 
+	requests := us.countRequests()
 	us.mutex.Lock()
 	defer us.mutex.Unlock()
 
@@ -87,16 +189,35 @@ func (us *UsageService) GetDailyUsage() (*models.UsageState, error) {
 		return us.getStateCopyLocked(), nil
 	}
 
-	return us.performUpdateLocked(1)
+	return us.performUpdateLocked(1, requests)
 }
 
 // UpdateUsage forces a fresh query to ccusage, bypassing cache
 // Used for immediate updates when user requests refresh
 // Returns error if ccusage command fails or data is invalid
 func (us *UsageService) UpdateUsage() (*models.UsageState, error) {
+	requests := us.countRequests()
 	us.mutex.Lock()
 	defer us.mutex.Unlock()
-	return us.performUpdateLocked(1)
+	return us.performUpdateLocked(1, requests)
+}
+
+// requestTally is today's request count and when a usage log was last
+// written, see requestCounter.
+type requestTally struct {
+	calls     int
+	lastWrite time.Time
+}
+
+// countRequests counts today's requests in Claude Code's logs. It reads
+// files, so callers run it before taking the mutex and pass the result to
+// performUpdateLocked.
+func (us *UsageService) countRequests() requestTally {
+	us.mutex.RLock()
+	dataDirs, now := us.dataDirs, us.clock.Now()
+	us.mutex.RUnlock()
+	calls, lastWrite := us.requests.Count(dataDirs, now)
+	return requestTally{calls: calls, lastWrite: lastWrite}
 }
 
 func (us *UsageService) getStateCopyLocked() *models.UsageState {
@@ -113,6 +234,17 @@ func (us *UsageService) setUnknownState() {
 
 func (us *UsageService) setUnknownStateLocked() {
 	us.setStateMetricsLocked(0, 0, false)
+	us.state.WeeklyCost = 0
+	us.state.WeeklyCount = 0
+	us.state.MonthlyCost = 0
+	us.state.Forecast = 0
+	us.state.Models = nil
+	us.state.ModelAlerts = nil
+	us.state.Yesterday = nil
+	us.state.LastWeek = nil
+	us.state.TopSession = nil
+	us.state.SessionAlerts = nil
+	us.state.TagCosts = nil
 	us.state.Status = models.Unknown
 }
 
@@ -125,12 +257,17 @@ func (us *UsageService) setNoDataForToday() {
 
 func (us *UsageService) setNoDataForTodayLocked() {
 	us.setStateMetricsLocked(0, 0, true)
+	us.state.Models = nil
+	us.state.TopSession = nil
+	us.state.SessionAlerts = nil
+	us.state.TagCosts = nil
 	us.updateStatusLocked() // $0.00 cost should evaluate to Green
 }
 
 func (us *UsageService) setStateMetricsLocked(tokens int, cost float64, available bool) {
-	now := time.Now()
+	now := us.clock.Now()
 	us.state.DailyCount = tokens
+	us.state.DailyCalls = 0
 	us.state.DailyCost = cost
 	us.state.LastUpdate = now
 	us.state.IsAvailable = available
@@ -144,6 +281,7 @@ func (us *UsageService) ResetDaily() error {
 	us.mutex.Lock()
 	defer us.mutex.Unlock()
 	us.state.Reset()
+	us.updateStatusLocked()    // quiet_until may have ended with the old day
 	us.lastQuery = time.Time{} // Clear cache
 	return nil
 }
@@ -152,50 +290,220 @@ func (us *UsageService) ResetDaily() error {
 // Performs quick validation without full query
 // Returns false if binary not found or not executable
 func (us *UsageService) IsAvailable() bool {
-	if us.ccusagePath == "" {
-		return false
+	_, err := us.resolveCCUsage()
+	return err == nil
+}
+
+// resolveCCUsage locates the executable that ccusage_path runs — the
+// runner (bunx, npx) for multi-word commands — and checks it is an
+// executable file.
+func (us *UsageService) resolveCCUsage() (string, error) {
+	return us.resolveCCUsageAt(us.ccusagePath)
+}
+
+// resolveCCUsageAt is resolveCCUsage for a ccusage_path other than the
+// current one.
+func (us *UsageService) resolveCCUsageAt(ccusagePath string) (string, error) {
+	bin, _, err := SplitCCUsageCommand(ccusagePath)
+	if err != nil {
+		return "", err
 	}
 
-	// Resolve via exec.LookPath first so the availability check follows the
-	// same rules as exec.CommandContext (PATH-only for bare names, never the
+	// Resolve through the runner first so the availability check follows
+	// the same rules as running it (PATH-only for bare names, never the
 	// cwd). Otherwise IsAvailable could return true for a file in the working
 	// directory that exec would later fail to find.
-	resolvedPath, err := exec.LookPath(us.ccusagePath)
+	resolver, ok := us.runner.(pathResolver)
+	if !ok {
+		return bin, nil
+	}
+	resolvedPath, err := resolver.LookPath(bin)
 	if err != nil {
-		return false
+		return "", err
 	}
 
 	info, err := os.Stat(resolvedPath)
 	if err != nil {
-		return false
+		return "", err
 	}
-
-	if info.IsDir() {
-		return false
+	// Windows has no execute bits; LookPath has already matched the name
+	// against PATHEXT (.exe, .cmd, ...), which is what makes it runnable.
+	if info.IsDir() || (runtime.GOOS != "windows" && info.Mode()&0o111 == 0) {
+		return "", fmt.Errorf("%s is not an executable file", resolvedPath)
 	}
-
-	return info.Mode()&0o111 != 0
+	return resolvedPath, nil
 }
 
 // SetCCUsagePath updates the path to ccusage binary
 // Validates that the new path is executable
 // Returns error if path is invalid or not executable
 func (us *UsageService) SetCCUsagePath(path string) error {
+	if err := us.CheckCCUsagePath(path); err != nil {
+		return err
+	}
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+	us.ccusagePath = path
+	return nil
+}
+
+// CheckCCUsagePath reports whether path, a ccusage_path value, resolves to
+// an executable the way running it would, without switching to it.
+func (us *UsageService) CheckCCUsagePath(path string) error {
 	if path == "" {
 		return lib.ValidationError("ccusage path cannot be empty")
 	}
+	if _, err := us.resolveCCUsageAt(path); err != nil {
+		return lib.ValidationError("ccusage path is not executable: " + path)
+	}
+	return nil
+}
 
-	oldPath := us.ccusagePath
-	us.ccusagePath = path
+// SetAlertLevels replaces the custom alert level ladder and recalculates
+// status. An empty slice reverts to the yellow/red threshold pair.
+func (us *UsageService) SetAlertLevels(levels []models.AlertLevel) {
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+	us.alertLevels = levels
+	us.updateStatusLocked()
+}
 
-	if !us.IsAvailable() {
-		us.ccusagePath = oldPath
-		return lib.ValidationError("ccusage path is not executable: " + path)
+// ApplyConfig swaps in a reloaded configuration in one step: thresholds,
+// alert levels, ccusage settings, and the polling interval all change under
+// a single lock, so no update ever sees a mix of old and new values. The
+// config must already be validated. Data-dir watching is fixed at
+// StartPolling and is not affected.
+func (us *UsageService) ApplyConfig(config *models.Config) {
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+
+	us.ccusagePath = config.CCUsagePath
+	us.cacheWindow = time.Duration(config.CacheWindow) * time.Second
+	us.cmdTimeout = time.Duration(config.CmdTimeout) * time.Second
+	us.yellowThreshold = config.YellowThreshold
+	us.redThreshold = config.RedThreshold
+	us.alertLevels = config.AlertLevels
+	us.dayThresholds = config.DayThresholds
+	us.modelThresholds = config.ModelThresholds
+	us.sessionCap = config.SessionCap
+	if !slices.Equal(us.projectTags, config.ProjectTags) {
+		us.projectTags = config.ProjectTags
+		us.lastSessionQuery = time.Time{} // re-split today with the new rules
+	}
+	us.quietUntil = config.QuietUntil
+	us.quietHours = config.QuietHoursWindow()
+	us.logOutputLength = config.LogOutputLength
+	us.dumpFile = config.CCUsageDumpFile
+	us.recordDir = config.RecordDir
+	if us.otlpEndpoint != config.OTLPEndpoint {
+		us.otlpEndpoint = config.OTLPEndpoint
+		us.tracer = newTracer(config.OTLPEndpoint)
+	}
+	if us.claudeDataDir != config.ClaudeDataDir {
+		// A running data watcher keeps its directories until restart.
+		us.claudeDataDir = config.ClaudeDataDir
+		us.sources = usageSources(config.ClaudeDataDir)
+		us.dataDirs = sourceDataDirs(us.sources)
+		if us.diskCache != nil {
+			us.diskCache = newCCUsageCache(us.dataDirs)
+		}
 	}
+	if us.resumeTimer != nil && !us.quietHours.Active(us.clock.Now()) {
+		// The new window no longer covers now; let the next tick poll.
+		us.resumeTimer.Stop()
+		us.resumeTimer = nil
+		us.state.Paused = false
+		_ = us.setPollingStatusLocked(PollingRunning)
+	}
+	us.resetTickerLocked(time.Duration(config.UpdateInterval) * time.Second)
+	// Drop the in-memory cache so the next read reflects the new settings.
+	us.lastQuery = time.Time{}
+	us.updateStatusLocked()
+}
 
+// SetUpdateInterval changes the polling interval, restarting the ticker
+// if polling is running so the new interval takes effect immediately.
+func (us *UsageService) SetUpdateInterval(intervalSeconds int) error {
+	if intervalSeconds <= 0 {
+		return lib.ValidationError("polling interval must be positive")
+	}
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+	us.resetTickerLocked(time.Duration(intervalSeconds) * time.Second)
+	return nil
+}
+
+// resetTickerLocked switches running polling to interval, recording it
+// for the watchdog and activity detection. It does nothing while polling
+// is stopped; StartPolling takes its own interval.
+func (us *UsageService) resetTickerLocked(interval time.Duration) {
+	loop := us.poll.Load()
+	if loop == nil {
+		return
+	}
+	loop.ticker.Reset(interval)
+	us.setPollIntervalLocked(interval)
+}
+
+// setPollIntervalLocked records interval, and the stall threshold it and
+// cmdTimeout give, where the watchdog can read them without us.mutex.
+func (us *UsageService) setPollIntervalLocked(interval time.Duration) {
+	us.pollInterval.Store(int64(interval))
+	us.stallAfter.Store(int64(us.stallThresholdLocked()))
+}
+
+// SetQuietUntil starts (or, with "", ends) quiet mode through the given
+// YYYY-MM-DD date and recalculates status.
+func (us *UsageService) SetQuietUntil(date string) error {
+	if _, err := models.ParseQuietUntil(date); err != nil {
+		return err
+	}
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+	us.quietUntil = date
+	us.updateStatusLocked()
 	return nil
 }
 
+// SetDemoFeed switches the service to synthetic data from feed instead of
+// running ccusage; nil returns to live data.
+func (us *UsageService) SetDemoFeed(feed *DemoFeed) {
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+	us.demo = feed
+	us.state.Demo = feed != nil
+	us.lastQuery = time.Time{}
+}
+
+// SetHistory records a sample after each successful poll; nil disables
+// recording.
+func (us *UsageService) SetHistory(history *HistoryService) {
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+	us.history = history
+}
+
+// SetCommandRunner overrides how ccusage is run, primarily for tests; nil
+// restores ExecRunner.
+func (us *UsageService) SetCommandRunner(runner CommandRunner) {
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+	if runner == nil {
+		runner = ExecRunner{}
+	}
+	us.runner = runner
+}
+
+// SetClock overrides the time source, primarily for tests.
+func (us *UsageService) SetClock(clock Clock) {
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+	if clock == nil {
+		clock = systemClock{}
+	}
+	us.clock = clock
+}
+
 // SetThresholds updates the alert thresholds and recalculates status
 func (us *UsageService) SetThresholds(yellowThreshold, redThreshold float64) {
 	us.mutex.Lock()
@@ -207,18 +515,26 @@ func (us *UsageService) SetThresholds(yellowThreshold, redThreshold float64) {
 
 // T025: Connect to ccusage binary with retry logic
 func (us *UsageService) updateWithRetry(maxRetries int) (*models.UsageState, error) {
+	requests := us.countRequests()
 	us.mutex.Lock()
 	defer us.mutex.Unlock()
-	return us.performUpdateLocked(maxRetries)
+	return us.performUpdateLocked(maxRetries, requests)
 }
 
 // performUpdateLocked assumes us.mutex is already held by the caller.
 // It returns a copy of the current state after attempting to refresh usage data.
-func (us *UsageService) performUpdateLocked(maxRetries int) (*models.UsageState, error) {
+// requests is from countRequests.
+func (us *UsageService) performUpdateLocked(maxRetries int, requests requestTally) (*models.UsageState, error) {
 	if maxRetries < 1 {
 		maxRetries = 1
 	}
 
+	if us.demo != nil {
+		_, err := us.applyCCUsageOutputLocked(us.demo.Next(us.clock.Now()))
+		us.state.Demo = true
+		return us.getStateCopyLocked(), err
+	}
+
 	var lastErr error
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
@@ -249,7 +565,12 @@ func (us *UsageService) performUpdateLocked(maxRetries int) (*models.UsageState,
 			return us.getStateCopyLocked(), lastErr
 		}
 
+		execSpan := us.span.Child("ccusage.exec")
+		execSpan.SetAttribute("attempt", attempt)
 		output, err := us.executeCCUsage()
+		execSpan.SetAttribute("output_bytes", len(output))
+		execSpan.SetError(err)
+		execSpan.End()
 		if err != nil {
 			wrapped := lib.WrapError(err, lib.ErrCodeCCUsage, "ccusage command failed")
 			if wrapped != nil {
@@ -277,39 +598,15 @@ func (us *UsageService) performUpdateLocked(maxRetries int) (*models.UsageState,
 			return us.getStateCopyLocked(), lastErr
 		}
 
-		response, err := parseCCUsageResponse(output)
+		us.recordResponseLocked(output)
+		previousCost, wasAvailable := us.state.DailyCost, us.state.IsAvailable
+		ccusageOutput, err := us.applyCCUsageOutputLocked(output)
 		if err != nil {
-			us.logger.Warn("ccusage JSON parsing failed, marking as unknown", map[string]interface{}{
-				"error":   err.Error(),
-				"out_len": len(output),
-				"output":  truncateOutput(output),
-			})
-			us.setUnknownStateLocked()
-			return us.getStateCopyLocked(), lib.WrapError(err, lib.ErrCodeCCUsage, "failed to parse ccusage JSON output")
+			return us.getStateCopyLocked(), err
 		}
-
-		today := time.Now().Format("2006-01-02")
-		ccusageOutput, found := findTodayOutput(response, today)
-		if !found {
-			us.logger.Info("No data found for today, setting to $0.00", map[string]interface{}{
-				"today":          today,
-				"availableDates": availableDates(response.Daily),
-			})
-			us.setNoDataForTodayLocked()
-			return us.getStateCopyLocked(), lib.WrapError(errors.New("no data for today"), lib.ErrCodeCCUsage, "ccusage has no data for today")
-		}
-
-		if ccusageOutput.TotalCost == 0 && ccusageOutput.TotalTokens == 0 {
-			us.logger.Warn("ccusage returned zero values, marking as unknown", map[string]interface{}{
-				"totalTokens": ccusageOutput.TotalTokens,
-				"totalCost":   ccusageOutput.TotalCost,
-				"date":        ccusageOutput.Date,
-			})
-			us.setUnknownStateLocked()
-			return us.getStateCopyLocked(), lib.WrapError(errors.New("ccusage returned zero values"), lib.ErrCodeCCUsage, "ccusage returned invalid zero values")
-		}
-
-		us.applyUsageDataLocked(ccusageOutput)
+		us.state.DailyCalls = requests.calls
+		us.state.Active = us.activeLocked(wasAvailable && us.state.DailyCost > previousCost, requests.lastWrite)
+		us.refreshTopSessionLocked(us.clock.Now())
 
 		context := map[string]interface{}{
 			"totalTokens": ccusageOutput.TotalTokens,
@@ -331,12 +628,123 @@ func (us *UsageService) performUpdateLocked(maxRetries int) (*models.UsageState,
 	return us.getStateCopyLocked(), lastErr
 }
 
+// applyCCUsageOutputLocked parses a ccusage daily report and updates the
+// tracked state from it. Errors (including "no data for today") leave the
+// state reflecting what was found.
+func (us *UsageService) applyCCUsageOutputLocked(output []byte) (CCUsageOutput, error) {
+	now := us.clock.Now()
+	weekStart, _ := currentWeekRange(now)
+	today := now.Format("2006-01-02")
+	yesterday, lastWeek := comparisonDates(now)
+	parseSpan := us.span.Child("ccusage.parse")
+	scan, err := scanDailyOutput(output, today, weekStart.Format("2006-01-02"), yesterday, lastWeek)
+	parseSpan.SetError(err)
+	parseSpan.End()
+	if err != nil {
+		us.logger.Warn("ccusage JSON parsing failed, marking as unknown", map[string]interface{}{
+			"error":   err.Error(),
+			"out_len": len(output),
+			"output":  us.truncateOutput(output),
+		})
+		us.setUnknownStateLocked()
+		return CCUsageOutput{}, lib.WrapError(err, lib.ErrCodeCCUsage, "failed to parse ccusage JSON output")
+	}
+
+	us.reportDays = scan.Days
+	// Week totals are meaningful even when today has no entry yet.
+	us.state.WeeklyCost = scan.WeekCost
+	us.state.WeeklyCount = scan.WeekTokens
+	us.state.MonthlyCost = costBetween(scan.Days, monthStart(now).Format("2006-01-02"), today)
+	lastSevenDays := costBetween(scan.Days, now.AddDate(0, 0, -6).Format("2006-01-02"), today)
+	us.state.Forecast = models.ForecastMonth(us.state.MonthlyCost, lastSevenDays, now)
+	us.state.Yesterday = costComparison(yesterday, scan.Compare[0])
+	us.state.LastWeek = costComparison(lastWeek, scan.Compare[1])
+
+	if !scan.Found {
+		us.logger.Info("No data found for today, setting to $0.00", map[string]interface{}{
+			"today":      today,
+			"entries":    scan.Entries,
+			"latestDate": scan.LatestDate,
+		})
+		us.setNoDataForTodayLocked()
+		return CCUsageOutput{}, lib.WrapError(errors.New("no data for today"), lib.ErrCodeCCUsage, "ccusage has no data for today")
+	}
+	ccusageOutput := scan.Today
+
+	if ccusageOutput.TotalCost == 0 && ccusageOutput.TotalTokens == 0 {
+		us.logger.Warn("ccusage returned zero values, marking as unknown", map[string]interface{}{
+			"totalTokens": ccusageOutput.TotalTokens,
+			"totalCost":   ccusageOutput.TotalCost,
+			"date":        ccusageOutput.Date,
+		})
+		us.setUnknownStateLocked()
+		return CCUsageOutput{}, lib.WrapError(errors.New("ccusage returned zero values"), lib.ErrCodeCCUsage, "ccusage returned invalid zero values")
+	}
+
+	stateSpan := us.span.Child("state.update")
+	us.applyUsageDataLocked(ccusageOutput)
+	stateSpan.End()
+	return ccusageOutput, nil
+}
+
 func (us *UsageService) executeCCUsage() ([]byte, error) {
+	args := ccusageDailyArgs(us.clock.Now())
+
+	// Skip spawning ccusage entirely when none of the JSONL files it reads
+	// have changed since the last successful run for the same date range.
+	var fp dataFingerprint
+	cacheable := false
+	if us.diskCache != nil {
+		fp, cacheable = us.diskCache.fingerprint()
+		if cacheable {
+			if output, ok := us.diskCache.load(us.ccusagePath, args, fp); ok {
+				us.logger.Debug("Reusing cached ccusage output; Claude data unchanged", map[string]interface{}{
+					"out_len": len(output),
+					"files":   fp.Files,
+				})
+				return output, nil
+			}
+		}
+	}
+
+	output, err := us.runCCUsage(args)
+	if err != nil {
+		return output, err
+	}
+
+	if cacheable {
+		if err := us.diskCache.store(us.ccusagePath, args, fp, output); err != nil {
+			us.logger.Debug("Failed to persist ccusage cache", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	return output, nil
+}
+
+// runCCUsage spawns ccusage with args under the configured timeout,
+// reading every usage source.
+func (us *UsageService) runCCUsage(args []string) ([]byte, error) {
+	return us.runCCUsageIn(args, ccusageConfigDirFor(us.claudeDataDir, us.sources))
+}
+
+// runCCUsageIn runs ccusage with CLAUDE_CONFIG_DIR set to configDir, or
+// inherited when configDir is empty.
+func (us *UsageService) runCCUsageIn(args []string, configDir string) ([]byte, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), us.cmdTimeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, us.ccusagePath, "daily", "--json")
-	output, err := cmd.Output()
+	bin, prefix, err := SplitCCUsageCommand(us.ccusagePath)
+	if err != nil {
+		return nil, err
+	}
+	var env []string
+	if configDir != "" {
+		env = append(env, "CLAUDE_CONFIG_DIR="+configDir)
+	}
+	output, stderr, _, err := us.runner.Run(ctx, bin, append(prefix, args...), env...)
+	us.dumpCCUsageOutput(args, output, stderr, err)
 	if err != nil {
 		// When the context deadline fires, Go kills the child with SIGKILL and
 		// surfaces a generic "signal: killed". Translate it so users see what
@@ -350,49 +758,124 @@ func (us *UsageService) executeCCUsage() ([]byte, error) {
 	us.logger.Debug("ccusage command successful", map[string]interface{}{
 		"out_len": len(output),
 	})
-
 	return output, nil
 }
 
-func parseCCUsageResponse(output []byte) (*CCUsageResponse, error) {
-	var response CCUsageResponse
-	if err := json.Unmarshal(output, &response); err != nil {
-		return nil, err
+// ccusageDailyArgs builds the ccusage invocation for the given moment.
+// Restricting the report to at most a month keeps ccusage from
+// re-crunching the whole JSONL archive on every poll while still covering
+// the current week and month, the same weekday last week for comparisons,
+// and the trailing week for the month-end forecast.
+func ccusageDailyArgs(now time.Time) []string {
+	since, until := reportRange(now)
+	return []string{
+		"daily", "--json",
+		"--since", since.Format(ccusageDateFormat),
+		"--until", until.Format(ccusageDateFormat),
+	}
+}
+
+// reportRange returns the first and last day ccusage is asked for: the
+// first of the month or seven days before today, whichever is earlier,
+// through today. This always includes the Monday that starts the current
+// week.
+func reportRange(now time.Time) (time.Time, time.Time) {
+	_, today := currentWeekRange(now)
+	since := today.AddDate(0, 0, -7)
+	if first := monthStart(today); first.Before(since) {
+		since = first
 	}
-	return &response, nil
+	return since, today
 }
 
-func findTodayOutput(response *CCUsageResponse, today string) (CCUsageOutput, bool) {
-	for _, daily := range response.Daily {
-		if daily.Date == today {
-			return daily, true
+// comparisonDates returns yesterday and the same weekday last week as
+// YYYY-MM-DD keys into the daily array.
+func comparisonDates(now time.Time) (string, string) {
+	_, today := currentWeekRange(now)
+	return today.AddDate(0, 0, -1).Format("2006-01-02"), today.AddDate(0, 0, -7).Format("2006-01-02")
+}
+
+// costBetween sums the costs dated from through to inclusive (YYYY-MM-DD).
+func costBetween(days map[string]dayTotal, from, to string) float64 {
+	total := 0.0
+	for date, day := range days {
+		if date >= from && date <= to {
+			total += day.Cost
 		}
 	}
-	return CCUsageOutput{}, false
+	return total
 }
 
-func availableDates(daily []CCUsageOutput) []string {
-	dates := make([]string, len(daily))
-	for i, d := range daily {
-		dates[i] = d.Date
+func costComparison(date string, total dayTotal) *models.CostComparison {
+	if !total.Found {
+		return nil
 	}
-	return dates
+	return &models.CostComparison{Date: date, Cost: total.Cost}
+}
+
+// monthStart returns local midnight on the first of now's month.
+func monthStart(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+}
+
+// currentWeekRange returns the Monday starting the week containing now and
+// now itself, both truncated to local midnight.
+func currentWeekRange(now time.Time) (time.Time, time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	offset := (int(today.Weekday()) + 6) % 7 // days since Monday
+	return today.AddDate(0, 0, -offset), today
 }
 
 func (us *UsageService) applyUsageDataLocked(output CCUsageOutput) {
 	us.setStateMetricsLocked(output.TotalTokens, output.TotalCost, true)
+	us.state.Models = modelUsageFrom(output.ModelBreakdowns, output.ModelsUsed)
 	us.updateStatusLocked()
 }
 
+// modelUsageFrom converts ccusage's per-model breakdown into state, counting
+// every token class toward the model's total. Reports without a breakdown
+// fall back to the modelsUsed names, with no tokens or cost.
+func modelUsageFrom(breakdowns []CCUsageModelSummary, modelsUsed []string) []models.ModelUsage {
+	if len(breakdowns) == 0 {
+		if len(modelsUsed) == 0 {
+			return nil
+		}
+		usage := make([]models.ModelUsage, 0, len(modelsUsed))
+		for _, name := range modelsUsed {
+			usage = append(usage, models.ModelUsage{Name: name})
+		}
+		return usage
+	}
+	usage := make([]models.ModelUsage, 0, len(breakdowns))
+	for _, b := range breakdowns {
+		usage = append(usage, models.ModelUsage{
+			Name:   b.ModelName,
+			Tokens: b.InputTokens + b.OutputTokens + b.CacheCreationTokens + b.CacheReadTokens,
+			Cost:   b.Cost,
+		})
+	}
+	return usage
+}
+
 func (us *UsageService) updateStatusLocked() {
-	us.state.UpdateStatus(us.yellowThreshold, us.redThreshold)
+	now := us.clock.Now()
+	if len(us.alertLevels) > 0 {
+		us.state.UpdateLevels(us.alertLevels)
+	} else {
+		us.state.UpdateStatus(models.ResolveDayThresholds(
+			us.dayThresholds, now.Weekday(), us.yellowThreshold, us.redThreshold))
+	}
+	us.state.UpdateModelAlerts(us.modelThresholds)
+	if models.QuietActive(us.quietUntil, now) {
+		us.state.Silence()
+	}
 }
 
 func (us *UsageService) logCommandFailure(err error, output []byte, extra map[string]interface{}) {
 	context := map[string]interface{}{
 		"error":   err.Error(),
 		"out_len": len(output),
-		"output":  truncateOutput(output),
+		"output":  us.truncateOutput(output),
 		"path":    us.ccusagePath,
 	}
 	for k, v := range extra {
@@ -402,108 +885,418 @@ func (us *UsageService) logCommandFailure(err error, output []byte, extra map[st
 	us.logger.Warn("ccusage command failed", context)
 }
 
-func truncateOutput(output []byte) string {
-	if len(output) <= maxLoggedOutputLength {
-		return string(output)
-	}
-	return string(output[:maxLoggedOutputLength]) + "..."
+func (us *UsageService) sleepForRetry(attempt int) {
+	us.sleep(retryDelay(attempt))
 }
 
-func (us *UsageService) sleepForRetry(attempt int) {
-	time.Sleep(time.Duration(attempt) * time.Second)
+// retryDelay is the wait after a failed attempt before the next.
+func retryDelay(attempt int) time.Duration {
+	return time.Duration(attempt) * time.Second
 }
 
 // StartPolling starts a configurable-interval polling timer that invokes
-// callback with the latest state on each tick (T030).
+// callback with the latest state on each tick (T030). Polling already in
+// progress is stopped first, so StartPolling also restarts cleanly.
 func (us *UsageService) StartPolling(intervalSeconds int, callback func(*models.UsageState)) error {
 	if intervalSeconds <= 0 {
 		return lib.ValidationError("polling interval must be positive")
 	}
 
-	us.StopPolling()
+	us.lifecycle.Lock()
+	defer us.lifecycle.Unlock()
+	us.stopPollingLocked()
 
 	// Create ticker and assign callback atomically with mutex protection
 	us.mutex.Lock()
+	if err := us.setPollingStatusLocked(PollingStarting); err != nil {
+		us.mutex.Unlock()
+		return err
+	}
+	interval := time.Duration(intervalSeconds) * time.Second
+	loop, ctx := newPollLoop(interval)
+	us.poll.Store(loop)
 	us.updateCallback = callback
-	us.ticker = time.NewTicker(time.Duration(intervalSeconds) * time.Second)
+	us.setPollIntervalLocked(interval)
+	us.lastActivity.Store(time.Now().UnixNano())
+	watchdogCtx, watchdogCancel := context.WithCancel(context.Background())
+	us.watchdogCancel = watchdogCancel
 	us.mutex.Unlock()
 
 	us.logger.Info("Starting usage polling", map[string]interface{}{
 		"intervalSeconds": intervalSeconds,
 	})
 
-	go us.pollingLoop()
+	us.loops.Add(1)
+	go us.pollingLoop(ctx, loop)
+	us.watchdog.Add(1)
+	go us.watchdogLoop(watchdogCtx, interval)
 
-	return nil
+	if us.watchDataDirs {
+		us.startWatcher()
+	}
+
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+	return us.setPollingStatusLocked(PollingRunning)
 }
 
-// StopPolling stops the polling timer
-func (us *UsageService) StopPolling() {
-	select {
-	case us.pollStopChan <- struct{}{}:
-	default:
+// startWatcher triggers an immediate (debounced) refresh whenever Claude
+// writes new usage entries. The ticker keeps running as a fallback for
+// changes the watcher can't see, such as the date rolling over. The
+// refresh itself runs on the polling loop, via signalWake.
+func (us *UsageService) startWatcher() {
+	watcher, err := newDataWatcher(us.dataDirs, defaultWatchDebounce, func() {
+		us.logger.Debug("Claude data changed, refreshing usage")
+		us.signalWake()
+	})
+	if err != nil {
+		us.logger.Warn("Data directory watcher unavailable, relying on polling", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
 	}
+
+	us.mutex.Lock()
+	us.watcher = watcher
+	us.mutex.Unlock()
+}
+
+// signal does a non-blocking send on ch; a signal already pending covers
+// this one. A nil ch (polling not started) is ignored.
+func signal(ch chan struct{}) {
 	select {
-	case us.resetStopChan <- struct{}{}:
+	case ch <- struct{}{}:
 	default:
 	}
+}
 
+// signalWake asks the running polling loop for a refresh. It looks the
+// loop up each time, so a loop the watchdog has replaced is never woken.
+func (us *UsageService) signalWake() {
+	if loop := us.poll.Load(); loop != nil {
+		signal(loop.wake)
+	}
+}
+
+// signalResume asks the running polling loop to end a quiet_hours pause.
+func (us *UsageService) signalResume() {
+	if loop := us.poll.Load(); loop != nil {
+		signal(loop.resume)
+	}
+}
+
+// StopPolling stops polling and the daily reset monitor and returns once
+// their goroutines have exited. It is safe to call repeatedly, including
+// when nothing was started. Updates already queued for the update
+// callback are delivered before it returns, so it must not be called from
+// the callback.
+func (us *UsageService) StopPolling() {
+	us.lifecycle.Lock()
+	defer us.lifecycle.Unlock()
+	us.stopPollingLocked()
+}
+
+// stopPollingLocked assumes us.lifecycle is held.
+func (us *UsageService) stopPollingLocked() {
 	us.mutex.Lock()
-	if us.ticker != nil {
-		us.ticker.Stop()
-		us.ticker = nil
+	wasPolling := us.pollingStatus != PollingStopped
+	if wasPolling {
+		_ = us.setPollingStatusLocked(PollingStopping)
 	}
+	for _, cancel := range []context.CancelFunc{us.resetCancel, us.watchdogCancel} {
+		if cancel != nil {
+			cancel()
+		}
+	}
+	us.resetCancel, us.watchdogCancel = nil, nil
+	if loop := us.poll.Swap(nil); loop != nil {
+		loop.stop()
+	}
+	if us.resumeTimer != nil {
+		us.resumeTimer.Stop()
+		us.resumeTimer = nil
+	}
+	us.state.Paused = false
+	watcher := us.watcher
+	us.watcher = nil
 	us.mutex.Unlock()
 
-	us.logger.Info("Usage polling stopped")
+	// Close and wait outside the lock: the loops take it to finish a poll.
+	if watcher != nil {
+		watcher.Close()
+	}
+	us.watchdog.Wait()
+	us.loops.Wait()
+	us.ui.flush()
+	us.mutex.RLock()
+	tracer := us.tracer
+	us.mutex.RUnlock()
+	tracer.Shutdown() // flush traces of the last polls
+
+	if wasPolling {
+		us.mutex.Lock()
+		_ = us.setPollingStatusLocked(PollingStopped)
+		us.mutex.Unlock()
+		us.logger.Info("Usage polling stopped")
+	}
 }
 
-// pollingLoop runs the polling loop in a goroutine
-func (us *UsageService) pollingLoop() {
-	us.mutex.RLock()
-	if us.ticker == nil {
-		us.mutex.RUnlock()
-		us.logger.Error("Polling loop started without ticker")
-		return
+// pollingLoop runs every scheduled refresh (ticks, watcher wake-ups, and
+// the end of a quiet_hours pause) until ctx is cancelled.
+// A panic while handling one is logged and the loop restarts, marking
+// polling degraded, so polling never silently dies.
+func (us *UsageService) pollingLoop(ctx context.Context, loop *pollLoop) {
+	defer us.loops.Done()
+
+	for us.runGuarded("polling loop", func() { us.servePolling(ctx, loop) }) {
+		us.mutex.Lock()
+		if us.pollingStatus == PollingRunning {
+			_ = us.setPollingStatusLocked(PollingDegraded)
+		}
+		us.mutex.Unlock()
+		if ctx.Err() != nil {
+			return
+		}
+		us.logger.Warn("Restarting polling loop after panic")
 	}
-	ticker := us.ticker
-	us.mutex.RUnlock()
+}
 
+func (us *UsageService) servePolling(ctx context.Context, loop *pollLoop) {
 	for {
 		select {
-		case <-ticker.C:
+		case <-loop.ticker.C:
 			us.logger.Debug("Polling timer triggered")
+			us.scheduledPoll(pollRetries)
 
-			state, err := us.updateWithRetry(3) // 3 retries for polling
-			if err != nil {
-				us.logger.Error("Polling update failed", map[string]interface{}{
-					"error": err.Error(),
-				})
-			}
+		case <-loop.wake:
+			us.scheduledPoll(1)
 
-			us.mutex.RLock()
-			callback := us.updateCallback
-			us.mutex.RUnlock()
-			if callback != nil {
-				callback(state)
-			}
+		case <-loop.resume:
+			us.resumePolling()
 
-		case <-us.pollStopChan:
+		case <-ctx.Done():
 			us.logger.Debug("Polling loop stopped")
 			return
 		}
+		us.lastActivity.Store(time.Now().UnixNano())
+	}
+}
+
+// scheduledPoll is pollOnce for timer- and watcher-driven refreshes, which
+// are skipped during quiet_hours. Explicit Refresh calls still go through.
+func (us *UsageService) scheduledPoll(maxRetries int) {
+	skip, paused := us.quietHoursPause()
+	if !skip {
+		us.pollOnce(maxRetries)
+		return
+	}
+	if paused != nil {
+		us.mutex.RLock()
+		callback := us.updateCallback
+		us.mutex.RUnlock()
+		us.dispatchUpdate(callback, paused, nil)
+	}
+}
+
+// quietHoursPause reports whether quiet_hours hold back a scheduled poll.
+// When the window has just begun it pauses polling and returns the state
+// to show.
+func (us *UsageService) quietHoursPause() (skip bool, paused *models.UsageState) {
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+
+	now := us.clock.Now()
+	if !us.quietHours.Active(now) {
+		return false, nil
+	}
+	if us.resumeTimer != nil {
+		return true, nil // already paused
+	}
+	if err := us.setPollingStatusLocked(PollingPaused); err != nil {
+		return true, nil // stopping or stopped
+	}
+
+	resumeAt := us.quietHours.NextEnd(now)
+	us.resumeTimer = time.AfterFunc(resumeAt.Sub(now), us.signalResume)
+	us.state.Paused = true
+	us.logger.Info("Quiet hours started, pausing polling", map[string]interface{}{
+		"resume_at": resumeAt.Format(time.RFC3339),
+	})
+	return true, us.getStateCopyLocked()
+}
+
+// resumePolling ends a quiet_hours pause with an immediate refresh.
+func (us *UsageService) resumePolling() {
+	if !us.endPause() {
+		return // polling stopped while paused
+	}
+	us.logger.Info("Quiet hours ended, resuming polling")
+	us.scheduledPoll(3)
+}
+
+func (us *UsageService) endPause() bool {
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+	us.resumeTimer = nil
+	us.state.Paused = false
+	return us.setPollingStatusLocked(PollingRunning) == nil
+}
+
+// Refresh forces a fresh query like UpdateUsage and also notifies the
+// polling callback, so the UI reflects out-of-band refresh requests.
+func (us *UsageService) Refresh() (*models.UsageState, error) {
+	return us.pollOnce(1)
+}
+
+// pollOnce refreshes usage and queues the result for the registered
+// callback, without waiting for it to run. With tracing on, each call is
+// one trace: a "poll" span with children for the ccusage run, parsing, the
+// state update, and the UI callback, ending once the callback has run.
+func (us *UsageService) pollOnce(maxRetries int) (*models.UsageState, error) {
+	span, state, err := us.tracedUpdate(maxRetries)
+	span.SetError(err)
+
+	if err != nil {
+		us.logger.Error("Polling update failed", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	us.mutex.Lock()
+	// "No data for today" is an error to callers but a working poll.
+	us.recordPollOutcomeLocked(us.clock.Now(), err == nil || (state != nil && state.IsAvailable))
+	if err == nil && state != nil && state.IsAvailable {
+		us.recordUsageSampleLocked(us.clock.Now(), state.DailyCount, state.DailyCost)
+	}
+	// Explicit refreshes while stopped or paused leave the lifecycle alone.
+	if us.pollingStatus == PollingRunning || us.pollingStatus == PollingDegraded {
+		if err != nil {
+			_ = us.setPollingStatusLocked(PollingDegraded)
+		} else {
+			_ = us.setPollingStatusLocked(PollingRunning)
+		}
+	}
+	callback := us.updateCallback
+	history := us.history
+	days := us.reportDays
+	us.mutex.Unlock()
+	if err == nil && history != nil {
+		if perr := history.ObservePricing(days); perr != nil {
+			us.logger.Warn("Failed to track ccusage pricing", map[string]interface{}{
+				"error": perr.Error(),
+			})
+		}
+		if herr := history.Record(state); herr != nil {
+			us.logger.Warn("Failed to record usage history", map[string]interface{}{
+				"error": herr.Error(),
+			})
+		}
+	}
+	us.dispatchUpdate(callback, state, span)
+	return state, err
+}
+
+// tracedUpdate is updateWithRetry under a new "poll" span, which it
+// returns for the caller to end.
+func (us *UsageService) tracedUpdate(maxRetries int) (*lib.Span, *models.UsageState, error) {
+	requests := us.countRequests()
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+	span := us.tracer.Start("poll")
+	span.SetAttribute("max_retries", maxRetries)
+	us.span = span
+	defer func() { us.span = nil }()
+	state, err := us.performUpdateLocked(maxRetries, requests)
+	return span, state, err
+}
+
+// runCallback hands state to callback, if any. A panic in it is logged
+// rather than propagated, so a UI bug can't take the poller down.
+func (us *UsageService) runCallback(callback func(*models.UsageState), state *models.UsageState) {
+	if callback != nil {
+		us.runGuarded("update callback", func() { callback(state) })
 	}
 }
 
+// runGuarded runs fn, recovering from and logging any panic with a stack
+// trace. It reports whether fn panicked.
+func (us *UsageService) runGuarded(name string, fn func()) (panicked bool) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			panicked = true
+			err := lib.PanicError(recovered).WithContext("goroutine", name)
+			us.logger.Error("Recovered from panic", map[string]interface{}{
+				"error":     err.Error(),
+				"code":      err.Code,
+				"goroutine": name,
+				"stack":     err.Context["stack"],
+			})
+		}
+	}()
+	fn()
+	return false
+}
+
 // StartDailyResetMonitor starts the daily reset scheduler with midnight
-// detection (T031).
+// detection (T031). It does nothing if the monitor is already running;
+// StopPolling stops it.
 func (us *UsageService) StartDailyResetMonitor() {
-	go us.dailyResetLoop()
+	us.lifecycle.Lock()
+	defer us.lifecycle.Unlock()
+
+	us.mutex.Lock()
+	if us.resetCancel != nil {
+		us.mutex.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	us.resetCancel = cancel
+	us.mutex.Unlock()
+
+	us.loops.Add(1)
+	go us.dailyResetLoop(ctx)
 	us.logger.Info("Daily reset monitor started")
 }
 
-// dailyResetLoop monitors for midnight and resets daily counters
-func (us *UsageService) dailyResetLoop() {
+// dailyResetLoop monitors for midnight and resets daily counters,
+// restarting after a panic like pollingLoop.
+func (us *UsageService) dailyResetLoop(ctx context.Context) {
+	defer us.loops.Done()
+
+	for us.runGuarded("daily reset loop", func() { us.serveDailyReset(ctx) }) {
+		if ctx.Err() != nil {
+			return
+		}
+		us.logger.Warn("Restarting daily reset loop after panic")
+	}
+}
+
+// dailyReset zeroes the day's counters and shows the new day. It fetches
+// today's usage for that unless quiet_hours have paused polling, in which
+// case the zeroed state is shown and ccusage isn't run until they end.
+func (us *UsageService) dailyReset() {
+	if err := us.ResetDaily(); err != nil {
+		us.logger.Error("Daily reset failed", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	us.logger.Info("Daily usage reset successfully")
+	us.mutex.RLock()
+	callback := us.updateCallback
+	paused := us.state.Paused
+	state := us.getStateCopyLocked()
+	us.mutex.RUnlock()
+	if callback == nil {
+		return
+	}
+	if !paused {
+		state, _ = us.GetDailyUsage()
+	}
+	us.dispatchUpdate(callback, state, nil)
+}
+
+func (us *UsageService) serveDailyReset(ctx context.Context) {
 	lastResetDay := time.Now().Day()
 	resetChecker := time.NewTicker(1 * time.Minute)
 	defer resetChecker.Stop()
@@ -518,24 +1311,11 @@ func (us *UsageService) dailyResetLoop() {
 					"lastResetDay": lastResetDay,
 				})
 
-				if err := us.ResetDaily(); err != nil {
-					us.logger.Error("Daily reset failed", map[string]interface{}{
-						"error": err.Error(),
-					})
-				} else {
-					us.logger.Info("Daily usage reset successfully")
-					us.mutex.RLock()
-					callback := us.updateCallback
-					us.mutex.RUnlock()
-					if callback != nil {
-						state, _ := us.GetDailyUsage()
-						callback(state)
-					}
-				}
+				us.dailyReset()
 				lastResetDay = now.Day()
 			}
 
-		case <-us.resetStopChan:
+		case <-ctx.Done():
 			us.logger.Debug("Daily reset loop stopped")
 			return
 		}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -12,13 +13,19 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"cc-dailyuse-bar/src/internal/testhelpers"
 	"cc-dailyuse-bar/src/models"
 )
 
 // Helper function to create a usage service with default config
 func newTestUsageService() *UsageService {
 	config := models.ConfigDefaults()
-	return NewUsageService(config)
+	service := NewUsageService(config)
+	// Keep tests away from the user's real cache and Claude data.
+	service.diskCache = nil
+	service.dataDirs = nil
+	service.sources = nil
+	return service
 }
 
 func TestNewUsageService(t *testing.T) {
@@ -32,7 +39,7 @@ func TestNewUsageService(t *testing.T) {
 	// Logger component is not exported, so we can't test it directly
 	assert.Equal(t, 10*time.Second, service.cacheWindow)
 	assert.Equal(t, 30*time.Second, service.cmdTimeout)
-	assert.NotNil(t, service.pollStopChan)
+	assert.Equal(t, PollingStopped, service.PollingStatus())
 }
 
 func TestUsageService_IsAvailable(t *testing.T) {
@@ -66,8 +73,8 @@ func TestUsageService_IsAvailable_ResolvedViaPath(t *testing.T) {
 
 	tempDir := t.TempDir()
 	binName := "cc-fake-shim"
-	binPath := filepath.Join(tempDir, binName)
-	require.NoError(t, os.WriteFile(binPath, []byte("#!/bin/bash\nexit 0"), 0o755))
+	// On Windows this is cc-fake-shim.cmd, found through PATHEXT.
+	testhelpers.WriteFakeCommand(t, tempDir, binName, "", 0)
 
 	// Prepend tempDir to PATH so LookPath resolves the bare name.
 	t.Setenv("PATH", tempDir+string(os.PathListSeparator)+os.Getenv("PATH"))
@@ -91,13 +98,12 @@ func TestUsageService_IsAvailable_BareNameInCWDNotOnPath(t *testing.T) {
 
 	tempDir := t.TempDir()
 	binName := "cc-cwd-only-shim"
-	require.NoError(t, os.WriteFile(filepath.Join(tempDir, binName),
-		[]byte("#!/bin/bash\nexit 0"), 0o755))
+	testhelpers.WriteFakeCommand(t, tempDir, binName, "", 0)
 
 	// Make tempDir the cwd but exclude it from PATH so the only way to
 	// "find" the binary is via the (incorrect) cwd-stat code path.
 	t.Chdir(tempDir)
-	t.Setenv("PATH", "/usr/bin:/bin")
+	t.Setenv("PATH", t.TempDir())
 
 	service.ccusagePath = binName
 	assert.False(t, service.IsAvailable(),
@@ -152,6 +158,31 @@ func TestUsageService_ResetDaily(t *testing.T) {
 	assert.True(t, service.lastQuery.IsZero()) // Should be cleared
 }
 
+func TestUsageService_DailyResetDuringQuietHours(t *testing.T) {
+	service := newTestUsageService()
+	service.SetClock(fixedClock{now: time.Date(2025, 3, 14, 0, 1, 0, 0, time.Local)})
+	ran := filepath.Join(t.TempDir(), "ran")
+	service.ccusagePath = writeCCUsageScript(t, fmt.Sprintf(
+		`touch %q; echo '{"daily":[{"date":"2025-03-14","totalTokens":1,"totalCost":1}]}'`, ran))
+	require.NoError(t, service.SetQuietUntil("2025-03-20"))
+	updates := make(chan *models.UsageState, 1)
+	service.updateCallback = func(state *models.UsageState) { updates <- state }
+	service.state.DailyCost = 25
+	service.state.Paused = true
+
+	service.dailyReset()
+	state := <-updates
+	assert.Zero(t, state.DailyCost)
+	assert.True(t, state.Paused)
+	assert.True(t, state.Quiet, "quiet_until still covers the new day")
+	assert.NoFileExists(t, ran, "ccusage doesn't run while quiet_hours pause polling")
+
+	service.state.Paused = false
+	service.dailyReset()
+	<-updates
+	assert.FileExists(t, ran)
+}
+
 func TestUsageService_SetThresholds(t *testing.T) {
 	service := newTestUsageService()
 
@@ -276,8 +307,8 @@ func TestUsageService_StartPolling_ValidInterval(t *testing.T) {
 	err := service.StartPolling(1, callback)
 	require.NoError(t, err)
 
-	// Verify ticker is set
-	assert.NotNil(t, service.ticker)
+	// Verify the polling loop is set
+	assert.NotNil(t, service.poll.Load())
 	assert.NotNil(t, service.updateCallback)
 
 	// Wait a bit for callback to be called
@@ -286,8 +317,8 @@ func TestUsageService_StartPolling_ValidInterval(t *testing.T) {
 	// Stop polling
 	service.StopPolling()
 
-	// Verify ticker is cleared
-	assert.Nil(t, service.ticker)
+	// Verify the polling loop is cleared
+	assert.Nil(t, service.poll.Load())
 }
 
 func TestUsageService_StopPolling(t *testing.T) {
@@ -303,8 +334,8 @@ func TestUsageService_StopPolling(t *testing.T) {
 	// Stop polling
 	service.StopPolling()
 
-	// Verify ticker is cleared
-	assert.Nil(t, service.ticker)
+	// Verify the polling loop is cleared
+	assert.Nil(t, service.poll.Load())
 }
 
 func TestUsageService_StartDailyResetMonitor(t *testing.T) {
@@ -344,17 +375,7 @@ func TestUsageService_UpdateWithRetry_NotAvailable(t *testing.T) {
 func TestUsageService_UpdateWithRetry_CommandFailure(t *testing.T) {
 	service := newTestUsageService()
 
-	// Create a temporary script that always fails
-	tempDir := t.TempDir()
-	scriptPath := filepath.Join(tempDir, "failing-ccusage")
-
-	scriptContent := `#!/bin/bash
-exit 1`
-
-	err := os.WriteFile(scriptPath, []byte(scriptContent), 0755)
-	require.NoError(t, err)
-
-	service.ccusagePath = scriptPath
+	service.ccusagePath = testhelpers.WriteFakeCommand(t, t.TempDir(), "failing-ccusage", "", 1)
 
 	state, err := service.updateWithRetry(2)
 
@@ -365,17 +386,7 @@ exit 1`
 func TestUsageService_UpdateWithRetry_InvalidJSON(t *testing.T) {
 	service := newTestUsageService()
 
-	// Create a temporary script that returns invalid JSON
-	tempDir := t.TempDir()
-	scriptPath := filepath.Join(tempDir, "invalid-json-ccusage")
-
-	scriptContent := `#!/bin/bash
-echo "invalid json"`
-
-	err := os.WriteFile(scriptPath, []byte(scriptContent), 0755)
-	require.NoError(t, err)
-
-	service.ccusagePath = scriptPath
+	service.ccusagePath = testhelpers.WriteFakeCommand(t, t.TempDir(), "invalid-json-ccusage", "invalid json\n", 0)
 
 	state, err := service.updateWithRetry(1)
 
@@ -412,13 +423,7 @@ func TestUsageService_UpdateWithRetry_ValidJSON(t *testing.T) {
 	jsonData, err := json.Marshal(response)
 	require.NoError(t, err)
 
-	scriptContent := `#!/bin/bash
-echo '` + string(jsonData) + `'`
-
-	err = os.WriteFile(scriptPath, []byte(scriptContent), 0755)
-	require.NoError(t, err)
-
-	service.ccusagePath = scriptPath
+	service.ccusagePath = testhelpers.WriteFakeCommand(t, tempDir, filepath.Base(scriptPath), string(jsonData), 0)
 
 	state, err := service.updateWithRetry(1)
 
@@ -456,13 +461,7 @@ func TestUsageService_UpdateWithRetry_NoDataForToday(t *testing.T) {
 	jsonData, err := json.Marshal(response)
 	require.NoError(t, err)
 
-	scriptContent := `#!/bin/bash
-echo '` + string(jsonData) + `'`
-
-	err = os.WriteFile(scriptPath, []byte(scriptContent), 0755)
-	require.NoError(t, err)
-
-	service.ccusagePath = scriptPath
+	service.ccusagePath = testhelpers.WriteFakeCommand(t, tempDir, filepath.Base(scriptPath), string(jsonData), 0)
 
 	state, err := service.updateWithRetry(1)
 
@@ -501,13 +500,7 @@ func TestUsageService_UpdateWithRetry_ZeroValues(t *testing.T) {
 	jsonData, err := json.Marshal(response)
 	require.NoError(t, err)
 
-	scriptContent := `#!/bin/bash
-echo '` + string(jsonData) + `'`
-
-	err = os.WriteFile(scriptPath, []byte(scriptContent), 0755)
-	require.NoError(t, err)
-
-	service.ccusagePath = scriptPath
+	service.ccusagePath = testhelpers.WriteFakeCommand(t, tempDir, filepath.Base(scriptPath), string(jsonData), 0)
 
 	state, err := service.updateWithRetry(1)
 
@@ -639,13 +632,7 @@ func TestUsageService_NoDataForToday(t *testing.T) {
 	jsonData, err := json.Marshal(response)
 	require.NoError(t, err)
 
-	scriptContent := `#!/bin/bash
-echo '` + string(jsonData) + `'`
-
-	err = os.WriteFile(scriptPath, []byte(scriptContent), 0755)
-	require.NoError(t, err)
-
-	service.ccusagePath = scriptPath
+	service.ccusagePath = testhelpers.WriteFakeCommand(t, tempDir, filepath.Base(scriptPath), string(jsonData), 0)
 
 	// Act
 	state, err := service.UpdateUsage()
@@ -676,3 +663,340 @@ func TestUsageService_DataUnavailable(t *testing.T) {
 	assert.False(t, state.IsAvailable)            // ccusage itself is unavailable
 	assert.Equal(t, models.Unknown, state.Status) // Should be Unknown
 }
+
+type fixedClock struct{ now time.Time }
+
+func (c fixedClock) Now() time.Time { return c.now }
+
+func TestCurrentWeekRange(t *testing.T) {
+	tests := []struct {
+		name  string
+		now   time.Time
+		since string
+	}{
+		{"monday", time.Date(2025, 3, 10, 9, 0, 0, 0, time.Local), "20250310"},
+		{"wednesday", time.Date(2025, 3, 12, 23, 59, 0, 0, time.Local), "20250310"},
+		{"sunday", time.Date(2025, 3, 16, 0, 1, 0, 0, time.Local), "20250310"},
+		{"across month", time.Date(2025, 4, 2, 12, 0, 0, 0, time.Local), "20250331"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since, until := currentWeekRange(tt.now)
+			assert.Equal(t, tt.since, since.Format(ccusageDateFormat))
+			assert.Equal(t, tt.now.Format(ccusageDateFormat), until.Format(ccusageDateFormat))
+		})
+	}
+}
+
+func TestUsageService_PassesDateRangeToCCUsage(t *testing.T) {
+	service := newTestUsageService()
+	now := time.Date(2025, 3, 12, 15, 30, 0, 0, time.Local)
+	service.SetClock(fixedClock{now: now})
+
+	tempDir := t.TempDir()
+	argsFile := filepath.Join(tempDir, "args")
+	scriptPath := filepath.Join(tempDir, "args-ccusage")
+	scriptContent := `#!/bin/bash
+[ "$1" = daily ] && echo "$@" > '` + argsFile + `'
+echo '{"daily":[{"date":"2025-03-09","totalTokens":99,"totalCost":9},{"date":"2025-03-10","totalTokens":5,"totalCost":2.25},{"date":"2025-03-12","totalTokens":10,"totalCost":1.5}]}'`
+	require.NoError(t, os.WriteFile(scriptPath, []byte(scriptContent), 0755))
+	service.ccusagePath = scriptPath
+
+	state, err := service.UpdateUsage()
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, state.DailyCost)
+	assert.Equal(t, now, state.LastUpdate)
+	assert.Equal(t, 3.75, state.WeeklyCost, "only Monday onwards counts toward the week")
+	assert.Equal(t, 15, state.WeeklyCount)
+	assert.Equal(t, 12.75, state.MonthlyCost)
+	assert.InDelta(t, models.ForecastMonth(12.75, 12.75, now), state.Forecast, 1e-9)
+
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	assert.Equal(t, "daily --json --since 20250301 --until 20250312\n", string(args))
+}
+
+func TestReportRange(t *testing.T) {
+	tests := []struct {
+		now       time.Time
+		wantSince string
+	}{
+		{time.Date(2025, 3, 12, 15, 30, 0, 0, time.Local), "20250301"}, // the month reaches back further
+		{time.Date(2025, 3, 3, 9, 0, 0, 0, time.Local), "20250224"},    // early in the month: a week back
+	}
+	for _, tt := range tests {
+		since, until := reportRange(tt.now)
+		assert.Equal(t, tt.wantSince, since.Format(ccusageDateFormat), tt.now.String())
+		assert.Equal(t, tt.now.Format(ccusageDateFormat), until.Format(ccusageDateFormat))
+	}
+}
+
+func TestUsageService_ComparesWithPreviousDays(t *testing.T) {
+	service := newTestUsageService()
+	service.SetClock(fixedClock{now: time.Date(2025, 3, 12, 15, 30, 0, 0, time.Local)})
+
+	scriptPath := filepath.Join(t.TempDir(), "compare-ccusage")
+	scriptContent := `#!/bin/bash
+echo '{"daily":[{"date":"2025-03-05","totalTokens":7,"totalCost":4},{"date":"2025-03-11","totalTokens":5,"totalCost":2.5},{"date":"2025-03-12","totalTokens":10,"totalCost":3.3}]}'`
+	require.NoError(t, os.WriteFile(scriptPath, []byte(scriptContent), 0755))
+	service.ccusagePath = scriptPath
+
+	state, err := service.UpdateUsage()
+	require.NoError(t, err)
+	require.NotNil(t, state.Yesterday)
+	assert.Equal(t, models.CostComparison{Date: "2025-03-11", Cost: 2.5}, *state.Yesterday)
+	require.NotNil(t, state.LastWeek)
+	assert.Equal(t, models.CostComparison{Date: "2025-03-05", Cost: 4}, *state.LastWeek)
+	assert.Equal(t, 5.8, state.WeeklyCost, "last week's day stays out of the weekly total")
+
+	scriptContent = `#!/bin/bash
+echo '{"daily":[{"date":"2025-03-12","totalTokens":10,"totalCost":3.3}]}'`
+	require.NoError(t, os.WriteFile(scriptPath, []byte(scriptContent), 0755))
+	state, err = service.UpdateUsage()
+	require.NoError(t, err)
+	assert.Nil(t, state.Yesterday)
+	assert.Nil(t, state.LastWeek)
+}
+
+func TestUsageService_SetAlertLevels(t *testing.T) {
+	service := newTestUsageService()
+	service.state.DailyCost = 12
+
+	service.SetAlertLevels([]models.AlertLevel{
+		{Name: "half", Threshold: 10, Status: models.Yellow},
+		{Name: "full", Threshold: 20, Status: models.Red},
+	})
+	assert.Equal(t, models.Yellow, service.state.Status)
+	assert.Equal(t, "half", service.state.Level)
+
+	service.SetAlertLevels(nil)
+	assert.Equal(t, models.Yellow, service.state.Status)
+	assert.Empty(t, service.state.Level)
+}
+
+func TestUsageService_DayThresholds(t *testing.T) {
+	service := newTestUsageService()
+	service.dayThresholds = map[string]models.ThresholdPair{
+		"weekends": {YellowThreshold: 2, RedThreshold: 5},
+	}
+	service.state.DailyCost = 6
+
+	// 2025-03-15 is a Saturday: the weekend pair applies.
+	service.SetClock(fixedClock{now: time.Date(2025, 3, 15, 12, 0, 0, 0, time.Local)})
+	service.updateStatusLocked()
+	assert.Equal(t, models.Red, service.state.Status)
+
+	// Monday falls back to the base 10/20 pair.
+	service.SetClock(fixedClock{now: time.Date(2025, 3, 17, 12, 0, 0, 0, time.Local)})
+	service.updateStatusLocked()
+	assert.Equal(t, models.Green, service.state.Status)
+}
+
+func TestUsageService_SetQuietUntil(t *testing.T) {
+	service := newTestUsageService()
+	service.SetClock(fixedClock{now: time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)})
+	service.state.DailyCost = 25
+
+	require.NoError(t, service.SetQuietUntil("2025-03-20"))
+	assert.Equal(t, models.Green, service.state.Status)
+	assert.True(t, service.state.Quiet)
+
+	require.NoError(t, service.SetQuietUntil(""))
+	assert.Equal(t, models.Red, service.state.Status)
+	assert.False(t, service.state.Quiet)
+
+	assert.Error(t, service.SetQuietUntil("tomorrow"))
+}
+
+func TestUsageService_ApplyConfig(t *testing.T) {
+	service := newTestUsageService()
+	service.state.DailyCost = 12
+	service.lastQuery = time.Now()
+	loop, _ := newPollLoop(time.Hour)
+	service.poll.Store(loop)
+	defer loop.stop()
+
+	config := models.ConfigDefaults()
+	config.YellowThreshold = 5
+	config.RedThreshold = 10
+	config.CCUsagePath = "/opt/ccusage"
+	config.CmdTimeout = 7
+	service.ApplyConfig(config)
+
+	assert.Equal(t, models.Red, service.state.Status)
+	assert.Equal(t, "/opt/ccusage", service.ccusagePath)
+	assert.Equal(t, 7*time.Second, service.cmdTimeout)
+	assert.True(t, service.lastQuery.IsZero(), "cached result is invalidated")
+}
+
+func TestUsageService_SetUpdateInterval(t *testing.T) {
+	service := newTestUsageService()
+	assert.NoError(t, service.SetUpdateInterval(15), "fine before polling starts")
+	assert.Error(t, service.SetUpdateInterval(0))
+
+	loop, _ := newPollLoop(time.Hour)
+	service.poll.Store(loop)
+	defer loop.stop()
+	require.NoError(t, service.SetUpdateInterval(1))
+	select {
+	case <-loop.ticker.C:
+	case <-time.After(3 * time.Second):
+		t.Fatal("ticker was not reset to the new interval")
+	}
+}
+
+func TestUsageService_ModelThresholds(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)
+	scriptPath := filepath.Join(t.TempDir(), "ccusage")
+	scriptContent := `#!/bin/bash
+echo '{"daily": [{"date": "2025-03-14", "totalTokens": 300, "totalCost": 14,
+  "modelBreakdowns": [
+    {"modelName": "claude-opus-4", "inputTokens": 100, "outputTokens": 100, "cost": 11},
+    {"modelName": "claude-sonnet-4", "inputTokens": 50, "outputTokens": 50, "cost": 3}
+  ]}]}'`
+	require.NoError(t, os.WriteFile(scriptPath, []byte(scriptContent), 0755))
+
+	service := newTestUsageService()
+	service.SetClock(fixedClock{now: now})
+	service.ccusagePath = scriptPath
+	service.modelThresholds = map[string]float64{"opus": 10}
+
+	state, err := service.UpdateUsage()
+	require.NoError(t, err)
+	require.Len(t, state.Models, 2)
+	assert.Equal(t, models.ModelUsage{Name: "claude-opus-4", Tokens: 200, Cost: 11}, state.Models[0])
+	assert.Equal(t, []models.ModelAlert{{Pattern: "opus", Cost: 11, Threshold: 10}}, state.ModelAlerts)
+}
+
+func TestUsageService_UpdateUsageCountsCalls(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)
+	dataDir := t.TempDir()
+	session := filepath.Join(dataDir, "session.jsonl")
+	require.NoError(t, os.WriteFile(session, []byte(
+		`{"timestamp":"`+now.Add(-time.Hour).Format(time.RFC3339)+`","requestId":"r1","message":{"id":"m1","usage":{}}}`+"\n"+
+			`{"timestamp":"`+now.Add(-time.Minute).Format(time.RFC3339)+`","requestId":"r2","message":{"id":"m2","usage":{}}}`+"\n"), 0o644))
+	require.NoError(t, os.Chtimes(session, now, now))
+
+	service := newTestUsageService()
+	service.SetClock(fixedClock{now: now})
+	service.dataDirs = []string{dataDir}
+	service.ccusagePath = writeCCUsageScript(t,
+		`echo '{"daily":[{"date":"2025-03-14","totalTokens":1200000,"totalCost":4}]}'`)
+
+	state, err := service.UpdateUsage()
+	require.NoError(t, err)
+	assert.Equal(t, 2, state.DailyCalls)
+	assert.Equal(t, 1200000, state.DailyCount, "tokens stay separate from calls")
+
+	require.NoError(t, service.ResetDaily())
+	assert.Zero(t, service.state.DailyCalls)
+}
+
+func TestUsageService_UpdateUsageActive(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)
+	costFile := filepath.Join(t.TempDir(), "cost")
+	require.NoError(t, os.WriteFile(costFile, []byte("4"), 0o644))
+
+	clock := &fixedClock{now: now}
+	service := newTestUsageService()
+	service.SetClock(clock)
+	service.ccusagePath = writeCCUsageScript(t, fmt.Sprintf(
+		`echo "{\"daily\":[{\"date\":\"2025-03-14\",\"totalTokens\":10,\"totalCost\":$(cat %q)}]}"`, costFile))
+
+	state, err := service.UpdateUsage()
+	require.NoError(t, err)
+	assert.False(t, state.Active, "the first poll has nothing to compare with")
+
+	require.NoError(t, os.WriteFile(costFile, []byte("5"), 0o644))
+	clock.now = now.Add(time.Minute)
+	state, err = service.UpdateUsage()
+	require.NoError(t, err)
+	assert.True(t, state.Active, "the cost rose")
+
+	clock.now = now.Add(2 * time.Minute)
+	state, err = service.UpdateUsage()
+	require.NoError(t, err)
+	assert.False(t, state.Active)
+
+	dataDir := t.TempDir()
+	session := filepath.Join(dataDir, "session.jsonl")
+	require.NoError(t, os.WriteFile(session, nil, 0o644))
+	require.NoError(t, os.Chtimes(session, clock.now.Add(-30*time.Second), clock.now.Add(-30*time.Second)))
+	service.dataDirs = []string{dataDir}
+	state, err = service.UpdateUsage()
+	require.NoError(t, err)
+	assert.True(t, state.Active, "a usage log was just written")
+
+	require.NoError(t, os.Chtimes(session, clock.now.Add(-5*time.Minute), clock.now.Add(-5*time.Minute)))
+	state, err = service.UpdateUsage()
+	require.NoError(t, err)
+	assert.False(t, state.Active, "the log was last written minutes ago")
+}
+
+func TestModelUsageFrom_ModelsUsedFallback(t *testing.T) {
+	assert.Equal(t, []models.ModelUsage{{Name: "claude-opus-4"}},
+		modelUsageFrom(nil, []string{"claude-opus-4"}), "names only when there's no breakdown")
+	assert.Equal(t, []models.ModelUsage{{Name: "claude-sonnet-4", Tokens: 2, Cost: 1}},
+		modelUsageFrom([]CCUsageModelSummary{{ModelName: "claude-sonnet-4", InputTokens: 1, OutputTokens: 1, Cost: 1}},
+			[]string{"claude-opus-4"}), "the breakdown wins")
+	assert.Nil(t, modelUsageFrom(nil, nil))
+}
+
+func TestUsageService_QuietHoursPausePolling(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "ran")
+	script := writeCCUsageScript(t, fmt.Sprintf(`touch %q
+echo '{"daily":[{"date":"2025-03-14","totalTokens":10,"totalCost":4}]}'`, marker))
+
+	clock := &fixedClock{now: time.Date(2025, 3, 14, 23, 30, 0, 0, time.Local)}
+	service := newTestUsageService()
+	service.SetClock(clock)
+	service.ccusagePath = script
+	quiet, err := models.ParseQuietHours("23:00-07:00")
+	require.NoError(t, err)
+	service.quietHours = quiet
+
+	service.pollingStatus = PollingRunning // as after StartPolling
+
+	var updates []*models.UsageState
+	service.updateCallback = func(state *models.UsageState) { updates = append(updates, state) }
+
+	service.scheduledPoll(1)
+	service.scheduledPoll(1) // still paused: no second update
+	service.ui.flush()
+	require.Len(t, updates, 1)
+	assert.True(t, updates[0].Paused)
+	assert.Equal(t, PollingPaused, service.PollingStatus())
+	assert.NoFileExists(t, marker, "ccusage is not run during quiet hours")
+	require.NotNil(t, service.resumeTimer)
+	service.resumeTimer.Stop() // fire it by hand below
+
+	clock.now = time.Date(2025, 3, 14, 7, 0, 0, 0, time.Local)
+	service.resumePolling()
+	service.ui.flush()
+	require.Len(t, updates, 2)
+	assert.False(t, updates[1].Paused)
+	assert.Equal(t, 4.0, updates[1].DailyCost)
+	assert.FileExists(t, marker)
+	assert.Nil(t, service.resumeTimer)
+	assert.Equal(t, PollingRunning, service.PollingStatus())
+}
+
+func TestUsageService_ApplyConfigEndsQuietHoursPause(t *testing.T) {
+	clock := &fixedClock{now: time.Date(2025, 3, 14, 23, 30, 0, 0, time.Local)}
+	service := newTestUsageService()
+	service.SetClock(clock)
+	config := models.ConfigDefaults()
+	config.QuietHours = "23:00-07:00"
+	service.ApplyConfig(config)
+	service.pollingStatus = PollingRunning // as after StartPolling
+
+	service.scheduledPoll(1)
+	require.True(t, service.state.Paused)
+
+	config.QuietHours = ""
+	service.ApplyConfig(config)
+	assert.False(t, service.state.Paused)
+	assert.Nil(t, service.resumeTimer)
+	assert.Equal(t, PollingRunning, service.PollingStatus())
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+// Usage source names.
+const (
+	SourceClaudeCode    = "Claude Code"
+	SourceClaudeDesktop = "Claude Desktop"
+)
+
+// UsageSource is a Claude config directory (one holding projects/*.jsonl)
+// that usage is read from.
+type UsageSource struct {
+	Name string
+	Dir  string
+}
+
+// clientSourceCandidates lists where other Anthropic clients keep the
+// Claude Code usage logs of sessions they run. ccusage only looks in the
+// CLI's directories, so usage found here is otherwise missed. Tests
+// replace it.
+var clientSourceCandidates = defaultClientSources
+
+// defaultClientSources returns the desktop app's data directory, where it
+// keeps the sessions of its bundled Claude Code, per OS: ~/.config/Claude,
+// ~/Library/Application Support/Claude, or %AppData%\Claude.
+func defaultClientSources() []UsageSource {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil
+	}
+	app := filepath.Join(configDir, "Claude")
+	return []UsageSource{
+		{Name: SourceClaudeDesktop, Dir: filepath.Join(app, "claude-code")},
+		{Name: SourceClaudeDesktop, Dir: app},
+	}
+}
+
+// usageSources lists every source usage is read from: the Claude Code
+// directories ccusage picks by itself (see claudeConfigDirs), then any
+// other client's that holds usage logs.
+func usageSources(configured string) []UsageSource {
+	dirs := claudeConfigDirs(configured)
+	sources := make([]UsageSource, 0, len(dirs))
+	for _, dir := range dirs {
+		sources = append(sources, UsageSource{Name: SourceClaudeCode, Dir: dir})
+	}
+	return append(sources, detectClientSources(dirs, clientSourceCandidates())...)
+}
+
+// detectClientSources returns the candidates holding usage JSONL. Ones
+// that resolve to a directory already read (a symlink to ~/.claude, or a
+// parent and its claude-code folder both matching) are skipped.
+func detectClientSources(cliDirs []string, candidates []UsageSource) []UsageSource {
+	visited := map[string]bool{}
+	for _, dir := range cliDirs {
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			visited[real] = true
+		}
+	}
+
+	var found []UsageSource
+	for _, candidate := range candidates {
+		real, err := filepath.EvalSymlinks(candidate.Dir)
+		if err != nil || visited[real] {
+			continue
+		}
+		if len(usageFiles(filepath.Join(candidate.Dir, "projects"))) == 0 {
+			continue
+		}
+		visited[real] = true
+		found = append(found, candidate)
+	}
+	return found
+}
+
+// sourceDataDirs returns the projects directories of sources.
+func sourceDataDirs(sources []UsageSource) []string {
+	dirs := make([]string, 0, len(sources))
+	for _, source := range sources {
+		dirs = append(dirs, filepath.Join(source.Dir, "projects"))
+	}
+	return dirs
+}
+
+// hasClientSources reports whether any source is another client's.
+func hasClientSources(sources []UsageSource) bool {
+	for _, source := range sources {
+		if source.Name != SourceClaudeCode {
+			return true
+		}
+	}
+	return false
+}
+
+// ccusageConfigDirFor returns the CLAUDE_CONFIG_DIR ccusage runs with:
+// configured (empty leaves the choice to ccusage) unless other clients'
+// data was found, in which case every existing source is listed so ccusage
+// merges them into one report.
+func ccusageConfigDirFor(configured string, sources []UsageSource) string {
+	if !hasClientSources(sources) {
+		return configured
+	}
+	var dirs []string
+	for _, source := range existingSources(sources) {
+		dirs = append(dirs, source.Dir)
+	}
+	return strings.Join(dirs, ",")
+}
+
+// existingSources drops sources whose directory doesn't exist, such as an
+// unused default location.
+func existingSources(sources []UsageSource) []UsageSource {
+	var existing []UsageSource
+	for _, source := range sources {
+		if info, err := os.Stat(source.Dir); err == nil && info.IsDir() {
+			existing = append(existing, source)
+		}
+	}
+	return existing
+}
+
+// SourceUsage is one source's share of today's usage.
+type SourceUsage struct {
+	UsageSource
+	Today CCUsageOutput // zero when the source has no usage today
+	Err   error
+}
+
+// SourceBreakdown runs ccusage once per source for today's totals. It
+// returns nil when all usage comes from Claude Code's own directories,
+// since the daily total already is the breakdown then.
+func (us *UsageService) SourceBreakdown() []SourceUsage {
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+	return us.sourceBreakdownLocked()
+}
+
+func (us *UsageService) sourceBreakdownLocked() []SourceUsage {
+	if !hasClientSources(us.sources) {
+		return nil
+	}
+	now := us.clock.Now()
+	today := now.Format("2006-01-02")
+	weekStart, _ := currentWeekRange(now)
+	args := ccusageDailyArgs(now)
+
+	var breakdown []SourceUsage
+	for _, source := range existingSources(us.sources) {
+		usage := SourceUsage{UsageSource: source}
+		output, err := us.runCCUsageIn(args, source.Dir)
+		if err == nil {
+			var scan *dailyScan
+			if scan, err = scanDailyOutput(output, today, weekStart.Format("2006-01-02")); err == nil {
+				usage.Today = scan.Today
+			}
+		}
+		usage.Err = err
+		breakdown = append(breakdown, usage)
+	}
+	return breakdown
+}
+
+// FormatSourceBreakdown is a one-line summary of breakdown, such as
+// "Claude Code (~/.claude) $12.40, Claude Desktop (~/.config/Claude) $3.10".
+func FormatSourceBreakdown(breakdown []SourceUsage, format models.CostFormat) string {
+	parts := make([]string, 0, len(breakdown))
+	for _, usage := range breakdown {
+		if usage.Err != nil {
+			parts = append(parts, fmt.Sprintf("%s (%s) error: %v", usage.Name, usage.Dir, usage.Err))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s) %s", usage.Name, usage.Dir, format.Format(usage.Today.TotalCost)))
+	}
+	return strings.Join(parts, ", ")
+}
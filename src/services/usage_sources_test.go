@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+func TestDetectClientSources(t *testing.T) {
+	root := t.TempDir()
+	cli := filepath.Join(root, "claude")
+	app := filepath.Join(root, "Claude")
+	bundled := filepath.Join(app, "claude-code")
+	writeSession(t, cli, filepath.Join("proj", "a.jsonl"))
+
+	candidates := []UsageSource{
+		{Name: SourceClaudeDesktop, Dir: bundled},
+		{Name: SourceClaudeDesktop, Dir: app},
+	}
+	assert.Empty(t, detectClientSources([]string{cli}, candidates), "no client data yet")
+
+	writeSession(t, bundled, filepath.Join("proj", "b.jsonl"))
+	assert.Equal(t, []UsageSource{{Name: SourceClaudeDesktop, Dir: bundled}},
+		detectClientSources([]string{cli}, candidates))
+}
+
+func TestDetectClientSources_SymlinkToCLI(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on Windows")
+	}
+	root := t.TempDir()
+	cli := filepath.Join(root, "claude")
+	writeSession(t, cli, filepath.Join("proj", "a.jsonl"))
+	link := filepath.Join(root, "Claude")
+	require.NoError(t, os.Symlink(cli, link))
+
+	assert.Empty(t, detectClientSources([]string{cli}, []UsageSource{{Name: SourceClaudeDesktop, Dir: link}}),
+		"a link to the CLI's directory is the same source")
+}
+
+func TestCCUsageConfigDirFor(t *testing.T) {
+	root := t.TempDir()
+	cli := filepath.Join(root, "claude")
+	app := filepath.Join(root, "Claude")
+	writeSession(t, cli, filepath.Join("proj", "a.jsonl"))
+	writeSession(t, app, filepath.Join("proj", "b.jsonl"))
+	missing := filepath.Join(root, "missing")
+
+	cliOnly := []UsageSource{{Name: SourceClaudeCode, Dir: cli}, {Name: SourceClaudeCode, Dir: missing}}
+	assert.Empty(t, ccusageConfigDirFor("", cliOnly), "ccusage picks its own directories")
+	assert.Equal(t, "/pinned", ccusageConfigDirFor("/pinned", cliOnly))
+
+	all := append(cliOnly, UsageSource{Name: SourceClaudeDesktop, Dir: app})
+	assert.Equal(t, cli+","+app, ccusageConfigDirFor("", all), "existing sources are merged")
+}
+
+func TestUsageService_SourceBreakdown(t *testing.T) {
+	root := t.TempDir()
+	cli := filepath.Join(root, "claude")
+	app := filepath.Join(root, "Claude")
+	writeSession(t, cli, filepath.Join("proj", "a.jsonl"))
+	writeSession(t, app, filepath.Join("proj", "b.jsonl"))
+
+	service := newTestUsageService()
+	service.SetClock(fixedClock{now: time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)})
+	service.ccusagePath = writeCCUsageScript(t, `case "$CLAUDE_CONFIG_DIR" in
+  *,*) echo '{"daily": [{"date": "2025-03-14", "totalTokens": 15, "totalCost": 4}]}' ;;
+  */claude) echo '{"daily": [{"date": "2025-03-14", "totalTokens": 10, "totalCost": 3}]}' ;;
+  *) echo '{"daily": []}' ;;
+esac`)
+
+	service.sources = []UsageSource{{Name: SourceClaudeCode, Dir: cli}}
+	assert.Nil(t, service.SourceBreakdown(), "nothing to break down with one client")
+
+	service.sources = append(service.sources, UsageSource{Name: SourceClaudeDesktop, Dir: app})
+	output, err := service.runCCUsage(nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), `"totalCost": 4`, "the daily report merges every source")
+
+	breakdown := service.SourceBreakdown()
+	require.Len(t, breakdown, 2)
+	assert.Equal(t, 3.0, breakdown[0].Today.TotalCost)
+	assert.Zero(t, breakdown[1].Today.TotalCost, "no usage today from the desktop app")
+	assert.NoError(t, breakdown[1].Err)
+
+	breakdown[1].Err = errors.New("boom")
+	line := FormatSourceBreakdown(breakdown, models.DefaultCostFormat())
+	assert.Equal(t, "Claude Code ("+cli+") $3.00, Claude Desktop ("+app+") error: boom", line)
+}
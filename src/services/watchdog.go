@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+// stallFactor is how many polling intervals may pass without the polling
+// loop handling anything before the watchdog declares it stalled. With
+// short intervals a poll's full retry budget counts instead, so a slow but
+// healthy run isn't mistaken for a stall; see stallThresholdLocked.
+const stallFactor = 3
+
+// watchdogLoop checks once per polling interval that the polling loop is
+// still making progress, until ctx is cancelled.
+func (us *UsageService) watchdogLoop(ctx context.Context, interval time.Duration) {
+	defer us.watchdog.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			us.runGuarded("polling watchdog", func() { us.recoverIfStalled(now) })
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pollLoop is one run of the polling loop, with its own ticker and
+// channels. When the watchdog replaces a stuck run, the old one keeps
+// nothing its successor uses, so once it unblocks it can't take the new
+// run's ticks or signals; it just sees its context cancelled and exits.
+type pollLoop struct {
+	cancel context.CancelFunc
+	ticker *time.Ticker
+	wake   chan struct{} // asks for a watcher-triggered refresh
+	resume chan struct{} // asks to end a quiet_hours pause
+}
+
+// newPollLoop returns a loop ticking every interval and the context that
+// stops it.
+func newPollLoop(interval time.Duration) (*pollLoop, context.Context) {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &pollLoop{
+		cancel: cancel,
+		ticker: time.NewTicker(interval),
+		wake:   make(chan struct{}, 1),
+		resume: make(chan struct{}, 1),
+	}, ctx
+}
+
+func (l *pollLoop) stop() {
+	l.cancel()
+	l.ticker.Stop()
+}
+
+// recoverIfStalled restarts the polling loop when it has handled neither a
+// successful nor a failed poll for stallThresholdLocked, as happens when
+// its ticker dies or a poll hangs. The stuck run is cancelled and a new
+// one started without waiting for it; it exits once its poll returns, and
+// StopPolling still waits for it to. It reports whether it restarted
+// polling.
+//
+// It never waits for us.mutex, since a poll wedged while holding it is
+// one of the stalls it has to catch: everything it needs is atomic.
+func (us *UsageService) recoverIfStalled(now time.Time) bool {
+	// StartPolling and StopPolling hold the lifecycle while they wait for
+	// the watchdog; let them win and check again next tick.
+	if !us.lifecycle.TryLock() {
+		return false
+	}
+	defer us.lifecycle.Unlock()
+
+	stuck := us.poll.Load()
+	silent := now.Sub(time.Unix(0, us.lastActivity.Load()))
+	if stuck == nil || silent < time.Duration(us.stallAfter.Load()) || us.pausedForQuietHours() {
+		return false
+	}
+
+	interval := time.Duration(us.pollInterval.Load())
+	loop, ctx := newPollLoop(interval)
+	stuck.stop()
+	us.poll.Store(loop)
+	us.lastActivity.Store(now.UnixNano())
+	us.lastRecovery.Store(now.UnixNano())
+	us.stallRecoveries.Add(1)
+
+	us.logger.Error("Polling stalled, restarting it", map[string]interface{}{
+		"silent_for": silent.Round(time.Second).String(),
+		"interval":   interval.String(),
+	})
+	us.loops.Add(1)
+	go us.pollingLoop(ctx, loop)
+	signal(loop.wake) // refresh now rather than an interval from now
+	return true
+}
+
+// pausedForQuietHours reports whether polling is paused for quiet_hours.
+// While us.mutex is held it can't tell, and reports false.
+func (us *UsageService) pausedForQuietHours() bool {
+	if !us.mutex.TryRLock() {
+		return false
+	}
+	defer us.mutex.RUnlock()
+	return us.pollingStatus == PollingPaused
+}
+
+// StallRecoveries reports how many times the watchdog has restarted a
+// stalled polling loop, and when it last did.
+func (us *UsageService) StallRecoveries() (count int, last time.Time) {
+	count = int(us.stallRecoveries.Load())
+	if count > 0 {
+		last = time.Unix(0, us.lastRecovery.Load())
+	}
+	return count, last
+}
+
+// stallThresholdLocked is how long the polling loop may go without
+// handling a poll before it counts as stalled: stallFactor intervals, or
+// an interval plus the longest a healthy scheduled poll can take with its
+// retries and the waits between them, whichever is longer.
+func (us *UsageService) stallThresholdLocked() time.Duration {
+	longestPoll := time.Duration(pollRetries) * us.cmdTimeout
+	for attempt := 1; attempt < pollRetries; attempt++ {
+		longestPoll += retryDelay(attempt)
+	}
+	interval := time.Duration(us.pollInterval.Load())
+	return max(stallFactor*interval, interval+longestPoll)
+}
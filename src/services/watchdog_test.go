@@ -0,0 +1,114 @@
+package services
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cc-dailyuse-bar/src/models"
+)
+
+func TestUsageService_RecoverIfStalled(t *testing.T) {
+	service := newTestUsageService()
+	service.SetClock(fixedClock{now: time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)})
+	service.ccusagePath = writeCCUsageScript(t, `echo '{"daily":[{"date":"2025-03-14","totalTokens":1,"totalCost":1}]}'`)
+
+	assert.False(t, service.recoverIfStalled(time.Now().Add(time.Hour)), "nothing to recover while stopped")
+
+	var polls atomic.Int32
+	require.NoError(t, service.StartPolling(60, func(*models.UsageState) { polls.Add(1) }))
+	defer service.StopPolling()
+	stuck := service.poll.Load()
+
+	assert.False(t, service.recoverIfStalled(time.Now().Add(2*time.Minute)), "within three intervals")
+	count, _ := service.StallRecoveries()
+	assert.Zero(t, count)
+
+	stalledAt := time.Now().Add(4 * time.Minute)
+	assert.True(t, service.recoverIfStalled(stalledAt))
+	count, last := service.StallRecoveries()
+	assert.Equal(t, 1, count)
+	assert.True(t, stalledAt.Equal(last))
+	assert.NotSame(t, stuck, service.poll.Load(), "polling restarted as a fresh loop")
+	require.Eventually(t, func() bool { return polls.Load() == 1 }, 5*time.Second, 10*time.Millisecond,
+		"recovery refreshes immediately")
+	assert.Equal(t, PollingRunning, service.PollingStatus())
+
+	assert.False(t, service.recoverIfStalled(time.Now().Add(time.Minute)), "the restarted loop is making progress")
+}
+
+func TestUsageService_RecoverIfStalled_MutexHeld(t *testing.T) {
+	service := newTestUsageService()
+	service.SetClock(fixedClock{now: time.Date(2025, 3, 14, 12, 0, 0, 0, time.Local)})
+	service.ccusagePath = writeCCUsageScript(t, `echo '{"daily":[{"date":"2025-03-14","totalTokens":1,"totalCost":1}]}'`)
+
+	var polls atomic.Int32
+	require.NoError(t, service.StartPolling(60, func(*models.UsageState) { polls.Add(1) }))
+	defer service.StopPolling()
+	stuck := service.poll.Load()
+
+	// A poll wedged holding the state lock mustn't wedge the watchdog too.
+	service.mutex.Lock()
+	recovered := make(chan bool)
+	go func() { recovered <- service.recoverIfStalled(time.Now().Add(time.Hour)) }()
+	select {
+	case ok := <-recovered:
+		assert.True(t, ok)
+	case <-time.After(5 * time.Second):
+		service.mutex.Unlock()
+		t.Fatal("recoverIfStalled waited for the state mutex")
+	}
+	restarted := service.poll.Load()
+	require.NotSame(t, stuck, restarted)
+	assert.NotEqual(t, stuck.wake, restarted.wake, "each loop gets its own channels")
+
+	// Signals go to the new loop only; the old one just exits.
+	service.signalWake()
+	assert.Empty(t, stuck.wake)
+	service.mutex.Unlock()
+	require.Eventually(t, func() bool { return polls.Load() >= 1 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestUsageService_RecoverIfStalled_Paused(t *testing.T) {
+	service := newTestUsageService()
+	require.NoError(t, service.StartPolling(60, nil))
+	defer service.StopPolling()
+
+	service.mutex.Lock()
+	require.NoError(t, service.setPollingStatusLocked(PollingPaused))
+	service.mutex.Unlock()
+	assert.False(t, service.recoverIfStalled(time.Now().Add(time.Hour)), "quiet_hours pauses aren't stalls")
+}
+
+func TestUsageService_RecoverIfStalled_FollowsIntervalChanges(t *testing.T) {
+	service := newTestUsageService()
+	require.NoError(t, service.StartPolling(30, nil))
+	defer service.StopPolling()
+
+	require.NoError(t, service.SetUpdateInterval(600))
+	assert.False(t, service.recoverIfStalled(time.Now().Add(10*time.Minute)),
+		"a longer interval set later isn't a stall")
+	assert.Equal(t, int64(600*time.Second), service.pollInterval.Load())
+
+	config := models.ConfigDefaults()
+	config.UpdateInterval = 120
+	service.ApplyConfig(config)
+	assert.Equal(t, int64(120*time.Second), service.pollInterval.Load())
+	assert.True(t, service.recoverIfStalled(time.Now().Add(10*time.Minute)))
+	assert.Equal(t, int64(120*time.Second), service.pollInterval.Load(), "recovery keeps the current interval")
+}
+
+func TestUsageService_StallThreshold(t *testing.T) {
+	service := newTestUsageService()
+	service.pollInterval.Store(int64(30 * time.Second))
+	service.cmdTimeout = 30 * time.Second
+	// Three 30s attempts plus 1s and 2s between them can't trip the
+	// watchdog on top of the interval before them.
+	assert.Equal(t, 30*time.Second+93*time.Second, service.stallThresholdLocked())
+
+	service.pollInterval.Store(int64(10 * time.Minute))
+	assert.Equal(t, 30*time.Minute, service.stallThresholdLocked())
+}